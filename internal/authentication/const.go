@@ -2,6 +2,7 @@ package authentication
 
 import (
 	"errors"
+	"fmt"
 )
 
 // Level is the type representing a level of authentication.
@@ -19,7 +20,8 @@ const (
 )
 
 const (
-	ldapSupportedExtensionAttribute = "supportedExtension"
+	ldapSupportedExtensionAttribute   = "supportedExtension"
+	ldapSupportedLDAPVersionAttribute = "supportedLDAPVersion"
 
 	// LDAP Extension OID: Password Modify Extended Operation.
 	//
@@ -38,6 +40,15 @@ const (
 	//
 	// See the linked documents for more information.
 	ldapOIDExtensionTLS = "1.3.6.1.4.1.1466.20037"
+
+	// LDAP Matching Rule OID: LDAP_MATCHING_RULE_IN_CHAIN.
+	//
+	// MS ADTS: https://learn.microsoft.com/en-us/windows/win32/adsi/search-filter-syntax
+	//
+	// OID Reference: https://oidref.com/1.2.840.113556.1.4.1941
+	//
+	// See the linked documents for more information.
+	ldapOIDMatchingRuleInChain = "1.2.840.113556.1.4.1941"
 )
 
 const (
@@ -67,6 +78,23 @@ const (
 	ldapAttributeUserPassword = "userPassword"
 )
 
+const (
+	ldapAttributePwdLastSet                         = "pwdLastSet"
+	ldapAttributeMsDSUserPasswordExpiryTimeComputed = "msDS-UserPasswordExpiryTimeComputed"
+	ldapAttributePwdChangedTime                     = "pwdChangedTime"
+	ldapAttributePwdReset                           = "pwdReset"
+
+	// ldapMsftPasswordNeverExpires is the value of the msDS-UserPasswordExpiryTimeComputed attribute when the password
+	// of the user never expires.
+	ldapMsftPasswordNeverExpires uint64 = 0x7FFFFFFFFFFFFFFF
+
+	// Active Directory bind error data codes which indicate the password was correct but has expired or must be changed.
+	//
+	// MS Docs: https://learn.microsoft.com/en-us/troubleshoot/windows-server/windows-security/logon-failure-account-lockout
+	ldapMsftErrDataPasswordExpired    = "data 532"
+	ldapMsftErrDataPasswordMustChange = "data 773"
+)
+
 const (
 	ldapBaseObjectFilter = "(objectClass=*)"
 )
@@ -81,13 +109,25 @@ const (
 )
 
 const (
-	ldapGeneralizedTimeDateTimeFormat = "20060102150405.0Z"
+	ldapGeneralizedTimeDateTimeFormat      = "20060102150405.0Z"
+	ldapGeneralizedTimeDateTimeParseFormat = "20060102150405Z0700"
 )
 
 const (
 	none = "none"
 )
 
+const (
+	// CacheResultHit is the result recorded when the user details were retrieved from the cache.
+	CacheResultHit = "hit"
+
+	// CacheResultNegativeHit is the result recorded when the cache indicated the user does not exist.
+	CacheResultNegativeHit = "negative_hit"
+
+	// CacheResultMiss is the result recorded when the user details were retrieved from the authentication backend.
+	CacheResultMiss = "miss"
+)
+
 const (
 	hashArgon2    = "argon2"
 	hashSHA2Crypt = "sha2crypt"
@@ -100,6 +140,15 @@ var (
 	// ErrUserNotFound indicates the user wasn't found in the authentication backend.
 	ErrUserNotFound = errors.New("user not found")
 
+	// ErrUserDisabled indicates the user was found in the authentication backend but is disabled. It wraps
+	// ErrUserNotFound so a disabled user is treated the same as a user which doesn't exist, except by the
+	// ChainedUserProvider which must not consult any other backend for the username.
+	ErrUserDisabled = fmt.Errorf("%w: the user is disabled", ErrUserNotFound)
+
+	// ErrPasswordChangeRequired indicates the password of the user was correct but has expired or must be changed before
+	// the user is allowed to login.
+	ErrPasswordChangeRequired = errors.New("password change required")
+
 	// ErrNoContent is returned when the file is empty.
 	ErrNoContent = errors.New("no file content")
 )
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/go-crypt/crypt/algorithm/pbkdf2"
 	"github.com/go-crypt/crypt/algorithm/scrypt"
 	"github.com/go-crypt/crypt/algorithm/shacrypt"
+	"github.com/sirupsen/logrus"
 
 	"github.com/authelia/authelia/v4/internal/configuration/schema"
 	"github.com/authelia/authelia/v4/internal/logging"
@@ -23,9 +25,11 @@ import (
 type FileUserProvider struct {
 	config        *schema.FileAuthenticationBackend
 	hash          algorithm.Hash
+	parameters    string
 	database      *FileUserDatabase
 	mutex         *sync.Mutex
 	timeoutReload time.Time
+	log           *logrus.Logger
 }
 
 // NewFileUserProvider creates a new instance of FileUserProvider.
@@ -34,6 +38,7 @@ func NewFileUserProvider(config *schema.FileAuthenticationBackend) (provider *Fi
 		config:        config,
 		mutex:         &sync.Mutex{},
 		timeoutReload: time.Now().Add(-1 * time.Second),
+		log:           logging.Logger(),
 	}
 }
 
@@ -72,10 +77,86 @@ func (p *FileUserProvider) CheckUserPassword(username string, password string) (
 	}
 
 	if details.Disabled {
-		return false, ErrUserNotFound
+		return false, ErrUserDisabled
 	}
 
-	return details.Digest.MatchAdvanced(password)
+	if match, err = details.Digest.MatchAdvanced(password); err != nil || !match {
+		return match, err
+	}
+
+	if p.config.RehashOnLogin {
+		p.rehash(details, password)
+	}
+
+	if p.isPasswordChangeRequired(details) {
+		return true, fmt.Errorf("the password of user '%s' has expired or must be changed: %w", details.Username, ErrPasswordChangeRequired)
+	}
+
+	return true, nil
+}
+
+// isPasswordChangeRequired returns true if password expiry is enabled and the user must change their password or the
+// password was changed before the configured max age. Users without a recorded password change time never expire.
+func (p *FileUserProvider) isPasswordChangeRequired(details DatabaseUserDetails) bool {
+	switch {
+	case !p.config.PasswordExpiry.Enable:
+		return false
+	case details.PasswordChangeRequired:
+		return true
+	case p.config.PasswordExpiry.MaxAge <= 0 || details.PasswordChangedAt.IsZero():
+		return false
+	default:
+		return !time.Now().Before(details.PasswordChangedAt.Add(p.config.PasswordExpiry.MaxAge))
+	}
+}
+
+// rehash hashes the password of the user with the configured algorithm and parameters if the stored digest does not
+// use them and saves the database. Errors are logged rather than returned as the user has already been authenticated.
+// The current details of the user are read again while holding the mutex so only the digest which was checked is
+// replaced, and a concurrent change to the user is never overwritten.
+func (p *FileUserProvider) rehash(details DatabaseUserDetails, password string) {
+	var (
+		digest  algorithm.Digest
+		current DatabaseUserDetails
+		err     error
+	)
+
+	encoded := details.Digest.Encode()
+	parameters := getDigestParameters(encoded)
+
+	if parameters == p.parameters {
+		return
+	}
+
+	if digest, err = p.hash.Hash(password); err != nil {
+		p.log.WithError(err).Errorf("Error occurred upgrading the password hash of user '%s'", details.Username)
+
+		return
+	}
+
+	p.mutex.Lock()
+
+	defer p.mutex.Unlock()
+
+	if current, err = p.database.GetUserDetails(details.Username); err != nil || current.Digest == nil || current.Digest.Encode() != encoded {
+		p.log.Debugf("Skipping the upgrade of the password hash of user '%s' as the user has changed", details.Username)
+
+		return
+	}
+
+	current.Digest = digest
+
+	p.database.SetUserDetails(current.Username, &current)
+
+	p.setTimeoutReload(time.Now())
+
+	if err = p.database.Save(); err != nil {
+		p.log.WithError(err).Errorf("Error occurred saving the upgraded password hash of user '%s'", details.Username)
+
+		return
+	}
+
+	p.log.Infof("Upgraded the password hash of user '%s' from '%s' to '%s'", details.Username, parameters, p.parameters)
 }
 
 // GetDetails retrieve the groups a user belongs to.
@@ -87,7 +168,7 @@ func (p *FileUserProvider) GetDetails(username string) (details *UserDetails, er
 	}
 
 	if d.Disabled {
-		return nil, ErrUserNotFound
+		return nil, ErrUserDisabled
 	}
 
 	return d.ToUserDetails(), nil
@@ -97,26 +178,28 @@ func (p *FileUserProvider) GetDetails(username string) (details *UserDetails, er
 func (p *FileUserProvider) UpdatePassword(username string, newPassword string) (err error) {
 	var details DatabaseUserDetails
 
+	p.mutex.Lock()
+
+	defer p.mutex.Unlock()
+
 	if details, err = p.database.GetUserDetails(username); err != nil {
 		return err
 	}
 
 	if details.Disabled {
-		return ErrUserNotFound
+		return ErrUserDisabled
 	}
 
 	if details.Digest, err = p.hash.Hash(newPassword); err != nil {
 		return err
 	}
 
-	p.database.SetUserDetails(details.Username, &details)
+	details.PasswordChangedAt, details.PasswordChangeRequired = time.Now().UTC().Truncate(time.Second), false
 
-	p.mutex.Lock()
+	p.database.SetUserDetails(details.Username, &details)
 
 	p.setTimeoutReload(time.Now())
 
-	p.mutex.Unlock()
-
 	if err = p.database.Save(); err != nil {
 		return err
 	}
@@ -127,7 +210,7 @@ func (p *FileUserProvider) UpdatePassword(username string, newPassword string) (
 // StartupCheck implements the startup check provider interface.
 func (p *FileUserProvider) StartupCheck() (err error) {
 	if err = checkDatabase(p.config.Path); err != nil {
-		logging.Logger().WithError(err).Errorf("Error checking user authentication YAML database")
+		p.log.WithError(err).Errorf("Error checking user authentication YAML database")
 
 		return fmt.Errorf("one or more errors occurred checking the authentication database")
 	}
@@ -136,6 +219,17 @@ func (p *FileUserProvider) StartupCheck() (err error) {
 		return err
 	}
 
+	if p.config.RehashOnLogin {
+		var digest algorithm.Digest
+
+		// The digest is only used to determine the encoded parameters so the password is irrelevant.
+		if digest, err = p.hash.Hash("parameters"); err != nil {
+			return fmt.Errorf("failed to determine the configured password hash parameters: %w", err)
+		}
+
+		p.parameters = getDigestParameters(digest.Encode())
+	}
+
 	p.database = NewFileUserDatabase(p.config.Path, p.config.Search.Email, p.config.Search.CaseInsensitive)
 
 	if err = p.database.Load(); err != nil {
@@ -215,5 +309,29 @@ func checkDatabase(path string) (err error) {
 	return nil
 }
 
+// getDigestParameters returns the encoded digest with the salt and key replaced by their encoded lengths, which allows
+// comparing the algorithm and parameters of two digests.
+func getDigestParameters(encoded string) (parameters string) {
+	parts := strings.Split(encoded, "$")
+
+	switch {
+	case len(parts) == 4 && strings.HasPrefix(parts[1], "2"):
+		// The bcrypt format has the salt and key in a single fixed length part.
+		return strings.Join(parts[:3], "$")
+	case len(parts) < 4:
+		// Formats which are not known to have a salt and key such as plaintext only have the algorithm identifier
+		// included as the remaining parts may be sensitive.
+		if len(parts) < 2 {
+			return ""
+		}
+
+		return strings.Join(parts[:2], "$")
+	}
+
+	n := len(parts)
+
+	return fmt.Sprintf("%s$<%d>$<%d>", strings.Join(parts[:n-2], "$"), len(parts[n-2]), len(parts[n-1]))
+}
+
 //go:embed users_database.template.yml
 var userYAMLTemplate []byte
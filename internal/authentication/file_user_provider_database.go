@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/asaskevich/govalidator"
 	"github.com/go-crypt/crypt"
@@ -38,13 +39,21 @@ type FileUserDatabase struct {
 	SearchCI    bool
 }
 
-// Save the database to disk.
+// Save the database to disk. The layout of the existing file is preserved if it can be read.
 func (m *FileUserDatabase) Save() (err error) {
 	m.RLock()
 
 	defer m.RUnlock()
 
-	if err = m.ToDatabaseModel().Write(m.Path); err != nil {
+	model := m.ToDatabaseModel()
+
+	existing := &DatabaseModel{}
+
+	if err = existing.Read(m.Path); err == nil {
+		model.node = existing.node
+	}
+
+	if err = model.Write(m.Path); err != nil {
 		return err
 	}
 
@@ -209,6 +218,9 @@ type DatabaseUserDetails struct {
 	DisplayName string
 	Email       string
 	Groups      []string
+
+	PasswordChangedAt      time.Time
+	PasswordChangeRequired bool
 }
 
 // ToUserDetails converts DatabaseUserDetails into a *UserDetails given a username.
@@ -228,12 +240,18 @@ func (m DatabaseUserDetails) ToUserDetailsModel() (model UserDetailsModel) {
 		DisplayName:    m.DisplayName,
 		Email:          m.Email,
 		Groups:         m.Groups,
+		Disabled:       m.Disabled,
+
+		PasswordChangedAt:      m.PasswordChangedAt,
+		PasswordChangeRequired: m.PasswordChangeRequired,
 	}
 }
 
 // DatabaseModel is the model of users file database.
 type DatabaseModel struct {
 	Users map[string]UserDetailsModel `yaml:"users" valid:"required"`
+
+	node *yaml.Node
 }
 
 // ReadToFileUserDatabase reads the DatabaseModel into a FileUserDatabase.
@@ -274,6 +292,12 @@ func (m *DatabaseModel) Read(filePath string) (err error) {
 		return fmt.Errorf("could not parse the YAML database: %w", err)
 	}
 
+	m.node = &yaml.Node{}
+
+	if err = yaml.Unmarshal(content, m.node); err != nil {
+		return fmt.Errorf("could not parse the YAML database: %w", err)
+	}
+
 	if ok, err = govalidator.ValidateStruct(m); err != nil {
 		return fmt.Errorf("could not validate the schema: %w", err)
 	}
@@ -285,13 +309,21 @@ func (m *DatabaseModel) Read(filePath string) (err error) {
 	return nil
 }
 
-// Write a DatabaseModel to disk.
+// Write a DatabaseModel to disk. If the DatabaseModel was read from disk the layout of the original file including the
+// order of the users and their attributes, the comments, and the indentation is preserved.
 func (m *DatabaseModel) Write(fileName string) (err error) {
 	var (
 		data []byte
 	)
 
-	if data, err = yaml.Marshal(m); err != nil {
+	switch m.node {
+	case nil:
+		data, err = yaml.Marshal(m)
+	default:
+		data, err = m.marshalNode()
+	}
+
+	if err != nil {
 		return err
 	}
 
@@ -304,7 +336,10 @@ type UserDetailsModel struct {
 	DisplayName    string   `yaml:"displayname" valid:"required"`
 	Email          string   `yaml:"email"`
 	Groups         []string `yaml:"groups"`
-	Disabled       bool     `yaml:"disabled"`
+	Disabled       bool     `yaml:"disabled,omitempty"`
+
+	PasswordChangedAt      time.Time `yaml:"password_changed_at,omitempty"`
+	PasswordChangeRequired bool      `yaml:"password_change_required,omitempty"`
 }
 
 // ToDatabaseUserDetailsModel converts a UserDetailsModel into a *DatabaseUserDetails.
@@ -322,5 +357,8 @@ func (m UserDetailsModel) ToDatabaseUserDetailsModel(username string) (model *Da
 		DisplayName: m.DisplayName,
 		Email:       m.Email,
 		Groups:      m.Groups,
+
+		PasswordChangedAt:      m.PasswordChangedAt,
+		PasswordChangeRequired: m.PasswordChangeRequired,
 	}, nil
 }
@@ -0,0 +1,193 @@
+package authentication
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	yamlTagString    = "!!str"
+	yamlTagBool      = "!!bool"
+	yamlTagTimestamp = "!!timestamp"
+	yamlTagSeq       = "!!seq"
+
+	yamlKeyUsers       = "users"
+	yamlKeyPassword    = "password"
+	yamlKeyDisplayName = "displayname"
+	yamlKeyEmail       = "email"
+	yamlKeyGroups      = "groups"
+	yamlKeyDisabled    = "disabled"
+
+	yamlKeyPasswordChangedAt      = "password_changed_at"
+	yamlKeyPasswordChangeRequired = "password_change_required"
+)
+
+// marshalNode applies the users of the DatabaseModel to the node it was read from and marshals the node. Users which
+// exist in the node are updated in place, users which no longer exist are removed, and new users are appended in
+// alphabetical order.
+func (m *DatabaseModel) marshalNode() (data []byte, err error) {
+	root := m.node
+
+	if root.Kind == yaml.DocumentNode && len(root.Content) != 0 {
+		root = root.Content[0]
+	}
+
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("could not update the YAML database: the document is not a mapping")
+	}
+
+	users := yamlMappingValue(root, yamlKeyUsers)
+
+	if users == nil || users.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("could not update the YAML database: the '%s' key is not a mapping", yamlKeyUsers)
+	}
+
+	seen := map[string]struct{}{}
+	content := make([]*yaml.Node, 0, len(users.Content))
+
+	for i := 0; i+1 < len(users.Content); i += 2 {
+		key, value := users.Content[i], users.Content[i+1]
+
+		details, ok := m.Users[key.Value]
+		if !ok {
+			continue
+		}
+
+		if err = yamlUpdateUserDetailsNode(value, details); err != nil {
+			return nil, fmt.Errorf("could not update the YAML database for user '%s': %w", key.Value, err)
+		}
+
+		seen[key.Value] = struct{}{}
+		content = append(content, key, value)
+	}
+
+	usernames := make([]string, 0, len(m.Users))
+
+	for username := range m.Users {
+		if _, ok := seen[username]; !ok {
+			usernames = append(usernames, username)
+		}
+	}
+
+	sort.Strings(usernames)
+
+	for _, username := range usernames {
+		value := &yaml.Node{}
+
+		if err = value.Encode(m.Users[username]); err != nil {
+			return nil, fmt.Errorf("could not update the YAML database for user '%s': %w", username, err)
+		}
+
+		content = append(content, &yaml.Node{Kind: yaml.ScalarNode, Tag: yamlTagString, Value: username}, value)
+	}
+
+	users.Content = content
+
+	buf := &bytes.Buffer{}
+
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(yamlMappingIndent(root, users))
+
+	if err = encoder.Encode(m.node); err != nil {
+		return nil, err
+	}
+
+	if err = encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func yamlUpdateUserDetailsNode(node *yaml.Node, details UserDetailsModel) (err error) {
+	if node.Kind != yaml.MappingNode {
+		return node.Encode(details)
+	}
+
+	yamlMappingSetScalar(node, yamlKeyPassword, yamlTagString, details.HashedPassword, false)
+	yamlMappingSetScalar(node, yamlKeyDisplayName, yamlTagString, details.DisplayName, false)
+	yamlMappingSetScalar(node, yamlKeyEmail, yamlTagString, details.Email, details.Email == "")
+	yamlMappingSetSequence(node, yamlKeyGroups, details.Groups)
+	yamlMappingSetScalar(node, yamlKeyDisabled, yamlTagBool, strconv.FormatBool(details.Disabled), !details.Disabled)
+	yamlMappingSetScalar(node, yamlKeyPasswordChangedAt, yamlTagTimestamp, details.PasswordChangedAt.Format(time.RFC3339Nano), details.PasswordChangedAt.IsZero())
+	yamlMappingSetScalar(node, yamlKeyPasswordChangeRequired, yamlTagBool, strconv.FormatBool(details.PasswordChangeRequired), !details.PasswordChangeRequired)
+
+	return nil
+}
+
+// yamlMappingSetScalar sets the value of a key in a mapping node to a scalar value keeping the style and comments of
+// the existing value. If the key does not exist and omit is true the key is not added.
+func yamlMappingSetScalar(node *yaml.Node, key, tag, value string, omit bool) {
+	if existing := yamlMappingValue(node, key); existing != nil && existing.Kind == yaml.ScalarNode {
+		existing.Tag, existing.Value = tag, value
+
+		return
+	} else if existing != nil {
+		*existing = yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+
+		return
+	}
+
+	if omit {
+		return
+	}
+
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: yamlTagString, Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value},
+	)
+}
+
+// yamlMappingSetSequence sets the value of a key in a mapping node to a sequence of strings keeping the style and
+// comments of the existing value. If the key does not exist and there are no values the key is not added.
+func yamlMappingSetSequence(node *yaml.Node, key string, values []string) {
+	content := make([]*yaml.Node, len(values))
+
+	for i, value := range values {
+		content[i] = &yaml.Node{Kind: yaml.ScalarNode, Tag: yamlTagString, Value: value}
+	}
+
+	if existing := yamlMappingValue(node, key); existing != nil {
+		existing.Kind, existing.Tag, existing.Value, existing.Content = yaml.SequenceNode, yamlTagSeq, "", content
+
+		return
+	}
+
+	if len(values) == 0 {
+		return
+	}
+
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: yamlTagString, Value: key},
+		&yaml.Node{Kind: yaml.SequenceNode, Tag: yamlTagSeq, Content: content},
+	)
+}
+
+func yamlMappingValue(node *yaml.Node, key string) (value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// yamlMappingIndent returns the indentation of the child mapping relative to the parent mapping, falling back to two
+// spaces if it can't be determined.
+func yamlMappingIndent(parent, child *yaml.Node) (indent int) {
+	if len(parent.Content) == 0 || len(child.Content) == 0 {
+		return 2
+	}
+
+	if indent = child.Content[0].Column - parent.Content[0].Column; indent < 2 {
+		return 2
+	}
+
+	return indent
+}
@@ -1,12 +1,14 @@
 package authentication
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"regexp"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -317,7 +319,289 @@ func TestShouldNotAllowLoginOfDisabledUsers(t *testing.T) {
 		ok, err := provider.CheckUserPassword("dis", "password")
 
 		assert.False(t, ok)
-		assert.EqualError(t, err, "user not found")
+		assert.ErrorIs(t, err, ErrUserDisabled)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func TestShouldRehashOnLogin(t *testing.T) {
+	WithDatabase(UserDatabaseContent, func(path string) {
+		config := DefaultFileAuthenticationBackendConfiguration
+		config.Path = path
+		config.RehashOnLogin = true
+
+		provider := NewFileUserProvider(&config)
+
+		assert.NoError(t, provider.StartupCheck())
+		assert.Equal(t, "$argon2id$v=19$m=64,t=3,p=4$<22>$<43>", provider.parameters)
+
+		ok, err := provider.CheckUserPassword("harry", "wrong")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		model := &DatabaseModel{}
+
+		require.NoError(t, model.Read(path))
+		assert.True(t, strings.HasPrefix(model.Users["harry"].HashedPassword, "{CRYPT}$6$"))
+
+		ok, err = provider.CheckUserPassword("harry", "password")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		require.NoError(t, model.Read(path))
+		assert.True(t, strings.HasPrefix(model.Users["harry"].HashedPassword, "$argon2id$v=19$m=64,t=3,p=4$"))
+		assert.True(t, strings.HasPrefix(model.Users["bob"].HashedPassword, "$6$rounds=500000$"))
+		assert.Equal(t, "Harry Potter", model.Users["harry"].DisplayName)
+
+		hashed := model.Users["harry"].HashedPassword
+
+		provider = NewFileUserProvider(&config)
+
+		assert.NoError(t, provider.StartupCheck())
+
+		ok, err = provider.CheckUserPassword("harry", "password")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		require.NoError(t, model.Read(path))
+		assert.Equal(t, hashed, model.Users["harry"].HashedPassword)
+	})
+}
+
+func TestShouldNotRehashOnLoginWhenPasswordChanged(t *testing.T) {
+	WithDatabase(UserDatabaseContent, func(path string) {
+		config := DefaultFileAuthenticationBackendConfiguration
+		config.Path = path
+		config.RehashOnLogin = true
+
+		provider := NewFileUserProvider(&config)
+
+		assert.NoError(t, provider.StartupCheck())
+
+		details, err := provider.database.GetUserDetails("harry")
+		require.NoError(t, err)
+
+		require.NoError(t, provider.UpdatePassword("harry", "new-password"))
+
+		provider.rehash(details, "password")
+
+		ok, err := provider.CheckUserPassword("harry", "password")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		ok, err = provider.CheckUserPassword("harry", "new-password")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		model := &DatabaseModel{}
+
+		require.NoError(t, model.Read(path))
+		assert.WithinDuration(t, time.Now(), model.Users["harry"].PasswordChangedAt, time.Minute)
+	})
+}
+
+func TestShouldRequirePasswordChangeWhenPasswordExpired(t *testing.T) {
+	content := []byte(fmt.Sprintf(`
+users:
+  john:
+    displayname: "John Doe"
+    password: "{CRYPT}$6$rounds=500000$jgiCMRyGXzoqpxS3$w2pJeZnnH8bwW3zzvoMWtTRfQYsHbWbD/hquuQ5vUeIyl9gdwBIt6RWk2S6afBA0DPakbeWgD/4SZPiS0hYtU/"
+    email: john.doe@authelia.com
+    password_change_required: true
+  harry:
+    displayname: "Harry Potter"
+    password: "{CRYPT}$6$rounds=500000$jgiCMRyGXzoqpxS3$w2pJeZnnH8bwW3zzvoMWtTRfQYsHbWbD/hquuQ5vUeIyl9gdwBIt6RWk2S6afBA0DPakbeWgD/4SZPiS0hYtU/"
+    email: harry.potter@authelia.com
+    password_changed_at: 2000-01-01T00:00:00Z
+  bob:
+    displayname: "Bob Dylan"
+    password: "{CRYPT}$6$rounds=500000$jgiCMRyGXzoqpxS3$w2pJeZnnH8bwW3zzvoMWtTRfQYsHbWbD/hquuQ5vUeIyl9gdwBIt6RWk2S6afBA0DPakbeWgD/4SZPiS0hYtU/"
+    email: bob.dylan@authelia.com
+    password_changed_at: %s
+  james:
+    displayname: "James Dean"
+    password: "{CRYPT}$6$rounds=500000$jgiCMRyGXzoqpxS3$w2pJeZnnH8bwW3zzvoMWtTRfQYsHbWbD/hquuQ5vUeIyl9gdwBIt6RWk2S6afBA0DPakbeWgD/4SZPiS0hYtU/"
+    email: james.dean@authelia.com
+`, time.Now().UTC().Format(time.RFC3339)))
+
+	WithDatabase(content, func(path string) {
+		config := DefaultFileAuthenticationBackendConfiguration
+		config.Path = path
+
+		provider := NewFileUserProvider(&config)
+
+		assert.NoError(t, provider.StartupCheck())
+
+		ok, err := provider.CheckUserPassword("john", "password")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		config.PasswordExpiry = schema.AuthenticationBackendPasswordExpiry{Enable: true, MaxAge: time.Hour * 24 * 90}
+
+		provider = NewFileUserProvider(&config)
+
+		assert.NoError(t, provider.StartupCheck())
+
+		ok, err = provider.CheckUserPassword("john", "wrong")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		for _, username := range []string{"john", "harry"} {
+			ok, err = provider.CheckUserPassword(username, "password")
+			assert.ErrorIs(t, err, ErrPasswordChangeRequired)
+			assert.True(t, ok)
+		}
+
+		for _, username := range []string{"bob", "james"} {
+			ok, err = provider.CheckUserPassword(username, "password")
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		}
+
+		require.NoError(t, provider.UpdatePassword("john", "new-password"))
+
+		ok, err = provider.CheckUserPassword("john", "new-password")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		model := &DatabaseModel{}
+
+		require.NoError(t, model.Read(path))
+		assert.False(t, model.Users["john"].PasswordChangeRequired)
+		assert.WithinDuration(t, time.Now(), model.Users["john"].PasswordChangedAt, time.Minute)
+		assert.Equal(t, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), model.Users["harry"].PasswordChangedAt)
+		assert.True(t, model.Users["james"].PasswordChangedAt.IsZero())
+	})
+}
+
+func TestShouldNotRehashOnLoginWhenDisabled(t *testing.T) {
+	WithDatabase(UserDatabaseContent, func(path string) {
+		config := DefaultFileAuthenticationBackendConfiguration
+		config.Path = path
+
+		provider := NewFileUserProvider(&config)
+
+		assert.NoError(t, provider.StartupCheck())
+
+		ok, err := provider.CheckUserPassword("harry", "password")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		model := &DatabaseModel{}
+
+		require.NoError(t, model.Read(path))
+		assert.True(t, strings.HasPrefix(model.Users["harry"].HashedPassword, "{CRYPT}$6$"))
+	})
+}
+
+func TestGetDigestParameters(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     string
+		expected string
+	}{
+		{"ShouldHandleArgon2", "$argon2id$v=19$m=65536,t=3,p=2$BpLnfgDsc2WD8F2q$o/vzA4myCqZZ36bUGsDY//8mKUYNZZaR0t4MFFSs+iM", "$argon2id$v=19$m=65536,t=3,p=2$<16>$<43>"},
+		{"ShouldHandleSHA2Crypt", "$6$rounds=500000$jgiCMRyGXzoqpxS3$w2pJeZnnH8bwW3zzvoMWtTRfQYsHbWbD/hquuQ5vUeIyl9gdwBIt6RWk2S6afBA0DPakbeWgD/4SZPiS0hYtU/", "$6$rounds=500000$<16>$<86>"},
+		{"ShouldHandleBCrypt", "$2b$12$yH7WOlBEAHMJ.zPNnjbMEOMYIFDBsbcmWp0S6CgyV3xR6QZ8UGCQa", "$2b$12"},
+		{"ShouldHandleBCryptSHA256", "$bcrypt-sha256$v=2,t=2b,r=12$n79VH.0Q2TMWmt3Oqt9uku$Kq4Noyk3094Y2QlB8NdRT8SvGiI4ft2", "$bcrypt-sha256$v=2,t=2b,r=12$<22>$<31>"},
+		{"ShouldHandlePlainText", "$plaintext$example", "$plaintext"},
+		{"ShouldHandleInvalid", "example", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, getDigestParameters(tc.have))
+		})
+	}
+}
+
+func TestShouldNotEnableDisabledUsersOnUpdatePassword(t *testing.T) {
+	WithDatabase(UserDatabaseContent, func(path string) {
+		config := DefaultFileAuthenticationBackendConfiguration
+		config.Path = path
+
+		provider := NewFileUserProvider(&config)
+
+		assert.NoError(t, provider.StartupCheck())
+		assert.NoError(t, provider.UpdatePassword("john", "newpassword"))
+
+		model := &DatabaseModel{}
+
+		require.NoError(t, model.Read(path))
+		assert.True(t, model.Users["dis"].Disabled)
+		assert.False(t, model.Users["john"].Disabled)
+	})
+}
+
+func TestShouldPreserveDatabaseLayoutOnWrite(t *testing.T) {
+	content := []byte(`---
+# The users.
+users:
+  # The user john.
+  john:
+    displayname: "John Doe"
+    password: "$argon2id$v=19$m=65536,t=3,p=2$BpLnfgDsc2WD8F2q$o/vzA4myCqZZ36bUGsDY//8mKUYNZZaR0t4MFFSs+iM"
+    email: john.doe@authelia.com
+    groups: [admins, dev]
+  harry:
+    displayname: "Harry Potter"
+    password: "$argon2id$v=19$m=65536,t=3,p=2$BpLnfgDsc2WD8F2q$o/vzA4myCqZZ36bUGsDY//8mKUYNZZaR0t4MFFSs+iM"
+    email: harry.potter@authelia.com
+  bob:
+    displayname: "Bob Dylan"
+    password: "$argon2id$v=19$m=65536,t=3,p=2$BpLnfgDsc2WD8F2q$o/vzA4myCqZZ36bUGsDY//8mKUYNZZaR0t4MFFSs+iM"
+...
+`)
+
+	expected := `# The users.
+users:
+  # The user john.
+  john:
+    displayname: "John Doe"
+    password: "$argon2id$v=19$m=65536,t=3,p=2$BpLnfgDsc2WD8F2q$o/vzA4myCqZZ36bUGsDY//8mKUYNZZaR0t4MFFSs+iM"
+    email: john.doe@authelia.com
+    groups: [admins]
+    disabled: true
+  bob:
+    displayname: "Bob Dylan"
+    password: "$argon2id$v=19$m=65536,t=3,p=2$BpLnfgDsc2WD8F2q$o/vzA4myCqZZ36bUGsDY//8mKUYNZZaR0t4MFFSs+iM"
+    groups:
+      - dev
+  fred:
+    password: $argon2id$v=19$m=65536,t=3,p=2$BpLnfgDsc2WD8F2q$o/vzA4myCqZZ36bUGsDY//8mKUYNZZaR0t4MFFSs+iM
+    displayname: Fred
+    email: fred@authelia.com
+    groups: []
+`
+
+	WithDatabase(content, func(path string) {
+		model := &DatabaseModel{}
+
+		require.NoError(t, model.Read(path))
+
+		john := model.Users["john"]
+		john.Groups = []string{"admins"}
+		john.Disabled = true
+		model.Users["john"] = john
+
+		bob := model.Users["bob"]
+		bob.Groups = []string{"dev"}
+		model.Users["bob"] = bob
+
+		delete(model.Users, "harry")
+
+		model.Users["fred"] = UserDetailsModel{
+			HashedPassword: john.HashedPassword,
+			DisplayName:    "Fred",
+			Email:          "fred@authelia.com",
+			Groups:         []string{},
+		}
+
+		require.NoError(t, model.Write(path))
+
+		actual, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(actual))
 	})
 }
 
@@ -0,0 +1,384 @@
+package authentication
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/logging"
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+// NewPooledLDAPClientFactory creates a new PooledLDAPClientFactory which dials the configured URLs using the provided
+// LDAPClientFactory.
+func NewPooledLDAPClientFactory(config schema.LDAPAuthenticationBackend, tlsConfig *tls.Config, factory LDAPClientFactory) (pool *PooledLDAPClientFactory) {
+	if factory == nil {
+		factory = NewProductionLDAPClientFactory()
+	}
+
+	pool = &PooledLDAPClientFactory{
+		factory:    factory,
+		tlsConfig:  tlsConfig,
+		roundRobin: config.URLStrategy == schema.LDAPURLStrategyRoundRobin,
+		log:        logging.Logger(),
+		clock:      &utils.RealClock{},
+	}
+
+	for _, raw := range append([]string{config.URL}, config.AdditionalURLs...) {
+		if utils.IsStringInSlice(raw, pool.urls) {
+			continue
+		}
+
+		var hostname string
+
+		if parsed, err := url.Parse(raw); err == nil {
+			hostname = parsed.Hostname()
+		}
+
+		pool.urls = append(pool.urls, raw)
+		pool.hostnames = append(pool.hostnames, hostname)
+	}
+
+	if config.Pooling.Enable && config.Pooling.Count > 0 {
+		pool.slots = make(chan struct{}, config.Pooling.Count)
+		pool.idleTimeout = config.Pooling.IdleTimeout
+		pool.healthCheckInterval = config.Pooling.HealthCheckInterval
+		pool.timeout = config.Pooling.Timeout
+	}
+
+	return pool
+}
+
+// PooledLDAPClientFactory is a LDAPClientFactory which keeps a bounded pool of idle connections to the configured URLs
+// and which fails over between the configured URLs when a URL can't be dialed. Connections to any other URL such as
+// referrals are dialed directly by the underlying LDAPClientFactory.
+type PooledLDAPClientFactory struct {
+	factory   LDAPClientFactory
+	tlsConfig *tls.Config
+
+	urls       []string
+	hostnames  []string
+	roundRobin bool
+	next       atomic.Uint32
+
+	slots               chan struct{}
+	idleTimeout         time.Duration
+	healthCheckInterval time.Duration
+	timeout             time.Duration
+
+	mu   sync.Mutex
+	idle []*pooledLDAPClient
+
+	log   *logrus.Logger
+	clock utils.Clock
+}
+
+// DialURL returns an idle pooled client or dials a new client when the address is one of the configured URLs,
+// otherwise it dials the address directly.
+func (f *PooledLDAPClientFactory) DialURL(addr string, opts ...ldap.DialOpt) (client LDAPClient, err error) {
+	if !utils.IsStringInSlice(addr, f.urls) {
+		return f.factory.DialURL(addr, opts...)
+	}
+
+	if err = f.acquire(); err != nil {
+		return nil, err
+	}
+
+	if pooled := f.getIdle(); pooled != nil {
+		return pooled, nil
+	}
+
+	if client, err = f.dial(opts...); err != nil {
+		f.release()
+
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// Idle returns the number of idle connections in the pool.
+func (f *PooledLDAPClientFactory) Idle() int {
+	f.mu.Lock()
+
+	defer f.mu.Unlock()
+
+	return len(f.idle)
+}
+
+func (f *PooledLDAPClientFactory) pooling() bool {
+	return f.slots != nil
+}
+
+func (f *PooledLDAPClientFactory) acquire() (err error) {
+	if !f.pooling() {
+		return nil
+	}
+
+	select {
+	case f.slots <- struct{}{}:
+		return nil
+	case <-f.clock.After(f.timeout):
+		return fmt.Errorf("timeout waiting for an available connection from the pool after %s", f.timeout)
+	}
+}
+
+func (f *PooledLDAPClientFactory) release() {
+	if !f.pooling() {
+		return
+	}
+
+	<-f.slots
+}
+
+func (f *PooledLDAPClientFactory) getIdle() (client *pooledLDAPClient) {
+	for {
+		f.mu.Lock()
+
+		if len(f.idle) == 0 {
+			f.mu.Unlock()
+
+			return nil
+		}
+
+		client = f.idle[len(f.idle)-1]
+		f.idle = f.idle[:len(f.idle)-1]
+
+		f.mu.Unlock()
+
+		idle := f.clock.Now().Sub(client.used)
+
+		switch {
+		case idle > f.idleTimeout:
+			f.log.Tracef("Closing pooled LDAP connection to '%s' as it has been idle for %s", f.urls[client.index], idle)
+		case idle > f.healthCheckInterval && !f.isHealthy(client):
+			f.log.Debugf("Closing pooled LDAP connection to '%s' as it failed the health check", f.urls[client.index])
+		default:
+			client.closed = false
+
+			return client
+		}
+
+		client.LDAPClient.Close()
+	}
+}
+
+func (f *PooledLDAPClientFactory) isHealthy(client *pooledLDAPClient) bool {
+	if closing, ok := client.LDAPClient.(interface{ IsClosing() bool }); ok && closing.IsClosing() {
+		return false
+	}
+
+	request := ldap.NewSearchRequest("", ldap.ScopeBaseObject, ldap.NeverDerefAliases,
+		1, 0, false, "(objectClass=*)", []string{ldapSupportedLDAPVersionAttribute}, nil)
+
+	if _, err := client.LDAPClient.Search(request); err != nil {
+		f.log.WithError(err).Tracef("Health check of pooled LDAP connection to '%s' failed", f.urls[client.index])
+
+		return false
+	}
+
+	return true
+}
+
+func (f *PooledLDAPClientFactory) order() (order []int) {
+	n := len(f.urls)
+
+	order = make([]int, n)
+
+	start := 0
+
+	if f.roundRobin {
+		start = int((f.next.Add(1) - 1) % uint32(n))
+	}
+
+	for i := 0; i < n; i++ {
+		order[i] = (start + i) % n
+	}
+
+	return order
+}
+
+func (f *PooledLDAPClientFactory) dial(opts ...ldap.DialOpt) (client *pooledLDAPClient, err error) {
+	var errs []error
+
+	for _, i := range f.order() {
+		dialOpts := opts
+
+		if config := f.tlsConfigFor(i, f.tlsConfig); config != f.tlsConfig {
+			dialOpts = append(append([]ldap.DialOpt{}, opts...), ldap.DialWithTLSConfig(config))
+		}
+
+		var c LDAPClient
+
+		if c, err = f.factory.DialURL(f.urls[i], dialOpts...); err != nil {
+			if len(f.urls) > 1 {
+				f.log.WithError(err).Warnf("Error occurred dialing LDAP URL '%s', trying the next URL", f.urls[i])
+			}
+
+			errs = append(errs, fmt.Errorf("error dialing '%s': %w", f.urls[i], err))
+
+			continue
+		}
+
+		return &pooledLDAPClient{LDAPClient: c, factory: f, index: i}, nil
+	}
+
+	if len(errs) == 1 {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("failed to dial all %d LDAP URLs: %w", len(errs), errors.Join(errs...))
+}
+
+// tlsConfigFor returns the *tls.Config for the URL at the index. If the configured server name is the hostname of the
+// primary URL it's substituted with the hostname of the URL at the index so certificate verification works for each
+// URL.
+func (f *PooledLDAPClientFactory) tlsConfigFor(index int, config *tls.Config) *tls.Config {
+	if config == nil || index == 0 || config.ServerName != f.hostnames[0] || f.hostnames[index] == f.hostnames[0] {
+		return config
+	}
+
+	config = config.Clone()
+	config.ServerName = f.hostnames[index]
+
+	return config
+}
+
+func (f *PooledLDAPClientFactory) put(client *pooledLDAPClient) {
+	if !f.pooling() {
+		client.LDAPClient.Close()
+
+		return
+	}
+
+	defer f.release()
+
+	if client.broken {
+		client.LDAPClient.Close()
+
+		return
+	}
+
+	now := f.clock.Now()
+
+	client.used = now
+
+	var expired []*pooledLDAPClient
+
+	f.mu.Lock()
+
+	idle := f.idle[:0]
+
+	for _, c := range f.idle {
+		if now.Sub(c.used) > f.idleTimeout {
+			expired = append(expired, c)
+		} else {
+			idle = append(idle, c)
+		}
+	}
+
+	f.idle = append(idle, client)
+
+	f.mu.Unlock()
+
+	for _, c := range expired {
+		c.LDAPClient.Close()
+	}
+}
+
+// pooledLDAPClient is a LDAPClient which returns itself to the pool when closed.
+type pooledLDAPClient struct {
+	LDAPClient
+
+	factory *PooledLDAPClientFactory
+	index   int
+	used    time.Time
+
+	tls    bool
+	broken bool
+	closed bool
+}
+
+// Close returns the client to the pool or closes the connection if it's broken or pooling is disabled.
+func (c *pooledLDAPClient) Close() {
+	if c.closed {
+		return
+	}
+
+	c.closed = true
+
+	c.factory.put(c)
+}
+
+// StartTLS performs the StartTLS operation unless it has already been performed on this connection.
+func (c *pooledLDAPClient) StartTLS(config *tls.Config) (err error) {
+	if c.tls {
+		return nil
+	}
+
+	if err = c.check(c.LDAPClient.StartTLS(c.factory.tlsConfigFor(c.index, config))); err == nil {
+		c.tls = true
+	}
+
+	return err
+}
+
+// Bind performs the Bind operation.
+func (c *pooledLDAPClient) Bind(username, password string) (err error) {
+	return c.check(c.LDAPClient.Bind(username, password))
+}
+
+// UnauthenticatedBind performs the UnauthenticatedBind operation.
+func (c *pooledLDAPClient) UnauthenticatedBind(username string) (err error) {
+	return c.check(c.LDAPClient.UnauthenticatedBind(username))
+}
+
+// Modify performs the Modify operation.
+func (c *pooledLDAPClient) Modify(modifyRequest *ldap.ModifyRequest) (err error) {
+	return c.check(c.LDAPClient.Modify(modifyRequest))
+}
+
+// PasswordModify performs the PasswordModify operation.
+func (c *pooledLDAPClient) PasswordModify(pwdModifyRequest *ldap.PasswordModifyRequest) (pwdModifyResult *ldap.PasswordModifyResult, err error) {
+	pwdModifyResult, err = c.LDAPClient.PasswordModify(pwdModifyRequest)
+
+	return pwdModifyResult, c.check(err)
+}
+
+// Search performs the Search operation.
+func (c *pooledLDAPClient) Search(searchRequest *ldap.SearchRequest) (searchResult *ldap.SearchResult, err error) {
+	searchResult, err = c.LDAPClient.Search(searchRequest)
+
+	return searchResult, c.check(err)
+}
+
+func (c *pooledLDAPClient) check(err error) error {
+	if err != nil && isLDAPConnectionError(err) {
+		c.broken = true
+	}
+
+	return err
+}
+
+func isLDAPConnectionError(err error) bool {
+	var e *ldap.Error
+
+	if errors.As(err, &e) {
+		switch e.ResultCode {
+		case ldap.ErrorNetwork, ldap.ErrorUnexpectedMessage, ldap.ErrorUnexpectedResponse:
+			return true
+		default:
+			return false
+		}
+	}
+
+	return true
+}
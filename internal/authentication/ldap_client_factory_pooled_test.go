@@ -0,0 +1,428 @@
+package authentication
+
+import (
+	"crypto/tls"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+func newTestPooledLDAPClientFactory(factory LDAPClientFactory, tlsConfig *tls.Config, count int, urls ...string) (pool *PooledLDAPClientFactory, clock *utils.TestingClock) {
+	config := schema.LDAPAuthenticationBackend{
+		URL:            urls[0],
+		AdditionalURLs: urls[1:],
+		URLStrategy:    schema.LDAPURLStrategyFailover,
+		Pooling: schema.LDAPAuthenticationBackendPooling{
+			Enable:              count > 0,
+			Count:               count,
+			IdleTimeout:         time.Minute * 5,
+			HealthCheckInterval: time.Second * 30,
+			Timeout:             time.Millisecond * 50,
+		},
+	}
+
+	clock = &utils.TestingClock{}
+	clock.Set(time.Unix(1700000000, 0))
+
+	pool = NewPooledLDAPClientFactory(config, tlsConfig, factory)
+	pool.clock = clock
+
+	return pool, clock
+}
+
+func TestPooledLDAPClientFactoryShouldReuseConnections(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPClientFactory(ctrl)
+	mockClient := NewMockLDAPClient(ctrl)
+
+	pool, _ := newTestPooledLDAPClientFactory(mockFactory, nil, 2, "ldap://127.0.0.1:389")
+
+	mockFactory.EXPECT().DialURL("ldap://127.0.0.1:389", gomock.Any()).Return(mockClient, nil).Times(1)
+	mockClient.EXPECT().Bind("cn=admin", "password").Return(nil).Times(2)
+
+	client, err := pool.DialURL("ldap://127.0.0.1:389")
+	require.NoError(t, err)
+	require.NoError(t, client.Bind("cn=admin", "password"))
+
+	client.Close()
+	client.Close()
+
+	assert.Equal(t, 1, pool.Idle())
+
+	client, err = pool.DialURL("ldap://127.0.0.1:389")
+	require.NoError(t, err)
+	require.NoError(t, client.Bind("cn=admin", "password"))
+
+	assert.Equal(t, 0, pool.Idle())
+
+	client.Close()
+
+	assert.Equal(t, 1, pool.Idle())
+}
+
+func TestPooledLDAPClientFactoryShouldBoundConnections(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPClientFactory(ctrl)
+	mockClient := NewMockLDAPClient(ctrl)
+
+	pool, _ := newTestPooledLDAPClientFactory(mockFactory, nil, 1, "ldap://127.0.0.1:389")
+
+	mockFactory.EXPECT().DialURL("ldap://127.0.0.1:389", gomock.Any()).Return(mockClient, nil).Times(1)
+
+	client, err := pool.DialURL("ldap://127.0.0.1:389")
+	require.NoError(t, err)
+
+	_, err = pool.DialURL("ldap://127.0.0.1:389")
+	assert.EqualError(t, err, "timeout waiting for an available connection from the pool after 50ms")
+
+	client.Close()
+
+	client, err = pool.DialURL("ldap://127.0.0.1:389")
+	require.NoError(t, err)
+
+	client.Close()
+}
+
+func TestPooledLDAPClientFactoryShouldReleaseSlotOnDialFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPClientFactory(ctrl)
+	mockClient := NewMockLDAPClient(ctrl)
+
+	pool, _ := newTestPooledLDAPClientFactory(mockFactory, nil, 1, "ldap://127.0.0.1:389")
+
+	gomock.InOrder(
+		mockFactory.EXPECT().DialURL("ldap://127.0.0.1:389", gomock.Any()).Return(nil, errors.New("connection refused")),
+		mockFactory.EXPECT().DialURL("ldap://127.0.0.1:389", gomock.Any()).Return(mockClient, nil),
+	)
+
+	_, err := pool.DialURL("ldap://127.0.0.1:389")
+	assert.EqualError(t, err, "connection refused")
+
+	client, err := pool.DialURL("ldap://127.0.0.1:389")
+	require.NoError(t, err)
+
+	client.Close()
+}
+
+func TestPooledLDAPClientFactoryShouldDiscardExpiredConnections(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPClientFactory(ctrl)
+	mockClientOne := NewMockLDAPClient(ctrl)
+	mockClientTwo := NewMockLDAPClient(ctrl)
+
+	pool, clock := newTestPooledLDAPClientFactory(mockFactory, nil, 2, "ldap://127.0.0.1:389")
+
+	gomock.InOrder(
+		mockFactory.EXPECT().DialURL("ldap://127.0.0.1:389", gomock.Any()).Return(mockClientOne, nil),
+		mockClientOne.EXPECT().Close(),
+		mockFactory.EXPECT().DialURL("ldap://127.0.0.1:389", gomock.Any()).Return(mockClientTwo, nil),
+	)
+
+	client, err := pool.DialURL("ldap://127.0.0.1:389")
+	require.NoError(t, err)
+
+	client.Close()
+
+	clock.Set(clock.Now().Add(time.Minute * 6))
+
+	client, err = pool.DialURL("ldap://127.0.0.1:389")
+	require.NoError(t, err)
+
+	assert.Equal(t, mockClientTwo, client.(*pooledLDAPClient).LDAPClient)
+}
+
+func TestPooledLDAPClientFactoryShouldHealthCheckConnections(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPClientFactory(ctrl)
+	mockClientOne := NewMockLDAPClient(ctrl)
+	mockClientTwo := NewMockLDAPClient(ctrl)
+
+	pool, clock := newTestPooledLDAPClientFactory(mockFactory, nil, 2, "ldap://127.0.0.1:389")
+
+	gomock.InOrder(
+		mockFactory.EXPECT().DialURL("ldap://127.0.0.1:389", gomock.Any()).Return(mockClientOne, nil),
+		mockClientOne.EXPECT().
+			Search(NewSearchRequestMatcher("(objectClass=*)")).
+			Return(&ldap.SearchResult{}, nil),
+		mockClientOne.EXPECT().
+			Search(NewSearchRequestMatcher("(objectClass=*)")).
+			Return(nil, ldap.NewError(ldap.ErrorNetwork, errors.New("connection reset"))),
+		mockClientOne.EXPECT().Close(),
+		mockFactory.EXPECT().DialURL("ldap://127.0.0.1:389", gomock.Any()).Return(mockClientTwo, nil),
+	)
+
+	client, err := pool.DialURL("ldap://127.0.0.1:389")
+	require.NoError(t, err)
+
+	client.Close()
+
+	clock.Set(clock.Now().Add(time.Minute))
+
+	client, err = pool.DialURL("ldap://127.0.0.1:389")
+	require.NoError(t, err)
+	assert.Equal(t, mockClientOne, client.(*pooledLDAPClient).LDAPClient)
+
+	client.Close()
+
+	clock.Set(clock.Now().Add(time.Minute))
+
+	client, err = pool.DialURL("ldap://127.0.0.1:389")
+	require.NoError(t, err)
+	assert.Equal(t, mockClientTwo, client.(*pooledLDAPClient).LDAPClient)
+}
+
+func TestPooledLDAPClientFactoryShouldDiscardBrokenConnections(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPClientFactory(ctrl)
+	mockClient := NewMockLDAPClient(ctrl)
+
+	pool, _ := newTestPooledLDAPClientFactory(mockFactory, nil, 2, "ldap://127.0.0.1:389")
+
+	gomock.InOrder(
+		mockFactory.EXPECT().DialURL("ldap://127.0.0.1:389", gomock.Any()).Return(mockClient, nil),
+		mockClient.EXPECT().Bind("cn=admin", "bad").Return(ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))),
+		mockClient.EXPECT().Search(gomock.Any()).Return(nil, ldap.NewError(ldap.ErrorNetwork, errors.New("connection reset"))),
+		mockClient.EXPECT().Close(),
+	)
+
+	client, err := pool.DialURL("ldap://127.0.0.1:389")
+	require.NoError(t, err)
+
+	assert.Error(t, client.Bind("cn=admin", "bad"))
+	assert.False(t, client.(*pooledLDAPClient).broken)
+
+	_, err = client.Search(&ldap.SearchRequest{})
+	assert.Error(t, err)
+	assert.True(t, client.(*pooledLDAPClient).broken)
+
+	client.Close()
+
+	assert.Equal(t, 0, pool.Idle())
+}
+
+func TestPooledLDAPClientFactoryShouldOnlyStartTLSOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPClientFactory(ctrl)
+	mockClient := NewMockLDAPClient(ctrl)
+
+	pool, _ := newTestPooledLDAPClientFactory(mockFactory, nil, 2, "ldap://127.0.0.1:389")
+
+	mockFactory.EXPECT().DialURL("ldap://127.0.0.1:389", gomock.Any()).Return(mockClient, nil)
+	mockClient.EXPECT().StartTLS(gomock.Any()).Return(nil).Times(1)
+
+	client, err := pool.DialURL("ldap://127.0.0.1:389")
+	require.NoError(t, err)
+	require.NoError(t, client.StartTLS(&tls.Config{}))
+
+	client.Close()
+
+	client, err = pool.DialURL("ldap://127.0.0.1:389")
+	require.NoError(t, err)
+	require.NoError(t, client.StartTLS(&tls.Config{}))
+
+	client.Close()
+}
+
+func TestPooledLDAPClientFactoryShouldFailover(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPClientFactory(ctrl)
+	mockClient := NewMockLDAPClient(ctrl)
+
+	pool, _ := newTestPooledLDAPClientFactory(mockFactory, nil, 0, "ldap://ldap1.example.com", "ldap://ldap2.example.com", "ldap://ldap3.example.com")
+
+	gomock.InOrder(
+		mockFactory.EXPECT().DialURL("ldap://ldap1.example.com", gomock.Any()).Return(nil, errors.New("connection refused")),
+		mockFactory.EXPECT().DialURL("ldap://ldap2.example.com", gomock.Any()).Return(mockClient, nil),
+		mockClient.EXPECT().Close(),
+		mockFactory.EXPECT().DialURL("ldap://ldap1.example.com", gomock.Any()).Return(nil, errors.New("connection refused")),
+		mockFactory.EXPECT().DialURL("ldap://ldap2.example.com", gomock.Any()).Return(nil, errors.New("connection refused")),
+		mockFactory.EXPECT().DialURL("ldap://ldap3.example.com", gomock.Any()).Return(nil, errors.New("no route to host")),
+	)
+
+	client, err := pool.DialURL("ldap://ldap1.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, 1, client.(*pooledLDAPClient).index)
+
+	client.Close()
+
+	assert.Equal(t, 0, pool.Idle())
+
+	_, err = pool.DialURL("ldap://ldap1.example.com")
+	assert.EqualError(t, err, "failed to dial all 3 LDAP URLs: error dialing 'ldap://ldap1.example.com': connection refused\nerror dialing 'ldap://ldap2.example.com': connection refused\nerror dialing 'ldap://ldap3.example.com': no route to host")
+}
+
+func TestPooledLDAPClientFactoryShouldRoundRobin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPClientFactory(ctrl)
+	mockClient := NewMockLDAPClient(ctrl)
+
+	pool, _ := newTestPooledLDAPClientFactory(mockFactory, nil, 0, "ldap://ldap1.example.com", "ldap://ldap2.example.com")
+	pool.roundRobin = true
+
+	gomock.InOrder(
+		mockFactory.EXPECT().DialURL("ldap://ldap1.example.com", gomock.Any()).Return(mockClient, nil),
+		mockFactory.EXPECT().DialURL("ldap://ldap2.example.com", gomock.Any()).Return(nil, errors.New("connection refused")),
+		mockFactory.EXPECT().DialURL("ldap://ldap1.example.com", gomock.Any()).Return(mockClient, nil),
+		mockFactory.EXPECT().DialURL("ldap://ldap1.example.com", gomock.Any()).Return(mockClient, nil),
+	)
+
+	for _, expected := range []int{0, 0, 0} {
+		client, err := pool.DialURL("ldap://ldap1.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, expected, client.(*pooledLDAPClient).index)
+	}
+}
+
+func TestPooledLDAPClientFactoryShouldPassThroughOtherURLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPClientFactory(ctrl)
+	mockClient := NewMockLDAPClient(ctrl)
+
+	pool, _ := newTestPooledLDAPClientFactory(mockFactory, nil, 1, "ldap://127.0.0.1:389")
+
+	mockFactory.EXPECT().DialURL("ldap://referral.example.com", gomock.Any()).Return(mockClient, nil).Times(2)
+
+	client, err := pool.DialURL("ldap://referral.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, mockClient, client)
+
+	_, err = pool.DialURL("ldap://referral.example.com")
+	require.NoError(t, err)
+}
+
+func TestPooledLDAPClientFactoryShouldSubstituteServerName(t *testing.T) {
+	pool, _ := newTestPooledLDAPClientFactory(nil, nil, 0, "ldaps://ldap1.example.com", "ldaps://ldap2.example.com:636")
+
+	config := &tls.Config{ServerName: "ldap1.example.com", MinVersion: tls.VersionTLS12}
+
+	assert.Equal(t, config, pool.tlsConfigFor(0, config))
+	assert.Equal(t, "ldap2.example.com", pool.tlsConfigFor(1, config).ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS12), pool.tlsConfigFor(1, config).MinVersion)
+	assert.Equal(t, "ldap1.example.com", config.ServerName)
+
+	custom := &tls.Config{ServerName: "ldap.example.com", MinVersion: tls.VersionTLS12}
+
+	assert.Equal(t, custom, pool.tlsConfigFor(1, custom))
+	assert.Nil(t, pool.tlsConfigFor(1, nil))
+}
+
+func TestShouldUsePooledLDAPClientFactoryWithAdditionalURLs(t *testing.T) {
+	provider := NewLDAPUserProviderWithFactory(schema.LDAPAuthenticationBackend{
+		URL: "ldap://127.0.0.1:389",
+	}, false, nil, nil)
+
+	assert.IsType(t, &ProductionLDAPClientFactory{}, provider.factory)
+
+	provider = NewLDAPUserProviderWithFactory(schema.LDAPAuthenticationBackend{
+		URL:            "ldap://127.0.0.1:389",
+		AdditionalURLs: []string{"ldap://127.0.0.2:389"},
+	}, false, nil, nil)
+
+	assert.IsType(t, &PooledLDAPClientFactory{}, provider.factory)
+}
+
+func TestPooledLDAPClientFactoryShouldCheckUserPasswordConcurrentlyWithSingleConnection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPClientFactory(ctrl)
+	mockClient := NewMockLDAPClient(ctrl)
+
+	provider := NewLDAPUserProviderWithFactory(
+		schema.LDAPAuthenticationBackend{
+			URL:                  "ldap://127.0.0.1:389",
+			User:                 "cn=admin,dc=example,dc=com",
+			Password:             "password",
+			UsernameAttribute:    "uid",
+			MailAttribute:        "mail",
+			DisplayNameAttribute: "displayName",
+			UsersFilter:          "uid={input}",
+			BaseDN:               "dc=example,dc=com",
+			Pooling: schema.LDAPAuthenticationBackendPooling{
+				Enable:              true,
+				Count:               1,
+				IdleTimeout:         time.Minute * 5,
+				HealthCheckInterval: time.Second * 30,
+				Timeout:             time.Second * 5,
+			},
+		},
+		false,
+		nil,
+		mockFactory)
+
+	require.IsType(t, &PooledLDAPClientFactory{}, provider.factory)
+
+	mockFactory.EXPECT().DialURL("ldap://127.0.0.1:389", gomock.Any()).Return(mockClient, nil).Times(1)
+	mockClient.EXPECT().Bind("cn=admin,dc=example,dc=com", "password").Return(nil).AnyTimes()
+	mockClient.EXPECT().Bind("uid=john,dc=example,dc=com", "password").Return(nil).AnyTimes()
+	mockClient.EXPECT().
+		Search(gomock.Any()).
+		Return(&ldap.SearchResult{
+			Entries: []*ldap.Entry{
+				{
+					DN:         "uid=john,dc=example,dc=com",
+					Attributes: []*ldap.EntryAttribute{{Name: "uid", Values: []string{"john"}}},
+				},
+			},
+		}, nil).
+		AnyTimes()
+
+	const n = 5
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, n)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			valid, err := provider.CheckUserPassword("john", "password")
+			if err == nil && !valid {
+				err = errors.New("password was not valid")
+			}
+
+			errs <- err
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 1, provider.factory.(*PooledLDAPClientFactory).Idle())
+}
@@ -45,6 +45,7 @@ type LDAPUserProvider struct {
 	groupsFilterReplacementInput    bool
 	groupsFilterReplacementUsername bool
 	groupsFilterReplacementDN       bool
+	groupsMemberOf                  bool
 }
 
 // NewLDAPUserProvider creates a new instance of LDAPUserProvider with the ProductionLDAPClientFactory.
@@ -74,6 +75,10 @@ func NewLDAPUserProviderWithFactory(config schema.LDAPAuthenticationBackend, dis
 		factory = NewProductionLDAPClientFactory()
 	}
 
+	if config.Pooling.Enable || len(config.AdditionalURLs) != 0 {
+		factory = NewPooledLDAPClientFactory(config, tlsConfig, factory)
+	}
+
 	provider = &LDAPUserProvider{
 		config:               config,
 		tlsConfig:            tlsConfig,
@@ -101,18 +106,29 @@ func (p *LDAPUserProvider) CheckUserPassword(username string, password string) (
 		return false, err
 	}
 
-	defer client.Close()
+	profile, err = p.getUserProfile(client, username)
 
-	if profile, err = p.getUserProfile(client, username); err != nil {
+	// The connection is closed before binding as the user so each check only requires a single pooled connection.
+	client.Close()
+
+	if err != nil {
 		return false, err
 	}
 
 	if clientUser, err = p.connectCustom(p.config.URL, profile.DN, password, p.config.StartTLS, p.dialOpts...); err != nil {
+		if p.isPasswordChangeRequiredBindError(err) {
+			return true, fmt.Errorf("the password of user '%s' has expired or must be changed: %w", username, ErrPasswordChangeRequired)
+		}
+
 		return false, fmt.Errorf("authentication failed. Cause: %w", err)
 	}
 
 	defer clientUser.Close()
 
+	if profile.PasswordChangeRequired {
+		return true, fmt.Errorf("the password of user '%s' has expired or must be changed: %w", username, ErrPasswordChangeRequired)
+	}
+
 	return true, nil
 }
 
@@ -133,39 +149,10 @@ func (p *LDAPUserProvider) GetDetails(username string) (details *UserDetails, er
 		return nil, err
 	}
 
-	var (
-		request *ldap.SearchRequest
-		result  *ldap.SearchResult
-	)
-
-	// Search for the users groups.
-	request = ldap.NewSearchRequest(
-		p.groupsBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
-		0, 0, false, p.resolveGroupsFilter(username, profile), p.groupsAttributes, nil,
-	)
+	var groups []string
 
-	p.log.
-		WithField("base_dn", request.BaseDN).
-		WithField("filter", request.Filter).
-		WithField("attr", request.Attributes).
-		WithField("scope", request.Scope).
-		WithField("deref", request.DerefAliases).
-		Trace("Performing group search")
-
-	if result, err = p.search(client, request); err != nil {
-		return nil, fmt.Errorf("unable to retrieve groups of user '%s'. Cause: %w", username, err)
-	}
-
-	groups := make([]string, 0)
-
-	for _, res := range result.Entries {
-		if len(res.Attributes) == 0 {
-			p.log.Warningf("No groups retrieved from LDAP for user %s", username)
-			break
-		}
-
-		// Append all values of the document. Normally there should be only one per document.
-		groups = append(groups, res.Attributes[0].Values...)
+	if groups, err = p.getUserGroups(client, username, profile); err != nil {
+		return nil, err
 	}
 
 	return &UserDetails{
@@ -388,6 +375,12 @@ func (p *LDAPUserProvider) getUserProfile(client LDAPClient, username string) (p
 		if attr.Name == p.config.DisplayNameAttribute {
 			userProfile.DisplayName = attr.Values[0]
 		}
+
+		if p.groupsMemberOf && attr.Name == p.config.MemberOfAttribute {
+			userProfile.MemberOf = attr.Values
+		}
+
+		p.parsePasswordExpiryAttribute(&userProfile, attr)
 	}
 
 	if userProfile.Username == "" {
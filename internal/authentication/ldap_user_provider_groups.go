@@ -0,0 +1,223 @@
+package authentication
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+)
+
+func (p *LDAPUserProvider) getUserGroups(client LDAPClient, username string, profile *ldapUserProfile) (groups []string, err error) {
+	switch p.config.GroupSearchMode {
+	case schema.LDAPGroupSearchModeInChain:
+		return p.getUserGroupsInChain(client, username, profile)
+	case schema.LDAPGroupSearchModeRecursive:
+		return p.getUserGroupsRecursive(client, username, profile)
+	case schema.LDAPGroupSearchModeMemberOf:
+		return p.getUserGroupsMemberOf(client, username, profile)
+	default:
+		return p.getUserGroupsFilter(client, username, profile)
+	}
+}
+
+// getUserGroupsFilter returns the groups which match the groups filter.
+func (p *LDAPUserProvider) getUserGroupsFilter(client LDAPClient, username string, profile *ldapUserProfile) (groups []string, err error) {
+	var entries []*ldap.Entry
+
+	if entries, err = p.searchGroups(client, username, p.resolveGroupsFilter(username, profile)); err != nil {
+		return nil, err
+	}
+
+	groups = make([]string, 0)
+
+	for _, entry := range entries {
+		if len(entry.Attributes) == 0 {
+			p.log.Warningf("No groups retrieved from LDAP for user %s", username)
+			break
+		}
+
+		// Append all values of the document. Normally there should be only one per document.
+		groups = append(groups, entry.Attributes[0].Values...)
+	}
+
+	return groups, nil
+}
+
+// getUserGroupsInChain returns the groups the user is a direct or nested member of using the
+// LDAP_MATCHING_RULE_IN_CHAIN matching rule which is evaluated by the directory server.
+func (p *LDAPUserProvider) getUserGroupsInChain(client LDAPClient, username string, profile *ldapUserProfile) (groups []string, err error) {
+	var entries []*ldap.Entry
+
+	filter := fmt.Sprintf("(%s:%s:=%s)", p.config.GroupMemberAttribute, ldapOIDMatchingRuleInChain, ldap.EscapeFilter(profile.DN))
+
+	if entries, err = p.searchGroups(client, username, filter); err != nil {
+		return nil, err
+	}
+
+	groups = make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		groups = append(groups, entry.GetAttributeValues(p.config.GroupNameAttribute)...)
+	}
+
+	return groups, nil
+}
+
+// getUserGroupsRecursive returns the groups which match the groups filter and then iteratively searches for the groups
+// which have those groups as a member until no new groups are found or the maximum depth is reached. Each group is only
+// searched for once which prevents membership cycles from being followed indefinitely.
+func (p *LDAPUserProvider) getUserGroupsRecursive(client LDAPClient, username string, profile *ldapUserProfile) (groups []string, err error) {
+	var entries []*ldap.Entry
+
+	if entries, err = p.searchGroups(client, username, p.resolveGroupsFilter(username, profile)); err != nil {
+		return nil, err
+	}
+
+	groups = make([]string, 0, len(entries))
+
+	visited := map[string]struct{}{}
+
+	var current []string
+
+	for depth := 0; ; depth++ {
+		var next []string
+
+		for _, entry := range entries {
+			key := strings.ToLower(entry.DN)
+
+			if _, ok := visited[key]; ok {
+				p.log.Tracef("Skipping group '%s' for user %s as it has already been retrieved", entry.DN, username)
+
+				continue
+			}
+
+			visited[key] = struct{}{}
+
+			groups = append(groups, entry.GetAttributeValues(p.config.GroupNameAttribute)...)
+			next = append(next, entry.DN)
+		}
+
+		if current = next; len(current) == 0 {
+			break
+		}
+
+		if depth >= p.config.GroupSearchMaxDepth {
+			p.log.Warnf("Maximum group search depth of %d reached while retrieving the groups of user %s, groups nested deeper than this are not included", p.config.GroupSearchMaxDepth, username)
+
+			break
+		}
+
+		if entries, err = p.searchGroups(client, username, p.resolveGroupsMemberFilter(current)); err != nil {
+			return nil, err
+		}
+	}
+
+	return groups, nil
+}
+
+// getUserGroupsMemberOf returns the groups listed in the member of attribute of the user. The group name is taken from
+// the relative distinguished name of the group if it's the group name attribute, otherwise it's retrieved from the
+// group entry.
+func (p *LDAPUserProvider) getUserGroupsMemberOf(client LDAPClient, username string, profile *ldapUserProfile) (groups []string, err error) {
+	var base *ldap.DN
+
+	if base, err = ldap.ParseDN(p.groupsBaseDN); err != nil {
+		return nil, fmt.Errorf("unable to parse the groups base distinguished name '%s': %w", p.groupsBaseDN, err)
+	}
+
+	groups = make([]string, 0, len(profile.MemberOf))
+
+	for _, value := range profile.MemberOf {
+		var dn *ldap.DN
+
+		if dn, err = ldap.ParseDN(value); err != nil {
+			p.log.WithError(err).Warnf("Skipping group '%s' of user %s as the distinguished name could not be parsed", value, username)
+
+			continue
+		}
+
+		if !base.AncestorOfFold(dn) {
+			p.log.Tracef("Skipping group '%s' of user %s as it's not within the groups base distinguished name '%s'", value, username, p.groupsBaseDN)
+
+			continue
+		}
+
+		if len(dn.RDNs[0].Attributes) == 1 && strings.EqualFold(dn.RDNs[0].Attributes[0].Type, p.config.GroupNameAttribute) {
+			groups = append(groups, dn.RDNs[0].Attributes[0].Value)
+
+			continue
+		}
+
+		var names []string
+
+		if names, err = p.getGroupNames(client, username, value); err != nil {
+			return nil, err
+		}
+
+		groups = append(groups, names...)
+	}
+
+	return groups, nil
+}
+
+func (p *LDAPUserProvider) getGroupNames(client LDAPClient, username, dn string) (names []string, err error) {
+	request := ldap.NewSearchRequest(
+		dn, ldap.ScopeBaseObject, ldap.NeverDerefAliases,
+		1, 0, false, ldapBaseObjectFilter, p.groupsAttributes, nil,
+	)
+
+	var result *ldap.SearchResult
+
+	if result, err = p.search(client, request); err != nil {
+		return nil, fmt.Errorf("unable to retrieve group '%s' of user '%s'. Cause: %w", dn, username, err)
+	}
+
+	if len(result.Entries) == 0 {
+		return nil, nil
+	}
+
+	return result.Entries[0].GetAttributeValues(p.config.GroupNameAttribute), nil
+}
+
+func (p *LDAPUserProvider) searchGroups(client LDAPClient, username, filter string) (entries []*ldap.Entry, err error) {
+	request := ldap.NewSearchRequest(
+		p.groupsBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		0, 0, false, filter, p.groupsAttributes, nil,
+	)
+
+	p.log.
+		WithField("base_dn", request.BaseDN).
+		WithField("filter", request.Filter).
+		WithField("attr", request.Attributes).
+		WithField("scope", request.Scope).
+		WithField("deref", request.DerefAliases).
+		Trace("Performing group search")
+
+	var result *ldap.SearchResult
+
+	if result, err = p.search(client, request); err != nil {
+		return nil, fmt.Errorf("unable to retrieve groups of user '%s'. Cause: %w", username, err)
+	}
+
+	return result.Entries, nil
+}
+
+func (p *LDAPUserProvider) resolveGroupsMemberFilter(dns []string) (filter string) {
+	if len(dns) == 1 {
+		return fmt.Sprintf("(%s=%s)", p.config.GroupMemberAttribute, ldap.EscapeFilter(dns[0]))
+	}
+
+	buf := &strings.Builder{}
+
+	buf.WriteString("(|")
+
+	for _, dn := range dns {
+		buf.WriteString(fmt.Sprintf("(%s=%s)", p.config.GroupMemberAttribute, ldap.EscapeFilter(dn)))
+	}
+
+	buf.WriteString(")")
+
+	return buf.String()
+}
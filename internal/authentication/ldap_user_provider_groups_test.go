@@ -0,0 +1,282 @@
+package authentication
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+)
+
+func newTestLDAPGroupSearchProvider(factory LDAPClientFactory, mode string) *LDAPUserProvider {
+	return NewLDAPUserProviderWithFactory(
+		schema.LDAPAuthenticationBackend{
+			URL:                  "ldap://127.0.0.1:389",
+			User:                 "cn=admin,dc=example,dc=com",
+			Password:             "password",
+			UsernameAttribute:    "uid",
+			MailAttribute:        "mail",
+			DisplayNameAttribute: "displayName",
+			MemberOfAttribute:    "memberOf",
+			UsersFilter:          "uid={input}",
+			AdditionalUsersDN:    "ou=users",
+			GroupsFilter:         "(member={dn})",
+			GroupNameAttribute:   "cn",
+			GroupMemberAttribute: "member",
+			GroupSearchMode:      mode,
+			GroupSearchMaxDepth:  2,
+			AdditionalGroupsDN:   "ou=groups",
+			BaseDN:               "dc=example,dc=com",
+		},
+		false,
+		nil,
+		factory)
+}
+
+func newTestLDAPGroupEntry(name string) *ldap.Entry {
+	return ldap.NewEntry("cn="+name+",ou=groups,dc=example,dc=com", map[string][]string{"cn": {name}})
+}
+
+func newTestLDAPGroupSearchResult(names ...string) *ldap.SearchResult {
+	result := &ldap.SearchResult{}
+
+	for _, name := range names {
+		result.Entries = append(result.Entries, newTestLDAPGroupEntry(name))
+	}
+
+	return result
+}
+
+func newTestLDAPUserSearchResult(memberOf ...string) *ldap.SearchResult {
+	attributes := map[string][]string{
+		"uid":         {"john"},
+		"mail":        {"john@example.com"},
+		"displayName": {"John Doe"},
+	}
+
+	if len(memberOf) != 0 {
+		attributes["memberOf"] = memberOf
+	}
+
+	return &ldap.SearchResult{
+		Entries: []*ldap.Entry{ldap.NewEntry("uid=john,ou=users,dc=example,dc=com", attributes)},
+	}
+}
+
+func TestShouldGetGroupsInChain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPClientFactory(ctrl)
+	mockClient := NewMockLDAPClient(ctrl)
+
+	provider := newTestLDAPGroupSearchProvider(mockFactory, schema.LDAPGroupSearchModeInChain)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().DialURL("ldap://127.0.0.1:389", gomock.Any()).Return(mockClient, nil),
+		mockClient.EXPECT().Bind("cn=admin,dc=example,dc=com", "password").Return(nil),
+		mockClient.EXPECT().
+			Search(NewExtendedSearchRequestMatcher("uid=john", "ou=users,dc=example,dc=com", ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, false, []string{"uid", "mail", "displayName"})).
+			Return(newTestLDAPUserSearchResult(), nil),
+		mockClient.EXPECT().
+			Search(NewExtendedSearchRequestMatcher("(member:1.2.840.113556.1.4.1941:=uid=john,ou=users,dc=example,dc=com)", "ou=groups,dc=example,dc=com", ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, false, []string{"cn"})).
+			Return(newTestLDAPGroupSearchResult("dev", "staff", "all"), nil),
+		mockClient.EXPECT().Close(),
+	)
+
+	details, err := provider.GetDetails("john")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"dev", "staff", "all"}, details.Groups)
+}
+
+func TestShouldGetGroupsRecursive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPClientFactory(ctrl)
+	mockClient := NewMockLDAPClient(ctrl)
+
+	provider := newTestLDAPGroupSearchProvider(mockFactory, schema.LDAPGroupSearchModeRecursive)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().DialURL("ldap://127.0.0.1:389", gomock.Any()).Return(mockClient, nil),
+		mockClient.EXPECT().Bind("cn=admin,dc=example,dc=com", "password").Return(nil),
+		mockClient.EXPECT().
+			Search(NewSearchRequestMatcher("uid=john")).
+			Return(newTestLDAPUserSearchResult(), nil),
+		mockClient.EXPECT().
+			Search(NewSearchRequestMatcher("(member=uid=john,ou=users,dc=example,dc=com)")).
+			Return(newTestLDAPGroupSearchResult("dev", "ops"), nil),
+		mockClient.EXPECT().
+			Search(NewSearchRequestMatcher("(|(member=cn=dev,ou=groups,dc=example,dc=com)(member=cn=ops,ou=groups,dc=example,dc=com))")).
+			Return(newTestLDAPGroupSearchResult("staff", "staff"), nil),
+		mockClient.EXPECT().
+			Search(NewSearchRequestMatcher("(member=cn=staff,ou=groups,dc=example,dc=com)")).
+			Return(&ldap.SearchResult{}, nil),
+		mockClient.EXPECT().Close(),
+	)
+
+	details, err := provider.GetDetails("john")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"dev", "ops", "staff"}, details.Groups)
+}
+
+func TestShouldGetGroupsRecursiveWithCycle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPClientFactory(ctrl)
+	mockClient := NewMockLDAPClient(ctrl)
+
+	provider := newTestLDAPGroupSearchProvider(mockFactory, schema.LDAPGroupSearchModeRecursive)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().DialURL("ldap://127.0.0.1:389", gomock.Any()).Return(mockClient, nil),
+		mockClient.EXPECT().Bind("cn=admin,dc=example,dc=com", "password").Return(nil),
+		mockClient.EXPECT().
+			Search(NewSearchRequestMatcher("uid=john")).
+			Return(newTestLDAPUserSearchResult(), nil),
+		mockClient.EXPECT().
+			Search(NewSearchRequestMatcher("(member=uid=john,ou=users,dc=example,dc=com)")).
+			Return(newTestLDAPGroupSearchResult("a"), nil),
+		mockClient.EXPECT().
+			Search(NewSearchRequestMatcher("(member=cn=a,ou=groups,dc=example,dc=com)")).
+			Return(newTestLDAPGroupSearchResult("b"), nil),
+		mockClient.EXPECT().
+			Search(NewSearchRequestMatcher("(member=cn=b,ou=groups,dc=example,dc=com)")).
+			Return(&ldap.SearchResult{Entries: []*ldap.Entry{
+				ldap.NewEntry("CN=A,OU=groups,DC=example,DC=com", map[string][]string{"cn": {"A"}}),
+			}}, nil),
+		mockClient.EXPECT().Close(),
+	)
+
+	details, err := provider.GetDetails("john")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a", "b"}, details.Groups)
+}
+
+func TestShouldGetGroupsRecursiveWithMaxDepth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPClientFactory(ctrl)
+	mockClient := NewMockLDAPClient(ctrl)
+
+	provider := newTestLDAPGroupSearchProvider(mockFactory, schema.LDAPGroupSearchModeRecursive)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().DialURL("ldap://127.0.0.1:389", gomock.Any()).Return(mockClient, nil),
+		mockClient.EXPECT().Bind("cn=admin,dc=example,dc=com", "password").Return(nil),
+		mockClient.EXPECT().
+			Search(NewSearchRequestMatcher("uid=john")).
+			Return(newTestLDAPUserSearchResult(), nil),
+		mockClient.EXPECT().
+			Search(NewSearchRequestMatcher("(member=uid=john,ou=users,dc=example,dc=com)")).
+			Return(newTestLDAPGroupSearchResult("a"), nil),
+		mockClient.EXPECT().
+			Search(NewSearchRequestMatcher("(member=cn=a,ou=groups,dc=example,dc=com)")).
+			Return(newTestLDAPGroupSearchResult("b"), nil),
+		mockClient.EXPECT().
+			Search(NewSearchRequestMatcher("(member=cn=b,ou=groups,dc=example,dc=com)")).
+			Return(newTestLDAPGroupSearchResult("c"), nil),
+		mockClient.EXPECT().Close(),
+	)
+
+	details, err := provider.GetDetails("john")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a", "b", "c"}, details.Groups)
+}
+
+func TestShouldNotGetGroupsRecursiveOnError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPClientFactory(ctrl)
+	mockClient := NewMockLDAPClient(ctrl)
+
+	provider := newTestLDAPGroupSearchProvider(mockFactory, schema.LDAPGroupSearchModeRecursive)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().DialURL("ldap://127.0.0.1:389", gomock.Any()).Return(mockClient, nil),
+		mockClient.EXPECT().Bind("cn=admin,dc=example,dc=com", "password").Return(nil),
+		mockClient.EXPECT().
+			Search(NewSearchRequestMatcher("uid=john")).
+			Return(newTestLDAPUserSearchResult(), nil),
+		mockClient.EXPECT().
+			Search(NewSearchRequestMatcher("(member=uid=john,ou=users,dc=example,dc=com)")).
+			Return(newTestLDAPGroupSearchResult("a"), nil),
+		mockClient.EXPECT().
+			Search(NewSearchRequestMatcher("(member=cn=a,ou=groups,dc=example,dc=com)")).
+			Return(nil, errors.New("size limit exceeded")),
+		mockClient.EXPECT().Close(),
+	)
+
+	details, err := provider.GetDetails("john")
+	assert.Nil(t, details)
+	assert.EqualError(t, err, "unable to retrieve groups of user 'john'. Cause: size limit exceeded")
+}
+
+func TestShouldGetGroupsMemberOf(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPClientFactory(ctrl)
+	mockClient := NewMockLDAPClient(ctrl)
+
+	provider := newTestLDAPGroupSearchProvider(mockFactory, schema.LDAPGroupSearchModeMemberOf)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().DialURL("ldap://127.0.0.1:389", gomock.Any()).Return(mockClient, nil),
+		mockClient.EXPECT().Bind("cn=admin,dc=example,dc=com", "password").Return(nil),
+		mockClient.EXPECT().
+			Search(NewExtendedSearchRequestMatcher("uid=john", "ou=users,dc=example,dc=com", ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, false, []string{"uid", "mail", "displayName", "memberOf"})).
+			Return(newTestLDAPUserSearchResult(
+				"cn=dev,ou=groups,dc=example,dc=com",
+				"CN=Staff,OU=Groups,DC=Example,DC=com",
+				"uid=admins,ou=groups,dc=example,dc=com",
+				"cn=other,ou=apps,dc=example,dc=com",
+				"not a dn",
+			), nil),
+		mockClient.EXPECT().
+			Search(NewExtendedSearchRequestMatcher("(objectClass=*)", "uid=admins,ou=groups,dc=example,dc=com", ldap.ScopeBaseObject, ldap.NeverDerefAliases, false, []string{"cn"})).
+			Return(newTestLDAPGroupSearchResult("administrators"), nil),
+		mockClient.EXPECT().Close(),
+	)
+
+	details, err := provider.GetDetails("john")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"dev", "Staff", "administrators"}, details.Groups)
+}
+
+func TestShouldGetNoGroupsMemberOf(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPClientFactory(ctrl)
+	mockClient := NewMockLDAPClient(ctrl)
+
+	provider := newTestLDAPGroupSearchProvider(mockFactory, schema.LDAPGroupSearchModeMemberOf)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().DialURL("ldap://127.0.0.1:389", gomock.Any()).Return(mockClient, nil),
+		mockClient.EXPECT().Bind("cn=admin,dc=example,dc=com", "password").Return(nil),
+		mockClient.EXPECT().
+			Search(NewSearchRequestMatcher("uid=john")).
+			Return(newTestLDAPUserSearchResult(), nil),
+		mockClient.EXPECT().Close(),
+	)
+
+	details, err := provider.GetDetails("john")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{}, details.Groups)
+}
@@ -0,0 +1,131 @@
+package authentication
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+// passwordExpiryAttributes returns the attributes which are used to determine if the password of a user has expired or
+// must be changed for the configured implementation.
+func (p *LDAPUserProvider) passwordExpiryAttributes() (attributes []string) {
+	switch p.config.Implementation {
+	case schema.LDAPImplementationActiveDirectory:
+		return []string{ldapAttributePwdLastSet, ldapAttributeMsDSUserPasswordExpiryTimeComputed}
+	default:
+		return []string{ldapAttributePwdChangedTime, ldapAttributePwdReset}
+	}
+}
+
+// parsePasswordExpiryAttribute updates the profile if the attribute indicates the password of the user has expired or
+// must be changed. Values which can't be parsed are logged and otherwise ignored.
+func (p *LDAPUserProvider) parsePasswordExpiryAttribute(profile *ldapUserProfile, attr *ldap.EntryAttribute) {
+	if !p.config.PasswordExpiry.Enable || len(attr.Values) == 0 {
+		return
+	}
+
+	var (
+		expired bool
+		err     error
+	)
+
+	value := attr.Values[0]
+
+	switch {
+	case strings.EqualFold(attr.Name, ldapAttributePwdLastSet):
+		expired, err = p.isPasswordExpiredMsftPwdLastSet(value)
+	case strings.EqualFold(attr.Name, ldapAttributeMsDSUserPasswordExpiryTimeComputed):
+		expired, err = p.isPasswordExpiredMsftExpiryTimeComputed(value)
+	case strings.EqualFold(attr.Name, ldapAttributePwdChangedTime):
+		expired, err = p.isPasswordExpiredPwdChangedTime(value)
+	case strings.EqualFold(attr.Name, ldapAttributePwdReset):
+		expired = strings.EqualFold(value, "TRUE")
+	default:
+		return
+	}
+
+	if err != nil {
+		p.log.WithError(err).Warnf("Error occurred parsing the '%s' attribute of user '%s' with value '%s'", attr.Name, profile.DN, value)
+
+		return
+	}
+
+	if expired {
+		profile.PasswordChangeRequired = true
+	}
+}
+
+// isPasswordExpiredMsftPwdLastSet returns true if the value of the pwdLastSet attribute indicates the password must be
+// changed or it was set before the configured max age.
+func (p *LDAPUserProvider) isPasswordExpiredMsftPwdLastSet(value string) (expired bool, err error) {
+	var epoch uint64
+
+	if epoch, err = strconv.ParseUint(value, 10, 64); err != nil {
+		return false, err
+	}
+
+	if epoch == 0 {
+		return true, nil
+	}
+
+	return p.isPasswordExpiredMaxAge(utils.MicrosoftNTEpochToTime(epoch)), nil
+}
+
+// isPasswordExpiredMsftExpiryTimeComputed returns true if the value of the msDS-UserPasswordExpiryTimeComputed
+// attribute indicates the password must be changed or the computed expiry time has passed.
+func (p *LDAPUserProvider) isPasswordExpiredMsftExpiryTimeComputed(value string) (expired bool, err error) {
+	var epoch uint64
+
+	if epoch, err = strconv.ParseUint(value, 10, 64); err != nil {
+		return false, err
+	}
+
+	switch epoch {
+	case 0:
+		return true, nil
+	case ldapMsftPasswordNeverExpires:
+		return false, nil
+	default:
+		return !p.clock.Now().Before(utils.MicrosoftNTEpochToTime(epoch)), nil
+	}
+}
+
+// isPasswordExpiredPwdChangedTime returns true if the value of the pwdChangedTime attribute is before the configured
+// max age.
+func (p *LDAPUserProvider) isPasswordExpiredPwdChangedTime(value string) (expired bool, err error) {
+	var changed time.Time
+
+	if changed, err = time.Parse(ldapGeneralizedTimeDateTimeParseFormat, value); err != nil {
+		return false, err
+	}
+
+	return p.isPasswordExpiredMaxAge(changed), nil
+}
+
+func (p *LDAPUserProvider) isPasswordExpiredMaxAge(changed time.Time) (expired bool) {
+	if p.config.PasswordExpiry.MaxAge <= 0 {
+		return false
+	}
+
+	return !p.clock.Now().Before(changed.Add(p.config.PasswordExpiry.MaxAge))
+}
+
+// isPasswordChangeRequiredBindError returns true if the error returned when binding as the user indicates the password
+// was correct but has expired or must be changed. Only Active Directory is known to return this information.
+func (p *LDAPUserProvider) isPasswordChangeRequiredBindError(err error) bool {
+	var e *ldap.Error
+
+	if !p.config.PasswordExpiry.Enable || !errors.As(err, &e) || e.ResultCode != ldap.LDAPResultInvalidCredentials {
+		return false
+	}
+
+	message := e.Error()
+
+	return strings.Contains(message, ldapMsftErrDataPasswordExpired) || strings.Contains(message, ldapMsftErrDataPasswordMustChange)
+}
@@ -0,0 +1,222 @@
+package authentication
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/logging"
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+func TestLDAPUserProviderPasswordExpiryAttributes(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+	nt := func(t time.Time) string {
+		return strconv.FormatUint(utils.UnixNanoTimeToMicrosoftNTEpoch(t.UnixNano()), 10)
+	}
+
+	testCases := []struct {
+		name     string
+		config   schema.AuthenticationBackendPasswordExpiry
+		attr     *ldap.EntryAttribute
+		expected bool
+	}{
+		{"ShouldIgnoreWhenDisabled", schema.AuthenticationBackendPasswordExpiry{}, &ldap.EntryAttribute{Name: "pwdReset", Values: []string{"TRUE"}}, false},
+		{"ShouldIgnoreNoValues", schema.AuthenticationBackendPasswordExpiry{Enable: true}, &ldap.EntryAttribute{Name: "pwdReset"}, false},
+		{"ShouldIgnoreOtherAttributes", schema.AuthenticationBackendPasswordExpiry{Enable: true}, &ldap.EntryAttribute{Name: "mail", Values: []string{"0"}}, false},
+		{"ShouldRequireChangePwdReset", schema.AuthenticationBackendPasswordExpiry{Enable: true}, &ldap.EntryAttribute{Name: "pwdReset", Values: []string{"TRUE"}}, true},
+		{"ShouldNotRequireChangePwdResetFalse", schema.AuthenticationBackendPasswordExpiry{Enable: true}, &ldap.EntryAttribute{Name: "pwdReset", Values: []string{"FALSE"}}, false},
+		{"ShouldRequireChangePwdChangedTimeExpired", schema.AuthenticationBackendPasswordExpiry{Enable: true, MaxAge: time.Hour}, &ldap.EntryAttribute{Name: "pwdChangedTime", Values: []string{now.Add(-time.Hour).Format("20060102150405Z")}}, true},
+		{"ShouldNotRequireChangePwdChangedTimeNotExpired", schema.AuthenticationBackendPasswordExpiry{Enable: true, MaxAge: time.Hour}, &ldap.EntryAttribute{Name: "pwdChangedTime", Values: []string{now.Add(-time.Minute).Format("20060102150405.0Z")}}, false},
+		{"ShouldNotRequireChangePwdChangedTimeNoMaxAge", schema.AuthenticationBackendPasswordExpiry{Enable: true}, &ldap.EntryAttribute{Name: "pwdChangedTime", Values: []string{"20000101000000Z"}}, false},
+		{"ShouldNotRequireChangePwdChangedTimeInvalid", schema.AuthenticationBackendPasswordExpiry{Enable: true, MaxAge: time.Hour}, &ldap.EntryAttribute{Name: "pwdChangedTime", Values: []string{"invalid"}}, false},
+		{"ShouldRequireChangePwdLastSetZero", schema.AuthenticationBackendPasswordExpiry{Enable: true}, &ldap.EntryAttribute{Name: "pwdLastSet", Values: []string{"0"}}, true},
+		{"ShouldRequireChangePwdLastSetExpired", schema.AuthenticationBackendPasswordExpiry{Enable: true, MaxAge: time.Hour}, &ldap.EntryAttribute{Name: "pwdLastSet", Values: []string{nt(now.Add(-time.Hour * 2))}}, true},
+		{"ShouldNotRequireChangePwdLastSetNotExpired", schema.AuthenticationBackendPasswordExpiry{Enable: true, MaxAge: time.Hour}, &ldap.EntryAttribute{Name: "pwdLastSet", Values: []string{nt(now.Add(-time.Minute))}}, false},
+		{"ShouldNotRequireChangePwdLastSetInvalid", schema.AuthenticationBackendPasswordExpiry{Enable: true}, &ldap.EntryAttribute{Name: "pwdLastSet", Values: []string{"abc"}}, false},
+		{"ShouldRequireChangeExpiryTimeComputedZero", schema.AuthenticationBackendPasswordExpiry{Enable: true}, &ldap.EntryAttribute{Name: "msDS-UserPasswordExpiryTimeComputed", Values: []string{"0"}}, true},
+		{"ShouldRequireChangeExpiryTimeComputedExpired", schema.AuthenticationBackendPasswordExpiry{Enable: true}, &ldap.EntryAttribute{Name: "msDS-UserPasswordExpiryTimeComputed", Values: []string{nt(now.Add(-time.Second))}}, true},
+		{"ShouldNotRequireChangeExpiryTimeComputedNotExpired", schema.AuthenticationBackendPasswordExpiry{Enable: true}, &ldap.EntryAttribute{Name: "msDS-UserPasswordExpiryTimeComputed", Values: []string{nt(now.Add(time.Hour))}}, false},
+		{"ShouldNotRequireChangeExpiryTimeComputedNeverExpires", schema.AuthenticationBackendPasswordExpiry{Enable: true}, &ldap.EntryAttribute{Name: "msDS-UserPasswordExpiryTimeComputed", Values: []string{"9223372036854775807"}}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := &utils.TestingClock{}
+			clock.Set(now)
+
+			provider := &LDAPUserProvider{
+				config: schema.LDAPAuthenticationBackend{PasswordExpiry: tc.config},
+				clock:  clock,
+				log:    logging.Logger(),
+			}
+
+			profile := &ldapUserProfile{DN: "uid=john,dc=example,dc=com"}
+
+			provider.parsePasswordExpiryAttribute(profile, tc.attr)
+
+			assert.Equal(t, tc.expected, profile.PasswordChangeRequired)
+		})
+	}
+}
+
+func TestLDAPUserProviderShouldRequestPasswordExpiryAttributes(t *testing.T) {
+	testCases := []struct {
+		name           string
+		implementation string
+		expected       []string
+	}{
+		{"ShouldRequestActiveDirectoryAttributes", schema.LDAPImplementationActiveDirectory, []string{"pwdLastSet", "msDS-UserPasswordExpiryTimeComputed"}},
+		{"ShouldRequestPasswordPolicyAttributes", schema.LDAPImplementationCustom, []string{"pwdChangedTime", "pwdReset"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewLDAPUserProviderWithFactory(
+				schema.LDAPAuthenticationBackend{
+					Implementation:       tc.implementation,
+					URL:                  "ldap://127.0.0.1:389",
+					UsernameAttribute:    "uid",
+					MailAttribute:        "mail",
+					DisplayNameAttribute: "displayName",
+					UsersFilter:          "uid={input}",
+					BaseDN:               "dc=example,dc=com",
+					PasswordExpiry:       schema.AuthenticationBackendPasswordExpiry{Enable: true},
+				},
+				false,
+				nil,
+				nil)
+
+			for _, attribute := range tc.expected {
+				assert.Contains(t, provider.usersAttributes, attribute)
+			}
+		})
+	}
+}
+
+func TestLDAPUserProviderCheckUserPasswordShouldRequirePasswordChange(t *testing.T) {
+	testCases := []struct {
+		name       string
+		enable     bool
+		attributes []*ldap.EntryAttribute
+		bind       error
+		valid      bool
+		err        string
+	}{
+		{
+			"ShouldRequireChangeWhenPwdReset",
+			true,
+			[]*ldap.EntryAttribute{{Name: "pwdReset", Values: []string{"TRUE"}}},
+			nil,
+			true,
+			"the password of user 'john' has expired or must be changed: password change required",
+		},
+		{
+			"ShouldNotRequireChangeWhenPwdResetAndDisabled",
+			false,
+			[]*ldap.EntryAttribute{{Name: "pwdReset", Values: []string{"TRUE"}}},
+			nil,
+			true,
+			"",
+		},
+		{
+			"ShouldRequireChangeWhenBindErrorMustChange",
+			true,
+			nil,
+			ldap.NewError(ldap.LDAPResultInvalidCredentials, fmt.Errorf("80090308: LdapErr: DSID-0C09044E, comment: AcceptSecurityContext error, data 773, v4563")),
+			true,
+			"the password of user 'john' has expired or must be changed: password change required",
+		},
+		{
+			"ShouldRequireChangeWhenBindErrorExpired",
+			true,
+			nil,
+			ldap.NewError(ldap.LDAPResultInvalidCredentials, fmt.Errorf("80090308: LdapErr: DSID-0C09044E, comment: AcceptSecurityContext error, data 532, v4563")),
+			true,
+			"the password of user 'john' has expired or must be changed: password change required",
+		},
+		{
+			"ShouldNotRequireChangeWhenBindErrorInvalidCredentials",
+			true,
+			nil,
+			ldap.NewError(ldap.LDAPResultInvalidCredentials, fmt.Errorf("80090308: LdapErr: DSID-0C09044E, comment: AcceptSecurityContext error, data 52e, v4563")),
+			false,
+			"authentication failed. Cause: bind failed with error: LDAP Result Code 49 \"Invalid Credentials\": 80090308: LdapErr: DSID-0C09044E, comment: AcceptSecurityContext error, data 52e, v4563",
+		},
+		{
+			"ShouldNotRequireChangeWhenBindErrorExpiredAndDisabled",
+			false,
+			nil,
+			ldap.NewError(ldap.LDAPResultInvalidCredentials, fmt.Errorf("80090308: LdapErr: DSID-0C09044E, comment: AcceptSecurityContext error, data 532, v4563")),
+			false,
+			"authentication failed. Cause: bind failed with error: LDAP Result Code 49 \"Invalid Credentials\": 80090308: LdapErr: DSID-0C09044E, comment: AcceptSecurityContext error, data 532, v4563",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockFactory := NewMockLDAPClientFactory(ctrl)
+			mockClient := NewMockLDAPClient(ctrl)
+
+			provider := NewLDAPUserProviderWithFactory(
+				schema.LDAPAuthenticationBackend{
+					URL:                  "ldap://127.0.0.1:389",
+					User:                 "cn=admin,dc=example,dc=com",
+					Password:             "password",
+					UsernameAttribute:    "uid",
+					MailAttribute:        "mail",
+					DisplayNameAttribute: "displayName",
+					UsersFilter:          "uid={input}",
+					BaseDN:               "dc=example,dc=com",
+					PasswordExpiry:       schema.AuthenticationBackendPasswordExpiry{Enable: tc.enable},
+				},
+				false,
+				nil,
+				mockFactory)
+
+			attributes := append([]*ldap.EntryAttribute{{Name: "uid", Values: []string{"john"}}}, tc.attributes...)
+
+			gomock.InOrder(
+				mockFactory.EXPECT().
+					DialURL(gomock.Eq("ldap://127.0.0.1:389"), gomock.Any()).
+					Return(mockClient, nil),
+				mockClient.EXPECT().
+					Bind(gomock.Eq("cn=admin,dc=example,dc=com"), gomock.Eq("password")).
+					Return(nil),
+				mockClient.EXPECT().
+					Search(gomock.Any()).
+					Return(&ldap.SearchResult{
+						Entries: []*ldap.Entry{{DN: "uid=john,dc=example,dc=com", Attributes: attributes}},
+					}, nil),
+				mockClient.EXPECT().Close(),
+				mockFactory.EXPECT().
+					DialURL(gomock.Eq("ldap://127.0.0.1:389"), gomock.Any()).
+					Return(mockClient, nil),
+				mockClient.EXPECT().
+					Bind(gomock.Eq("uid=john,dc=example,dc=com"), gomock.Eq("password")).
+					Return(tc.bind),
+				mockClient.EXPECT().Close(),
+			)
+
+			valid, err := provider.CheckUserPassword("john", "password")
+
+			assert.Equal(t, tc.valid, valid)
+
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
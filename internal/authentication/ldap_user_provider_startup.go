@@ -108,6 +108,22 @@ func (p *LDAPUserProvider) parseDynamicUsersConfiguration() {
 		p.usersAttributes = append(p.usersAttributes, p.config.DisplayNameAttribute)
 	}
 
+	if p.config.GroupSearchMode == schema.LDAPGroupSearchModeMemberOf {
+		p.groupsMemberOf = true
+
+		if !utils.IsStringInSlice(p.config.MemberOfAttribute, p.usersAttributes) {
+			p.usersAttributes = append(p.usersAttributes, p.config.MemberOfAttribute)
+		}
+	}
+
+	if p.config.PasswordExpiry.Enable {
+		for _, attribute := range p.passwordExpiryAttributes() {
+			if !utils.IsStringInSlice(attribute, p.usersAttributes) {
+				p.usersAttributes = append(p.usersAttributes, attribute)
+			}
+		}
+	}
+
 	if p.config.AdditionalUsersDN != "" {
 		p.usersBaseDN = p.config.AdditionalUsersDN + "," + p.config.BaseDN
 	} else {
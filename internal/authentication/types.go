@@ -60,6 +60,9 @@ type ldapUserProfile struct {
 	Emails      []string
 	DisplayName string
 	Username    string
+	MemberOf    []string
+
+	PasswordChangeRequired bool
 }
 
 // LDAPSupportedFeatures represents features which a server may support which are implemented in code.
@@ -13,3 +13,12 @@ type UserProvider interface {
 	GetDetails(username string) (details *UserDetails, err error)
 	UpdatePassword(username string, newPassword string) (err error)
 }
+
+// MetricsRecorder represents the methods used to record authentication backend metrics.
+type MetricsRecorder interface {
+	RecordAuthenticationBackendCache(result string)
+}
+
+type reloader interface {
+	Reload() (reloaded bool, err error)
+}
@@ -0,0 +1,295 @@
+package authentication
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/logging"
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+// NewCachedUserProvider creates a new CachedUserProvider which caches the user details retrieved from the provided
+// UserProvider. The metrics recorder is optional.
+func NewCachedUserProvider(provider UserProvider, config schema.AuthenticationBackendCache, metrics MetricsRecorder) *CachedUserProvider {
+	return &CachedUserProvider{
+		provider:          provider,
+		lifespan:          config.Lifespan,
+		negativeLifespan:  config.NegativeLifespan,
+		backgroundRefresh: config.BackgroundRefresh,
+		metrics:           metrics,
+		entries:           map[string]map[string]*cachedUserDetails{},
+		refreshing:        map[string]struct{}{},
+		log:               logging.Logger(),
+		clock:             &utils.RealClock{},
+	}
+}
+
+// CachedUserProvider is a UserProvider which caches the results of GetDetails from another UserProvider. Users which
+// don't exist are cached for the negative lifespan. If a background refresh is configured, user details which are
+// older than the background refresh duration are returned from the cache and refreshed in the background. Cached user
+// details are grouped by the lowercase username so invalidating a user removes the cached user details for every case
+// variation of the username, however the user details are only returned for the exact username as the UserProvider may
+// be case-sensitive.
+type CachedUserProvider struct {
+	provider UserProvider
+
+	lifespan          time.Duration
+	negativeLifespan  time.Duration
+	backgroundRefresh time.Duration
+
+	metrics MetricsRecorder
+
+	mu         sync.Mutex
+	entries    map[string]map[string]*cachedUserDetails
+	refreshing map[string]struct{}
+	pruned     time.Time
+
+	// generation is incremented every time cached user details are invalidated. User details retrieved from the
+	// underlying UserProvider are only cached if the generation didn't change while they were being retrieved, which
+	// prevents stale user details from replacing an invalidation which happened during the retrieval.
+	generation uint64
+
+	log   *logrus.Logger
+	clock utils.Clock
+}
+
+type cachedUserDetails struct {
+	details *UserDetails
+	created time.Time
+	expires time.Time
+}
+
+// StartupCheck implements the startup check provider interface.
+func (p *CachedUserProvider) StartupCheck() (err error) {
+	return p.provider.StartupCheck()
+}
+
+// CheckUserPassword checks the password of the user with the underlying UserProvider. Passwords are never cached. If
+// the password is valid any cached result indicating the user does not exist is removed.
+func (p *CachedUserProvider) CheckUserPassword(username string, password string) (valid bool, err error) {
+	if valid, err = p.provider.CheckUserPassword(username, password); valid {
+		p.mu.Lock()
+
+		if entry, ok := p.get(username); ok && entry.details == nil {
+			p.delete(username)
+		}
+
+		p.mu.Unlock()
+	}
+
+	return valid, err
+}
+
+// GetDetails returns the user details from the cache or retrieves them from the underlying UserProvider.
+func (p *CachedUserProvider) GetDetails(username string) (details *UserDetails, err error) {
+	now := p.clock.Now()
+
+	p.mu.Lock()
+
+	if entry, ok := p.get(username); ok && now.Before(entry.expires) {
+		if entry.details == nil {
+			p.mu.Unlock()
+
+			p.record(CacheResultNegativeHit)
+
+			return nil, ErrUserNotFound
+		}
+
+		if p.backgroundRefresh > 0 && now.Sub(entry.created) >= p.backgroundRefresh {
+			if _, ok = p.refreshing[username]; !ok {
+				p.refreshing[username] = struct{}{}
+
+				go p.refresh(username, p.generation)
+			}
+		}
+
+		details = entry.details.clone()
+
+		p.mu.Unlock()
+
+		p.record(CacheResultHit)
+
+		return details, nil
+	}
+
+	generation := p.generation
+
+	p.mu.Unlock()
+
+	p.record(CacheResultMiss)
+
+	if details, err = p.provider.GetDetails(username); err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			p.set(username, nil, generation)
+		}
+
+		return nil, err
+	}
+
+	p.set(username, details, generation)
+
+	return details.clone(), nil
+}
+
+// UpdatePassword updates the password of the user with the underlying UserProvider and removes the cached user details.
+func (p *CachedUserProvider) UpdatePassword(username string, newPassword string) (err error) {
+	err = p.provider.UpdatePassword(username, newPassword)
+
+	p.Invalidate(username)
+
+	return err
+}
+
+// Reload reloads the underlying UserProvider if it supports reloading and removes all cached user details if it was
+// reloaded.
+func (p *CachedUserProvider) Reload() (reloaded bool, err error) {
+	provider, ok := p.provider.(reloader)
+	if !ok {
+		return false, nil
+	}
+
+	if reloaded, err = provider.Reload(); reloaded {
+		p.mu.Lock()
+
+		p.entries = map[string]map[string]*cachedUserDetails{}
+		p.generation++
+
+		p.mu.Unlock()
+	}
+
+	return reloaded, err
+}
+
+// Invalidate removes the cached user details for every case variation of the username.
+func (p *CachedUserProvider) Invalidate(username string) {
+	p.mu.Lock()
+
+	delete(p.entries, strings.ToLower(username))
+	p.generation++
+
+	p.mu.Unlock()
+}
+
+func (p *CachedUserProvider) refresh(username string, generation uint64) {
+	details, err := p.provider.GetDetails(username)
+
+	p.mu.Lock()
+
+	delete(p.refreshing, username)
+
+	p.mu.Unlock()
+
+	switch {
+	case err == nil:
+		p.set(username, details, generation)
+	case errors.Is(err, ErrUserNotFound):
+		p.set(username, nil, generation)
+	default:
+		p.log.WithError(err).Errorf("Error occurred refreshing the cached details of user '%s' in the background", username)
+	}
+}
+
+func (p *CachedUserProvider) set(username string, details *UserDetails, generation uint64) {
+	lifespan := p.lifespan
+
+	if details == nil {
+		if lifespan = p.negativeLifespan; lifespan <= 0 {
+			return
+		}
+	}
+
+	now := p.clock.Now()
+
+	p.mu.Lock()
+
+	defer p.mu.Unlock()
+
+	if generation != p.generation {
+		return
+	}
+
+	if now.Sub(p.pruned) >= p.lifespan {
+		for key, entries := range p.entries {
+			for name, entry := range entries {
+				if !now.Before(entry.expires) {
+					delete(entries, name)
+				}
+			}
+
+			if len(entries) == 0 {
+				delete(p.entries, key)
+			}
+		}
+
+		p.pruned = now
+	}
+
+	key := strings.ToLower(username)
+
+	entries, ok := p.entries[key]
+	if !ok {
+		entries = map[string]*cachedUserDetails{}
+
+		p.entries[key] = entries
+	}
+
+	entries[username] = &cachedUserDetails{
+		details: details.clone(),
+		created: now,
+		expires: now.Add(lifespan),
+	}
+}
+
+// get returns the cached user details for the exact username. The mutex must be locked by the caller.
+func (p *CachedUserProvider) get(username string) (entry *cachedUserDetails, ok bool) {
+	entry, ok = p.entries[strings.ToLower(username)][username]
+
+	return entry, ok
+}
+
+// delete removes the cached user details for the exact username. The mutex must be locked by the caller.
+func (p *CachedUserProvider) delete(username string) {
+	key := strings.ToLower(username)
+
+	if entries, ok := p.entries[key]; ok {
+		delete(entries, username)
+
+		if len(entries) == 0 {
+			delete(p.entries, key)
+		}
+	}
+}
+
+func (p *CachedUserProvider) record(result string) {
+	if p.metrics == nil {
+		return
+	}
+
+	p.metrics.RecordAuthenticationBackendCache(result)
+}
+
+func (d *UserDetails) clone() *UserDetails {
+	if d == nil {
+		return nil
+	}
+
+	return &UserDetails{
+		Username:    d.Username,
+		DisplayName: d.DisplayName,
+		Emails:      cloneStrings(d.Emails),
+		Groups:      cloneStrings(d.Groups),
+	}
+}
+
+func cloneStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+
+	return append(make([]string, 0, len(values)), values...)
+}
@@ -0,0 +1,326 @@
+package authentication
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+type testUserProvider struct {
+	mu      sync.Mutex
+	users   map[string]*UserDetails
+	err     error
+	calls   int
+	updated []string
+	reloads int
+	block   chan struct{}
+}
+
+func (p *testUserProvider) StartupCheck() (err error) {
+	return p.err
+}
+
+func (p *testUserProvider) CheckUserPassword(username string, password string) (valid bool, err error) {
+	p.mu.Lock()
+
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return false, p.err
+	}
+
+	if _, ok := p.users[username]; !ok {
+		return false, ErrUserNotFound
+	}
+
+	return password == "password", nil
+}
+
+func (p *testUserProvider) GetDetails(username string) (details *UserDetails, err error) {
+	if p.block != nil {
+		<-p.block
+	}
+
+	p.mu.Lock()
+
+	defer p.mu.Unlock()
+
+	p.calls++
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	if details = p.users[username]; details == nil {
+		return nil, ErrUserNotFound
+	}
+
+	return details.clone(), nil
+}
+
+func (p *testUserProvider) UpdatePassword(username string, newPassword string) (err error) {
+	p.mu.Lock()
+
+	defer p.mu.Unlock()
+
+	if _, ok := p.users[username]; !ok {
+		return ErrUserNotFound
+	}
+
+	p.updated = append(p.updated, username)
+
+	return nil
+}
+
+func (p *testUserProvider) Reload() (reloaded bool, err error) {
+	p.reloads++
+
+	return true, nil
+}
+
+func (p *testUserProvider) getCalls() int {
+	p.mu.Lock()
+
+	defer p.mu.Unlock()
+
+	return p.calls
+}
+
+func (p *testUserProvider) setUser(username string, details *UserDetails) {
+	p.mu.Lock()
+
+	defer p.mu.Unlock()
+
+	if details == nil {
+		delete(p.users, username)
+	} else {
+		p.users[username] = details
+	}
+}
+
+type testCacheMetricsRecorder struct {
+	mu      sync.Mutex
+	results map[string]int
+}
+
+func (r *testCacheMetricsRecorder) RecordAuthenticationBackendCache(result string) {
+	r.mu.Lock()
+
+	defer r.mu.Unlock()
+
+	r.results[result]++
+}
+
+func newTestCachedUserProvider(config schema.AuthenticationBackendCache) (provider *CachedUserProvider, backend *testUserProvider, metrics *testCacheMetricsRecorder, clock *utils.TestingClock) {
+	backend = &testUserProvider{
+		users: map[string]*UserDetails{
+			"john": {Username: "john", DisplayName: "John Doe", Emails: []string{"john@example.com"}, Groups: []string{"admins"}},
+		},
+	}
+
+	metrics = &testCacheMetricsRecorder{results: map[string]int{}}
+
+	clock = &utils.TestingClock{}
+	clock.Set(time.Unix(1700000000, 0))
+
+	provider = NewCachedUserProvider(backend, config, metrics)
+	provider.clock = clock
+
+	return provider, backend, metrics, clock
+}
+
+func TestCachedUserProviderShouldCacheDetails(t *testing.T) {
+	provider, backend, metrics, clock := newTestCachedUserProvider(schema.AuthenticationBackendCache{Lifespan: time.Minute, NegativeLifespan: time.Second * 10})
+
+	details, err := provider.GetDetails("john")
+	require.NoError(t, err)
+	assert.Equal(t, "John Doe", details.DisplayName)
+
+	details.Groups[0] = "modified"
+
+	details, err = provider.GetDetails("john")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admins"}, details.Groups)
+	assert.Equal(t, 1, backend.getCalls())
+
+	clock.Set(clock.Now().Add(time.Minute))
+
+	_, err = provider.GetDetails("john")
+	require.NoError(t, err)
+	assert.Equal(t, 2, backend.getCalls())
+
+	assert.Equal(t, map[string]int{CacheResultHit: 1, CacheResultMiss: 2}, metrics.results)
+}
+
+func TestCachedUserProviderShouldCacheUserNotFound(t *testing.T) {
+	provider, backend, metrics, clock := newTestCachedUserProvider(schema.AuthenticationBackendCache{Lifespan: time.Minute, NegativeLifespan: time.Second * 10})
+
+	_, err := provider.GetDetails("harry")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	_, err = provider.GetDetails("harry")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	assert.Equal(t, 1, backend.getCalls())
+
+	clock.Set(clock.Now().Add(time.Second * 10))
+
+	backend.setUser("harry", &UserDetails{Username: "harry"})
+
+	details, err := provider.GetDetails("harry")
+	require.NoError(t, err)
+	assert.Equal(t, "harry", details.Username)
+	assert.Equal(t, 2, backend.getCalls())
+
+	assert.Equal(t, map[string]int{CacheResultNegativeHit: 1, CacheResultMiss: 2}, metrics.results)
+}
+
+func TestCachedUserProviderShouldNotCacheErrors(t *testing.T) {
+	provider, backend, _, _ := newTestCachedUserProvider(schema.AuthenticationBackendCache{Lifespan: time.Minute, NegativeLifespan: time.Second * 10})
+
+	backend.err = errors.New("connection refused")
+
+	_, err := provider.GetDetails("john")
+	assert.EqualError(t, err, "connection refused")
+
+	_, err = provider.GetDetails("john")
+	assert.EqualError(t, err, "connection refused")
+	assert.Equal(t, 2, backend.getCalls())
+
+	assert.EqualError(t, provider.StartupCheck(), "connection refused")
+}
+
+func TestCachedUserProviderShouldInvalidate(t *testing.T) {
+	provider, backend, _, _ := newTestCachedUserProvider(schema.AuthenticationBackendCache{Lifespan: time.Minute, NegativeLifespan: time.Second * 10})
+
+	_, err := provider.GetDetails("john")
+	require.NoError(t, err)
+
+	require.NoError(t, provider.UpdatePassword("john", "new"))
+	assert.Equal(t, []string{"john"}, backend.updated)
+
+	_, err = provider.GetDetails("john")
+	require.NoError(t, err)
+	assert.Equal(t, 2, backend.getCalls())
+
+	_, err = provider.GetDetails("harry")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	backend.setUser("harry", &UserDetails{Username: "harry"})
+
+	valid, err := provider.CheckUserPassword("harry", "password")
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	_, err = provider.GetDetails("harry")
+	require.NoError(t, err)
+	assert.Equal(t, 4, backend.getCalls())
+
+	reloaded, err := provider.Reload()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, 1, backend.reloads)
+
+	_, err = provider.GetDetails("john")
+	require.NoError(t, err)
+	assert.Equal(t, 5, backend.getCalls())
+}
+
+func TestCachedUserProviderShouldRefreshInBackground(t *testing.T) {
+	provider, backend, _, clock := newTestCachedUserProvider(schema.AuthenticationBackendCache{Lifespan: time.Minute, NegativeLifespan: time.Second * 10, BackgroundRefresh: time.Second * 30})
+
+	_, err := provider.GetDetails("john")
+	require.NoError(t, err)
+
+	clock.Set(clock.Now().Add(time.Second * 30))
+
+	backend.setUser("john", &UserDetails{Username: "john", DisplayName: "John Smith"})
+	backend.block = make(chan struct{})
+
+	details, err := provider.GetDetails("john")
+	require.NoError(t, err)
+	assert.Equal(t, "John Doe", details.DisplayName)
+
+	details, err = provider.GetDetails("john")
+	require.NoError(t, err)
+	assert.Equal(t, "John Doe", details.DisplayName)
+
+	close(backend.block)
+
+	require.Eventually(t, func() bool {
+		details, err = provider.GetDetails("john")
+
+		return err == nil && details.DisplayName == "John Smith"
+	}, time.Second, time.Millisecond*10)
+
+	assert.Equal(t, 2, backend.getCalls())
+}
+
+func TestCachedUserProviderShouldNotCacheRefreshAfterInvalidate(t *testing.T) {
+	provider, backend, _, clock := newTestCachedUserProvider(schema.AuthenticationBackendCache{Lifespan: time.Minute, NegativeLifespan: time.Second * 10, BackgroundRefresh: time.Second * 30})
+
+	_, err := provider.GetDetails("john")
+	require.NoError(t, err)
+
+	clock.Set(clock.Now().Add(time.Second * 30))
+
+	backend.block = make(chan struct{})
+
+	_, err = provider.GetDetails("john")
+	require.NoError(t, err)
+
+	provider.Invalidate("john")
+
+	close(backend.block)
+
+	require.Eventually(t, func() bool {
+		provider.mu.Lock()
+
+		defer provider.mu.Unlock()
+
+		return len(provider.refreshing) == 0
+	}, time.Second, time.Millisecond*10)
+
+	provider.mu.Lock()
+	assert.Len(t, provider.entries, 0)
+	provider.mu.Unlock()
+
+	_, err = provider.GetDetails("john")
+	require.NoError(t, err)
+	assert.Equal(t, 3, backend.getCalls())
+}
+
+func TestCachedUserProviderShouldInvalidateEveryCaseVariation(t *testing.T) {
+	provider, backend, _, _ := newTestCachedUserProvider(schema.AuthenticationBackendCache{Lifespan: time.Minute, NegativeLifespan: time.Second * 10})
+
+	backend.setUser("John", &UserDetails{Username: "John", DisplayName: "John Smith"})
+
+	details, err := provider.GetDetails("john")
+	require.NoError(t, err)
+	assert.Equal(t, "John Doe", details.DisplayName)
+
+	details, err = provider.GetDetails("John")
+	require.NoError(t, err)
+	assert.Equal(t, "John Smith", details.DisplayName)
+
+	_, err = provider.GetDetails("JOHN")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	assert.Equal(t, 3, backend.getCalls())
+
+	provider.Invalidate("john")
+
+	provider.mu.Lock()
+	assert.Len(t, provider.entries, 0)
+	provider.mu.Unlock()
+
+	_, err = provider.GetDetails("John")
+	require.NoError(t, err)
+	assert.Equal(t, 4, backend.getCalls())
+}
@@ -0,0 +1,176 @@
+package authentication
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/authelia/authelia/v4/internal/logging"
+)
+
+// ChainedUserProviderBackend is a named UserProvider used by the ChainedUserProvider.
+type ChainedUserProviderBackend struct {
+	Name     string
+	Provider UserProvider
+}
+
+// NewChainedUserProvider creates a new ChainedUserProvider which consults the backends in the order provided.
+func NewChainedUserProvider(backends ...ChainedUserProviderBackend) *ChainedUserProvider {
+	return &ChainedUserProvider{
+		backends: backends,
+		owners:   map[string]int{},
+		log:      logging.Logger(),
+	}
+}
+
+// ChainedUserProvider is a UserProvider which consults an ordered list of backends. The first backend in the list which
+// has a user with a given username owns that username, and all operations for that username are performed only by
+// the owning backend. This means a user with the same username in a later backend can never be used to authenticate,
+// including when the user in the owning backend is disabled.
+type ChainedUserProvider struct {
+	backends []ChainedUserProviderBackend
+
+	mu     sync.RWMutex
+	owners map[string]int
+
+	log *logrus.Logger
+}
+
+// StartupCheck performs the startup check of every backend and returns all of the errors.
+func (p *ChainedUserProvider) StartupCheck() (err error) {
+	var errs []error
+
+	for _, backend := range p.backends {
+		if err = backend.Provider.StartupCheck(); err != nil {
+			errs = append(errs, fmt.Errorf("backend '%s': %w", backend.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// CheckUserPassword checks the password of the user against the first backend which has a user with the username. The
+// backend is remembered as the owner of the username.
+func (p *ChainedUserProvider) CheckUserPassword(username string, password string) (valid bool, err error) {
+	err = p.resolve(username, -1, func(backend ChainedUserProviderBackend) (err error) {
+		valid, err = backend.Provider.CheckUserPassword(username, password)
+
+		return err
+	})
+
+	return valid, err
+}
+
+// GetDetails retrieves the user details from the backend which owns the username.
+func (p *ChainedUserProvider) GetDetails(username string) (details *UserDetails, err error) {
+	err = p.do(username, func(backend ChainedUserProviderBackend) (err error) {
+		details, err = backend.Provider.GetDetails(username)
+
+		return err
+	})
+
+	return details, err
+}
+
+// UpdatePassword updates the password of the user in the backend which owns the username.
+func (p *ChainedUserProvider) UpdatePassword(username string, newPassword string) (err error) {
+	return p.do(username, func(backend ChainedUserProviderBackend) (err error) {
+		return backend.Provider.UpdatePassword(username, newPassword)
+	})
+}
+
+// Reload reloads every backend which supports reloading and forgets which backend owns each username if any of them
+// were reloaded.
+func (p *ChainedUserProvider) Reload() (reloaded bool, err error) {
+	var errs []error
+
+	for _, backend := range p.backends {
+		provider, ok := backend.Provider.(reloader)
+		if !ok {
+			continue
+		}
+
+		var r bool
+
+		if r, err = provider.Reload(); err != nil {
+			errs = append(errs, fmt.Errorf("backend '%s': %w", backend.Name, err))
+		}
+
+		reloaded = reloaded || r
+	}
+
+	if reloaded {
+		p.mu.Lock()
+
+		p.owners = map[string]int{}
+
+		p.mu.Unlock()
+	}
+
+	return reloaded, errors.Join(errs...)
+}
+
+// do performs the operation with the backend which owns the username. If the owner is not known, or the owner no longer
+// has a user with the username, the owner is resolved. If the user is disabled in the owner the owner is not resolved
+// again.
+func (p *ChainedUserProvider) do(username string, operation func(backend ChainedUserProviderBackend) (err error)) (err error) {
+	p.mu.RLock()
+
+	owner, ok := p.owners[username]
+
+	p.mu.RUnlock()
+
+	if !ok {
+		return p.resolve(username, -1, operation)
+	}
+
+	if err = operation(p.backends[owner]); errors.Is(err, ErrUserDisabled) || !errors.Is(err, ErrUserNotFound) {
+		return err
+	}
+
+	p.mu.Lock()
+
+	delete(p.owners, username)
+
+	p.mu.Unlock()
+
+	return p.resolve(username, owner, operation)
+}
+
+// resolve performs the operation with each backend in order, except the skipped backend, until one of them does not
+// return ErrUserNotFound and remembers that backend as the owner of the username. Any other error stops the search as
+// it's not possible to determine if that backend owns the username, and trying the next backend could allow a user in a
+// later backend to shadow a user in an earlier one. A backend which returns ErrUserDisabled owns the username and the
+// error is returned.
+func (p *ChainedUserProvider) resolve(username string, skip int, operation func(backend ChainedUserProviderBackend) (err error)) (err error) {
+	for i, backend := range p.backends {
+		if i == skip {
+			continue
+		}
+
+		err = operation(backend)
+
+		switch {
+		case errors.Is(err, ErrUserDisabled):
+			p.log.Tracef("User '%s' is owned by the authentication backend '%s' and is disabled", username, backend.Name)
+		case errors.Is(err, ErrUserNotFound):
+			continue
+		case err != nil:
+			return err
+		default:
+			p.log.Tracef("User '%s' is owned by the authentication backend '%s'", username, backend.Name)
+		}
+
+		p.mu.Lock()
+
+		p.owners[username] = i
+
+		p.mu.Unlock()
+
+		return err
+	}
+
+	return ErrUserNotFound
+}
@@ -0,0 +1,169 @@
+package authentication
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestChainedUserProvider() (provider *ChainedUserProvider, first, second *testUserProvider) {
+	first = &testUserProvider{
+		users: map[string]*UserDetails{
+			"john": {Username: "john", DisplayName: "John Doe (first)"},
+		},
+	}
+
+	second = &testUserProvider{
+		users: map[string]*UserDetails{
+			"john":  {Username: "john", DisplayName: "John Doe (second)"},
+			"harry": {Username: "harry", DisplayName: "Harry Potter"},
+		},
+	}
+
+	provider = NewChainedUserProvider(
+		ChainedUserProviderBackend{Name: "file", Provider: first},
+		ChainedUserProviderBackend{Name: "ldap", Provider: second},
+	)
+
+	return provider, first, second
+}
+
+func TestChainedUserProviderShouldPreferEarlierBackend(t *testing.T) {
+	provider, first, second := newTestChainedUserProvider()
+
+	valid, err := provider.CheckUserPassword("john", "password")
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	details, err := provider.GetDetails("john")
+	require.NoError(t, err)
+	assert.Equal(t, "John Doe (first)", details.DisplayName)
+
+	require.NoError(t, provider.UpdatePassword("john", "new"))
+	assert.Equal(t, []string{"john"}, first.updated)
+	assert.Len(t, second.updated, 0)
+	assert.Equal(t, 0, second.getCalls())
+}
+
+func TestChainedUserProviderShouldFallThroughToLaterBackend(t *testing.T) {
+	provider, first, second := newTestChainedUserProvider()
+
+	valid, err := provider.CheckUserPassword("harry", "password")
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	details, err := provider.GetDetails("harry")
+	require.NoError(t, err)
+	assert.Equal(t, "Harry Potter", details.DisplayName)
+	assert.Equal(t, 0, first.getCalls())
+	assert.Equal(t, 1, second.getCalls())
+
+	require.NoError(t, provider.UpdatePassword("harry", "new"))
+	assert.Len(t, first.updated, 0)
+	assert.Equal(t, []string{"harry"}, second.updated)
+
+	_, err = provider.GetDetails("fred")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	valid, err = provider.CheckUserPassword("fred", "password")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	assert.False(t, valid)
+}
+
+func TestChainedUserProviderShouldResolveOwnerWhenUserRemoved(t *testing.T) {
+	provider, first, _ := newTestChainedUserProvider()
+
+	details, err := provider.GetDetails("john")
+	require.NoError(t, err)
+	assert.Equal(t, "John Doe (first)", details.DisplayName)
+
+	first.setUser("john", nil)
+
+	details, err = provider.GetDetails("john")
+	require.NoError(t, err)
+	assert.Equal(t, "John Doe (second)", details.DisplayName)
+}
+
+func TestChainedUserProviderShouldNotFallThroughForDisabledUser(t *testing.T) {
+	WithDatabase(UserDatabaseContent, func(path string) {
+		config := DefaultFileAuthenticationBackendConfiguration
+		config.Path = path
+
+		file := NewFileUserProvider(&config)
+
+		require.NoError(t, file.StartupCheck())
+
+		ldap := &testUserProvider{
+			users: map[string]*UserDetails{
+				"dis": {Username: "dis", DisplayName: "Disabled (ldap)"},
+			},
+		}
+
+		provider := NewChainedUserProvider(
+			ChainedUserProviderBackend{Name: "file", Provider: file},
+			ChainedUserProviderBackend{Name: "ldap", Provider: ldap},
+		)
+
+		valid, err := provider.CheckUserPassword("dis", "password")
+		assert.ErrorIs(t, err, ErrUserDisabled)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+		assert.False(t, valid)
+		assert.Equal(t, map[string]int{"dis": 0}, provider.owners)
+
+		details, err := provider.GetDetails("dis")
+		assert.ErrorIs(t, err, ErrUserDisabled)
+		assert.Nil(t, details)
+
+		assert.ErrorIs(t, provider.UpdatePassword("dis", "new"), ErrUserDisabled)
+
+		provider.owners = map[string]int{}
+
+		details, err = provider.GetDetails("dis")
+		assert.ErrorIs(t, err, ErrUserDisabled)
+		assert.Nil(t, details)
+
+		assert.Equal(t, 0, ldap.getCalls())
+		assert.Len(t, ldap.updated, 0)
+	})
+}
+
+func TestChainedUserProviderShouldNotShadowOnError(t *testing.T) {
+	provider, first, second := newTestChainedUserProvider()
+
+	first.err = errors.New("connection refused")
+
+	valid, err := provider.CheckUserPassword("harry", "password")
+	assert.EqualError(t, err, "connection refused")
+	assert.False(t, valid)
+
+	_, err = provider.GetDetails("harry")
+	assert.EqualError(t, err, "connection refused")
+	assert.Equal(t, 0, second.getCalls())
+}
+
+func TestChainedUserProviderShouldAggregateStartupCheck(t *testing.T) {
+	provider, first, second := newTestChainedUserProvider()
+
+	assert.NoError(t, provider.StartupCheck())
+
+	first.err = errors.New("file not found")
+	second.err = errors.New("connection refused")
+
+	assert.EqualError(t, provider.StartupCheck(), "backend 'file': file not found\nbackend 'ldap': connection refused")
+}
+
+func TestChainedUserProviderShouldReload(t *testing.T) {
+	provider, first, second := newTestChainedUserProvider()
+
+	_, err := provider.GetDetails("john")
+	require.NoError(t, err)
+
+	reloaded, err := provider.Reload()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, 1, first.reloads)
+	assert.Equal(t, 1, second.reloads)
+	assert.Len(t, provider.owners, 0)
+}
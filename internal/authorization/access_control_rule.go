@@ -23,10 +23,13 @@ func NewAccessControlRule(pos int, rule schema.ACLRule, networksMap map[string][
 	r := &AccessControlRule{
 		Position: pos,
 		Query:    NewAccessControlQuery(rule.Query),
+		Headers:  NewAccessControlHeader(rule.Headers),
 		Methods:  schemaMethodsToACL(rule.Methods),
 		Networks: schemaNetworksToACL(rule.Networks, networksMap, networksCacheMap),
 		Subjects: schemaSubjectsToACL(rule.Subjects),
 		Policy:   NewLevel(rule.Policy),
+
+		MaxAuthenticationAge: rule.MaxAuthenticationAge,
 	}
 
 	if len(r.Subjects) != 0 {
@@ -48,10 +51,13 @@ type AccessControlRule struct {
 	Domains   []AccessControlDomain
 	Resources []AccessControlResource
 	Query     []AccessControlQuery
+	Headers   []AccessControlHeader
 	Methods   []string
 	Networks  []*net.IPNet
 	Subjects  []AccessControlSubjects
 	Policy    Level
+
+	MaxAuthenticationAge schema.MaxAuthenticationAge
 }
 
 // IsMatch returns true if all elements of an AccessControlRule match the object and subject.
@@ -68,6 +74,10 @@ func (acr *AccessControlRule) IsMatch(subject Subject, object Object) (match boo
 		return false
 	}
 
+	if !acr.MatchesHeaders(object) {
+		return false
+	}
+
 	if !acr.MatchesMethods(object) {
 		return false
 	}
@@ -134,6 +144,23 @@ func (acr *AccessControlRule) MatchesQuery(object Object) (match bool) {
 	return false
 }
 
+// MatchesHeaders returns true if the rule matches the request headers.
+func (acr *AccessControlRule) MatchesHeaders(object Object) (match bool) {
+	// If there are no header rules in this rule then the header condition is a match.
+	if len(acr.Headers) == 0 {
+		return true
+	}
+
+	// Iterate over the headers until we find a match (return true) or until we exit the loop (return false).
+	for _, header := range acr.Headers {
+		if header.IsMatch(object) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // MatchesMethods returns true if the rule matches the method.
 func (acr *AccessControlRule) MatchesMethods(object Object) (match bool) {
 	// If there are no methods in this rule then the method condition is a match.
@@ -1,6 +1,8 @@
 package authorization
 
 import (
+	"sync"
+
 	"github.com/sirupsen/logrus"
 
 	"github.com/authelia/authelia/v4/internal/configuration/schema"
@@ -9,9 +11,12 @@ import (
 
 // Authorizer the component in charge of checking whether a user can access a given resource.
 type Authorizer struct {
+	mu sync.RWMutex
+
 	defaultPolicy Level
 	rules         []*AccessControlRule
 	mfa           bool
+	external      *ExternalPolicyDecisionPoint
 	config        *schema.Configuration
 	log           *logrus.Logger
 }
@@ -19,54 +24,97 @@ type Authorizer struct {
 // NewAuthorizer create an instance of authorizer with a given access control config.
 func NewAuthorizer(config *schema.Configuration) (authorizer *Authorizer) {
 	authorizer = &Authorizer{
-		defaultPolicy: NewLevel(config.AccessControl.DefaultPolicy),
-		rules:         NewAccessControlRules(config.AccessControl),
-		config:        config,
-		log:           logging.Logger(),
+		log: logging.Logger(),
 	}
 
-	if authorizer.defaultPolicy == TwoFactor {
-		authorizer.mfa = true
+	authorizer.load(config)
 
-		return authorizer
-	}
+	return authorizer
+}
 
-	for _, rule := range authorizer.rules {
-		if rule.Policy == TwoFactor {
-			authorizer.mfa = true
+// Reload atomically replaces the access control configuration used by the authorizer. Requests being evaluated while
+// the reload occurs use the previous configuration.
+func (p *Authorizer) Reload(config schema.AccessControlConfiguration) {
+	p.mu.RLock()
+
+	full := *p.config
+
+	p.mu.RUnlock()
+
+	full.AccessControl = config
+
+	p.load(&full)
+}
 
-			return authorizer
+func (p *Authorizer) load(config *schema.Configuration) {
+	defaultPolicy := NewLevel(config.AccessControl.DefaultPolicy)
+	rules := NewAccessControlRules(config.AccessControl)
+	external := NewExternalPolicyDecisionPoint(config.AccessControl.External)
+	mfa := isSecondFactorEnabled(config, defaultPolicy, rules)
+
+	p.mu.Lock()
+
+	defer p.mu.Unlock()
+
+	p.defaultPolicy, p.rules, p.external, p.mfa, p.config = defaultPolicy, rules, external, mfa, config
+}
+
+func isSecondFactorEnabled(config *schema.Configuration, defaultPolicy Level, rules []*AccessControlRule) bool {
+	if defaultPolicy == TwoFactor {
+		return true
+	}
+
+	for _, rule := range rules {
+		// The external policy decision point may require two factor authentication.
+		if rule.Policy == TwoFactor || rule.Policy == External {
+			return true
 		}
 	}
 
-	if authorizer.config.IdentityProviders.OIDC != nil {
-		for _, client := range authorizer.config.IdentityProviders.OIDC.Clients {
+	if config.IdentityProviders.OIDC != nil {
+		for _, client := range config.IdentityProviders.OIDC.Clients {
 			if client.Policy == twoFactor {
-				authorizer.mfa = true
-
-				return authorizer
+				return true
 			}
 		}
 	}
 
-	return authorizer
+	return false
 }
 
 // IsSecondFactorEnabled return true if at least one policy is set to second factor.
 func (p *Authorizer) IsSecondFactorEnabled() bool {
+	p.mu.RLock()
+
+	defer p.mu.RUnlock()
+
 	return p.mfa
 }
 
 // GetRequiredLevel retrieve the required level of authorization to access the object.
 func (p *Authorizer) GetRequiredLevel(subject Subject, object Object) (hasSubjects bool, level Level) {
+	hasSubjects, level, _ = p.GetRequiredPolicy(subject, object)
+
+	return hasSubjects, level
+}
+
+// GetRequiredPolicy retrieve the required level of authorization and the maximum authentication age of each factor to
+// access the object.
+func (p *Authorizer) GetRequiredPolicy(subject Subject, object Object) (hasSubjects bool, level Level, maxAge schema.MaxAuthenticationAge) {
 	p.log.Debugf("Check authorization of subject %s and object %s (method %s).",
 		subject.String(), object.String(), object.Method)
 
-	for _, rule := range p.rules {
+	rules, defaultPolicy, external := p.getRules()
+
+	for _, rule := range rules {
 		if rule.IsMatch(subject, object) {
 			p.log.Tracef(traceFmtACLHitMiss, "HIT", rule.Position, subject, object, object.Method)
 
-			return rule.HasSubjects, rule.Policy
+			if rule.Policy == External {
+				return rule.HasSubjects, p.getExternalLevel(external, subject, object, rule.Position), rule.MaxAuthenticationAge
+			}
+
+			return rule.HasSubjects, rule.Policy, rule.MaxAuthenticationAge
 		}
 
 		p.log.Tracef(traceFmtACLHitMiss, "MISS", rule.Position, subject, object, object.Method)
@@ -74,16 +122,36 @@ func (p *Authorizer) GetRequiredLevel(subject Subject, object Object) (hasSubjec
 
 	p.log.Debugf("No matching rule for subject %s and url %s (method %s) applying default policy", subject, object, object.Method)
 
-	return false, p.defaultPolicy
+	return false, defaultPolicy, maxAge
+}
+
+func (p *Authorizer) getRules() (rules []*AccessControlRule, defaultPolicy Level, external *ExternalPolicyDecisionPoint) {
+	p.mu.RLock()
+
+	defer p.mu.RUnlock()
+
+	return p.rules, p.defaultPolicy, p.external
+}
+
+func (p *Authorizer) getExternalLevel(external *ExternalPolicyDecisionPoint, subject Subject, object Object, position int) (level Level) {
+	if external == nil {
+		p.log.Errorf("Rule #%d uses the external policy but no external policy decision point is configured, denying access", position)
+
+		return Denied
+	}
+
+	return external.GetRequiredLevel(subject, object, position)
 }
 
 // GetRuleMatchResults iterates through the rules and produces a list of RuleMatchResult provided a subject and object.
 func (p *Authorizer) GetRuleMatchResults(subject Subject, object Object) (results []RuleMatchResult) {
 	skipped := false
 
-	results = make([]RuleMatchResult, len(p.rules))
+	rules, _, _ := p.getRules()
+
+	results = make([]RuleMatchResult, len(rules))
 
-	for i, rule := range p.rules {
+	for i, rule := range rules {
 		results[i] = RuleMatchResult{
 			Rule:    rule,
 			Skipped: skipped,
@@ -91,6 +159,7 @@ func (p *Authorizer) GetRuleMatchResults(subject Subject, object Object) (result
 			MatchDomain:        rule.MatchesDomains(subject, object),
 			MatchResources:     rule.MatchesResources(subject, object),
 			MatchQuery:         rule.MatchesQuery(object),
+			MatchHeaders:       rule.MatchesHeaders(object),
 			MatchMethods:       rule.MatchesMethods(object),
 			MatchNetworks:      rule.MatchesNetworks(subject),
 			MatchSubjects:      rule.MatchesSubjects(subject),
@@ -2,6 +2,7 @@ package authorization
 
 import (
 	"net"
+	"net/http"
 	"net/url"
 	"regexp"
 	"testing"
@@ -32,9 +33,13 @@ func NewAuthorizerTester(config schema.AccessControlConfiguration) *AuthorizerTe
 }
 
 func (s *AuthorizerTester) CheckAuthorizations(t *testing.T, subject Subject, requestURI, method string, expectedLevel Level) {
+	s.CheckAuthorizationsWithHeaders(t, subject, requestURI, method, nil, expectedLevel)
+}
+
+func (s *AuthorizerTester) CheckAuthorizationsWithHeaders(t *testing.T, subject Subject, requestURI, method string, header http.Header, expectedLevel Level) {
 	targetURL, _ := url.ParseRequestURI(requestURI)
 
-	object := NewObject(targetURL, method)
+	object := NewObject(targetURL, method, header)
 
 	_, level := s.GetRequiredLevel(subject, object)
 
@@ -42,9 +47,13 @@ func (s *AuthorizerTester) CheckAuthorizations(t *testing.T, subject Subject, re
 }
 
 func (s *AuthorizerTester) GetRuleMatchResults(subject Subject, requestURI, method string) (results []RuleMatchResult) {
+	return s.GetRuleMatchResultsWithHeaders(subject, requestURI, method, nil)
+}
+
+func (s *AuthorizerTester) GetRuleMatchResultsWithHeaders(subject Subject, requestURI, method string, header http.Header) (results []RuleMatchResult) {
 	targetURL, _ := url.ParseRequestURI(requestURI)
 
-	object := NewObject(targetURL, method)
+	object := NewObject(targetURL, method, header)
 
 	return s.Authorizer.GetRuleMatchResults(subject, object)
 }
@@ -331,6 +340,168 @@ func (s *AuthorizerSuite) TestShouldCheckQueryPolicy() {
 	}
 }
 
+func (s *AuthorizerSuite) TestShouldCheckHeaderPolicy() {
+	tester := NewAuthorizerBuilder().
+		WithDefaultPolicy(deny).
+		WithRule(schema.ACLRule{
+			Domains: []string{"one.example.com"},
+			Headers: [][]schema.ACLQueryRule{
+				{
+					{
+						Operator: operatorEqual,
+						Key:      "x-tenant",
+						Value:    "abc",
+					},
+					{
+						Operator: operatorAbsent,
+						Key:      "X-Admin",
+					},
+				},
+				{
+					{
+						Operator: operatorPresent,
+						Key:      "X-Public",
+					},
+				},
+			},
+			Policy: oneFactor,
+		}).
+		WithRule(schema.ACLRule{
+			Domains: []string{"two.example.com"},
+			Headers: [][]schema.ACLQueryRule{
+				{
+					{
+						Operator: operatorNotEqual,
+						Key:      "X-Tenant",
+						Value:    "abc",
+					},
+				},
+			},
+			Policy: twoFactor,
+		}).
+		WithRule(schema.ACLRule{
+			Domains: []string{"three.example.com"},
+			Headers: [][]schema.ACLQueryRule{
+				{
+					{
+						Operator: operatorPattern,
+						Key:      "User-Agent",
+						Value:    regexp.MustCompile(`^curl/.*$`),
+					},
+				},
+			},
+			Policy: twoFactor,
+		}).
+		WithRule(schema.ACLRule{
+			Domains: []string{"four.example.com"},
+			Headers: [][]schema.ACLQueryRule{
+				{
+					{
+						Operator: operatorNotPattern,
+						Key:      "User-Agent",
+						Value:    regexp.MustCompile(`^curl/.*$`),
+					},
+				},
+			},
+			Policy: twoFactor,
+		}).
+		Build()
+
+	testCases := []struct {
+		name, requestURL string
+		header           http.Header
+		expected         Level
+	}{
+		{"ShouldAllow1FAEqualRule", "https://one.example.com/", http.Header{"X-Tenant": []string{"abc"}}, OneFactor},
+		{"ShouldDenyAbsentRule", "https://one.example.com/", http.Header{"X-Tenant": []string{"abc"}, "X-Admin": []string{"true"}}, Denied},
+		{"ShouldAllow1FAPresentRule", "https://one.example.com/", http.Header{"X-Public": []string{""}}, OneFactor},
+		{"ShouldDenyNoHeaders", "https://one.example.com/", nil, Denied},
+		{"ShouldAllow2FANotEqualRule", "https://two.example.com/", http.Header{"X-Tenant": []string{"xyz"}}, TwoFactor},
+		{"ShouldDenyNotEqualRule", "https://two.example.com/", http.Header{"X-Tenant": []string{"abc"}}, Denied},
+		{"ShouldAllow2FAPatternRule", "https://three.example.com/", http.Header{"User-Agent": []string{"curl/7.88.1"}}, TwoFactor},
+		{"ShouldDenyPatternRule", "https://three.example.com/", http.Header{"User-Agent": []string{"Mozilla/5.0"}}, Denied},
+		{"ShouldAllow2FANotPatternRule", "https://four.example.com/", http.Header{"User-Agent": []string{"Mozilla/5.0"}}, TwoFactor},
+		{"ShouldDenyNotPatternRule", "https://four.example.com/", http.Header{"User-Agent": []string{"curl/7.88.1"}}, Denied},
+	}
+
+	for _, tc := range testCases {
+		s.T().Run(tc.name, func(t *testing.T) {
+			tester.CheckAuthorizationsWithHeaders(t, UserWithGroups, tc.requestURL, "GET", tc.header, tc.expected)
+		})
+	}
+}
+
+// The rule match results must agree with the required level, which means a rule with query or header criteria that
+// don't match must not be considered a match and must not cause the following rules to be skipped.
+func (s *AuthorizerSuite) TestShouldCheckRuleMatchResultsQueryAndHeaders() {
+	tester := NewAuthorizerBuilder().
+		WithDefaultPolicy(deny).
+		WithRule(schema.ACLRule{
+			Domains: []string{"one.example.com"},
+			Query: [][]schema.ACLQueryRule{
+				{
+					{
+						Operator: operatorPresent,
+						Key:      "admin",
+					},
+				},
+			},
+			Policy: twoFactor,
+		}).
+		WithRule(schema.ACLRule{
+			Domains: []string{"one.example.com"},
+			Headers: [][]schema.ACLQueryRule{
+				{
+					{
+						Operator: operatorEqual,
+						Key:      "X-Tenant",
+						Value:    "abc",
+					},
+				},
+			},
+			Policy: twoFactor,
+		}).
+		WithRule(schema.ACLRule{
+			Domains: []string{"one.example.com"},
+			Policy:  oneFactor,
+		}).
+		Build()
+
+	header := http.Header{"X-Tenant": []string{"xyz"}}
+
+	tester.CheckAuthorizationsWithHeaders(s.T(), John, "https://one.example.com/?user=john", "GET", header, OneFactor)
+
+	results := tester.GetRuleMatchResultsWithHeaders(John, "https://one.example.com/?user=john", "GET", header)
+
+	s.Require().Len(results, 3)
+
+	s.Assert().False(results[0].IsMatch())
+	s.Assert().False(results[0].IsPotentialMatch())
+	s.Assert().True(results[0].MatchDomain)
+	s.Assert().False(results[0].MatchQuery)
+	s.Assert().True(results[0].MatchHeaders)
+
+	s.Assert().False(results[1].IsMatch())
+	s.Assert().False(results[1].IsPotentialMatch())
+	s.Assert().False(results[1].Skipped)
+	s.Assert().True(results[1].MatchDomain)
+	s.Assert().True(results[1].MatchQuery)
+	s.Assert().False(results[1].MatchHeaders)
+
+	s.Assert().True(results[2].IsMatch())
+	s.Assert().False(results[2].Skipped)
+
+	tester.CheckAuthorizationsWithHeaders(s.T(), John, "https://one.example.com/?admin", "GET", header, TwoFactor)
+
+	results = tester.GetRuleMatchResultsWithHeaders(John, "https://one.example.com/?admin", "GET", header)
+
+	s.Require().Len(results, 3)
+
+	s.Assert().True(results[0].IsMatch())
+	s.Assert().True(results[1].Skipped)
+	s.Assert().True(results[2].Skipped)
+}
+
 func (s *AuthorizerSuite) TestShouldCheckRulePrecedence() {
 	tester := NewAuthorizerBuilder().
 		WithDefaultPolicy(deny).
@@ -1036,6 +1207,50 @@ func TestNewAuthorizer(t *testing.T) {
 	assert.Equal(t, "admins", group.Name)
 }
 
+func TestAuthorizerShouldReload(t *testing.T) {
+	config := &schema.Configuration{
+		AccessControl: schema.AccessControlConfiguration{
+			DefaultPolicy: deny,
+			Rules: []schema.ACLRule{
+				{
+					Domains: []string{"example.com"},
+					Policy:  oneFactor,
+				},
+			},
+		},
+	}
+
+	authorizer := NewAuthorizer(config)
+
+	targetURL, _ := url.ParseRequestURI("https://example.com/")
+	object := NewObject(targetURL, http.MethodGet, nil)
+
+	_, level := authorizer.GetRequiredLevel(Subject{}, object)
+	assert.Equal(t, OneFactor, level)
+	assert.False(t, authorizer.IsSecondFactorEnabled())
+
+	authorizer.Reload(schema.AccessControlConfiguration{
+		DefaultPolicy: deny,
+		Rules: []schema.ACLRule{
+			{
+				Domains: []string{"example.com"},
+				Policy:  twoFactor,
+			},
+			{
+				Domains: []string{"other.example.com"},
+				Policy:  bypass,
+			},
+		},
+	})
+
+	_, level = authorizer.GetRequiredLevel(Subject{}, object)
+	assert.Equal(t, TwoFactor, level)
+	assert.True(t, authorizer.IsSecondFactorEnabled())
+	assert.Len(t, authorizer.GetRuleMatchResults(Subject{}, object), 2)
+
+	assert.Len(t, config.AccessControl.Rules, 1)
+}
+
 func TestAuthorizerIsSecondFactorEnabledRuleWithNoOIDC(t *testing.T) {
 	config := &schema.Configuration{
 		AccessControl: schema.AccessControlConfiguration{
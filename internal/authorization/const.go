@@ -15,6 +15,9 @@ const (
 
 	// Denied denied level.
 	Denied
+
+	// External external level which is resolved to one of the other levels by the external policy decision point.
+	External
 )
 
 const (
@@ -27,6 +30,7 @@ const (
 	oneFactor = "one_factor"
 	twoFactor = "two_factor"
 	deny      = "deny"
+	external  = "external"
 )
 
 const (
@@ -48,4 +52,8 @@ var (
 	IdentitySubexpNames = []string{subexpNameUser, subexpNameGroup}
 )
 
+const (
+	contentTypeApplicationJSON = "application/json"
+)
+
 const traceFmtACLHitMiss = "ACL %s Position %d for subject %s and object %s (method %s)"
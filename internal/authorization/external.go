@@ -0,0 +1,253 @@
+package authorization
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/logging"
+)
+
+// NewExternalPolicyDecisionPoint creates a new ExternalPolicyDecisionPoint from the schema.ACLExternal configuration.
+// It returns nil if no address is configured.
+func NewExternalPolicyDecisionPoint(config schema.ACLExternal) (pdp *ExternalPolicyDecisionPoint) {
+	if config.Address == nil {
+		return nil
+	}
+
+	return &ExternalPolicyDecisionPoint{
+		address:       config.Address.String(),
+		client:        &http.Client{Timeout: config.Timeout},
+		cacheDuration: config.CacheDuration,
+		cacheSize:     config.CacheSize,
+		failure:       NewLevel(config.FailurePolicy),
+		cache:         map[[sha256.Size]byte]*list.Element{},
+		recent:        list.New(),
+		log:           logging.Logger(),
+		now:           time.Now,
+	}
+}
+
+// ExternalPolicyDecisionPoint determines the required authorization level for rules which use the external policy by
+// calling an external HTTP policy decision point.
+type ExternalPolicyDecisionPoint struct {
+	address       string
+	client        *http.Client
+	cacheDuration time.Duration
+	cacheSize     int
+	failure       Level
+
+	// The cache is bounded to cacheSize entries and the least recently used entry is evicted when it's full. The recent
+	// list is ordered from the most recently used entry to the least recently used entry.
+	mu     sync.Mutex
+	cache  map[[sha256.Size]byte]*list.Element
+	recent *list.List
+
+	log *logrus.Logger
+	now func() time.Time
+}
+
+// ExternalPolicyRequest is the JSON body sent to the external policy decision point.
+type ExternalPolicyRequest struct {
+	Rule                int                          `json:"rule"`
+	Subject             ExternalPolicyRequestSubject `json:"subject"`
+	Object              ExternalPolicyRequestObject  `json:"object"`
+	AuthenticationLevel string                       `json:"authentication_level"`
+}
+
+// ExternalPolicyRequestSubject is the subject portion of the ExternalPolicyRequest.
+type ExternalPolicyRequestSubject struct {
+	Username string   `json:"username"`
+	Groups   []string `json:"groups"`
+	IP       string   `json:"ip"`
+}
+
+// ExternalPolicyRequestObject is the object portion of the ExternalPolicyRequest.
+type ExternalPolicyRequestObject struct {
+	URL    string `json:"url"`
+	Domain string `json:"domain"`
+	Path   string `json:"path"`
+	Method string `json:"method"`
+}
+
+// ExternalPolicyResponse is the JSON body expected from the external policy decision point.
+type ExternalPolicyResponse struct {
+	Policy string `json:"policy"`
+}
+
+type externalPolicyCacheEntry struct {
+	key     [sha256.Size]byte
+	level   Level
+	expires time.Time
+}
+
+// GetRequiredLevel returns the required level the external policy decision point decided for the subject and object
+// which matched the rule at the given position. If the decision point can't be reached or returns an invalid response
+// the failure policy is returned instead.
+func (pdp *ExternalPolicyDecisionPoint) GetRequiredLevel(subject Subject, object Object, position int) (level Level) {
+	request := newExternalPolicyRequest(subject, object, position)
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		pdp.log.WithError(err).Errorf("Error occurred encoding the external policy request for rule #%d, applying the failure policy '%s'", position, pdp.failure)
+
+		return pdp.failure
+	}
+
+	key := sha256.Sum256(body)
+
+	if level, ok := pdp.getCached(key); ok {
+		return level
+	}
+
+	if level, err = pdp.request(body); err != nil {
+		pdp.log.WithError(err).Errorf("Error occurred retrieving the external policy decision for rule #%d and subject %s and object %s (method %s), applying the failure policy '%s'", position, subject, object, object.Method, pdp.failure)
+
+		return pdp.failure
+	}
+
+	pdp.log.Debugf("External policy decision for rule #%d and subject %s and object %s (method %s) is '%s'", position, subject, object, object.Method, level)
+
+	pdp.setCached(key, level)
+
+	return level
+}
+
+func (pdp *ExternalPolicyDecisionPoint) request(body []byte) (level Level, err error) {
+	var (
+		req  *http.Request
+		resp *http.Response
+	)
+
+	if req, err = http.NewRequest(http.MethodPost, pdp.address, bytes.NewReader(body)); err != nil {
+		return Denied, err
+	}
+
+	req.Header.Set("Content-Type", contentTypeApplicationJSON)
+	req.Header.Set("Accept", contentTypeApplicationJSON)
+
+	if resp, err = pdp.client.Do(req); err != nil {
+		return Denied, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Denied, fmt.Errorf("the policy decision point responded with status code %d", resp.StatusCode)
+	}
+
+	var response ExternalPolicyResponse
+
+	if err = json.NewDecoder(io.LimitReader(resp.Body, 1024*64)).Decode(&response); err != nil {
+		return Denied, fmt.Errorf("the policy decision point response could not be decoded: %w", err)
+	}
+
+	switch response.Policy {
+	case bypass, oneFactor, twoFactor, deny:
+		return NewLevel(response.Policy), nil
+	default:
+		return Denied, fmt.Errorf("the policy decision point responded with the invalid policy '%s'", response.Policy)
+	}
+}
+
+func (pdp *ExternalPolicyDecisionPoint) getCached(key [sha256.Size]byte) (level Level, ok bool) {
+	if pdp.cacheDuration <= 0 {
+		return Denied, false
+	}
+
+	pdp.mu.Lock()
+
+	defer pdp.mu.Unlock()
+
+	element, ok := pdp.cache[key]
+	if !ok {
+		return Denied, false
+	}
+
+	entry := element.Value.(*externalPolicyCacheEntry)
+
+	if pdp.now().After(entry.expires) {
+		pdp.removeCached(element)
+
+		return Denied, false
+	}
+
+	pdp.recent.MoveToFront(element)
+
+	return entry.level, true
+}
+
+func (pdp *ExternalPolicyDecisionPoint) setCached(key [sha256.Size]byte, level Level) {
+	if pdp.cacheDuration <= 0 || pdp.cacheSize <= 0 {
+		return
+	}
+
+	pdp.mu.Lock()
+
+	defer pdp.mu.Unlock()
+
+	now := pdp.now()
+
+	if element, ok := pdp.cache[key]; ok {
+		entry := element.Value.(*externalPolicyCacheEntry)
+
+		entry.level, entry.expires = level, now.Add(pdp.cacheDuration)
+
+		pdp.recent.MoveToFront(element)
+
+		return
+	}
+
+	// Expired entries are removed when they're retrieved, otherwise they're removed from the back of the list when
+	// they're also the least recently used, or evicted when the cache is full.
+	for element := pdp.recent.Back(); element != nil && now.After(element.Value.(*externalPolicyCacheEntry).expires); element = pdp.recent.Back() {
+		pdp.removeCached(element)
+	}
+
+	for pdp.recent.Len() >= pdp.cacheSize {
+		pdp.removeCached(pdp.recent.Back())
+	}
+
+	pdp.cache[key] = pdp.recent.PushFront(&externalPolicyCacheEntry{key: key, level: level, expires: now.Add(pdp.cacheDuration)})
+}
+
+func (pdp *ExternalPolicyDecisionPoint) removeCached(element *list.Element) {
+	pdp.recent.Remove(element)
+
+	delete(pdp.cache, element.Value.(*externalPolicyCacheEntry).key)
+}
+
+func newExternalPolicyRequest(subject Subject, object Object, position int) (request ExternalPolicyRequest) {
+	request = ExternalPolicyRequest{
+		Rule: position,
+		Subject: ExternalPolicyRequestSubject{
+			Username: subject.Username,
+			Groups:   subject.Groups,
+		},
+		Object: ExternalPolicyRequestObject{
+			Domain: object.Domain,
+			Path:   object.Path,
+			Method: object.Method,
+		},
+		AuthenticationLevel: subject.AuthenticationLevel.String(),
+	}
+
+	if subject.IP != nil {
+		request.Subject.IP = subject.IP.String()
+	}
+
+	if object.URL != nil {
+		request.Object.URL = object.URL.String()
+	}
+
+	return request
+}
@@ -0,0 +1,336 @@
+package authorization
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/authentication"
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+)
+
+type externalPolicyStub struct {
+	server   *httptest.Server
+	requests atomic.Int32
+
+	mu   sync.Mutex
+	last ExternalPolicyRequest
+}
+
+func newExternalPolicyStub(t *testing.T, handler func(w http.ResponseWriter, request ExternalPolicyRequest)) (stub *externalPolicyStub) {
+	stub = &externalPolicyStub{}
+
+	stub.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.requests.Add(1)
+
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, contentTypeApplicationJSON, r.Header.Get("Content-Type"))
+
+		var request ExternalPolicyRequest
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		stub.mu.Lock()
+		stub.last = request
+		stub.mu.Unlock()
+
+		handler(w, request)
+	}))
+
+	t.Cleanup(stub.server.Close)
+
+	return stub
+}
+
+func (stub *externalPolicyStub) config(t *testing.T) schema.ACLExternal {
+	address, err := url.Parse(stub.server.URL)
+	require.NoError(t, err)
+
+	return schema.ACLExternal{
+		Address:       address,
+		Timeout:       time.Second,
+		CacheDuration: time.Minute,
+		CacheSize:     10,
+		FailurePolicy: deny,
+	}
+}
+
+func (stub *externalPolicyStub) lastRequest() ExternalPolicyRequest {
+	stub.mu.Lock()
+
+	defer stub.mu.Unlock()
+
+	return stub.last
+}
+
+func writeExternalPolicy(w http.ResponseWriter, policy string) {
+	w.Header().Set("Content-Type", contentTypeApplicationJSON)
+
+	_ = json.NewEncoder(w).Encode(ExternalPolicyResponse{Policy: policy})
+}
+
+func TestShouldReturnNilExternalPolicyDecisionPointWithoutAddress(t *testing.T) {
+	assert.Nil(t, NewExternalPolicyDecisionPoint(schema.ACLExternal{}))
+}
+
+func TestExternalPolicyDecisionPointShouldSendSubjectObjectAndLevel(t *testing.T) {
+	stub := newExternalPolicyStub(t, func(w http.ResponseWriter, request ExternalPolicyRequest) {
+		writeExternalPolicy(w, twoFactor)
+	})
+
+	pdp := NewExternalPolicyDecisionPoint(stub.config(t))
+
+	targetURL, _ := url.ParseRequestURI("https://app.example.com/admin?x=1")
+
+	level := pdp.GetRequiredLevel(Subject{
+		Username:            "john",
+		Groups:              []string{"admins", "dev"},
+		IP:                  net.ParseIP("192.168.1.10"),
+		AuthenticationLevel: authentication.OneFactor,
+	}, NewObject(targetURL, http.MethodPut, nil), 3)
+
+	assert.Equal(t, TwoFactor, level)
+	assert.Equal(t, int32(1), stub.requests.Load())
+	assert.Equal(t, ExternalPolicyRequest{
+		Rule: 3,
+		Subject: ExternalPolicyRequestSubject{
+			Username: "john",
+			Groups:   []string{"admins", "dev"},
+			IP:       "192.168.1.10",
+		},
+		Object: ExternalPolicyRequestObject{
+			URL:    "https://app.example.com/admin?x=1",
+			Domain: "app.example.com",
+			Path:   "/admin?x=1",
+			Method: http.MethodPut,
+		},
+		AuthenticationLevel: "one_factor",
+	}, stub.lastRequest())
+}
+
+func TestExternalPolicyDecisionPointShouldCacheDecisions(t *testing.T) {
+	stub := newExternalPolicyStub(t, func(w http.ResponseWriter, request ExternalPolicyRequest) {
+		switch request.Subject.Username {
+		case "john":
+			writeExternalPolicy(w, oneFactor)
+		default:
+			writeExternalPolicy(w, deny)
+		}
+	})
+
+	pdp := NewExternalPolicyDecisionPoint(stub.config(t))
+
+	now := time.Unix(1700000000, 0)
+
+	pdp.now = func() time.Time {
+		return now
+	}
+
+	targetURL, _ := url.ParseRequestURI("https://app.example.com/")
+	object := NewObject(targetURL, http.MethodGet, nil)
+
+	assert.Equal(t, OneFactor, pdp.GetRequiredLevel(Subject{Username: "john"}, object, 1))
+	assert.Equal(t, OneFactor, pdp.GetRequiredLevel(Subject{Username: "john"}, object, 1))
+	assert.Equal(t, int32(1), stub.requests.Load())
+
+	assert.Equal(t, Denied, pdp.GetRequiredLevel(Subject{Username: "harry"}, object, 1))
+	assert.Equal(t, int32(2), stub.requests.Load())
+
+	now = now.Add(time.Minute * 2)
+
+	assert.Equal(t, OneFactor, pdp.GetRequiredLevel(Subject{Username: "john"}, object, 1))
+	assert.Equal(t, int32(3), stub.requests.Load())
+	assert.Len(t, pdp.cache, 1)
+}
+
+func TestExternalPolicyDecisionPointShouldEvictLeastRecentlyUsedDecisions(t *testing.T) {
+	stub := newExternalPolicyStub(t, func(w http.ResponseWriter, request ExternalPolicyRequest) {
+		writeExternalPolicy(w, oneFactor)
+	})
+
+	config := stub.config(t)
+	config.CacheSize = 2
+
+	pdp := NewExternalPolicyDecisionPoint(config)
+
+	targetURL, _ := url.ParseRequestURI("https://app.example.com/")
+	object := NewObject(targetURL, http.MethodGet, nil)
+
+	pdp.GetRequiredLevel(Subject{Username: "john"}, object, 1)
+	pdp.GetRequiredLevel(Subject{Username: "harry"}, object, 1)
+	pdp.GetRequiredLevel(Subject{Username: "john"}, object, 1)
+	assert.Equal(t, int32(2), stub.requests.Load())
+
+	pdp.GetRequiredLevel(Subject{Username: "bob"}, object, 1)
+	assert.Equal(t, int32(3), stub.requests.Load())
+	assert.Len(t, pdp.cache, 2)
+	assert.Equal(t, 2, pdp.recent.Len())
+
+	pdp.GetRequiredLevel(Subject{Username: "john"}, object, 1)
+	assert.Equal(t, int32(3), stub.requests.Load())
+
+	pdp.GetRequiredLevel(Subject{Username: "harry"}, object, 1)
+	assert.Equal(t, int32(4), stub.requests.Load())
+	assert.Len(t, pdp.cache, 2)
+}
+
+func TestExternalPolicyDecisionPointShouldNotCacheWhenDisabled(t *testing.T) {
+	stub := newExternalPolicyStub(t, func(w http.ResponseWriter, request ExternalPolicyRequest) {
+		writeExternalPolicy(w, bypass)
+	})
+
+	config := stub.config(t)
+	config.CacheDuration = 0
+
+	pdp := NewExternalPolicyDecisionPoint(config)
+
+	targetURL, _ := url.ParseRequestURI("https://app.example.com/")
+	object := NewObject(targetURL, http.MethodGet, nil)
+
+	assert.Equal(t, Bypass, pdp.GetRequiredLevel(Subject{}, object, 1))
+	assert.Equal(t, Bypass, pdp.GetRequiredLevel(Subject{}, object, 1))
+	assert.Equal(t, int32(2), stub.requests.Load())
+}
+
+func TestExternalPolicyDecisionPointShouldApplyFailurePolicy(t *testing.T) {
+	testCases := []struct {
+		name     string
+		handler  func(w http.ResponseWriter, request ExternalPolicyRequest)
+		failure  string
+		expected Level
+	}{
+		{
+			"ShouldFailClosedOnStatusCode",
+			func(w http.ResponseWriter, request ExternalPolicyRequest) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			deny,
+			Denied,
+		},
+		{
+			"ShouldFailOpenOnStatusCode",
+			func(w http.ResponseWriter, request ExternalPolicyRequest) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			oneFactor,
+			OneFactor,
+		},
+		{
+			"ShouldFailOnInvalidPolicy",
+			func(w http.ResponseWriter, request ExternalPolicyRequest) {
+				writeExternalPolicy(w, "allow")
+			},
+			twoFactor,
+			TwoFactor,
+		},
+		{
+			"ShouldFailOnInvalidBody",
+			func(w http.ResponseWriter, request ExternalPolicyRequest) {
+				_, _ = w.Write([]byte("not json"))
+			},
+			bypass,
+			Bypass,
+		},
+		{
+			"ShouldFailOnTimeout",
+			func(w http.ResponseWriter, request ExternalPolicyRequest) {
+				time.Sleep(time.Millisecond * 200)
+
+				writeExternalPolicy(w, bypass)
+			},
+			oneFactor,
+			OneFactor,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := newExternalPolicyStub(t, tc.handler)
+
+			config := stub.config(t)
+			config.Timeout = time.Millisecond * 50
+			config.FailurePolicy = tc.failure
+
+			pdp := NewExternalPolicyDecisionPoint(config)
+
+			targetURL, _ := url.ParseRequestURI("https://app.example.com/")
+			object := NewObject(targetURL, http.MethodGet, nil)
+
+			assert.Equal(t, tc.expected, pdp.GetRequiredLevel(Subject{Username: "john"}, object, 1))
+			assert.Equal(t, tc.expected, pdp.GetRequiredLevel(Subject{Username: "john"}, object, 1))
+			assert.Equal(t, int32(2), stub.requests.Load())
+		})
+	}
+}
+
+func TestAuthorizerShouldEvaluateExternalPolicy(t *testing.T) {
+	stub := newExternalPolicyStub(t, func(w http.ResponseWriter, request ExternalPolicyRequest) {
+		switch request.AuthenticationLevel {
+		case "two_factor":
+			writeExternalPolicy(w, twoFactor)
+		default:
+			writeExternalPolicy(w, oneFactor)
+		}
+	})
+
+	authorizer := NewAuthorizer(&schema.Configuration{
+		AccessControl: schema.AccessControlConfiguration{
+			DefaultPolicy: deny,
+			External:      stub.config(t),
+			Rules: []schema.ACLRule{
+				{Domains: []string{"public.example.com"}, Policy: bypass},
+				{Domains: []string{"app.example.com"}, Policy: external},
+			},
+		},
+	})
+
+	assert.True(t, authorizer.IsSecondFactorEnabled())
+
+	targetURL, _ := url.ParseRequestURI("https://app.example.com/")
+	object := NewObject(targetURL, http.MethodGet, nil)
+
+	_, level := authorizer.GetRequiredLevel(Subject{Username: "john", AuthenticationLevel: authentication.OneFactor}, object)
+	assert.Equal(t, OneFactor, level)
+
+	_, level = authorizer.GetRequiredLevel(Subject{Username: "john", AuthenticationLevel: authentication.TwoFactor}, object)
+	assert.Equal(t, TwoFactor, level)
+
+	assert.Equal(t, 2, stub.lastRequest().Rule)
+
+	targetURL, _ = url.ParseRequestURI("https://public.example.com/")
+
+	_, level = authorizer.GetRequiredLevel(Subject{}, NewObject(targetURL, http.MethodGet, nil))
+	assert.Equal(t, Bypass, level)
+	assert.Equal(t, int32(2), stub.requests.Load())
+
+	results := authorizer.GetRuleMatchResults(Subject{}, object)
+
+	require.Len(t, results, 2)
+	assert.Equal(t, External, results[1].Rule.Policy)
+	assert.Equal(t, "external", results[1].Rule.Policy.String())
+}
+
+func TestAuthorizerShouldDenyExternalPolicyWithoutDecisionPoint(t *testing.T) {
+	authorizer := NewAuthorizer(&schema.Configuration{
+		AccessControl: schema.AccessControlConfiguration{
+			DefaultPolicy: bypass,
+			Rules: []schema.ACLRule{
+				{Domains: []string{"app.example.com"}, Policy: external},
+			},
+		},
+	})
+
+	targetURL, _ := url.ParseRequestURI("https://app.example.com/")
+
+	_, level := authorizer.GetRequiredLevel(Subject{}, NewObject(targetURL, http.MethodGet, nil))
+	assert.Equal(t, Denied, level)
+}
@@ -3,9 +3,11 @@ package authorization
 import (
 	"fmt"
 	"net"
+	"net/http"
 	"net/url"
 	"strings"
 
+	"github.com/authelia/authelia/v4/internal/authentication"
 	"github.com/authelia/authelia/v4/internal/utils"
 )
 
@@ -34,6 +36,9 @@ type Subject struct {
 	Username string
 	Groups   []string
 	IP       net.IP
+
+	// AuthenticationLevel is the current authentication level of the subject which is only used by the external policy.
+	AuthenticationLevel authentication.Level
 }
 
 // String returns a string representation of the Subject.
@@ -53,6 +58,7 @@ type Object struct {
 	Domain string
 	Path   string
 	Method string
+	Header http.Header
 }
 
 // String is a string representation of the Object.
@@ -60,18 +66,23 @@ func (o Object) String() string {
 	return o.URL.String()
 }
 
-// NewObjectRaw creates a new Object type from a URL and a method header.
-func NewObjectRaw(targetURL *url.URL, method []byte) (object Object) {
-	return NewObject(targetURL, string(method))
+// NewObjectRaw creates a new Object type from a URL, a method header, and the request headers.
+func NewObjectRaw(targetURL *url.URL, method []byte, header http.Header) (object Object) {
+	return NewObject(targetURL, string(method), header)
 }
 
-// NewObject creates a new Object type from a URL and a method header.
-func NewObject(targetURL *url.URL, method string) (object Object) {
+// NewObject creates a new Object type from a URL, a method header, and the request headers.
+func NewObject(targetURL *url.URL, method string, header http.Header) (object Object) {
+	if header == nil {
+		header = http.Header{}
+	}
+
 	return Object{
 		URL:    targetURL,
 		Domain: targetURL.Hostname(),
 		Path:   utils.URLPathFullClean(targetURL),
 		Method: method,
+		Header: header,
 	}
 }
 
@@ -84,6 +95,7 @@ type RuleMatchResult struct {
 	MatchDomain        bool
 	MatchResources     bool
 	MatchQuery         bool
+	MatchHeaders       bool
 	MatchMethods       bool
 	MatchNetworks      bool
 	MatchSubjects      bool
@@ -92,10 +104,10 @@ type RuleMatchResult struct {
 
 // IsMatch returns true if all the criteria matched.
 func (r RuleMatchResult) IsMatch() (match bool) {
-	return r.MatchDomain && r.MatchResources && r.MatchMethods && r.MatchNetworks && r.MatchSubjectsExact
+	return r.MatchDomain && r.MatchResources && r.MatchQuery && r.MatchHeaders && r.MatchMethods && r.MatchNetworks && r.MatchSubjectsExact
 }
 
 // IsPotentialMatch returns true if the rule is potentially a match.
 func (r RuleMatchResult) IsPotentialMatch() (match bool) {
-	return r.MatchDomain && r.MatchResources && r.MatchMethods && r.MatchNetworks && r.MatchSubjects && !r.MatchSubjectsExact
+	return r.MatchDomain && r.MatchResources && r.MatchQuery && r.MatchHeaders && r.MatchMethods && r.MatchNetworks && r.MatchSubjects && !r.MatchSubjectsExact
 }
@@ -1,6 +1,7 @@
 package authorization
 
 import (
+	"net/http"
 	"net/url"
 	"testing"
 
@@ -13,12 +14,13 @@ func TestShouldAppendQueryParamToURL(t *testing.T) {
 
 	require.NoError(t, err)
 
-	object := NewObject(targetURL, "GET")
+	object := NewObject(targetURL, "GET", nil)
 
 	assert.Equal(t, "https", object.URL.Scheme)
 	assert.Equal(t, "domain.example.com", object.Domain)
 	assert.Equal(t, "/api?type=none", object.Path)
 	assert.Equal(t, "GET", object.Method)
+	assert.NotNil(t, object.Header)
 }
 
 func TestShouldCreateNewObjectFromRaw(t *testing.T) {
@@ -26,13 +28,14 @@ func TestShouldCreateNewObjectFromRaw(t *testing.T) {
 
 	require.NoError(t, err)
 
-	object := NewObjectRaw(targetURL, []byte("GET"))
+	object := NewObjectRaw(targetURL, []byte("GET"), http.Header{"X-Tenant": []string{"abc"}})
 
 	assert.Equal(t, "https", object.URL.Scheme)
 	assert.Equal(t, "domain.example.com", object.Domain)
 	assert.Equal(t, "/api", object.URL.Path)
 	assert.Equal(t, "/api", object.Path)
 	assert.Equal(t, "GET", object.Method)
+	assert.Equal(t, "abc", object.Header.Get("X-Tenant"))
 }
 
 func TestShouldCleanURL(t *testing.T) {
@@ -58,7 +61,7 @@ func TestShouldCleanURL(t *testing.T) {
 			have, err := url.ParseRequestURI(tc.have + tc.havePath)
 			require.NoError(t, err)
 
-			object := NewObject(have, tc.method)
+			object := NewObject(have, tc.method, nil)
 
 			assert.Equal(t, tc.expectedScheme, object.URL.Scheme)
 			assert.Equal(t, tc.expectedDomain, object.Domain)
@@ -74,7 +77,7 @@ func TestShouldCleanURL(t *testing.T) {
 
 			have.Path, have.RawQuery = path.Path, path.RawQuery
 
-			object = NewObject(have, tc.method)
+			object = NewObject(have, tc.method, nil)
 
 			assert.Equal(t, tc.expectedScheme, object.URL.Scheme)
 			assert.Equal(t, tc.expectedDomain, object.Domain)
@@ -20,6 +20,8 @@ func NewLevel(policy string) Level {
 		return TwoFactor
 	case deny:
 		return Denied
+	case external:
+		return External
 	}
 	// By default the deny policy applies.
 	return Denied
@@ -36,6 +38,8 @@ func (l Level) String() string {
 		return twoFactor
 	case Denied:
 		return deny
+	case External:
+		return external
 	default:
 		return deny
 	}
@@ -1,15 +1,24 @@
 package commands
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
 	"net/url"
+	"os"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/authelia/authelia/v4/internal/authorization"
+	"github.com/authelia/authelia/v4/internal/configuration"
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
 	"github.com/authelia/authelia/v4/internal/configuration/validator"
 )
 
@@ -25,6 +34,7 @@ func newAccessControlCommand(ctx *CmdCtx) (cmd *cobra.Command) {
 
 	cmd.AddCommand(
 		newAccessControlCheckCommand(ctx),
+		newAccessControlDryRunCommand(ctx),
 	)
 
 	return cmd
@@ -46,6 +56,7 @@ func newAccessControlCheckCommand(ctx *CmdCtx) (cmd *cobra.Command) {
 
 	cmd.Flags().String("url", "", "the url of the object")
 	cmd.Flags().String("method", "GET", "the HTTP method of the object")
+	cmd.Flags().StringArray("header", nil, "the HTTP headers of the object in the 'Name: Value' format, can be specified multiple times")
 	cmd.Flags().String("username", "", "the username of the subject")
 	cmd.Flags().StringSlice("groups", nil, "the groups of the subject")
 	cmd.Flags().String("ip", "", "the ip of the subject")
@@ -54,6 +65,30 @@ func newAccessControlCheckCommand(ctx *CmdCtx) (cmd *cobra.Command) {
 	return cmd
 }
 
+func newAccessControlDryRunCommand(ctx *CmdCtx) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     "dry-run",
+		Short:   cmdAutheliaAccessControlDryRunShort,
+		Long:    cmdAutheliaAccessControlDryRunLong,
+		Example: cmdAutheliaAccessControlDryRunExample,
+		PreRunE: ctx.ChainRunE(
+			ctx.ConfigLoadRunE,
+		),
+		RunE: ctx.AccessControlDryRunRunE,
+
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().StringSlice(cmdFlagNameCandidate, nil, "the candidate configuration files or directories to compare against the current configuration")
+	cmd.Flags().String(cmdFlagNameRequests, "", "the JSON lines file containing the recorded requests to replay")
+	cmd.Flags().Bool("verbose", false, "enables verbose output")
+
+	_ = cmd.MarkFlagRequired(cmdFlagNameCandidate)
+	_ = cmd.MarkFlagRequired(cmdFlagNameRequests)
+
+	return cmd
+}
+
 func (ctx *CmdCtx) AccessControlCheckRunE(cmd *cobra.Command, _ []string) (err error) {
 	validator.ValidateAccessControl(ctx.config, ctx.cconfig.validator)
 
@@ -86,6 +121,207 @@ func (ctx *CmdCtx) AccessControlCheckRunE(cmd *cobra.Command, _ []string) (err e
 	return nil
 }
 
+// AccessControlDryRunRunE is the RunE for the authelia access-control dry-run command.
+func (ctx *CmdCtx) AccessControlDryRunRunE(cmd *cobra.Command, _ []string) (err error) {
+	var (
+		candidates, filters []string
+		fileFilters         []configuration.FileFilter
+		requests            string
+		verbose             bool
+	)
+
+	if candidates, err = cmd.Flags().GetStringSlice(cmdFlagNameCandidate); err != nil {
+		return err
+	}
+
+	if candidates, err = loadXNormalizedPaths(candidates); err != nil {
+		return err
+	}
+
+	if requests, err = cmd.Flags().GetString(cmdFlagNameRequests); err != nil {
+		return err
+	}
+
+	if verbose, err = cmd.Flags().GetBool("verbose"); err != nil {
+		return err
+	}
+
+	if filters, _, err = loadXEnvCLIStringSliceValue(cmd, cmdFlagEnvNameConfigFilters, cmdFlagNameConfigExpFilters); err != nil {
+		return err
+	}
+
+	if fileFilters, err = configuration.NewFileFilters(filters); err != nil {
+		return fmt.Errorf("error occurred loading configuration: flag '--%s' is invalid: %w", cmdFlagNameConfigExpFilters, err)
+	}
+
+	candidate := &schema.Configuration{}
+	val := schema.NewStructValidator()
+
+	if _, err = configuration.LoadAdvanced(val, "", candidate,
+		configuration.NewDefaultSourcesWithDefaults(candidates, fileFilters, configuration.DefaultEnvPrefix, configuration.DefaultEnvDelimiter, ctx.cconfig.defaults)...); err != nil {
+		return fmt.Errorf("error occurred loading the candidate configuration: %w", err)
+	}
+
+	if err = accessControlValidate(ctx.config, ctx.cconfig.validator); err != nil {
+		return err
+	}
+
+	if err = accessControlValidate(candidate, val); err != nil {
+		return fmt.Errorf("the candidate configuration has errors: %w", err)
+	}
+
+	file, err := os.Open(requests)
+	if err != nil {
+		return fmt.Errorf("error occurred opening the requests file: %w", err)
+	}
+
+	defer file.Close()
+
+	total, changes, err := accessControlDryRun(file, ctx.config, candidate, os.Stdout, verbose)
+	if err != nil {
+		return err
+	}
+
+	if changes != 0 {
+		return fmt.Errorf("%d of %d requests have a different result with the candidate configuration", changes, total)
+	}
+
+	fmt.Printf("\nAll %d requests have the same result with the candidate configuration.\n", total)
+
+	return nil
+}
+
+func accessControlValidate(config *schema.Configuration, val *schema.StructValidator) (err error) {
+	validator.ValidateAccessControl(config, val)
+	validator.ValidateRules(config, val)
+
+	if val.HasErrors() {
+		return errors.New("your configuration has errors")
+	}
+
+	return nil
+}
+
+type accessControlDryRunRequest struct {
+	URL      string            `json:"url"`
+	Method   string            `json:"method"`
+	Headers  map[string]string `json:"headers"`
+	Username string            `json:"username"`
+	Groups   []string          `json:"groups"`
+	IP       string            `json:"ip"`
+}
+
+// SubjectObject returns the authorization.Subject and authorization.Object for this request.
+func (r accessControlDryRunRequest) SubjectObject() (subject authorization.Subject, object authorization.Object, err error) {
+	parsedURL, err := url.ParseRequestURI(r.URL)
+	if err != nil {
+		return subject, object, err
+	}
+
+	method := r.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var header http.Header
+
+	if len(r.Headers) != 0 {
+		header = http.Header{}
+
+		for key, value := range r.Headers {
+			header.Set(key, value)
+		}
+	}
+
+	subject = authorization.Subject{
+		Username: r.Username,
+		Groups:   r.Groups,
+		IP:       net.ParseIP(r.IP),
+	}
+
+	return subject, authorization.NewObject(parsedURL, method, header), nil
+}
+
+type accessControlDryRunResult struct {
+	Position int
+	Policy   string
+}
+
+func (r accessControlDryRunResult) String() string {
+	if r.Position == 0 {
+		return fmt.Sprintf("policy '%s' from the default policy", r.Policy)
+	}
+
+	return fmt.Sprintf("policy '%s' from rule #%d", r.Policy, r.Position)
+}
+
+func newAccessControlDryRunResult(results []authorization.RuleMatchResult, defaultPolicy string) (result accessControlDryRunResult) {
+	for i, r := range results {
+		if r.IsMatch() && !r.Skipped {
+			return accessControlDryRunResult{Position: i + 1, Policy: r.Rule.Policy.String()}
+		}
+	}
+
+	return accessControlDryRunResult{Policy: defaultPolicy}
+}
+
+func accessControlDryRun(in io.Reader, current, candidate *schema.Configuration, out io.Writer, verbose bool) (total, changes int, err error) {
+	var (
+		authorizerCurrent   = authorization.NewAuthorizer(current)
+		authorizerCandidate = authorization.NewAuthorizer(candidate)
+
+		scanner = bufio.NewScanner(in)
+		line    int
+	)
+
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line++
+
+		raw := bytes.TrimSpace(scanner.Bytes())
+
+		if len(raw) == 0 {
+			continue
+		}
+
+		var (
+			request       accessControlDryRunRequest
+			subject       authorization.Subject
+			object        authorization.Object
+			before, after accessControlDryRunResult
+		)
+
+		if err = json.Unmarshal(raw, &request); err != nil {
+			return total, changes, fmt.Errorf("error occurred parsing the request on line %d: %w", line, err)
+		}
+
+		if subject, object, err = request.SubjectObject(); err != nil {
+			return total, changes, fmt.Errorf("error occurred parsing the request on line %d: %w", line, err)
+		}
+
+		total++
+
+		before = newAccessControlDryRunResult(authorizerCurrent.GetRuleMatchResults(subject, object), current.AccessControl.DefaultPolicy)
+		after = newAccessControlDryRunResult(authorizerCandidate.GetRuleMatchResults(subject, object), candidate.AccessControl.DefaultPolicy)
+
+		switch {
+		case before != after:
+			changes++
+
+			_, _ = fmt.Fprintf(out, "Line %d: request to '%s' method '%s' (%s) changed from the %s to the %s.\n", line, object.String(), object.Method, subject.String(), before, after)
+		case verbose:
+			_, _ = fmt.Fprintf(out, "Line %d: request to '%s' method '%s' (%s) is unchanged with the %s.\n", line, object.String(), object.Method, subject.String(), before)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return total, changes, fmt.Errorf("error occurred reading the requests: %w", err)
+	}
+
+	return total, changes, nil
+}
+
 func accessControlCheckWriteObjectSubject(object authorization.Object, subject authorization.Subject) {
 	output := strings.Builder{}
 
@@ -95,6 +331,18 @@ func accessControlCheckWriteObjectSubject(object authorization.Object, subject a
 		output.WriteString(fmt.Sprintf(" method '%s'", object.Method))
 	}
 
+	if len(object.Header) != 0 {
+		names := make([]string, 0, len(object.Header))
+
+		for name := range object.Header {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		output.WriteString(fmt.Sprintf(" headers '%s'", strings.Join(names, ",")))
+	}
+
 	if subject.Username != "" {
 		output.WriteString(fmt.Sprintf(" username '%s'", subject.Username))
 	}
@@ -195,6 +443,16 @@ func getSubjectAndObjectFromFlags(cmd *cobra.Command) (subject authorization.Sub
 		return subject, object, err
 	}
 
+	headers, err := cmd.Flags().GetStringArray("header")
+	if err != nil {
+		return subject, object, err
+	}
+
+	header, err := parseHeaderFlags(headers)
+	if err != nil {
+		return subject, object, err
+	}
+
 	username, err := cmd.Flags().GetString("username")
 	if err != nil {
 		return subject, object, err
@@ -218,7 +476,23 @@ func getSubjectAndObjectFromFlags(cmd *cobra.Command) (subject authorization.Sub
 		IP:       parsedIP,
 	}
 
-	object = authorization.NewObject(parsedURL, method)
+	object = authorization.NewObject(parsedURL, method, header)
 
 	return subject, object, nil
 }
+
+func parseHeaderFlags(headers []string) (header http.Header, err error) {
+	header = http.Header{}
+
+	for _, h := range headers {
+		name, value, found := strings.Cut(h, ":")
+
+		if name = strings.TrimSpace(name); !found || name == "" {
+			return nil, fmt.Errorf("header '%s' is invalid: must be in the 'Name: Value' format", h)
+		}
+
+		header.Add(name, strings.TrimSpace(value))
+	}
+
+	return header, nil
+}
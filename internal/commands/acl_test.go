@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+)
+
+func TestAccessControlDryRun(t *testing.T) {
+	current := &schema.Configuration{
+		AccessControl: schema.AccessControlConfiguration{
+			DefaultPolicy: "deny",
+			Rules: []schema.ACLRule{
+				{Domains: []string{"public.example.com"}, Policy: "bypass"},
+				{Domains: []string{"admin.example.com"}, Subjects: [][]string{{"group:admins"}}, Policy: "two_factor"},
+				{Domains: []string{"*.example.com"}, Policy: "one_factor"},
+			},
+		},
+	}
+
+	candidate := &schema.Configuration{
+		AccessControl: schema.AccessControlConfiguration{
+			DefaultPolicy: "deny",
+			Rules: []schema.ACLRule{
+				{Domains: []string{"public.example.com"}, Policy: "bypass"},
+				{Domains: []string{"*.example.com"}, Policy: "one_factor"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		have            string
+		verbose         bool
+		total, changes  int
+		expected        []string
+		expectedMissing []string
+		err             string
+	}{
+		{
+			"ShouldReportNoChanges",
+			`{"url":"https://public.example.com/","method":"GET"}` + "\n\n" + `{"url":"https://other.com/"}`,
+			false,
+			2, 0,
+			nil,
+			[]string{"Line 1", "Line 3"},
+			"",
+		},
+		{
+			"ShouldReportNoChangesVerbose",
+			`{"url":"https://public.example.com/","method":"GET"}`,
+			true,
+			1, 0,
+			[]string{"Line 1: request to 'https://public.example.com/' method 'GET' (username= groups= ip=<nil>) is unchanged with the policy 'bypass' from rule #1."},
+			nil,
+			"",
+		},
+		{
+			"ShouldReportPolicyChange",
+			`{"url":"https://admin.example.com/","method":"POST","username":"john","groups":["admins"],"ip":"192.168.1.1"}`,
+			false,
+			1, 1,
+			[]string{"Line 1: request to 'https://admin.example.com/' method 'POST' (username=john groups=admins ip=192.168.1.1) changed from the policy 'two_factor' from rule #2 to the policy 'one_factor' from rule #2."},
+			nil,
+			"",
+		},
+		{
+			"ShouldReportPositionChange",
+			`{"url":"https://app.example.com/","username":"john"}`,
+			false,
+			1, 1,
+			[]string{"changed from the policy 'one_factor' from rule #3 to the policy 'one_factor' from rule #2."},
+			nil,
+			"",
+		},
+		{
+			"ShouldErrorOnBadJSON",
+			`{"url":"https://app.example.com/"}` + "\n" + `{"url":`,
+			false,
+			1, 1,
+			nil,
+			nil,
+			"error occurred parsing the request on line 2: unexpected end of JSON input",
+		},
+		{
+			"ShouldErrorOnBadURL",
+			`{"url":"example.com"}`,
+			false,
+			0, 0,
+			nil,
+			nil,
+			"error occurred parsing the request on line 1: parse \"example.com\": invalid URI for request",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+
+			total, changes, err := accessControlDryRun(strings.NewReader(tc.have), current, candidate, out, tc.verbose)
+
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err)
+			}
+
+			assert.Equal(t, tc.total, total)
+			assert.Equal(t, tc.changes, changes)
+
+			for _, expected := range tc.expected {
+				assert.Contains(t, out.String(), expected)
+			}
+
+			for _, missing := range tc.expectedMissing {
+				assert.NotContains(t, out.String(), missing)
+			}
+		})
+	}
+}
+
+func TestAccessControlDryRunShouldMatchHeaders(t *testing.T) {
+	rule := func(tenant string) *schema.Configuration {
+		return &schema.Configuration{
+			AccessControl: schema.AccessControlConfiguration{
+				DefaultPolicy: "deny",
+				Rules: []schema.ACLRule{
+					{
+						Domains: []string{"app.example.com"},
+						Headers: [][]schema.ACLQueryRule{{{Operator: "equal", Key: "X-Tenant", Value: tenant}}},
+						Policy:  "one_factor",
+					},
+				},
+			},
+		}
+	}
+
+	out := &bytes.Buffer{}
+
+	total, changes, err := accessControlDryRun(strings.NewReader(`{"url":"https://app.example.com/","headers":{"x-tenant":"abc"}}`), rule("abc"), rule("xyz"), out, false)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, 1, changes)
+	assert.Contains(t, out.String(), "changed from the policy 'one_factor' from rule #1 to the policy 'deny' from the default policy.")
+}
@@ -74,7 +74,24 @@ Notes:
 authelia access-control check-policy --config config.yml --url https://example.com --username john
 authelia access-control check-policy --config config.yml --url https://example.com --groups admin,public
 authelia access-control check-policy --config config.yml --url https://example.com --username john --method GET
-authelia access-control check-policy --config config.yml --url https://example.com --username john --method GET --verbose`
+authelia access-control check-policy --config config.yml --url https://example.com --username john --method GET --verbose
+authelia access-control check-policy --config config.yml --url https://example.com --header 'X-Tenant: example' --header 'User-Agent: curl/7.88.1'`
+
+	cmdAutheliaAccessControlDryRunShort = "Replays recorded requests against the current and a candidate configuration to detect policy changes"
+
+	cmdAutheliaAccessControlDryRunLong = `
+Replays recorded requests against the access control rules of the current and a candidate configuration and reports
+every request where the resulting policy or the position of the matched rule changes.
+
+The requests file is in the JSON lines format where each line is a JSON object with the following keys: url, method,
+headers, username, groups, and ip. The headers key is an object of header names and values. Empty lines are ignored and
+the method defaults to GET.
+
+This command exits with a non-zero exit code if any request has a different result with the candidate configuration.
+`
+
+	cmdAutheliaAccessControlDryRunExample = `authelia access-control dry-run --config config.yml --candidate config.candidate.yml --requests requests.jsonl
+authelia access-control dry-run --config config.yml --candidate config.candidate.yml --requests requests.jsonl --verbose`
 
 	cmdAutheliaStorageShort = "Manage the Authelia storage"
 
@@ -98,7 +115,9 @@ This subcommand allows management of the storage encryption.`
 
 	cmdAutheliaStorageEncryptionCheckLong = `Checks the encryption key against the database data.
 
-This is useful for validating all data that can be encrypted is intact.`
+This is useful for validating all data that can be encrypted is intact. The verbose output also shows how many rows
+in each table are encrypted with each key which is useful for monitoring the progress of the re-encryption of values
+after the encryption key is changed.`
 
 	cmdAutheliaStorageEncryptionCheckExample = `authelia storage encryption check
 authelia storage encryption check --verbose
@@ -109,11 +128,117 @@ authelia storage encryption check --verbose --encryption-key b3453fde-ecc2-4a1f-
 
 	cmdAutheliaStorageEncryptionChangeKeyLong = `Changes the encryption key.
 
-This subcommand allows you to change the encryption key of an Authelia SQL database.`
+This subcommand allows you to change the encryption key of an Authelia SQL database. Every value is re-encrypted in a
+single transaction. Alternatively the previous encryption key can be added to the storage.encryption.decryption_keys
+configuration option when the encryption key is changed in the configuration in which case the values are re-encrypted
+in batches in the background.`
 
 	cmdAutheliaStorageEncryptionChangeKeyExample = `authelia storage encryption change-key --config config.yml --new-encryption-key 0e95cb49-5804-4ad9-be82-bb04a9ddecd8
 authelia storage encryption change-key --encryption-key b3453fde-ecc2-4a1f-9422-2707ddbed495 --new-encryption-key 0e95cb49-5804-4ad9-be82-bb04a9ddecd8 --postgres.host postgres --postgres.password autheliapw`
 
+	cmdAutheliaStorageNotificationsShort = "Manage the notification outbox"
+
+	cmdAutheliaStorageNotificationsLong = `Manage the notification outbox.
+
+This subcommand allows listing, retrying, and purging the notifications in the notification outbox.`
+
+	cmdAutheliaStorageNotificationsExample = `authelia storage notifications --help`
+
+	cmdAutheliaStorageNotificationsListShort = "List the notifications in the outbox"
+
+	cmdAutheliaStorageNotificationsListLong = `List the notifications in the outbox.
+
+This subcommand allows listing the notifications in the outbox optionally filtered by status.`
+
+	cmdAutheliaStorageNotificationsListExample = `authelia storage notifications list
+authelia storage notifications list --status failed
+authelia storage notifications list --config config.yml
+authelia storage notifications list --encryption-key b3453fde-ecc2-4a1f-9422-2707ddbed495 --postgres.host postgres --postgres.password autheliapw`
+
+	cmdAutheliaStorageNotificationsRetryShort = "Retry failed notifications in the outbox"
+
+	cmdAutheliaStorageNotificationsRetryLong = `Retry failed notifications in the outbox.
+
+This subcommand allows resetting failed notifications so they are attempted again by the outbox delivery.`
+
+	cmdAutheliaStorageNotificationsRetryExample = `authelia storage notifications retry 10
+authelia storage notifications retry --all
+authelia storage notifications retry --all --config config.yml
+authelia storage notifications retry --all --encryption-key b3453fde-ecc2-4a1f-9422-2707ddbed495 --postgres.host postgres --postgres.password autheliapw`
+
+	cmdAutheliaStorageNotificationsPurgeShort = "Purge notifications from the outbox"
+
+	cmdAutheliaStorageNotificationsPurgeLong = `Purge notifications from the outbox.
+
+This subcommand allows deleting the notifications with a status from the outbox, by default the notifications which
+were sent successfully.`
+
+	cmdAutheliaStorageNotificationsPurgeExample = `authelia storage notifications purge
+authelia storage notifications purge --older-than 168h
+authelia storage notifications purge --status failed --older-than 720h
+authelia storage notifications purge --config config.yml
+authelia storage notifications purge --encryption-key b3453fde-ecc2-4a1f-9422-2707ddbed495 --postgres.host postgres --postgres.password autheliapw`
+
+	cmdAutheliaStoragePruneShort = "Prune data which is no longer retained"
+
+	cmdAutheliaStoragePruneLong = `Prune data which is no longer retained.
+
+This subcommand allows deleting the data which is no longer retained according to the storage retention configuration
+immediately. The data is otherwise pruned by the daemon periodically unless this is disabled.`
+
+	cmdAutheliaStoragePruneExample = `authelia storage prune
+authelia storage prune --dry-run
+authelia storage prune --config config.yml
+authelia storage prune --encryption-key b3453fde-ecc2-4a1f-9422-2707ddbed495 --postgres.host postgres --postgres.password autheliapw`
+
+	cmdAutheliaStorageCopyShort = "Copy the data to another storage backend"
+
+	cmdAutheliaStorageCopyLong = `Copy the data to another storage backend.
+
+This subcommand allows copying all of the data from the configured storage backend to the storage backend configured in
+the target configuration files, for example to migrate from SQLite to PostgreSQL. Only the storage section of the target
+configuration files is used.
+
+The target schema is migrated to the latest version before the data is copied and it must not contain any data. The
+identifiers of every row are preserved, and the encrypted values are re-encrypted if the target uses a different
+encryption key. The number of rows in each table is verified once the copy is complete.`
+
+	cmdAutheliaStorageCopyExample = `authelia storage copy --to target.yml
+authelia storage copy --to target.yml --batch-size 500
+authelia storage copy --config config.yml --to target.yml
+authelia storage copy --encryption-key b3453fde-ecc2-4a1f-9422-2707ddbed495 --sqlite.path db.sqlite3 --to target.yml`
+
+	cmdAutheliaStorageBackupShort = "Perform a backup of all of the data"
+
+	cmdAutheliaStorageBackupLong = `Perform a backup of all of the data.
+
+This subcommand allows writing all of the data in the storage backend to a single file encrypted with a passphrase. The
+backup doesn't depend on the storage backend or the storage encryption key, so it can be restored to any of the storage
+backends with the restore subcommand. The data is read within a single read-only transaction so the backup is a
+consistent snapshot.
+
+The passphrase is read from the terminal if it's not provided with the --passphrase flag.`
+
+	cmdAutheliaStorageBackupExample = `authelia storage backup
+authelia storage backup --file authelia.backup
+authelia storage backup --config config.yml
+authelia storage backup --encryption-key b3453fde-ecc2-4a1f-9422-2707ddbed495 --postgres.host postgres --postgres.password autheliapw`
+
+	cmdAutheliaStorageRestoreShort = "Restore all of the data from a backup"
+
+	cmdAutheliaStorageRestoreLong = `Restore all of the data from a backup.
+
+This subcommand allows restoring the data from a backup created with the backup subcommand. The storage must not contain
+any data. The schema is migrated to the schema version of the backup before the data is restored and to the latest
+schema version afterwards. Backups from a schema version newer than the storage schema version are refused. The data
+is restored within a single transaction so none of it is kept if the backup can't be fully restored.
+
+The passphrase is read from the terminal if it's not provided with the --passphrase flag.`
+
+	cmdAutheliaStorageRestoreExample = `authelia storage restore authelia.backup
+authelia storage restore --config config.yml authelia.backup
+authelia storage restore --encryption-key b3453fde-ecc2-4a1f-9422-2707ddbed495 --postgres.host postgres --postgres.password autheliapw authelia.backup`
+
 	cmdAutheliaStorageUserShort = "Manages user settings"
 
 	cmdAutheliaStorageUserLong = `Manages user settings.
@@ -262,7 +387,8 @@ authelia storage user totp generate john --algorithm SHA512 --config config.yml
 
 	cmdAutheliaStorageUserTOTPDeleteLong = `Delete a TOTP configuration for a user.
 
-This subcommand allows deleting a TOTP configuration directly from the database for a given user.`
+This subcommand allows deleting a TOTP configuration directly from the database for a given user. The user is notified
+of the removal via the configured notifier unless the notification is disabled.`
 
 	cmdAutheliaStorageUserTOTPDeleteExample = `authelia storage user totp delete john
 authelia storage user totp delete john --config config.yml
@@ -392,6 +518,88 @@ schema version of the database.`
 authelia storage migrate down --target 20 --config config.yml
 authelia storage migrate down --target 20 --encryption-key b3453fde-ecc2-4a1f-9422-2707ddbed495 --postgres.host postgres --postgres.password autheliapw`
 
+	cmdAutheliaUsersShort = "Manage the users in the file authentication backend"
+
+	cmdAutheliaUsersLong = `Manage the users in the file authentication backend.
+
+This subcommand allows managing the users in the file authentication database without editing the YAML file manually.
+Passwords are hashed using the configured password hashing settings. If Authelia is running with the watch option of
+the file authentication backend enabled the changes are applied automatically.`
+
+	cmdAutheliaUsersExample = `authelia users --help`
+
+	cmdAutheliaUsersAddShort = "Add a user"
+
+	cmdAutheliaUsersAddLong = `Add a user.
+
+This subcommand allows adding a user to the file authentication database. If the database doesn't exist it's created.`
+
+	cmdAutheliaUsersAddExample = `authelia users add john --display-name "John Doe" --email john.doe@example.com --groups admins,dev
+authelia users add john --display-name "John Doe" --email john.doe@example.com --config config.yml
+authelia users add john --display-name "John Doe" --random --path users_database.yml`
+
+	cmdAutheliaUsersDeleteShort = "Delete a user"
+
+	cmdAutheliaUsersDeleteLong = `Delete a user.
+
+This subcommand allows deleting a user from the file authentication database.`
+
+	cmdAutheliaUsersDeleteExample = `authelia users delete john
+authelia users delete john --config config.yml
+authelia users delete john --path users_database.yml`
+
+	cmdAutheliaUsersDisableShort = "Disable a user"
+
+	cmdAutheliaUsersDisableLong = `Disable a user.
+
+This subcommand allows disabling a user in the file authentication database which prevents them from logging in.`
+
+	cmdAutheliaUsersDisableExample = `authelia users disable john
+authelia users disable john --config config.yml
+authelia users disable john --path users_database.yml`
+
+	cmdAutheliaUsersEnableShort = "Enable a user"
+
+	cmdAutheliaUsersEnableLong = `Enable a user.
+
+This subcommand allows enabling a previously disabled user in the file authentication database.`
+
+	cmdAutheliaUsersEnableExample = `authelia users enable john
+authelia users enable john --config config.yml
+authelia users enable john --path users_database.yml`
+
+	cmdAutheliaUsersSetPasswordShort = "Set the password of a user"
+
+	cmdAutheliaUsersSetPasswordLong = `Set the password of a user.
+
+This subcommand allows setting the password of a user in the file authentication database.`
+
+	cmdAutheliaUsersSetPasswordExample = `authelia users set-password john
+authelia users set-password john --config config.yml
+authelia users set-password john --random --path users_database.yml
+authelia users set-password john --random --require-change`
+
+	cmdAutheliaUsersSetGroupsShort = "Set the groups of a user"
+
+	cmdAutheliaUsersSetGroupsLong = `Set the groups of a user.
+
+This subcommand allows setting the groups of a user in the file authentication database. The existing groups of the
+user are replaced, and if no groups are provided all groups are removed from the user.`
+
+	cmdAutheliaUsersSetGroupsExample = `authelia users set-groups john admins dev
+authelia users set-groups john admins dev --config config.yml
+authelia users set-groups john --path users_database.yml`
+
+	cmdAutheliaUsersListShort = "List the users"
+
+	cmdAutheliaUsersListLong = `List the users.
+
+This subcommand allows listing the users in the file authentication database.`
+
+	cmdAutheliaUsersListExample = `authelia users list
+authelia users list --config config.yml
+authelia users list --path users_database.yml`
+
 	cmdAutheliaValidateConfigShort = "Check a configuration against the internal configuration validation mechanisms"
 
 	cmdAutheliaValidateConfigLong = `Check a configuration against the internal configuration validation mechanisms.
@@ -426,6 +634,19 @@ authelia crypto rand --charset numeric
 authelia crypto rand --charset numeric-hex
 authelia crypto rand --characters 0123456789ABCDEF`
 
+	cmdAutheliaCryptoBloomShort = "Generate a breached password bloom filter"
+
+	cmdAutheliaCryptoBloomLong = `Generate a breached password bloom filter.
+
+This subcommand allows generating a bloom filter file for the breached password policy from either a directory of
+range files or a single file with one 'HASH:COUNT' line per SHA-1 sum. The input is read twice, once to count the sums
+and once to add them to the filter.`
+
+	cmdAutheliaCryptoBloomExample = `authelia crypto bloom --help
+authelia crypto bloom --directory /data/pwned-passwords --output /config/breached.bloom
+authelia crypto bloom --file pwned-passwords-sha1-ordered-by-hash.txt --output /config/breached.bloom
+authelia crypto bloom --file pwned-passwords-sha1-ordered-by-hash.txt --output /config/breached.bloom --probability 0.0001 --min-count 10`
+
 	cmdAutheliaCryptoHashShort = "Perform cryptographic hash operations"
 
 	cmdAutheliaCryptoHashLong = `Perform cryptographic hash operations.
@@ -595,6 +816,9 @@ const (
 	cmdFlagNameConfigExpFilters = "config.experimental.filters"
 	cmdFlagEnvNameConfigFilters = "X_AUTHELIA_CONFIG_FILTERS"
 
+	cmdFlagNameCandidate = "candidate"
+	cmdFlagNameRequests  = "requests"
+
 	cmdFlagNameCharSet     = "charset"
 	cmdFlagValueCharSet    = "alphanumeric"
 	cmdFlagUsageCharset    = "sets the charset for the random password, options are 'ascii', 'alphanumeric', 'alphabetic', 'numeric', 'numeric-hex', and 'rfc3986'"
@@ -605,27 +829,42 @@ const (
 
 	cmdFlagNameNewEncryptionKey = "new-encryption-key"
 
-	cmdFlagNameFile        = "file"
-	cmdFlagNameUsers       = "users"
-	cmdFlagNameServices    = "services"
-	cmdFlagNameSectors     = "sectors"
-	cmdFlagNameIdentifier  = "identifier"
-	cmdFlagNameService     = "service"
-	cmdFlagNameSector      = "sector"
-	cmdFlagNameDescription = "description"
-	cmdFlagNameAll         = "all"
-	cmdFlagNameKeyID       = "kid"
-	cmdFlagNameVerbose     = "verbose"
-	cmdFlagNameSecret      = "secret"
-	cmdFlagNameSecretSize  = "secret-size"
-	cmdFlagNamePeriod      = "period"
-	cmdFlagNameDigits      = "digits"
-	cmdFlagNameAlgorithm   = "algorithm"
-	cmdFlagNameIssuer      = "issuer"
-	cmdFlagNameForce       = "force"
-	cmdFlagNamePath        = "path"
-	cmdFlagNameTarget      = "target"
-	cmdFlagNameDestroyData = "destroy-data"
+	cmdFlagNameTo         = "to"
+	cmdFlagNameBatchSize  = "batch-size"
+	cmdFlagNamePassphrase = "passphrase"
+
+	cmdFlagNameFile          = "file"
+	cmdFlagNameUsers         = "users"
+	cmdFlagNameServices      = "services"
+	cmdFlagNameSectors       = "sectors"
+	cmdFlagNameIdentifier    = "identifier"
+	cmdFlagNameService       = "service"
+	cmdFlagNameSector        = "sector"
+	cmdFlagNameDescription   = "description"
+	cmdFlagNameAll           = "all"
+	cmdFlagNameKeyID         = "kid"
+	cmdFlagNameVerbose       = "verbose"
+	cmdFlagNameSecret        = "secret"
+	cmdFlagNameSecretSize    = "secret-size"
+	cmdFlagNamePeriod        = "period"
+	cmdFlagNameDigits        = "digits"
+	cmdFlagNameAlgorithm     = "algorithm"
+	cmdFlagNameIssuer        = "issuer"
+	cmdFlagNameForce         = "force"
+	cmdFlagNamePath          = "path"
+	cmdFlagNameTarget        = "target"
+	cmdFlagNameDestroyData   = "destroy-data"
+	cmdFlagNameDisplayName   = "display-name"
+	cmdFlagNameEmail         = "email"
+	cmdFlagNameGroups        = "groups"
+	cmdFlagNameDisabled      = "disabled"
+	cmdFlagNameRequireChange = "require-change"
+	cmdFlagNameOutput        = "output"
+	cmdFlagNameProbability   = "probability"
+	cmdFlagNameMinCount      = "min-count"
+	cmdFlagNameStatus        = "status"
+	cmdFlagNameOlderThan     = "older-than"
+	cmdFlagNameDryRun        = "dry-run"
 
 	cmdFlagNameEncryptionKey      = "encryption-key"
 	cmdFlagNameSQLite3Path        = "sqlite.path"
@@ -655,6 +894,7 @@ const (
 
 	cmdUseCrypto      = "crypto"
 	cmdUseRand        = "rand"
+	cmdUseBloom       = "bloom"
 	cmdUseCertificate = "certificate"
 	cmdUseGenerate    = "generate"
 	cmdUseValidate    = "validate"
@@ -18,7 +18,6 @@ import (
 	"github.com/authelia/authelia/v4/internal/logging"
 	"github.com/authelia/authelia/v4/internal/metrics"
 	"github.com/authelia/authelia/v4/internal/middlewares"
-	"github.com/authelia/authelia/v4/internal/notification"
 	"github.com/authelia/authelia/v4/internal/ntp"
 	"github.com/authelia/authelia/v4/internal/oidc"
 	"github.com/authelia/authelia/v4/internal/random"
@@ -68,6 +67,8 @@ func NewCmdCtxConfig() *CmdCtxConfig {
 type CmdCtxConfig struct {
 	defaults  configuration.Source
 	sources   []configuration.Source
+	files     []string
+	filters   []configuration.FileFilter
 	keys      []string
 	validator *schema.StructValidator
 }
@@ -138,38 +139,33 @@ func (ctx *CmdCtx) LoadProviders() (warns, errs []error) {
 	ctx.providers.Authorizer = authorization.NewAuthorizer(ctx.config)
 	ctx.providers.NTP = ntp.NewProvider(&ctx.config.NTP)
 	ctx.providers.PasswordPolicy = middlewares.NewPasswordPolicyProvider(ctx.config.PasswordPolicy)
+	ctx.providers.PasswordChanges = middlewares.NewPasswordChangeCache(middlewares.PasswordChangeCacheLifespan)
 	ctx.providers.Regulator = regulation.NewRegulator(ctx.config.Regulation, ctx.providers.StorageProvider, utils.RealClock{})
 	ctx.providers.SessionProvider = session.NewProvider(ctx.config.Session, ctx.trusted)
 	ctx.providers.TOTP = totp.NewTimeBasedProvider(ctx.config.TOTP)
 
+	if ctx.config.Telemetry.Metrics.Enabled {
+		ctx.providers.Metrics = metrics.NewPrometheus()
+	}
+
 	var err error
 
-	switch {
-	case ctx.config.AuthenticationBackend.File != nil:
-		ctx.providers.UserProvider = authentication.NewFileUserProvider(ctx.config.AuthenticationBackend.File)
-	case ctx.config.AuthenticationBackend.LDAP != nil:
-		ctx.providers.UserProvider = authentication.NewLDAPUserProvider(ctx.config.AuthenticationBackend, ctx.trusted)
+	ctx.providers.UserProvider = getUserProvider(ctx)
+
+	if ctx.providers.UserProvider != nil && ctx.config.AuthenticationBackend.Cache.Enable {
+		ctx.providers.UserProvider = authentication.NewCachedUserProvider(ctx.providers.UserProvider, ctx.config.AuthenticationBackend.Cache, ctx.providers.Metrics)
 	}
 
 	if ctx.providers.Templates, err = templates.New(templates.Config{EmailTemplatesPath: ctx.config.Notifier.TemplatePath}); err != nil {
 		errs = append(errs, err)
 	}
 
-	switch {
-	case ctx.config.Notifier.SMTP != nil:
-		ctx.providers.Notifier = notification.NewSMTPNotifier(ctx.config.Notifier.SMTP, ctx.trusted)
-	case ctx.config.Notifier.FileSystem != nil:
-		ctx.providers.Notifier = notification.NewFileNotifier(*ctx.config.Notifier.FileSystem)
-	}
+	ctx.providers.Notifier = getNotifierProvider(ctx, ctx.providers.StorageProvider, ctx.providers.Templates)
 
 	if ctx.providers.OpenIDConnect, err = oidc.NewOpenIDConnectProvider(ctx.config.IdentityProviders.OIDC, ctx.providers.StorageProvider, ctx.providers.Templates); err != nil {
 		errs = append(errs, err)
 	}
 
-	if ctx.config.Telemetry.Metrics.Enabled {
-		ctx.providers.Metrics = metrics.NewPrometheus()
-	}
-
 	return warns, errs
 }
 
@@ -329,6 +325,8 @@ func (ctx *CmdCtx) ConfigLoadRunE(cmd *cobra.Command, _ []string) (err error) {
 		ctx.cconfig = NewCmdCtxConfig()
 	}
 
+	ctx.cconfig.files, ctx.cconfig.filters = configs, filters
+
 	if ctx.cconfig.keys, err = configuration.LoadAdvanced(
 		ctx.cconfig.validator,
 		"",
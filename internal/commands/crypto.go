@@ -30,6 +30,7 @@ func newCryptoCmd(ctx *CmdCtx) (cmd *cobra.Command) {
 		newCryptoCertificateCmd(ctx),
 		newCryptoHashCmd(ctx),
 		newCryptoPairCmd(ctx),
+		newCryptoBloomCmd(ctx),
 	)
 
 	return cmd
@@ -0,0 +1,212 @@
+package commands
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // Usage is required by the breached password range format.
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authelia/authelia/v4/internal/middlewares"
+)
+
+func newCryptoBloomCmd(ctx *CmdCtx) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     cmdUseBloom,
+		Short:   cmdAutheliaCryptoBloomShort,
+		Long:    cmdAutheliaCryptoBloomLong,
+		Example: cmdAutheliaCryptoBloomExample,
+		Args:    cobra.NoArgs,
+		RunE:    ctx.CryptoBloomRunE,
+
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().String(cmdFlagNameDirectory, "", "directory of range files named after the first 5 characters of the SHA-1 sum")
+	cmd.Flags().String(cmdFlagNameFile, "", "file with one 'HASH:COUNT' line per SHA-1 sum")
+	cmd.Flags().StringP(cmdFlagNameOutput, "o", "breached.bloom", "path to write the bloom filter to")
+	cmd.Flags().Float64(cmdFlagNameProbability, 0.001, "false positive probability of the bloom filter")
+	cmd.Flags().Int(cmdFlagNameMinCount, 1, "minimum number of times a password must have appeared in a breach to be added")
+
+	cmd.MarkFlagsMutuallyExclusive(cmdFlagNameDirectory, cmdFlagNameFile)
+
+	return cmd
+}
+
+// CryptoBloomRunE is the RunE for the authelia crypto bloom command.
+func (ctx *CmdCtx) CryptoBloomRunE(cmd *cobra.Command, _ []string) (err error) {
+	var (
+		directory, file, output string
+		probability             float64
+		minCount                int
+	)
+
+	if directory, err = cmd.Flags().GetString(cmdFlagNameDirectory); err != nil {
+		return err
+	}
+
+	if file, err = cmd.Flags().GetString(cmdFlagNameFile); err != nil {
+		return err
+	}
+
+	if output, err = cmd.Flags().GetString(cmdFlagNameOutput); err != nil {
+		return err
+	}
+
+	if probability, err = cmd.Flags().GetFloat64(cmdFlagNameProbability); err != nil {
+		return err
+	}
+
+	if minCount, err = cmd.Flags().GetInt(cmdFlagNameMinCount); err != nil {
+		return err
+	}
+
+	if probability <= 0 || probability >= 1 {
+		return fmt.Errorf("the probability must be more than 0 and less than 1 but it's configured as %g", probability)
+	}
+
+	var walk func(fn func(sum [sha1.Size]byte)) error
+
+	switch {
+	case directory != "":
+		walk = func(fn func(sum [sha1.Size]byte)) error {
+			return cryptoBloomWalkDirectory(directory, minCount, fn)
+		}
+	case file != "":
+		walk = func(fn func(sum [sha1.Size]byte)) error {
+			return cryptoBloomWalkFile(file, "", minCount, fn)
+		}
+	default:
+		return fmt.Errorf("either the '%s' or the '%s' flag must be specified", cmdFlagNameDirectory, cmdFlagNameFile)
+	}
+
+	var n uint64
+
+	if err = walk(func(_ [sha1.Size]byte) { n++ }); err != nil {
+		return err
+	}
+
+	filter := middlewares.NewBreachedPasswordBloomFilter(n, probability)
+
+	if err = walk(filter.Add); err != nil {
+		return err
+	}
+
+	var f *os.File
+
+	if f, err = os.Create(output); err != nil {
+		return fmt.Errorf("error occurred creating the bloom filter file: %w", err)
+	}
+
+	w := bufio.NewWriter(f)
+
+	if _, err = filter.WriteTo(w); err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("error occurred writing the bloom filter file: %w", err)
+	}
+
+	if err = w.Flush(); err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("error occurred writing the bloom filter file: %w", err)
+	}
+
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("error occurred closing the bloom filter file: %w", err)
+	}
+
+	fmt.Printf("Successfully wrote a bloom filter of %d breached passwords to '%s'\n", n, output)
+
+	return nil
+}
+
+func cryptoBloomWalkDirectory(directory string, minCount int, fn func(sum [sha1.Size]byte)) (err error) {
+	var entries []os.DirEntry
+
+	if entries, err = os.ReadDir(directory); err != nil {
+		return fmt.Errorf("error occurred reading the range directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		prefix := strings.TrimSuffix(entry.Name(), ".txt")
+
+		if len(prefix) != 5 {
+			continue
+		}
+
+		if _, err = hex.DecodeString(prefix + "0"); err != nil {
+			continue
+		}
+
+		if err = cryptoBloomWalkFile(filepath.Join(directory, entry.Name()), prefix, minCount, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func cryptoBloomWalkFile(name, prefix string, minCount int, fn func(sum [sha1.Size]byte)) (err error) {
+	var f *os.File
+
+	if f, err = os.Open(name); err != nil {
+		return fmt.Errorf("error occurred opening the file '%s': %w", name, err)
+	}
+
+	defer f.Close()
+
+	return cryptoBloomWalk(f, prefix, minCount, fn)
+}
+
+func cryptoBloomWalk(r io.Reader, prefix string, minCount int, fn func(sum [sha1.Size]byte)) (err error) {
+	scanner := bufio.NewScanner(r)
+
+	var (
+		sum   [sha1.Size]byte
+		count int
+		line  int
+	)
+
+	for scanner.Scan() {
+		line++
+
+		hash, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+
+		if !found {
+			continue
+		}
+
+		if count, err = strconv.Atoi(value); err != nil {
+			return fmt.Errorf("error occurred parsing the count on line %d: %w", line, err)
+		}
+
+		if count < minCount {
+			continue
+		}
+
+		hash = prefix + hash
+
+		if len(hash) != sha1.Size*2 {
+			return fmt.Errorf("error occurred parsing the hash on line %d: the hash is not a SHA-1 sum", line)
+		}
+
+		if _, err = hex.Decode(sum[:], []byte(hash)); err != nil {
+			return fmt.Errorf("error occurred parsing the hash on line %d: %w", line, err)
+		}
+
+		fn(sum)
+	}
+
+	return scanner.Err()
+}
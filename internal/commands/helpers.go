@@ -1,25 +1,94 @@
 package commands
 
 import (
+	"crypto/x509"
 	"encoding/base32"
 	"errors"
 	"fmt"
 
 	"github.com/spf13/pflag"
 
+	"github.com/authelia/authelia/v4/internal/authentication"
 	"github.com/authelia/authelia/v4/internal/configuration/schema"
 	"github.com/authelia/authelia/v4/internal/model"
+	"github.com/authelia/authelia/v4/internal/notification"
 	"github.com/authelia/authelia/v4/internal/storage"
+	"github.com/authelia/authelia/v4/internal/templates"
 )
 
 func getStorageProvider(ctx *CmdCtx) (provider storage.Provider) {
+	return getStorageProviderFromConfig(ctx.config, ctx.trusted)
+}
+
+func getStorageProviderFromConfig(config *schema.Configuration, trusted *x509.CertPool) (provider storage.Provider) {
+	switch {
+	case config.Storage.PostgreSQL != nil:
+		return storage.NewPostgreSQLProvider(config, trusted)
+	case config.Storage.MySQL != nil:
+		return storage.NewMySQLProvider(config, trusted)
+	case config.Storage.Local != nil:
+		return storage.NewSQLiteProvider(config, trusted)
+	default:
+		return nil
+	}
+}
+
+func getUserProvider(ctx *CmdCtx) (provider authentication.UserProvider) {
+	if len(ctx.config.AuthenticationBackend.Chain) != 0 {
+		backends := make([]authentication.ChainedUserProviderBackend, 0, len(ctx.config.AuthenticationBackend.Chain))
+
+		for _, name := range ctx.config.AuthenticationBackend.Chain {
+			if provider = getUserProviderBackend(ctx, name); provider != nil {
+				backends = append(backends, authentication.ChainedUserProviderBackend{Name: name, Provider: provider})
+			}
+		}
+
+		return authentication.NewChainedUserProvider(backends...)
+	}
+
+	switch {
+	case ctx.config.AuthenticationBackend.File != nil:
+		return getUserProviderBackend(ctx, schema.AuthenticationBackendFile)
+	case ctx.config.AuthenticationBackend.LDAP != nil:
+		return getUserProviderBackend(ctx, schema.AuthenticationBackendLDAP)
+	default:
+		return nil
+	}
+}
+
+func getNotifierProvider(ctx *CmdCtx, store storage.Provider, tmpl *templates.Provider) (notifier notification.Notifier) {
+	switch {
+	case ctx.config.Notifier.SMTP != nil:
+		notifier = notification.NewSMTPNotifier(ctx.config.Notifier.SMTP, ctx.trusted)
+	case ctx.config.Notifier.FileSystem != nil:
+		notifier = notification.NewFileNotifier(*ctx.config.Notifier.FileSystem)
+	default:
+		return nil
+	}
+
+	if len(ctx.config.Notifier.Webhooks) != 0 {
+		webhooks := make([]*notification.WebhookNotifier, len(ctx.config.Notifier.Webhooks))
+
+		for i := range ctx.config.Notifier.Webhooks {
+			webhooks[i] = notification.NewWebhookNotifier(&ctx.config.Notifier.Webhooks[i], ctx.trusted)
+		}
+
+		notifier = notification.NewMultiNotifier(notifier, webhooks...)
+	}
+
+	if tmpl != nil && ctx.config.Notifier.Outbox.Enabled {
+		notifier = notification.NewOutboxNotifier(ctx.config.Notifier.Outbox, notifier, store, tmpl)
+	}
+
+	return notifier
+}
+
+func getUserProviderBackend(ctx *CmdCtx, name string) (provider authentication.UserProvider) {
 	switch {
-	case ctx.config.Storage.PostgreSQL != nil:
-		return storage.NewPostgreSQLProvider(ctx.config, ctx.trusted)
-	case ctx.config.Storage.MySQL != nil:
-		return storage.NewMySQLProvider(ctx.config, ctx.trusted)
-	case ctx.config.Storage.Local != nil:
-		return storage.NewSQLiteProvider(ctx.config)
+	case name == schema.AuthenticationBackendFile && ctx.config.AuthenticationBackend.File != nil:
+		return authentication.NewFileUserProvider(ctx.config.AuthenticationBackend.File)
+	case name == schema.AuthenticationBackendLDAP && ctx.config.AuthenticationBackend.LDAP != nil:
+		return authentication.NewLDAPUserProvider(ctx.config.AuthenticationBackend, ctx.trusted)
 	default:
 		return nil
 	}
@@ -119,3 +188,20 @@ func storageWebauthnDeleteRunEOptsFromFlags(flags *pflag.FlagSet, args []string)
 
 	return
 }
+
+func storageNotificationsStatusFromFlags(flags *pflag.FlagSet, allowEmpty bool) (status string, err error) {
+	if status, err = flags.GetString(cmdFlagNameStatus); err != nil {
+		return "", err
+	}
+
+	switch status {
+	case model.OutboundNotificationStatusPending, model.OutboundNotificationStatusSent, model.OutboundNotificationStatusFailed:
+		return status, nil
+	case "":
+		if allowEmpty {
+			return status, nil
+		}
+	}
+
+	return "", fmt.Errorf("flag --%s must be one of '%s', '%s', or '%s' but it's configured as '%s'", cmdFlagNameStatus, model.OutboundNotificationStatusPending, model.OutboundNotificationStatusSent, model.OutboundNotificationStatusFailed, status)
+}
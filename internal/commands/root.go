@@ -46,6 +46,7 @@ func NewRootCmd() (cmd *cobra.Command) {
 		newBuildInfoCmd(ctx),
 		newCryptoCmd(ctx),
 		newStorageCmd(ctx),
+		newUsersCmd(ctx),
 		newValidateConfigCmd(ctx),
 
 		newHelpTopic("config", "Help for the config file/directory paths", helpTopicConfig),
@@ -116,6 +117,14 @@ func doStartupChecks(ctx *CmdCtx) {
 		failures = append(failures, "notification")
 	}
 
+	if provider, ok := ctx.providers.PasswordPolicy.(model.StartupCheck); ok {
+		if err = doStartupCheck(ctx, "password policy", provider, false); err != nil {
+			ctx.log.Errorf("Failure running the password policy provider startup check: %+v", err)
+
+			failures = append(failures, "password policy")
+		}
+	}
+
 	if !ctx.config.NTP.DisableStartupCheck && !ctx.providers.Authorizer.IsSecondFactorEnabled() {
 		ctx.log.Debug("The NTP startup check was skipped due to there being no configured 2FA access control rules")
 	} else if err = doStartupCheck(ctx, "ntp", ctx.providers.NTP, ctx.config.NTP.DisableStartupCheck); err != nil {
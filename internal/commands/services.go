@@ -10,14 +10,20 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/sirupsen/logrus"
 	"github.com/valyala/fasthttp"
 	"golang.org/x/sync/errgroup"
 
-	"github.com/authelia/authelia/v4/internal/authentication"
+	"github.com/authelia/authelia/v4/internal/authorization"
+	"github.com/authelia/authelia/v4/internal/configuration"
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/configuration/validator"
+	"github.com/authelia/authelia/v4/internal/notification"
 	"github.com/authelia/authelia/v4/internal/server"
+	"github.com/authelia/authelia/v4/internal/storage"
 )
 
 // NewServerService creates a new ServerService with the appropriate logger etc.
@@ -33,50 +39,95 @@ func NewServerService(name string, server *fasthttp.Server, listener net.Listene
 
 // NewFileWatcherService creates a new FileWatcherService with the appropriate logger etc.
 func NewFileWatcherService(name, path string, reload ProviderReload, log *logrus.Logger) (service *FileWatcherService, err error) {
-	if path == "" {
+	return NewFileWatcherServiceMultiple(name, []string{path}, reload, log)
+}
+
+// NewFileWatcherServiceMultiple creates a new FileWatcherService which watches multiple paths with the appropriate
+// logger etc.
+func NewFileWatcherServiceMultiple(name string, paths []string, reload ProviderReload, log *logrus.Logger) (service *FileWatcherService, err error) {
+	if len(paths) == 0 {
 		return nil, fmt.Errorf("path must be specified")
 	}
 
+	var watcher *fsnotify.Watcher
+
+	if watcher, err = fsnotify.NewWatcher(); err != nil {
+		return nil, err
+	}
+
+	service = &FileWatcherService{
+		watcher: watcher,
+		reload:  reload,
+		log:     log.WithFields(map[string]any{"service": "watcher", "watcher": name}),
+	}
+
+	for _, path := range paths {
+		var target fileWatcherTarget
+
+		if target, err = newFileWatcherTarget(path); err != nil {
+			_ = watcher.Close()
+
+			return nil, err
+		}
+
+		if err = service.watcher.Add(target.directory); err != nil {
+			_ = watcher.Close()
+
+			return nil, fmt.Errorf("failed to add path '%s' to watch list: %w", path, err)
+		}
+
+		service.targets = append(service.targets, target)
+	}
+
+	return service, nil
+}
+
+func newFileWatcherTarget(path string) (target fileWatcherTarget, err error) {
+	if path == "" {
+		return target, fmt.Errorf("path must be specified")
+	}
+
 	var info os.FileInfo
 
 	if info, err = os.Stat(path); err != nil {
-		return nil, fmt.Errorf("error stating file '%s': %w", path, err)
+		return target, fmt.Errorf("error stating file '%s': %w", path, err)
 	}
 
 	if path, err = filepath.Abs(path); err != nil {
-		return nil, fmt.Errorf("error determining absolute path of file '%s': %w", path, err)
+		return target, fmt.Errorf("error determining absolute path of file '%s': %w", path, err)
 	}
 
-	var watcher *fsnotify.Watcher
-
-	if watcher, err = fsnotify.NewWatcher(); err != nil {
-		return nil, err
+	if info.IsDir() {
+		return fileWatcherTarget{directory: filepath.Clean(path)}, nil
 	}
 
-	entry := log.WithFields(map[string]any{"service": "watcher", "watcher": name})
+	return fileWatcherTarget{directory: filepath.Dir(path), file: filepath.Base(path)}, nil
+}
 
-	if info.IsDir() {
-		service = &FileWatcherService{
-			watcher:   watcher,
-			reload:    reload,
-			log:       entry,
-			directory: filepath.Clean(path),
-		}
-	} else {
-		service = &FileWatcherService{
-			watcher:   watcher,
-			reload:    reload,
-			log:       entry,
-			directory: filepath.Dir(path),
-			file:      filepath.Base(path),
-		}
+// NewStorageEncryptionRekeyService creates a new StorageEncryptionRekeyService with the appropriate logger etc.
+func NewStorageEncryptionRekeyService(config schema.StorageEncryptionRekeyConfiguration, provider storage.Provider, log *logrus.Logger) (service *StorageEncryptionRekeyService) {
+	service = &StorageEncryptionRekeyService{
+		config:   config,
+		provider: provider,
+		log:      log.WithFields(map[string]any{"service": "storage", "storage": "encryption-rekey"}),
 	}
 
-	if err = service.watcher.Add(service.directory); err != nil {
-		return nil, fmt.Errorf("failed to add path '%s' to watch list: %w", path, err)
+	service.ctx, service.cancel = context.WithCancel(context.Background())
+
+	return service
+}
+
+// NewStoragePruneService creates a new StoragePruneService with the appropriate logger etc.
+func NewStoragePruneService(config *schema.Configuration, provider storage.Provider, log *logrus.Logger) (service *StoragePruneService) {
+	service = &StoragePruneService{
+		config:   config,
+		provider: provider,
+		log:      log.WithFields(map[string]any{"service": "storage", "storage": "prune"}),
 	}
 
-	return service, nil
+	service.ctx, service.cancel = context.WithCancel(context.Background())
+
+	return service
 }
 
 // ProviderReload represents the required methods to support reloading a provider.
@@ -130,9 +181,32 @@ type FileWatcherService struct {
 	watcher *fsnotify.Watcher
 	reload  ProviderReload
 
-	log       *logrus.Entry
-	file      string
+	log     *logrus.Entry
+	targets []fileWatcherTarget
+}
+
+type fileWatcherTarget struct {
 	directory string
+	file      string
+}
+
+// IsMatch returns true if the file name from an event is relevant to this target.
+func (target fileWatcherTarget) IsMatch(name string) bool {
+	if filepath.Dir(filepath.Clean(name)) != target.directory {
+		return false
+	}
+
+	return target.file == "" || target.file == filepath.Base(name)
+}
+
+func (service *FileWatcherService) isRelevant(name string) bool {
+	for _, target := range service.targets {
+		if target.IsMatch(name) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // Run the FileWatcherService.
@@ -143,7 +217,9 @@ func (service *FileWatcherService) Run() (err error) {
 		}
 	}()
 
-	service.log.WithField("file", filepath.Join(service.directory, service.file)).Info("Watching for file changes to the file")
+	for _, target := range service.targets {
+		service.log.WithField("file", filepath.Join(target.directory, target.file)).Info("Watching for file changes to the file")
+	}
 
 	for {
 		select {
@@ -152,7 +228,7 @@ func (service *FileWatcherService) Run() (err error) {
 				return nil
 			}
 
-			if service.file != "" && service.file != filepath.Base(event.Name) {
+			if !service.isRelevant(event.Name) {
 				service.log.WithFields(map[string]any{"file": event.Name, "op": event.Op}).Tracef("File modification detected to irrelevant file")
 				break
 			}
@@ -191,6 +267,152 @@ func (service *FileWatcherService) Shutdown() {
 	}
 }
 
+// StorageEncryptionRekeyService is a Service which re-encrypts the values in the storage which are not encrypted with
+// the current encryption key in batches until every value is encrypted with the current encryption key.
+type StorageEncryptionRekeyService struct {
+	config   schema.StorageEncryptionRekeyConfiguration
+	provider storage.Provider
+	log      *logrus.Entry
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Run the StorageEncryptionRekeyService.
+func (service *StorageEncryptionRekeyService) Run() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			service.log.WithError(recoverErr(r)).Error("Critical error caught (recovered)")
+		}
+	}()
+
+	ticker := time.NewTicker(service.config.Interval)
+
+	defer ticker.Stop()
+
+	state := &storage.EncryptionRekeyState{}
+
+	for {
+		if err = service.provider.SchemaEncryptionRekey(service.ctx, state, service.config.BatchSize); err != nil {
+			if service.ctx.Err() != nil {
+				return nil
+			}
+
+			service.log.WithError(err).Errorf("Error occurred re-encrypting values with the current encryption key after %d values were re-encrypted", state.Rekeyed)
+
+			return nil
+		}
+
+		if state.Complete() {
+			switch {
+			case state.Failed != 0:
+				service.log.Warnf("Completed re-encrypting %d values with the current encryption key but %d values could not be decrypted with any of the encryption keys", state.Rekeyed, state.Failed)
+			case state.Rekeyed != 0:
+				service.log.Infof("Completed re-encrypting %d values with the current encryption key", state.Rekeyed)
+			default:
+				service.log.Debug("All values are encrypted with the current encryption key")
+			}
+
+			return nil
+		}
+
+		service.log.Debugf("Re-encrypted %d values with the current encryption key so far", state.Rekeyed)
+
+		select {
+		case <-service.ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown the StorageEncryptionRekeyService.
+func (service *StorageEncryptionRekeyService) Shutdown() {
+	service.cancel()
+}
+
+// StoragePruneService is a Service which periodically deletes the data in the storage which is no longer retained
+// according to the retention configuration in batches.
+type StoragePruneService struct {
+	config   *schema.Configuration
+	provider storage.Provider
+	log      *logrus.Entry
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Run the StoragePruneService.
+func (service *StoragePruneService) Run() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			service.log.WithError(recoverErr(r)).Error("Critical error caught (recovered)")
+		}
+	}()
+
+	ticker := time.NewTicker(service.config.Storage.Retention.Interval)
+
+	defer ticker.Stop()
+
+	for {
+		service.prune()
+
+		select {
+		case <-service.ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown the StoragePruneService.
+func (service *StoragePruneService) Shutdown() {
+	service.cancel()
+}
+
+func (service *StoragePruneService) prune() {
+	policy := storage.NewPrunePolicy(service.config, time.Now())
+
+	var (
+		result, total storage.PruneResult
+		err           error
+	)
+
+	for !total.Complete {
+		if result, err = service.provider.Prune(service.ctx, policy, service.config.Storage.Retention.BatchSize); err != nil {
+			if service.ctx.Err() != nil {
+				return
+			}
+
+			service.log.WithError(err).Errorf("Error occurred pruning data which is no longer retained after %d rows were pruned", total.Total())
+
+			return
+		}
+
+		total.Merge(result)
+
+		if service.ctx.Err() != nil {
+			break
+		}
+	}
+
+	if total.Total() == 0 {
+		service.log.Debug("No data required pruning")
+
+		return
+	}
+
+	fields := map[string]any{}
+
+	for _, table := range total.Tables {
+		if table.Count != 0 {
+			fields[table.Table] = table.Count
+		}
+	}
+
+	service.log.WithFields(fields).Infof("Pruned %d rows which are no longer retained", total.Total())
+}
+
 func svcSvrMainFunc(ctx *CmdCtx) (service Service) {
 	switch svr, listener, paths, isTLS, err := server.CreateDefaultServer(ctx.config, ctx.providers); {
 	case err != nil:
@@ -221,7 +443,10 @@ func svcWatcherUsersFunc(ctx *CmdCtx) (service Service) {
 	var err error
 
 	if ctx.config.AuthenticationBackend.File != nil && ctx.config.AuthenticationBackend.File.Watch {
-		provider := ctx.providers.UserProvider.(*authentication.FileUserProvider)
+		provider, ok := ctx.providers.UserProvider.(ProviderReload)
+		if !ok {
+			ctx.log.Fatal("Create Watcher Service (users) returned error: the user provider does not support reloading")
+		}
 
 		if service, err = NewFileWatcherService("users", ctx.config.AuthenticationBackend.File.Path, provider, ctx.log); err != nil {
 			ctx.log.WithError(err).Fatal("Create Watcher Service (users) returned error")
@@ -231,6 +456,107 @@ func svcWatcherUsersFunc(ctx *CmdCtx) (service Service) {
 	return service
 }
 
+func svcNotifierOutboxFunc(ctx *CmdCtx) (service Service) {
+	if outbox, ok := ctx.providers.Notifier.(*notification.OutboxNotifier); ok {
+		service = outbox
+	}
+
+	return service
+}
+
+func svcStorageEncryptionRekeyFunc(ctx *CmdCtx) (service Service) {
+	if ctx.config.Storage.Encryption.Rekey.Disable || ctx.providers.StorageProvider == nil {
+		return nil
+	}
+
+	return NewStorageEncryptionRekeyService(ctx.config.Storage.Encryption.Rekey, ctx.providers.StorageProvider, ctx.log)
+}
+
+func svcStoragePruneFunc(ctx *CmdCtx) (service Service) {
+	if !ctx.config.Storage.Retention.Enabled || ctx.providers.StorageProvider == nil {
+		return nil
+	}
+
+	return NewStoragePruneService(ctx.config, ctx.providers.StorageProvider, ctx.log)
+}
+
+func svcWatcherAccessControlFunc(ctx *CmdCtx) (service Service) {
+	var err error
+
+	if ctx.config.AccessControl.Watch && ctx.cconfig != nil && len(ctx.cconfig.files) != 0 {
+		reloader := NewAccessControlReloader(ctx)
+
+		if service, err = NewFileWatcherServiceMultiple("access-control", ctx.cconfig.files, reloader, ctx.log); err != nil {
+			ctx.log.WithError(err).Fatal("Create Watcher Service (access-control) returned error")
+		}
+	}
+
+	return service
+}
+
+// NewAccessControlReloader creates a new AccessControlReloader from the configuration sources of the CmdCtx.
+func NewAccessControlReloader(ctx *CmdCtx) (reloader *AccessControlReloader) {
+	return &AccessControlReloader{
+		files:      ctx.cconfig.files,
+		filters:    ctx.cconfig.filters,
+		defaults:   ctx.cconfig.defaults,
+		sources:    ctx.cconfig.sources,
+		authorizer: ctx.providers.Authorizer,
+		log:        ctx.log,
+	}
+}
+
+// AccessControlReloader is a ProviderReload which reloads the access control configuration from the configuration
+// sources and swaps the rules used by the authorization.Authorizer if the new configuration is valid.
+type AccessControlReloader struct {
+	files    []string
+	filters  []configuration.FileFilter
+	defaults configuration.Source
+	sources  []configuration.Source
+
+	authorizer *authorization.Authorizer
+	log        *logrus.Logger
+}
+
+// Reload the access control configuration. If the configuration is invalid the errors are logged and the existing
+// rules continue to be used.
+func (reloader *AccessControlReloader) Reload() (reloaded bool, err error) {
+	var (
+		config = &schema.Configuration{}
+		val    = schema.NewStructValidator()
+	)
+
+	if _, err = configuration.LoadAdvanced(val, "", config,
+		configuration.NewDefaultSourcesWithDefaults(
+			reloader.files,
+			reloader.filters,
+			configuration.DefaultEnvPrefix,
+			configuration.DefaultEnvDelimiter,
+			reloader.defaults,
+			reloader.sources...)...); err != nil {
+		return false, fmt.Errorf("error occurred loading the configuration: %w", err)
+	}
+
+	validator.ValidateAccessControl(config, val)
+	validator.ValidateRules(config, val)
+
+	for _, warn := range val.Warnings() {
+		reloader.log.WithError(warn).Warn("Access Control configuration has a warning")
+	}
+
+	if val.HasErrors() {
+		for _, e := range val.Errors() {
+			reloader.log.WithError(e).Error("Access Control configuration has an error")
+		}
+
+		return false, fmt.Errorf("the access control configuration has %d errors so the existing rules will continue to be used", len(val.Errors()))
+	}
+
+	reloader.authorizer.Reload(config.AccessControl)
+
+	return true, nil
+}
+
 func connectionType(isTLS bool) string {
 	if isTLS {
 		return "TLS"
@@ -258,7 +584,8 @@ func servicesRun(ctx *CmdCtx) {
 
 	for _, serviceFunc := range []func(ctx *CmdCtx) Service{
 		svcSvrMainFunc, svcSvrMetricsFunc,
-		svcWatcherUsersFunc,
+		svcWatcherUsersFunc, svcWatcherAccessControlFunc,
+		svcNotifierOutboxFunc, svcStorageEncryptionRekeyFunc, svcStoragePruneFunc,
 	} {
 		if service := serviceFunc(ctx); service != nil {
 			services = append(services, service)
@@ -0,0 +1,225 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/authorization"
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/model"
+	"github.com/authelia/authelia/v4/internal/storage"
+)
+
+func TestFileWatcherTargetIsMatch(t *testing.T) {
+	dir := t.TempDir()
+
+	file := filepath.Join(dir, "configuration.yml")
+
+	require.NoError(t, os.WriteFile(file, []byte("---\n"), 0600))
+
+	target, err := newFileWatcherTarget(file)
+	require.NoError(t, err)
+
+	assert.True(t, target.IsMatch(file))
+	assert.False(t, target.IsMatch(filepath.Join(dir, "users.yml")))
+	assert.False(t, target.IsMatch(filepath.Join(dir, "sub", "configuration.yml")))
+
+	target, err = newFileWatcherTarget(dir)
+	require.NoError(t, err)
+
+	assert.True(t, target.IsMatch(file))
+	assert.True(t, target.IsMatch(filepath.Join(dir, "users.yml")))
+	assert.False(t, target.IsMatch(filepath.Join(filepath.Dir(dir), "users.yml")))
+
+	_, err = newFileWatcherTarget(filepath.Join(dir, "missing.yml"))
+	assert.ErrorContains(t, err, "error stating file")
+
+	_, err = newFileWatcherTarget("")
+	assert.EqualError(t, err, "path must be specified")
+}
+
+func TestAccessControlReloader(t *testing.T) {
+	dir := t.TempDir()
+
+	file := filepath.Join(dir, "configuration.yml")
+
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+	}
+
+	write(`
+access_control:
+  default_policy: deny
+  rules:
+    - domain: 'app.example.com'
+      policy: one_factor
+`)
+
+	ctx := NewCmdCtx()
+	ctx.cconfig = NewCmdCtxConfig()
+	ctx.cconfig.files = []string{file}
+	ctx.config = &schema.Configuration{
+		AccessControl: schema.AccessControlConfiguration{
+			DefaultPolicy: "deny",
+			Rules: []schema.ACLRule{
+				{Domains: []string{"app.example.com"}, Policy: "one_factor"},
+			},
+		},
+	}
+	ctx.providers.Authorizer = authorization.NewAuthorizer(ctx.config)
+
+	reloader := NewAccessControlReloader(ctx)
+
+	targetURL, _ := url.ParseRequestURI("https://app.example.com/")
+	object := authorization.NewObject(targetURL, http.MethodGet, nil)
+
+	write(`
+access_control:
+  default_policy: deny
+  rules:
+    - domain: 'app.example.com'
+      policy: two_factor
+      headers:
+        - - operator: 'pattern'
+            key: 'User-Agent'
+            value: '^curl/.*$'
+    - domain: 'app.example.com'
+      policy: bypass
+`)
+
+	reloaded, err := reloader.Reload()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+
+	_, level := ctx.providers.Authorizer.GetRequiredLevel(authorization.Subject{}, object)
+	assert.Equal(t, authorization.Bypass, level)
+
+	object.Header.Set("User-Agent", "curl/8.0.1")
+
+	_, level = ctx.providers.Authorizer.GetRequiredLevel(authorization.Subject{}, object)
+	assert.Equal(t, authorization.TwoFactor, level)
+
+	write(`
+access_control:
+  default_policy: deny
+  rules:
+    - domain: 'app.example.com'
+      policy: not_a_policy
+`)
+
+	reloaded, err = reloader.Reload()
+	assert.EqualError(t, err, "the access control configuration has 1 errors so the existing rules will continue to be used")
+	assert.False(t, reloaded)
+
+	_, level = ctx.providers.Authorizer.GetRequiredLevel(authorization.Subject{}, object)
+	assert.Equal(t, authorization.TwoFactor, level)
+}
+
+func TestStorageEncryptionRekeyService(t *testing.T) {
+	ctx := context.Background()
+
+	config := &schema.Configuration{
+		Storage: schema.StorageConfiguration{
+			Local:         &schema.LocalStorageConfiguration{Path: filepath.Join(t.TempDir(), "db.sqlite3")},
+			EncryptionKey: "an_old_encryption_key_which_is_long",
+		},
+	}
+
+	provider := storage.NewSQLiteProvider(config, nil)
+	require.NoError(t, provider.StartupCheck())
+
+	for _, username := range []string{"john", "harry", "bob"} {
+		require.NoError(t, provider.SaveTOTPConfiguration(ctx, model.TOTPConfiguration{
+			CreatedAt: time.Now(),
+			Username:  username,
+			Issuer:    "Authelia",
+			Algorithm: "SHA1",
+			Digits:    6,
+			Period:    30,
+			Secret:    []byte(username),
+		}))
+	}
+
+	require.NoError(t, provider.Close())
+
+	config.Storage.EncryptionKey = "a_new_encryption_key_which_is_long"
+	config.Storage.Encryption.DecryptionKeys = []string{"an_old_encryption_key_which_is_long"}
+
+	provider = storage.NewSQLiteProvider(config, nil)
+	require.NoError(t, provider.StartupCheck())
+
+	defer func() {
+		_ = provider.Close()
+	}()
+
+	service := NewStorageEncryptionRekeyService(schema.StorageEncryptionRekeyConfiguration{BatchSize: 1, Interval: time.Millisecond}, provider, logrus.New())
+
+	require.NoError(t, service.Run())
+
+	service.Shutdown()
+
+	result, err := provider.SchemaEncryptionCheckKey(ctx, true)
+	require.NoError(t, err)
+
+	rows, percent := result.Tables["totp_configurations"].Progress(result.Current)
+
+	assert.Equal(t, 3, rows)
+	assert.Equal(t, float64(100), percent)
+}
+
+func TestStoragePruneService(t *testing.T) {
+	ctx := context.Background()
+
+	config := &schema.Configuration{
+		Storage: schema.StorageConfiguration{
+			Local:         &schema.LocalStorageConfiguration{Path: filepath.Join(t.TempDir(), "db.sqlite3")},
+			EncryptionKey: "an_old_encryption_key_which_is_long",
+			Retention:     schema.DefaultStorageRetentionConfiguration,
+		},
+	}
+
+	config.Storage.Retention.BatchSize = 1
+
+	provider := storage.NewSQLiteProvider(config, nil)
+	require.NoError(t, provider.StartupCheck())
+
+	defer func() {
+		_ = provider.Close()
+	}()
+
+	for _, at := range []time.Time{time.Now().Add(-time.Hour * 24 * 500), time.Now().Add(-time.Hour * 24 * 400), time.Now()} {
+		require.NoError(t, provider.AppendAuthenticationLog(ctx, model.AuthenticationAttempt{Time: at, Successful: true, Username: "john", Type: "1FA"}))
+	}
+
+	service := NewStoragePruneService(config, provider, logrus.New())
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- service.Run()
+	}()
+
+	assert.Eventually(t, func() bool {
+		result, err := provider.LoadPruneCounts(ctx, storage.NewPrunePolicy(config, time.Now()))
+
+		return err == nil && result.Total() == 0
+	}, time.Second*5, time.Millisecond*10)
+
+	service.Shutdown()
+
+	require.NoError(t, <-done)
+
+	history, err := provider.LoadAuthenticationLogs(ctx, "john", time.Time{}, 10, 0)
+	require.NoError(t, err)
+
+	assert.Len(t, history, 1)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/model"
 )
 
 func newStorageCmd(ctx *CmdCtx) (cmd *cobra.Command) {
@@ -52,11 +53,90 @@ func newStorageCmd(ctx *CmdCtx) (cmd *cobra.Command) {
 		newStorageSchemaInfoCmd(ctx),
 		newStorageEncryptionCmd(ctx),
 		newStorageUserCmd(ctx),
+		newStorageNotificationsCmd(ctx),
+		newStoragePruneCmd(ctx),
+		newStorageCopyCmd(ctx),
+		newStorageBackupCmd(ctx),
+		newStorageRestoreCmd(ctx),
 	)
 
 	return cmd
 }
 
+func newStoragePruneCmd(ctx *CmdCtx) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     "prune",
+		Short:   cmdAutheliaStoragePruneShort,
+		Long:    cmdAutheliaStoragePruneLong,
+		Example: cmdAutheliaStoragePruneExample,
+		RunE:    ctx.StoragePruneRunE,
+		Args:    cobra.NoArgs,
+
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().Bool(cmdFlagNameDryRun, false, "only display the number of rows which would be pruned from each table")
+
+	return cmd
+}
+
+func newStorageCopyCmd(ctx *CmdCtx) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     "copy",
+		Short:   cmdAutheliaStorageCopyShort,
+		Long:    cmdAutheliaStorageCopyLong,
+		Example: cmdAutheliaStorageCopyExample,
+		RunE:    ctx.StorageCopyRunE,
+		Args:    cobra.NoArgs,
+
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().StringSlice(cmdFlagNameTo, nil, "the configuration files which configure the target storage backend")
+	cmd.Flags().Int(cmdFlagNameBatchSize, 1000, "the number of rows to copy from each table at a time")
+
+	_ = cmd.MarkFlagRequired(cmdFlagNameTo)
+
+	return cmd
+}
+
+func newStorageBackupCmd(ctx *CmdCtx) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     "backup",
+		Short:   cmdAutheliaStorageBackupShort,
+		Long:    cmdAutheliaStorageBackupLong,
+		Example: cmdAutheliaStorageBackupExample,
+		RunE:    ctx.StorageBackupRunE,
+		Args:    cobra.NoArgs,
+
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().StringP(cmdFlagNameFile, "f", "authelia.backup", "the file name for the backup")
+	cmd.Flags().String(cmdFlagNamePassphrase, "", "the passphrase used to encrypt the backup")
+	cmd.Flags().Int(cmdFlagNameBatchSize, 1000, "the number of rows to read from each table at a time")
+
+	return cmd
+}
+
+func newStorageRestoreCmd(ctx *CmdCtx) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     "restore <filename>",
+		Short:   cmdAutheliaStorageRestoreShort,
+		Long:    cmdAutheliaStorageRestoreLong,
+		Example: cmdAutheliaStorageRestoreExample,
+		RunE:    ctx.StorageRestoreRunE,
+		Args:    cobra.ExactArgs(1),
+
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().String(cmdFlagNamePassphrase, "", "the passphrase used to decrypt the backup")
+	cmd.Flags().Int(cmdFlagNameBatchSize, 1000, "the number of rows to restore to each table at a time")
+
+	return cmd
+}
+
 func newStorageEncryptionCmd(ctx *CmdCtx) (cmd *cobra.Command) {
 	cmd = &cobra.Command{
 		Use:     "encryption",
@@ -570,3 +650,75 @@ func newStorageMigrateDownCmd(ctx *CmdCtx) (cmd *cobra.Command) {
 
 	return cmd
 }
+
+func newStorageNotificationsCmd(ctx *CmdCtx) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     "notifications",
+		Short:   cmdAutheliaStorageNotificationsShort,
+		Long:    cmdAutheliaStorageNotificationsLong,
+		Example: cmdAutheliaStorageNotificationsExample,
+		Args:    cobra.NoArgs,
+
+		DisableAutoGenTag: true,
+	}
+
+	cmd.AddCommand(
+		newStorageNotificationsListCmd(ctx),
+		newStorageNotificationsRetryCmd(ctx),
+		newStorageNotificationsPurgeCmd(ctx),
+	)
+
+	return cmd
+}
+
+func newStorageNotificationsListCmd(ctx *CmdCtx) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     "list",
+		Short:   cmdAutheliaStorageNotificationsListShort,
+		Long:    cmdAutheliaStorageNotificationsListLong,
+		Example: cmdAutheliaStorageNotificationsListExample,
+		RunE:    ctx.StorageNotificationsListRunE,
+		Args:    cobra.NoArgs,
+
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().String(cmdFlagNameStatus, "", "only list the notifications with this status, options are 'pending', 'sent', and 'failed'")
+
+	return cmd
+}
+
+func newStorageNotificationsRetryCmd(ctx *CmdCtx) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     "retry [id]",
+		Short:   cmdAutheliaStorageNotificationsRetryShort,
+		Long:    cmdAutheliaStorageNotificationsRetryLong,
+		Example: cmdAutheliaStorageNotificationsRetryExample,
+		RunE:    ctx.StorageNotificationsRetryRunE,
+		Args:    cobra.MaximumNArgs(1),
+
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().Bool(cmdFlagNameAll, false, "retry all of the failed notifications")
+
+	return cmd
+}
+
+func newStorageNotificationsPurgeCmd(ctx *CmdCtx) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     "purge",
+		Short:   cmdAutheliaStorageNotificationsPurgeShort,
+		Long:    cmdAutheliaStorageNotificationsPurgeLong,
+		Example: cmdAutheliaStorageNotificationsPurgeExample,
+		RunE:    ctx.StorageNotificationsPurgeRunE,
+		Args:    cobra.NoArgs,
+
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().String(cmdFlagNameStatus, model.OutboundNotificationStatusSent, "the status of the notifications to purge, options are 'pending', 'sent', and 'failed'")
+	cmd.Flags().Duration(cmdFlagNameOlderThan, 0, "only purge the notifications created more than this duration ago")
+
+	return cmd
+}
@@ -10,16 +10,22 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 
+	"github.com/authelia/authelia/v4/internal/authentication"
+	"github.com/authelia/authelia/v4/internal/configuration"
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
 	"github.com/authelia/authelia/v4/internal/configuration/validator"
 	"github.com/authelia/authelia/v4/internal/model"
 	"github.com/authelia/authelia/v4/internal/random"
 	"github.com/authelia/authelia/v4/internal/storage"
+	"github.com/authelia/authelia/v4/internal/templates"
 	"github.com/authelia/authelia/v4/internal/totp"
 	"github.com/authelia/authelia/v4/internal/utils"
 )
@@ -104,7 +110,7 @@ func (ctx *CmdCtx) ConfigValidateStorageRunE(_ *cobra.Command, _ []string) (err
 		return err
 	}
 
-	validator.ValidateStorage(ctx.config.Storage, ctx.cconfig.validator)
+	validator.ValidateStorage(&ctx.config.Storage, ctx.cconfig.validator)
 
 	validator.ValidateTOTP(ctx.config, ctx.cconfig.validator)
 
@@ -162,7 +168,17 @@ func (ctx *CmdCtx) StorageSchemaEncryptionCheckRunE(cmd *cobra.Command, args []s
 		}
 
 		if verbose {
-			fmt.Printf("\nTables:")
+			fmt.Printf("\nKeys:\n")
+
+			for _, id := range result.Keys {
+				if id == result.Current {
+					fmt.Printf("\n\t%s (current)", id)
+				} else {
+					fmt.Printf("\n\t%s", id)
+				}
+			}
+
+			fmt.Printf("\n\nTables:")
 
 			tables := make([]string, 0, len(result.Tables))
 
@@ -176,6 +192,26 @@ func (ctx *CmdCtx) StorageSchemaEncryptionCheckRunE(cmd *cobra.Command, args []s
 				table := result.Tables[name]
 
 				fmt.Printf("\n\n\tTable (%s): %s\n\t\tInvalid Rows: %d\n\t\tTotal Rows: %d", name, table.ResultDescriptor(), table.Invalid, table.Total)
+
+				if table.Total == 0 {
+					continue
+				}
+
+				rows, percent := table.Progress(result.Current)
+
+				fmt.Printf("\n\t\tCurrent Key Rows: %d (%.2f%%)", rows, percent)
+
+				for _, id := range result.Keys {
+					if id == result.Current || table.Keys[id] == 0 {
+						continue
+					}
+
+					fmt.Printf("\n\t\tKey %s Rows: %d", id, table.Keys[id])
+				}
+
+				if table.Legacy != 0 {
+					fmt.Printf("\n\t\tLegacy Rows: %d", table.Legacy)
+				}
 			}
 
 			fmt.Printf("\n")
@@ -744,9 +780,70 @@ func (ctx *CmdCtx) StorageUserTOTPDeleteRunE(cmd *cobra.Command, args []string)
 
 	fmt.Printf("Successfully deleted TOTP configuration for user '%s'\n", user)
 
+	if err = ctx.storageUserTOTPDeleteNotify(user); err != nil {
+		fmt.Printf("Failed to notify user '%s' that their TOTP configuration was deleted: %v\n", user, err)
+	}
+
 	return nil
 }
 
+// storageUserTOTPDeleteNotify sends the TOTP removed security event notification to the user if it's enabled. The
+// authentication backend and notifier are only validated and loaded here as the storage commands don't require them.
+func (ctx *CmdCtx) storageUserTOTPDeleteNotify(user string) (err error) {
+	if ctx.config.Notifier.Events.DisableTOTPRemoved {
+		return nil
+	}
+
+	val := schema.NewStructValidator()
+
+	validator.ValidateAuthenticationBackend(&ctx.config.AuthenticationBackend, val)
+	validator.ValidateNotifier(&ctx.config.Notifier, val)
+
+	if errs := val.Errors(); len(errs) != 0 {
+		return fmt.Errorf("the configuration is not valid: %w", errs[0])
+	}
+
+	provider := getUserProvider(ctx)
+
+	if provider == nil {
+		return fmt.Errorf("the authentication backend is not configured")
+	}
+
+	if err = provider.StartupCheck(); err != nil {
+		return fmt.Errorf("failed to load the authentication backend: %w", err)
+	}
+
+	var details *authentication.UserDetails
+
+	if details, err = provider.GetDetails(user); err != nil {
+		return fmt.Errorf("failed to retrieve the user details: %w", err)
+	}
+
+	if len(details.Emails) == 0 {
+		return fmt.Errorf("the user has no email address configured")
+	}
+
+	var tmpl *templates.Provider
+
+	if tmpl, err = templates.New(templates.Config{EmailTemplatesPath: ctx.config.Notifier.TemplatePath}); err != nil {
+		return fmt.Errorf("failed to load the templates: %w", err)
+	}
+
+	notifier := getNotifierProvider(ctx, ctx.providers.StorageProvider, tmpl)
+
+	if notifier == nil {
+		return fmt.Errorf("the notifier is not configured")
+	}
+
+	data := templates.EmailEventValues{
+		Title:       "Second Factor Method Removed",
+		DisplayName: details.DisplayName,
+		Details:     map[string]any{"Action": "Second Factor Method Removed", "Category": "Time-based One Time Password"},
+	}
+
+	return notifier.Send(ctx, details.Addresses()[0], data.Title, tmpl.GetEmailTemplate(templates.TemplateNameEmailEventTOTPRemoved), data)
+}
+
 const (
 	cliOutputFmtSuccessfulUserExportFile = "Successfully exported %d %s as %s to the '%s' file\n"
 	cliOutputFmtSuccessfulUserImportFile = "Successfully imported %d %s from the %s file '%s' into the database\n"
@@ -1286,3 +1383,479 @@ func (ctx *CmdCtx) StorageUserIdentifiersAddRunE(cmd *cobra.Command, args []stri
 
 	return nil
 }
+
+// StorageNotificationsListRunE is the RunE for the authelia storage notifications list command.
+func (ctx *CmdCtx) StorageNotificationsListRunE(cmd *cobra.Command, _ []string) (err error) {
+	defer func() {
+		_ = ctx.providers.StorageProvider.Close()
+	}()
+
+	var status string
+
+	if status, err = storageNotificationsStatusFromFlags(cmd.Flags(), true); err != nil {
+		return err
+	}
+
+	if err = ctx.CheckSchema(); err != nil {
+		return storageWrapCheckSchemaErr(err)
+	}
+
+	var notifications []model.OutboundNotification
+
+	limit := 10
+
+	output := strings.Builder{}
+
+	for page := 0; true; page++ {
+		if notifications, err = ctx.providers.StorageProvider.LoadOutboundNotifications(ctx, status, limit, page); err != nil {
+			return fmt.Errorf("failed to list notifications: %w", err)
+		}
+
+		if page == 0 && len(notifications) == 0 {
+			return errors.New("no notifications in the outbox")
+		}
+
+		for _, notification := range notifications {
+			output.WriteString(fmt.Sprintf("%d\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n", notification.ID, notification.CreatedAt.Format(time.RFC3339), notification.Status, notification.Attempts, notification.Template, notification.Locale, notification.Recipient, notification.Subject, notification.LastError.String))
+		}
+
+		if len(notifications) < limit {
+			break
+		}
+	}
+
+	fmt.Printf("Notifications:\n\nID\tCreated\tStatus\tAttempts\tTemplate\tLocale\tRecipient\tSubject\tLast Error\n")
+	fmt.Println(output.String())
+
+	return nil
+}
+
+// StorageNotificationsRetryRunE is the RunE for the authelia storage notifications retry command.
+func (ctx *CmdCtx) StorageNotificationsRetryRunE(cmd *cobra.Command, args []string) (err error) {
+	defer func() {
+		_ = ctx.providers.StorageProvider.Close()
+	}()
+
+	var all bool
+
+	if all, err = cmd.Flags().GetBool(cmdFlagNameAll); err != nil {
+		return err
+	}
+
+	switch {
+	case all && len(args) != 0:
+		return errors.New("must either supply the id or the --all flag but both were specified")
+	case !all && len(args) == 0:
+		return errors.New("must supply the id or the --all flag")
+	}
+
+	if err = ctx.CheckSchema(); err != nil {
+		return storageWrapCheckSchemaErr(err)
+	}
+
+	now := time.Now()
+
+	if all {
+		var count int64
+
+		if count, err = ctx.providers.StorageProvider.RetryOutboundNotifications(ctx, now); err != nil {
+			return fmt.Errorf("failed to retry the failed notifications: %w", err)
+		}
+
+		fmt.Printf("Successfully scheduled %d failed notifications to be retried\n", count)
+
+		return nil
+	}
+
+	var id int
+
+	if id, err = strconv.Atoi(args[0]); err != nil {
+		return fmt.Errorf("failed to parse the id '%s': %w", args[0], err)
+	}
+
+	if err = ctx.providers.StorageProvider.RetryOutboundNotification(ctx, id, now); err != nil {
+		if errors.Is(err, storage.ErrNoOutboundNotification) {
+			return fmt.Errorf("no failed notification with id '%d' exists in the outbox", id)
+		}
+
+		return fmt.Errorf("failed to retry the notification with id '%d': %w", id, err)
+	}
+
+	fmt.Printf("Successfully scheduled the notification with id '%d' to be retried\n", id)
+
+	return nil
+}
+
+// StorageNotificationsPurgeRunE is the RunE for the authelia storage notifications purge command.
+func (ctx *CmdCtx) StorageNotificationsPurgeRunE(cmd *cobra.Command, _ []string) (err error) {
+	defer func() {
+		_ = ctx.providers.StorageProvider.Close()
+	}()
+
+	var (
+		status    string
+		olderThan time.Duration
+		count     int64
+	)
+
+	if status, err = storageNotificationsStatusFromFlags(cmd.Flags(), false); err != nil {
+		return err
+	}
+
+	if olderThan, err = cmd.Flags().GetDuration(cmdFlagNameOlderThan); err != nil {
+		return err
+	}
+
+	if olderThan < 0 {
+		return fmt.Errorf("flag --%s must not be negative", cmdFlagNameOlderThan)
+	}
+
+	if err = ctx.CheckSchema(); err != nil {
+		return storageWrapCheckSchemaErr(err)
+	}
+
+	if count, err = ctx.providers.StorageProvider.PurgeOutboundNotifications(ctx, status, time.Now().Add(-olderThan)); err != nil {
+		return fmt.Errorf("failed to purge the notifications with status '%s': %w", status, err)
+	}
+
+	fmt.Printf("Successfully purged %d notifications with status '%s'\n", count, status)
+
+	return nil
+}
+
+// StoragePruneRunE is the RunE for the authelia storage prune command.
+func (ctx *CmdCtx) StoragePruneRunE(cmd *cobra.Command, _ []string) (err error) {
+	defer func() {
+		_ = ctx.providers.StorageProvider.Close()
+	}()
+
+	var dryRun bool
+
+	if dryRun, err = cmd.Flags().GetBool(cmdFlagNameDryRun); err != nil {
+		return err
+	}
+
+	if err = ctx.CheckSchema(); err != nil {
+		return storageWrapCheckSchemaErr(err)
+	}
+
+	policy := storage.NewPrunePolicy(ctx.config, time.Now())
+
+	if dryRun {
+		var result storage.PruneResult
+
+		if result, err = ctx.providers.StorageProvider.LoadPruneCounts(ctx, policy); err != nil {
+			return fmt.Errorf("failed to count the rows to prune: %w", err)
+		}
+
+		fmt.Printf("Rows which would be pruned:\n\n%s\nTotal: %d\n", storagePruneResultString(result), result.Total())
+
+		return nil
+	}
+
+	var result, total storage.PruneResult
+
+	for !total.Complete {
+		if result, err = ctx.providers.StorageProvider.Prune(ctx, policy, ctx.config.Storage.Retention.BatchSize); err != nil {
+			return fmt.Errorf("failed to prune after %d rows were pruned: %w", total.Total(), err)
+		}
+
+		total.Merge(result)
+	}
+
+	fmt.Printf("Successfully pruned %d rows:\n\n%s", total.Total(), storagePruneResultString(total))
+
+	return nil
+}
+
+func storagePruneResultString(result storage.PruneResult) string {
+	output := strings.Builder{}
+
+	for _, table := range result.Tables {
+		output.WriteString(fmt.Sprintf("\t%s: %d\n", table.Table, table.Count))
+	}
+
+	return output.String()
+}
+
+// StorageCopyRunE is the RunE for the authelia storage copy command.
+func (ctx *CmdCtx) StorageCopyRunE(cmd *cobra.Command, _ []string) (err error) {
+	defer func() {
+		_ = ctx.providers.StorageProvider.Close()
+	}()
+
+	var (
+		paths []string
+		limit int
+	)
+
+	if paths, err = cmd.Flags().GetStringSlice(cmdFlagNameTo); err != nil {
+		return err
+	}
+
+	if limit, err = storageBatchSize(cmd); err != nil {
+		return err
+	}
+
+	if err = ctx.CheckSchema(); err != nil {
+		return storageWrapCheckSchemaErr(err)
+	}
+
+	var config *schema.Configuration
+
+	if config, err = storageCopyLoadTargetConfig(paths); err != nil {
+		return err
+	}
+
+	target := getStorageProviderFromConfig(config, ctx.trusted)
+
+	defer func() {
+		_ = target.Close()
+	}()
+
+	if err = target.StartupCheck(); err != nil {
+		return fmt.Errorf("failed to prepare the target storage: %w", err)
+	}
+
+	var result storage.SchemaCopyResult
+
+	if result, err = ctx.providers.StorageProvider.SchemaCopy(ctx, target, limit); err != nil {
+		return fmt.Errorf("failed to copy the storage: %w", err)
+	}
+
+	output := strings.Builder{}
+
+	for _, table := range result.Tables {
+		output.WriteString(fmt.Sprintf("\t%s: %d\n", table.Table, table.Count))
+	}
+
+	fmt.Printf("Successfully copied %d rows:\n\n%s\nRe-encrypted Values: %d\n", result.Total(), output.String(), result.Reencrypted)
+
+	return nil
+}
+
+// storageCopyLoadTargetConfig loads and validates the storage configuration of the target of the authelia storage copy
+// command. Only the configuration files are used as the environment and command line configure the source.
+func storageCopyLoadTargetConfig(paths []string) (config *schema.Configuration, err error) {
+	val := schema.NewStructValidator()
+
+	sources := make([]configuration.Source, 0, len(paths))
+
+	for _, source := range configuration.NewFileSources(paths) {
+		sources = append(sources, source)
+	}
+
+	config = &schema.Configuration{}
+
+	if _, err = configuration.LoadAdvanced(val, "storage", &config.Storage, sources...); err != nil {
+		return nil, fmt.Errorf("failed to load the target configuration: %w", err)
+	}
+
+	validator.ValidateStorage(&config.Storage, val)
+
+	if errs := val.Errors(); len(errs) != 0 {
+		err = fmt.Errorf("failed to validate the target configuration: %w", errs[0])
+
+		for _, e := range errs[1:] {
+			err = fmt.Errorf("%w, %v", err, e)
+		}
+
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// StorageBackupRunE is the RunE for the authelia storage backup command.
+func (ctx *CmdCtx) StorageBackupRunE(cmd *cobra.Command, _ []string) (err error) {
+	defer func() {
+		_ = ctx.providers.StorageProvider.Close()
+	}()
+
+	var (
+		filename, passphrase string
+		limit                int
+	)
+
+	if filename, err = cmd.Flags().GetString(cmdFlagNameFile); err != nil {
+		return err
+	}
+
+	if limit, err = storageBatchSize(cmd); err != nil {
+		return err
+	}
+
+	switch _, err = os.Stat(filename); {
+	case err == nil:
+		return fmt.Errorf("must specify a file that doesn't exist but '%s' exists", filename)
+	case !os.IsNotExist(err):
+		return fmt.Errorf("error occurred opening '%s': %w", filename, err)
+	}
+
+	if err = ctx.CheckSchema(); err != nil {
+		return storageWrapCheckSchemaErr(err)
+	}
+
+	if passphrase, err = storageBackupPassphrase(cmd, true); err != nil {
+		return err
+	}
+
+	var file *os.File
+
+	if file, err = os.OpenFile(filename, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600); err != nil {
+		return fmt.Errorf("error occurred creating '%s': %w", filename, err)
+	}
+
+	var result storage.SchemaBackupResult
+
+	if result, err = ctx.providers.StorageProvider.SchemaBackup(ctx, file, passphrase, limit); err == nil {
+		err = file.Close()
+	} else {
+		_ = file.Close()
+	}
+
+	if err != nil {
+		_ = os.Remove(filename)
+
+		return fmt.Errorf("failed to backup the storage: %w", err)
+	}
+
+	fmt.Printf("Successfully backed up %d rows to the '%s' file:\n\n%s", result.Total(), filename, storageBackupResultString(result))
+
+	return nil
+}
+
+// StorageRestoreRunE is the RunE for the authelia storage restore command.
+func (ctx *CmdCtx) StorageRestoreRunE(cmd *cobra.Command, args []string) (err error) {
+	defer func() {
+		_ = ctx.providers.StorageProvider.Close()
+	}()
+
+	var (
+		passphrase string
+		limit      int
+		file       *os.File
+	)
+
+	if limit, err = storageBatchSize(cmd); err != nil {
+		return err
+	}
+
+	if file, err = os.Open(args[0]); err != nil {
+		return fmt.Errorf("error occurred opening '%s': %w", args[0], err)
+	}
+
+	defer file.Close()
+
+	if passphrase, err = storageBackupPassphrase(cmd, false); err != nil {
+		return err
+	}
+
+	var backup *storage.BackupReader
+
+	if backup, err = storage.OpenBackup(file, passphrase); err != nil {
+		return fmt.Errorf("failed to open the backup: %w", err)
+	}
+
+	provider := ctx.providers.StorageProvider
+	manifest := backup.Manifest()
+
+	var version, latest int
+
+	if version, err = provider.SchemaVersion(ctx); err != nil {
+		return fmt.Errorf("failed to read the schema version: %w", err)
+	}
+
+	if latest, err = provider.SchemaLatestVersion(); err != nil {
+		return fmt.Errorf("failed to read the latest schema version: %w", err)
+	}
+
+	switch {
+	case manifest.SchemaVersion > latest:
+		return fmt.Errorf("the backup schema version %d is newer than the latest schema version %d supported by this version of Authelia", manifest.SchemaVersion, latest)
+	case version > manifest.SchemaVersion:
+		return fmt.Errorf("the storage schema version %d is newer than the backup schema version %d", version, manifest.SchemaVersion)
+	case version != 0:
+		var result storage.EncryptionValidationResult
+
+		if result, err = provider.SchemaEncryptionCheckKey(ctx, false); err != nil && !errors.Is(err, storage.ErrSchemaEncryptionVersionUnsupported) {
+			return fmt.Errorf("failed to check the storage encryption key: %w", err)
+		}
+
+		if err == nil && !result.Success() {
+			return storage.ErrSchemaEncryptionInvalidKey
+		}
+	}
+
+	if version < manifest.SchemaVersion {
+		if err = provider.SchemaMigrate(ctx, true, manifest.SchemaVersion); err != nil {
+			return fmt.Errorf("failed to migrate the schema to the backup schema version %d: %w", manifest.SchemaVersion, err)
+		}
+	}
+
+	var result storage.SchemaBackupResult
+
+	if result, err = provider.SchemaRestore(ctx, backup, limit); err != nil {
+		return fmt.Errorf("failed to restore the storage: %w", err)
+	}
+
+	if manifest.SchemaVersion < latest {
+		if err = provider.SchemaMigrate(ctx, true, latest); err != nil {
+			return fmt.Errorf("failed to migrate the schema to the latest schema version %d after the restore: %w", latest, err)
+		}
+	}
+
+	fmt.Printf("Successfully restored %d rows from the backup created at %s:\n\n%s", result.Total(), manifest.CreatedAt.Format(time.RFC3339), storageBackupResultString(result))
+
+	return nil
+}
+
+func storageBatchSize(cmd *cobra.Command) (limit int, err error) {
+	if limit, err = cmd.Flags().GetInt(cmdFlagNameBatchSize); err != nil {
+		return 0, err
+	}
+
+	if limit <= 0 {
+		return 0, fmt.Errorf("the batch size must be more than 0 but it's configured as %d", limit)
+	}
+
+	return limit, nil
+}
+
+func storageBackupPassphrase(cmd *cobra.Command, confirm bool) (passphrase string, err error) {
+	if passphrase, err = cmd.Flags().GetString(cmdFlagNamePassphrase); err != nil || passphrase != "" {
+		return passphrase, err
+	}
+
+	if passphrase, err = termReadPasswordWithPrompt("Enter Backup Passphrase: ", cmdFlagNamePassphrase); err != nil {
+		return "", err
+	}
+
+	if confirm {
+		var confirmation string
+
+		if confirmation, err = termReadPasswordWithPrompt("Confirm Backup Passphrase: ", cmdFlagNamePassphrase); err != nil {
+			return "", err
+		}
+
+		if passphrase != confirmation {
+			return "", errors.New("the passphrase did not match the confirmation passphrase")
+		}
+	}
+
+	if passphrase == "" {
+		return "", errors.New("the passphrase must not be blank")
+	}
+
+	return passphrase, nil
+}
+
+func storageBackupResultString(result storage.SchemaBackupResult) string {
+	output := strings.Builder{}
+
+	for _, table := range result.Tables {
+		output.WriteString(fmt.Sprintf("\t%s: %d\n", table.Table, table.Count))
+	}
+
+	return output.String()
+}
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+)
+
+func TestStorageUserTOTPDeleteNotify(t *testing.T) {
+	ctx, _ := newTestUsersCmdCtx(t)
+
+	cmd := newUsersAddCmd(ctx)
+	require.NoError(t, cmd.ParseFlags([]string{"--display-name", "John Doe", "--email", "john.doe@example.com", "--password", "password"}))
+	require.NoError(t, ctx.UsersAddRunE(cmd, []string{"john"}))
+
+	filename := filepath.Join(t.TempDir(), "notification.txt")
+
+	ctx.config.Notifier.FileSystem = &schema.FileSystemNotifierConfiguration{Filename: filename}
+
+	require.NoError(t, ctx.storageUserTOTPDeleteNotify("john"))
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), "john.doe@example.com")
+	assert.Contains(t, string(data), "Second Factor Method Removed")
+	assert.Contains(t, string(data), "This email was generated by an administrator.")
+
+	assert.EqualError(t, ctx.storageUserTOTPDeleteNotify("harry"), "failed to retrieve the user details: user not found")
+
+	require.NoError(t, os.Remove(filename))
+
+	ctx.config.Notifier.Events.DisableTOTPRemoved = true
+
+	require.NoError(t, ctx.storageUserTOTPDeleteNotify("john"))
+
+	_, err = os.Stat(filename)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,481 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-crypt/crypt/algorithm"
+	"github.com/spf13/cobra"
+
+	"github.com/authelia/authelia/v4/internal/authentication"
+)
+
+func newUsersCmd(ctx *CmdCtx) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     "users",
+		Short:   cmdAutheliaUsersShort,
+		Long:    cmdAutheliaUsersLong,
+		Example: cmdAutheliaUsersExample,
+		PersistentPreRunE: ctx.ChainRunE(
+			ctx.ConfigUsersCommandLineConfigRunE,
+			ctx.ConfigLoadRunE,
+			ctx.ConfigValidateSectionUsersRunE,
+		),
+		Args: cobra.NoArgs,
+
+		DisableAutoGenTag: true,
+	}
+
+	cmd.PersistentFlags().String(cmdFlagNamePath, "", "the file authentication database path")
+
+	cmd.AddCommand(
+		newUsersAddCmd(ctx),
+		newUsersDeleteCmd(ctx),
+		newUsersDisableCmd(ctx),
+		newUsersEnableCmd(ctx),
+		newUsersSetPasswordCmd(ctx),
+		newUsersSetGroupsCmd(ctx),
+		newUsersListCmd(ctx),
+	)
+
+	return cmd
+}
+
+func newUsersAddCmd(ctx *CmdCtx) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     "add <username>",
+		Short:   cmdAutheliaUsersAddShort,
+		Long:    cmdAutheliaUsersAddLong,
+		Example: cmdAutheliaUsersAddExample,
+		RunE:    ctx.UsersAddRunE,
+		Args:    cobra.ExactArgs(1),
+
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().String(cmdFlagNameDisplayName, "", "the display name of the user, defaults to the username")
+	cmd.Flags().String(cmdFlagNameEmail, "", "the email address of the user")
+	cmd.Flags().StringSlice(cmdFlagNameGroups, nil, "the groups of the user")
+	cmd.Flags().Bool(cmdFlagNameDisabled, false, "adds the user as a disabled user")
+	cmd.Flags().Bool(cmdFlagNameRequireChange, false, "requires the user to change their password on their next login")
+
+	cmdFlagPassword(cmd, true)
+	cmdFlagRandomPassword(cmd)
+
+	return cmd
+}
+
+func newUsersDeleteCmd(ctx *CmdCtx) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     "delete <username>",
+		Short:   cmdAutheliaUsersDeleteShort,
+		Long:    cmdAutheliaUsersDeleteLong,
+		Example: cmdAutheliaUsersDeleteExample,
+		RunE:    ctx.UsersDeleteRunE,
+		Args:    cobra.ExactArgs(1),
+
+		DisableAutoGenTag: true,
+	}
+
+	return cmd
+}
+
+func newUsersDisableCmd(ctx *CmdCtx) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     "disable <username>",
+		Short:   cmdAutheliaUsersDisableShort,
+		Long:    cmdAutheliaUsersDisableLong,
+		Example: cmdAutheliaUsersDisableExample,
+		RunE:    ctx.NewUsersSetDisabledRunE(true),
+		Args:    cobra.ExactArgs(1),
+
+		DisableAutoGenTag: true,
+	}
+
+	return cmd
+}
+
+func newUsersEnableCmd(ctx *CmdCtx) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     "enable <username>",
+		Short:   cmdAutheliaUsersEnableShort,
+		Long:    cmdAutheliaUsersEnableLong,
+		Example: cmdAutheliaUsersEnableExample,
+		RunE:    ctx.NewUsersSetDisabledRunE(false),
+		Args:    cobra.ExactArgs(1),
+
+		DisableAutoGenTag: true,
+	}
+
+	return cmd
+}
+
+func newUsersSetPasswordCmd(ctx *CmdCtx) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     "set-password <username>",
+		Short:   cmdAutheliaUsersSetPasswordShort,
+		Long:    cmdAutheliaUsersSetPasswordLong,
+		Example: cmdAutheliaUsersSetPasswordExample,
+		RunE:    ctx.UsersSetPasswordRunE,
+		Args:    cobra.ExactArgs(1),
+
+		DisableAutoGenTag: true,
+	}
+
+	cmdFlagPassword(cmd, true)
+	cmdFlagRandomPassword(cmd)
+
+	cmd.Flags().Bool(cmdFlagNameRequireChange, false, "requires the user to change their password on their next login")
+
+	return cmd
+}
+
+func newUsersSetGroupsCmd(ctx *CmdCtx) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     "set-groups <username> [groups...]",
+		Short:   cmdAutheliaUsersSetGroupsShort,
+		Long:    cmdAutheliaUsersSetGroupsLong,
+		Example: cmdAutheliaUsersSetGroupsExample,
+		RunE:    ctx.UsersSetGroupsRunE,
+		Args:    cobra.MinimumNArgs(1),
+
+		DisableAutoGenTag: true,
+	}
+
+	return cmd
+}
+
+func newUsersListCmd(ctx *CmdCtx) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     "list",
+		Short:   cmdAutheliaUsersListShort,
+		Long:    cmdAutheliaUsersListLong,
+		Example: cmdAutheliaUsersListExample,
+		RunE:    ctx.UsersListRunE,
+		Args:    cobra.NoArgs,
+
+		DisableAutoGenTag: true,
+	}
+
+	return cmd
+}
+
+// ConfigUsersCommandLineConfigRunE configures the users command mapping.
+func (ctx *CmdCtx) ConfigUsersCommandLineConfigRunE(cmd *cobra.Command, _ []string) (err error) {
+	flagsMap := map[string]string{
+		cmdFlagNamePath: "authentication_backend.file.path",
+	}
+
+	return ctx.ConfigSetFlagsMapRunE(cmd.Flags(), flagsMap, true, false)
+}
+
+// ConfigValidateSectionUsersRunE validates the configuration (structure, file authentication backend section).
+func (ctx *CmdCtx) ConfigValidateSectionUsersRunE(cmd *cobra.Command, args []string) (err error) {
+	if ctx.config.AuthenticationBackend.File == nil || ctx.config.AuthenticationBackend.File.Path == "" {
+		return fmt.Errorf("the file authentication backend is not configured, either configure it or use the --%s flag", cmdFlagNamePath)
+	}
+
+	return ctx.ConfigValidateSectionPasswordRunE(cmd, args)
+}
+
+// UsersAddRunE is the RunE for the authelia users add command.
+func (ctx *CmdCtx) UsersAddRunE(cmd *cobra.Command, args []string) (err error) {
+	var (
+		model    *authentication.DatabaseModel
+		details  authentication.UserDetailsModel
+		disabled bool
+	)
+
+	username := args[0]
+
+	if username == "" {
+		return fmt.Errorf("the username must not be empty")
+	}
+
+	if details.DisplayName, err = cmd.Flags().GetString(cmdFlagNameDisplayName); err != nil {
+		return err
+	}
+
+	if details.DisplayName == "" {
+		details.DisplayName = username
+	}
+
+	if details.Email, err = cmd.Flags().GetString(cmdFlagNameEmail); err != nil {
+		return err
+	}
+
+	if details.Groups, err = cmd.Flags().GetStringSlice(cmdFlagNameGroups); err != nil {
+		return err
+	}
+
+	if disabled, err = cmd.Flags().GetBool(cmdFlagNameDisabled); err != nil {
+		return err
+	}
+
+	details.Disabled = disabled
+
+	if details.PasswordChangeRequired, err = cmd.Flags().GetBool(cmdFlagNameRequireChange); err != nil {
+		return err
+	}
+
+	if model, err = ctx.usersReadDatabase(true); err != nil {
+		return err
+	}
+
+	if _, ok := model.Users[username]; ok {
+		return fmt.Errorf("user '%s' already exists in the authentication database", username)
+	}
+
+	if details.HashedPassword, err = ctx.usersHashPassword(cmd); err != nil {
+		return err
+	}
+
+	details.PasswordChangedAt = time.Now().UTC().Truncate(time.Second)
+
+	model.Users[username] = details
+
+	if err = ctx.usersWriteDatabase(model); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully added user '%s'\n", username)
+
+	return nil
+}
+
+// UsersDeleteRunE is the RunE for the authelia users delete command.
+func (ctx *CmdCtx) UsersDeleteRunE(_ *cobra.Command, args []string) (err error) {
+	var model *authentication.DatabaseModel
+
+	username := args[0]
+
+	if model, err = ctx.usersReadDatabase(false); err != nil {
+		return err
+	}
+
+	if _, err = usersGetUser(model, username); err != nil {
+		return err
+	}
+
+	delete(model.Users, username)
+
+	if err = ctx.usersWriteDatabase(model); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully deleted user '%s'\n", username)
+
+	return nil
+}
+
+// NewUsersSetDisabledRunE creates the RunE for the authelia users disable and enable commands.
+func (ctx *CmdCtx) NewUsersSetDisabledRunE(disabled bool) func(cmd *cobra.Command, args []string) (err error) {
+	return func(_ *cobra.Command, args []string) (err error) {
+		var (
+			model   *authentication.DatabaseModel
+			details authentication.UserDetailsModel
+		)
+
+		username := args[0]
+
+		if model, err = ctx.usersReadDatabase(false); err != nil {
+			return err
+		}
+
+		if details, err = usersGetUser(model, username); err != nil {
+			return err
+		}
+
+		details.Disabled = disabled
+
+		model.Users[username] = details
+
+		if err = ctx.usersWriteDatabase(model); err != nil {
+			return err
+		}
+
+		if disabled {
+			fmt.Printf("Successfully disabled user '%s'\n", username)
+		} else {
+			fmt.Printf("Successfully enabled user '%s'\n", username)
+		}
+
+		return nil
+	}
+}
+
+// UsersSetPasswordRunE is the RunE for the authelia users set-password command.
+func (ctx *CmdCtx) UsersSetPasswordRunE(cmd *cobra.Command, args []string) (err error) {
+	var (
+		model   *authentication.DatabaseModel
+		details authentication.UserDetailsModel
+	)
+
+	username := args[0]
+
+	if model, err = ctx.usersReadDatabase(false); err != nil {
+		return err
+	}
+
+	if details, err = usersGetUser(model, username); err != nil {
+		return err
+	}
+
+	if details.PasswordChangeRequired, err = cmd.Flags().GetBool(cmdFlagNameRequireChange); err != nil {
+		return err
+	}
+
+	if details.HashedPassword, err = ctx.usersHashPassword(cmd); err != nil {
+		return err
+	}
+
+	details.PasswordChangedAt = time.Now().UTC().Truncate(time.Second)
+
+	model.Users[username] = details
+
+	if err = ctx.usersWriteDatabase(model); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully set the password of user '%s'\n", username)
+
+	return nil
+}
+
+// UsersSetGroupsRunE is the RunE for the authelia users set-groups command.
+func (ctx *CmdCtx) UsersSetGroupsRunE(_ *cobra.Command, args []string) (err error) {
+	var (
+		model   *authentication.DatabaseModel
+		details authentication.UserDetailsModel
+	)
+
+	username := args[0]
+
+	if model, err = ctx.usersReadDatabase(false); err != nil {
+		return err
+	}
+
+	if details, err = usersGetUser(model, username); err != nil {
+		return err
+	}
+
+	details.Groups = append([]string{}, args[1:]...)
+
+	model.Users[username] = details
+
+	if err = ctx.usersWriteDatabase(model); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully set the groups of user '%s'\n", username)
+
+	return nil
+}
+
+// UsersListRunE is the RunE for the authelia users list command.
+func (ctx *CmdCtx) UsersListRunE(_ *cobra.Command, _ []string) (err error) {
+	var model *authentication.DatabaseModel
+
+	if model, err = ctx.usersReadDatabase(false); err != nil {
+		return err
+	}
+
+	usernames := make([]string, 0, len(model.Users))
+
+	for username := range model.Users {
+		usernames = append(usernames, username)
+	}
+
+	sort.Strings(usernames)
+
+	fmt.Printf("Users:\n\nUsername\tDisplay Name\tEmail\tGroups\tDisabled\n")
+
+	for _, username := range usernames {
+		details := model.Users[username]
+
+		fmt.Printf("%s\t%s\t%s\t%s\t%t\n", username, details.DisplayName, details.Email, strings.Join(details.Groups, ","), details.Disabled)
+	}
+
+	return nil
+}
+
+func (ctx *CmdCtx) usersReadDatabase(allowMissing bool) (model *authentication.DatabaseModel, err error) {
+	model = &authentication.DatabaseModel{Users: map[string]authentication.UserDetailsModel{}}
+
+	if err = model.Read(ctx.config.AuthenticationBackend.File.Path); err != nil {
+		if allowMissing && (errors.Is(err, fs.ErrNotExist) || errors.Is(err, authentication.ErrNoContent)) {
+			return &authentication.DatabaseModel{Users: map[string]authentication.UserDetailsModel{}}, nil
+		}
+
+		return nil, fmt.Errorf("error reading the authentication database: %w", err)
+	}
+
+	return model, nil
+}
+
+// usersWriteDatabase checks the database can be loaded by the file authentication backend before writing it so the
+// running instance does not fail to reload it.
+func (ctx *CmdCtx) usersWriteDatabase(model *authentication.DatabaseModel) (err error) {
+	config := ctx.config.AuthenticationBackend.File
+
+	database := authentication.NewFileUserDatabase(config.Path, config.Search.Email, config.Search.CaseInsensitive)
+
+	if err = model.ReadToFileUserDatabase(database); err != nil {
+		return fmt.Errorf("error decoding the authentication database: %w", err)
+	}
+
+	if err = database.LoadAliases(); err != nil {
+		return err
+	}
+
+	if err = model.Write(config.Path); err != nil {
+		return fmt.Errorf("error writing the authentication database: %w", err)
+	}
+
+	return nil
+}
+
+func (ctx *CmdCtx) usersHashPassword(cmd *cobra.Command) (digest string, err error) {
+	var (
+		hash     algorithm.Hash
+		d        algorithm.Digest
+		password string
+		random   bool
+	)
+
+	if password, random, err = cmdCryptoHashGetPassword(cmd, nil, false, true); err != nil {
+		return "", err
+	}
+
+	if len(password) == 0 {
+		return "", fmt.Errorf("no password provided")
+	}
+
+	if hash, err = authentication.NewFileCryptoHashFromConfig(ctx.config.AuthenticationBackend.File.Password); err != nil {
+		return "", err
+	}
+
+	if d, err = hash.Hash(password); err != nil {
+		return "", err
+	}
+
+	if random {
+		fmt.Printf("Random Password: %s\n", password)
+	}
+
+	return d.Encode(), nil
+}
+
+func usersGetUser(model *authentication.DatabaseModel, username string) (details authentication.UserDetailsModel, err error) {
+	var ok bool
+
+	if details, ok = model.Users[username]; !ok {
+		return details, fmt.Errorf("user '%s' does not exist in the authentication database", username)
+	}
+
+	return details, nil
+}
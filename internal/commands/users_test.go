@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/authentication"
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+)
+
+func newTestUsersCmdCtx(t *testing.T) (ctx *CmdCtx, path string) {
+	path = filepath.Join(t.TempDir(), "users_database.yml")
+
+	password := schema.DefaultPasswordConfig
+	password.Algorithm = "sha2crypt"
+	password.SHA2Crypt.Iterations = 1000
+
+	ctx = NewCmdCtx()
+	ctx.config.AuthenticationBackend.File = &schema.FileAuthenticationBackend{
+		Path:     path,
+		Password: password,
+	}
+
+	return ctx, path
+}
+
+func TestUsersCommands(t *testing.T) {
+	ctx, path := newTestUsersCmdCtx(t)
+
+	cmd := newUsersAddCmd(ctx)
+	require.NoError(t, cmd.ParseFlags([]string{"--display-name", "John Doe", "--email", "john.doe@example.com", "--groups", "admins,dev", "--password", "password"}))
+
+	require.NoError(t, ctx.UsersAddRunE(cmd, []string{"john"}))
+	assert.EqualError(t, ctx.UsersAddRunE(cmd, []string{"john"}), "user 'john' already exists in the authentication database")
+
+	cmd = newUsersAddCmd(ctx)
+	require.NoError(t, cmd.ParseFlags([]string{"--email", "john.doe@example.com", "--password", "password"}))
+
+	ctx.config.AuthenticationBackend.File.Search.Email = true
+
+	err := ctx.UsersAddRunE(cmd, []string{"harry"})
+	require.Error(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^error loading authentication database: email 'john.doe@example.com' is configured for for more than one user \(users are '(harry|john)', '(harry|john)'\) which isn't allowed when email search is enabled$`), err.Error())
+
+	ctx.config.AuthenticationBackend.File.Search.Email = false
+
+	require.NoError(t, cmd.Flags().Set(cmdFlagNameEmail, "harry.potter@example.com"))
+	require.NoError(t, ctx.UsersAddRunE(cmd, []string{"harry"}))
+
+	require.NoError(t, ctx.NewUsersSetDisabledRunE(true)(nil, []string{"harry"}))
+	require.NoError(t, ctx.UsersSetGroupsRunE(nil, []string{"john", "ops"}))
+
+	cmd = newUsersSetPasswordCmd(ctx)
+	require.NoError(t, cmd.ParseFlags([]string{"--password", "newpassword"}))
+	require.NoError(t, ctx.UsersSetPasswordRunE(cmd, []string{"john"}))
+
+	assert.EqualError(t, ctx.UsersDeleteRunE(nil, []string{"fred"}), "user 'fred' does not exist in the authentication database")
+
+	config := *ctx.config.AuthenticationBackend.File
+
+	provider := authentication.NewFileUserProvider(&config)
+	require.NoError(t, provider.StartupCheck())
+
+	valid, err := provider.CheckUserPassword("john", "newpassword")
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	_, err = provider.CheckUserPassword("harry", "password")
+	assert.ErrorIs(t, err, authentication.ErrUserNotFound)
+
+	details, err := provider.GetDetails("john")
+	require.NoError(t, err)
+	assert.Equal(t, "John Doe", details.DisplayName)
+	assert.Equal(t, []string{"ops"}, details.Groups)
+
+	require.NoError(t, ctx.NewUsersSetDisabledRunE(false)(nil, []string{"harry"}))
+	require.NoError(t, ctx.UsersDeleteRunE(nil, []string{"john"}))
+
+	model := &authentication.DatabaseModel{}
+	require.NoError(t, model.Read(path))
+
+	assert.Len(t, model.Users, 1)
+	assert.Equal(t, "harry", model.Users["harry"].DisplayName)
+	assert.False(t, model.Users["harry"].Disabled)
+	assert.False(t, model.Users["harry"].PasswordChangedAt.IsZero())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestUsersCommandsShouldRequirePasswordChange(t *testing.T) {
+	ctx, path := newTestUsersCmdCtx(t)
+
+	cmd := newUsersAddCmd(ctx)
+	require.NoError(t, cmd.ParseFlags([]string{"--password", "password", "--require-change"}))
+	require.NoError(t, ctx.UsersAddRunE(cmd, []string{"john"}))
+
+	model := &authentication.DatabaseModel{}
+	require.NoError(t, model.Read(path))
+	assert.True(t, model.Users["john"].PasswordChangeRequired)
+
+	ctx.config.AuthenticationBackend.File.PasswordExpiry.Enable = true
+
+	config := *ctx.config.AuthenticationBackend.File
+
+	provider := authentication.NewFileUserProvider(&config)
+	require.NoError(t, provider.StartupCheck())
+
+	valid, err := provider.CheckUserPassword("john", "password")
+	assert.ErrorIs(t, err, authentication.ErrPasswordChangeRequired)
+	assert.True(t, valid)
+
+	cmd = newUsersSetPasswordCmd(ctx)
+	require.NoError(t, cmd.ParseFlags([]string{"--password", "newpassword"}))
+	require.NoError(t, ctx.UsersSetPasswordRunE(cmd, []string{"john"}))
+
+	require.NoError(t, model.Read(path))
+	assert.False(t, model.Users["john"].PasswordChangeRequired)
+}
+
+func TestUsersCommandsShouldErrorWithoutDatabase(t *testing.T) {
+	ctx, _ := newTestUsersCmdCtx(t)
+
+	assert.ErrorContains(t, ctx.UsersListRunE(nil, nil), "error reading the authentication database: failed to read the")
+	assert.ErrorContains(t, ctx.UsersDeleteRunE(nil, []string{"john"}), "error reading the authentication database: failed to read the")
+
+	ctx.config.AuthenticationBackend.File = nil
+
+	assert.EqualError(t, ctx.ConfigValidateSectionUsersRunE(nil, nil), "the file authentication backend is not configured, either configure it or use the --path flag")
+}
@@ -1,7 +1,9 @@
 package schema
 
 import (
+	"net/url"
 	"regexp"
+	"time"
 )
 
 // AccessControlConfiguration represents the configuration related to ACLs.
@@ -9,6 +11,18 @@ type AccessControlConfiguration struct {
 	DefaultPolicy string       `koanf:"default_policy"`
 	Networks      []ACLNetwork `koanf:"networks"`
 	Rules         []ACLRule    `koanf:"rules"`
+	External      ACLExternal  `koanf:"external"`
+	Watch         bool         `koanf:"watch"`
+}
+
+// ACLExternal represents the configuration of the external policy decision point used by rules with the external
+// policy.
+type ACLExternal struct {
+	Address       *url.URL      `koanf:"address"`
+	Timeout       time.Duration `koanf:"timeout"`
+	CacheDuration time.Duration `koanf:"cache_duration"`
+	CacheSize     int           `koanf:"cache_size"`
+	FailurePolicy string        `koanf:"failure_policy"`
 }
 
 // ACLNetwork represents one ACL network group entry.
@@ -27,15 +41,25 @@ type ACLRule struct {
 	Resources    []regexp.Regexp  `koanf:"resources"`
 	Methods      []string         `koanf:"methods"`
 	Query        [][]ACLQueryRule `koanf:"query"`
+	Headers      [][]ACLQueryRule `koanf:"headers"`
+
+	MaxAuthenticationAge MaxAuthenticationAge `koanf:"max_authentication_age"`
 }
 
-// ACLQueryRule represents the ACL query criteria.
+// ACLQueryRule represents the ACL query and header criteria.
 type ACLQueryRule struct {
 	Operator string `koanf:"operator"`
 	Key      string `koanf:"key"`
 	Value    any    `koanf:"value"`
 }
 
+// DefaultACLExternal represents the default configuration related to the external policy decision point.
+var DefaultACLExternal = ACLExternal{
+	Timeout:       time.Second * 5,
+	CacheSize:     10000,
+	FailurePolicy: "deny",
+}
+
 // DefaultACLNetwork represents the default configuration related to access control network group configuration.
 var DefaultACLNetwork = []ACLNetwork{
 	{
@@ -8,26 +8,60 @@ import (
 
 // AuthenticationBackend represents the configuration related to the authentication backend.
 type AuthenticationBackend struct {
-	PasswordReset PasswordResetAuthenticationBackend `koanf:"password_reset"`
+	PasswordReset  PasswordResetAuthenticationBackend  `koanf:"password_reset"`
+	PasswordChange PasswordChangeAuthenticationBackend `koanf:"password_change"`
 
 	RefreshInterval string `koanf:"refresh_interval"`
 
+	Cache AuthenticationBackendCache `koanf:"cache"`
+
+	Chain []string `koanf:"chain"`
+
 	File *FileAuthenticationBackend `koanf:"file"`
 	LDAP *LDAPAuthenticationBackend `koanf:"ldap"`
 }
 
+// AuthenticationBackendCache represents the configuration related to caching user details retrieved from the
+// authentication backend.
+type AuthenticationBackendCache struct {
+	Enable            bool          `koanf:"enable"`
+	Lifespan          time.Duration `koanf:"lifespan"`
+	NegativeLifespan  time.Duration `koanf:"negative_lifespan"`
+	BackgroundRefresh time.Duration `koanf:"background_refresh"`
+}
+
+// AuthenticationBackendPasswordExpiry represents the configuration related to detecting passwords which have expired
+// or which must be changed before the user is allowed to login.
+type AuthenticationBackendPasswordExpiry struct {
+	Enable bool          `koanf:"enable"`
+	MaxAge time.Duration `koanf:"max_age"`
+}
+
 // PasswordResetAuthenticationBackend represents the configuration related to password reset functionality.
 type PasswordResetAuthenticationBackend struct {
 	Disable   bool    `koanf:"disable"`
 	CustomURL url.URL `koanf:"custom_url"`
 }
 
+// PasswordChangeAuthenticationBackend represents the configuration related to the password change functionality for
+// logged in users.
+type PasswordChangeAuthenticationBackend struct {
+	Disable             bool          `koanf:"disable"`
+	RequireSecondFactor bool          `koanf:"require_second_factor"`
+	SecondFactorMaxAge  time.Duration `koanf:"second_factor_max_age"`
+	InvalidateSessions  bool          `koanf:"invalidate_sessions"`
+}
+
 // FileAuthenticationBackend represents the configuration related to file-based backend.
 type FileAuthenticationBackend struct {
 	Path     string   `koanf:"path"`
 	Watch    bool     `koanf:"watch"`
 	Password Password `koanf:"password"`
 
+	RehashOnLogin bool `koanf:"rehash_on_login"`
+
+	PasswordExpiry AuthenticationBackendPasswordExpiry `koanf:"password_expiry"`
+
 	Search FileSearchAuthenticationBackend `koanf:"search"`
 }
 
@@ -97,31 +131,69 @@ type SCryptPassword struct {
 type LDAPAuthenticationBackend struct {
 	Implementation string        `koanf:"implementation"`
 	URL            string        `koanf:"url"`
+	AdditionalURLs []string      `koanf:"additional_urls"`
+	URLStrategy    string        `koanf:"url_strategy"`
 	Timeout        time.Duration `koanf:"timeout"`
 	StartTLS       bool          `koanf:"start_tls"`
 	TLS            *TLSConfig    `koanf:"tls"`
 
+	Pooling LDAPAuthenticationBackendPooling `koanf:"pooling"`
+
 	BaseDN string `koanf:"base_dn"`
 
 	AdditionalUsersDN string `koanf:"additional_users_dn"`
 	UsersFilter       string `koanf:"users_filter"`
 
-	AdditionalGroupsDN string `koanf:"additional_groups_dn"`
-	GroupsFilter       string `koanf:"groups_filter"`
+	AdditionalGroupsDN   string `koanf:"additional_groups_dn"`
+	GroupsFilter         string `koanf:"groups_filter"`
+	GroupSearchMode      string `koanf:"group_search_mode"`
+	GroupSearchMaxDepth  int    `koanf:"group_search_max_depth"`
+	GroupMemberAttribute string `koanf:"group_member_attribute"`
 
 	GroupNameAttribute   string `koanf:"group_name_attribute"`
 	UsernameAttribute    string `koanf:"username_attribute"`
 	MailAttribute        string `koanf:"mail_attribute"`
 	DisplayNameAttribute string `koanf:"display_name_attribute"`
+	MemberOfAttribute    string `koanf:"member_of_attribute"`
 
 	PermitReferrals               bool `koanf:"permit_referrals"`
 	PermitUnauthenticatedBind     bool `koanf:"permit_unauthenticated_bind"`
 	PermitFeatureDetectionFailure bool `koanf:"permit_feature_detection_failure"`
 
+	PasswordExpiry AuthenticationBackendPasswordExpiry `koanf:"password_expiry"`
+
 	User     string `koanf:"user"`
 	Password string `koanf:"password"`
 }
 
+// LDAPAuthenticationBackendPooling represents the configuration related to LDAP connection pooling.
+type LDAPAuthenticationBackendPooling struct {
+	Enable              bool          `koanf:"enable"`
+	Count               int           `koanf:"count"`
+	IdleTimeout         time.Duration `koanf:"idle_timeout"`
+	HealthCheckInterval time.Duration `koanf:"health_check_interval"`
+	Timeout             time.Duration `koanf:"timeout"`
+}
+
+// DefaultAuthenticationBackendCache represents the default authentication backend cache configuration.
+var DefaultAuthenticationBackendCache = AuthenticationBackendCache{
+	Lifespan:         time.Minute * 5,
+	NegativeLifespan: time.Second * 30,
+}
+
+// DefaultPasswordChangeAuthenticationBackend represents the default password change configuration.
+var DefaultPasswordChangeAuthenticationBackend = PasswordChangeAuthenticationBackend{
+	SecondFactorMaxAge: time.Minute * 5,
+}
+
+// DefaultLDAPAuthenticationBackendPooling represents the default LDAP connection pooling configuration.
+var DefaultLDAPAuthenticationBackendPooling = LDAPAuthenticationBackendPooling{
+	Count:               5,
+	IdleTimeout:         time.Minute * 5,
+	HealthCheckInterval: time.Second * 30,
+	Timeout:             time.Second * 10,
+}
+
 // DefaultPasswordConfig represents the default configuration related to Argon2id hashing.
 var DefaultPasswordConfig = Password{
 	Algorithm: argon2,
@@ -179,6 +251,9 @@ var DefaultLDAPAuthenticationBackendConfigurationImplementationCustom = LDAPAuth
 	MailAttribute:        ldapAttrMail,
 	DisplayNameAttribute: ldapAttrDisplayName,
 	GroupNameAttribute:   ldapAttrCommonName,
+	GroupMemberAttribute: ldapAttrMember,
+	GroupSearchMaxDepth:  10,
+	MemberOfAttribute:    ldapAttrMemberOf,
 	Timeout:              time.Second * 5,
 	TLS: &TLSConfig{
 		MinimumVersion: TLSVersion{tls.VersionTLS12},
@@ -193,6 +268,9 @@ var DefaultLDAPAuthenticationBackendConfigurationImplementationActiveDirectory =
 	DisplayNameAttribute: ldapAttrDisplayName,
 	GroupsFilter:         "(&(member={dn})(|(sAMAccountType=268435456)(sAMAccountType=536870912)))",
 	GroupNameAttribute:   ldapAttrCommonName,
+	GroupMemberAttribute: ldapAttrMember,
+	GroupSearchMaxDepth:  10,
+	MemberOfAttribute:    ldapAttrMemberOf,
 	Timeout:              time.Second * 5,
 	TLS: &TLSConfig{
 		MinimumVersion: TLSVersion{tls.VersionTLS12},
@@ -207,6 +285,9 @@ var DefaultLDAPAuthenticationBackendConfigurationImplementationRFC2307bis = LDAP
 	DisplayNameAttribute: ldapAttrDisplayName,
 	GroupsFilter:         "(&(|(member={dn})(uniqueMember={dn}))(|(objectClass=groupOfNames)(objectClass=groupOfUniqueNames)(objectClass=groupOfMembers)))",
 	GroupNameAttribute:   ldapAttrCommonName,
+	GroupMemberAttribute: ldapAttrMember,
+	GroupSearchMaxDepth:  10,
+	MemberOfAttribute:    ldapAttrMemberOf,
 	Timeout:              time.Second * 5,
 	TLS: &TLSConfig{
 		MinimumVersion: TLSVersion{tls.VersionTLS12},
@@ -221,6 +302,9 @@ var DefaultLDAPAuthenticationBackendConfigurationImplementationFreeIPA = LDAPAut
 	DisplayNameAttribute: ldapAttrDisplayName,
 	GroupsFilter:         "(&(member={dn})(objectClass=groupOfNames))",
 	GroupNameAttribute:   ldapAttrCommonName,
+	GroupMemberAttribute: ldapAttrMember,
+	GroupSearchMaxDepth:  10,
+	MemberOfAttribute:    ldapAttrMemberOf,
 	Timeout:              time.Second * 5,
 	TLS: &TLSConfig{
 		MinimumVersion: TLSVersion{tls.VersionTLS12},
@@ -237,6 +321,9 @@ var DefaultLDAPAuthenticationBackendConfigurationImplementationLLDAP = LDAPAuthe
 	DisplayNameAttribute: ldapAttrCommonName,
 	GroupsFilter:         "(&(member={dn})(objectClass=groupOfUniqueNames))",
 	GroupNameAttribute:   ldapAttrCommonName,
+	GroupMemberAttribute: ldapAttrMember,
+	GroupSearchMaxDepth:  10,
+	MemberOfAttribute:    ldapAttrMemberOf,
 	Timeout:              time.Second * 5,
 	TLS: &TLSConfig{
 		MinimumVersion: TLSVersion{tls.VersionTLS12},
@@ -251,6 +338,9 @@ var DefaultLDAPAuthenticationBackendConfigurationImplementationGLAuth = LDAPAuth
 	DisplayNameAttribute: ldapAttrDescription,
 	GroupsFilter:         "(&(uniqueMember={dn})(objectClass=posixGroup))",
 	GroupNameAttribute:   ldapAttrCommonName,
+	GroupMemberAttribute: "uniqueMember",
+	GroupSearchMaxDepth:  10,
+	MemberOfAttribute:    ldapAttrMemberOf,
 	Timeout:              time.Second * 5,
 	TLS: &TLSConfig{
 		MinimumVersion: TLSVersion{tls.VersionTLS12},
@@ -41,6 +41,14 @@ const (
 	Version10 = "1.0"
 )
 
+const (
+	// AuthenticationBackendFile is the name of the file authentication backend.
+	AuthenticationBackendFile = "file"
+
+	// AuthenticationBackendLDAP is the name of the LDAP authentication backend.
+	AuthenticationBackendLDAP = "ldap"
+)
+
 // ErrTLSVersionNotSupported returned when an unknown TLS version supplied.
 var ErrTLSVersionNotSupported = errors.New("supplied tls version isn't supported")
 
@@ -58,6 +66,35 @@ const (
 	RefreshIntervalAlways = 0 * time.Millisecond
 )
 
+const (
+	// WebhookNotifierFormatGeneric is the string for the webhook notifier format which sends a signed JSON payload.
+	WebhookNotifierFormatGeneric = "generic"
+
+	// WebhookNotifierFormatSlack is the string for the webhook notifier format which sends Slack incoming webhook
+	// messages.
+	WebhookNotifierFormatSlack = "slack"
+
+	// WebhookNotifierFormatMattermost is the string for the webhook notifier format which sends Mattermost incoming
+	// webhook messages.
+	WebhookNotifierFormatMattermost = "mattermost"
+
+	// WebhookNotifierFormatMatrix is the string for the webhook notifier format which sends Matrix room messages.
+	WebhookNotifierFormatMatrix = "matrix"
+)
+
+const (
+	// PasswordPolicyBreachedModeDirectory is the string for the breached password policy mode which checks a directory
+	// of range files.
+	PasswordPolicyBreachedModeDirectory = "directory"
+
+	// PasswordPolicyBreachedModeBloom is the string for the breached password policy mode which checks a bloom filter
+	// file.
+	PasswordPolicyBreachedModeBloom = "bloom"
+
+	// PasswordPolicyBreachedModeAPI is the string for the breached password policy mode which checks a range API.
+	PasswordPolicyBreachedModeAPI = "api"
+)
+
 const (
 	// LDAPImplementationCustom is the string for the custom LDAP implementation.
 	LDAPImplementationCustom = "custom"
@@ -78,6 +115,32 @@ const (
 	LDAPImplementationGLAuth = "glauth"
 )
 
+const (
+	// LDAPURLStrategyFailover is the string for the LDAP URL strategy which always prefers the first available URL.
+	LDAPURLStrategyFailover = "failover"
+
+	// LDAPURLStrategyRoundRobin is the string for the LDAP URL strategy which rotates between the available URLs.
+	LDAPURLStrategyRoundRobin = "round-robin"
+)
+
+const (
+	// LDAPGroupSearchModeFilter is the string for the LDAP group search mode which returns the groups matching the
+	// groups filter.
+	LDAPGroupSearchModeFilter = "filter"
+
+	// LDAPGroupSearchModeInChain is the string for the LDAP group search mode which uses the LDAP_MATCHING_RULE_IN_CHAIN
+	// matching rule to return the groups the user is a direct or nested member of.
+	LDAPGroupSearchModeInChain = "in-chain"
+
+	// LDAPGroupSearchModeRecursive is the string for the LDAP group search mode which returns the groups matching the
+	// groups filter and then recursively searches for the groups those groups are members of.
+	LDAPGroupSearchModeRecursive = "recursive"
+
+	// LDAPGroupSearchModeMemberOf is the string for the LDAP group search mode which reads the groups from the member
+	// of attribute of the user.
+	LDAPGroupSearchModeMemberOf = "memberof"
+)
+
 // TOTP Algorithm.
 const (
 	TOTPAlgorithmSHA1   = "SHA1"
@@ -118,4 +181,6 @@ const (
 	ldapAttrDisplayName = "displayName"
 	ldapAttrDescription = "description"
 	ldapAttrCommonName  = "cn"
+	ldapAttrMember      = "member"
+	ldapAttrMemberOf    = "memberOf"
 )
@@ -66,6 +66,8 @@ type OpenIDConnectClientConfiguration struct {
 
 	ConsentMode                  string         `koanf:"consent_mode"`
 	ConsentPreConfiguredDuration *time.Duration `koanf:"pre_configured_consent_duration"`
+
+	MaxAuthenticationAge MaxAuthenticationAge `koanf:"max_authentication_age"`
 }
 
 // DefaultOpenIDConnectConfiguration contains defaults for OIDC.
@@ -49,9 +49,20 @@ var Keys = []string{
 	"identity_providers.oidc.clients[].userinfo_signing_algorithm",
 	"identity_providers.oidc.clients[].consent_mode",
 	"identity_providers.oidc.clients[].pre_configured_consent_duration",
+	"identity_providers.oidc.clients[].max_authentication_age.first_factor",
+	"identity_providers.oidc.clients[].max_authentication_age.second_factor",
 	"authentication_backend.password_reset.disable",
 	"authentication_backend.password_reset.custom_url",
+	"authentication_backend.password_change.disable",
+	"authentication_backend.password_change.require_second_factor",
+	"authentication_backend.password_change.second_factor_max_age",
+	"authentication_backend.password_change.invalidate_sessions",
 	"authentication_backend.refresh_interval",
+	"authentication_backend.cache.enable",
+	"authentication_backend.cache.lifespan",
+	"authentication_backend.cache.negative_lifespan",
+	"authentication_backend.cache.background_refresh",
+	"authentication_backend.chain",
 	"authentication_backend.file.path",
 	"authentication_backend.file.watch",
 	"authentication_backend.file.password.algorithm",
@@ -79,10 +90,15 @@ var Keys = []string{
 	"authentication_backend.file.password.parallelism",
 	"authentication_backend.file.password.key_length",
 	"authentication_backend.file.password.salt_length",
+	"authentication_backend.file.rehash_on_login",
+	"authentication_backend.file.password_expiry.enable",
+	"authentication_backend.file.password_expiry.max_age",
 	"authentication_backend.file.search.email",
 	"authentication_backend.file.search.case_insensitive",
 	"authentication_backend.ldap.implementation",
 	"authentication_backend.ldap.url",
+	"authentication_backend.ldap.additional_urls",
+	"authentication_backend.ldap.url_strategy",
 	"authentication_backend.ldap.timeout",
 	"authentication_backend.ldap.start_tls",
 	"authentication_backend.ldap.tls.minimum_version",
@@ -91,18 +107,29 @@ var Keys = []string{
 	"authentication_backend.ldap.tls.server_name",
 	"authentication_backend.ldap.tls.private_key",
 	"authentication_backend.ldap.tls.certificate_chain",
+	"authentication_backend.ldap.pooling.enable",
+	"authentication_backend.ldap.pooling.count",
+	"authentication_backend.ldap.pooling.idle_timeout",
+	"authentication_backend.ldap.pooling.health_check_interval",
+	"authentication_backend.ldap.pooling.timeout",
 	"authentication_backend.ldap.base_dn",
 	"authentication_backend.ldap.additional_users_dn",
 	"authentication_backend.ldap.users_filter",
 	"authentication_backend.ldap.additional_groups_dn",
 	"authentication_backend.ldap.groups_filter",
+	"authentication_backend.ldap.group_search_mode",
+	"authentication_backend.ldap.group_search_max_depth",
+	"authentication_backend.ldap.group_member_attribute",
 	"authentication_backend.ldap.group_name_attribute",
 	"authentication_backend.ldap.username_attribute",
 	"authentication_backend.ldap.mail_attribute",
 	"authentication_backend.ldap.display_name_attribute",
+	"authentication_backend.ldap.member_of_attribute",
 	"authentication_backend.ldap.permit_referrals",
 	"authentication_backend.ldap.permit_unauthenticated_bind",
 	"authentication_backend.ldap.permit_feature_detection_failure",
+	"authentication_backend.ldap.password_expiry.enable",
+	"authentication_backend.ldap.password_expiry.max_age",
 	"authentication_backend.ldap.user",
 	"authentication_backend.ldap.password",
 	"session.secret",
@@ -171,6 +198,18 @@ var Keys = []string{
 	"access_control.rules[].query[][].key",
 	"access_control.rules[].query[][].value",
 	"access_control.rules[].query",
+	"access_control.rules[].headers[][].operator",
+	"access_control.rules[].headers[][].key",
+	"access_control.rules[].headers[][].value",
+	"access_control.rules[].headers",
+	"access_control.rules[].max_authentication_age.first_factor",
+	"access_control.rules[].max_authentication_age.second_factor",
+	"access_control.external.address",
+	"access_control.external.timeout",
+	"access_control.external.cache_duration",
+	"access_control.external.cache_size",
+	"access_control.external.failure_policy",
+	"access_control.watch",
 	"ntp.address",
 	"ntp.version",
 	"ntp.max_desync",
@@ -210,6 +249,33 @@ var Keys = []string{
 	"storage.postgres.ssl.certificate",
 	"storage.postgres.ssl.key",
 	"storage.encryption_key",
+	"storage.encryption.decryption_keys",
+	"storage.encryption.rekey.disable",
+	"storage.encryption.rekey.batch_size",
+	"storage.encryption.rekey.interval",
+	"storage.encryption.key_provider.vault_transit.address",
+	"storage.encryption.key_provider.vault_transit.token",
+	"storage.encryption.key_provider.vault_transit.namespace",
+	"storage.encryption.key_provider.vault_transit.mount",
+	"storage.encryption.key_provider.vault_transit.key_name",
+	"storage.encryption.key_provider.vault_transit.timeout",
+	"storage.encryption.key_provider.vault_transit.tls.minimum_version",
+	"storage.encryption.key_provider.vault_transit.tls.maximum_version",
+	"storage.encryption.key_provider.vault_transit.tls.skip_verify",
+	"storage.encryption.key_provider.vault_transit.tls.server_name",
+	"storage.encryption.key_provider.vault_transit.tls.private_key",
+	"storage.encryption.key_provider.vault_transit.tls.certificate_chain",
+	"storage.encryption.key_provider.file.path",
+	"storage.encryption.key_provider.data_key_cache.lifetime",
+	"storage.encryption.key_provider.data_key_cache.size",
+	"storage.retention.enabled",
+	"storage.retention.interval",
+	"storage.retention.batch_size",
+	"storage.retention.authentication_logs",
+	"storage.retention.identity_verification",
+	"storage.retention.oauth2_sessions",
+	"storage.retention.oauth2_blacklisted_jti",
+	"storage.retention.oauth2_consent_preconfigurations",
 	"notifier.disable_startup_check",
 	"notifier.filesystem.filename",
 	"notifier.smtp.host",
@@ -230,6 +296,38 @@ var Keys = []string{
 	"notifier.smtp.tls.server_name",
 	"notifier.smtp.tls.private_key",
 	"notifier.smtp.tls.certificate_chain",
+	"notifier.smtp.dkim.domain",
+	"notifier.smtp.dkim.selector",
+	"notifier.smtp.dkim.private_key",
+	"notifier.smtp.dkim.headers",
+	"notifier.smtp.smime.private_key",
+	"notifier.smtp.smime.certificate_chain",
+	"notifier.webhooks",
+	"notifier.webhooks[].name",
+	"notifier.webhooks[].format",
+	"notifier.webhooks[].url",
+	"notifier.webhooks[].secret",
+	"notifier.webhooks[].access_token",
+	"notifier.webhooks[].room_id",
+	"notifier.webhooks[].timeout",
+	"notifier.webhooks[].templates",
+	"notifier.webhooks[].tls.minimum_version",
+	"notifier.webhooks[].tls.maximum_version",
+	"notifier.webhooks[].tls.skip_verify",
+	"notifier.webhooks[].tls.server_name",
+	"notifier.webhooks[].tls.private_key",
+	"notifier.webhooks[].tls.certificate_chain",
+	"notifier.outbox.enabled",
+	"notifier.outbox.interval",
+	"notifier.outbox.max_attempts",
+	"notifier.outbox.backoff",
+	"notifier.outbox.max_backoff",
+	"notifier.events.disable_password_changed",
+	"notifier.events.disable_webauthn_device_added",
+	"notifier.events.disable_totp_added",
+	"notifier.events.disable_totp_removed",
+	"notifier.events.disable_new_login",
+	"notifier.events.disable_user_banned",
 	"notifier.template_path",
 	"server.host",
 	"server.port",
@@ -272,6 +370,14 @@ var Keys = []string{
 	"password_policy.standard.require_special",
 	"password_policy.zxcvbn.enabled",
 	"password_policy.zxcvbn.min_score",
+	"password_policy.breached.enabled",
+	"password_policy.breached.mode",
+	"password_policy.breached.path",
+	"password_policy.breached.url",
+	"password_policy.breached.timeout",
+	"password_policy.breached.threshold",
+	"password_policy.history.enabled",
+	"password_policy.history.count",
 	"privacy_policy.enabled",
 	"privacy_policy.require_user_acceptance",
 	"privacy_policy.policy_url",
@@ -3,6 +3,7 @@ package schema
 import (
 	"crypto/tls"
 	"net/mail"
+	"net/url"
 	"time"
 )
 
@@ -26,6 +27,49 @@ type SMTPNotifierConfiguration struct {
 	DisableHTMLEmails   bool          `koanf:"disable_html_emails"`
 	DisableStartTLS     bool          `koanf:"disable_starttls"`
 	TLS                 *TLSConfig    `koanf:"tls"`
+
+	DKIM  *SMTPNotifierDKIMConfiguration  `koanf:"dkim"`
+	SMIME *SMTPNotifierSMIMEConfiguration `koanf:"smime"`
+}
+
+// SMTPNotifierDKIMConfiguration represents the configuration of the DKIM signature added to emails sent by the SMTP
+// notifier.
+type SMTPNotifierDKIMConfiguration struct {
+	Domain     string                  `koanf:"domain"`
+	Selector   string                  `koanf:"selector"`
+	PrivateKey CryptographicPrivateKey `koanf:"private_key"`
+	Headers    []string                `koanf:"headers"`
+}
+
+// SMTPNotifierSMIMEConfiguration represents the configuration of the S/MIME signature of emails sent by the SMTP
+// notifier.
+type SMTPNotifierSMIMEConfiguration struct {
+	PrivateKey       CryptographicPrivateKey `koanf:"private_key"`
+	CertificateChain X509CertificateChain    `koanf:"certificate_chain"`
+}
+
+// WebhookNotifierConfiguration represents the configuration of a notifier which sends notifications to a HTTP endpoint
+// in addition to the email notifier.
+type WebhookNotifierConfiguration struct {
+	Name        string        `koanf:"name"`
+	Format      string        `koanf:"format"`
+	URL         *url.URL      `koanf:"url"`
+	Secret      string        `koanf:"secret"`
+	AccessToken string        `koanf:"access_token"`
+	RoomID      string        `koanf:"room_id"`
+	Timeout     time.Duration `koanf:"timeout"`
+	Templates   []string      `koanf:"templates"`
+	TLS         *TLSConfig    `koanf:"tls"`
+}
+
+// NotifierOutboxConfiguration represents the configuration of the notification outbox which persists notifications
+// to the storage and delivers them in the background.
+type NotifierOutboxConfiguration struct {
+	Enabled     bool          `koanf:"enabled"`
+	Interval    time.Duration `koanf:"interval"`
+	MaxAttempts int           `koanf:"max_attempts"`
+	Backoff     time.Duration `koanf:"backoff"`
+	MaxBackoff  time.Duration `koanf:"max_backoff"`
 }
 
 // NotifierConfiguration represents the configuration of the notifier to use when sending notifications to users.
@@ -33,9 +77,23 @@ type NotifierConfiguration struct {
 	DisableStartupCheck bool                             `koanf:"disable_startup_check"`
 	FileSystem          *FileSystemNotifierConfiguration `koanf:"filesystem"`
 	SMTP                *SMTPNotifierConfiguration       `koanf:"smtp"`
+	Webhooks            []WebhookNotifierConfiguration   `koanf:"webhooks"`
+	Outbox              NotifierOutboxConfiguration      `koanf:"outbox"`
+	Events              NotifierEventsConfiguration      `koanf:"events"`
 	TemplatePath        string                           `koanf:"template_path"`
 }
 
+// NotifierEventsConfiguration represents the configuration of the security event notifications sent to users when
+// their account changes. Each event is enabled by default.
+type NotifierEventsConfiguration struct {
+	DisablePasswordChanged     bool `koanf:"disable_password_changed"`
+	DisableWebauthnDeviceAdded bool `koanf:"disable_webauthn_device_added"`
+	DisableTOTPAdded           bool `koanf:"disable_totp_added"`
+	DisableTOTPRemoved         bool `koanf:"disable_totp_removed"`
+	DisableNewLogin            bool `koanf:"disable_new_login"`
+	DisableUserBanned          bool `koanf:"disable_user_banned"`
+}
+
 // DefaultSMTPNotifierConfiguration represents default configuration parameters for the SMTP notifier.
 var DefaultSMTPNotifierConfiguration = SMTPNotifierConfiguration{
 	Timeout:             time.Second * 5,
@@ -46,3 +104,26 @@ var DefaultSMTPNotifierConfiguration = SMTPNotifierConfiguration{
 		MinimumVersion: TLSVersion{tls.VersionTLS12},
 	},
 }
+
+// DefaultSMTPNotifierDKIMConfiguration represents default configuration parameters for the SMTP notifier DKIM
+// signature.
+var DefaultSMTPNotifierDKIMConfiguration = SMTPNotifierDKIMConfiguration{
+	Headers: []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type"},
+}
+
+// DefaultWebhookNotifierConfiguration represents default configuration parameters for the webhook notifiers.
+var DefaultWebhookNotifierConfiguration = WebhookNotifierConfiguration{
+	Format:  WebhookNotifierFormatGeneric,
+	Timeout: time.Second * 5,
+	TLS: &TLSConfig{
+		MinimumVersion: TLSVersion{tls.VersionTLS12},
+	},
+}
+
+// DefaultNotifierOutboxConfiguration represents default configuration parameters for the notification outbox.
+var DefaultNotifierOutboxConfiguration = NotifierOutboxConfiguration{
+	Interval:    time.Minute,
+	MaxAttempts: 10,
+	Backoff:     time.Second * 30,
+	MaxBackoff:  time.Hour,
+}
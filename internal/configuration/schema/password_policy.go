@@ -1,5 +1,10 @@
 package schema
 
+import (
+	"net/url"
+	"time"
+)
+
 // PasswordPolicyStandardParams represents the configuration related to standard parameters of password policy.
 type PasswordPolicyStandardParams struct {
 	Enabled          bool `koanf:"enabled"`
@@ -17,10 +22,29 @@ type PasswordPolicyZXCVBNParams struct {
 	MinScore int  `koanf:"min_score"`
 }
 
+// PasswordPolicyBreachedParams represents the configuration related to checking passwords against known breached
+// passwords.
+type PasswordPolicyBreachedParams struct {
+	Enabled   bool          `koanf:"enabled"`
+	Mode      string        `koanf:"mode"`
+	Path      string        `koanf:"path"`
+	URL       *url.URL      `koanf:"url"`
+	Timeout   time.Duration `koanf:"timeout"`
+	Threshold int           `koanf:"threshold"`
+}
+
+// PasswordPolicyHistoryParams represents the configuration related to preventing the reuse of previous passwords.
+type PasswordPolicyHistoryParams struct {
+	Enabled bool `koanf:"enabled"`
+	Count   int  `koanf:"count"`
+}
+
 // PasswordPolicyConfiguration represents the configuration related to password policy.
 type PasswordPolicyConfiguration struct {
 	Standard PasswordPolicyStandardParams `koanf:"standard"`
 	ZXCVBN   PasswordPolicyZXCVBNParams   `koanf:"zxcvbn"`
+	Breached PasswordPolicyBreachedParams `koanf:"breached"`
+	History  PasswordPolicyHistoryParams  `koanf:"history"`
 }
 
 // DefaultPasswordPolicyConfiguration is the default password policy configuration.
@@ -34,4 +58,15 @@ var DefaultPasswordPolicyConfiguration = PasswordPolicyConfiguration{
 		Enabled:  false,
 		MinScore: 3,
 	},
+	Breached: PasswordPolicyBreachedParams{
+		Enabled:   false,
+		Mode:      PasswordPolicyBreachedModeDirectory,
+		URL:       &url.URL{Scheme: "https", Host: "api.pwnedpasswords.com"},
+		Timeout:   time.Second * 5,
+		Threshold: 1,
+	},
+	History: PasswordPolicyHistoryParams{
+		Enabled: false,
+		Count:   5,
+	},
 }
@@ -29,6 +29,17 @@ type ServerTimeouts struct {
 	Idle  time.Duration `koanf:"idle"`
 }
 
+// MaxAuthenticationAge represents the maximum age of each authentication factor before it must be performed again.
+type MaxAuthenticationAge struct {
+	FirstFactor  time.Duration `koanf:"first_factor"`
+	SecondFactor time.Duration `koanf:"second_factor"`
+}
+
+// IsZero returns true if neither of the maximum ages are configured.
+func (a MaxAuthenticationAge) IsZero() bool {
+	return a.FirstFactor == 0 && a.SecondFactor == 0
+}
+
 // ServerBuffers represents server buffer configurations.
 type ServerBuffers struct {
 	Read  int `koanf:"read"`
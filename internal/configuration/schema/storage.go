@@ -2,6 +2,7 @@ package schema
 
 import (
 	"crypto/tls"
+	"net/url"
 	"time"
 )
 
@@ -51,7 +52,105 @@ type StorageConfiguration struct {
 	MySQL      *MySQLStorageConfiguration      `koanf:"mysql"`
 	PostgreSQL *PostgreSQLStorageConfiguration `koanf:"postgres"`
 
-	EncryptionKey string `koanf:"encryption_key"`
+	EncryptionKey string                         `koanf:"encryption_key"`
+	Encryption    StorageEncryptionConfiguration `koanf:"encryption"`
+	Retention     StorageRetentionConfiguration  `koanf:"retention"`
+}
+
+// StorageRetentionConfiguration represents the configuration of the background pruning of storage data which is no
+// longer required. The pruning is disabled unless it's enabled as it permanently deletes data. A negative retention
+// disables pruning of that data type.
+type StorageRetentionConfiguration struct {
+	Enabled   bool          `koanf:"enabled"`
+	Interval  time.Duration `koanf:"interval"`
+	BatchSize int           `koanf:"batch_size"`
+
+	AuthenticationLogs            time.Duration `koanf:"authentication_logs"`
+	IdentityVerification          time.Duration `koanf:"identity_verification"`
+	OAuth2Sessions                time.Duration `koanf:"oauth2_sessions"`
+	OAuth2BlacklistedJTI          time.Duration `koanf:"oauth2_blacklisted_jti"`
+	OAuth2ConsentPreConfiguration time.Duration `koanf:"oauth2_consent_preconfigurations"`
+}
+
+// StorageEncryptionConfiguration represents the configuration of the storage encryption keyring.
+type StorageEncryptionConfiguration struct {
+	DecryptionKeys []string                                  `koanf:"decryption_keys"`
+	Rekey          StorageEncryptionRekeyConfiguration       `koanf:"rekey"`
+	KeyProvider    StorageEncryptionKeyProviderConfiguration `koanf:"key_provider"`
+}
+
+// StorageEncryptionRekeyConfiguration represents the configuration of the background re-encryption of values which
+// are not encrypted with the current encryption key.
+type StorageEncryptionRekeyConfiguration struct {
+	Disable   bool          `koanf:"disable"`
+	BatchSize int           `koanf:"batch_size"`
+	Interval  time.Duration `koanf:"interval"`
+}
+
+// StorageEncryptionKeyProviderConfiguration represents the configuration of the external provider of the keys which
+// wrap the data keys used to encrypt values.
+type StorageEncryptionKeyProviderConfiguration struct {
+	VaultTransit *StorageEncryptionVaultTransitConfiguration    `koanf:"vault_transit"`
+	File         *StorageEncryptionFileKeyProviderConfiguration `koanf:"file"`
+
+	DataKeyCache StorageEncryptionDataKeyCacheConfiguration `koanf:"data_key_cache"`
+}
+
+// StorageEncryptionVaultTransitConfiguration represents the configuration of the HashiCorp Vault Transit key provider.
+type StorageEncryptionVaultTransitConfiguration struct {
+	Address   *url.URL      `koanf:"address"`
+	Token     string        `koanf:"token"`
+	Namespace string        `koanf:"namespace"`
+	Mount     string        `koanf:"mount"`
+	KeyName   string        `koanf:"key_name"`
+	Timeout   time.Duration `koanf:"timeout"`
+	TLS       *TLSConfig    `koanf:"tls"`
+}
+
+// StorageEncryptionFileKeyProviderConfiguration represents the configuration of the local file key provider.
+type StorageEncryptionFileKeyProviderConfiguration struct {
+	Path string `koanf:"path"`
+}
+
+// StorageEncryptionDataKeyCacheConfiguration represents the configuration of the cache of data keys which avoids
+// a request to the key provider for every value which is encrypted or decrypted.
+type StorageEncryptionDataKeyCacheConfiguration struct {
+	Lifetime time.Duration `koanf:"lifetime"`
+	Size     int           `koanf:"size"`
+}
+
+// DefaultStorageEncryptionRekeyConfiguration represents the default storage encryption rekey configuration.
+var DefaultStorageEncryptionRekeyConfiguration = StorageEncryptionRekeyConfiguration{
+	BatchSize: 100,
+	Interval:  time.Second * 5,
+}
+
+// DefaultStorageEncryptionVaultTransitConfiguration represents the default storage encryption HashiCorp Vault Transit
+// key provider configuration.
+var DefaultStorageEncryptionVaultTransitConfiguration = StorageEncryptionVaultTransitConfiguration{
+	Mount:   "transit",
+	Timeout: time.Second * 5,
+	TLS: &TLSConfig{
+		MinimumVersion: TLSVersion{tls.VersionTLS12},
+	},
+}
+
+// DefaultStorageEncryptionDataKeyCacheConfiguration represents the default storage encryption data key cache
+// configuration.
+var DefaultStorageEncryptionDataKeyCacheConfiguration = StorageEncryptionDataKeyCacheConfiguration{
+	Lifetime: time.Minute * 5,
+	Size:     1000,
+}
+
+// DefaultStorageRetentionConfiguration represents the default storage retention configuration.
+var DefaultStorageRetentionConfiguration = StorageRetentionConfiguration{
+	Interval:                      time.Hour,
+	BatchSize:                     1000,
+	AuthenticationLogs:            time.Hour * 24 * 365,
+	IdentityVerification:          time.Hour * 24 * 7,
+	OAuth2Sessions:                time.Hour * 24 * 7,
+	OAuth2BlacklistedJTI:          time.Hour * 24,
+	OAuth2ConsentPreConfiguration: time.Hour * 24 * 7,
 }
 
 // DefaultSQLStorageConfiguration represents the default SQL configuration.
@@ -71,6 +71,43 @@ func ValidateAccessControl(config *schema.Configuration, validator *schema.Struc
 			}
 		}
 	}
+
+	validateAccessControlExternal(config, validator)
+}
+
+func validateAccessControlExternal(config *schema.Configuration, validator *schema.StructValidator) {
+	external := &config.AccessControl.External
+
+	if external.Address != nil && external.Address.Scheme != schemeHTTP && external.Address.Scheme != schemeHTTPS {
+		validator.Push(fmt.Errorf(errFmtAccessControlExternalAddressScheme, external.Address.Scheme))
+	}
+
+	switch {
+	case external.Timeout < 0:
+		validator.Push(fmt.Errorf(errFmtAccessControlExternalNegativeDuration, "timeout", external.Timeout))
+	case external.Timeout == 0:
+		external.Timeout = schema.DefaultACLExternal.Timeout
+	}
+
+	if external.CacheDuration < 0 {
+		validator.Push(fmt.Errorf(errFmtAccessControlExternalNegativeDuration, "cache_duration", external.CacheDuration))
+	}
+
+	switch {
+	case external.CacheSize < 0:
+		validator.Push(fmt.Errorf(errFmtAccessControlExternalNegativeCacheSize, external.CacheSize))
+	case external.CacheSize == 0:
+		external.CacheSize = schema.DefaultACLExternal.CacheSize
+	}
+
+	switch external.FailurePolicy {
+	case "":
+		external.FailurePolicy = schema.DefaultACLExternal.FailurePolicy
+	default:
+		if !IsPolicyValid(external.FailurePolicy) {
+			validator.Push(fmt.Errorf(errFmtAccessControlExternalFailurePolicy, strings.Join(validACLRulePolicies, "', '"), external.FailurePolicy))
+		}
+	}
 }
 
 // ValidateRules validates an ACL Rule configuration.
@@ -92,7 +129,12 @@ func ValidateRules(config *schema.Configuration, validator *schema.StructValidat
 
 		validateDomains(rulePosition, rule, validator)
 
-		if !IsPolicyValid(rule.Policy) {
+		switch {
+		case rule.Policy == policyExternal:
+			if config.AccessControl.External.Address == nil {
+				validator.Push(fmt.Errorf(errFmtAccessControlRuleExternalNoAddress, ruleDescriptor(rulePosition, rule)))
+			}
+		case !IsPolicyValid(rule.Policy):
 			validator.Push(fmt.Errorf(errFmtAccessControlRuleInvalidPolicy, ruleDescriptor(rulePosition, rule), rule.Policy))
 		}
 
@@ -102,7 +144,11 @@ func ValidateRules(config *schema.Configuration, validator *schema.StructValidat
 
 		validateMethods(rulePosition, rule, validator)
 
-		validateQuery(i, rule, config, validator)
+		validateQuery(rulePosition, rule, validator)
+
+		validateHeaders(rulePosition, rule, validator)
+
+		validateMaxAuthenticationAge(rulePosition, rule, validator)
 
 		if rule.Policy == policyBypass {
 			validateBypass(rulePosition, rule, validator)
@@ -163,43 +209,76 @@ func validateMethods(rulePosition int, rule schema.ACLRule, validator *schema.St
 	}
 }
 
+func validateMaxAuthenticationAge(rulePosition int, rule schema.ACLRule, validator *schema.StructValidator) {
+	if rule.MaxAuthenticationAge.IsZero() {
+		return
+	}
+
+	if rule.MaxAuthenticationAge.FirstFactor < 0 {
+		validator.Push(fmt.Errorf(errFmtAccessControlRuleMaxAuthenticationAgeNegative, ruleDescriptor(rulePosition, rule), "first_factor", rule.MaxAuthenticationAge.FirstFactor))
+	}
+
+	if rule.MaxAuthenticationAge.SecondFactor < 0 {
+		validator.Push(fmt.Errorf(errFmtAccessControlRuleMaxAuthenticationAgeNegative, ruleDescriptor(rulePosition, rule), "second_factor", rule.MaxAuthenticationAge.SecondFactor))
+	}
+
+	switch rule.Policy {
+	case policyOneFactor:
+		if rule.MaxAuthenticationAge.SecondFactor != 0 {
+			validator.Push(fmt.Errorf(errFmtAccessControlRuleMaxAuthenticationAgeSecondFactor, ruleDescriptor(rulePosition, rule), rule.Policy))
+		}
+	case policyTwoFactor:
+		break
+	default:
+		validator.Push(fmt.Errorf(errFmtAccessControlRuleMaxAuthenticationAgePolicy, ruleDescriptor(rulePosition, rule), rule.Policy))
+	}
+}
+
+func validateQuery(rulePosition int, rule schema.ACLRule, validator *schema.StructValidator) {
+	validateCriteria(rulePosition, rule, "query", rule.Query, validator)
+}
+
+func validateHeaders(rulePosition int, rule schema.ACLRule, validator *schema.StructValidator) {
+	validateCriteria(rulePosition, rule, "headers", rule.Headers, validator)
+}
+
 //nolint:gocyclo
-func validateQuery(i int, rule schema.ACLRule, config *schema.Configuration, validator *schema.StructValidator) {
-	for j := 0; j < len(config.AccessControl.Rules[i].Query); j++ {
-		for k := 0; k < len(config.AccessControl.Rules[i].Query[j]); k++ {
-			if config.AccessControl.Rules[i].Query[j][k].Operator == "" {
-				if config.AccessControl.Rules[i].Query[j][k].Key != "" {
-					switch config.AccessControl.Rules[i].Query[j][k].Value {
+func validateCriteria(rulePosition int, rule schema.ACLRule, option string, criteria [][]schema.ACLQueryRule, validator *schema.StructValidator) {
+	for j := 0; j < len(criteria); j++ {
+		for k := 0; k < len(criteria[j]); k++ {
+			if criteria[j][k].Operator == "" {
+				if criteria[j][k].Key != "" {
+					switch criteria[j][k].Value {
 					case "", nil:
-						config.AccessControl.Rules[i].Query[j][k].Operator = operatorPresent
+						criteria[j][k].Operator = operatorPresent
 					default:
-						config.AccessControl.Rules[i].Query[j][k].Operator = operatorEqual
+						criteria[j][k].Operator = operatorEqual
 					}
 				}
-			} else if !utils.IsStringInSliceFold(config.AccessControl.Rules[i].Query[j][k].Operator, validACLRuleOperators) {
-				validator.Push(fmt.Errorf(errFmtAccessControlRuleQueryInvalid, ruleDescriptor(i+1, rule), config.AccessControl.Rules[i].Query[j][k].Operator, strings.Join(validACLRuleOperators, "', '")))
+			} else if !utils.IsStringInSliceFold(criteria[j][k].Operator, validACLRuleOperators) {
+				validator.Push(fmt.Errorf(errFmtAccessControlRuleQueryInvalid, ruleDescriptor(rulePosition, rule), option, criteria[j][k].Operator, strings.Join(validACLRuleOperators, "', '")))
 			}
 
-			if config.AccessControl.Rules[i].Query[j][k].Key == "" {
-				validator.Push(fmt.Errorf(errFmtAccessControlRuleQueryInvalidNoValue, ruleDescriptor(i+1, rule), "key"))
+			if criteria[j][k].Key == "" {
+				validator.Push(fmt.Errorf(errFmtAccessControlRuleQueryInvalidNoValue, ruleDescriptor(rulePosition, rule), option, "key"))
 			}
 
-			op := config.AccessControl.Rules[i].Query[j][k].Operator
+			op := criteria[j][k].Operator
 
 			if op == "" {
 				continue
 			}
 
-			switch v := config.AccessControl.Rules[i].Query[j][k].Value.(type) {
+			switch v := criteria[j][k].Value.(type) {
 			case nil:
 				if op != operatorAbsent && op != operatorPresent {
-					validator.Push(fmt.Errorf(errFmtAccessControlRuleQueryInvalidNoValueOperator, ruleDescriptor(i+1, rule), "value", op))
+					validator.Push(fmt.Errorf(errFmtAccessControlRuleQueryInvalidNoValueOperator, ruleDescriptor(rulePosition, rule), option, "value", op))
 				}
 			case string:
 				switch op {
 				case operatorPresent, operatorAbsent:
 					if v != "" {
-						validator.Push(fmt.Errorf(errFmtAccessControlRuleQueryInvalidValue, ruleDescriptor(i+1, rule), "value", op))
+						validator.Push(fmt.Errorf(errFmtAccessControlRuleQueryInvalidValue, ruleDescriptor(rulePosition, rule), option, "value", op))
 					}
 				case operatorPattern, operatorNotPattern:
 					var (
@@ -208,13 +287,13 @@ func validateQuery(i int, rule schema.ACLRule, config *schema.Configuration, val
 					)
 
 					if pattern, err = regexp.Compile(v); err != nil {
-						validator.Push(fmt.Errorf(errFmtAccessControlRuleQueryInvalidValueParse, ruleDescriptor(i+1, rule), "value", err))
+						validator.Push(fmt.Errorf(errFmtAccessControlRuleQueryInvalidValueParse, ruleDescriptor(rulePosition, rule), option, "value", err))
 					} else {
-						config.AccessControl.Rules[i].Query[j][k].Value = pattern
+						criteria[j][k].Value = pattern
 					}
 				}
 			default:
-				validator.Push(fmt.Errorf(errFmtAccessControlRuleQueryInvalidValueType, ruleDescriptor(i+1, rule), v))
+				validator.Push(fmt.Errorf(errFmtAccessControlRuleQueryInvalidValueType, ruleDescriptor(rulePosition, rule), option, v))
 			}
 		}
 	}
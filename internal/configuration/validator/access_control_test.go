@@ -2,8 +2,10 @@ package validator
 
 import (
 	"fmt"
+	"net/url"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -142,9 +144,9 @@ func (suite *AccessControl) TestShouldRaiseErrorsWithEmptyRules() {
 	suite.Require().Len(suite.validator.Errors(), 4)
 
 	suite.Assert().EqualError(suite.validator.Errors()[0], "access control: rule #1: rule is invalid: must have the option 'domain' or 'domain_regex' configured")
-	suite.Assert().EqualError(suite.validator.Errors()[1], "access control: rule #1: rule 'policy' option '' is invalid: must be one of 'deny', 'two_factor', 'one_factor' or 'bypass'")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "access control: rule #1: rule 'policy' option '' is invalid: must be one of 'deny', 'two_factor', 'one_factor', 'bypass' or 'external'")
 	suite.Assert().EqualError(suite.validator.Errors()[2], "access control: rule #2: rule is invalid: must have the option 'domain' or 'domain_regex' configured")
-	suite.Assert().EqualError(suite.validator.Errors()[3], "access control: rule #2: rule 'policy' option 'wrong' is invalid: must be one of 'deny', 'two_factor', 'one_factor' or 'bypass'")
+	suite.Assert().EqualError(suite.validator.Errors()[3], "access control: rule #2: rule 'policy' option 'wrong' is invalid: must be one of 'deny', 'two_factor', 'one_factor', 'bypass' or 'external'")
 }
 
 func (suite *AccessControl) TestShouldRaiseErrorInvalidPolicy() {
@@ -160,7 +162,7 @@ func (suite *AccessControl) TestShouldRaiseErrorInvalidPolicy() {
 	suite.Assert().Len(suite.validator.Warnings(), 0)
 	suite.Require().Len(suite.validator.Errors(), 1)
 
-	suite.Assert().EqualError(suite.validator.Errors()[0], "access control: rule #1 (domain 'public.example.com'): rule 'policy' option 'invalid' is invalid: must be one of 'deny', 'two_factor', 'one_factor' or 'bypass'")
+	suite.Assert().EqualError(suite.validator.Errors()[0], "access control: rule #1 (domain 'public.example.com'): rule 'policy' option 'invalid' is invalid: must be one of 'deny', 'two_factor', 'one_factor', 'bypass' or 'external'")
 }
 
 func (suite *AccessControl) TestShouldRaiseErrorInvalidNetwork() {
@@ -376,6 +378,194 @@ func (suite *AccessControl) TestShouldErrorOnInvalidRulesQuery() {
 	suite.Assert().EqualError(suite.validator.Errors()[6], "access control: rule #9 (domain 'public.example.com'): 'query' option 'value' is invalid: expected type was string but got int")
 }
 
+func (suite *AccessControl) TestShouldRaiseErrorInvalidHeaderRules() {
+	domains := []string{"public.example.com"}
+	suite.config.AccessControl.Rules = []schema.ACLRule{
+		{
+			Domains: domains,
+			Policy:  "bypass",
+			Headers: [][]schema.ACLQueryRule{
+				{
+					{Operator: "equal", Key: "X-Tenant"},
+				},
+			},
+		},
+		{
+			Domains: domains,
+			Policy:  "bypass",
+			Headers: [][]schema.ACLQueryRule{
+				{
+					{Operator: "present"},
+				},
+			},
+		},
+		{
+			Domains: domains,
+			Policy:  "bypass",
+			Headers: [][]schema.ACLQueryRule{
+				{
+					{Operator: "not", Key: "X-Tenant", Value: "a"},
+				},
+			},
+		},
+		{
+			Domains: domains,
+			Policy:  "bypass",
+			Headers: [][]schema.ACLQueryRule{
+				{
+					{Operator: "pattern", Key: "User-Agent", Value: "(bad pattern"},
+				},
+			},
+		},
+	}
+
+	ValidateRules(suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Require().Len(suite.validator.Errors(), 4)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "access control: rule #1 (domain 'public.example.com'): 'headers' option 'value' is invalid: must have a value when the operator is 'equal'")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "access control: rule #2 (domain 'public.example.com'): 'headers' option 'key' is invalid: must have a value")
+	suite.Assert().EqualError(suite.validator.Errors()[2], "access control: rule #3 (domain 'public.example.com'): 'headers' option 'operator' with value 'not' is invalid: must be one of 'present', 'absent', 'equal', 'not equal', 'pattern', 'not pattern'")
+	suite.Assert().EqualError(suite.validator.Errors()[3], "access control: rule #4 (domain 'public.example.com'): 'headers' option 'value' is invalid: error parsing regexp: missing closing ): `(bad pattern`")
+}
+
+func (suite *AccessControl) TestShouldSetHeaderRuleDefaultsAndCompilePatterns() {
+	suite.config.AccessControl.Rules = []schema.ACLRule{
+		{
+			Domains: []string{"public.example.com"},
+			Policy:  "one_factor",
+			Headers: [][]schema.ACLQueryRule{
+				{
+					{Key: "X-Tenant", Value: "abc"},
+					{Key: "X-Present"},
+					{Operator: "pattern", Key: "User-Agent", Value: "^curl/.*$"},
+				},
+			},
+		},
+	}
+
+	ValidateRules(suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Assert().Len(suite.validator.Errors(), 0)
+
+	suite.Assert().Equal("equal", suite.config.AccessControl.Rules[0].Headers[0][0].Operator)
+	suite.Assert().Equal("present", suite.config.AccessControl.Rules[0].Headers[0][1].Operator)
+	suite.Assert().IsType(&regexp.Regexp{}, suite.config.AccessControl.Rules[0].Headers[0][2].Value)
+}
+
+func (suite *AccessControl) TestShouldRaiseErrorInvalidMaxAuthenticationAge() {
+	domains := []string{"public.example.com"}
+	suite.config.AccessControl.Rules = []schema.ACLRule{
+		{
+			Domains: domains,
+			Policy:  "two_factor",
+			MaxAuthenticationAge: schema.MaxAuthenticationAge{
+				FirstFactor:  time.Hour * 12,
+				SecondFactor: time.Hour,
+			},
+		},
+		{
+			Domains: domains,
+			Policy:  "two_factor",
+			MaxAuthenticationAge: schema.MaxAuthenticationAge{
+				FirstFactor: -time.Hour,
+			},
+		},
+		{
+			Domains: domains,
+			Policy:  "one_factor",
+			MaxAuthenticationAge: schema.MaxAuthenticationAge{
+				FirstFactor:  time.Hour,
+				SecondFactor: time.Hour,
+			},
+		},
+		{
+			Domains: domains,
+			Policy:  "bypass",
+			MaxAuthenticationAge: schema.MaxAuthenticationAge{
+				FirstFactor: time.Hour,
+			},
+		},
+	}
+
+	ValidateRules(suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Require().Len(suite.validator.Errors(), 3)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "access control: rule #2 (domain 'public.example.com'): 'max_authentication_age' option 'first_factor' is invalid: must be a positive duration but it is configured as '-1h0m0s'")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "access control: rule #3 (domain 'public.example.com'): 'max_authentication_age' option 'second_factor' is invalid: must only be configured when the 'policy' option is 'two_factor' but it is configured as 'one_factor'")
+	suite.Assert().EqualError(suite.validator.Errors()[2], "access control: rule #4 (domain 'public.example.com'): 'max_authentication_age' option is invalid: must only be configured when the 'policy' option is 'one_factor' or 'two_factor' but it is configured as 'bypass'")
+}
+
+func (suite *AccessControl) TestShouldSetExternalDefaults() {
+	ValidateAccessControl(suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Assert().Len(suite.validator.Errors(), 0)
+
+	suite.Assert().Nil(suite.config.AccessControl.External.Address)
+	suite.Assert().Equal(schema.DefaultACLExternal.Timeout, suite.config.AccessControl.External.Timeout)
+	suite.Assert().Equal(time.Duration(0), suite.config.AccessControl.External.CacheDuration)
+	suite.Assert().Equal(schema.DefaultACLExternal.CacheSize, suite.config.AccessControl.External.CacheSize)
+	suite.Assert().Equal(policyDeny, suite.config.AccessControl.External.FailurePolicy)
+}
+
+func (suite *AccessControl) TestShouldRaiseErrorInvalidExternal() {
+	suite.config.AccessControl.External = schema.ACLExternal{
+		Address:       &url.URL{Scheme: "tcp", Host: "pdp.example.com"},
+		Timeout:       -time.Second,
+		CacheDuration: -time.Minute,
+		CacheSize:     -1,
+		FailurePolicy: "allow",
+	}
+
+	ValidateAccessControl(suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Require().Len(suite.validator.Errors(), 5)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "access control: external: option 'address' must have the scheme 'http' or 'https' but it is configured as 'tcp'")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "access control: external: option 'timeout' must be a positive duration but it is configured as '-1s'")
+	suite.Assert().EqualError(suite.validator.Errors()[2], "access control: external: option 'cache_duration' must be a positive duration but it is configured as '-1m0s'")
+	suite.Assert().EqualError(suite.validator.Errors()[3], "access control: external: option 'cache_size' must be more than 0 but it is configured as '-1'")
+	suite.Assert().EqualError(suite.validator.Errors()[4], "access control: external: option 'failure_policy' must be one of 'bypass', 'one_factor', 'two_factor', 'deny' but it is configured as 'allow'")
+}
+
+func (suite *AccessControl) TestShouldValidateExternalPolicy() {
+	suite.config.AccessControl.Rules = []schema.ACLRule{
+		{
+			Domains: []string{"app.example.com"},
+			Policy:  policyExternal,
+		},
+	}
+
+	ValidateRules(suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Require().Len(suite.validator.Errors(), 1)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "access control: rule #1 (domain 'app.example.com'): 'policy' option 'external' is invalid: the 'access_control.external.address' option must be configured")
+
+	suite.SetupTest()
+
+	suite.config.AccessControl.External.Address = &url.URL{Scheme: "https", Host: "pdp.example.com", Path: "/v1/decision"}
+	suite.config.AccessControl.Rules = []schema.ACLRule{
+		{
+			Domains: []string{"app.example.com"},
+			Policy:  policyExternal,
+		},
+	}
+
+	ValidateAccessControl(suite.config, suite.validator)
+	ValidateRules(suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Assert().Len(suite.validator.Errors(), 0)
+}
+
 func TestAccessControl(t *testing.T) {
 	suite.Run(t, new(AccessControl))
 }
@@ -39,9 +39,11 @@ func ValidateAuthenticationBackend(config *schema.AuthenticationBackend, validat
 		}
 	}
 
-	if config.LDAP != nil && config.File != nil {
-		validator.Push(fmt.Errorf(errFmtAuthBackendMultipleConfigured))
-	}
+	validateAuthenticationBackendPasswordChange(&config.PasswordChange, validator)
+
+	validateAuthenticationBackendCache(&config.Cache, validator)
+
+	validateAuthenticationBackendChain(config, validator)
 
 	if config.File != nil {
 		validateFileAuthenticationBackend(config.File, validator)
@@ -52,6 +54,78 @@ func ValidateAuthenticationBackend(config *schema.AuthenticationBackend, validat
 	}
 }
 
+func validateAuthenticationBackendChain(config *schema.AuthenticationBackend, validator *schema.StructValidator) {
+	if len(config.Chain) == 0 {
+		if config.LDAP != nil && config.File != nil {
+			validator.Push(fmt.Errorf(errFmtAuthBackendMultipleConfigured))
+		}
+
+		return
+	}
+
+	configured := map[string]bool{
+		schema.AuthenticationBackendFile: config.File != nil,
+		schema.AuthenticationBackendLDAP: config.LDAP != nil,
+	}
+
+	seen := map[string]bool{}
+
+	for _, name := range config.Chain {
+		switch {
+		case !utils.IsStringInSlice(name, validAuthBackends):
+			validator.Push(fmt.Errorf(errFmtAuthBackendChainInvalid, name, strings.Join(validAuthBackends, "', '")))
+		case seen[name]:
+			validator.Push(fmt.Errorf(errFmtAuthBackendChainDuplicate, name))
+		case !configured[name]:
+			validator.Push(fmt.Errorf(errFmtAuthBackendChainNotConfigured, name, name))
+		}
+
+		seen[name] = true
+	}
+
+	for _, name := range validAuthBackends {
+		if configured[name] && !seen[name] {
+			validator.Push(fmt.Errorf(errFmtAuthBackendChainMissing, name, name))
+		}
+	}
+}
+
+func validateAuthenticationBackendPasswordChange(config *schema.PasswordChangeAuthenticationBackend, validator *schema.StructValidator) {
+	switch {
+	case config.SecondFactorMaxAge < 0:
+		validator.Push(fmt.Errorf(errFmtAuthBackendPasswordChangeSecondFactorMaxAgeNegative, config.SecondFactorMaxAge))
+	case config.SecondFactorMaxAge == 0:
+		config.SecondFactorMaxAge = schema.DefaultPasswordChangeAuthenticationBackend.SecondFactorMaxAge
+	}
+}
+
+func validateAuthenticationBackendCache(config *schema.AuthenticationBackendCache, validator *schema.StructValidator) {
+	if !config.Enable {
+		return
+	}
+
+	switch {
+	case config.Lifespan < 0:
+		validator.Push(fmt.Errorf(errFmtAuthBackendCacheNegative, "lifespan", config.Lifespan))
+	case config.Lifespan == 0:
+		config.Lifespan = schema.DefaultAuthenticationBackendCache.Lifespan
+	}
+
+	switch {
+	case config.NegativeLifespan < 0:
+		validator.Push(fmt.Errorf(errFmtAuthBackendCacheNegative, "negative_lifespan", config.NegativeLifespan))
+	case config.NegativeLifespan == 0:
+		config.NegativeLifespan = schema.DefaultAuthenticationBackendCache.NegativeLifespan
+	}
+
+	switch {
+	case config.BackgroundRefresh < 0:
+		validator.Push(fmt.Errorf(errFmtAuthBackendCacheNegative, "background_refresh", config.BackgroundRefresh))
+	case config.BackgroundRefresh != 0 && config.Lifespan > 0 && config.BackgroundRefresh >= config.Lifespan:
+		validator.Push(fmt.Errorf(errFmtAuthBackendCacheBackgroundRefresh, config.Lifespan, config.BackgroundRefresh))
+	}
+}
+
 // validateFileAuthenticationBackend validates and updates the file authentication backend configuration.
 func validateFileAuthenticationBackend(config *schema.FileAuthenticationBackend, validator *schema.StructValidator) {
 	if config.Path == "" {
@@ -59,6 +133,14 @@ func validateFileAuthenticationBackend(config *schema.FileAuthenticationBackend,
 	}
 
 	ValidatePasswordConfiguration(&config.Password, validator)
+
+	validateAuthenticationBackendPasswordExpiry(schema.AuthenticationBackendFile, &config.PasswordExpiry, validator)
+}
+
+func validateAuthenticationBackendPasswordExpiry(backend string, config *schema.AuthenticationBackendPasswordExpiry, validator *schema.StructValidator) {
+	if config.MaxAge < 0 {
+		validator.Push(fmt.Errorf(errFmtAuthBackendPasswordExpiryMaxAgeNegative, backend, config.MaxAge))
+	}
 }
 
 // ValidatePasswordConfiguration validates the file auth backend password configuration.
@@ -329,6 +411,10 @@ func validateLDAPAuthenticationBackend(config *schema.AuthenticationBackend, val
 		defaultTLS.ServerName = validateLDAPAuthenticationBackendURL(config.LDAP, validator)
 	}
 
+	validateLDAPAuthenticationBackendPooling(config.LDAP, validator)
+	validateLDAPAuthenticationBackendGroupSearch(config.LDAP, validator)
+	validateAuthenticationBackendPasswordExpiry(schema.AuthenticationBackendLDAP, &config.LDAP.PasswordExpiry, validator)
+
 	if config.LDAP.TLS == nil {
 		config.LDAP.TLS = &schema.TLSConfig{}
 	}
@@ -379,6 +465,10 @@ func validateLDAPAuthenticationBackendImplementation(config *schema.Authenticati
 			config.LDAP.Timeout = implementation.Timeout
 		}
 
+		if config.LDAP.GroupSearchMaxDepth == 0 {
+			config.LDAP.GroupSearchMaxDepth = implementation.GroupSearchMaxDepth
+		}
+
 		tlsconfig = &schema.TLSConfig{
 			MinimumVersion: implementation.TLS.MinimumVersion,
 			MaximumVersion: implementation.TLS.MaximumVersion,
@@ -426,29 +516,107 @@ func setDefaultImplementationLDAPAuthenticationBackendProfileAttributes(config *
 	if ldapImplementationShouldSetStr(config.GroupNameAttribute, implementation.GroupNameAttribute) {
 		config.GroupNameAttribute = implementation.GroupNameAttribute
 	}
+
+	if ldapImplementationShouldSetStr(config.GroupMemberAttribute, implementation.GroupMemberAttribute) {
+		config.GroupMemberAttribute = implementation.GroupMemberAttribute
+	}
+
+	if ldapImplementationShouldSetStr(config.MemberOfAttribute, implementation.MemberOfAttribute) {
+		config.MemberOfAttribute = implementation.MemberOfAttribute
+	}
 }
 
 func validateLDAPAuthenticationBackendURL(config *schema.LDAPAuthenticationBackend, validator *schema.StructValidator) (hostname string) {
+	var ok bool
+
+	if config.URL, hostname, ok = validateLDAPURL("url", config.URL, validator); !ok {
+		return ""
+	}
+
+	for i, additional := range config.AdditionalURLs {
+		config.AdditionalURLs[i], _, _ = validateLDAPURL(fmt.Sprintf("additional_urls[%d]", i), additional, validator)
+	}
+
+	return hostname
+}
+
+func validateLDAPURL(option, value string, validator *schema.StructValidator) (normalized, hostname string, ok bool) {
 	var (
 		parsedURL *url.URL
 		err       error
 	)
 
-	if parsedURL, err = url.Parse(config.URL); err != nil {
-		validator.Push(fmt.Errorf(errFmtLDAPAuthBackendURLNotParsable, err))
+	if parsedURL, err = url.Parse(value); err != nil {
+		validator.Push(fmt.Errorf(errFmtLDAPAuthBackendURLNotParsable, option, err))
 
-		return
+		return value, "", false
 	}
 
 	if parsedURL.Scheme != schemeLDAP && parsedURL.Scheme != schemeLDAPS {
-		validator.Push(fmt.Errorf(errFmtLDAPAuthBackendURLInvalidScheme, parsedURL.Scheme))
+		validator.Push(fmt.Errorf(errFmtLDAPAuthBackendURLInvalidScheme, option, parsedURL.Scheme))
+
+		return value, "", false
+	}
+
+	return parsedURL.String(), parsedURL.Hostname(), true
+}
+
+func validateLDAPAuthenticationBackendPooling(config *schema.LDAPAuthenticationBackend, validator *schema.StructValidator) {
+	switch config.URLStrategy {
+	case "":
+		config.URLStrategy = schema.LDAPURLStrategyFailover
+	default:
+		if !utils.IsStringInSlice(config.URLStrategy, validLDAPURLStrategies) {
+			validator.Push(fmt.Errorf(errFmtLDAPAuthBackendURLStrategy, config.URLStrategy, strings.Join(validLDAPURLStrategies, "', '")))
+		}
+	}
 
+	if !config.Pooling.Enable {
 		return
 	}
 
-	config.URL = parsedURL.String()
+	switch {
+	case config.Pooling.Count < 0:
+		validator.Push(fmt.Errorf(errFmtLDAPAuthBackendPoolingNegative, "count", config.Pooling.Count))
+	case config.Pooling.Count == 0:
+		config.Pooling.Count = schema.DefaultLDAPAuthenticationBackendPooling.Count
+	}
+
+	switch {
+	case config.Pooling.IdleTimeout < 0:
+		validator.Push(fmt.Errorf(errFmtLDAPAuthBackendPoolingNegative, "idle_timeout", config.Pooling.IdleTimeout))
+	case config.Pooling.IdleTimeout == 0:
+		config.Pooling.IdleTimeout = schema.DefaultLDAPAuthenticationBackendPooling.IdleTimeout
+	}
+
+	switch {
+	case config.Pooling.HealthCheckInterval < 0:
+		validator.Push(fmt.Errorf(errFmtLDAPAuthBackendPoolingNegative, "health_check_interval", config.Pooling.HealthCheckInterval))
+	case config.Pooling.HealthCheckInterval == 0:
+		config.Pooling.HealthCheckInterval = schema.DefaultLDAPAuthenticationBackendPooling.HealthCheckInterval
+	}
+
+	switch {
+	case config.Pooling.Timeout < 0:
+		validator.Push(fmt.Errorf(errFmtLDAPAuthBackendPoolingNegative, "timeout", config.Pooling.Timeout))
+	case config.Pooling.Timeout == 0:
+		config.Pooling.Timeout = schema.DefaultLDAPAuthenticationBackendPooling.Timeout
+	}
+}
+
+func validateLDAPAuthenticationBackendGroupSearch(config *schema.LDAPAuthenticationBackend, validator *schema.StructValidator) {
+	switch config.GroupSearchMode {
+	case "":
+		config.GroupSearchMode = schema.LDAPGroupSearchModeFilter
+	default:
+		if !utils.IsStringInSlice(config.GroupSearchMode, validLDAPGroupSearchModes) {
+			validator.Push(fmt.Errorf(errFmtLDAPAuthBackendGroupSearchMode, config.GroupSearchMode, strings.Join(validLDAPGroupSearchModes, "', '")))
+		}
+	}
 
-	return parsedURL.Hostname()
+	if config.GroupSearchMaxDepth < 0 {
+		validator.Push(fmt.Errorf(errFmtLDAPAuthBackendGroupSearchMaxDepth, config.GroupSearchMaxDepth))
+	}
 }
 
 func validateLDAPRequiredParameters(config *schema.AuthenticationBackend, validator *schema.StructValidator) {
@@ -460,6 +628,10 @@ func validateLDAPRequiredParameters(config *schema.AuthenticationBackend, valida
 		if !config.PasswordReset.Disable {
 			validator.Push(fmt.Errorf(errFmtLDAPAuthBackendUnauthenticatedBindWithResetEnabled))
 		}
+
+		if !config.PasswordChange.Disable {
+			validator.Push(fmt.Errorf(errFmtLDAPAuthBackendUnauthenticatedBindWithChangeEnabled))
+		}
 	} else {
 		if config.LDAP.User == "" {
 			validator.Push(fmt.Errorf(errFmtLDAPAuthBackendMissingOption, "user"))
@@ -491,9 +663,12 @@ func validateLDAPRequiredParameters(config *schema.AuthenticationBackend, valida
 		}
 	}
 
-	if config.LDAP.GroupsFilter == "" {
+	switch {
+	case config.LDAP.GroupSearchMode == schema.LDAPGroupSearchModeInChain, config.LDAP.GroupSearchMode == schema.LDAPGroupSearchModeMemberOf:
+		// The groups filter is not used by these group search modes.
+	case config.LDAP.GroupsFilter == "":
 		validator.Push(fmt.Errorf(errFmtLDAPAuthBackendMissingOption, "groups_filter"))
-	} else if !strings.HasPrefix(config.LDAP.GroupsFilter, "(") || !strings.HasSuffix(config.LDAP.GroupsFilter, ")") {
+	case !strings.HasPrefix(config.LDAP.GroupsFilter, "(") || !strings.HasSuffix(config.LDAP.GroupsFilter, ")"):
 		validator.Push(fmt.Errorf(errFmtLDAPAuthBackendFilterEnclosingParenthesis, "groups_filter", config.LDAP.GroupsFilter, config.LDAP.GroupsFilter))
 	}
 }
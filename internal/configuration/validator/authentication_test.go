@@ -44,6 +44,247 @@ func TestShouldRaiseErrorWhenNoBackendProvided(t *testing.T) {
 	assert.EqualError(t, validator.Errors()[0], "authentication_backend: you must ensure either the 'file' or 'ldap' authentication backend is configured")
 }
 
+func TestShouldValidateAuthenticationBackendCache(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     schema.AuthenticationBackendCache
+		expected schema.AuthenticationBackendCache
+		errs     []string
+	}{
+		{
+			"ShouldNotSetDefaultsWhenDisabled",
+			schema.AuthenticationBackendCache{},
+			schema.AuthenticationBackendCache{},
+			nil,
+		},
+		{
+			"ShouldSetDefaults",
+			schema.AuthenticationBackendCache{Enable: true},
+			schema.AuthenticationBackendCache{Enable: true, Lifespan: time.Minute * 5, NegativeLifespan: time.Second * 30},
+			nil,
+		},
+		{
+			"ShouldAllowBackgroundRefresh",
+			schema.AuthenticationBackendCache{Enable: true, Lifespan: time.Minute, NegativeLifespan: time.Second, BackgroundRefresh: time.Second * 30},
+			schema.AuthenticationBackendCache{Enable: true, Lifespan: time.Minute, NegativeLifespan: time.Second, BackgroundRefresh: time.Second * 30},
+			nil,
+		},
+		{
+			"ShouldRaiseErrorOnNegative",
+			schema.AuthenticationBackendCache{Enable: true, Lifespan: -time.Second, NegativeLifespan: -time.Second, BackgroundRefresh: -time.Second},
+			schema.AuthenticationBackendCache{Enable: true, Lifespan: -time.Second, NegativeLifespan: -time.Second, BackgroundRefresh: -time.Second},
+			[]string{
+				"authentication_backend: cache: option 'lifespan' must not be negative but it is configured as '-1s'",
+				"authentication_backend: cache: option 'negative_lifespan' must not be negative but it is configured as '-1s'",
+				"authentication_backend: cache: option 'background_refresh' must not be negative but it is configured as '-1s'",
+			},
+		},
+		{
+			"ShouldRaiseErrorOnBackgroundRefreshNotLessThanLifespan",
+			schema.AuthenticationBackendCache{Enable: true, BackgroundRefresh: time.Minute * 5},
+			schema.AuthenticationBackendCache{Enable: true, Lifespan: time.Minute * 5, NegativeLifespan: time.Second * 30, BackgroundRefresh: time.Minute * 5},
+			[]string{
+				"authentication_backend: cache: option 'background_refresh' must be less than the 'lifespan' of '5m0s' but it is configured as '5m0s'",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			validator := schema.NewStructValidator()
+			config := schema.AuthenticationBackend{
+				Cache: tc.have,
+				File:  &schema.FileAuthenticationBackend{Path: "/a/path", Password: schema.DefaultPasswordConfig},
+			}
+
+			ValidateAuthenticationBackend(&config, validator)
+
+			assert.Equal(t, tc.expected, config.Cache)
+			assert.Len(t, validator.Warnings(), 0)
+			require.Len(t, validator.Errors(), len(tc.errs))
+
+			for i, err := range tc.errs {
+				assert.EqualError(t, validator.Errors()[i], err)
+			}
+		})
+	}
+}
+
+func TestShouldValidateAuthenticationBackendPasswordChange(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     schema.PasswordChangeAuthenticationBackend
+		expected time.Duration
+		errs     []string
+	}{
+		{
+			"ShouldSetDefaultSecondFactorMaxAge",
+			schema.PasswordChangeAuthenticationBackend{},
+			time.Minute * 5,
+			nil,
+		},
+		{
+			"ShouldAllowSecondFactorMaxAge",
+			schema.PasswordChangeAuthenticationBackend{RequireSecondFactor: true, SecondFactorMaxAge: time.Minute},
+			time.Minute,
+			nil,
+		},
+		{
+			"ShouldRaiseErrorOnNegativeSecondFactorMaxAge",
+			schema.PasswordChangeAuthenticationBackend{RequireSecondFactor: true, SecondFactorMaxAge: -time.Second},
+			-time.Second,
+			[]string{
+				"authentication_backend: password_change: option 'second_factor_max_age' must not be negative but it is configured as '-1s'",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			validator := schema.NewStructValidator()
+			config := schema.AuthenticationBackend{
+				PasswordChange: tc.have,
+				File:           &schema.FileAuthenticationBackend{Path: "/a/path", Password: schema.DefaultPasswordConfig},
+			}
+
+			ValidateAuthenticationBackend(&config, validator)
+
+			assert.Len(t, validator.Warnings(), 0)
+			require.Len(t, validator.Errors(), len(tc.errs))
+
+			for i, err := range tc.errs {
+				assert.EqualError(t, validator.Errors()[i], err)
+			}
+
+			assert.Equal(t, tc.expected, config.PasswordChange.SecondFactorMaxAge)
+		})
+	}
+}
+
+func TestShouldValidateAuthenticationBackendPasswordExpiry(t *testing.T) {
+	testCases := []struct {
+		name string
+		have schema.AuthenticationBackendPasswordExpiry
+		errs []string
+	}{
+		{
+			"ShouldAllowDisabled",
+			schema.AuthenticationBackendPasswordExpiry{},
+			nil,
+		},
+		{
+			"ShouldAllowMaxAge",
+			schema.AuthenticationBackendPasswordExpiry{Enable: true, MaxAge: time.Hour * 24 * 90},
+			nil,
+		},
+		{
+			"ShouldRaiseErrorOnNegativeMaxAge",
+			schema.AuthenticationBackendPasswordExpiry{Enable: true, MaxAge: -time.Second},
+			[]string{
+				"authentication_backend: file: password_expiry: option 'max_age' must not be negative but it is configured as '-1s'",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			validator := schema.NewStructValidator()
+			config := schema.AuthenticationBackend{
+				File: &schema.FileAuthenticationBackend{Path: "/a/path", Password: schema.DefaultPasswordConfig, PasswordExpiry: tc.have},
+			}
+
+			ValidateAuthenticationBackend(&config, validator)
+
+			assert.Len(t, validator.Warnings(), 0)
+			require.Len(t, validator.Errors(), len(tc.errs))
+
+			for i, err := range tc.errs {
+				assert.EqualError(t, validator.Errors()[i], err)
+			}
+		})
+	}
+}
+
+func TestShouldValidateAuthenticationBackendChain(t *testing.T) {
+	testCases := []struct {
+		name       string
+		file, ldap bool
+		chain      []string
+		errs       []string
+	}{
+		{
+			"ShouldAllowSingleBackendWithoutChain",
+			true, false,
+			nil,
+			nil,
+		},
+		{
+			"ShouldAllowBothBackendsWithChain",
+			true, true,
+			[]string{"ldap", "file"},
+			nil,
+		},
+		{
+			"ShouldAllowSingleBackendWithChain",
+			false, true,
+			[]string{"ldap"},
+			nil,
+		},
+		{
+			"ShouldRaiseErrorBothBackendsWithoutChain",
+			true, true,
+			nil,
+			[]string{
+				"authentication_backend: please ensure only one of the 'file' or 'ldap' backend is configured",
+			},
+		},
+		{
+			"ShouldRaiseErrorInvalidAndDuplicateValues",
+			true, true,
+			[]string{"file", "db", "ldap", "file"},
+			[]string{
+				"authentication_backend: option 'chain' contains the value 'db' but it must only contain the following values: 'file', 'ldap'",
+				"authentication_backend: option 'chain' contains the value 'file' more than once",
+			},
+		},
+		{
+			"ShouldRaiseErrorNotConfiguredAndMissing",
+			true, false,
+			[]string{"ldap"},
+			[]string{
+				"authentication_backend: option 'chain' contains the value 'ldap' but the 'ldap' backend is not configured",
+				"authentication_backend: option 'chain' must contain the value 'file' when the 'file' backend is configured",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			validator := schema.NewStructValidator()
+			config := schema.AuthenticationBackend{
+				Chain: tc.chain,
+			}
+
+			if tc.file {
+				config.File = &schema.FileAuthenticationBackend{Path: "/a/path"}
+			}
+
+			if tc.ldap {
+				config.LDAP = &schema.LDAPAuthenticationBackend{}
+			}
+
+			validateAuthenticationBackendChain(&config, validator)
+
+			assert.Len(t, validator.Warnings(), 0)
+			require.Len(t, validator.Errors(), len(tc.errs))
+
+			for i, err := range tc.errs {
+				assert.EqualError(t, validator.Errors()[i], err)
+			}
+		})
+	}
+}
+
 type FileBasedAuthenticationBackend struct {
 	suite.Suite
 	config    schema.AuthenticationBackend
@@ -647,6 +888,7 @@ func (suite *LDAPAuthenticationBackendSuite) TestShouldRaiseErrorWhenPasswordNot
 func (suite *LDAPAuthenticationBackendSuite) TestShouldNotRaiseErrorWhenPasswordNotProvidedWithPermitUnauthenticatedBind() {
 	suite.config.LDAP.Password = ""
 	suite.config.LDAP.PermitUnauthenticatedBind = true
+	suite.config.PasswordChange.Disable = true
 
 	ValidateAuthenticationBackend(&suite.config, suite.validator)
 
@@ -656,10 +898,24 @@ func (suite *LDAPAuthenticationBackendSuite) TestShouldNotRaiseErrorWhenPassword
 	suite.Assert().EqualError(suite.validator.Errors()[0], "authentication_backend: ldap: option 'permit_unauthenticated_bind' can't be enabled when password reset is enabled")
 }
 
+func (suite *LDAPAuthenticationBackendSuite) TestShouldRaiseErrorWhenPasswordChangeEnabledWithPermitUnauthenticatedBind() {
+	suite.config.LDAP.Password = ""
+	suite.config.LDAP.PermitUnauthenticatedBind = true
+	suite.config.PasswordReset.Disable = true
+
+	ValidateAuthenticationBackend(&suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Require().Len(suite.validator.Errors(), 1)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "authentication_backend: ldap: option 'permit_unauthenticated_bind' can't be enabled when password change is enabled")
+}
+
 func (suite *LDAPAuthenticationBackendSuite) TestShouldRaiseErrorWhenPasswordProvidedWithPermitUnauthenticatedBind() {
 	suite.config.LDAP.Password = "test"
 	suite.config.LDAP.PermitUnauthenticatedBind = true
 	suite.config.PasswordReset.Disable = true
+	suite.config.PasswordChange.Disable = true
 
 	ValidateAuthenticationBackend(&suite.config, suite.validator)
 
@@ -673,6 +929,7 @@ func (suite *LDAPAuthenticationBackendSuite) TestShouldNotRaiseErrorWhenPermitUn
 	suite.config.LDAP.Password = ""
 	suite.config.LDAP.PermitUnauthenticatedBind = true
 	suite.config.PasswordReset.Disable = true
+	suite.config.PasswordChange.Disable = true
 
 	ValidateAuthenticationBackend(&suite.config, suite.validator)
 
@@ -875,6 +1132,130 @@ func (suite *LDAPAuthenticationBackendSuite) TestShouldNotAllowTLSVerMinGreaterT
 	suite.Assert().EqualError(suite.validator.Errors()[0], "authentication_backend: ldap: tls: option combination of 'minimum_version' and 'maximum_version' is invalid: minimum version TLS1.3 is greater than the maximum version TLS1.2")
 }
 
+func (suite *LDAPAuthenticationBackendSuite) TestShouldSetDefaultURLStrategy() {
+	ValidateAuthenticationBackend(&suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Assert().Len(suite.validator.Errors(), 0)
+
+	suite.Assert().Equal(schema.LDAPURLStrategyFailover, suite.config.LDAP.URLStrategy)
+	suite.Assert().Equal(schema.LDAPAuthenticationBackendPooling{}, suite.config.LDAP.Pooling)
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldValidateAdditionalURLs() {
+	suite.config.LDAP.AdditionalURLs = []string{"ldaps://ldap2.example.com", "http://ldap3.example.com"}
+	suite.config.LDAP.URLStrategy = schema.LDAPURLStrategyRoundRobin
+
+	ValidateAuthenticationBackend(&suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Require().Len(suite.validator.Errors(), 1)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "authentication_backend: ldap: option 'additional_urls[1]' must have either the 'ldap' or 'ldaps' scheme but it is configured as 'http'")
+	suite.Assert().Equal(schema.LDAPURLStrategyRoundRobin, suite.config.LDAP.URLStrategy)
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldRaiseErrorOnInvalidURLStrategy() {
+	suite.config.LDAP.URLStrategy = "random"
+
+	ValidateAuthenticationBackend(&suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Require().Len(suite.validator.Errors(), 1)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "authentication_backend: ldap: option 'url_strategy' is configured as 'random' but must be one of the following values: 'failover', 'round-robin'")
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldSetDefaultPooling() {
+	suite.config.LDAP.Pooling.Enable = true
+
+	ValidateAuthenticationBackend(&suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Assert().Len(suite.validator.Errors(), 0)
+
+	suite.Assert().Equal(schema.DefaultLDAPAuthenticationBackendPooling.Count, suite.config.LDAP.Pooling.Count)
+	suite.Assert().Equal(schema.DefaultLDAPAuthenticationBackendPooling.IdleTimeout, suite.config.LDAP.Pooling.IdleTimeout)
+	suite.Assert().Equal(schema.DefaultLDAPAuthenticationBackendPooling.HealthCheckInterval, suite.config.LDAP.Pooling.HealthCheckInterval)
+	suite.Assert().Equal(schema.DefaultLDAPAuthenticationBackendPooling.Timeout, suite.config.LDAP.Pooling.Timeout)
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldRaiseErrorOnNegativePooling() {
+	suite.config.LDAP.Pooling = schema.LDAPAuthenticationBackendPooling{
+		Enable:              true,
+		Count:               -1,
+		IdleTimeout:         -time.Second,
+		HealthCheckInterval: -time.Second,
+		Timeout:             -time.Second,
+	}
+
+	ValidateAuthenticationBackend(&suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Require().Len(suite.validator.Errors(), 4)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "authentication_backend: ldap: pooling: option 'count' must not be negative but it is configured as '-1'")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "authentication_backend: ldap: pooling: option 'idle_timeout' must not be negative but it is configured as '-1s'")
+	suite.Assert().EqualError(suite.validator.Errors()[2], "authentication_backend: ldap: pooling: option 'health_check_interval' must not be negative but it is configured as '-1s'")
+	suite.Assert().EqualError(suite.validator.Errors()[3], "authentication_backend: ldap: pooling: option 'timeout' must not be negative but it is configured as '-1s'")
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldSetDefaultGroupSearch() {
+	ValidateAuthenticationBackend(&suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Assert().Len(suite.validator.Errors(), 0)
+
+	suite.Assert().Equal(schema.LDAPGroupSearchModeFilter, suite.config.LDAP.GroupSearchMode)
+	suite.Assert().Equal(schema.DefaultLDAPAuthenticationBackendConfigurationImplementationCustom.GroupSearchMaxDepth, suite.config.LDAP.GroupSearchMaxDepth)
+	suite.Assert().Equal(schema.DefaultLDAPAuthenticationBackendConfigurationImplementationCustom.GroupMemberAttribute, suite.config.LDAP.GroupMemberAttribute)
+	suite.Assert().Equal(schema.DefaultLDAPAuthenticationBackendConfigurationImplementationCustom.MemberOfAttribute, suite.config.LDAP.MemberOfAttribute)
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldRaiseErrorOnInvalidGroupSearch() {
+	suite.config.LDAP.GroupSearchMode = "nested"
+	suite.config.LDAP.GroupSearchMaxDepth = -1
+
+	ValidateAuthenticationBackend(&suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Require().Len(suite.validator.Errors(), 2)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "authentication_backend: ldap: option 'group_search_mode' is configured as 'nested' but must be one of the following values: 'filter', 'in-chain', 'recursive', 'memberof'")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "authentication_backend: ldap: option 'group_search_max_depth' must not be negative but it is configured as '-1'")
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldNotRequireGroupsFilterWhenNotUsed() {
+	testCases := []struct {
+		name     string
+		mode     string
+		expected []string
+	}{
+		{"ShouldRequireFilter", schema.LDAPGroupSearchModeFilter, []string{"authentication_backend: ldap: option 'groups_filter' is required"}},
+		{"ShouldRequireRecursive", schema.LDAPGroupSearchModeRecursive, []string{"authentication_backend: ldap: option 'groups_filter' is required"}},
+		{"ShouldNotRequireInChain", schema.LDAPGroupSearchModeInChain, nil},
+		{"ShouldNotRequireMemberOf", schema.LDAPGroupSearchModeMemberOf, nil},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.SetupTest()
+
+			suite.config.LDAP.GroupsFilter = ""
+			suite.config.LDAP.GroupSearchMode = tc.mode
+
+			ValidateAuthenticationBackend(&suite.config, suite.validator)
+
+			suite.Assert().Len(suite.validator.Warnings(), 0)
+			suite.Require().Len(suite.validator.Errors(), len(tc.expected))
+
+			for i, expected := range tc.expected {
+				suite.Assert().EqualError(suite.validator.Errors()[i], expected)
+			}
+		})
+	}
+}
+
 func TestLDAPAuthenticationBackend(t *testing.T) {
 	suite.Run(t, new(LDAPAuthenticationBackendSuite))
 }
@@ -59,7 +59,7 @@ func ValidateConfiguration(config *schema.Configuration, validator *schema.Struc
 
 	ValidateTelemetry(config, validator)
 
-	ValidateStorage(config.Storage, validator)
+	ValidateStorage(&config.Storage, validator)
 
 	ValidateNotifier(&config.Notifier, validator)
 
@@ -20,6 +20,7 @@ const (
 	policyOneFactor = "one_factor"
 	policyTwoFactor = "two_factor"
 	policyDeny      = "deny"
+	policyExternal  = "external"
 )
 
 const (
@@ -64,6 +65,26 @@ const (
 		"opportunistic STARTTLS is explicitly disabled which means all emails will be sent insecurely over plaintext " +
 		"and this setting is only necessary for non-compliant SMTP servers which advertise they support STARTTLS " +
 		"when they actually don't support STARTTLS"
+
+	errFmtNotifierSMTPDKIMNotConfigured        = "notifier: smtp: dkim: option '%s' is required"
+	errFmtNotifierSMTPDKIMPrivateKeyType       = "notifier: smtp: dkim: option 'private_key' must be an RSA or Ed25519 private key but it's a %T"
+	errFmtNotifierSMTPDKIMPrivateKeyBitSize    = "notifier: smtp: dkim: option 'private_key' must be an RSA private key with %d bits or more but it only has %d bits"
+	errFmtNotifierSMTPDKIMHeadersNoFrom        = "notifier: smtp: dkim: option 'headers' must contain the 'From' header"
+	errFmtNotifierSMTPDKIMDomainNotAligned     = "notifier: smtp: dkim: option 'domain' is configured as '%s' which is not the domain or a parent domain of the sender domain '%s' which will cause DMARC alignment checks to fail"
+	errFmtNotifierSMTPSMIMENotConfigured       = "notifier: smtp: smime: option '%s' is required"
+	errFmtNotifierSMTPSMIMEPrivateKeyType      = "notifier: smtp: smime: option 'private_key' must be an RSA or ECDSA private key but it's a %T"
+	errFmtNotifierSMTPSMIMECertificateChainKey = "notifier: smtp: smime: option 'certificate_chain' is invalid: provided certificate is not the public key for the private key provided"
+
+	errFmtNotifierWebhookNotConfigured      = "notifier: webhooks: webhook %s: option '%s' is required"
+	errFmtNotifierWebhookNameDuplicate      = "notifier: webhooks: webhook %s: option 'name' must be unique but the value '%s' is configured for more than one webhook"
+	errFmtNotifierWebhookFormatInvalid      = "notifier: webhooks: webhook %s: option 'format' " + errSuffixMustBeOneOf
+	errFmtNotifierWebhookURLInvalidScheme   = "notifier: webhooks: webhook %s: option 'url' must have the scheme 'http' or 'https' but it's configured as '%s'"
+	errFmtNotifierWebhookTimeoutNegative    = "notifier: webhooks: webhook %s: option 'timeout' must be a positive duration but it's configured as '%s'"
+	errFmtNotifierWebhookTemplateNotAllowed = "notifier: webhooks: webhook %s: option 'templates' contains the template '%s' which can't be sent to a webhook as it contains a link which grants access to the account of the user"
+	errFmtNotifierWebhookTLSConfigInvalid   = "notifier: webhooks: webhook %s: tls: %w"
+
+	errFmtNotifierOutboxOptionNegative     = "notifier: outbox: option '%s' must be more than 0 but it's configured as '%s'"
+	errFmtNotifierOutboxMaxBackoffTooShort = "notifier: outbox: option 'max_backoff' must be more than or equal to the option 'backoff' which is configured as '%s' but it's configured as '%s'"
 )
 
 const (
@@ -76,10 +97,26 @@ const (
 		"authentication backend is configured"
 	errFmtAuthBackendMultipleConfigured = "authentication_backend: please ensure only one of the 'file' or 'ldap' " +
 		"backend is configured"
+	errFmtAuthBackendChainInvalid = "authentication_backend: option 'chain' contains the value '%s' but it must " +
+		"only contain the following values: '%s'"
+	errFmtAuthBackendChainDuplicate     = "authentication_backend: option 'chain' contains the value '%s' more than once"
+	errFmtAuthBackendChainNotConfigured = "authentication_backend: option 'chain' contains the value '%s' but the " +
+		"'%s' backend is not configured"
+	errFmtAuthBackendChainMissing = "authentication_backend: option 'chain' must contain the value '%s' when the " +
+		"'%s' backend is configured"
 	errFmtAuthBackendRefreshInterval = "authentication_backend: option 'refresh_interval' is configured to '%s' but " +
 		"it must be either a duration notation or one of 'disable', or 'always': %w"
 	errFmtAuthBackendPasswordResetCustomURLScheme = "authentication_backend: password_reset: option 'custom_url' is" +
 		" configured to '%s' which has the scheme '%s' but the scheme must be either 'http' or 'https'"
+	errFmtAuthBackendPasswordChangeSecondFactorMaxAgeNegative = "authentication_backend: password_change: option " +
+		"'second_factor_max_age' must not be negative but it is configured as '%s'"
+	errFmtAuthBackendCacheNegative = "authentication_backend: cache: option '%s' " +
+		"must not be negative but it is configured as '%s'"
+	errFmtAuthBackendCacheBackgroundRefresh = "authentication_backend: cache: option 'background_refresh' " +
+		"must be less than the 'lifespan' of '%s' but it is configured as '%s'"
+
+	errFmtAuthBackendPasswordExpiryMaxAgeNegative = "authentication_backend: %s: password_expiry: option 'max_age' " +
+		"must not be negative but it is configured as '%s'"
 
 	errFmtFileAuthBackendPathNotConfigured  = "authentication_backend: file: option 'path' is required"
 	errFmtFileAuthBackendPasswordUnknownAlg = "authentication_backend: file: password: option 'algorithm' " +
@@ -93,8 +130,9 @@ const (
 	errFmtFileAuthBackendPasswordArgon2MemoryTooLow = "authentication_backend: file: password: argon2: " +
 		"option 'memory' is configured as '%d' but must be greater than or equal to '%d' or '%d' (the value of 'parallelism) multiplied by '%d'"
 
-	errFmtLDAPAuthBackendUnauthenticatedBindWithPassword     = "authentication_backend: ldap: option 'permit_unauthenticated_bind' can't be enabled when a password is specified"
-	errFmtLDAPAuthBackendUnauthenticatedBindWithResetEnabled = "authentication_backend: ldap: option 'permit_unauthenticated_bind' can't be enabled when password reset is enabled"
+	errFmtLDAPAuthBackendUnauthenticatedBindWithPassword      = "authentication_backend: ldap: option 'permit_unauthenticated_bind' can't be enabled when a password is specified"
+	errFmtLDAPAuthBackendUnauthenticatedBindWithResetEnabled  = "authentication_backend: ldap: option 'permit_unauthenticated_bind' can't be enabled when password reset is enabled"
+	errFmtLDAPAuthBackendUnauthenticatedBindWithChangeEnabled = "authentication_backend: ldap: option 'permit_unauthenticated_bind' can't be enabled when password change is enabled"
 
 	errFmtLDAPAuthBackendMissingOption    = "authentication_backend: ldap: option '%s' is required"
 	errFmtLDAPAuthBackendTLSConfigInvalid = "authentication_backend: ldap: tls: %w"
@@ -103,9 +141,17 @@ const (
 	errFmtLDAPAuthBackendFilterReplacedPlaceholders = "authentication_backend: ldap: option " +
 		"'%s' has an invalid placeholder: '%s' has been removed, please use '%s' instead"
 	errFmtLDAPAuthBackendURLNotParsable = "authentication_backend: ldap: option " +
-		"'url' could not be parsed: %w"
+		"'%s' could not be parsed: %w"
 	errFmtLDAPAuthBackendURLInvalidScheme = "authentication_backend: ldap: option " +
-		"'url' must have either the 'ldap' or 'ldaps' scheme but it is configured as '%s'"
+		"'%s' must have either the 'ldap' or 'ldaps' scheme but it is configured as '%s'"
+	errFmtLDAPAuthBackendURLStrategy = "authentication_backend: ldap: option 'url_strategy' " +
+		errSuffixMustBeOneOf
+	errFmtLDAPAuthBackendPoolingNegative = "authentication_backend: ldap: pooling: option '%s' " +
+		"must not be negative but it is configured as '%v'"
+	errFmtLDAPAuthBackendGroupSearchMode = "authentication_backend: ldap: option 'group_search_mode' " +
+		errSuffixMustBeOneOf
+	errFmtLDAPAuthBackendGroupSearchMaxDepth = "authentication_backend: ldap: option 'group_search_max_depth' " +
+		"must not be negative but it is configured as '%d'"
 	errFmtLDAPAuthBackendFilterEnclosingParenthesis = "authentication_backend: ldap: option " +
 		"'%s' must contain enclosing parenthesis: '%s' should probably be '(%s)'"
 	errFmtLDAPAuthBackendFilterMissingPlaceholder = "authentication_backend: ldap: option " +
@@ -125,6 +171,14 @@ const (
 	errStrStorage                                 = "storage: configuration for a 'local', 'mysql' or 'postgres' database must be provided"
 	errStrStorageEncryptionKeyMustBeProvided      = "storage: option 'encryption_key' is required"
 	errStrStorageEncryptionKeyTooShort            = "storage: option 'encryption_key' must be 20 characters or longer"
+	errFmtStorageEncryptionDecryptionKeyTooShort  = "storage: encryption: option 'decryption_keys' must only contain keys which are 20 characters or longer but the key at position %d is shorter"
+	errFmtStorageEncryptionRekeyOptionNegative    = "storage: encryption: rekey: option '%s' must be more than 0 but it's configured as '%s'"
+	errStrStorageEncryptionKeyProviderMultiple    = "storage: encryption: key_provider: only one of the 'vault_transit' and 'file' options can be configured"
+	errFmtStorageEncryptionKeyProviderRequired    = "storage: encryption: key_provider: %s: option '%s' is required"
+	errFmtStorageEncryptionKeyProviderNegative    = "storage: encryption: key_provider: %s: option '%s' must be more than 0 but it's configured as '%s'"
+	errFmtStorageEncryptionKeyProviderURLScheme   = "storage: encryption: key_provider: vault_transit: option 'address' must have the 'http' or 'https' scheme but it's configured as '%s'"
+	errFmtStorageEncryptionKeyProviderTLSInvalid  = "storage: encryption: key_provider: vault_transit: tls: %w"
+	errFmtStorageRetentionOptionNegative          = "storage: retention: option '%s' must be more than 0 but it's configured as '%s'"
 	errFmtStorageUserPassMustBeProvided           = "storage: %s: option 'username' and 'password' are required" //nolint:gosec
 	errFmtStorageOptionMustBeProvided             = "storage: %s: option '%s' is required"
 	errFmtStorageTLSConfigInvalid                 = "storage: %s: tls: %w"
@@ -171,6 +225,10 @@ const (
 		"invalid value: redirect uri '%s' must have the scheme but it is absent"
 	errFmtOIDCClientInvalidPolicy = "identity_providers: oidc: client '%s': option 'policy' must be 'one_factor' " +
 		"or 'two_factor' but it is configured as '%s'"
+	errFmtOIDCClientInvalidMaxAuthenticationAgeNegative = "identity_providers: oidc: client '%s': option " +
+		"'max_authentication_age.%s' must be a positive duration but it is configured as '%s'"
+	errFmtOIDCClientInvalidMaxAuthenticationAgeSecondFactor = "identity_providers: oidc: client '%s': option " +
+		"'max_authentication_age.second_factor' must only be configured when the option 'authorization_policy' is 'two_factor' but it is configured as '%s'"
 	errFmtOIDCClientInvalidPKCEChallengeMethod = "identity_providers: oidc: client '%s': option 'pkce_challenge_method' must be 'plain' " +
 		"or 'S256' but it is configured as '%s'"
 	errFmtOIDCClientInvalidConsentMode = "identity_providers: oidc: client '%s': consent: option 'mode' must be one of " +
@@ -208,7 +266,7 @@ const (
 	errFmtAccessControlRuleNoDomains = "access control: rule %s: rule is invalid: must have the option " +
 		"'domain' or 'domain_regex' configured"
 	errFmtAccessControlRuleInvalidPolicy = "access control: rule %s: rule 'policy' option '%s' " +
-		"is invalid: must be one of 'deny', 'two_factor', 'one_factor' or 'bypass'"
+		"is invalid: must be one of 'deny', 'two_factor', 'one_factor', 'bypass' or 'external'"
 	errAccessControlRuleBypassPolicyInvalidWithSubjects = "access control: rule %s: 'policy' option 'bypass' is " +
 		"not supported when 'subject' option is configured: see " +
 		"https://www.authelia.com/c/acl#bypass"
@@ -221,18 +279,34 @@ const (
 		"invalid: must start with 'user:' or 'group:'"
 	errFmtAccessControlRuleMethodInvalid = "access control: rule %s: 'methods' option '%s' is " +
 		"invalid: must be one of '%s'"
-	errFmtAccessControlRuleQueryInvalid = "access control: rule %s: 'query' option 'operator' with value '%s' is " +
+	errFmtAccessControlRuleQueryInvalid = "access control: rule %s: '%s' option 'operator' with value '%s' is " +
 		"invalid: must be one of '%s'"
-	errFmtAccessControlRuleQueryInvalidNoValue = "access control: rule %s: 'query' option '%s' is " +
+	errFmtAccessControlRuleQueryInvalidNoValue = "access control: rule %s: '%s' option '%s' is " +
 		"invalid: must have a value"
-	errFmtAccessControlRuleQueryInvalidNoValueOperator = "access control: rule %s: 'query' option '%s' is " +
+	errFmtAccessControlRuleQueryInvalidNoValueOperator = "access control: rule %s: '%s' option '%s' is " +
 		"invalid: must have a value when the operator is '%s'"
-	errFmtAccessControlRuleQueryInvalidValue = "access control: rule %s: 'query' option '%s' is " +
+	errFmtAccessControlRuleQueryInvalidValue = "access control: rule %s: '%s' option '%s' is " +
 		"invalid: must not have a value when the operator is '%s'"
-	errFmtAccessControlRuleQueryInvalidValueParse = "access control: rule %s: 'query' option '%s' is " +
+	errFmtAccessControlRuleQueryInvalidValueParse = "access control: rule %s: '%s' option '%s' is " +
 		"invalid: %w"
-	errFmtAccessControlRuleQueryInvalidValueType = "access control: rule %s: 'query' option 'value' is " +
+	errFmtAccessControlRuleQueryInvalidValueType = "access control: rule %s: '%s' option 'value' is " +
 		"invalid: expected type was string but got %T"
+	errFmtAccessControlRuleExternalNoAddress = "access control: rule %s: 'policy' option 'external' is " +
+		"invalid: the 'access_control.external.address' option must be configured"
+	errFmtAccessControlExternalAddressScheme = "access control: external: option 'address' must have the scheme " +
+		"'http' or 'https' but it is configured as '%s'"
+	errFmtAccessControlExternalFailurePolicy = "access control: external: option 'failure_policy' must be one of '%s' " +
+		"but it is configured as '%s'"
+	errFmtAccessControlExternalNegativeDuration = "access control: external: option '%s' must be a positive duration " +
+		"but it is configured as '%s'"
+	errFmtAccessControlExternalNegativeCacheSize = "access control: external: option 'cache_size' must be more than 0 " +
+		"but it is configured as '%d'"
+	errFmtAccessControlRuleMaxAuthenticationAgeNegative = "access control: rule %s: 'max_authentication_age' option " +
+		"'%s' is invalid: must be a positive duration but it is configured as '%s'"
+	errFmtAccessControlRuleMaxAuthenticationAgePolicy = "access control: rule %s: 'max_authentication_age' option " +
+		"is invalid: must only be configured when the 'policy' option is 'one_factor' or 'two_factor' but it is configured as '%s'"
+	errFmtAccessControlRuleMaxAuthenticationAgeSecondFactor = "access control: rule %s: 'max_authentication_age' option " +
+		"'second_factor' is invalid: must only be configured when the 'policy' option is 'two_factor' but it is configured as '%s'"
 )
 
 // Theme Error constants.
@@ -274,7 +348,8 @@ const (
 
 // Regulation Error Consts.
 const (
-	errFmtRegulationFindTimeGreaterThanBanTime = "regulation: option 'find_time' must be less than or equal to option 'ban_time'"
+	errFmtRegulationFindTimeGreaterThanBanTime                   = "regulation: option 'find_time' must be less than or equal to option 'ban_time'"
+	errFmtRegulationBanTimeGreaterThanAuthenticationLogRetention = "regulation: option 'ban_time' must be less than or equal to the storage retention option 'authentication_logs' but it's configured as '%s' and the retention is configured as '%s'"
 )
 
 // Server Error constants.
@@ -302,6 +377,12 @@ const (
 	errPasswordPolicyMultipleDefined                        = "password_policy: only a single password policy mechanism can be specified"
 	errFmtPasswordPolicyStandardMinLengthNotGreaterThanZero = "password_policy: standard: option 'min_length' must be greater than 0 but is configured as %d"
 	errFmtPasswordPolicyZXCVBNMinScoreInvalid               = "password_policy: zxcvbn: option 'min_score' is invalid: must be between 1 and 4 but it's configured as %d"
+	errFmtPasswordPolicyBreachedMode                        = "password_policy: breached: option 'mode' " + errSuffixMustBeOneOf
+	errFmtPasswordPolicyBreachedPathRequired                = "password_policy: breached: option 'path' is required when the mode is '%s'"
+	errFmtPasswordPolicyBreachedURLScheme                   = "password_policy: breached: option 'url' must have the scheme 'http' or 'https' but it's configured as '%s'"
+	errFmtPasswordPolicyBreachedNegative                    = "password_policy: breached: option '%s' must not be negative but it's configured as '%s'"
+	errFmtPasswordPolicyBreachedThresholdInvalid            = "password_policy: breached: option 'threshold' must be greater than 0 but it's configured as %d"
+	errFmtPasswordPolicyHistoryCountInvalid                 = "password_policy: history: option 'count' must be greater than 0 but it's configured as %d"
 )
 
 const (
@@ -339,6 +420,30 @@ const (
 )
 
 var (
+	validAuthBackends = []string{schema.AuthenticationBackendFile, schema.AuthenticationBackendLDAP}
+
+	validLDAPURLStrategies = []string{schema.LDAPURLStrategyFailover, schema.LDAPURLStrategyRoundRobin}
+
+	validLDAPGroupSearchModes = []string{
+		schema.LDAPGroupSearchModeFilter,
+		schema.LDAPGroupSearchModeInChain,
+		schema.LDAPGroupSearchModeRecursive,
+		schema.LDAPGroupSearchModeMemberOf,
+	}
+
+	validPasswordPolicyBreachedModes = []string{
+		schema.PasswordPolicyBreachedModeDirectory,
+		schema.PasswordPolicyBreachedModeBloom,
+		schema.PasswordPolicyBreachedModeAPI,
+	}
+
+	validWebhookNotifierFormats = []string{
+		schema.WebhookNotifierFormatGeneric,
+		schema.WebhookNotifierFormatSlack,
+		schema.WebhookNotifierFormatMattermost,
+		schema.WebhookNotifierFormatMatrix,
+	}
+
 	validLDAPImplementations = []string{
 		schema.LDAPImplementationCustom,
 		schema.LDAPImplementationActiveDirectory,
@@ -182,6 +182,7 @@ func validateOIDCClients(config *schema.OpenIDConnectConfiguration, val *schema.
 			val.Push(fmt.Errorf(errFmtOIDCClientInvalidPKCEChallengeMethod, client.ID, client.PKCEChallengeMethod))
 		}
 
+		validateOIDCClientMaxAuthenticationAge(c, config, val)
 		validateOIDCClientConsentMode(c, config, val)
 		validateOIDCClientSectorIdentifier(client, val)
 		validateOIDCClientScopes(c, config, val)
@@ -237,6 +238,22 @@ func validateOIDCClientSectorIdentifier(client schema.OpenIDConnectClientConfigu
 	}
 }
 
+func validateOIDCClientMaxAuthenticationAge(c int, config *schema.OpenIDConnectConfiguration, val *schema.StructValidator) {
+	client := config.Clients[c]
+
+	if client.MaxAuthenticationAge.FirstFactor < 0 {
+		val.Push(fmt.Errorf(errFmtOIDCClientInvalidMaxAuthenticationAgeNegative, client.ID, "first_factor", client.MaxAuthenticationAge.FirstFactor))
+	}
+
+	if client.MaxAuthenticationAge.SecondFactor < 0 {
+		val.Push(fmt.Errorf(errFmtOIDCClientInvalidMaxAuthenticationAgeNegative, client.ID, "second_factor", client.MaxAuthenticationAge.SecondFactor))
+	}
+
+	if client.MaxAuthenticationAge.SecondFactor != 0 && client.Policy == policyOneFactor {
+		val.Push(fmt.Errorf(errFmtOIDCClientInvalidMaxAuthenticationAgeSecondFactor, client.ID, client.Policy))
+	}
+}
+
 func validateOIDCClientConsentMode(c int, config *schema.OpenIDConnectConfiguration, val *schema.StructValidator) {
 	switch {
 	case utils.IsStringInSlice(config.Clients[c].ConsentMode, []string{"", "auto"}):
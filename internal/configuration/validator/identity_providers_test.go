@@ -577,6 +577,36 @@ func TestShouldRaiseErrorWhenOIDCClientConfiguredWithBadUserinfoAlg(t *testing.T
 	assert.EqualError(t, validator.Errors()[0], "identity_providers: oidc: client 'good_id': option 'userinfo_signing_algorithm' must be one of 'none, RS256' but it is configured as 'rs256'")
 }
 
+func TestShouldRaiseErrorWhenOIDCClientConfiguredWithBadMaxAuthenticationAge(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.IdentityProvidersConfiguration{
+		OIDC: &schema.OpenIDConnectConfiguration{
+			HMACSecret:       "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+			IssuerPrivateKey: MustParseRSAPrivateKey(testKey1),
+			Clients: []schema.OpenIDConnectClientConfiguration{
+				{
+					ID:     "good_id",
+					Secret: MustDecodeSecret("$plaintext$good_secret"),
+					Policy: "one_factor",
+					RedirectURIs: []string{
+						"https://google.com/callback",
+					},
+					MaxAuthenticationAge: schema.MaxAuthenticationAge{
+						FirstFactor:  -time.Minute,
+						SecondFactor: time.Minute,
+					},
+				},
+			},
+		},
+	}
+
+	ValidateIdentityProviders(config, validator)
+
+	require.Len(t, validator.Errors(), 2)
+	assert.EqualError(t, validator.Errors()[0], "identity_providers: oidc: client 'good_id': option 'max_authentication_age.first_factor' must be a positive duration but it is configured as '-1m0s'")
+	assert.EqualError(t, validator.Errors()[1], "identity_providers: oidc: client 'good_id': option 'max_authentication_age.second_factor' must only be configured when the option 'authorization_policy' is 'two_factor' but it is configured as 'one_factor'")
+}
+
 func TestValidateIdentityProvidersShouldRaiseWarningOnSecurityIssue(t *testing.T) {
 	validator := schema.NewStructValidator()
 	config := &schema.IdentityProvidersConfiguration{
@@ -1,10 +1,17 @@
 package validator
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/templates"
+	"github.com/authelia/authelia/v4/internal/utils"
 )
 
 // ValidateNotifier validates and update notifier configuration.
@@ -19,6 +26,9 @@ func ValidateNotifier(config *schema.NotifierConfiguration, validator *schema.St
 		return
 	}
 
+	validateWebhookNotifiers(config, validator)
+	validateNotifierOutbox(&config.Outbox, validator)
+
 	if config.FileSystem != nil {
 		if config.FileSystem.Filename == "" {
 			validator.Push(fmt.Errorf(errFmtNotifierFileSystemFileNameNotConfigured))
@@ -99,4 +109,205 @@ func validateSMTPNotifier(config *schema.SMTPNotifierConfiguration, validator *s
 	if config.DisableStartTLS {
 		validator.PushWarning(fmt.Errorf(errFmtNotifierStartTlsDisabled))
 	}
+
+	validateSMTPNotifierDKIM(config, validator)
+	validateSMTPNotifierSMIME(config, validator)
+}
+
+func validateSMTPNotifierDKIM(config *schema.SMTPNotifierConfiguration, validator *schema.StructValidator) {
+	if config.DKIM == nil {
+		return
+	}
+
+	var sender string
+
+	if at := strings.LastIndex(config.Sender.Address, "@"); at >= 0 {
+		sender = strings.ToLower(config.Sender.Address[at+1:])
+	}
+
+	switch {
+	case config.DKIM.Domain == "" && sender == "":
+		validator.Push(fmt.Errorf(errFmtNotifierSMTPDKIMNotConfigured, "domain"))
+	case config.DKIM.Domain == "":
+		config.DKIM.Domain = sender
+	case sender != "" && !isDomainOrSubdomain(sender, config.DKIM.Domain):
+		validator.PushWarning(fmt.Errorf(errFmtNotifierSMTPDKIMDomainNotAligned, config.DKIM.Domain, sender))
+	}
+
+	if config.DKIM.Selector == "" {
+		validator.Push(fmt.Errorf(errFmtNotifierSMTPDKIMNotConfigured, "selector"))
+	}
+
+	switch key := config.DKIM.PrivateKey.(type) {
+	case nil:
+		validator.Push(fmt.Errorf(errFmtNotifierSMTPDKIMNotConfigured, "private_key"))
+	case *rsa.PrivateKey:
+		if key.Size()*8 < 1024 {
+			validator.Push(fmt.Errorf(errFmtNotifierSMTPDKIMPrivateKeyBitSize, 1024, key.Size()*8))
+		}
+	case ed25519.PrivateKey:
+		break
+	default:
+		validator.Push(fmt.Errorf(errFmtNotifierSMTPDKIMPrivateKeyType, key))
+	}
+
+	if len(config.DKIM.Headers) == 0 {
+		config.DKIM.Headers = schema.DefaultSMTPNotifierDKIMConfiguration.Headers
+	} else if !utils.IsStringInSliceFold("From", config.DKIM.Headers) {
+		validator.Push(fmt.Errorf(errFmtNotifierSMTPDKIMHeadersNoFrom))
+	}
+}
+
+func validateSMTPNotifierSMIME(config *schema.SMTPNotifierConfiguration, validator *schema.StructValidator) {
+	if config.SMIME == nil {
+		return
+	}
+
+	switch key := config.SMIME.PrivateKey.(type) {
+	case nil:
+		validator.Push(fmt.Errorf(errFmtNotifierSMTPSMIMENotConfigured, "private_key"))
+	case *rsa.PrivateKey, *ecdsa.PrivateKey:
+		break
+	default:
+		validator.Push(fmt.Errorf(errFmtNotifierSMTPSMIMEPrivateKeyType, key))
+	}
+
+	switch {
+	case !config.SMIME.CertificateChain.HasCertificates():
+		validator.Push(fmt.Errorf(errFmtNotifierSMTPSMIMENotConfigured, "certificate_chain"))
+	case config.SMIME.PrivateKey != nil && !config.SMIME.CertificateChain.EqualKey(config.SMIME.PrivateKey):
+		validator.Push(fmt.Errorf(errFmtNotifierSMTPSMIMECertificateChainKey))
+	}
+}
+
+func isDomainOrSubdomain(domain, parent string) bool {
+	domain, parent = strings.ToLower(domain), strings.ToLower(parent)
+
+	return domain == parent || strings.HasSuffix(domain, "."+parent)
+}
+
+func validateNotifierOutbox(config *schema.NotifierOutboxConfiguration, validator *schema.StructValidator) {
+	switch {
+	case config.Interval == 0:
+		config.Interval = schema.DefaultNotifierOutboxConfiguration.Interval
+	case config.Interval < 0:
+		validator.Push(fmt.Errorf(errFmtNotifierOutboxOptionNegative, "interval", config.Interval))
+	}
+
+	switch {
+	case config.MaxAttempts == 0:
+		config.MaxAttempts = schema.DefaultNotifierOutboxConfiguration.MaxAttempts
+	case config.MaxAttempts < 0:
+		validator.Push(fmt.Errorf(errFmtNotifierOutboxOptionNegative, "max_attempts", strconv.Itoa(config.MaxAttempts)))
+	}
+
+	switch {
+	case config.Backoff == 0:
+		config.Backoff = schema.DefaultNotifierOutboxConfiguration.Backoff
+	case config.Backoff < 0:
+		validator.Push(fmt.Errorf(errFmtNotifierOutboxOptionNegative, "backoff", config.Backoff))
+	}
+
+	switch {
+	case config.MaxBackoff == 0:
+		config.MaxBackoff = schema.DefaultNotifierOutboxConfiguration.MaxBackoff
+
+		if config.MaxBackoff < config.Backoff {
+			config.MaxBackoff = config.Backoff
+		}
+	case config.MaxBackoff < 0:
+		validator.Push(fmt.Errorf(errFmtNotifierOutboxOptionNegative, "max_backoff", config.MaxBackoff))
+	case config.MaxBackoff < config.Backoff:
+		validator.Push(fmt.Errorf(errFmtNotifierOutboxMaxBackoffTooShort, config.Backoff, config.MaxBackoff))
+	}
+}
+
+func validateWebhookNotifiers(config *schema.NotifierConfiguration, validator *schema.StructValidator) {
+	var names []string
+
+	for i := range config.Webhooks {
+		webhook := &config.Webhooks[i]
+		descriptor := webhookDescriptor(i+1, webhook)
+
+		switch {
+		case webhook.Name == "":
+			validator.Push(fmt.Errorf(errFmtNotifierWebhookNotConfigured, descriptor, "name"))
+		case utils.IsStringInSlice(webhook.Name, names):
+			validator.Push(fmt.Errorf(errFmtNotifierWebhookNameDuplicate, descriptor, webhook.Name))
+		default:
+			names = append(names, webhook.Name)
+		}
+
+		validateWebhookNotifier(descriptor, webhook, validator)
+	}
+}
+
+func validateWebhookNotifier(descriptor string, config *schema.WebhookNotifierConfiguration, validator *schema.StructValidator) {
+	if config.Format == "" {
+		config.Format = schema.DefaultWebhookNotifierConfiguration.Format
+	}
+
+	if !utils.IsStringInSlice(config.Format, validWebhookNotifierFormats) {
+		validator.Push(fmt.Errorf(errFmtNotifierWebhookFormatInvalid, descriptor, config.Format, strings.Join(validWebhookNotifierFormats, "', '")))
+	}
+
+	switch {
+	case config.URL == nil:
+		validator.Push(fmt.Errorf(errFmtNotifierWebhookNotConfigured, descriptor, "url"))
+	case config.URL.Scheme != schemeHTTP && config.URL.Scheme != schemeHTTPS:
+		validator.Push(fmt.Errorf(errFmtNotifierWebhookURLInvalidScheme, descriptor, config.URL.Scheme))
+	}
+
+	switch config.Format {
+	case schema.WebhookNotifierFormatGeneric:
+		if config.Secret == "" {
+			validator.Push(fmt.Errorf(errFmtNotifierWebhookNotConfigured, descriptor, "secret"))
+		}
+	case schema.WebhookNotifierFormatMatrix:
+		if config.AccessToken == "" {
+			validator.Push(fmt.Errorf(errFmtNotifierWebhookNotConfigured, descriptor, "access_token"))
+		}
+
+		if config.RoomID == "" {
+			validator.Push(fmt.Errorf(errFmtNotifierWebhookNotConfigured, descriptor, "room_id"))
+		}
+	}
+
+	switch {
+	case config.Timeout == 0:
+		config.Timeout = schema.DefaultWebhookNotifierConfiguration.Timeout
+	case config.Timeout < 0:
+		validator.Push(fmt.Errorf(errFmtNotifierWebhookTimeoutNegative, descriptor, config.Timeout))
+	}
+
+	for _, name := range config.Templates {
+		if name == templates.TemplateNameEmailIdentityVerification {
+			validator.Push(fmt.Errorf(errFmtNotifierWebhookTemplateNotAllowed, descriptor, name))
+		}
+	}
+
+	if config.TLS == nil {
+		config.TLS = &schema.TLSConfig{}
+	}
+
+	configDefaultTLS := &schema.TLSConfig{
+		MinimumVersion: schema.DefaultWebhookNotifierConfiguration.TLS.MinimumVersion,
+		MaximumVersion: schema.DefaultWebhookNotifierConfiguration.TLS.MaximumVersion,
+	}
+
+	if config.URL != nil {
+		configDefaultTLS.ServerName = config.URL.Hostname()
+	}
+
+	if err := ValidateTLSConfig(config.TLS, configDefaultTLS); err != nil {
+		validator.Push(fmt.Errorf(errFmtNotifierWebhookTLSConfigInvalid, descriptor, err))
+	}
+}
+
+func webhookDescriptor(position int, config *schema.WebhookNotifierConfiguration) string {
+	if config.Name == "" {
+		return fmt.Sprintf("#%d", position)
+	}
+
+	return fmt.Sprintf("#%d (name '%s')", position, config.Name)
 }
@@ -1,10 +1,15 @@
 package validator
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
 	"fmt"
 	"net/mail"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 
@@ -27,6 +32,8 @@ func (suite *NotifierSuite) SetupTest() {
 		Port:     25,
 	}
 	suite.config.FileSystem = nil
+	suite.config.Webhooks = nil
+	suite.config.Outbox = schema.NotifierOutboxConfiguration{}
 }
 
 /*
@@ -187,6 +194,117 @@ func (suite *NotifierSuite) TestSMTPShouldEnsureSenderIsProvided() {
 	suite.Assert().EqualError(suite.validator.Errors()[0], fmt.Sprintf(errFmtNotifierSMTPNotConfigured, "sender"))
 }
 
+func (suite *NotifierSuite) TestSMTPDKIMShouldSetDefaults() {
+	suite.config.SMTP.DKIM = &schema.SMTPNotifierDKIMConfiguration{
+		Selector:   "authelia",
+		PrivateKey: MustParseRSAPrivateKey(testKey1),
+	}
+
+	ValidateNotifier(&suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Assert().Len(suite.validator.Errors(), 0)
+
+	suite.Assert().Equal("example.com", suite.config.SMTP.DKIM.Domain)
+	suite.Assert().Equal(schema.DefaultSMTPNotifierDKIMConfiguration.Headers, suite.config.SMTP.DKIM.Headers)
+}
+
+func (suite *NotifierSuite) TestSMTPDKIMShouldWarnNotAligned() {
+	suite.config.SMTP.DKIM = &schema.SMTPNotifierDKIMConfiguration{
+		Domain:     "example.org",
+		Selector:   "authelia",
+		PrivateKey: ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)),
+	}
+
+	ValidateNotifier(&suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Errors(), 0)
+	suite.Require().Len(suite.validator.Warnings(), 1)
+
+	suite.Assert().EqualError(suite.validator.Warnings()[0], "notifier: smtp: dkim: option 'domain' is configured as 'example.org' which is not the domain or a parent domain of the sender domain 'example.com' which will cause DMARC alignment checks to fail")
+}
+
+func (suite *NotifierSuite) TestSMTPSignatureShouldRaiseErrors() {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().NoError(err)
+
+	testCases := []struct {
+		name     string
+		dkim     *schema.SMTPNotifierDKIMConfiguration
+		smime    *schema.SMTPNotifierSMIMEConfiguration
+		expected []string
+	}{
+		{
+			"ShouldRaiseErrorDKIMNotConfigured",
+			&schema.SMTPNotifierDKIMConfiguration{},
+			nil,
+			[]string{
+				"notifier: smtp: dkim: option 'selector' is required",
+				"notifier: smtp: dkim: option 'private_key' is required",
+			},
+		},
+		{
+			"ShouldRaiseErrorDKIMInvalidKeyAndHeaders",
+			&schema.SMTPNotifierDKIMConfiguration{Selector: "authelia", PrivateKey: key, Headers: []string{"Subject"}},
+			nil,
+			[]string{
+				"notifier: smtp: dkim: option 'private_key' must be an RSA or Ed25519 private key but it's a *ecdsa.PrivateKey",
+				"notifier: smtp: dkim: option 'headers' must contain the 'From' header",
+			},
+		},
+		{
+			"ShouldRaiseErrorSMIMENotConfigured",
+			nil,
+			&schema.SMTPNotifierSMIMEConfiguration{},
+			[]string{
+				"notifier: smtp: smime: option 'private_key' is required",
+				"notifier: smtp: smime: option 'certificate_chain' is required",
+			},
+		},
+		{
+			"ShouldRaiseErrorSMIMEInvalidKey",
+			nil,
+			&schema.SMTPNotifierSMIMEConfiguration{PrivateKey: ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)), CertificateChain: MustParseX509CertificateChain(testCert1)},
+			[]string{
+				"notifier: smtp: smime: option 'private_key' must be an RSA or ECDSA private key but it's a ed25519.PrivateKey",
+				"notifier: smtp: smime: option 'certificate_chain' is invalid: provided certificate is not the public key for the private key provided",
+			},
+		},
+		{
+			"ShouldRaiseErrorSMIMEKeyMismatch",
+			nil,
+			&schema.SMTPNotifierSMIMEConfiguration{PrivateKey: MustParseRSAPrivateKey(testKey2), CertificateChain: MustParseX509CertificateChain(testCert1)},
+			[]string{
+				"notifier: smtp: smime: option 'certificate_chain' is invalid: provided certificate is not the public key for the private key provided",
+			},
+		},
+		{
+			"ShouldNotRaiseErrorValid",
+			&schema.SMTPNotifierDKIMConfiguration{Selector: "authelia", PrivateKey: MustParseRSAPrivateKey(testKey1), Headers: []string{"from", "subject"}},
+			&schema.SMTPNotifierSMIMEConfiguration{PrivateKey: MustParseRSAPrivateKey(testKey1), CertificateChain: MustParseX509CertificateChain(testCert1)},
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.SetupTest()
+
+			suite.config.SMTP.DKIM = tc.dkim
+			suite.config.SMTP.SMIME = tc.smime
+
+			ValidateNotifier(&suite.config, suite.validator)
+
+			suite.Assert().Len(suite.validator.Warnings(), 0)
+			suite.Require().Len(suite.validator.Errors(), len(tc.expected))
+
+			for i, expected := range tc.expected {
+				suite.Assert().EqualError(suite.validator.Errors()[i], expected)
+			}
+		})
+	}
+}
+
 /*
 File Tests.
 */
@@ -212,6 +330,172 @@ func (suite *NotifierSuite) TestFileShouldEnsureFilenameIsProvided() {
 	suite.Assert().EqualError(suite.validator.Errors()[0], errFmtNotifierFileSystemFileNameNotConfigured)
 }
 
+/*
+Webhook Tests.
+*/
+func (suite *NotifierSuite) TestWebhookShouldSetDefaults() {
+	suite.config.Webhooks = []schema.WebhookNotifierConfiguration{
+		{Name: "example", URL: MustParseURL("https://hooks.example.com/abc"), Secret: "secret"},
+	}
+
+	ValidateNotifier(&suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Assert().Len(suite.validator.Errors(), 0)
+
+	suite.Assert().Equal(schema.WebhookNotifierFormatGeneric, suite.config.Webhooks[0].Format)
+	suite.Assert().Equal(schema.DefaultWebhookNotifierConfiguration.Timeout, suite.config.Webhooks[0].Timeout)
+	suite.Require().NotNil(suite.config.Webhooks[0].TLS)
+	suite.Assert().Equal("hooks.example.com", suite.config.Webhooks[0].TLS.ServerName)
+	suite.Assert().Equal(uint16(tls.VersionTLS12), suite.config.Webhooks[0].TLS.MinimumVersion.Value)
+}
+
+func (suite *NotifierSuite) TestWebhookShouldValidateWithFileSystem() {
+	suite.config.SMTP = nil
+	suite.config.FileSystem = &schema.FileSystemNotifierConfiguration{Filename: "test"}
+	suite.config.Webhooks = []schema.WebhookNotifierConfiguration{
+		{Name: "example", URL: MustParseURL("https://hooks.example.com/abc")},
+	}
+
+	ValidateNotifier(&suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Require().Len(suite.validator.Errors(), 1)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "notifier: webhooks: webhook #1 (name 'example'): option 'secret' is required")
+}
+
+func (suite *NotifierSuite) TestWebhookShouldRaiseErrors() {
+	testCases := []struct {
+		name     string
+		have     []schema.WebhookNotifierConfiguration
+		expected []string
+	}{
+		{
+			"ShouldRaiseErrorMissingNameAndURL",
+			[]schema.WebhookNotifierConfiguration{{Format: schema.WebhookNotifierFormatSlack}},
+			[]string{
+				"notifier: webhooks: webhook #1: option 'name' is required",
+				"notifier: webhooks: webhook #1: option 'url' is required",
+			},
+		},
+		{
+			"ShouldRaiseErrorDuplicateName",
+			[]schema.WebhookNotifierConfiguration{
+				{Name: "chat", Format: schema.WebhookNotifierFormatSlack, URL: MustParseURL("https://hooks.example.com/abc")},
+				{Name: "chat", Format: schema.WebhookNotifierFormatMattermost, URL: MustParseURL("https://hooks.example.com/xyz")},
+			},
+			[]string{
+				"notifier: webhooks: webhook #2 (name 'chat'): option 'name' must be unique but the value 'chat' is configured for more than one webhook",
+			},
+		},
+		{
+			"ShouldRaiseErrorInvalidFormatAndScheme",
+			[]schema.WebhookNotifierConfiguration{{Name: "chat", Format: "teams", URL: MustParseURL("ftp://hooks.example.com/abc")}},
+			[]string{
+				"notifier: webhooks: webhook #1 (name 'chat'): option 'format' is configured as 'teams' but must be one of the following values: 'generic', 'slack', 'mattermost', 'matrix'",
+				"notifier: webhooks: webhook #1 (name 'chat'): option 'url' must have the scheme 'http' or 'https' but it's configured as 'ftp'",
+			},
+		},
+		{
+			"ShouldRaiseErrorMatrixMissingOptions",
+			[]schema.WebhookNotifierConfiguration{{Name: "matrix", Format: schema.WebhookNotifierFormatMatrix, URL: MustParseURL("https://matrix.example.com")}},
+			[]string{
+				"notifier: webhooks: webhook #1 (name 'matrix'): option 'access_token' is required",
+				"notifier: webhooks: webhook #1 (name 'matrix'): option 'room_id' is required",
+			},
+		},
+		{
+			"ShouldRaiseErrorNegativeTimeoutAndIdentityVerificationTemplate",
+			[]schema.WebhookNotifierConfiguration{{Name: "chat", Format: schema.WebhookNotifierFormatSlack, URL: MustParseURL("https://hooks.example.com/abc"), Timeout: -time.Second, Templates: []string{"Event", "IdentityVerification"}}},
+			[]string{
+				"notifier: webhooks: webhook #1 (name 'chat'): option 'timeout' must be a positive duration but it's configured as '-1s'",
+				"notifier: webhooks: webhook #1 (name 'chat'): option 'templates' contains the template 'IdentityVerification' which can't be sent to a webhook as it contains a link which grants access to the account of the user",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.SetupTest()
+
+			suite.config.Webhooks = tc.have
+
+			ValidateNotifier(&suite.config, suite.validator)
+
+			suite.Assert().Len(suite.validator.Warnings(), 0)
+			suite.Require().Len(suite.validator.Errors(), len(tc.expected))
+
+			for i, expected := range tc.expected {
+				suite.Assert().EqualError(suite.validator.Errors()[i], expected)
+			}
+		})
+	}
+}
+
+/*
+Outbox Tests.
+*/
+func (suite *NotifierSuite) TestOutboxShouldSetDefaults() {
+	suite.config.Outbox.Enabled = true
+
+	ValidateNotifier(&suite.config, suite.validator)
+
+	suite.Assert().Len(suite.validator.Warnings(), 0)
+	suite.Assert().Len(suite.validator.Errors(), 0)
+
+	suite.Assert().Equal(schema.NotifierOutboxConfiguration{
+		Enabled:     true,
+		Interval:    time.Minute,
+		MaxAttempts: 10,
+		Backoff:     time.Second * 30,
+		MaxBackoff:  time.Hour,
+	}, suite.config.Outbox)
+}
+
+func (suite *NotifierSuite) TestOutboxShouldRaiseErrors() {
+	testCases := []struct {
+		name     string
+		have     schema.NotifierOutboxConfiguration
+		expected []string
+	}{
+		{
+			"ShouldRaiseErrorNegativeValues",
+			schema.NotifierOutboxConfiguration{Interval: -time.Second, MaxAttempts: -1, Backoff: -time.Second, MaxBackoff: -time.Second},
+			[]string{
+				"notifier: outbox: option 'interval' must be more than 0 but it's configured as '-1s'",
+				"notifier: outbox: option 'max_attempts' must be more than 0 but it's configured as '-1'",
+				"notifier: outbox: option 'backoff' must be more than 0 but it's configured as '-1s'",
+				"notifier: outbox: option 'max_backoff' must be more than 0 but it's configured as '-1s'",
+			},
+		},
+		{
+			"ShouldRaiseErrorMaxBackoffLessThanBackoff",
+			schema.NotifierOutboxConfiguration{Backoff: time.Minute, MaxBackoff: time.Second},
+			[]string{
+				"notifier: outbox: option 'max_backoff' must be more than or equal to the option 'backoff' which is configured as '1m0s' but it's configured as '1s'",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.SetupTest()
+
+			suite.config.Outbox = tc.have
+
+			ValidateNotifier(&suite.config, suite.validator)
+
+			suite.Assert().Len(suite.validator.Warnings(), 0)
+			suite.Require().Len(suite.validator.Errors(), len(tc.expected))
+
+			for i, expected := range tc.expected {
+				suite.Assert().EqualError(suite.validator.Errors()[i], expected)
+			}
+		})
+	}
+}
+
 func TestNotifierSuite(t *testing.T) {
 	suite.Run(t, new(NotifierSuite))
 }
@@ -2,6 +2,7 @@ package validator
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/authelia/authelia/v4/internal/configuration/schema"
 	"github.com/authelia/authelia/v4/internal/utils"
@@ -33,4 +34,56 @@ func ValidatePasswordPolicy(config *schema.PasswordPolicyConfiguration, validato
 			validator.Push(fmt.Errorf(errFmtPasswordPolicyZXCVBNMinScoreInvalid, config.ZXCVBN.MinScore))
 		}
 	}
+
+	if config.Breached.Enabled {
+		validatePasswordPolicyBreached(&config.Breached, validator)
+	}
+
+	if config.History.Enabled {
+		switch {
+		case config.History.Count == 0:
+			config.History.Count = schema.DefaultPasswordPolicyConfiguration.History.Count
+		case config.History.Count < 0:
+			validator.Push(fmt.Errorf(errFmtPasswordPolicyHistoryCountInvalid, config.History.Count))
+		}
+	}
+}
+
+func validatePasswordPolicyBreached(config *schema.PasswordPolicyBreachedParams, validator *schema.StructValidator) {
+	switch config.Mode {
+	case "":
+		config.Mode = schema.DefaultPasswordPolicyConfiguration.Breached.Mode
+	case schema.PasswordPolicyBreachedModeDirectory, schema.PasswordPolicyBreachedModeBloom, schema.PasswordPolicyBreachedModeAPI:
+		break
+	default:
+		validator.Push(fmt.Errorf(errFmtPasswordPolicyBreachedMode, config.Mode, strings.Join(validPasswordPolicyBreachedModes, "', '")))
+	}
+
+	switch config.Mode {
+	case schema.PasswordPolicyBreachedModeDirectory, schema.PasswordPolicyBreachedModeBloom:
+		if config.Path == "" {
+			validator.Push(fmt.Errorf(errFmtPasswordPolicyBreachedPathRequired, config.Mode))
+		}
+	case schema.PasswordPolicyBreachedModeAPI:
+		switch {
+		case config.URL == nil:
+			config.URL = schema.DefaultPasswordPolicyConfiguration.Breached.URL
+		case config.URL.Scheme != schemeHTTP && config.URL.Scheme != schemeHTTPS:
+			validator.Push(fmt.Errorf(errFmtPasswordPolicyBreachedURLScheme, config.URL.Scheme))
+		}
+
+		switch {
+		case config.Timeout < 0:
+			validator.Push(fmt.Errorf(errFmtPasswordPolicyBreachedNegative, "timeout", config.Timeout))
+		case config.Timeout == 0:
+			config.Timeout = schema.DefaultPasswordPolicyConfiguration.Breached.Timeout
+		}
+	}
+
+	switch {
+	case config.Threshold == 0:
+		config.Threshold = schema.DefaultPasswordPolicyConfiguration.Breached.Threshold
+	case config.Threshold < 0:
+		validator.Push(fmt.Errorf(errFmtPasswordPolicyBreachedThresholdInvalid, config.Threshold))
+	}
 }
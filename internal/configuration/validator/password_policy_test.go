@@ -2,7 +2,9 @@ package validator
 
 import (
 	"fmt"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -150,3 +152,121 @@ func TestValidatePasswordPolicy(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatePasswordPolicyBreached(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     schema.PasswordPolicyBreachedParams
+		expected schema.PasswordPolicyBreachedParams
+		errs     []string
+	}{
+		{
+			"ShouldSetDefaults",
+			schema.PasswordPolicyBreachedParams{Enabled: true, Path: "/config/breached"},
+			schema.PasswordPolicyBreachedParams{Enabled: true, Mode: schema.PasswordPolicyBreachedModeDirectory, Path: "/config/breached", Threshold: 1},
+			nil,
+		},
+		{
+			"ShouldSetDefaultsAPI",
+			schema.PasswordPolicyBreachedParams{Enabled: true, Mode: schema.PasswordPolicyBreachedModeAPI},
+			schema.PasswordPolicyBreachedParams{Enabled: true, Mode: schema.PasswordPolicyBreachedModeAPI, URL: schema.DefaultPasswordPolicyConfiguration.Breached.URL, Timeout: time.Second * 5, Threshold: 1},
+			nil,
+		},
+		{
+			"ShouldRaiseErrorPathRequired",
+			schema.PasswordPolicyBreachedParams{Enabled: true, Mode: schema.PasswordPolicyBreachedModeBloom, Threshold: 10},
+			schema.PasswordPolicyBreachedParams{Enabled: true, Mode: schema.PasswordPolicyBreachedModeBloom, Threshold: 10},
+			[]string{
+				"password_policy: breached: option 'path' is required when the mode is 'bloom'",
+			},
+		},
+		{
+			"ShouldRaiseErrorInvalidMode",
+			schema.PasswordPolicyBreachedParams{Enabled: true, Mode: "bad", Threshold: 1},
+			schema.PasswordPolicyBreachedParams{Enabled: true, Mode: "bad", Threshold: 1},
+			[]string{
+				"password_policy: breached: option 'mode' is configured as 'bad' but must be one of the following values: 'directory', 'bloom', 'api'",
+			},
+		},
+		{
+			"ShouldRaiseErrorsAPI",
+			schema.PasswordPolicyBreachedParams{Enabled: true, Mode: schema.PasswordPolicyBreachedModeAPI, URL: &url.URL{Scheme: "ftp", Host: "example.com"}, Timeout: -time.Second, Threshold: -1},
+			schema.PasswordPolicyBreachedParams{Enabled: true, Mode: schema.PasswordPolicyBreachedModeAPI, URL: &url.URL{Scheme: "ftp", Host: "example.com"}, Timeout: -time.Second, Threshold: -1},
+			[]string{
+				"password_policy: breached: option 'url' must have the scheme 'http' or 'https' but it's configured as 'ftp'",
+				"password_policy: breached: option 'timeout' must not be negative but it's configured as '-1s'",
+				"password_policy: breached: option 'threshold' must be greater than 0 but it's configured as -1",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			validator := &schema.StructValidator{}
+
+			config := &schema.PasswordPolicyConfiguration{Breached: tc.have}
+
+			ValidatePasswordPolicy(config, validator)
+
+			assert.Len(t, validator.Warnings(), 0)
+			assert.Equal(t, tc.expected, config.Breached)
+
+			errs := validator.Errors()
+			require.Len(t, errs, len(tc.errs))
+
+			for i, err := range errs {
+				assert.EqualError(t, err, tc.errs[i])
+			}
+		})
+	}
+}
+
+func TestValidatePasswordPolicyHistory(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     schema.PasswordPolicyHistoryParams
+		expected schema.PasswordPolicyHistoryParams
+		errs     []string
+	}{
+		{
+			"ShouldNotSetDefaultsWhenDisabled",
+			schema.PasswordPolicyHistoryParams{},
+			schema.PasswordPolicyHistoryParams{},
+			nil,
+		},
+		{
+			"ShouldSetDefaults",
+			schema.PasswordPolicyHistoryParams{Enabled: true},
+			schema.PasswordPolicyHistoryParams{Enabled: true, Count: 5},
+			nil,
+		},
+		{
+			"ShouldRaiseErrorNegativeCount",
+			schema.PasswordPolicyHistoryParams{Enabled: true, Count: -1},
+			schema.PasswordPolicyHistoryParams{Enabled: true, Count: -1},
+			[]string{
+				"password_policy: history: option 'count' must be greater than 0 but it's configured as -1",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			validator := &schema.StructValidator{}
+
+			config := &schema.PasswordPolicyConfiguration{History: tc.have}
+
+			ValidatePasswordPolicy(config, validator)
+
+			assert.Len(t, validator.Warnings(), 0)
+			assert.Equal(t, tc.expected, config.History)
+
+			errs := validator.Errors()
+			require.Len(t, errs, len(tc.errs))
+
+			for i, err := range errs {
+				assert.EqualError(t, err, tc.errs[i])
+			}
+		})
+	}
+}
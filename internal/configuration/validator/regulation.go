@@ -19,4 +19,10 @@ func ValidateRegulation(config *schema.Configuration, validator *schema.StructVa
 	if config.Regulation.FindTime > config.Regulation.BanTime {
 		validator.Push(fmt.Errorf(errFmtRegulationFindTimeGreaterThanBanTime))
 	}
+
+	// The regulator uses the authentication logs within the ban time so they must not be pruned before then.
+	if retention := config.Storage.Retention; config.Regulation.MaxRetries > 0 && retention.Enabled &&
+		retention.AuthenticationLogs > 0 && retention.AuthenticationLogs < config.Regulation.BanTime {
+		validator.Push(fmt.Errorf(errFmtRegulationBanTimeGreaterThanAuthenticationLogRetention, config.Regulation.BanTime, retention.AuthenticationLogs))
+	}
 }
@@ -52,3 +52,39 @@ func TestShouldRaiseErrorWhenFindTimeLessThanBanTime(t *testing.T) {
 	assert.Len(t, validator.Errors(), 1)
 	assert.EqualError(t, validator.Errors()[0], "regulation: option 'find_time' must be less than or equal to option 'ban_time'")
 }
+
+func TestShouldRaiseErrorWhenAuthenticationLogRetentionLessThanBanTime(t *testing.T) {
+	testCases := []struct {
+		name      string
+		retries   int
+		retention schema.StorageRetentionConfiguration
+		expected  string
+	}{
+		{"ShouldRaiseError", 3, schema.StorageRetentionConfiguration{Enabled: true, AuthenticationLogs: time.Minute}, "regulation: option 'ban_time' must be less than or equal to the storage retention option 'authentication_logs' but it's configured as '1h0m0s' and the retention is configured as '1m0s'"},
+		{"ShouldNotRaiseErrorWhenEqual", 3, schema.StorageRetentionConfiguration{Enabled: true, AuthenticationLogs: time.Hour}, ""},
+		{"ShouldNotRaiseErrorWhenDefault", 3, schema.StorageRetentionConfiguration{Enabled: true}, ""},
+		{"ShouldNotRaiseErrorWhenPruningLogsDisabled", 3, schema.StorageRetentionConfiguration{Enabled: true, AuthenticationLogs: -1}, ""},
+		{"ShouldNotRaiseErrorWhenPruningDisabled", 3, schema.StorageRetentionConfiguration{AuthenticationLogs: time.Minute}, ""},
+		{"ShouldNotRaiseErrorWhenRegulationDisabled", 0, schema.StorageRetentionConfiguration{Enabled: true, AuthenticationLogs: time.Minute}, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			validator := schema.NewStructValidator()
+			config := newDefaultRegulationConfig()
+
+			config.Regulation.MaxRetries = tc.retries
+			config.Regulation.BanTime = time.Hour
+			config.Storage.Retention = tc.retention
+
+			ValidateRegulation(&config, validator)
+
+			if tc.expected == "" {
+				assert.Len(t, validator.Errors(), 0)
+			} else {
+				assert.Len(t, validator.Errors(), 1)
+				assert.EqualError(t, validator.Errors()[0], tc.expected)
+			}
+		})
+	}
+}
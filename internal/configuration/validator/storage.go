@@ -3,6 +3,7 @@ package validator
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/authelia/authelia/v4/internal/configuration/schema"
@@ -10,7 +11,7 @@ import (
 )
 
 // ValidateStorage validates storage configuration.
-func ValidateStorage(config schema.StorageConfiguration, validator *schema.StructValidator) {
+func ValidateStorage(config *schema.StorageConfiguration, validator *schema.StructValidator) {
 	if config.Local == nil && config.MySQL == nil && config.PostgreSQL == nil {
 		validator.Push(errors.New(errStrStorage))
 	}
@@ -24,11 +25,153 @@ func ValidateStorage(config schema.StorageConfiguration, validator *schema.Struc
 		validateLocalStorageConfiguration(config.Local, validator)
 	}
 
-	if config.EncryptionKey == "" {
-		validator.Push(errors.New(errStrStorageEncryptionKeyMustBeProvided))
-	} else if len(config.EncryptionKey) < 20 {
+	keyProvider := config.Encryption.KeyProvider.VaultTransit != nil || config.Encryption.KeyProvider.File != nil
+
+	switch {
+	case config.EncryptionKey == "":
+		if !keyProvider {
+			validator.Push(errors.New(errStrStorageEncryptionKeyMustBeProvided))
+		}
+	case len(config.EncryptionKey) < 20:
 		validator.Push(errors.New(errStrStorageEncryptionKeyTooShort))
 	}
+
+	validateStorageEncryption(&config.Encryption, validator)
+	validateStorageRetention(&config.Retention, validator)
+}
+
+func validateStorageEncryption(config *schema.StorageEncryptionConfiguration, validator *schema.StructValidator) {
+	for i, key := range config.DecryptionKeys {
+		if len(key) < 20 {
+			validator.Push(fmt.Errorf(errFmtStorageEncryptionDecryptionKeyTooShort, i+1))
+		}
+	}
+
+	switch {
+	case config.Rekey.BatchSize == 0:
+		config.Rekey.BatchSize = schema.DefaultStorageEncryptionRekeyConfiguration.BatchSize
+	case config.Rekey.BatchSize < 0:
+		validator.Push(fmt.Errorf(errFmtStorageEncryptionRekeyOptionNegative, "batch_size", strconv.Itoa(config.Rekey.BatchSize)))
+	}
+
+	switch {
+	case config.Rekey.Interval == 0:
+		config.Rekey.Interval = schema.DefaultStorageEncryptionRekeyConfiguration.Interval
+	case config.Rekey.Interval < 0:
+		validator.Push(fmt.Errorf(errFmtStorageEncryptionRekeyOptionNegative, "interval", config.Rekey.Interval))
+	}
+
+	validateStorageEncryptionKeyProvider(&config.KeyProvider, validator)
+}
+
+func validateStorageEncryptionKeyProvider(config *schema.StorageEncryptionKeyProviderConfiguration, validator *schema.StructValidator) {
+	switch {
+	case config.VaultTransit != nil && config.File != nil:
+		validator.Push(errors.New(errStrStorageEncryptionKeyProviderMultiple))
+	case config.VaultTransit != nil:
+		validateStorageEncryptionVaultTransit(config.VaultTransit, validator)
+	case config.File != nil:
+		if config.File.Path == "" {
+			validator.Push(fmt.Errorf(errFmtStorageEncryptionKeyProviderRequired, "file", "path"))
+		}
+	default:
+		return
+	}
+
+	switch {
+	case config.DataKeyCache.Lifetime == 0:
+		config.DataKeyCache.Lifetime = schema.DefaultStorageEncryptionDataKeyCacheConfiguration.Lifetime
+	case config.DataKeyCache.Lifetime < 0:
+		validator.Push(fmt.Errorf(errFmtStorageEncryptionKeyProviderNegative, "data_key_cache", "lifetime", config.DataKeyCache.Lifetime))
+	}
+
+	switch {
+	case config.DataKeyCache.Size == 0:
+		config.DataKeyCache.Size = schema.DefaultStorageEncryptionDataKeyCacheConfiguration.Size
+	case config.DataKeyCache.Size < 0:
+		validator.Push(fmt.Errorf(errFmtStorageEncryptionKeyProviderNegative, "data_key_cache", "size", strconv.Itoa(config.DataKeyCache.Size)))
+	}
+}
+
+func validateStorageEncryptionVaultTransit(config *schema.StorageEncryptionVaultTransitConfiguration, validator *schema.StructValidator) {
+	switch {
+	case config.Address == nil:
+		validator.Push(fmt.Errorf(errFmtStorageEncryptionKeyProviderRequired, "vault_transit", "address"))
+	case config.Address.Scheme != schemeHTTP && config.Address.Scheme != schemeHTTPS:
+		validator.Push(fmt.Errorf(errFmtStorageEncryptionKeyProviderURLScheme, config.Address.Scheme))
+	}
+
+	if config.Token == "" {
+		validator.Push(fmt.Errorf(errFmtStorageEncryptionKeyProviderRequired, "vault_transit", "token"))
+	}
+
+	if config.KeyName == "" {
+		validator.Push(fmt.Errorf(errFmtStorageEncryptionKeyProviderRequired, "vault_transit", "key_name"))
+	}
+
+	if config.Mount == "" {
+		config.Mount = schema.DefaultStorageEncryptionVaultTransitConfiguration.Mount
+	}
+
+	switch {
+	case config.Timeout == 0:
+		config.Timeout = schema.DefaultStorageEncryptionVaultTransitConfiguration.Timeout
+	case config.Timeout < 0:
+		validator.Push(fmt.Errorf(errFmtStorageEncryptionKeyProviderNegative, "vault_transit", "timeout", config.Timeout))
+	}
+
+	if config.TLS == nil {
+		config.TLS = &schema.TLSConfig{}
+	}
+
+	configDefaultTLS := &schema.TLSConfig{
+		MinimumVersion: schema.DefaultStorageEncryptionVaultTransitConfiguration.TLS.MinimumVersion,
+		MaximumVersion: schema.DefaultStorageEncryptionVaultTransitConfiguration.TLS.MaximumVersion,
+	}
+
+	if config.Address != nil {
+		configDefaultTLS.ServerName = config.Address.Hostname()
+	}
+
+	if err := ValidateTLSConfig(config.TLS, configDefaultTLS); err != nil {
+		validator.Push(fmt.Errorf(errFmtStorageEncryptionKeyProviderTLSInvalid, err))
+	}
+}
+
+func validateStorageRetention(config *schema.StorageRetentionConfiguration, validator *schema.StructValidator) {
+	switch {
+	case config.Interval == 0:
+		config.Interval = schema.DefaultStorageRetentionConfiguration.Interval
+	case config.Interval < 0:
+		validator.Push(fmt.Errorf(errFmtStorageRetentionOptionNegative, "interval", config.Interval))
+	}
+
+	switch {
+	case config.BatchSize == 0:
+		config.BatchSize = schema.DefaultStorageRetentionConfiguration.BatchSize
+	case config.BatchSize < 0:
+		validator.Push(fmt.Errorf(errFmtStorageRetentionOptionNegative, "batch_size", strconv.Itoa(config.BatchSize)))
+	}
+
+	if config.AuthenticationLogs == 0 {
+		config.AuthenticationLogs = schema.DefaultStorageRetentionConfiguration.AuthenticationLogs
+	}
+
+	if config.IdentityVerification == 0 {
+		config.IdentityVerification = schema.DefaultStorageRetentionConfiguration.IdentityVerification
+	}
+
+	if config.OAuth2Sessions == 0 {
+		config.OAuth2Sessions = schema.DefaultStorageRetentionConfiguration.OAuth2Sessions
+	}
+
+	if config.OAuth2BlacklistedJTI == 0 {
+		config.OAuth2BlacklistedJTI = schema.DefaultStorageRetentionConfiguration.OAuth2BlacklistedJTI
+	}
+
+	if config.OAuth2ConsentPreConfiguration == 0 {
+		config.OAuth2ConsentPreConfiguration = schema.DefaultStorageRetentionConfiguration.OAuth2ConsentPreConfiguration
+	}
 }
 
 func validateSQLConfiguration(config *schema.SQLStorageConfiguration, validator *schema.StructValidator, provider string) {
@@ -2,7 +2,9 @@ package validator
 
 import (
 	"crypto/tls"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 
@@ -21,6 +23,8 @@ func (suite *StorageSuite) SetupTest() {
 	suite.config.Local = nil
 	suite.config.PostgreSQL = nil
 	suite.config.MySQL = nil
+	suite.config.Encryption = schema.StorageEncryptionConfiguration{}
+	suite.config.Retention = schema.StorageRetentionConfiguration{}
 }
 
 func (suite *StorageSuite) TestShouldValidateOneStorageIsConfigured() {
@@ -28,7 +32,7 @@ func (suite *StorageSuite) TestShouldValidateOneStorageIsConfigured() {
 	suite.config.PostgreSQL = nil
 	suite.config.MySQL = nil
 
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Require().Len(suite.validator.Warnings(), 0)
 	suite.Require().Len(suite.validator.Errors(), 1)
@@ -40,7 +44,7 @@ func (suite *StorageSuite) TestShouldValidateLocalPathIsProvided() {
 		Path: "",
 	}
 
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Require().Len(suite.validator.Warnings(), 0)
 	suite.Require().Len(suite.validator.Errors(), 1)
@@ -50,7 +54,7 @@ func (suite *StorageSuite) TestShouldValidateLocalPathIsProvided() {
 	suite.validator.Clear()
 	suite.config.Local.Path = "/myapth"
 
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Require().Len(suite.validator.Warnings(), 0)
 	suite.Require().Len(suite.validator.Errors(), 0)
@@ -58,7 +62,7 @@ func (suite *StorageSuite) TestShouldValidateLocalPathIsProvided() {
 
 func (suite *StorageSuite) TestShouldValidateMySQLHostUsernamePasswordAndDatabaseAreProvided() {
 	suite.config.MySQL = &schema.MySQLStorageConfiguration{}
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Require().Len(suite.validator.Errors(), 3)
 	suite.Assert().EqualError(suite.validator.Errors()[0], "storage: mysql: option 'host' is required")
@@ -74,7 +78,7 @@ func (suite *StorageSuite) TestShouldValidateMySQLHostUsernamePasswordAndDatabas
 			Database: "database",
 		},
 	}
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Require().Len(suite.validator.Warnings(), 0)
 	suite.Require().Len(suite.validator.Errors(), 0)
@@ -93,7 +97,7 @@ func (suite *StorageSuite) TestShouldSetDefaultMySQLTLSServerName() {
 		},
 	}
 
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Assert().Len(suite.validator.Warnings(), 0)
 	suite.Assert().Len(suite.validator.Errors(), 0)
@@ -114,7 +118,7 @@ func (suite *StorageSuite) TestShouldRaiseErrorOnInvalidMySQLTLSVersion() {
 		},
 	}
 
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Assert().Len(suite.validator.Warnings(), 0)
 	suite.Require().Len(suite.validator.Errors(), 1)
@@ -136,7 +140,7 @@ func (suite *StorageSuite) TestShouldRaiseErrorOnInvalidMySQLTLSMinVersionGreate
 		},
 	}
 
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Assert().Len(suite.validator.Warnings(), 0)
 	suite.Require().Len(suite.validator.Errors(), 1)
@@ -147,7 +151,7 @@ func (suite *StorageSuite) TestShouldRaiseErrorOnInvalidMySQLTLSMinVersionGreate
 func (suite *StorageSuite) TestShouldValidatePostgreSQLHostUsernamePasswordAndDatabaseAreProvided() {
 	suite.config.PostgreSQL = &schema.PostgreSQLStorageConfiguration{}
 	suite.config.MySQL = nil
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Require().Len(suite.validator.Errors(), 3)
 	suite.Assert().EqualError(suite.validator.Errors()[0], "storage: postgres: option 'host' is required")
@@ -163,7 +167,7 @@ func (suite *StorageSuite) TestShouldValidatePostgreSQLHostUsernamePasswordAndDa
 			Database: "database",
 		},
 	}
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Assert().Len(suite.validator.Warnings(), 0)
 	suite.Assert().Len(suite.validator.Errors(), 0)
@@ -179,7 +183,7 @@ func (suite *StorageSuite) TestShouldValidatePostgresSchemaDefault() {
 		},
 	}
 
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Assert().Len(suite.validator.Warnings(), 0)
 	suite.Assert().Len(suite.validator.Errors(), 0)
@@ -201,7 +205,7 @@ func (suite *StorageSuite) TestShouldValidatePostgresTLSDefaults() {
 		TLS: &schema.TLSConfig{},
 	}
 
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Assert().Len(suite.validator.Warnings(), 0)
 	suite.Assert().Len(suite.validator.Errors(), 0)
@@ -225,7 +229,7 @@ func (suite *StorageSuite) TestShouldSetDefaultPostgreSQLTLSServerName() {
 		},
 	}
 
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Assert().Len(suite.validator.Warnings(), 0)
 	suite.Assert().Len(suite.validator.Errors(), 0)
@@ -246,7 +250,7 @@ func (suite *StorageSuite) TestShouldRaiseErrorOnInvalidPostgreSQLTLSVersion() {
 		},
 	}
 
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Assert().Len(suite.validator.Warnings(), 0)
 	suite.Require().Len(suite.validator.Errors(), 1)
@@ -268,7 +272,7 @@ func (suite *StorageSuite) TestShouldRaiseErrorOnInvalidPostgreSQLMinVersionGrea
 		},
 	}
 
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Assert().Len(suite.validator.Warnings(), 0)
 	suite.Require().Len(suite.validator.Errors(), 1)
@@ -287,7 +291,7 @@ func (suite *StorageSuite) TestShouldValidatePostgresSSLDefaults() {
 		SSL: &schema.PostgreSQLSSLStorageConfiguration{},
 	}
 
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Assert().Len(suite.validator.Warnings(), 1)
 	suite.Assert().Len(suite.validator.Errors(), 0)
@@ -310,7 +314,7 @@ func (suite *StorageSuite) TestShouldRaiseErrorOnTLSAndLegacySSL() {
 		TLS: &schema.TLSConfig{},
 	}
 
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Assert().Len(suite.validator.Warnings(), 0)
 	suite.Require().Len(suite.validator.Errors(), 1)
@@ -332,7 +336,7 @@ func (suite *StorageSuite) TestShouldValidatePostgresDefaultsDontOverrideConfigu
 		},
 	}
 
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Require().Len(suite.validator.Warnings(), 1)
 	suite.Assert().Len(suite.validator.Errors(), 0)
@@ -356,7 +360,7 @@ func (suite *StorageSuite) TestShouldValidatePostgresSSLModeMustBeValid() {
 		},
 	}
 
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Assert().Len(suite.validator.Warnings(), 1)
 	suite.Require().Len(suite.validator.Errors(), 1)
@@ -369,7 +373,7 @@ func (suite *StorageSuite) TestShouldRaiseErrorOnNoEncryptionKey() {
 		Path: "/this/is/a/path",
 	}
 
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Require().Len(suite.validator.Warnings(), 0)
 	suite.Require().Len(suite.validator.Errors(), 1)
@@ -382,13 +386,211 @@ func (suite *StorageSuite) TestShouldRaiseErrorOnShortEncryptionKey() {
 		Path: "/this/is/a/path",
 	}
 
-	ValidateStorage(suite.config, suite.validator)
+	ValidateStorage(&suite.config, suite.validator)
 
 	suite.Require().Len(suite.validator.Warnings(), 0)
 	suite.Require().Len(suite.validator.Errors(), 1)
 	suite.Assert().EqualError(suite.validator.Errors()[0], "storage: option 'encryption_key' must be 20 characters or longer")
 }
 
+func (suite *StorageSuite) TestShouldSetDefaultEncryptionRekeyValues() {
+	suite.config.Local = &schema.LocalStorageConfiguration{
+		Path: "/this/is/a/path",
+	}
+
+	ValidateStorage(&suite.config, suite.validator)
+
+	suite.Require().Len(suite.validator.Warnings(), 0)
+	suite.Require().Len(suite.validator.Errors(), 0)
+
+	suite.Assert().Equal(schema.DefaultStorageEncryptionRekeyConfiguration.BatchSize, suite.config.Encryption.Rekey.BatchSize)
+	suite.Assert().Equal(schema.DefaultStorageEncryptionRekeyConfiguration.Interval, suite.config.Encryption.Rekey.Interval)
+}
+
+func (suite *StorageSuite) TestShouldRaiseErrorOnInvalidEncryptionOptions() {
+	suite.config.Local = &schema.LocalStorageConfiguration{
+		Path: "/this/is/a/path",
+	}
+
+	suite.config.Encryption = schema.StorageEncryptionConfiguration{
+		DecryptionKeys: []string{"an_old_encryption_key_which_is_long", "abc"},
+		Rekey: schema.StorageEncryptionRekeyConfiguration{
+			BatchSize: -1,
+			Interval:  -time.Second,
+		},
+	}
+
+	ValidateStorage(&suite.config, suite.validator)
+
+	suite.Require().Len(suite.validator.Warnings(), 0)
+	suite.Require().Len(suite.validator.Errors(), 3)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "storage: encryption: option 'decryption_keys' must only contain keys which are 20 characters or longer but the key at position 2 is shorter")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "storage: encryption: rekey: option 'batch_size' must be more than 0 but it's configured as '-1'")
+	suite.Assert().EqualError(suite.validator.Errors()[2], "storage: encryption: rekey: option 'interval' must be more than 0 but it's configured as '-1s'")
+}
+
+func (suite *StorageSuite) TestShouldNotRequireEncryptionKeyWithKeyProvider() {
+	suite.config.EncryptionKey = ""
+	suite.config.Local = &schema.LocalStorageConfiguration{
+		Path: "/this/is/a/path",
+	}
+
+	suite.config.Encryption.KeyProvider.File = &schema.StorageEncryptionFileKeyProviderConfiguration{
+		Path: "/config/keyring",
+	}
+
+	ValidateStorage(&suite.config, suite.validator)
+
+	suite.Require().Len(suite.validator.Warnings(), 0)
+	suite.Require().Len(suite.validator.Errors(), 0)
+
+	suite.Assert().Equal(schema.DefaultStorageEncryptionDataKeyCacheConfiguration.Lifetime, suite.config.Encryption.KeyProvider.DataKeyCache.Lifetime)
+	suite.Assert().Equal(schema.DefaultStorageEncryptionDataKeyCacheConfiguration.Size, suite.config.Encryption.KeyProvider.DataKeyCache.Size)
+}
+
+func (suite *StorageSuite) TestShouldSetDefaultVaultTransitValues() {
+	suite.config.Local = &schema.LocalStorageConfiguration{
+		Path: "/this/is/a/path",
+	}
+
+	suite.config.Encryption.KeyProvider.VaultTransit = &schema.StorageEncryptionVaultTransitConfiguration{
+		Address: &url.URL{Scheme: schemeHTTPS, Host: "vault.example.com:8200"},
+		Token:   "hvs.token",
+		KeyName: "authelia",
+	}
+
+	ValidateStorage(&suite.config, suite.validator)
+
+	suite.Require().Len(suite.validator.Warnings(), 0)
+	suite.Require().Len(suite.validator.Errors(), 0)
+
+	suite.Assert().Equal("transit", suite.config.Encryption.KeyProvider.VaultTransit.Mount)
+	suite.Assert().Equal(time.Second*5, suite.config.Encryption.KeyProvider.VaultTransit.Timeout)
+	suite.Assert().Equal("vault.example.com", suite.config.Encryption.KeyProvider.VaultTransit.TLS.ServerName)
+	suite.Assert().Equal(uint16(tls.VersionTLS12), suite.config.Encryption.KeyProvider.VaultTransit.TLS.MinimumVersion.Value)
+}
+
+func (suite *StorageSuite) TestShouldRaiseErrorOnInvalidKeyProviderOptions() {
+	suite.config.Local = &schema.LocalStorageConfiguration{
+		Path: "/this/is/a/path",
+	}
+
+	testCases := []struct {
+		name     string
+		have     schema.StorageEncryptionKeyProviderConfiguration
+		expected []string
+	}{
+		{
+			"ShouldRaiseErrorOnMultiple",
+			schema.StorageEncryptionKeyProviderConfiguration{
+				VaultTransit: &schema.StorageEncryptionVaultTransitConfiguration{},
+				File:         &schema.StorageEncryptionFileKeyProviderConfiguration{},
+			},
+			[]string{
+				"storage: encryption: key_provider: only one of the 'vault_transit' and 'file' options can be configured",
+			},
+		},
+		{
+			"ShouldRaiseErrorOnMissingVaultTransitOptions",
+			schema.StorageEncryptionKeyProviderConfiguration{
+				VaultTransit: &schema.StorageEncryptionVaultTransitConfiguration{
+					Timeout: -time.Second,
+				},
+			},
+			[]string{
+				"storage: encryption: key_provider: vault_transit: option 'address' is required",
+				"storage: encryption: key_provider: vault_transit: option 'token' is required",
+				"storage: encryption: key_provider: vault_transit: option 'key_name' is required",
+				"storage: encryption: key_provider: vault_transit: option 'timeout' must be more than 0 but it's configured as '-1s'",
+			},
+		},
+		{
+			"ShouldRaiseErrorOnVaultTransitAddressScheme",
+			schema.StorageEncryptionKeyProviderConfiguration{
+				VaultTransit: &schema.StorageEncryptionVaultTransitConfiguration{
+					Address: &url.URL{Scheme: "tcp", Host: "vault.example.com:8200"},
+					Token:   "hvs.token",
+					KeyName: "authelia",
+				},
+			},
+			[]string{
+				"storage: encryption: key_provider: vault_transit: option 'address' must have the 'http' or 'https' scheme but it's configured as 'tcp'",
+			},
+		},
+		{
+			"ShouldRaiseErrorOnFileOptionsAndNegativeCache",
+			schema.StorageEncryptionKeyProviderConfiguration{
+				File: &schema.StorageEncryptionFileKeyProviderConfiguration{},
+				DataKeyCache: schema.StorageEncryptionDataKeyCacheConfiguration{
+					Lifetime: -time.Minute,
+					Size:     -1,
+				},
+			},
+			[]string{
+				"storage: encryption: key_provider: file: option 'path' is required",
+				"storage: encryption: key_provider: data_key_cache: option 'lifetime' must be more than 0 but it's configured as '-1m0s'",
+				"storage: encryption: key_provider: data_key_cache: option 'size' must be more than 0 but it's configured as '-1'",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.validator = schema.NewStructValidator()
+			suite.config.Encryption = schema.StorageEncryptionConfiguration{KeyProvider: tc.have}
+
+			ValidateStorage(&suite.config, suite.validator)
+
+			suite.Require().Len(suite.validator.Warnings(), 0)
+			suite.Require().Len(suite.validator.Errors(), len(tc.expected))
+
+			for i, expected := range tc.expected {
+				suite.Assert().EqualError(suite.validator.Errors()[i], expected)
+			}
+		})
+	}
+}
+
+func (suite *StorageSuite) TestShouldSetDefaultRetentionValues() {
+	suite.config.Local = &schema.LocalStorageConfiguration{
+		Path: "/this/is/a/path",
+	}
+
+	suite.config.Retention.OAuth2BlacklistedJTI = -1
+
+	ValidateStorage(&suite.config, suite.validator)
+
+	suite.Require().Len(suite.validator.Warnings(), 0)
+	suite.Require().Len(suite.validator.Errors(), 0)
+
+	suite.Assert().False(suite.config.Retention.Enabled)
+	suite.Assert().Equal(schema.DefaultStorageRetentionConfiguration.Interval, suite.config.Retention.Interval)
+	suite.Assert().Equal(schema.DefaultStorageRetentionConfiguration.BatchSize, suite.config.Retention.BatchSize)
+	suite.Assert().Equal(schema.DefaultStorageRetentionConfiguration.AuthenticationLogs, suite.config.Retention.AuthenticationLogs)
+	suite.Assert().Equal(schema.DefaultStorageRetentionConfiguration.IdentityVerification, suite.config.Retention.IdentityVerification)
+	suite.Assert().Equal(schema.DefaultStorageRetentionConfiguration.OAuth2Sessions, suite.config.Retention.OAuth2Sessions)
+	suite.Assert().Equal(time.Duration(-1), suite.config.Retention.OAuth2BlacklistedJTI)
+	suite.Assert().Equal(schema.DefaultStorageRetentionConfiguration.OAuth2ConsentPreConfiguration, suite.config.Retention.OAuth2ConsentPreConfiguration)
+}
+
+func (suite *StorageSuite) TestShouldRaiseErrorOnInvalidRetentionOptions() {
+	suite.config.Local = &schema.LocalStorageConfiguration{
+		Path: "/this/is/a/path",
+	}
+
+	suite.config.Retention = schema.StorageRetentionConfiguration{
+		Interval:  -time.Minute,
+		BatchSize: -1,
+	}
+
+	ValidateStorage(&suite.config, suite.validator)
+
+	suite.Require().Len(suite.validator.Warnings(), 0)
+	suite.Require().Len(suite.validator.Errors(), 2)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "storage: retention: option 'interval' must be more than 0 but it's configured as '-1m0s'")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "storage: retention: option 'batch_size' must be more than 0 but it's configured as '-1'")
+}
+
 func TestShouldRunStorageSuite(t *testing.T) {
 	suite.Run(t, new(StorageSuite))
 }
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"time"
+
 	"github.com/valyala/fasthttp"
 )
 
@@ -67,14 +69,23 @@ const (
 	messageUnableToRegisterOneTimePassword = "Unable to set up one-time passwords." //nolint:gosec
 	messageUnableToRegisterSecurityKey     = "Unable to register your security key."
 	messageUnableToResetPassword           = "Unable to reset your password."
+	messageUnableToChangePassword          = "Unable to change your password."
+	messageSecondFactorRequired            = "A recent second factor authentication is required."
 	messageMFAValidationFailed             = "Authentication failed, please retry later."
 	messagePasswordWeak                    = "Your supplied password does not meet the password policy requirements"
+	messagePasswordReused                  = "Your supplied password has been used recently and does not meet the password policy requirements"
 )
 
 const (
 	workflowOpenIDConnect = "openid_connect"
 )
 
+const (
+	// passwordChangeRequiredLifespan is the amount of time a user has to change their password after performing the
+	// first factor with a password which has expired or must be changed.
+	passwordChangeRequiredLifespan = time.Minute * 5
+)
+
 const (
 	logFmtErrParseRequestBody     = "Failed to parse %s request body: %+v"
 	logFmtErrWriteResponseBody    = "Failed to write %s response body for user '%s': %+v"
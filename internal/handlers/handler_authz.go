@@ -6,6 +6,7 @@ import (
 
 	"github.com/authelia/authelia/v4/internal/authentication"
 	"github.com/authelia/authelia/v4/internal/authorization"
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
 	"github.com/authelia/authelia/v4/internal/middlewares"
 	"github.com/authelia/authelia/v4/internal/session"
 	"github.com/authelia/authelia/v4/internal/utils"
@@ -75,16 +76,23 @@ func (authz *Authz) Handler(ctx *middlewares.AutheliaCtx) {
 	authn.Object = object
 	authn.Method = friendlyMethod(authn.Object.Method)
 
-	ruleHasSubject, required := ctx.Providers.Authorizer.GetRequiredLevel(
+	ruleHasSubject, required, maxAge := ctx.Providers.Authorizer.GetRequiredPolicy(
 		authorization.Subject{
-			Username: authn.Details.Username,
-			Groups:   authn.Details.Groups,
-			IP:       ctx.RemoteIP(),
+			Username:            authn.Details.Username,
+			Groups:              authn.Details.Groups,
+			IP:                  ctx.RemoteIP(),
+			AuthenticationLevel: authn.Level,
 		},
 		object,
 	)
 
-	switch isAuthzResult(authn.Level, required, ruleHasSubject) {
+	result := isAuthzResult(authn.Level, required, ruleHasSubject)
+
+	if result == AuthzResultAuthorized && authz.handleStaleAuthentication(ctx, provider, &authn, required, maxAge) {
+		result = AuthzResultUnauthorized
+	}
+
+	switch result {
 	case AuthzResultForbidden:
 		ctx.Logger.Infof("Access to '%s' is forbidden to user '%s'", object.URL.String(), authn.Username)
 		ctx.ReplyForbidden()
@@ -103,6 +111,43 @@ func (authz *Authz) Handler(ctx *middlewares.AutheliaCtx) {
 	}
 }
 
+// handleStaleAuthentication lowers the authentication level of the session when one of the factors required to access
+// the object was performed longer ago than the maximum authentication age, returning true if it did so.
+func (authz *Authz) handleStaleAuthentication(ctx *middlewares.AutheliaCtx, provider *session.Session, authn *Authn, required authorization.Level, maxAge schema.MaxAuthenticationAge) (stale bool) {
+	if authn.Type != AuthnTypeCookie || maxAge.IsZero() {
+		return false
+	}
+
+	var (
+		userSession session.UserSession
+		err         error
+	)
+
+	if userSession, err = provider.GetSession(ctx.RequestCtx); err != nil {
+		ctx.Logger.WithError(err).Error("Error occurred retrieving user session to check the authentication age")
+
+		return false
+	}
+
+	firstFactor, secondFactor := userSession.StaleFactors(ctx.Clock.Now(), required, maxAge)
+
+	if !firstFactor && !secondFactor {
+		return false
+	}
+
+	ctx.Logger.Infof("Access to '%s' requires user '%s' to authenticate again as the authentication age exceeds the maximum (first factor stale: %t, second factor stale: %t)", authn.Object.URL.String(), authn.Username, firstFactor, secondFactor)
+
+	userSession.SetStaleFactors(firstFactor, secondFactor)
+
+	if err = provider.SaveSession(ctx.RequestCtx, userSession); err != nil {
+		ctx.Logger.WithError(err).Error("Error occurred saving the user session after lowering the authentication level")
+	}
+
+	authn.Level = userSession.AuthenticationLevel
+
+	return true
+}
+
 func (authz *Authz) getAutheliaURL(ctx *middlewares.AutheliaCtx, provider *session.Session) (autheliaURL *url.URL, err error) {
 	if authz.handleGetAutheliaURL == nil {
 		return nil, nil
@@ -324,6 +324,12 @@ func handleVerifyGETAuthnCookieValidate(ctx *middlewares.AutheliaCtx, provider *
 		return true
 	}
 
+	if invalid = ctx.IsSessionPasswordChanged(userSession); invalid {
+		ctx.Logger.Infof("Session for user '%s' was authenticated before the password of the user was changed", userSession.Username)
+
+		return true
+	}
+
 	if username := ctx.Request.Header.PeekBytes(headerSessionUsername); username != nil && !strings.EqualFold(string(username), userSession.Username) {
 		ctx.Logger.Warnf("Session for user '%s' does not match the Session-Username header with value '%s' which could be a sign of a cookie hijack", userSession.Username, username)
 
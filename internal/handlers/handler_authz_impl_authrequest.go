@@ -33,7 +33,7 @@ func handleAuthzGetObjectAuthRequest(ctx *middlewares.AutheliaCtx) (object autho
 		return object, fmt.Errorf("header 'X-Original-Method' with value '%s' has invalid characters", method)
 	}
 
-	return authorization.NewObjectRaw(targetURL, method), nil
+	return authorization.NewObjectRaw(targetURL, method, getRequestHeaders(ctx)), nil
 }
 
 func handleAuthzUnauthorizedAuthRequest(ctx *middlewares.AutheliaCtx, authn *Authn, _ *url.URL) {
@@ -8,10 +8,12 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"github.com/valyala/fasthttp"
 
 	"github.com/authelia/authelia/v4/internal/authentication"
+	"github.com/authelia/authelia/v4/internal/authorization"
 	"github.com/authelia/authelia/v4/internal/configuration/schema"
 	"github.com/authelia/authelia/v4/internal/middlewares"
 	"github.com/authelia/authelia/v4/internal/mocks"
@@ -1519,6 +1521,100 @@ func (s *AuthzSuite) TestShouldNotRedirectRequestsForBypassACLWhenInactiveForToo
 	}
 }
 
+func (s *AuthzSuite) TestShouldLowerAuthenticationLevelWhenAuthenticationAgeExceeded() {
+	if s.setRequest == nil {
+		s.T().Skip()
+	}
+
+	builder := s.Builder()
+
+	builder = builder.WithStrategies(
+		NewCookieSessionAuthnStrategy(-1),
+	)
+
+	authz := builder.Build()
+
+	testCases := []struct {
+		name                                string
+		firstFactorAge, secondFactorAge     time.Duration
+		expected                            authentication.Level
+		expectedFirstFactorReauthentication bool
+	}{
+		{"ShouldAllowFresh", time.Minute, time.Minute, authentication.TwoFactor, false},
+		{"ShouldLowerSecondFactorStale", time.Minute, time.Hour * 2, authentication.OneFactor, false},
+		{"ShouldLowerFirstFactorStale", time.Hour * 13, time.Minute, authentication.NotAuthenticated, true},
+		{"ShouldLowerBothFactorsStale", time.Hour * 13, time.Hour * 2, authentication.NotAuthenticated, false},
+	}
+
+	for _, tc := range testCases {
+		s.T().Run(tc.name, func(t *testing.T) {
+			mock := mocks.NewMockAutheliaCtx(t)
+
+			defer mock.Close()
+
+			mock.Ctx.Clock = &mock.Clock
+
+			mock.Clock.Set(time.Now())
+
+			for i, cookie := range mock.Ctx.Configuration.Session.Cookies {
+				mock.Ctx.Configuration.Session.Cookies[i].AutheliaURL = s.RequireParseRequestURI(fmt.Sprintf("https://auth.%s", cookie.Domain))
+			}
+
+			mock.Ctx.Providers.SessionProvider = session.NewProvider(mock.Ctx.Configuration.Session, nil)
+			mock.Ctx.Providers.Authorizer = authorization.NewAuthorizer(&schema.Configuration{
+				AccessControl: schema.AccessControlConfiguration{
+					DefaultPolicy: "deny",
+					Rules: []schema.ACLRule{
+						{
+							Domains: []string{"two-factor.example.com"},
+							Policy:  "two_factor",
+							MaxAuthenticationAge: schema.MaxAuthenticationAge{
+								FirstFactor:  time.Hour * 12,
+								SecondFactor: time.Hour,
+							},
+						},
+					},
+				},
+			})
+
+			targetURI := s.RequireParseRequestURI("https://two-factor.example.com")
+
+			s.setRequest(mock.Ctx, fasthttp.MethodGet, targetURI, true, false)
+
+			userSession, err := mock.Ctx.GetSession()
+			require.NoError(t, err)
+
+			userSession.Username = testUsername
+			userSession.AuthenticationLevel = authentication.TwoFactor
+			userSession.LastActivity = mock.Clock.Now().Unix()
+			userSession.FirstFactorAuthnTimestamp = mock.Clock.Now().Add(-tc.firstFactorAge).Unix()
+			userSession.SecondFactorAuthnTimestamp = mock.Clock.Now().Add(-tc.secondFactorAge).Unix()
+
+			require.NoError(t, mock.Ctx.SaveSession(userSession))
+
+			authz.Handler(mock.Ctx)
+
+			if tc.expected == authentication.TwoFactor {
+				assert.Equal(t, fasthttp.StatusOK, mock.Ctx.Response.StatusCode())
+			} else {
+				switch s.implementation {
+				case AuthzImplAuthRequest, AuthzImplLegacy:
+					assert.Equal(t, fasthttp.StatusUnauthorized, mock.Ctx.Response.StatusCode())
+				default:
+					assert.Equal(t, fasthttp.StatusFound, mock.Ctx.Response.StatusCode())
+				}
+			}
+
+			userSession, err = mock.Ctx.GetSession()
+			require.NoError(t, err)
+
+			assert.Equal(t, testUsername, userSession.Username)
+			assert.Equal(t, tc.expected, userSession.AuthenticationLevel)
+			assert.Equal(t, tc.expectedFirstFactorReauthentication, userSession.FirstFactorReauthentication)
+		})
+	}
+}
+
 func (s *AuthzSuite) TestShouldFailToParsePortalURL() {
 	if s.setRequest == nil || s.implementation == AuthzImplAuthRequest {
 		s.T().Skip()
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/authelia/authelia/v4/internal/authentication"
@@ -29,6 +30,17 @@ func friendlyUsername(username string) (fusername string) {
 	}
 }
 
+// getRequestHeaders returns the headers of the current request as a http.Header for the purpose of ACL matching.
+func getRequestHeaders(ctx *middlewares.AutheliaCtx) (header http.Header) {
+	header = http.Header{}
+
+	ctx.Request.Header.VisitAll(func(key, value []byte) {
+		header.Add(string(key), string(value))
+	})
+
+	return header
+}
+
 func isAuthzResult(level authentication.Level, required authorization.Level, ruleHasSubject bool) AuthzResult {
 	switch {
 	case required == authorization.Bypass:
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/authelia/authelia/v4/internal/authentication"
+	"github.com/authelia/authelia/v4/internal/middlewares"
+	"github.com/authelia/authelia/v4/internal/regulation"
+	"github.com/authelia/authelia/v4/internal/session"
+	"github.com/authelia/authelia/v4/internal/templates"
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+// ChangePasswordPOST handler for changing the password of a logged in user. The current password of the user is
+// required, and optionally a recent second factor authentication.
+func ChangePasswordPOST(ctx *middlewares.AutheliaCtx) {
+	var (
+		userSession session.UserSession
+		err         error
+	)
+
+	if userSession, err = ctx.GetSession(); err != nil {
+		ctx.Error(fmt.Errorf("error occurred retrieving session for user: %w", err), messageUnableToChangePassword)
+		return
+	}
+
+	config := ctx.Configuration.AuthenticationBackend.PasswordChange
+
+	if config.RequireSecondFactor && !isSecondFactorRecent(ctx, &userSession, config.SecondFactorMaxAge) {
+		ctx.Error(fmt.Errorf("user '%s' has not performed second factor authentication within the last %s", userSession.Username, config.SecondFactorMaxAge), messageSecondFactorRequired)
+		return
+	}
+
+	var requestBody bodyChangePasswordRequest
+
+	if err = ctx.ParseBody(&requestBody); err != nil {
+		ctx.Error(err, messageUnableToChangePassword)
+		return
+	}
+
+	if bannedUntil, err := ctx.Providers.Regulator.Regulate(ctx, userSession.Username); err != nil {
+		if errors.Is(err, regulation.ErrUserIsBanned) {
+			_ = markAuthenticationAttempt(ctx, false, &bannedUntil, userSession.Username, regulation.AuthTypePasswordChange, nil)
+
+			ctx.Error(fmt.Errorf("user '%s' is banned until %s", userSession.Username, bannedUntil), messageAuthenticationFailed)
+
+			return
+		}
+
+		ctx.Error(fmt.Errorf("error occurred performing regulation for user '%s': %w", userSession.Username, err), messageAuthenticationFailed)
+
+		return
+	}
+
+	valid, err := ctx.Providers.UserProvider.CheckUserPassword(userSession.Username, requestBody.OldPassword)
+
+	// A password which has expired or must be changed is still permitted to be changed by this endpoint.
+	if err != nil && !(valid && errors.Is(err, authentication.ErrPasswordChangeRequired)) {
+		_ = markAuthenticationAttempt(ctx, false, nil, userSession.Username, regulation.AuthTypePasswordChange, err)
+
+		ctx.Error(fmt.Errorf("error occurred checking the current password of user '%s': %w", userSession.Username, err), messageAuthenticationFailed)
+
+		return
+	}
+
+	if !valid {
+		_ = markAuthenticationAttempt(ctx, false, nil, userSession.Username, regulation.AuthTypePasswordChange, nil)
+
+		ctx.Error(fmt.Errorf("user '%s' supplied an incorrect current password", userSession.Username), messageAuthenticationFailed)
+
+		return
+	}
+
+	if !ctxCheckPasswordPolicy(ctx, userSession.Username, requestBody.NewPassword, messageUnableToChangePassword) {
+		return
+	}
+
+	if err = ctx.Providers.UserProvider.UpdatePassword(userSession.Username, requestBody.NewPassword); err != nil {
+		switch {
+		case utils.IsStringInSliceContains(err.Error(), ldapPasswordComplexityCodes),
+			utils.IsStringInSliceContains(err.Error(), ldapPasswordComplexityErrors):
+			ctx.Error(err, ldapPasswordComplexityCode)
+		default:
+			ctx.Error(err, messageUnableToChangePassword)
+		}
+
+		return
+	}
+
+	ctxSavePasswordHistory(ctx, userSession.Username, requestBody.NewPassword)
+
+	ctx.Logger.Infof("Password of user '%s' has been changed", userSession.Username)
+
+	// The successful check of the current password is only recorded once the password has been changed so it's never
+	// recorded as a login, and the error is only logged as the password has already been changed at this point.
+	_ = markAuthenticationAttempt(ctx, true, nil, userSession.Username, regulation.AuthTypePasswordChange, nil)
+
+	if config.InvalidateSessions {
+		now := ctx.Clock.Now()
+
+		// The session is updated before the password change is saved so it's never considered to be invalidated by it.
+		userSession.PasswordChangeTimestamp = now.Unix()
+
+		if err = ctx.SaveSession(userSession); err != nil {
+			ctx.Logger.Errorf("Unable to update the password change state of the session for user '%s': %v", userSession.Username, err)
+		}
+
+		ctxSaveUserPasswordChange(ctx, userSession.Username, now)
+	}
+
+	ctxLogEvent(ctx, userSession.Username, templates.TemplateNameEmailEventPasswordChanged, "Password changed successfully", map[string]any{"Action": "Password Change"})
+
+	ctx.ReplyOK()
+}
+
+// ctxSaveUserPasswordChange saves the time the password of the user was changed if sessions are invalidated when a
+// password is changed. Errors are only logged as the password has already been changed at this point.
+func ctxSaveUserPasswordChange(ctx *middlewares.AutheliaCtx, username string, changedAt time.Time) {
+	if !ctx.Configuration.AuthenticationBackend.PasswordChange.InvalidateSessions {
+		return
+	}
+
+	if err := ctx.Providers.StorageProvider.SaveUserPasswordChange(ctx, username, changedAt); err != nil {
+		ctx.Logger.Errorf("Unable to save the password change of user '%s': %+v", username, err)
+
+		return
+	}
+
+	if ctx.Providers.PasswordChanges != nil {
+		ctx.Providers.PasswordChanges.Set(username, changedAt, ctx.Clock.Now())
+	}
+}
+
+// isSecondFactorRecent returns true if the session was elevated by a second factor within the max age.
+func isSecondFactorRecent(ctx *middlewares.AutheliaCtx, userSession *session.UserSession, maxAge time.Duration) bool {
+	if userSession.AuthenticationLevel < authentication.TwoFactor || userSession.SecondFactorAuthnTimestamp == 0 {
+		return false
+	}
+
+	return ctx.Clock.Now().Before(time.Unix(userSession.SecondFactorAuthnTimestamp, 0).Add(maxAge))
+}
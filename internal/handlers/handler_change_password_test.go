@@ -0,0 +1,530 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/v4/internal/authentication"
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/middlewares"
+	"github.com/authelia/authelia/v4/internal/mocks"
+	"github.com/authelia/authelia/v4/internal/model"
+	"github.com/authelia/authelia/v4/internal/regulation"
+	"github.com/authelia/authelia/v4/internal/session"
+	"github.com/authelia/authelia/v4/internal/templates"
+)
+
+func newChangePasswordMock(t *testing.T, config schema.PasswordChangeAuthenticationBackend, setup func(now time.Time, userSession *session.UserSession)) (mock *mocks.MockAutheliaCtx) {
+	mock = mocks.NewMockAutheliaCtx(t)
+
+	mock.Ctx.Clock = &mock.Clock
+	mock.Ctx.Configuration.AuthenticationBackend.PasswordChange = config
+	mock.Ctx.Providers.PasswordPolicy = middlewares.NewPasswordPolicyProvider(schema.PasswordPolicyConfiguration{
+		Standard: schema.PasswordPolicyStandardParams{Enabled: true, MinLength: 8},
+	})
+
+	userSession, err := mock.Ctx.GetSession()
+	require.NoError(t, err)
+
+	userSession.Username = testUsername
+	userSession.AuthenticationLevel = authentication.OneFactor
+	userSession.FirstFactorAuthnTimestamp = mock.Clock.Now().Add(-time.Hour).Unix()
+
+	if setup != nil {
+		setup(mock.Clock.Now(), &userSession)
+	}
+
+	require.NoError(t, mock.Ctx.SaveSession(userSession))
+
+	return mock
+}
+
+func expectChangePasswordAuthenticationLog(mock *mocks.MockAutheliaCtx, successful bool, authType string) *gomock.Call {
+	return mock.StorageMock.
+		EXPECT().
+		AppendAuthenticationLog(mock.Ctx, gomock.Eq(model.AuthenticationAttempt{
+			Username:   testUsername,
+			Successful: successful,
+			Time:       mock.Clock.Now(),
+			Type:       authType,
+			RemoteIP:   model.NewNullIPFromString("0.0.0.0"),
+		})).
+		Return(nil)
+}
+
+func TestChangePasswordPOSTShouldChangePassword(t *testing.T) {
+	testCases := []struct {
+		name       string
+		config     schema.PasswordChangeAuthenticationBackend
+		setup      func(now time.Time, userSession *session.UserSession)
+		invalidate bool
+	}{
+		{
+			"ShouldChangePassword",
+			schema.PasswordChangeAuthenticationBackend{},
+			nil,
+			false,
+		},
+		{
+			"ShouldChangePasswordWithRecentSecondFactor",
+			schema.PasswordChangeAuthenticationBackend{RequireSecondFactor: true, SecondFactorMaxAge: time.Minute * 5},
+			func(now time.Time, userSession *session.UserSession) {
+				userSession.SetTwoFactorTOTP(now.Add(-time.Minute))
+			},
+			false,
+		},
+		{
+			"ShouldChangePasswordAndRecordSessionWhenInvalidatingSessions",
+			schema.PasswordChangeAuthenticationBackend{InvalidateSessions: true},
+			nil,
+			true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := newChangePasswordMock(t, tc.config, tc.setup)
+
+			defer mock.Close()
+
+			calls := []*gomock.Call{
+				mock.UserProviderMock.
+					EXPECT().
+					CheckUserPassword(gomock.Eq(testUsername), gomock.Eq("old-password")).
+					Return(true, nil),
+				mock.UserProviderMock.
+					EXPECT().
+					UpdatePassword(gomock.Eq(testUsername), gomock.Eq("new-password")).
+					Return(nil),
+				expectChangePasswordAuthenticationLog(mock, true, regulation.AuthTypePasswordChange),
+			}
+
+			if tc.invalidate {
+				mock.StorageMock.
+					EXPECT().
+					LoadUserPasswordChange(mock.Ctx, gomock.Eq(testUsername)).
+					Return(time.Time{}, nil).
+					AnyTimes()
+
+				calls = append(calls, mock.StorageMock.
+					EXPECT().
+					SaveUserPasswordChange(mock.Ctx, gomock.Eq(testUsername), gomock.Eq(mock.Clock.Now())).
+					Return(nil))
+			}
+
+			calls = append(calls,
+				mock.UserProviderMock.
+					EXPECT().
+					GetDetails(gomock.Eq(testUsername)).
+					Return(&authentication.UserDetails{Username: testUsername, DisplayName: "John Smith", Emails: []string{"john@example.com"}}, nil),
+				mock.NotifierMock.
+					EXPECT().
+					Send(gomock.Any(), gomock.Any(), gomock.Eq("Password changed successfully"), gomock.Any(), gomock.Any()).
+					Return(nil),
+			)
+
+			gomock.InOrder(calls...)
+
+			mock.Ctx.Request.SetBodyString(`{"old_password":"old-password","new_password":"new-password"}`)
+
+			ChangePasswordPOST(mock.Ctx)
+
+			mock.Assert200OK(t, nil)
+
+			userSession, err := mock.Ctx.GetSession()
+			require.NoError(t, err)
+
+			if tc.invalidate {
+				assert.Equal(t, mock.Clock.Now().Unix(), userSession.PasswordChangeTimestamp)
+			} else {
+				assert.Equal(t, int64(0), userSession.PasswordChangeTimestamp)
+			}
+		})
+	}
+}
+
+func TestChangePasswordPOSTShouldLocalizeNotificationSubject(t *testing.T) {
+	mock := newChangePasswordMock(t, schema.PasswordChangeAuthenticationBackend{}, nil)
+
+	defer mock.Close()
+
+	gomock.InOrder(
+		mock.UserProviderMock.
+			EXPECT().
+			CheckUserPassword(gomock.Eq(testUsername), gomock.Eq("old-password")).
+			Return(true, nil),
+		mock.UserProviderMock.
+			EXPECT().
+			UpdatePassword(gomock.Eq(testUsername), gomock.Eq("new-password")).
+			Return(nil),
+		expectChangePasswordAuthenticationLog(mock, true, regulation.AuthTypePasswordChange),
+		mock.UserProviderMock.
+			EXPECT().
+			GetDetails(gomock.Eq(testUsername)).
+			Return(&authentication.UserDetails{Username: testUsername, DisplayName: "John Smith", Emails: []string{"john@example.com"}}, nil),
+		mock.NotifierMock.
+			EXPECT().
+			Send(gomock.Any(), gomock.Any(), gomock.Eq("Passwort erfolgreich geändert"), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ mail.Address, _ string, et *templates.EmailTemplate, data any) error {
+				assert.Equal(t, "de", et.Locale)
+				assert.Equal(t, "Passwort erfolgreich geändert", data.(templates.EmailEventValues).Title)
+
+				return nil
+			}),
+	)
+
+	mock.Ctx.Request.Header.Set(fasthttp.HeaderAcceptLanguage, "de-DE,de;q=0.9,en;q=0.8")
+	mock.Ctx.Request.SetBodyString(`{"old_password":"old-password","new_password":"new-password"}`)
+
+	ChangePasswordPOST(mock.Ctx)
+
+	mock.Assert200OK(t, nil)
+}
+
+func TestChangePasswordPOSTShouldFail(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   schema.PasswordChangeAuthenticationBackend
+		setup    func(now time.Time, userSession *session.UserSession)
+		body     string
+		expect   func(mock *mocks.MockAutheliaCtx)
+		expected string
+		log      string
+	}{
+		{
+			"ShouldFailWithoutSecondFactor",
+			schema.PasswordChangeAuthenticationBackend{RequireSecondFactor: true, SecondFactorMaxAge: time.Minute * 5},
+			nil,
+			`{"old_password":"old-password","new_password":"new-password"}`,
+			nil,
+			messageSecondFactorRequired,
+			"user 'john' has not performed second factor authentication within the last 5m0s",
+		},
+		{
+			"ShouldFailWithExpiredSecondFactor",
+			schema.PasswordChangeAuthenticationBackend{RequireSecondFactor: true, SecondFactorMaxAge: time.Minute * 5},
+			func(now time.Time, userSession *session.UserSession) {
+				userSession.SetTwoFactorTOTP(now.Add(-time.Minute * 10))
+			},
+			`{"old_password":"old-password","new_password":"new-password"}`,
+			nil,
+			messageSecondFactorRequired,
+			"user 'john' has not performed second factor authentication within the last 5m0s",
+		},
+		{
+			"ShouldFailWithMissingNewPassword",
+			schema.PasswordChangeAuthenticationBackend{},
+			nil,
+			`{"old_password":"old-password"}`,
+			nil,
+			messageUnableToChangePassword,
+			"unable to validate body: new_password: non zero value required",
+		},
+		{
+			"ShouldFailWithIncorrectPassword",
+			schema.PasswordChangeAuthenticationBackend{},
+			nil,
+			`{"old_password":"bad-password","new_password":"new-password"}`,
+			func(mock *mocks.MockAutheliaCtx) {
+				gomock.InOrder(
+					mock.UserProviderMock.
+						EXPECT().
+						CheckUserPassword(gomock.Eq(testUsername), gomock.Eq("bad-password")).
+						Return(false, nil),
+					expectChangePasswordAuthenticationLog(mock, false, regulation.AuthTypePasswordChange),
+				)
+			},
+			messageAuthenticationFailed,
+			"user 'john' supplied an incorrect current password",
+		},
+		{
+			"ShouldFailWithCheckPasswordError",
+			schema.PasswordChangeAuthenticationBackend{},
+			nil,
+			`{"old_password":"old-password","new_password":"new-password"}`,
+			func(mock *mocks.MockAutheliaCtx) {
+				gomock.InOrder(
+					mock.UserProviderMock.
+						EXPECT().
+						CheckUserPassword(gomock.Eq(testUsername), gomock.Eq("old-password")).
+						Return(false, fmt.Errorf("failed")),
+					expectChangePasswordAuthenticationLog(mock, false, regulation.AuthTypePasswordChange),
+				)
+			},
+			messageAuthenticationFailed,
+			"error occurred checking the current password of user 'john': failed",
+		},
+		{
+			"ShouldFailWithWeakPassword",
+			schema.PasswordChangeAuthenticationBackend{},
+			nil,
+			`{"old_password":"old-password","new_password":"weak"}`,
+			func(mock *mocks.MockAutheliaCtx) {
+				gomock.InOrder(
+					mock.UserProviderMock.
+						EXPECT().
+						CheckUserPassword(gomock.Eq(testUsername), gomock.Eq("old-password")).
+						Return(true, nil),
+				)
+			},
+			messagePasswordWeak,
+			"the supplied password does not met the security policy",
+		},
+		{
+			"ShouldFailWithBackendComplexityError",
+			schema.PasswordChangeAuthenticationBackend{},
+			nil,
+			`{"old_password":"old-password","new_password":"new-password"}`,
+			func(mock *mocks.MockAutheliaCtx) {
+				gomock.InOrder(
+					mock.UserProviderMock.
+						EXPECT().
+						CheckUserPassword(gomock.Eq(testUsername), gomock.Eq("old-password")).
+						Return(true, nil),
+					mock.UserProviderMock.
+						EXPECT().
+						UpdatePassword(gomock.Eq(testUsername), gomock.Eq("new-password")).
+						Return(fmt.Errorf("LDAP Result Code 19 \"Constraint Violation\": 0000052D: Constraint violation")),
+				)
+			},
+			ldapPasswordComplexityCode,
+			"LDAP Result Code 19 \"Constraint Violation\": 0000052D: Constraint violation",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := newChangePasswordMock(t, tc.config, tc.setup)
+
+			defer mock.Close()
+
+			if tc.expect != nil {
+				tc.expect(mock)
+			}
+
+			mock.Ctx.Request.SetBodyString(tc.body)
+
+			ChangePasswordPOST(mock.Ctx)
+
+			mock.Assert200KO(t, tc.expected)
+			assert.Equal(t, tc.log, mock.Hook.LastEntry().Message)
+		})
+	}
+}
+
+func TestChangePasswordPOSTShouldFailWhenBreachedPasswordSourceUnavailable(t *testing.T) {
+	mock := newChangePasswordMock(t, schema.PasswordChangeAuthenticationBackend{}, nil)
+
+	defer mock.Close()
+
+	mock.Ctx.Providers.PasswordPolicy = middlewares.NewPasswordPolicyProvider(schema.PasswordPolicyConfiguration{
+		Standard: schema.PasswordPolicyStandardParams{Enabled: true, MinLength: 8},
+		Breached: schema.PasswordPolicyBreachedParams{Enabled: true, Mode: schema.PasswordPolicyBreachedModeBloom, Path: filepath.Join(t.TempDir(), "missing.bloom")},
+	})
+
+	mock.UserProviderMock.
+		EXPECT().
+		CheckUserPassword(gomock.Eq(testUsername), gomock.Eq("old-password")).
+		Return(true, nil)
+
+	mock.Ctx.Request.SetBodyString(`{"old_password":"old-password","new_password":"new-password"}`)
+
+	ChangePasswordPOST(mock.Ctx)
+
+	mock.Assert200KO(t, messageUnableToChangePassword)
+	assert.Contains(t, mock.Hook.LastEntry().Message, "error occurred checking if the password has been breached: ")
+}
+
+func TestIsSessionPasswordChanged(t *testing.T) {
+	testCases := []struct {
+		name     string
+		enable   bool
+		session  func(now time.Time) session.UserSession
+		expect   func(mock *mocks.MockAutheliaCtx)
+		expected bool
+	}{
+		{
+			"ShouldNotCheckWhenDisabled",
+			false,
+			func(now time.Time) session.UserSession {
+				return session.UserSession{Username: testUsername, FirstFactorAuthnTimestamp: now.Add(-time.Hour).Unix()}
+			},
+			nil,
+			false,
+		},
+		{
+			"ShouldNotCheckAnonymous",
+			true,
+			func(now time.Time) session.UserSession {
+				return session.UserSession{}
+			},
+			nil,
+			false,
+		},
+		{
+			"ShouldInvalidateSessionAuthenticatedBeforeChange",
+			true,
+			func(now time.Time) session.UserSession {
+				return session.UserSession{Username: testUsername, FirstFactorAuthnTimestamp: now.Add(-time.Hour).Unix()}
+			},
+			func(mock *mocks.MockAutheliaCtx) {
+				mock.StorageMock.
+					EXPECT().
+					LoadUserPasswordChange(mock.Ctx, gomock.Eq(testUsername)).
+					Return(mock.Clock.Now().Add(-time.Minute), nil)
+			},
+			true,
+		},
+		{
+			"ShouldNotInvalidateSessionAuthenticatedAfterChange",
+			true,
+			func(now time.Time) session.UserSession {
+				return session.UserSession{Username: testUsername, FirstFactorAuthnTimestamp: now.Unix()}
+			},
+			func(mock *mocks.MockAutheliaCtx) {
+				mock.StorageMock.
+					EXPECT().
+					LoadUserPasswordChange(mock.Ctx, gomock.Eq(testUsername)).
+					Return(mock.Clock.Now().Add(-time.Minute), nil)
+			},
+			false,
+		},
+		{
+			"ShouldNotInvalidateSessionWhichChangedThePassword",
+			true,
+			func(now time.Time) session.UserSession {
+				return session.UserSession{Username: testUsername, FirstFactorAuthnTimestamp: now.Add(-time.Hour).Unix(), PasswordChangeTimestamp: now.Add(-time.Minute).Unix()}
+			},
+			func(mock *mocks.MockAutheliaCtx) {
+				mock.StorageMock.
+					EXPECT().
+					LoadUserPasswordChange(mock.Ctx, gomock.Eq(testUsername)).
+					Return(mock.Clock.Now().Add(-time.Minute), nil)
+			},
+			false,
+		},
+		{
+			"ShouldNotInvalidateSessionWithoutChange",
+			true,
+			func(now time.Time) session.UserSession {
+				return session.UserSession{Username: testUsername, FirstFactorAuthnTimestamp: now.Add(-time.Hour).Unix()}
+			},
+			func(mock *mocks.MockAutheliaCtx) {
+				mock.StorageMock.
+					EXPECT().
+					LoadUserPasswordChange(mock.Ctx, gomock.Eq(testUsername)).
+					Return(time.Time{}, nil)
+			},
+			false,
+		},
+		{
+			"ShouldNotInvalidateSessionOnStorageError",
+			true,
+			func(now time.Time) session.UserSession {
+				return session.UserSession{Username: testUsername, FirstFactorAuthnTimestamp: now.Add(-time.Hour).Unix()}
+			},
+			func(mock *mocks.MockAutheliaCtx) {
+				mock.StorageMock.
+					EXPECT().
+					LoadUserPasswordChange(mock.Ctx, gomock.Eq(testUsername)).
+					Return(time.Time{}, fmt.Errorf("failed"))
+			},
+			false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := mocks.NewMockAutheliaCtx(t)
+
+			defer mock.Close()
+
+			mock.Ctx.Configuration.AuthenticationBackend.PasswordChange.InvalidateSessions = tc.enable
+
+			if tc.expect != nil {
+				tc.expect(mock)
+			}
+
+			userSession := tc.session(mock.Clock.Now())
+
+			assert.Equal(t, tc.expected, mock.Ctx.IsSessionPasswordChanged(&userSession))
+		})
+	}
+}
+
+func TestIsSessionPasswordChangedShouldCache(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+
+	defer mock.Close()
+
+	mock.Ctx.Clock = &mock.Clock
+	mock.Ctx.Configuration.AuthenticationBackend.PasswordChange.InvalidateSessions = true
+	mock.Ctx.Providers.PasswordChanges = middlewares.NewPasswordChangeCache(time.Minute)
+
+	now := mock.Clock.Now()
+
+	userSession := session.UserSession{Username: testUsername, FirstFactorAuthnTimestamp: now.Add(-time.Hour).Unix()}
+
+	gomock.InOrder(
+		mock.StorageMock.
+			EXPECT().
+			LoadUserPasswordChange(mock.Ctx, gomock.Eq(testUsername)).
+			Return(time.Time{}, nil),
+		mock.StorageMock.
+			EXPECT().
+			LoadUserPasswordChange(mock.Ctx, gomock.Eq(testUsername)).
+			Return(now.Add(-time.Minute*30), nil),
+		mock.StorageMock.
+			EXPECT().
+			LoadUserPasswordChange(mock.Ctx, gomock.Eq(testUsername)).
+			Return(time.Time{}, fmt.Errorf("failed")),
+	)
+
+	assert.False(t, mock.Ctx.IsSessionPasswordChanged(&userSession))
+	assert.False(t, mock.Ctx.IsSessionPasswordChanged(&userSession))
+
+	mock.Clock.Set(now.Add(time.Minute))
+
+	assert.True(t, mock.Ctx.IsSessionPasswordChanged(&userSession))
+	assert.True(t, mock.Ctx.IsSessionPasswordChanged(&userSession))
+
+	mock.Clock.Set(now.Add(time.Minute * 2))
+
+	assert.True(t, mock.Ctx.IsSessionPasswordChanged(&userSession))
+
+	mock.Ctx.Providers.PasswordChanges.Set("harry", now, mock.Clock.Now())
+
+	assert.True(t, mock.Ctx.IsSessionPasswordChanged(&session.UserSession{Username: "harry", FirstFactorAuthnTimestamp: now.Add(-time.Hour).Unix()}))
+}
+
+func TestShouldDestroySessionAuthenticatedBeforePasswordChange(t *testing.T) {
+	mock := newChangePasswordMock(t, schema.PasswordChangeAuthenticationBackend{InvalidateSessions: true}, nil)
+
+	defer mock.Close()
+
+	mock.StorageMock.
+		EXPECT().
+		LoadUserPasswordChange(mock.Ctx, gomock.Eq(testUsername)).
+		Return(mock.Clock.Now().Add(-time.Minute), nil)
+
+	userSession, err := mock.Ctx.GetSession()
+	require.NoError(t, err)
+
+	assert.Equal(t, "", userSession.Username)
+	assert.Equal(t, authentication.NotAuthenticated, userSession.AuthenticationLevel)
+
+	mock.Ctx.Request.SetBodyString(`{"old_password":"old-password","new_password":"new-password"}`)
+
+	middlewares.Require1FA(ChangePasswordPOST)(mock.Ctx)
+
+	assert.Equal(t, fasthttp.StatusForbidden, mock.Ctx.Response.StatusCode())
+}
@@ -4,9 +4,13 @@ import (
 	"errors"
 	"time"
 
+	"github.com/authelia/authelia/v4/internal/authentication"
 	"github.com/authelia/authelia/v4/internal/configuration/schema"
 	"github.com/authelia/authelia/v4/internal/middlewares"
+	"github.com/authelia/authelia/v4/internal/model"
 	"github.com/authelia/authelia/v4/internal/regulation"
+	"github.com/authelia/authelia/v4/internal/session"
+	"github.com/authelia/authelia/v4/internal/templates"
 	"github.com/authelia/authelia/v4/internal/utils"
 )
 
@@ -50,7 +54,11 @@ func FirstFactorPOST(delayFunc middlewares.TimingAttackDelayFunc) middlewares.Re
 		}
 
 		userPasswordOk, err := ctx.Providers.UserProvider.CheckUserPassword(bodyJSON.Username, bodyJSON.Password)
-		if err != nil {
+
+		// The password was correct but must be changed before the session can be elevated.
+		passwordChangeRequired := userPasswordOk && errors.Is(err, authentication.ErrPasswordChangeRequired)
+
+		if err != nil && !passwordChangeRequired {
 			_ = markAuthenticationAttempt(ctx, false, nil, bodyJSON.Username, regulation.AuthType1FA, err)
 
 			respondUnauthorized(ctx, messageAuthenticationFailed)
@@ -66,12 +74,23 @@ func FirstFactorPOST(delayFunc middlewares.TimingAttackDelayFunc) middlewares.Re
 			return
 		}
 
+		// The history must be checked before the attempt is marked otherwise the attempt itself is considered.
+		newLogin := isEventNotificationEnabled(ctx.Configuration.Notifier.Events, templates.TemplateNameEmailEventNewLogin) &&
+			ctxIsNewLogin(ctx, bodyJSON.Username)
+
 		if err = markAuthenticationAttempt(ctx, true, nil, bodyJSON.Username, regulation.AuthType1FA, nil); err != nil {
 			respondUnauthorized(ctx, messageAuthenticationFailed)
 
 			return
 		}
 
+		if newLogin {
+			ctxLogEvent(ctx, bodyJSON.Username, templates.TemplateNameEmailEventNewLogin, "New Sign In", map[string]any{
+				"Action":     "New Sign In",
+				"User Agent": regulation.NormalizeUserAgent(ctx.UserAgent()),
+			})
+		}
+
 		// TODO: write tests.
 		provider, err := ctx.GetSessionProvider()
 		if err != nil {
@@ -110,6 +129,12 @@ func FirstFactorPOST(delayFunc middlewares.TimingAttackDelayFunc) middlewares.Re
 			return
 		}
 
+		if passwordChangeRequired {
+			successful = handleFirstFactorPasswordChangeRequired(ctx, bodyJSON.Username)
+
+			return
+		}
+
 		// Check if bodyJSON.KeepMeLoggedIn can be deref'd and derive the value based on the configuration and JSON data.
 		keepMeLoggedIn := !provider.Config.DisableRememberMe && bodyJSON.KeepMeLoggedIn != nil && *bodyJSON.KeepMeLoggedIn
 
@@ -161,6 +186,49 @@ func FirstFactorPOST(delayFunc middlewares.TimingAttackDelayFunc) middlewares.Re
 	}
 }
 
+// handleFirstFactorPasswordChangeRequired records the required password change in the session without elevating it and
+// responds with the password change required response.
+func handleFirstFactorPasswordChangeRequired(ctx *middlewares.AutheliaCtx, username string) (successful bool) {
+	details, err := ctx.Providers.UserProvider.GetDetails(username)
+	if err != nil {
+		ctx.Logger.Errorf(logFmtErrObtainProfileDetails, regulation.AuthType1FA, username, err)
+
+		respondUnauthorized(ctx, messageAuthenticationFailed)
+
+		return false
+	}
+
+	userSession, err := ctx.GetSession()
+	if err != nil {
+		ctx.Logger.Errorf("%s", err)
+
+		respondUnauthorized(ctx, messageAuthenticationFailed)
+
+		return false
+	}
+
+	userSession.PasswordChangeRequired = &session.PasswordChangeRequired{
+		Username: details.Username,
+		Expires:  ctx.Clock.Now().Add(passwordChangeRequiredLifespan),
+	}
+
+	if err = ctx.SaveSession(userSession); err != nil {
+		ctx.Logger.Errorf(logFmtErrSessionSave, "password change state", regulation.AuthType1FA, username, err)
+
+		respondUnauthorized(ctx, messageAuthenticationFailed)
+
+		return false
+	}
+
+	ctx.Logger.Infof("The password of user '%s' has expired or must be changed before they can login", details.Username)
+
+	if err = ctx.SetJSONBody(passwordChangeRequiredResponse{PasswordChangeRequired: true}); err != nil {
+		ctx.Logger.Errorf(logFmtErrWriteResponseBody, regulation.AuthType1FA, username, err)
+	}
+
+	return true
+}
+
 func getProfileRefreshSettings(cfg schema.AuthenticationBackend) (refresh bool, refreshInterval time.Duration) {
 	if cfg.LDAP != nil {
 		if cfg.RefreshInterval == schema.ProfileRefreshDisabled {
@@ -179,3 +247,25 @@ func getProfileRefreshSettings(cfg schema.AuthenticationBackend) (refresh bool,
 
 	return refresh, refreshInterval
 }
+
+// ctxIsNewLogin returns true if the user has previously authenticated with the first factor but never from the remote IP
+// or never with the user agent. The user agent is only considered if it has been recorded for a previous authentication
+// so authentications recorded before the user agent was recorded are not considered new.
+func ctxIsNewLogin(ctx *middlewares.AutheliaCtx, username string) bool {
+	history, err := ctx.Providers.StorageProvider.LoadAuthenticationHistory(ctx, username, regulation.AuthType1FA,
+		model.NewNullIP(ctx.RemoteIP()), regulation.NormalizeUserAgent(ctx.UserAgent()))
+	if err != nil {
+		ctx.Logger.Errorf("Unable to determine if the %s authentication attempt by user '%s' is from a new location or device: %+v", regulation.AuthType1FA, username, err)
+
+		return false
+	}
+
+	switch {
+	case history.Successful == 0:
+		return false
+	case history.RemoteIP == 0:
+		return true
+	default:
+		return history.UserAgentRecorded != 0 && history.UserAgent == 0
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/authelia/authelia/v4/internal/middlewares"
+	"github.com/authelia/authelia/v4/internal/session"
+	"github.com/authelia/authelia/v4/internal/templates"
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+// FirstFactorPasswordChangePOST handler for changing passwords which have expired or must be changed after the first
+// factor. The session is not elevated, the user is expected to perform the first factor again with the new password.
+func FirstFactorPasswordChangePOST(ctx *middlewares.AutheliaCtx) {
+	var (
+		userSession session.UserSession
+		err         error
+	)
+
+	if userSession, err = ctx.GetSession(); err != nil {
+		ctx.Error(fmt.Errorf("error occurred retrieving session for user: %w", err), messageUnableToChangePassword)
+		return
+	}
+
+	if userSession.PasswordChangeRequired == nil {
+		ctx.Error(fmt.Errorf("no password change is required for this session"), messageUnableToChangePassword)
+		return
+	}
+
+	username := userSession.PasswordChangeRequired.Username
+
+	if !ctx.Clock.Now().Before(userSession.PasswordChangeRequired.Expires) {
+		userSession.PasswordChangeRequired = nil
+
+		if err = ctx.SaveSession(userSession); err != nil {
+			ctx.Logger.Errorf("Unable to clear the expired password change state of user %s: %v", username, err)
+		}
+
+		ctx.Error(fmt.Errorf("the required password change of user %s has expired", username), messageUnableToChangePassword)
+
+		return
+	}
+
+	var requestBody bodyFirstFactorPasswordChangeRequest
+
+	if err = ctx.ParseBody(&requestBody); err != nil {
+		ctx.Error(err, messageUnableToChangePassword)
+		return
+	}
+
+	if !ctxCheckPasswordPolicy(ctx, username, requestBody.Password, messageUnableToChangePassword) {
+		return
+	}
+
+	if err = ctx.Providers.UserProvider.UpdatePassword(username, requestBody.Password); err != nil {
+		switch {
+		case utils.IsStringInSliceContains(err.Error(), ldapPasswordComplexityCodes),
+			utils.IsStringInSliceContains(err.Error(), ldapPasswordComplexityErrors):
+			ctx.Error(err, ldapPasswordComplexityCode)
+		default:
+			ctx.Error(err, messageUnableToChangePassword)
+		}
+
+		return
+	}
+
+	ctxSavePasswordHistory(ctx, username, requestBody.Password)
+	ctxSaveUserPasswordChange(ctx, username, ctx.Clock.Now())
+
+	ctx.Logger.Debugf("Password of user %s has been changed after it expired or was required to be changed", username)
+
+	userSession.PasswordChangeRequired = nil
+
+	if err = ctx.SaveSession(userSession); err != nil {
+		ctx.Error(fmt.Errorf("unable to update password change state: %w", err), messageOperationFailed)
+		return
+	}
+
+	ctx.ReplyOK()
+
+	ctxLogEvent(ctx, username, templates.TemplateNameEmailEventPasswordChanged, "Password changed successfully", map[string]any{"Action": "Required Password Change"})
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/authentication"
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/middlewares"
+	"github.com/authelia/authelia/v4/internal/mocks"
+	"github.com/authelia/authelia/v4/internal/session"
+)
+
+func newFirstFactorPasswordChangeMock(t *testing.T, state func(now time.Time) *session.PasswordChangeRequired) (mock *mocks.MockAutheliaCtx) {
+	mock = mocks.NewMockAutheliaCtx(t)
+
+	mock.Ctx.Clock = &mock.Clock
+	mock.Ctx.Providers.PasswordPolicy = middlewares.NewPasswordPolicyProvider(schema.PasswordPolicyConfiguration{
+		Standard: schema.PasswordPolicyStandardParams{Enabled: true, MinLength: 8},
+	})
+
+	userSession, err := mock.Ctx.GetSession()
+	require.NoError(t, err)
+
+	userSession.PasswordChangeRequired = state(mock.Clock.Now())
+
+	require.NoError(t, mock.Ctx.SaveSession(userSession))
+
+	return mock
+}
+
+func TestFirstFactorPasswordChangePOSTShouldChangePassword(t *testing.T) {
+	mock := newFirstFactorPasswordChangeMock(t, func(now time.Time) *session.PasswordChangeRequired {
+		return &session.PasswordChangeRequired{Username: "john", Expires: now.Add(time.Minute)}
+	})
+
+	defer mock.Close()
+
+	gomock.InOrder(
+		mock.UserProviderMock.
+			EXPECT().
+			UpdatePassword(gomock.Eq("john"), gomock.Eq("new-password")).
+			Return(nil),
+		mock.UserProviderMock.
+			EXPECT().
+			GetDetails(gomock.Eq("john")).
+			Return(&authentication.UserDetails{Username: "john", DisplayName: "John Smith", Emails: []string{"john@example.com"}}, nil),
+		mock.NotifierMock.
+			EXPECT().
+			Send(gomock.Any(), gomock.Any(), gomock.Eq("Password changed successfully"), gomock.Any(), gomock.Any()).
+			Return(nil),
+	)
+
+	mock.Ctx.Request.SetBodyString(`{"password":"new-password"}`)
+
+	FirstFactorPasswordChangePOST(mock.Ctx)
+
+	mock.Assert200OK(t, nil)
+
+	userSession, err := mock.Ctx.GetSession()
+	require.NoError(t, err)
+
+	assert.Nil(t, userSession.PasswordChangeRequired)
+	assert.Equal(t, "", userSession.Username)
+}
+
+func TestFirstFactorPasswordChangePOSTShouldFail(t *testing.T) {
+	testCases := []struct {
+		name     string
+		state    func(now time.Time) *session.PasswordChangeRequired
+		body     string
+		setup    func(mock *mocks.MockAutheliaCtx)
+		expected string
+		log      string
+		cleared  bool
+	}{
+		{
+			"ShouldFailWithoutState",
+			func(now time.Time) *session.PasswordChangeRequired { return nil },
+			`{"password":"new-password"}`,
+			nil,
+			messageUnableToChangePassword,
+			"no password change is required for this session",
+			true,
+		},
+		{
+			"ShouldFailWithExpiredState",
+			func(now time.Time) *session.PasswordChangeRequired {
+				return &session.PasswordChangeRequired{Username: "john", Expires: now}
+			},
+			`{"password":"new-password"}`,
+			nil,
+			messageUnableToChangePassword,
+			"the required password change of user john has expired",
+			true,
+		},
+		{
+			"ShouldFailWithWeakPassword",
+			func(now time.Time) *session.PasswordChangeRequired {
+				return &session.PasswordChangeRequired{Username: "john", Expires: now.Add(time.Minute)}
+			},
+			`{"password":"weak"}`,
+			nil,
+			messagePasswordWeak,
+			"the supplied password does not met the security policy",
+			false,
+		},
+		{
+			"ShouldFailWithBackendComplexityError",
+			func(now time.Time) *session.PasswordChangeRequired {
+				return &session.PasswordChangeRequired{Username: "john", Expires: now.Add(time.Minute)}
+			},
+			`{"password":"new-password"}`,
+			func(mock *mocks.MockAutheliaCtx) {
+				mock.UserProviderMock.
+					EXPECT().
+					UpdatePassword(gomock.Eq("john"), gomock.Eq("new-password")).
+					Return(fmt.Errorf("LDAP Result Code 19 \"Constraint Violation\": 0000052D: Constraint violation"))
+			},
+			ldapPasswordComplexityCode,
+			"LDAP Result Code 19 \"Constraint Violation\": 0000052D: Constraint violation",
+			false,
+		},
+		{
+			"ShouldFailWithBackendError",
+			func(now time.Time) *session.PasswordChangeRequired {
+				return &session.PasswordChangeRequired{Username: "john", Expires: now.Add(time.Minute)}
+			},
+			`{"password":"new-password"}`,
+			func(mock *mocks.MockAutheliaCtx) {
+				mock.UserProviderMock.
+					EXPECT().
+					UpdatePassword(gomock.Eq("john"), gomock.Eq("new-password")).
+					Return(fmt.Errorf("failed"))
+			},
+			messageUnableToChangePassword,
+			"failed",
+			false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := newFirstFactorPasswordChangeMock(t, tc.state)
+
+			defer mock.Close()
+
+			if tc.setup != nil {
+				tc.setup(mock)
+			}
+
+			mock.Ctx.Request.SetBodyString(tc.body)
+
+			FirstFactorPasswordChangePOST(mock.Ctx)
+
+			mock.Assert200KO(t, tc.expected)
+			assert.Equal(t, tc.log, mock.Hook.LastEntry().Message)
+
+			userSession, err := mock.Ctx.GetSession()
+			require.NoError(t, err)
+
+			if tc.cleared {
+				assert.Nil(t, userSession.PasswordChangeRequired)
+			} else {
+				assert.NotNil(t, userSession.PasswordChangeRequired)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -14,6 +15,7 @@ import (
 	"github.com/authelia/authelia/v4/internal/mocks"
 	"github.com/authelia/authelia/v4/internal/model"
 	"github.com/authelia/authelia/v4/internal/regulation"
+	"github.com/authelia/authelia/v4/internal/templates"
 )
 
 type FirstFactorSuite struct {
@@ -135,6 +137,11 @@ func (s *FirstFactorSuite) TestShouldFailIfUserProviderGetDetailsFail() {
 		CheckUserPassword(gomock.Eq("test"), gomock.Eq("hello")).
 		Return(true, nil)
 
+	s.mock.StorageMock.
+		EXPECT().
+		LoadAuthenticationHistory(s.mock.Ctx, gomock.Eq("test"), gomock.Eq(regulation.AuthType1FA), gomock.Any(), gomock.Any()).
+		Return(&model.AuthenticationHistory{}, nil)
+
 	s.mock.StorageMock.
 		EXPECT().
 		AppendAuthenticationLog(s.mock.Ctx, gomock.Any()).
@@ -162,6 +169,11 @@ func (s *FirstFactorSuite) TestShouldFailIfAuthenticationMarkFail() {
 		CheckUserPassword(gomock.Eq("test"), gomock.Eq("hello")).
 		Return(true, nil)
 
+	s.mock.StorageMock.
+		EXPECT().
+		LoadAuthenticationHistory(s.mock.Ctx, gomock.Eq("test"), gomock.Eq(regulation.AuthType1FA), gomock.Any(), gomock.Any()).
+		Return(&model.AuthenticationHistory{}, nil)
+
 	s.mock.StorageMock.
 		EXPECT().
 		AppendAuthenticationLog(s.mock.Ctx, gomock.Any()).
@@ -193,6 +205,11 @@ func (s *FirstFactorSuite) TestShouldAuthenticateUserWithRememberMeChecked() {
 			Groups:   []string{"dev", "admins"},
 		}, nil)
 
+	s.mock.StorageMock.
+		EXPECT().
+		LoadAuthenticationHistory(s.mock.Ctx, gomock.Eq("test"), gomock.Eq(regulation.AuthType1FA), gomock.Any(), gomock.Any()).
+		Return(&model.AuthenticationHistory{}, nil)
+
 	s.mock.StorageMock.
 		EXPECT().
 		AppendAuthenticationLog(s.mock.Ctx, gomock.Any()).
@@ -234,6 +251,11 @@ func (s *FirstFactorSuite) TestShouldAuthenticateUserWithRememberMeUnchecked() {
 			Groups:   []string{"dev", "admins"},
 		}, nil)
 
+	s.mock.StorageMock.
+		EXPECT().
+		LoadAuthenticationHistory(s.mock.Ctx, gomock.Eq("test"), gomock.Eq(regulation.AuthType1FA), gomock.Any(), gomock.Any()).
+		Return(&model.AuthenticationHistory{}, nil)
+
 	s.mock.StorageMock.
 		EXPECT().
 		AppendAuthenticationLog(s.mock.Ctx, gomock.Any()).
@@ -279,6 +301,11 @@ func (s *FirstFactorSuite) TestShouldSaveUsernameFromAuthenticationBackendInSess
 			Groups:   []string{"dev", "admins"},
 		}, nil)
 
+	s.mock.StorageMock.
+		EXPECT().
+		LoadAuthenticationHistory(s.mock.Ctx, gomock.Eq("test"), gomock.Eq(regulation.AuthType1FA), gomock.Any(), gomock.Any()).
+		Return(&model.AuthenticationHistory{}, nil)
+
 	s.mock.StorageMock.
 		EXPECT().
 		AppendAuthenticationLog(s.mock.Ctx, gomock.Any()).
@@ -306,6 +333,237 @@ func (s *FirstFactorSuite) TestShouldSaveUsernameFromAuthenticationBackendInSess
 	assert.Equal(s.T(), []string{"dev", "admins"}, userSession.Groups)
 }
 
+func (s *FirstFactorSuite) TestShouldRequirePasswordChangeWithoutElevatingSession() {
+	s.mock.Ctx.Clock = &s.mock.Clock
+
+	s.mock.UserProviderMock.
+		EXPECT().
+		CheckUserPassword(gomock.Eq("test"), gomock.Eq("hello")).
+		Return(true, fmt.Errorf("the password of user 'test' has expired or must be changed: %w", authentication.ErrPasswordChangeRequired))
+
+	s.mock.UserProviderMock.
+		EXPECT().
+		GetDetails(gomock.Eq("test")).
+		Return(&authentication.UserDetails{
+			Username: "Test",
+			Emails:   []string{"test@example.com"},
+			Groups:   []string{"dev", "admins"},
+		}, nil)
+
+	s.mock.StorageMock.
+		EXPECT().
+		LoadAuthenticationHistory(s.mock.Ctx, gomock.Eq("test"), gomock.Eq(regulation.AuthType1FA), gomock.Any(), gomock.Any()).
+		Return(&model.AuthenticationHistory{}, nil)
+
+	s.mock.StorageMock.
+		EXPECT().
+		AppendAuthenticationLog(s.mock.Ctx, gomock.Eq(model.AuthenticationAttempt{
+			Username:   "test",
+			Successful: true,
+			Banned:     false,
+			Time:       s.mock.Clock.Now(),
+			Type:       regulation.AuthType1FA,
+			RemoteIP:   model.NewNullIPFromString("0.0.0.0"),
+		})).
+		Return(nil)
+
+	s.mock.Ctx.Request.SetBodyString(`{
+		"username": "test",
+		"password": "hello",
+		"keepMeLoggedIn": false
+	}`)
+	FirstFactorPOST(nil)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), passwordChangeRequiredResponse{PasswordChangeRequired: true})
+
+	userSession, err := s.mock.Ctx.GetSession()
+	s.Require().NoError(err)
+
+	assert.Equal(s.T(), "", userSession.Username)
+	assert.Equal(s.T(), authentication.NotAuthenticated, userSession.AuthenticationLevel)
+	s.Require().NotNil(userSession.PasswordChangeRequired)
+	assert.Equal(s.T(), "Test", userSession.PasswordChangeRequired.Username)
+	assert.Equal(s.T(), s.mock.Clock.Now().Add(passwordChangeRequiredLifespan), userSession.PasswordChangeRequired.Expires)
+}
+
+func (s *FirstFactorSuite) TestShouldNotRequirePasswordChangeWhenPasswordInvalid() {
+	s.mock.UserProviderMock.
+		EXPECT().
+		CheckUserPassword(gomock.Eq("test"), gomock.Eq("hello")).
+		Return(false, authentication.ErrPasswordChangeRequired)
+
+	s.mock.StorageMock.
+		EXPECT().
+		AppendAuthenticationLog(s.mock.Ctx, gomock.Any()).
+		Return(nil)
+
+	s.mock.Ctx.Request.SetBodyString(`{
+		"username": "test",
+		"password": "hello",
+		"keepMeLoggedIn": false
+	}`)
+	FirstFactorPOST(nil)(s.mock.Ctx)
+
+	s.mock.Assert401KO(s.T(), "Authentication failed. Check your credentials.")
+
+	userSession, err := s.mock.Ctx.GetSession()
+	s.Require().NoError(err)
+
+	assert.Nil(s.T(), userSession.PasswordChangeRequired)
+}
+
+func (s *FirstFactorSuite) TestShouldNotifyNewLogin() {
+	s.mock.Ctx.Request.Header.SetUserAgent("Mozilla/5.0")
+
+	gomock.InOrder(
+		s.mock.UserProviderMock.
+			EXPECT().
+			CheckUserPassword(gomock.Eq("test"), gomock.Eq("hello")).
+			Return(true, nil),
+		s.mock.StorageMock.
+			EXPECT().
+			LoadAuthenticationHistory(s.mock.Ctx, gomock.Eq("test"), gomock.Eq(regulation.AuthType1FA), gomock.Eq(model.NewNullIPFromString("0.0.0.0")), gomock.Eq("Mozilla/5.0")).
+			Return(&model.AuthenticationHistory{Successful: 2, RemoteIP: 0, UserAgent: 2, UserAgentRecorded: 2}, nil),
+		s.mock.StorageMock.
+			EXPECT().
+			AppendAuthenticationLog(s.mock.Ctx, gomock.Eq(model.AuthenticationAttempt{
+				Username:   "test",
+				Successful: true,
+				Time:       s.mock.Clock.Now(),
+				Type:       regulation.AuthType1FA,
+				RemoteIP:   model.NewNullIPFromString("0.0.0.0"),
+				UserAgent:  "Mozilla/5.0",
+			})).
+			Return(nil),
+		s.mock.UserProviderMock.
+			EXPECT().
+			GetDetails(gomock.Eq("test")).
+			Return(&authentication.UserDetails{Username: "test", Emails: []string{"test@example.com"}}, nil),
+		s.mock.NotifierMock.
+			EXPECT().
+			Send(gomock.Any(), gomock.Any(), gomock.Eq("New Sign In"), gomock.Eq(s.mock.Ctx.Providers.Templates.GetEmailTemplate(templates.TemplateNameEmailEventNewLogin)), gomock.Any()).
+			Return(nil),
+		s.mock.UserProviderMock.
+			EXPECT().
+			GetDetails(gomock.Eq("test")).
+			Return(&authentication.UserDetails{Username: "test", Emails: []string{"test@example.com"}}, nil),
+	)
+
+	s.mock.Ctx.Request.SetBodyString(`{
+		"username": "test",
+		"password": "hello",
+		"keepMeLoggedIn": false
+	}`)
+	FirstFactorPOST(nil)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+}
+
+func (s *FirstFactorSuite) TestShouldNotCheckNewLoginWhenDisabled() {
+	s.mock.Ctx.Configuration.Notifier.Events.DisableNewLogin = true
+
+	gomock.InOrder(
+		s.mock.UserProviderMock.
+			EXPECT().
+			CheckUserPassword(gomock.Eq("test"), gomock.Eq("hello")).
+			Return(true, nil),
+		s.mock.StorageMock.
+			EXPECT().
+			AppendAuthenticationLog(s.mock.Ctx, gomock.Any()).
+			Return(nil),
+		s.mock.UserProviderMock.
+			EXPECT().
+			GetDetails(gomock.Eq("test")).
+			Return(&authentication.UserDetails{Username: "test", Emails: []string{"test@example.com"}}, nil),
+	)
+
+	s.mock.Ctx.Request.SetBodyString(`{
+		"username": "test",
+		"password": "hello",
+		"keepMeLoggedIn": false
+	}`)
+	FirstFactorPOST(nil)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+}
+
+func (s *FirstFactorSuite) TestShouldNotifyUserBanned() {
+	s.mock.Ctx.Configuration.Regulation = schema.RegulationConfiguration{MaxRetries: 2, FindTime: time.Minute, BanTime: time.Hour}
+	s.mock.Ctx.Providers.Regulator = regulation.NewRegulator(s.mock.Ctx.Configuration.Regulation, s.mock.StorageMock, &s.mock.Clock)
+
+	attempts := []model.AuthenticationAttempt{
+		{Username: "test", Successful: false, Time: s.mock.Clock.Now()},
+		{Username: "test", Successful: false, Time: s.mock.Clock.Now().Add(-time.Second)},
+	}
+
+	gomock.InOrder(
+		s.mock.StorageMock.
+			EXPECT().
+			LoadAuthenticationLogs(s.mock.Ctx, gomock.Eq("test"), gomock.Any(), gomock.Eq(10), gomock.Eq(0)).
+			Return(attempts[1:], nil),
+		s.mock.UserProviderMock.
+			EXPECT().
+			CheckUserPassword(gomock.Eq("test"), gomock.Eq("hello")).
+			Return(false, nil),
+		s.mock.StorageMock.
+			EXPECT().
+			AppendAuthenticationLog(s.mock.Ctx, gomock.Any()).
+			Return(nil),
+		s.mock.StorageMock.
+			EXPECT().
+			LoadAuthenticationLogs(s.mock.Ctx, gomock.Eq("test"), gomock.Any(), gomock.Eq(10), gomock.Eq(0)).
+			Return(attempts, nil),
+		s.mock.UserProviderMock.
+			EXPECT().
+			GetDetails(gomock.Eq("test")).
+			Return(&authentication.UserDetails{Username: "test", Emails: []string{"test@example.com"}}, nil),
+		s.mock.NotifierMock.
+			EXPECT().
+			Send(gomock.Any(), gomock.Any(), gomock.Eq("Account Locked"), gomock.Eq(s.mock.Ctx.Providers.Templates.GetEmailTemplate(templates.TemplateNameEmailEventUserBanned)), gomock.Any()).
+			Return(nil),
+	)
+
+	s.mock.Ctx.Request.SetBodyString(`{
+		"username": "test",
+		"password": "hello",
+		"keepMeLoggedIn": false
+	}`)
+	FirstFactorPOST(nil)(s.mock.Ctx)
+
+	s.mock.Assert401KO(s.T(), "Authentication failed. Check your credentials.")
+}
+
+func TestCtxIsNewLogin(t *testing.T) {
+	testCases := []struct {
+		name     string
+		history  *model.AuthenticationHistory
+		err      error
+		expected bool
+	}{
+		{"ShouldNotBeNewFirstLogin", &model.AuthenticationHistory{}, nil, false},
+		{"ShouldBeNewRemoteIP", &model.AuthenticationHistory{Successful: 1, UserAgent: 1, UserAgentRecorded: 1}, nil, true},
+		{"ShouldBeNewUserAgent", &model.AuthenticationHistory{Successful: 1, RemoteIP: 1, UserAgentRecorded: 1}, nil, true},
+		{"ShouldNotBeNewUserAgentNotRecorded", &model.AuthenticationHistory{Successful: 1, RemoteIP: 1}, nil, false},
+		{"ShouldNotBeNewKnown", &model.AuthenticationHistory{Successful: 3, RemoteIP: 2, UserAgent: 1, UserAgentRecorded: 2}, nil, false},
+		{"ShouldNotBeNewError", nil, fmt.Errorf("failed"), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := mocks.NewMockAutheliaCtx(t)
+
+			defer mock.Close()
+
+			mock.StorageMock.
+				EXPECT().
+				LoadAuthenticationHistory(mock.Ctx, gomock.Eq("john"), gomock.Eq(regulation.AuthType1FA), gomock.Any(), gomock.Any()).
+				Return(tc.history, tc.err)
+
+			assert.Equal(t, tc.expected, ctxIsNewLogin(mock.Ctx, "john"))
+		})
+	}
+}
+
 type FirstFactorRedirectionSuite struct {
 	suite.Suite
 
@@ -338,6 +596,11 @@ func (s *FirstFactorRedirectionSuite) SetupTest() {
 			Groups:   []string{"dev", "admins"},
 		}, nil)
 
+	s.mock.StorageMock.
+		EXPECT().
+		LoadAuthenticationHistory(s.mock.Ctx, gomock.Eq("test"), gomock.Eq(regulation.AuthType1FA), gomock.Any(), gomock.Any()).
+		Return(&model.AuthenticationHistory{}, nil)
+
 	s.mock.StorageMock.
 		EXPECT().
 		AppendAuthenticationLog(s.mock.Ctx, gomock.Any()).
@@ -455,6 +718,49 @@ func (s *FirstFactorRedirectionSuite) TestShouldReply200WhenUnsafeTargetURLProvi
 	s.mock.Assert200OK(s.T(), nil)
 }
 
+// When:
+//
+//	1/ two_factor is enabled by a rule with header criteria
+//	2/ the first factor request itself has a matching header
+//
+// Then:
+//
+//	the user should be redirected to the target URL as the header criteria is not evaluated.
+func (s *FirstFactorRedirectionSuite) TestShouldRedirectWhenHeaderRuleRequiresTwoFactor() {
+	s.mock.Ctx.Providers.Authorizer = authorization.NewAuthorizer(&schema.Configuration{
+		AccessControl: schema.AccessControlConfiguration{
+			DefaultPolicy: "one_factor",
+			Rules: []schema.ACLRule{
+				{
+					Domains: []string{"test.example.com"},
+					Headers: [][]schema.ACLQueryRule{
+						{
+							{
+								Operator: "equal",
+								Key:      "X-Tenant",
+								Value:    "abc",
+							},
+						},
+					},
+					Policy: "two_factor",
+				},
+			},
+		}})
+	s.mock.Ctx.Request.Header.Set("X-Tenant", "abc")
+	s.mock.Ctx.Request.SetBodyString(`{
+		"username": "test",
+		"password": "hello",
+		"requestMethod": "GET",
+		"keepMeLoggedIn": false,
+		"targetURL": "https://test.example.com"
+	}`)
+
+	FirstFactorPOST(nil)(s.mock.Ctx)
+
+	// Respond with 200.
+	s.mock.Assert200OK(s.T(), redirectResponse{Redirect: "https://test.example.com"})
+}
+
 func TestFirstFactorSuite(t *testing.T) {
 	suite.Run(t, new(FirstFactorSuite))
 	suite.Run(t, new(FirstFactorRedirectionSuite))
@@ -79,6 +79,20 @@ func OpenIDConnectAuthorization(ctx *middlewares.AutheliaCtx, rw http.ResponseWr
 		return
 	}
 
+	if firstFactor, secondFactor := userSession.StaleFactors(ctx.Clock.Now(), client.Policy, client.MaxAuthenticationAge); firstFactor || secondFactor {
+		ctx.Logger.Infof("Authorization Request with id '%s' on client with id '%s' requires user '%s' to authenticate again as the authentication age exceeds the maximum (first factor stale: %t, second factor stale: %t)", requester.GetID(), client.GetID(), userSession.Username, firstFactor, secondFactor)
+
+		userSession.SetStaleFactors(firstFactor, secondFactor)
+
+		if err = ctx.SaveSession(userSession); err != nil {
+			ctx.Logger.Errorf("Authorization Request with id '%s' on client with id '%s' could not be processed: error occurred saving session information: %+v", requester.GetID(), client.GetID(), err)
+
+			ctx.Providers.OpenIDConnect.WriteAuthorizeError(ctx, rw, requester, fosite.ErrServerError.WithHint("Could not save the user session."))
+
+			return
+		}
+	}
+
 	if consent, handled = handleOIDCAuthorizationConsent(ctx, issuer, client, userSession, rw, r, requester); handled {
 		return
 	}
@@ -38,8 +38,7 @@ func ResetPasswordPOST(ctx *middlewares.AutheliaCtx) {
 		return
 	}
 
-	if err = ctx.Providers.PasswordPolicy.Check(requestBody.Password); err != nil {
-		ctx.Error(err, messagePasswordWeak)
+	if !ctxCheckPasswordPolicy(ctx, username, requestBody.Password, messageUnableToResetPassword) {
 		return
 	}
 
@@ -55,6 +54,9 @@ func ResetPasswordPOST(ctx *middlewares.AutheliaCtx) {
 		return
 	}
 
+	ctxSavePasswordHistory(ctx, username, requestBody.Password)
+	ctxSaveUserPasswordChange(ctx, username, ctx.Clock.Now())
+
 	ctx.Logger.Debugf("Password of user %s has been reset", username)
 
 	// Reset the request.
@@ -65,40 +67,5 @@ func ResetPasswordPOST(ctx *middlewares.AutheliaCtx) {
 		return
 	}
 
-	// Send Notification.
-	userInfo, err := ctx.Providers.UserProvider.GetDetails(username)
-	if err != nil {
-		ctx.Logger.Error(err)
-		ctx.ReplyOK()
-
-		return
-	}
-
-	if len(userInfo.Emails) == 0 {
-		ctx.Logger.Error(fmt.Errorf("user %s has no email address configured", username))
-		ctx.ReplyOK()
-
-		return
-	}
-
-	data := templates.EmailEventValues{
-		Title:       "Password changed successfully",
-		DisplayName: userInfo.DisplayName,
-		RemoteIP:    ctx.RemoteIP().String(),
-		Details: map[string]any{
-			"Action": "Password Reset",
-		},
-	}
-
-	addresses := userInfo.Addresses()
-
-	ctx.Logger.Debugf("Sending an email to user %s (%s) to inform that the password has changed.",
-		username, addresses[0])
-
-	if err = ctx.Providers.Notifier.Send(ctx, addresses[0], "Password changed successfully", ctx.Providers.Templates.GetEventEmailTemplate(), data); err != nil {
-		ctx.Logger.Error(err)
-		ctx.ReplyOK()
-
-		return
-	}
+	ctxLogEvent(ctx, username, templates.TemplateNameEmailEventPasswordChanged, "Password changed successfully", map[string]any{"Action": "Password Reset"})
 }
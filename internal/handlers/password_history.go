@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-crypt/crypt"
+	"github.com/go-crypt/crypt/algorithm"
+
+	"github.com/authelia/authelia/v4/internal/authentication"
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/middlewares"
+	"github.com/authelia/authelia/v4/internal/model"
+)
+
+// ctxCheckPasswordPolicy checks the password against the password policy and the password history of the user. If
+// the password doesn't meet the policy the error is replied and false is returned.
+func ctxCheckPasswordPolicy(ctx *middlewares.AutheliaCtx, username, password, messageUnable string) (ok bool) {
+	var (
+		reused bool
+		err    error
+	)
+
+	if err = ctx.Providers.PasswordPolicy.Check(password); err != nil {
+		var errUnavailable *middlewares.PasswordPolicyUnavailableError
+
+		if errors.As(err, &errUnavailable) {
+			ctx.Error(err, messageUnable)
+		} else {
+			ctx.Error(err, messagePasswordWeak)
+		}
+
+		return false
+	}
+
+	if reused, err = ctxIsPasswordReused(ctx, username, password); err != nil {
+		ctx.Error(err, messageUnable)
+
+		return false
+	}
+
+	if reused {
+		ctx.Error(fmt.Errorf("user '%s' supplied a password which matches one of their previous passwords", username), messagePasswordReused)
+
+		return false
+	}
+
+	return true
+}
+
+// ctxIsPasswordReused returns true if the password history is enabled and the password matches any of the previous
+// passwords of the user, or the current password of the user when the file authentication backend is configured.
+func ctxIsPasswordReused(ctx *middlewares.AutheliaCtx, username, password string) (reused bool, err error) {
+	config := ctx.Configuration.PasswordPolicy.History
+
+	if !config.Enabled {
+		return false, nil
+	}
+
+	var history []model.PasswordHistory
+
+	if history, err = ctx.Providers.StorageProvider.LoadPasswordHistory(ctx, username, config.Count); err != nil {
+		return false, fmt.Errorf("error occurred loading the password history of user '%s': %w", username, err)
+	}
+
+	for _, previous := range history {
+		if reused, err = crypt.CheckPassword(password, previous.Digest); err != nil {
+			return false, fmt.Errorf("error occurred checking the password history of user '%s': %w", username, err)
+		}
+
+		if reused {
+			return true, nil
+		}
+	}
+
+	// The current password may not be in the password history if it was set before the history was enabled. It's only
+	// checked with the file backend as the LDAP backend binds as the user to check it, which the directory server may
+	// count as a failed login. Errors are not returned as most backends return an error when the password doesn't match.
+	if ctx.Configuration.AuthenticationBackend.File == nil {
+		return false, nil
+	}
+
+	if reused, err = ctx.Providers.UserProvider.CheckUserPassword(username, password); reused {
+		return true, nil
+	} else if err != nil {
+		ctx.Logger.WithError(err).Debugf("Current password of user '%s' does not match the supplied password", username)
+	}
+
+	return false, nil
+}
+
+// ctxSavePasswordHistory saves the password to the password history of the user if it's enabled and prunes the
+// history. Errors are only logged as the password has already been changed at this point.
+func ctxSavePasswordHistory(ctx *middlewares.AutheliaCtx, username, password string) {
+	config := ctx.Configuration.PasswordPolicy.History
+
+	if !config.Enabled {
+		return
+	}
+
+	var (
+		hash   algorithm.Hash
+		digest algorithm.Digest
+		err    error
+	)
+
+	if hash, err = authentication.NewFileCryptoHashFromConfig(passwordHistoryHashConfig(ctx)); err != nil {
+		ctx.Logger.Errorf("Unable to save the password history of user '%s': %+v", username, err)
+
+		return
+	}
+
+	if digest, err = hash.Hash(password); err != nil {
+		ctx.Logger.Errorf("Unable to save the password history of user '%s': %+v", username, err)
+
+		return
+	}
+
+	if err = ctx.Providers.StorageProvider.SavePasswordHistory(ctx, model.PasswordHistory{
+		CreatedAt: ctx.Clock.Now(),
+		Username:  username,
+		Digest:    digest.Encode(),
+	}); err != nil {
+		ctx.Logger.Errorf("Unable to save the password history of user '%s': %+v", username, err)
+
+		return
+	}
+
+	if err = ctx.Providers.StorageProvider.PrunePasswordHistory(ctx, username, config.Count); err != nil {
+		ctx.Logger.Errorf("Unable to prune the password history of user '%s': %+v", username, err)
+	}
+}
+
+// passwordHistoryHashConfig returns the password hashing configuration of the file authentication backend if it's
+// configured, otherwise the default password hashing configuration.
+func passwordHistoryHashConfig(ctx *middlewares.AutheliaCtx) schema.Password {
+	if ctx.Configuration.AuthenticationBackend.File != nil {
+		return ctx.Configuration.AuthenticationBackend.File.Password
+	}
+
+	return schema.DefaultPasswordConfig
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-crypt/crypt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/authentication"
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/mocks"
+	"github.com/authelia/authelia/v4/internal/model"
+	"github.com/authelia/authelia/v4/internal/regulation"
+)
+
+var testPasswordHistoryHashConfig = schema.Password{
+	Algorithm: "sha2crypt",
+	SHA2Crypt: schema.SHA2CryptPassword{Variant: "sha512", Iterations: 1000, SaltLength: 16},
+}
+
+func newPasswordHistoryDigest(t *testing.T, password string) string {
+	hash, err := authentication.NewFileCryptoHashFromConfig(testPasswordHistoryHashConfig)
+	require.NoError(t, err)
+
+	digest, err := hash.Hash(password)
+	require.NoError(t, err)
+
+	return digest.Encode()
+}
+
+func newPasswordHistoryMock(t *testing.T) (mock *mocks.MockAutheliaCtx) {
+	mock = newChangePasswordMock(t, schema.PasswordChangeAuthenticationBackend{}, nil)
+
+	mock.Ctx.Configuration.PasswordPolicy.History = schema.PasswordPolicyHistoryParams{Enabled: true, Count: 5}
+	mock.Ctx.Configuration.AuthenticationBackend.File = &schema.FileAuthenticationBackend{Password: testPasswordHistoryHashConfig}
+
+	return mock
+}
+
+func TestChangePasswordPOSTShouldSavePasswordHistory(t *testing.T) {
+	mock := newPasswordHistoryMock(t)
+
+	defer mock.Close()
+
+	gomock.InOrder(
+		mock.UserProviderMock.
+			EXPECT().
+			CheckUserPassword(gomock.Eq(testUsername), gomock.Eq("old-password")).
+			Return(true, nil),
+		mock.StorageMock.
+			EXPECT().
+			LoadPasswordHistory(mock.Ctx, gomock.Eq(testUsername), gomock.Eq(5)).
+			Return([]model.PasswordHistory{{Username: testUsername, Digest: newPasswordHistoryDigest(t, "older-password")}}, nil),
+		mock.UserProviderMock.
+			EXPECT().
+			CheckUserPassword(gomock.Eq(testUsername), gomock.Eq("new-password")).
+			Return(false, fmt.Errorf("authentication failed")),
+		mock.UserProviderMock.
+			EXPECT().
+			UpdatePassword(gomock.Eq(testUsername), gomock.Eq("new-password")).
+			Return(nil),
+		mock.StorageMock.
+			EXPECT().
+			SavePasswordHistory(mock.Ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, history model.PasswordHistory) error {
+				assert.Equal(t, testUsername, history.Username)
+				assert.Equal(t, mock.Clock.Now(), history.CreatedAt)
+
+				valid, err := crypt.CheckPassword("new-password", history.Digest)
+
+				assert.NoError(t, err)
+				assert.True(t, valid)
+
+				return nil
+			}),
+		mock.StorageMock.
+			EXPECT().
+			PrunePasswordHistory(mock.Ctx, gomock.Eq(testUsername), gomock.Eq(5)).
+			Return(nil),
+		expectChangePasswordAuthenticationLog(mock, true, regulation.AuthTypePasswordChange),
+		mock.UserProviderMock.
+			EXPECT().
+			GetDetails(gomock.Eq(testUsername)).
+			Return(&authentication.UserDetails{Username: testUsername, DisplayName: "John Smith", Emails: []string{"john@example.com"}}, nil),
+		mock.NotifierMock.
+			EXPECT().
+			Send(gomock.Any(), gomock.Any(), gomock.Eq("Password changed successfully"), gomock.Any(), gomock.Any()).
+			Return(nil),
+	)
+
+	mock.Ctx.Request.SetBodyString(`{"old_password":"old-password","new_password":"new-password"}`)
+
+	ChangePasswordPOST(mock.Ctx)
+
+	mock.Assert200OK(t, nil)
+}
+
+func TestChangePasswordPOSTShouldNotCheckCurrentPasswordWithLDAP(t *testing.T) {
+	mock := newPasswordHistoryMock(t)
+
+	defer mock.Close()
+
+	mock.Ctx.Configuration.AuthenticationBackend.File = nil
+	mock.Ctx.Configuration.AuthenticationBackend.LDAP = &schema.LDAPAuthenticationBackend{}
+
+	gomock.InOrder(
+		mock.UserProviderMock.
+			EXPECT().
+			CheckUserPassword(gomock.Eq(testUsername), gomock.Eq("old-password")).
+			Return(true, nil),
+		mock.StorageMock.
+			EXPECT().
+			LoadPasswordHistory(mock.Ctx, gomock.Eq(testUsername), gomock.Eq(5)).
+			Return(nil, nil),
+		mock.UserProviderMock.
+			EXPECT().
+			UpdatePassword(gomock.Eq(testUsername), gomock.Eq("new-password")).
+			Return(fmt.Errorf("failed")),
+	)
+
+	mock.Ctx.Request.SetBodyString(`{"old_password":"old-password","new_password":"new-password"}`)
+
+	ChangePasswordPOST(mock.Ctx)
+
+	mock.Assert200KO(t, messageUnableToChangePassword)
+	assert.Equal(t, "failed", mock.Hook.LastEntry().Message)
+}
+
+func TestChangePasswordPOSTShouldRejectReusedPassword(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		history  []model.PasswordHistory
+		err      error
+		current  bool
+		expected string
+		log      string
+	}{
+		{
+			"ShouldRejectPasswordInHistory",
+			`{"old_password":"old-password","new_password":"new-password"}`,
+			[]model.PasswordHistory{
+				{Username: testUsername, Digest: newPasswordHistoryDigest(t, "older-password")},
+				{Username: testUsername, Digest: newPasswordHistoryDigest(t, "new-password")},
+			},
+			nil,
+			false,
+			messagePasswordReused,
+			"user 'john' supplied a password which matches one of their previous passwords",
+		},
+		{
+			"ShouldRejectCurrentPassword",
+			`{"old_password":"old-password","new_password":"old-password"}`,
+			nil,
+			nil,
+			true,
+			messagePasswordReused,
+			"user 'john' supplied a password which matches one of their previous passwords",
+		},
+		{
+			"ShouldFailWhenHistoryCanNotBeLoaded",
+			`{"old_password":"old-password","new_password":"new-password"}`,
+			nil,
+			fmt.Errorf("failed"),
+			false,
+			messageUnableToChangePassword,
+			"error occurred loading the password history of user 'john': failed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := newPasswordHistoryMock(t)
+
+			defer mock.Close()
+
+			calls := []*gomock.Call{
+				mock.UserProviderMock.
+					EXPECT().
+					CheckUserPassword(gomock.Eq(testUsername), gomock.Eq("old-password")).
+					Return(true, nil),
+				mock.StorageMock.
+					EXPECT().
+					LoadPasswordHistory(mock.Ctx, gomock.Eq(testUsername), gomock.Eq(5)).
+					Return(tc.history, tc.err),
+			}
+
+			if tc.current {
+				calls = append(calls, mock.UserProviderMock.
+					EXPECT().
+					CheckUserPassword(gomock.Eq(testUsername), gomock.Eq("old-password")).
+					Return(true, nil))
+			}
+
+			gomock.InOrder(calls...)
+
+			mock.Ctx.Request.SetBodyString(tc.body)
+
+			ChangePasswordPOST(mock.Ctx)
+
+			mock.Assert200KO(t, tc.expected)
+			assert.Equal(t, tc.log, mock.Hook.LastEntry().Message)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"path"
@@ -9,11 +10,14 @@ import (
 	"github.com/google/uuid"
 	"github.com/valyala/fasthttp"
 
+	"github.com/authelia/authelia/v4/internal/authentication"
 	"github.com/authelia/authelia/v4/internal/authorization"
 	"github.com/authelia/authelia/v4/internal/middlewares"
 	"github.com/authelia/authelia/v4/internal/model"
 	"github.com/authelia/authelia/v4/internal/oidc"
+	"github.com/authelia/authelia/v4/internal/regulation"
 	"github.com/authelia/authelia/v4/internal/session"
+	"github.com/authelia/authelia/v4/internal/templates"
 )
 
 // Handle1FAResponse handle the redirection upon 1FA authentication.
@@ -42,11 +46,12 @@ func Handle1FAResponse(ctx *middlewares.AutheliaCtx, targetURI, requestMethod st
 
 	_, requiredLevel := ctx.Providers.Authorizer.GetRequiredLevel(
 		authorization.Subject{
-			Username: username,
-			Groups:   groups,
-			IP:       ctx.RemoteIP(),
+			Username:            username,
+			Groups:              groups,
+			IP:                  ctx.RemoteIP(),
+			AuthenticationLevel: authentication.OneFactor,
 		},
-		authorization.NewObject(targetURL, requestMethod))
+		authorization.NewObject(targetURL, requestMethod, nil))
 
 	ctx.Logger.Debugf("Required level for the URL %s is %d", targetURI, requiredLevel)
 
@@ -274,6 +279,16 @@ func markAuthenticationAttempt(ctx *middlewares.AutheliaCtx, successful bool, ba
 		return err
 	}
 
+	// A ban is the result of this attempt if the user was not banned before it and is banned after it.
+	if !successful && bannedUntil == nil && (authType == regulation.AuthType1FA || authType == regulation.AuthTypePasswordChange) {
+		if until, errBan := ctx.Providers.Regulator.Regulate(ctx, username); errors.Is(errBan, regulation.ErrUserIsBanned) {
+			ctxLogEvent(ctx, username, templates.TemplateNameEmailEventUserBanned, "Account Locked", map[string]any{
+				"Action":       "Account Locked",
+				"Locked Until": until.UTC().Format(time.RFC1123),
+			})
+		}
+	}
+
 	if successful {
 		ctx.Logger.Debugf("Successful %s authentication attempt made by user '%s'", authType, username)
 	} else {
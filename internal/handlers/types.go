@@ -79,6 +79,23 @@ type redirectResponse struct {
 	Redirect string `json:"redirect"`
 }
 
+// passwordChangeRequiredResponse represent the response sent by the first factor endpoint when the password of the user
+// was correct but has expired or must be changed.
+type passwordChangeRequiredResponse struct {
+	PasswordChangeRequired bool `json:"password_change_required"`
+}
+
+// bodyFirstFactorPasswordChangeRequest is the model of the request body of the first factor password change endpoint.
+type bodyFirstFactorPasswordChangeRequest struct {
+	Password string `json:"password" valid:"required"`
+}
+
+// bodyChangePasswordRequest is the model of the request body of the password change endpoint.
+type bodyChangePasswordRequest struct {
+	OldPassword string `json:"old_password" valid:"required"`
+	NewPassword string `json:"new_password" valid:"required"`
+}
+
 // TOTPKeyResponse is the model of response that is sent to the client up successful identity verification.
 type TOTPKeyResponse struct {
 	Base32Secret string `json:"base32_secret"`
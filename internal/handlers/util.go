@@ -1,14 +1,23 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/authelia/authelia/v4/internal/authentication"
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
 	"github.com/authelia/authelia/v4/internal/middlewares"
 	"github.com/authelia/authelia/v4/internal/templates"
 )
 
-func ctxLogEvent(ctx *middlewares.AutheliaCtx, username, description string, eventDetails map[string]any) {
+// ctxLogEvent sends the security event notification with the given template name to the user if the event is enabled.
+func ctxLogEvent(ctx *middlewares.AutheliaCtx, username, event, description string, eventDetails map[string]any) {
+	if !isEventNotificationEnabled(ctx.Configuration.Notifier.Events, event) {
+		ctx.Logger.Debugf("Skipping the %s notification for user %s as it's disabled", event, username)
+
+		return
+	}
+
 	var (
 		details *authentication.UserDetails
 		err     error
@@ -18,7 +27,14 @@ func ctxLogEvent(ctx *middlewares.AutheliaCtx, username, description string, eve
 
 	// Send Notification.
 	if details, err = ctx.Providers.UserProvider.GetDetails(username); err != nil {
+		if errors.Is(err, authentication.ErrUserNotFound) {
+			ctx.Logger.Debugf("Skipping the %s notification as user %s does not exist", event, username)
+
+			return
+		}
+
 		ctx.Logger.Error(err)
+
 		return
 	}
 
@@ -27,8 +43,10 @@ func ctxLogEvent(ctx *middlewares.AutheliaCtx, username, description string, eve
 		return
 	}
 
+	et := ctx.Providers.Templates.GetLocalizedEmailTemplate(event, ctx.AcceptLanguage()...)
+
 	data := templates.EmailEventValues{
-		Title:       description,
+		Title:       et.Translate(description),
 		DisplayName: details.DisplayName,
 		RemoteIP:    ctx.RemoteIP().String(),
 		Details:     eventDetails,
@@ -40,8 +58,28 @@ func ctxLogEvent(ctx *middlewares.AutheliaCtx, username, description string, eve
 
 	ctx.Logger.Debugf("Sending an email to user %s (%s) to inform them of an important event.", username, addresses[0])
 
-	if err = ctx.Providers.Notifier.Send(ctx, addresses[0], description, ctx.Providers.Templates.GetEventEmailTemplate(), data); err != nil {
+	if err = ctx.Providers.Notifier.Send(ctx, addresses[0], data.Title, et, data); err != nil {
 		ctx.Logger.Error(err)
 		return
 	}
 }
+
+// isEventNotificationEnabled returns true if the security event notification with the given template name is enabled.
+func isEventNotificationEnabled(config schema.NotifierEventsConfiguration, event string) bool {
+	switch event {
+	case templates.TemplateNameEmailEventPasswordChanged:
+		return !config.DisablePasswordChanged
+	case templates.TemplateNameEmailEventWebauthnDeviceAdded:
+		return !config.DisableWebauthnDeviceAdded
+	case templates.TemplateNameEmailEventTOTPAdded:
+		return !config.DisableTOTPAdded
+	case templates.TemplateNameEmailEventTOTPRemoved:
+		return !config.DisableTOTPRemoved
+	case templates.TemplateNameEmailEventNewLogin:
+		return !config.DisableNewLogin
+	case templates.TemplateNameEmailEventUserBanned:
+		return !config.DisableUserBanned
+	default:
+		return true
+	}
+}
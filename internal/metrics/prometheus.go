@@ -19,12 +19,13 @@ func NewPrometheus() (provider *Prometheus) {
 
 // Prometheus is a middleware for recording prometheus metrics.
 type Prometheus struct {
-	authnDuration   *prometheus.HistogramVec
-	reqDuration     *prometheus.HistogramVec
-	reqCounter      *prometheus.CounterVec
-	authzCounter    *prometheus.CounterVec
-	authnCounter    *prometheus.CounterVec
-	authn2FACounter *prometheus.CounterVec
+	authnDuration            *prometheus.HistogramVec
+	reqDuration              *prometheus.HistogramVec
+	reqCounter               *prometheus.CounterVec
+	authzCounter             *prometheus.CounterVec
+	authnCounter             *prometheus.CounterVec
+	authn2FACounter          *prometheus.CounterVec
+	authnBackendCacheCounter *prometheus.CounterVec
 }
 
 // RecordRequest takes the statusCode string, requestMethod string, and the elapsed time.Duration to record the request and request duration metrics.
@@ -53,6 +54,11 @@ func (r *Prometheus) RecordAuthenticationDuration(success bool, elapsed time.Dur
 	r.authnDuration.WithLabelValues(strconv.FormatBool(success)).Observe(elapsed.Seconds())
 }
 
+// RecordAuthenticationBackendCache takes the result string to record the authentication backend cache metrics.
+func (r *Prometheus) RecordAuthenticationBackendCache(result string) {
+	r.authnBackendCacheCounter.WithLabelValues(result).Inc()
+}
+
 func (r *Prometheus) register() {
 	r.authnDuration = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -109,4 +115,13 @@ func (r *Prometheus) register() {
 		},
 		[]string{"success", "banned", "type"},
 	)
+
+	r.authnBackendCacheCounter = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "authelia",
+			Name:      "authn_backend_cache",
+			Help:      "The number of authentication backend user details lookups processed by the cache.",
+		},
+		[]string{"result"},
+	)
 }
@@ -8,10 +8,12 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/asaskevich/govalidator"
 	"github.com/sirupsen/logrus"
 	"github.com/valyala/fasthttp"
+	"golang.org/x/text/language"
 
 	"github.com/authelia/authelia/v4/internal/configuration/schema"
 	"github.com/authelia/authelia/v4/internal/logging"
@@ -341,23 +343,65 @@ func (ctx *AutheliaCtx) GetSession() (userSession session.UserSession, err error
 		return provider.NewDefaultUserSession(), nil
 	}
 
-	if userSession.CookieDomain != provider.Config.Domain {
+	switch {
+	case userSession.CookieDomain != provider.Config.Domain:
 		ctx.Logger.Warnf("Destroying session cookie as the cookie domain '%s' does not match the requests detected cookie domain '%s' which may be a sign a user tried to move this cookie from one domain to another", userSession.CookieDomain, provider.Config.Domain)
+	case ctx.IsSessionPasswordChanged(&userSession):
+		ctx.Logger.Infof("Destroying session cookie as the session for user '%s' was authenticated before the password of the user was changed", userSession.Username)
+	default:
+		return userSession, nil
+	}
 
-		if err = provider.DestroySession(ctx.RequestCtx); err != nil {
-			ctx.Logger.WithError(err).Error("Error occurred trying to destroy the session cookie")
-		}
+	if err = provider.DestroySession(ctx.RequestCtx); err != nil {
+		ctx.Logger.WithError(err).Error("Error occurred trying to destroy the session cookie")
+	}
 
-		userSession = provider.NewDefaultUserSession()
+	userSession = provider.NewDefaultUserSession()
 
-		if err = provider.SaveSession(ctx.RequestCtx, userSession); err != nil {
-			ctx.Logger.WithError(err).Error("Error occurred trying to save the new session cookie")
-		}
+	if err = provider.SaveSession(ctx.RequestCtx, userSession); err != nil {
+		ctx.Logger.WithError(err).Error("Error occurred trying to save the new session cookie")
 	}
 
 	return userSession, nil
 }
 
+// IsSessionPasswordChanged returns true if sessions are invalidated when a password is changed and the password of the
+// user was changed after the user session was authenticated. The password change made using the user session itself
+// does not invalidate it. The time of the last password change is cached by the PasswordChanges provider if it's
+// configured.
+func (ctx *AutheliaCtx) IsSessionPasswordChanged(userSession *session.UserSession) (changed bool) {
+	if userSession.Username == "" || !ctx.Configuration.AuthenticationBackend.PasswordChange.InvalidateSessions {
+		return false
+	}
+
+	var (
+		changedAt time.Time
+		err       error
+	)
+
+	if ctx.Providers.PasswordChanges == nil {
+		changedAt, err = ctx.Providers.StorageProvider.LoadUserPasswordChange(ctx, userSession.Username)
+	} else {
+		changedAt, err = ctx.Providers.PasswordChanges.Get(ctx, ctx.Providers.StorageProvider, userSession.Username, ctx.Clock.Now())
+	}
+
+	if err != nil {
+		ctx.Logger.WithError(err).Errorf("Error occurred checking the last password change for user '%s'", userSession.Username)
+	}
+
+	if changedAt.IsZero() {
+		return false
+	}
+
+	authenticated := userSession.FirstFactorAuthnTimestamp
+
+	if userSession.PasswordChangeTimestamp > authenticated {
+		authenticated = userSession.PasswordChangeTimestamp
+	}
+
+	return changedAt.Unix() > authenticated
+}
+
 // SaveSession saves the content of the session.
 func (ctx *AutheliaCtx) SaveSession(userSession session.UserSession) error {
 	provider, err := ctx.GetSessionProvider()
@@ -562,6 +606,13 @@ func (ctx *AutheliaCtx) AcceptsMIME(mime string) (acceptsMime bool) {
 	return false
 }
 
+// AcceptLanguage returns the language tags from the Accept-Language header ordered by preference.
+func (ctx *AutheliaCtx) AcceptLanguage() (tags []language.Tag) {
+	tags, _, _ = language.ParseAcceptLanguage(string(ctx.Request.Header.PeekBytes(headerAcceptLanguage)))
+
+	return tags
+}
+
 // SpecialRedirect performs a redirect similar to fasthttp.RequestCtx except it allows statusCode 401 and includes body
 // content in the form of a link to the location.
 func (ctx *AutheliaCtx) SpecialRedirect(uri string, statusCode int) {
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/valyala/fasthttp"
+	"golang.org/x/text/language"
 
 	"github.com/authelia/authelia/v4/internal/configuration/schema"
 	"github.com/authelia/authelia/v4/internal/middlewares"
@@ -229,6 +230,17 @@ func TestShouldDetectNonXHR(t *testing.T) {
 	assert.False(t, mock.Ctx.IsXHR())
 }
 
+func TestShouldParseAcceptLanguage(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	assert.Len(t, mock.Ctx.AcceptLanguage(), 0)
+
+	mock.Ctx.RequestCtx.Request.Header.Set(fasthttp.HeaderAcceptLanguage, "fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7")
+
+	assert.Equal(t, []language.Tag{language.MustParse("fr-CH"), language.French, language.English, language.German}, mock.Ctx.AcceptLanguage())
+}
+
 func TestShouldReturnCorrectSecondFactorMethods(t *testing.T) {
 	mock := mocks.NewMockAutheliaCtx(t)
 	defer mock.Close()
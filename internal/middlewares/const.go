@@ -2,6 +2,7 @@ package middlewares
 
 import (
 	"errors"
+	"time"
 
 	"github.com/valyala/fasthttp"
 )
@@ -9,9 +10,10 @@ import (
 var (
 	headerXAutheliaURL = []byte("X-Authelia-URL")
 
-	headerAccept        = []byte(fasthttp.HeaderAccept)
-	headerContentLength = []byte(fasthttp.HeaderContentLength)
-	headerLocation      = []byte(fasthttp.HeaderLocation)
+	headerAccept         = []byte(fasthttp.HeaderAccept)
+	headerAcceptLanguage = []byte(fasthttp.HeaderAcceptLanguage)
+	headerContentLength  = []byte(fasthttp.HeaderContentLength)
+	headerLocation       = []byte(fasthttp.HeaderLocation)
 
 	headerXForwardedProto = []byte(fasthttp.HeaderXForwardedProto)
 	headerXForwardedHost  = []byte(fasthttp.HeaderXForwardedHost)
@@ -112,3 +114,12 @@ const (
 var protoHostSeparator = []byte("://")
 
 var errPasswordPolicyNoMet = errors.New("the supplied password does not met the security policy")
+
+var errPasswordPolicyBreached = errors.New("the supplied password has previously appeared in a data breach")
+
+// PasswordChangeCacheLifespan is the amount of time the time of the last password change of a user is cached, which
+// is the maximum amount of time a session is still usable after the password was changed via another instance.
+const PasswordChangeCacheLifespan = time.Minute
+
+// bloomFilterMagic is the magic bytes at the start of a breached password bloom filter file.
+const bloomFilterMagic = "AUTHELIABLOOM1"
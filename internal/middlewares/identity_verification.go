@@ -11,6 +11,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/authelia/authelia/v4/internal/model"
+	"github.com/authelia/authelia/v4/internal/notification"
 	"github.com/authelia/authelia/v4/internal/templates"
 )
 
@@ -71,10 +72,12 @@ func IdentityVerificationStart(args IdentityVerificationStartArgs, delayFunc Tim
 		linkURL.Path = path.Join(linkURL.Path, args.TargetEndpoint)
 		linkURL.RawQuery = query.Encode()
 
+		et := ctx.Providers.Templates.GetLocalizedEmailTemplate(templates.TemplateNameEmailIdentityVerification, ctx.AcceptLanguage()...)
+
 		data := templates.EmailIdentityVerificationValues{
-			Title:       args.MailTitle,
+			Title:       et.Translate(args.MailTitle),
 			LinkURL:     linkURL.String(),
-			LinkText:    args.MailButtonContent,
+			LinkText:    et.Translate(args.MailButtonContent),
 			DisplayName: identity.DisplayName,
 			RemoteIP:    ctx.RemoteIP().String(),
 		}
@@ -84,7 +87,9 @@ func IdentityVerificationStart(args IdentityVerificationStartArgs, delayFunc Tim
 
 		recipient := mail.Address{Name: identity.DisplayName, Address: identity.Email}
 
-		if err = ctx.Providers.Notifier.Send(ctx, recipient, args.MailTitle, ctx.Providers.Templates.GetIdentityVerificationEmailTemplate(), data); err != nil {
+		// The link is sent immediately rather than via the outbox as it expires, and the user must be informed if it
+		// could not be sent.
+		if err = notification.SendImmediately(ctx, ctx.Providers.Notifier, recipient, data.Title, et, data); err != nil {
 			ctx.Error(err, messageOperationFailed)
 			return
 		}
@@ -0,0 +1,84 @@
+package middlewares
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/authelia/authelia/v4/internal/storage"
+)
+
+// NewPasswordChangeCache returns a new PasswordChangeCache which caches the time the password of each user was last
+// changed for the lifespan.
+func NewPasswordChangeCache(lifespan time.Duration) *PasswordChangeCache {
+	return &PasswordChangeCache{
+		lifespan: lifespan,
+		entries:  map[string]passwordChangeCacheEntry{},
+	}
+}
+
+// PasswordChangeCache caches the time the password of each user was last changed so checking if a session was
+// invalidated by a password change doesn't require a query to the storage provider for every request. The time is
+// updated immediately when the password is changed via this instance, and changes made via any other instance are
+// loaded once the cached time expires. If the time can't be loaded from the storage provider the last known time is
+// used until it can be.
+type PasswordChangeCache struct {
+	lifespan time.Duration
+
+	mu      sync.Mutex
+	entries map[string]passwordChangeCacheEntry
+	pruned  time.Time
+}
+
+type passwordChangeCacheEntry struct {
+	changedAt time.Time
+	expires   time.Time
+}
+
+// Get returns the time the password of the user was last changed from the cache or loads it from the storage provider.
+func (c *PasswordChangeCache) Get(ctx context.Context, provider storage.Provider, username string, now time.Time) (changedAt time.Time, err error) {
+	c.mu.Lock()
+
+	entry, ok := c.entries[username]
+
+	c.mu.Unlock()
+
+	if ok && now.Before(entry.expires) {
+		return entry.changedAt, nil
+	}
+
+	if changedAt, err = provider.LoadUserPasswordChange(ctx, username); err != nil {
+		if ok {
+			return entry.changedAt, err
+		}
+
+		return changedAt, err
+	}
+
+	c.Set(username, changedAt, now)
+
+	return changedAt, nil
+}
+
+// Set updates the time the password of the user was last changed.
+func (c *PasswordChangeCache) Set(username string, changedAt, now time.Time) {
+	c.mu.Lock()
+
+	defer c.mu.Unlock()
+
+	if now.Sub(c.pruned) >= c.lifespan {
+		for key, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, key)
+			}
+		}
+
+		c.pruned = now
+	}
+
+	if entry, ok := c.entries[username]; ok && entry.changedAt.After(changedAt) {
+		changedAt = entry.changedAt
+	}
+
+	c.entries[username] = passwordChangeCacheEntry{changedAt: changedAt, expires: now.Add(c.lifespan)}
+}
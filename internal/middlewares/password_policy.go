@@ -13,8 +13,35 @@ type PasswordPolicyProvider interface {
 	Check(password string) (err error)
 }
 
+// PasswordPolicyUnavailableError is returned by a PasswordPolicyProvider when the password could not be checked against
+// the policy, for example when the source of breached passwords is unavailable. It indicates a failure of the provider
+// rather than a password which doesn't meet the policy.
+type PasswordPolicyUnavailableError struct {
+	Err error
+}
+
+// Error implements the error interface.
+func (e *PasswordPolicyUnavailableError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *PasswordPolicyUnavailableError) Unwrap() error {
+	return e.Err
+}
+
 // NewPasswordPolicyProvider returns a new password policy provider.
 func NewPasswordPolicyProvider(config schema.PasswordPolicyConfiguration) (provider PasswordPolicyProvider) {
+	provider = newPasswordPolicyProvider(config)
+
+	if config.Breached.Enabled {
+		return NewBreachedPasswordPolicyProvider(config.Breached, provider)
+	}
+
+	return provider
+}
+
+func newPasswordPolicyProvider(config schema.PasswordPolicyConfiguration) (provider PasswordPolicyProvider) {
 	if !config.Standard.Enabled && !config.ZXCVBN.Enabled {
 		return &StandardPasswordPolicyProvider{}
 	}
@@ -0,0 +1,239 @@
+package middlewares
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // Usage is required by the breached password range format.
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/model"
+)
+
+// NewBreachedPasswordPolicyProvider returns a new BreachedPasswordPolicyProvider which checks passwords against the
+// configured source of breached passwords after they've been checked by the next provider.
+func NewBreachedPasswordPolicyProvider(config schema.PasswordPolicyBreachedParams, next PasswordPolicyProvider) (provider *BreachedPasswordPolicyProvider) {
+	provider = &BreachedPasswordPolicyProvider{
+		next:      next,
+		threshold: config.Threshold,
+	}
+
+	if provider.threshold < 1 {
+		provider.threshold = 1
+	}
+
+	switch config.Mode {
+	case schema.PasswordPolicyBreachedModeBloom:
+		provider.source = &BreachedPasswordBloomSource{path: config.Path}
+	case schema.PasswordPolicyBreachedModeAPI:
+		provider.source = &BreachedPasswordAPISource{url: config.URL, client: &http.Client{Timeout: config.Timeout}}
+	default:
+		provider.source = &BreachedPasswordDirectorySource{path: config.Path}
+	}
+
+	return provider
+}
+
+// BreachedPasswordSource represents a source of breached passwords.
+type BreachedPasswordSource interface {
+	// Breached returns true if the SHA-1 sum of a password has appeared in a breach at least threshold times. Sources
+	// which do not record the number of times a password has appeared ignore the threshold.
+	Breached(sum [sha1.Size]byte, threshold int) (breached bool, err error)
+}
+
+// BreachedPasswordPolicyProvider handles checking passwords against known breached passwords.
+type BreachedPasswordPolicyProvider struct {
+	next      PasswordPolicyProvider
+	source    BreachedPasswordSource
+	threshold int
+}
+
+// Check checks the password against the policy.
+func (p *BreachedPasswordPolicyProvider) Check(password string) (err error) {
+	if p.next != nil {
+		if err = p.next.Check(password); err != nil {
+			return err
+		}
+	}
+
+	var breached bool
+
+	if breached, err = p.source.Breached(sha1.Sum([]byte(password)), p.threshold); err != nil { //nolint:gosec
+		return &PasswordPolicyUnavailableError{Err: fmt.Errorf("error occurred checking if the password has been breached: %w", err)}
+	}
+
+	if breached {
+		return errPasswordPolicyBreached
+	}
+
+	return nil
+}
+
+// StartupCheck implements the startup check provider interface.
+func (p *BreachedPasswordPolicyProvider) StartupCheck() (err error) {
+	if source, ok := p.source.(model.StartupCheck); ok {
+		return source.StartupCheck()
+	}
+
+	return nil
+}
+
+// BreachedPasswordDirectorySource checks passwords against a directory of range files where each file is named after
+// the first 5 characters of the hexadecimal SHA-1 sum, optionally with the .txt extension, and contains one line per
+// sum in the format 'SUFFIX:COUNT'. A prefix without a range file has no breached passwords.
+type BreachedPasswordDirectorySource struct {
+	path string
+}
+
+// StartupCheck implements the startup check provider interface.
+func (s *BreachedPasswordDirectorySource) StartupCheck() (err error) {
+	var info os.FileInfo
+
+	if info, err = os.Stat(s.path); err != nil {
+		return fmt.Errorf("error occurred checking the breached password range directory: %w", err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("error occurred checking the breached password range directory: the path '%s' is not a directory", s.path)
+	}
+
+	return nil
+}
+
+// Breached implements BreachedPasswordSource.
+func (s *BreachedPasswordDirectorySource) Breached(sum [sha1.Size]byte, threshold int) (breached bool, err error) {
+	prefix, suffix := breachedPasswordRange(sum)
+
+	var file *os.File
+
+	if file, err = os.Open(filepath.Join(s.path, prefix)); errors.Is(err, os.ErrNotExist) {
+		file, err = os.Open(filepath.Join(s.path, prefix+".txt"))
+	}
+
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("error occurred opening the range file for prefix '%s': %w", prefix, err)
+	}
+
+	defer file.Close()
+
+	return isBreachedPasswordRange(file, suffix, threshold)
+}
+
+// BreachedPasswordAPISource checks passwords against a HTTP range API which responds to requests to the '/range/PREFIX'
+// path with one line per SHA-1 sum in the format 'SUFFIX:COUNT'.
+type BreachedPasswordAPISource struct {
+	url    *url.URL
+	client *http.Client
+}
+
+// Breached implements BreachedPasswordSource.
+func (s *BreachedPasswordAPISource) Breached(sum [sha1.Size]byte, threshold int) (breached bool, err error) {
+	prefix, suffix := breachedPasswordRange(sum)
+
+	var req *http.Request
+
+	if req, err = http.NewRequest(http.MethodGet, s.url.JoinPath("range", prefix).String(), nil); err != nil {
+		return false, fmt.Errorf("error occurred creating the range request: %w", err)
+	}
+
+	req.Header.Set("Add-Padding", "true")
+	req.Header.Set("User-Agent", "Authelia")
+
+	var resp *http.Response
+
+	if resp, err = s.client.Do(req); err != nil {
+		return false, fmt.Errorf("error occurred performing the range request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("error occurred performing the range request: the API responded with status code %d", resp.StatusCode)
+	}
+
+	return isBreachedPasswordRange(resp.Body, suffix, threshold)
+}
+
+// BreachedPasswordBloomSource checks passwords against a bloom filter file. The file is loaded into memory the first
+// time it's used.
+type BreachedPasswordBloomSource struct {
+	path string
+
+	once   sync.Once
+	filter *BreachedPasswordBloomFilter
+	err    error
+}
+
+// StartupCheck implements the startup check provider interface.
+func (s *BreachedPasswordBloomSource) StartupCheck() (err error) {
+	return s.load()
+}
+
+// Breached implements BreachedPasswordSource.
+func (s *BreachedPasswordBloomSource) Breached(sum [sha1.Size]byte, _ int) (breached bool, err error) {
+	if err = s.load(); err != nil {
+		return false, err
+	}
+
+	return s.filter.Test(sum), nil
+}
+
+func (s *BreachedPasswordBloomSource) load() (err error) {
+	s.once.Do(func() {
+		var file *os.File
+
+		if file, s.err = os.Open(s.path); s.err != nil {
+			s.err = fmt.Errorf("error occurred opening the breached password bloom filter: %w", s.err)
+
+			return
+		}
+
+		defer file.Close()
+
+		if s.filter, s.err = ReadBreachedPasswordBloomFilter(bufio.NewReader(file)); s.err != nil {
+			s.err = fmt.Errorf("error occurred reading the breached password bloom filter: %w", s.err)
+		}
+	})
+
+	return s.err
+}
+
+func breachedPasswordRange(sum [sha1.Size]byte) (prefix, suffix string) {
+	value := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	return value[:5], value[5:]
+}
+
+func isBreachedPasswordRange(r io.Reader, suffix string, threshold int) (breached bool, err error) {
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		hash, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+
+		if !found || !strings.EqualFold(hash, suffix) {
+			continue
+		}
+
+		var count int
+
+		if count, err = strconv.Atoi(value); err != nil {
+			return false, fmt.Errorf("error occurred parsing the count of suffix '%s': %w", suffix, err)
+		}
+
+		return count >= threshold, nil
+	}
+
+	return false, scanner.Err()
+}
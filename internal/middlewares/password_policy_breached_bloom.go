@@ -0,0 +1,138 @@
+package middlewares
+
+import (
+	"crypto/sha1" //nolint:gosec // Usage is required by the breached password range format.
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// NewBreachedPasswordBloomFilter returns a new empty BreachedPasswordBloomFilter sized for n SHA-1 sums with the false
+// positive probability p.
+func NewBreachedPasswordBloomFilter(n uint64, p float64) (filter *BreachedPasswordBloomFilter) {
+	if n == 0 {
+		n = 1
+	}
+
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+
+	if m < 8 {
+		m = 8
+	}
+
+	k := uint32(math.Round(float64(m) / float64(n) * math.Ln2))
+
+	if k < 1 {
+		k = 1
+	}
+
+	return &BreachedPasswordBloomFilter{
+		k:    k,
+		m:    m,
+		bits: make([]byte, (m+7)/8),
+	}
+}
+
+// ReadBreachedPasswordBloomFilter reads a BreachedPasswordBloomFilter previously written with WriteTo.
+func ReadBreachedPasswordBloomFilter(r io.Reader) (filter *BreachedPasswordBloomFilter, err error) {
+	header := make([]byte, len(bloomFilterMagic)+4+8)
+
+	if _, err = io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("error occurred reading the header: %w", err)
+	}
+
+	if string(header[:len(bloomFilterMagic)]) != bloomFilterMagic {
+		return nil, errors.New("the file is not a bloom filter")
+	}
+
+	filter = &BreachedPasswordBloomFilter{
+		k: binary.BigEndian.Uint32(header[len(bloomFilterMagic):]),
+		m: binary.BigEndian.Uint64(header[len(bloomFilterMagic)+4:]),
+	}
+
+	if filter.k == 0 || filter.m == 0 {
+		return nil, fmt.Errorf("the header has invalid parameters k %d and m %d", filter.k, filter.m)
+	}
+
+	size := filter.m / 8
+
+	if filter.m%8 != 0 {
+		size++
+	}
+
+	// The bits are read without allocating the size from the header upfront as the header may not be trustworthy.
+	if filter.bits, err = io.ReadAll(io.LimitReader(r, int64(size))); err != nil {
+		return nil, fmt.Errorf("error occurred reading the bits: %w", err)
+	}
+
+	if uint64(len(filter.bits))*8 < filter.m {
+		return nil, fmt.Errorf("the file has %d bits but the header has m %d", len(filter.bits)*8, filter.m)
+	}
+
+	return filter, nil
+}
+
+// BreachedPasswordBloomFilter is a bloom filter of the SHA-1 sums of breached passwords.
+type BreachedPasswordBloomFilter struct {
+	k    uint32
+	m    uint64
+	bits []byte
+}
+
+// Add a SHA-1 sum to the filter.
+func (f *BreachedPasswordBloomFilter) Add(sum [sha1.Size]byte) {
+	h1, h2 := bloomFilterHashes(sum)
+
+	for i := uint64(0); i < uint64(f.k); i++ {
+		index := (h1 + i*h2) % f.m
+
+		f.bits[index/8] |= 1 << (index % 8)
+	}
+}
+
+// Test returns true if the SHA-1 sum may be in the filter, and false if it's definitely not in the filter.
+func (f *BreachedPasswordBloomFilter) Test(sum [sha1.Size]byte) bool {
+	h1, h2 := bloomFilterHashes(sum)
+
+	for i := uint64(0); i < uint64(f.k); i++ {
+		index := (h1 + i*h2) % f.m
+
+		if f.bits[index/8]&(1<<(index%8)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// WriteTo writes the filter to the writer. The format is the magic bytes, the number of hash functions as a big endian
+// uint32, the number of bits as a big endian uint64, then the bits.
+func (f *BreachedPasswordBloomFilter) WriteTo(w io.Writer) (n int64, err error) {
+	header := make([]byte, len(bloomFilterMagic)+4+8)
+
+	copy(header, bloomFilterMagic)
+	binary.BigEndian.PutUint32(header[len(bloomFilterMagic):], f.k)
+	binary.BigEndian.PutUint64(header[len(bloomFilterMagic)+4:], f.m)
+
+	var written int
+
+	for _, data := range [][]byte{header, f.bits} {
+		written, err = w.Write(data)
+
+		n += int64(written)
+
+		if err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+// bloomFilterHashes derives the two hashes used for double hashing from the SHA-1 sum, which is already uniformly
+// distributed. The second hash is always odd so it's never zero.
+func bloomFilterHashes(sum [sha1.Size]byte) (h1, h2 uint64) {
+	return binary.BigEndian.Uint64(sum[0:8]), binary.BigEndian.Uint64(sum[8:16]) | 1
+}
@@ -0,0 +1,195 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha1" //nolint:gosec // Usage is required by the breached password range format.
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+)
+
+func TestBreachedPasswordPolicyProviderDirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	// SHA-1 of 'password' is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "5BAA6"), []byte("003D68EB55068C33ACE09247EE4C639306B:3\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\r\n"), 0600))
+
+	// SHA-1 of 'letmein' is B7A875FC1EA228B9061041B7CEC4BD3C52AB3CE3.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "B7A87.txt"), []byte("5FC1EA228B9061041B7CEC4BD3C52AB3CE3:5\n"), 0600))
+
+	// SHA-1 of 'abc' is A9993E364706816ABA3E25717850C26C9CD0D89D, and a directory can't be read as a range file.
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "A9993"), 0700))
+
+	testCases := []struct {
+		name      string
+		threshold int
+		have      string
+		err       string
+	}{
+		{"ShouldRejectBreached", 1, "password", "the supplied password has previously appeared in a data breach"},
+		{"ShouldRejectBreachedTXT", 1, "letmein", "the supplied password has previously appeared in a data breach"},
+		{"ShouldAllowBreachedBelowThreshold", 10, "letmein", ""},
+		{"ShouldAllowMissingRange", 1, "a really str0ng pass12nm3kjl12word@@#4", ""},
+		{"ShouldErrorUnreadableRange", 1, "abc", "error occurred checking if the password has been breached: "},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewBreachedPasswordPolicyProvider(schema.PasswordPolicyBreachedParams{Mode: schema.PasswordPolicyBreachedModeDirectory, Path: dir, Threshold: tc.threshold}, &StandardPasswordPolicyProvider{})
+
+			assert.NoError(t, provider.StartupCheck())
+
+			err := provider.Check(tc.have)
+
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+
+	var errUnavailable *PasswordPolicyUnavailableError
+
+	assert.ErrorAs(t, NewBreachedPasswordPolicyProvider(schema.PasswordPolicyBreachedParams{Mode: schema.PasswordPolicyBreachedModeDirectory, Path: dir, Threshold: 1}, nil).Check("abc"), &errUnavailable)
+}
+
+func TestBreachedPasswordPolicyProviderDirectoryStartupCheck(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "file")
+
+	require.NoError(t, os.WriteFile(path, []byte("abc"), 0600))
+
+	provider := NewBreachedPasswordPolicyProvider(schema.PasswordPolicyBreachedParams{Mode: schema.PasswordPolicyBreachedModeDirectory, Path: path}, nil)
+
+	assert.EqualError(t, provider.StartupCheck(), fmt.Sprintf("error occurred checking the breached password range directory: the path '%s' is not a directory", path))
+
+	provider = NewBreachedPasswordPolicyProvider(schema.PasswordPolicyBreachedParams{Mode: schema.PasswordPolicyBreachedModeDirectory, Path: filepath.Join(dir, "missing")}, nil)
+
+	assert.ErrorContains(t, provider.StartupCheck(), "error occurred checking the breached password range directory: stat ")
+}
+
+func TestBreachedPasswordPolicyProviderAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/range/5BAA6":
+			assert.Equal(t, "true", r.Header.Get("Add-Padding"))
+
+			_, _ = w.Write([]byte("003D68EB55068C33ACE09247EE4C639306B:0\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\r\n"))
+		case "/range/B7A87":
+			_, _ = w.Write([]byte("5FC1EA228B9061041B7CEC4BD3C52AB3CE3:0\r\n"))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	provider := NewBreachedPasswordPolicyProvider(schema.PasswordPolicyBreachedParams{Mode: schema.PasswordPolicyBreachedModeAPI, URL: u, Timeout: time.Second}, &StandardPasswordPolicyProvider{})
+
+	assert.NoError(t, provider.StartupCheck())
+	assert.EqualError(t, provider.Check("password"), "the supplied password has previously appeared in a data breach")
+	assert.NoError(t, provider.Check("letmein"))
+	err = provider.Check("abc")
+
+	var errUnavailable *PasswordPolicyUnavailableError
+
+	assert.EqualError(t, err, "error occurred checking if the password has been breached: error occurred performing the range request: the API responded with status code 503")
+	assert.ErrorAs(t, err, &errUnavailable)
+}
+
+func TestBreachedPasswordPolicyProviderBloom(t *testing.T) {
+	filter := NewBreachedPasswordBloomFilter(100, 0.0001)
+
+	filter.Add(sha1.Sum([]byte("password"))) //nolint:gosec
+
+	for i := 0; i < 99; i++ {
+		filter.Add(sha1.Sum([]byte(fmt.Sprintf("breached-%d", i)))) //nolint:gosec
+	}
+
+	buf := &bytes.Buffer{}
+
+	_, err := filter.WriteTo(buf)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "breached.bloom")
+
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0600))
+
+	provider := NewBreachedPasswordPolicyProvider(schema.PasswordPolicyBreachedParams{Mode: schema.PasswordPolicyBreachedModeBloom, Path: path}, &StandardPasswordPolicyProvider{})
+
+	assert.NoError(t, provider.StartupCheck())
+	assert.EqualError(t, provider.Check("password"), "the supplied password has previously appeared in a data breach")
+	assert.EqualError(t, provider.Check("breached-50"), "the supplied password has previously appeared in a data breach")
+	assert.NoError(t, provider.Check("a really str0ng pass12nm3kjl12word@@#4"))
+}
+
+func TestBreachedPasswordPolicyProviderBloomShouldErrorInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breached.bloom")
+
+	require.NoError(t, os.WriteFile(path, []byte("this file is definitely not a bloom filter file"), 0600))
+
+	provider := NewBreachedPasswordPolicyProvider(schema.PasswordPolicyBreachedParams{Mode: schema.PasswordPolicyBreachedModeBloom, Path: path}, nil)
+
+	assert.EqualError(t, provider.StartupCheck(), "error occurred reading the breached password bloom filter: the file is not a bloom filter")
+	assert.EqualError(t, provider.Check("password"), "error occurred checking if the password has been breached: error occurred reading the breached password bloom filter: the file is not a bloom filter")
+}
+
+func TestReadBreachedPasswordBloomFilterShouldErrorInvalidHeader(t *testing.T) {
+	header := func(k uint32, m uint64) []byte {
+		buf := []byte(bloomFilterMagic)
+
+		buf = binary.BigEndian.AppendUint32(buf, k)
+		buf = binary.BigEndian.AppendUint64(buf, m)
+
+		return buf
+	}
+
+	testCases := []struct {
+		name string
+		have []byte
+		err  string
+	}{
+		{"ShouldErrorZeroParameters", header(0, 8), "the header has invalid parameters k 0 and m 8"},
+		{"ShouldErrorLargeM", append(header(1, math.MaxUint64), 0xFF, 0xFF), "the file has 16 bits but the header has m 18446744073709551615"},
+		{"ShouldErrorTruncatedBits", append(header(1, 24), 0xFF, 0xFF), "the file has 16 bits but the header has m 24"},
+		{"ShouldErrorNoBits", header(1, 1), "the file has 0 bits but the header has m 1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := ReadBreachedPasswordBloomFilter(bytes.NewReader(tc.have))
+
+			assert.Nil(t, filter)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestBreachedPasswordPolicyProviderShouldCheckNextFirst(t *testing.T) {
+	provider := NewPasswordPolicyProvider(schema.PasswordPolicyConfiguration{
+		Standard: schema.PasswordPolicyStandardParams{Enabled: true, MinLength: 20},
+		Breached: schema.PasswordPolicyBreachedParams{Enabled: true, Mode: schema.PasswordPolicyBreachedModeDirectory, Path: t.TempDir()},
+	})
+
+	require.IsType(t, &BreachedPasswordPolicyProvider{}, provider)
+
+	assert.EqualError(t, provider.Check("password"), "the supplied password does not met the security policy")
+}
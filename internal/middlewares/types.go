@@ -47,6 +47,7 @@ type Providers struct {
 	Templates       *templates.Provider
 	TOTP            totp.Provider
 	PasswordPolicy  PasswordPolicyProvider
+	PasswordChanges *PasswordChangeCache
 	Random          random.Provider
 }
 
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	sql "database/sql"
+	io "io"
 	reflect "reflect"
 	time "time"
 
@@ -69,6 +70,21 @@ func (mr *MockStorageMockRecorder) BeginTX(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginTX", reflect.TypeOf((*MockStorage)(nil).BeginTX), arg0)
 }
 
+// ClaimOutboundNotification mocks base method.
+func (m *MockStorage) ClaimOutboundNotification(arg0 context.Context, arg1 int, arg2, arg3 time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimOutboundNotification", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimOutboundNotification indicates an expected call of ClaimOutboundNotification.
+func (mr *MockStorageMockRecorder) ClaimOutboundNotification(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOutboundNotification", reflect.TypeOf((*MockStorage)(nil).ClaimOutboundNotification), arg0, arg1, arg2, arg3)
+}
+
 // Close mocks base method.
 func (m *MockStorage) Close() error {
 	m.ctrl.T.Helper()
@@ -210,6 +226,21 @@ func (mr *MockStorageMockRecorder) FindIdentityVerification(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindIdentityVerification", reflect.TypeOf((*MockStorage)(nil).FindIdentityVerification), arg0, arg1)
 }
 
+// LoadAuthenticationHistory mocks base method.
+func (m *MockStorage) LoadAuthenticationHistory(arg0 context.Context, arg1, arg2 string, arg3 model.NullIP, arg4 string) (*model.AuthenticationHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadAuthenticationHistory", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*model.AuthenticationHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadAuthenticationHistory indicates an expected call of LoadAuthenticationHistory.
+func (mr *MockStorageMockRecorder) LoadAuthenticationHistory(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadAuthenticationHistory", reflect.TypeOf((*MockStorage)(nil).LoadAuthenticationHistory), arg0, arg1, arg2, arg3, arg4)
+}
+
 // LoadAuthenticationLogs mocks base method.
 func (m *MockStorage) LoadAuthenticationLogs(arg0 context.Context, arg1 string, arg2 time.Time, arg3, arg4 int) ([]model.AuthenticationAttempt, error) {
 	m.ctrl.T.Helper()
@@ -285,6 +316,51 @@ func (mr *MockStorageMockRecorder) LoadOAuth2Session(arg0, arg1, arg2 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadOAuth2Session", reflect.TypeOf((*MockStorage)(nil).LoadOAuth2Session), arg0, arg1, arg2)
 }
 
+// LoadOutboundNotifications mocks base method.
+func (m *MockStorage) LoadOutboundNotifications(arg0 context.Context, arg1 string, arg2, arg3 int) ([]model.OutboundNotification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadOutboundNotifications", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]model.OutboundNotification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadOutboundNotifications indicates an expected call of LoadOutboundNotifications.
+func (mr *MockStorageMockRecorder) LoadOutboundNotifications(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadOutboundNotifications", reflect.TypeOf((*MockStorage)(nil).LoadOutboundNotifications), arg0, arg1, arg2, arg3)
+}
+
+// LoadOutboundNotificationsDue mocks base method.
+func (m *MockStorage) LoadOutboundNotificationsDue(arg0 context.Context, arg1 time.Time, arg2 int) ([]model.OutboundNotification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadOutboundNotificationsDue", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]model.OutboundNotification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadOutboundNotificationsDue indicates an expected call of LoadOutboundNotificationsDue.
+func (mr *MockStorageMockRecorder) LoadOutboundNotificationsDue(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadOutboundNotificationsDue", reflect.TypeOf((*MockStorage)(nil).LoadOutboundNotificationsDue), arg0, arg1, arg2)
+}
+
+// LoadPasswordHistory mocks base method.
+func (m *MockStorage) LoadPasswordHistory(arg0 context.Context, arg1 string, arg2 int) ([]model.PasswordHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadPasswordHistory", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]model.PasswordHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadPasswordHistory indicates an expected call of LoadPasswordHistory.
+func (mr *MockStorageMockRecorder) LoadPasswordHistory(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadPasswordHistory", reflect.TypeOf((*MockStorage)(nil).LoadPasswordHistory), arg0, arg1, arg2)
+}
+
 // LoadPreferred2FAMethod mocks base method.
 func (m *MockStorage) LoadPreferred2FAMethod(arg0 context.Context, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -315,6 +391,21 @@ func (mr *MockStorageMockRecorder) LoadPreferredDuoDevice(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadPreferredDuoDevice", reflect.TypeOf((*MockStorage)(nil).LoadPreferredDuoDevice), arg0, arg1)
 }
 
+// LoadPruneCounts mocks base method.
+func (m *MockStorage) LoadPruneCounts(arg0 context.Context, arg1 storage.PrunePolicy) (storage.PruneResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadPruneCounts", arg0, arg1)
+	ret0, _ := ret[0].(storage.PruneResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadPruneCounts indicates an expected call of LoadPruneCounts.
+func (mr *MockStorageMockRecorder) LoadPruneCounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadPruneCounts", reflect.TypeOf((*MockStorage)(nil).LoadPruneCounts), arg0, arg1)
+}
+
 // LoadTOTPConfiguration mocks base method.
 func (m *MockStorage) LoadTOTPConfiguration(arg0 context.Context, arg1 string) (*model.TOTPConfiguration, error) {
 	m.ctrl.T.Helper()
@@ -405,6 +496,21 @@ func (mr *MockStorageMockRecorder) LoadUserOpaqueIdentifiers(arg0 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadUserOpaqueIdentifiers", reflect.TypeOf((*MockStorage)(nil).LoadUserOpaqueIdentifiers), arg0)
 }
 
+// LoadUserPasswordChange mocks base method.
+func (m *MockStorage) LoadUserPasswordChange(arg0 context.Context, arg1 string) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadUserPasswordChange", arg0, arg1)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadUserPasswordChange indicates an expected call of LoadUserPasswordChange.
+func (mr *MockStorageMockRecorder) LoadUserPasswordChange(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadUserPasswordChange", reflect.TypeOf((*MockStorage)(nil).LoadUserPasswordChange), arg0, arg1)
+}
+
 // LoadWebauthnDevices mocks base method.
 func (m *MockStorage) LoadWebauthnDevices(arg0 context.Context, arg1, arg2 int) ([]model.WebauthnDevice, error) {
 	m.ctrl.T.Helper()
@@ -435,6 +541,79 @@ func (mr *MockStorageMockRecorder) LoadWebauthnDevicesByUsername(arg0, arg1 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadWebauthnDevicesByUsername", reflect.TypeOf((*MockStorage)(nil).LoadWebauthnDevicesByUsername), arg0, arg1)
 }
 
+// Prune mocks base method.
+func (m *MockStorage) Prune(arg0 context.Context, arg1 storage.PrunePolicy, arg2 int) (storage.PruneResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Prune", arg0, arg1, arg2)
+	ret0, _ := ret[0].(storage.PruneResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Prune indicates an expected call of Prune.
+func (mr *MockStorageMockRecorder) Prune(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prune", reflect.TypeOf((*MockStorage)(nil).Prune), arg0, arg1, arg2)
+}
+
+// PrunePasswordHistory mocks base method.
+func (m *MockStorage) PrunePasswordHistory(arg0 context.Context, arg1 string, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrunePasswordHistory", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PrunePasswordHistory indicates an expected call of PrunePasswordHistory.
+func (mr *MockStorageMockRecorder) PrunePasswordHistory(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrunePasswordHistory", reflect.TypeOf((*MockStorage)(nil).PrunePasswordHistory), arg0, arg1, arg2)
+}
+
+// PurgeOutboundNotifications mocks base method.
+func (m *MockStorage) PurgeOutboundNotifications(arg0 context.Context, arg1 string, arg2 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeOutboundNotifications", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeOutboundNotifications indicates an expected call of PurgeOutboundNotifications.
+func (mr *MockStorageMockRecorder) PurgeOutboundNotifications(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeOutboundNotifications", reflect.TypeOf((*MockStorage)(nil).PurgeOutboundNotifications), arg0, arg1, arg2)
+}
+
+// RetryOutboundNotification mocks base method.
+func (m *MockStorage) RetryOutboundNotification(arg0 context.Context, arg1 int, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryOutboundNotification", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryOutboundNotification indicates an expected call of RetryOutboundNotification.
+func (mr *MockStorageMockRecorder) RetryOutboundNotification(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryOutboundNotification", reflect.TypeOf((*MockStorage)(nil).RetryOutboundNotification), arg0, arg1, arg2)
+}
+
+// RetryOutboundNotifications mocks base method.
+func (m *MockStorage) RetryOutboundNotifications(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryOutboundNotifications", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetryOutboundNotifications indicates an expected call of RetryOutboundNotifications.
+func (mr *MockStorageMockRecorder) RetryOutboundNotifications(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryOutboundNotifications", reflect.TypeOf((*MockStorage)(nil).RetryOutboundNotifications), arg0, arg1)
+}
+
 // RevokeOAuth2Session mocks base method.
 func (m *MockStorage) RevokeOAuth2Session(arg0 context.Context, arg1 storage.OAuth2SessionType, arg2 string) error {
 	m.ctrl.T.Helper()
@@ -590,6 +769,34 @@ func (mr *MockStorageMockRecorder) SaveOAuth2Session(arg0, arg1, arg2 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOAuth2Session", reflect.TypeOf((*MockStorage)(nil).SaveOAuth2Session), arg0, arg1, arg2)
 }
 
+// SaveOutboundNotification mocks base method.
+func (m *MockStorage) SaveOutboundNotification(arg0 context.Context, arg1 model.OutboundNotification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOutboundNotification", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOutboundNotification indicates an expected call of SaveOutboundNotification.
+func (mr *MockStorageMockRecorder) SaveOutboundNotification(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOutboundNotification", reflect.TypeOf((*MockStorage)(nil).SaveOutboundNotification), arg0, arg1)
+}
+
+// SavePasswordHistory mocks base method.
+func (m *MockStorage) SavePasswordHistory(arg0 context.Context, arg1 model.PasswordHistory) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePasswordHistory", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePasswordHistory indicates an expected call of SavePasswordHistory.
+func (mr *MockStorageMockRecorder) SavePasswordHistory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePasswordHistory", reflect.TypeOf((*MockStorage)(nil).SavePasswordHistory), arg0, arg1)
+}
+
 // SavePreferred2FAMethod mocks base method.
 func (m *MockStorage) SavePreferred2FAMethod(arg0 context.Context, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
@@ -646,6 +853,20 @@ func (mr *MockStorageMockRecorder) SaveUserOpaqueIdentifier(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUserOpaqueIdentifier", reflect.TypeOf((*MockStorage)(nil).SaveUserOpaqueIdentifier), arg0, arg1)
 }
 
+// SaveUserPasswordChange mocks base method.
+func (m *MockStorage) SaveUserPasswordChange(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveUserPasswordChange", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveUserPasswordChange indicates an expected call of SaveUserPasswordChange.
+func (mr *MockStorageMockRecorder) SaveUserPasswordChange(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUserPasswordChange", reflect.TypeOf((*MockStorage)(nil).SaveUserPasswordChange), arg0, arg1, arg2)
+}
+
 // SaveWebauthnDevice mocks base method.
 func (m *MockStorage) SaveWebauthnDevice(arg0 context.Context, arg1 model.WebauthnDevice) error {
 	m.ctrl.T.Helper()
@@ -660,6 +881,36 @@ func (mr *MockStorageMockRecorder) SaveWebauthnDevice(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebauthnDevice", reflect.TypeOf((*MockStorage)(nil).SaveWebauthnDevice), arg0, arg1)
 }
 
+// SchemaBackup mocks base method.
+func (m *MockStorage) SchemaBackup(arg0 context.Context, arg1 io.Writer, arg2 string, arg3 int) (storage.SchemaBackupResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SchemaBackup", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(storage.SchemaBackupResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SchemaBackup indicates an expected call of SchemaBackup.
+func (mr *MockStorageMockRecorder) SchemaBackup(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SchemaBackup", reflect.TypeOf((*MockStorage)(nil).SchemaBackup), arg0, arg1, arg2, arg3)
+}
+
+// SchemaCopy mocks base method.
+func (m *MockStorage) SchemaCopy(arg0 context.Context, arg1 storage.Provider, arg2 int) (storage.SchemaCopyResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SchemaCopy", arg0, arg1, arg2)
+	ret0, _ := ret[0].(storage.SchemaCopyResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SchemaCopy indicates an expected call of SchemaCopy.
+func (mr *MockStorageMockRecorder) SchemaCopy(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SchemaCopy", reflect.TypeOf((*MockStorage)(nil).SchemaCopy), arg0, arg1, arg2)
+}
+
 // SchemaEncryptionChangeKey mocks base method.
 func (m *MockStorage) SchemaEncryptionChangeKey(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -689,6 +940,20 @@ func (mr *MockStorageMockRecorder) SchemaEncryptionCheckKey(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SchemaEncryptionCheckKey", reflect.TypeOf((*MockStorage)(nil).SchemaEncryptionCheckKey), arg0, arg1)
 }
 
+// SchemaEncryptionRekey mocks base method.
+func (m *MockStorage) SchemaEncryptionRekey(arg0 context.Context, arg1 *storage.EncryptionRekeyState, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SchemaEncryptionRekey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SchemaEncryptionRekey indicates an expected call of SchemaEncryptionRekey.
+func (mr *MockStorageMockRecorder) SchemaEncryptionRekey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SchemaEncryptionRekey", reflect.TypeOf((*MockStorage)(nil).SchemaEncryptionRekey), arg0, arg1, arg2)
+}
+
 // SchemaLatestVersion mocks base method.
 func (m *MockStorage) SchemaLatestVersion() (int, error) {
 	m.ctrl.T.Helper()
@@ -763,6 +1028,21 @@ func (mr *MockStorageMockRecorder) SchemaMigrationsUp(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SchemaMigrationsUp", reflect.TypeOf((*MockStorage)(nil).SchemaMigrationsUp), arg0, arg1)
 }
 
+// SchemaRestore mocks base method.
+func (m *MockStorage) SchemaRestore(arg0 context.Context, arg1 *storage.BackupReader, arg2 int) (storage.SchemaBackupResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SchemaRestore", arg0, arg1, arg2)
+	ret0, _ := ret[0].(storage.SchemaBackupResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SchemaRestore indicates an expected call of SchemaRestore.
+func (mr *MockStorageMockRecorder) SchemaRestore(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SchemaRestore", reflect.TypeOf((*MockStorage)(nil).SchemaRestore), arg0, arg1, arg2)
+}
+
 // SchemaTables mocks base method.
 func (m *MockStorage) SchemaTables(arg0 context.Context) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -807,6 +1087,20 @@ func (mr *MockStorageMockRecorder) StartupCheck() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartupCheck", reflect.TypeOf((*MockStorage)(nil).StartupCheck))
 }
 
+// UpdateOutboundNotificationStatus mocks base method.
+func (m *MockStorage) UpdateOutboundNotificationStatus(arg0 context.Context, arg1 model.OutboundNotification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOutboundNotificationStatus", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOutboundNotificationStatus indicates an expected call of UpdateOutboundNotificationStatus.
+func (mr *MockStorageMockRecorder) UpdateOutboundNotificationStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOutboundNotificationStatus", reflect.TypeOf((*MockStorage)(nil).UpdateOutboundNotificationStatus), arg0, arg1)
+}
+
 // UpdateTOTPConfigurationSignIn mocks base method.
 func (m *MockStorage) UpdateTOTPConfigurationSignIn(arg0 context.Context, arg1 int, arg2 sql.NullTime) error {
 	m.ctrl.T.Helper()
@@ -15,4 +15,21 @@ type AuthenticationAttempt struct {
 	RemoteIP      NullIP    `db:"remote_ip"`
 	RequestURI    string    `db:"request_uri"`
 	RequestMethod string    `db:"request_method"`
+	UserAgent     string    `db:"user_agent"`
+}
+
+// AuthenticationHistory represents the number of previous successful authentications of a user which match the
+// properties of an authentication attempt.
+type AuthenticationHistory struct {
+	// Successful is the number of previous successful authentications.
+	Successful int `db:"successful"`
+
+	// RemoteIP is the number of previous successful authentications from the same remote IP.
+	RemoteIP int `db:"remote_ip"`
+
+	// UserAgent is the number of previous successful authentications with the same user agent.
+	UserAgent int `db:"user_agent"`
+
+	// UserAgentRecorded is the number of previous successful authentications which recorded a user agent.
+	UserAgentRecorded int `db:"user_agent_recorded"`
 }
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	// OutboundNotificationStatusPending is the status of an OutboundNotification which has not been delivered yet.
+	OutboundNotificationStatusPending = "pending"
+
+	// OutboundNotificationStatusSent is the status of an OutboundNotification which has been delivered.
+	OutboundNotificationStatusSent = "sent"
+
+	// OutboundNotificationStatusFailed is the status of an OutboundNotification which has exceeded the maximum number
+	// of delivery attempts and will not be attempted again unless it's retried manually.
+	OutboundNotificationStatusFailed = "failed"
+)
+
+// OutboundNotification represents a notification persisted to the notification outbox for delivery by the outbox
+// worker. The Data is the JSON encoded template data and is encrypted in the database.
+type OutboundNotification struct {
+	ID            int            `db:"id"`
+	CreatedAt     time.Time      `db:"created_at"`
+	NextAttemptAt time.Time      `db:"next_attempt_at"`
+	SentAt        sql.NullTime   `db:"sent_at"`
+	Attempts      int            `db:"attempts"`
+	Status        string         `db:"status"`
+	Template      string         `db:"template"`
+	Locale        string         `db:"locale"`
+	Recipient     string         `db:"recipient"`
+	Subject       string         `db:"subject"`
+	Data          []byte         `db:"data"`
+	LastError     sql.NullString `db:"last_error"`
+}
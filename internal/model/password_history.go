@@ -0,0 +1,13 @@
+package model
+
+import (
+	"time"
+)
+
+// PasswordHistory represents a previous password of a user.
+type PasswordHistory struct {
+	ID        int       `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+	Username  string    `db:"username"`
+	Digest    string    `db:"digest"`
+}
@@ -1,5 +1,10 @@
 package notification
 
+import (
+	"encoding/asn1"
+	"time"
+)
+
 const (
 	fileNotifierMode   = 0600
 	fileNotifierHeader = "Date: %s\nRecipient: %s\nSubject: %s\n"
@@ -9,6 +14,42 @@ const (
 	smtpPortSUBMISSIONS = 465
 )
 
+const (
+	smtpHeaderDKIMSignature = "DKIM-Signature"
+)
+
+const (
+	dkimAlgorithmRSASHA256     = "rsa-sha256"
+	dkimAlgorithmEd25519SHA256 = "ed25519-sha256"
+)
+
+var (
+	oidPKCS7Data                = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidPKCS7SignedData          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidAttributeContentType     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidAttributeMessageDigest   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidAttributeSigningTime     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+	oidDigestSHA256             = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSignatureRSA             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidSignatureECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+)
+
+const (
+	headerAuthorization    = "Authorization"
+	headerContentType      = "Content-Type"
+	headerWebhookSignature = "X-Authelia-Signature"
+)
+
+const (
+	webhookMatrixPathWhoAmI  = "/_matrix/client/v3/account/whoami"
+	webhookMatrixPathSendFmt = "/_matrix/client/v3/rooms/%s/send/m.room.message/%s"
+)
+
+const (
+	outboxBatchSize     = 20
+	outboxClaimDuration = time.Minute * 5
+)
+
 const (
 	posixNewLine = "\n"
 )
@@ -0,0 +1,64 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/authelia/authelia/v4/internal/logging"
+	"github.com/authelia/authelia/v4/internal/templates"
+)
+
+// NewMultiNotifier creates a MultiNotifier which sends every notification with the primary notifier and additionally
+// with each webhook that matches the template.
+func NewMultiNotifier(primary Notifier, webhooks ...*WebhookNotifier) *MultiNotifier {
+	return &MultiNotifier{
+		primary:  primary,
+		webhooks: webhooks,
+		log:      logging.Logger().WithFields(map[string]any{"service": "notifier", "notifier": "webhook"}),
+	}
+}
+
+// MultiNotifier a notifier which sends notifications with a primary notifier and a list of webhook notifiers.
+type MultiNotifier struct {
+	primary  Notifier
+	webhooks []*WebhookNotifier
+	log      *logrus.Entry
+}
+
+// StartupCheck implements model.StartupCheck to perform startup check operations.
+func (n *MultiNotifier) StartupCheck() (err error) {
+	if err = n.primary.StartupCheck(); err != nil {
+		return err
+	}
+
+	for _, webhook := range n.webhooks {
+		if err = webhook.StartupCheck(); err != nil {
+			return fmt.Errorf("webhook '%s': %w", webhook.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// Send a notification via the primary notifier and each of the webhooks which match the template. The notification
+// is considered delivered once the primary notifier has sent it, so only an error from the primary notifier is returned
+// and errors from the webhooks are logged. This ensures a webhook failure never causes the primary notifier to send the
+// notification again when it's retried. A failure of one notifier does not prevent the others from being attempted.
+func (n *MultiNotifier) Send(ctx context.Context, recipient mail.Address, subject string, et *templates.EmailTemplate, data any) (err error) {
+	err = n.primary.Send(ctx, recipient, subject, et, data)
+
+	for _, webhook := range n.webhooks {
+		if !webhook.Matches(et) {
+			continue
+		}
+
+		if errWebhook := webhook.Send(ctx, recipient, subject, et, data); errWebhook != nil {
+			n.log.WithError(errWebhook).WithField("webhook", webhook.Name()).Error("Error occurred sending the notification to the webhook")
+		}
+	}
+
+	return err
+}
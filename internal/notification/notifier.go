@@ -14,3 +14,21 @@ type Notifier interface {
 
 	Send(ctx context.Context, recipient mail.Address, subject string, et *templates.EmailTemplate, data any) (err error)
 }
+
+// ImmediateNotifier is a Notifier which defers the delivery of notifications but can also deliver them immediately.
+type ImmediateNotifier interface {
+	Notifier
+
+	SendImmediately(ctx context.Context, recipient mail.Address, subject string, et *templates.EmailTemplate, data any) (err error)
+}
+
+// SendImmediately sends a notification with the notifier without deferring the delivery if the notifier supports it,
+// so any error delivering the notification is returned. It should be used for notifications which are only useful for
+// a short time or where the user must be informed if the delivery fails.
+func SendImmediately(ctx context.Context, notifier Notifier, recipient mail.Address, subject string, et *templates.EmailTemplate, data any) (err error) {
+	if immediate, ok := notifier.(ImmediateNotifier); ok {
+		return immediate.SendImmediately(ctx, recipient, subject, et, data)
+	}
+
+	return notifier.Send(ctx, recipient, subject, et, data)
+}
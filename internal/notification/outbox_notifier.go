@@ -0,0 +1,251 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/text/language"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/logging"
+	"github.com/authelia/authelia/v4/internal/model"
+	"github.com/authelia/authelia/v4/internal/templates"
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+// OutboxStorageProvider is the subset of the storage provider used by the OutboxNotifier.
+type OutboxStorageProvider interface {
+	SaveOutboundNotification(ctx context.Context, notification model.OutboundNotification) (err error)
+	LoadOutboundNotificationsDue(ctx context.Context, now time.Time, limit int) (notifications []model.OutboundNotification, err error)
+	ClaimOutboundNotification(ctx context.Context, id int, now, until time.Time) (claimed bool, err error)
+	UpdateOutboundNotificationStatus(ctx context.Context, notification model.OutboundNotification) (err error)
+}
+
+// NewOutboxNotifier creates an OutboxNotifier which persists notifications to the outbox and delivers them with the
+// notifier in the background.
+func NewOutboxNotifier(config schema.NotifierOutboxConfiguration, notifier Notifier, storage OutboxStorageProvider, templates *templates.Provider) *OutboxNotifier {
+	return &OutboxNotifier{
+		config:    config,
+		notifier:  notifier,
+		storage:   storage,
+		templates: templates,
+		clock:     utils.RealClock{},
+		log:       logging.Logger().WithFields(map[string]any{"service": "notifier", "notifier": "outbox"}),
+		wake:      make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+}
+
+// OutboxNotifier a notifier which persists notifications to the notification outbox in the storage so the request
+// which sends a notification does not have to wait for the delivery. The notifications are delivered by the Run loop
+// which retries failed deliveries with an exponential backoff until the maximum attempts is reached, at which point
+// the notification is marked as failed.
+type OutboxNotifier struct {
+	config    schema.NotifierOutboxConfiguration
+	notifier  Notifier
+	storage   OutboxStorageProvider
+	templates *templates.Provider
+	clock     utils.Clock
+	log       *logrus.Entry
+
+	wake     chan struct{}
+	done     chan struct{}
+	shutdown sync.Once
+}
+
+// StartupCheck implements model.StartupCheck to perform startup check operations.
+func (n *OutboxNotifier) StartupCheck() (err error) {
+	return n.notifier.StartupCheck()
+}
+
+// Send a notification by persisting it to the notification outbox. An error is only returned if the notification
+// could not be persisted.
+func (n *OutboxNotifier) Send(ctx context.Context, recipient mail.Address, subject string, et *templates.EmailTemplate, data any) (err error) {
+	if et == nil || n.templates.GetEmailTemplate(et.Name) == nil {
+		return fmt.Errorf("notifier: outbox: failed to queue message: the template is not known")
+	}
+
+	var raw []byte
+
+	if raw, err = json.Marshal(data); err != nil {
+		return fmt.Errorf("notifier: outbox: failed to queue message: failed to marshal data: %w", err)
+	}
+
+	now := n.clock.Now()
+
+	if err = n.storage.SaveOutboundNotification(ctx, model.OutboundNotification{
+		CreatedAt:     now,
+		NextAttemptAt: now,
+		Status:        model.OutboundNotificationStatusPending,
+		Template:      et.Name,
+		Locale:        et.Locale,
+		Recipient:     recipient.String(),
+		Subject:       subject,
+		Data:          raw,
+	}); err != nil {
+		return fmt.Errorf("notifier: outbox: failed to queue message: %w", err)
+	}
+
+	n.Wake()
+
+	return nil
+}
+
+// SendImmediately sends a notification with the notifier without persisting it to the notification outbox. An error is
+// returned if the notification could not be delivered.
+func (n *OutboxNotifier) SendImmediately(ctx context.Context, recipient mail.Address, subject string, et *templates.EmailTemplate, data any) (err error) {
+	return n.notifier.Send(ctx, recipient, subject, et, data)
+}
+
+// Wake the Run loop so it processes the due notifications without waiting for the interval.
+func (n *OutboxNotifier) Wake() {
+	select {
+	case n.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run the delivery loop until Shutdown is called.
+func (n *OutboxNotifier) Run() (err error) {
+	ticker := time.NewTicker(n.config.Interval)
+
+	defer ticker.Stop()
+
+	n.log.Info("Delivering notifications from the outbox")
+
+	for {
+		n.Process(context.Background())
+
+		select {
+		case <-n.done:
+			return nil
+		case <-ticker.C:
+		case <-n.wake:
+		}
+	}
+}
+
+// Shutdown the delivery loop.
+func (n *OutboxNotifier) Shutdown() {
+	n.shutdown.Do(func() {
+		close(n.done)
+	})
+}
+
+// Process delivers a batch of the due notifications and returns the number which were attempted.
+func (n *OutboxNotifier) Process(ctx context.Context) (attempted int) {
+	now := n.clock.Now()
+
+	notifications, err := n.storage.LoadOutboundNotificationsDue(ctx, now, outboxBatchSize)
+	if err != nil {
+		n.log.WithError(err).Error("Error occurred loading the due notifications")
+
+		return 0
+	}
+
+	for _, notification := range notifications {
+		var claimed bool
+
+		if claimed, err = n.storage.ClaimOutboundNotification(ctx, notification.ID, now, now.Add(outboxClaimDuration)); err != nil {
+			n.log.WithError(err).WithField("id", notification.ID).Error("Error occurred claiming the notification")
+
+			continue
+		}
+
+		if !claimed {
+			continue
+		}
+
+		attempted++
+
+		n.attempt(ctx, notification)
+	}
+
+	if len(notifications) == outboxBatchSize {
+		n.Wake()
+	}
+
+	return attempted
+}
+
+func (n *OutboxNotifier) attempt(ctx context.Context, notification model.OutboundNotification) {
+	log := n.log.WithFields(map[string]any{"id": notification.ID, "template": notification.Template})
+
+	err := n.deliver(ctx, notification)
+
+	now := n.clock.Now()
+
+	notification.Attempts++
+
+	switch {
+	case err == nil:
+		notification.Status = model.OutboundNotificationStatusSent
+		notification.SentAt = sql.NullTime{Time: now, Valid: true}
+		notification.LastError = sql.NullString{}
+
+		log.Debug("Delivered the notification")
+	case notification.Attempts >= n.config.MaxAttempts:
+		notification.Status = model.OutboundNotificationStatusFailed
+		notification.LastError = sql.NullString{String: err.Error(), Valid: true}
+
+		log.WithError(err).Errorf("Error occurred delivering the notification, it has been marked as failed after %d attempts", notification.Attempts)
+	default:
+		notification.NextAttemptAt = now.Add(n.backoff(notification.Attempts))
+		notification.LastError = sql.NullString{String: err.Error(), Valid: true}
+
+		log.WithError(err).Warnf("Error occurred delivering the notification, it will be attempted again at %s", notification.NextAttemptAt)
+	}
+
+	if err = n.storage.UpdateOutboundNotificationStatus(ctx, notification); err != nil {
+		log.WithError(err).Error("Error occurred updating the notification status")
+	}
+}
+
+func (n *OutboxNotifier) deliver(ctx context.Context, notification model.OutboundNotification) (err error) {
+	var et *templates.EmailTemplate
+
+	if notification.Locale == "" {
+		et = n.templates.GetEmailTemplate(notification.Template)
+	} else {
+		et = n.templates.GetLocalizedEmailTemplate(notification.Template, language.Make(notification.Locale))
+	}
+
+	if et == nil {
+		return fmt.Errorf("the template '%s' is not known", notification.Template)
+	}
+
+	var recipient *mail.Address
+
+	if recipient, err = mail.ParseAddress(notification.Recipient); err != nil {
+		return fmt.Errorf("failed to parse recipient: %w", err)
+	}
+
+	var data map[string]any
+
+	if err = json.Unmarshal(notification.Data, &data); err != nil {
+		return fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+
+	return n.notifier.Send(ctx, *recipient, notification.Subject, et, data)
+}
+
+// backoff returns the duration to wait before the next attempt which doubles with each attempt up to the maximum.
+func (n *OutboxNotifier) backoff(attempts int) (backoff time.Duration) {
+	backoff = n.config.Backoff
+
+	for i := 1; i < attempts && backoff < n.config.MaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > n.config.MaxBackoff {
+		return n.config.MaxBackoff
+	}
+
+	return backoff
+}
@@ -0,0 +1,291 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/mail"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/model"
+	"github.com/authelia/authelia/v4/internal/templates"
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+type testOutboxStorage struct {
+	mu            sync.Mutex
+	notifications []model.OutboundNotification
+}
+
+func (s *testOutboxStorage) status(id int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.notifications[id-1].Status
+}
+
+func (s *testOutboxStorage) SaveOutboundNotification(_ context.Context, notification model.OutboundNotification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	notification.ID = len(s.notifications) + 1
+
+	s.notifications = append(s.notifications, notification)
+
+	return nil
+}
+
+func (s *testOutboxStorage) LoadOutboundNotificationsDue(_ context.Context, now time.Time, limit int) (notifications []model.OutboundNotification, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, notification := range s.notifications {
+		if notification.Status == model.OutboundNotificationStatusPending && !notification.NextAttemptAt.After(now) && len(notifications) < limit {
+			notifications = append(notifications, notification)
+		}
+	}
+
+	return notifications, nil
+}
+
+func (s *testOutboxStorage) ClaimOutboundNotification(_ context.Context, id int, now, until time.Time) (claimed bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	notification := &s.notifications[id-1]
+
+	if notification.Status != model.OutboundNotificationStatusPending || notification.NextAttemptAt.After(now) {
+		return false, nil
+	}
+
+	notification.NextAttemptAt = until
+
+	return true, nil
+}
+
+func (s *testOutboxStorage) UpdateOutboundNotificationStatus(_ context.Context, notification model.OutboundNotification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.notifications[notification.ID-1] = notification
+
+	return nil
+}
+
+type testOutboxNotifier struct {
+	err       error
+	sent      []any
+	templates []*templates.EmailTemplate
+}
+
+func (n *testOutboxNotifier) StartupCheck() error {
+	return n.err
+}
+
+func (n *testOutboxNotifier) Send(_ context.Context, _ mail.Address, _ string, et *templates.EmailTemplate, data any) error {
+	n.sent = append(n.sent, data)
+	n.templates = append(n.templates, et)
+
+	return n.err
+}
+
+func newTestOutboxNotifier(t *testing.T, notifier Notifier, clock utils.Clock) (outbox *OutboxNotifier, storage *testOutboxStorage) {
+	provider, err := templates.New(templates.Config{})
+	require.NoError(t, err)
+
+	storage = &testOutboxStorage{}
+
+	outbox = NewOutboxNotifier(schema.NotifierOutboxConfiguration{
+		Interval:    time.Minute,
+		MaxAttempts: 3,
+		Backoff:     time.Second * 30,
+		MaxBackoff:  time.Minute,
+	}, notifier, storage, provider)
+
+	outbox.clock = clock
+
+	return outbox, storage
+}
+
+func TestOutboxNotifierShouldDeliver(t *testing.T) {
+	clock := &utils.TestingClock{}
+	clock.Set(time.Unix(1000000, 0))
+
+	notifier := &testOutboxNotifier{}
+
+	outbox, storage := newTestOutboxNotifier(t, notifier, clock)
+
+	data := templates.EmailEventValues{Title: "Password changed", DisplayName: "John Smith", Details: map[string]any{"Action": "Password Reset"}}
+
+	require.NoError(t, outbox.Send(context.Background(), mail.Address{Name: "John Smith", Address: "john@example.com"}, "Password changed", &templates.EmailTemplate{Name: templates.TemplateNameEmailEvent}, data))
+
+	require.Len(t, storage.notifications, 1)
+	assert.Equal(t, model.OutboundNotificationStatusPending, storage.notifications[0].Status)
+	assert.Equal(t, `"John Smith" <john@example.com>`, storage.notifications[0].Recipient)
+	assert.Len(t, notifier.sent, 0)
+
+	assert.Equal(t, 1, outbox.Process(context.Background()))
+
+	require.Len(t, notifier.sent, 1)
+	assert.Equal(t, map[string]any{"Title": "Password changed", "DisplayName": "John Smith", "Details": map[string]any{"Action": "Password Reset"}, "RemoteIP": ""}, notifier.sent[0])
+
+	assert.Equal(t, model.OutboundNotificationStatusSent, storage.notifications[0].Status)
+	assert.Equal(t, 1, storage.notifications[0].Attempts)
+	assert.True(t, storage.notifications[0].SentAt.Valid)
+
+	assert.Equal(t, 0, outbox.Process(context.Background()))
+}
+
+func TestOutboxNotifierShouldDeliverLocalized(t *testing.T) {
+	clock := &utils.TestingClock{}
+	clock.Set(time.Unix(1000000, 0))
+
+	notifier := &testOutboxNotifier{}
+
+	outbox, storage := newTestOutboxNotifier(t, notifier, clock)
+
+	et := outbox.templates.GetLocalizedEmailTemplate(templates.TemplateNameEmailEvent, language.German)
+	require.NotNil(t, et)
+
+	require.NoError(t, outbox.Send(context.Background(), mail.Address{Address: "john@example.com"}, "Subject", et, nil))
+
+	require.Len(t, storage.notifications, 1)
+	assert.Equal(t, templates.TemplateNameEmailEvent, storage.notifications[0].Template)
+	assert.Equal(t, "de", storage.notifications[0].Locale)
+
+	assert.Equal(t, 1, outbox.Process(context.Background()))
+
+	require.Len(t, notifier.templates, 1)
+	assert.Equal(t, et, notifier.templates[0])
+}
+
+func TestOutboxNotifierShouldRetryWithBackoffAndFail(t *testing.T) {
+	clock := &utils.TestingClock{}
+	clock.Set(time.Unix(1000000, 0))
+
+	notifier := &testOutboxNotifier{err: errors.New("smtp failure")}
+
+	outbox, storage := newTestOutboxNotifier(t, notifier, clock)
+
+	require.NoError(t, outbox.Send(context.Background(), mail.Address{Address: "john@example.com"}, "Subject", &templates.EmailTemplate{Name: templates.TemplateNameEmailEvent}, nil))
+
+	assert.Equal(t, 1, outbox.Process(context.Background()))
+	assert.Equal(t, model.OutboundNotificationStatusPending, storage.notifications[0].Status)
+	assert.Equal(t, "smtp failure", storage.notifications[0].LastError.String)
+	assert.Equal(t, clock.Now().Add(time.Second*30), storage.notifications[0].NextAttemptAt)
+
+	assert.Equal(t, 0, outbox.Process(context.Background()))
+
+	clock.Set(clock.Now().Add(time.Second * 30))
+
+	assert.Equal(t, 1, outbox.Process(context.Background()))
+	assert.Equal(t, model.OutboundNotificationStatusPending, storage.notifications[0].Status)
+	assert.Equal(t, clock.Now().Add(time.Minute), storage.notifications[0].NextAttemptAt)
+
+	clock.Set(clock.Now().Add(time.Minute))
+
+	assert.Equal(t, 1, outbox.Process(context.Background()))
+	assert.Equal(t, model.OutboundNotificationStatusFailed, storage.notifications[0].Status)
+	assert.Equal(t, 3, storage.notifications[0].Attempts)
+
+	clock.Set(clock.Now().Add(time.Hour))
+
+	assert.Equal(t, 0, outbox.Process(context.Background()))
+	assert.Len(t, notifier.sent, 3)
+}
+
+func TestOutboxNotifierShouldNotRetryWebhookFailures(t *testing.T) {
+	clock := &utils.TestingClock{}
+	clock.Set(time.Unix(1000000, 0))
+
+	server, _ := newWebhookTestServer(t, http.StatusInternalServerError)
+
+	notifier := &testOutboxNotifier{}
+
+	outbox, storage := newTestOutboxNotifier(t, NewMultiNotifier(notifier, NewWebhookNotifier(newWebhookTestConfig(t, server, schema.WebhookNotifierFormatSlack), nil)), clock)
+
+	require.NoError(t, outbox.Send(context.Background(), mail.Address{Address: "john@example.com"}, "Subject", &templates.EmailTemplate{Name: templates.TemplateNameEmailEvent}, nil))
+
+	assert.Equal(t, 1, outbox.Process(context.Background()))
+	assert.Equal(t, model.OutboundNotificationStatusSent, storage.notifications[0].Status)
+	assert.Equal(t, 1, storage.notifications[0].Attempts)
+
+	clock.Set(clock.Now().Add(time.Hour))
+
+	assert.Equal(t, 0, outbox.Process(context.Background()))
+	assert.Len(t, notifier.sent, 1)
+}
+
+func TestOutboxNotifierShouldSendImmediately(t *testing.T) {
+	clock := &utils.TestingClock{}
+	clock.Set(time.Unix(1000000, 0))
+
+	notifier := &testOutboxNotifier{}
+
+	outbox, storage := newTestOutboxNotifier(t, notifier, clock)
+
+	data := templates.EmailIdentityVerificationValues{Title: "Reset your password", LinkURL: "https://auth.example.com/reset-password/step2?token=abc"}
+
+	require.NoError(t, SendImmediately(context.Background(), outbox, mail.Address{Address: "john@example.com"}, "Reset your password", &templates.EmailTemplate{Name: templates.TemplateNameEmailIdentityVerification}, data))
+
+	assert.Len(t, storage.notifications, 0)
+	assert.Equal(t, []any{data}, notifier.sent)
+
+	notifier.err = errors.New("smtp failure")
+
+	assert.EqualError(t, SendImmediately(context.Background(), outbox, mail.Address{Address: "john@example.com"}, "Reset your password", &templates.EmailTemplate{Name: templates.TemplateNameEmailIdentityVerification}, data), "smtp failure")
+	assert.Len(t, storage.notifications, 0)
+
+	notifier.err = nil
+
+	require.NoError(t, SendImmediately(context.Background(), notifier, mail.Address{Address: "john@example.com"}, "Reset your password", &templates.EmailTemplate{Name: templates.TemplateNameEmailIdentityVerification}, data))
+	assert.Len(t, notifier.sent, 3)
+}
+
+func TestOutboxNotifierShouldErrorUnknownTemplate(t *testing.T) {
+	outbox, storage := newTestOutboxNotifier(t, &testOutboxNotifier{}, &utils.TestingClock{})
+
+	assert.EqualError(t, outbox.Send(context.Background(), mail.Address{Address: "john@example.com"}, "Subject", &templates.EmailTemplate{Name: "Unknown"}, nil), "notifier: outbox: failed to queue message: the template is not known")
+	assert.Len(t, storage.notifications, 0)
+}
+
+func TestOutboxNotifierBackoff(t *testing.T) {
+	outbox := &OutboxNotifier{config: schema.NotifierOutboxConfiguration{Backoff: time.Second * 30, MaxBackoff: time.Minute * 5}}
+
+	assert.Equal(t, time.Second*30, outbox.backoff(1))
+	assert.Equal(t, time.Minute, outbox.backoff(2))
+	assert.Equal(t, time.Minute*2, outbox.backoff(3))
+	assert.Equal(t, time.Minute*4, outbox.backoff(4))
+	assert.Equal(t, time.Minute*5, outbox.backoff(5))
+	assert.Equal(t, time.Minute*5, outbox.backoff(50))
+}
+
+func TestOutboxNotifierRunShouldDeliverOnWake(t *testing.T) {
+	notifier := &testOutboxNotifier{}
+
+	outbox, storage := newTestOutboxNotifier(t, notifier, utils.RealClock{})
+
+	done := make(chan error)
+
+	go func() {
+		done <- outbox.Run()
+	}()
+
+	require.NoError(t, outbox.Send(context.Background(), mail.Address{Address: "john@example.com"}, "Subject", &templates.EmailTemplate{Name: templates.TemplateNameEmailEvent}, nil))
+
+	assert.Eventually(t, func() bool {
+		return storage.status(1) == model.OutboundNotificationStatusSent
+	}, time.Second*5, time.Millisecond*10)
+
+	outbox.Shutdown()
+	outbox.Shutdown()
+
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,209 @@
+package notification
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+// newDKIMSigner creates a dkimSigner using the DKIM configuration.
+func newDKIMSigner(config *schema.SMTPNotifierDKIMConfiguration) *dkimSigner {
+	signer := &dkimSigner{
+		domain:   config.Domain,
+		selector: config.Selector,
+		headers:  config.Headers,
+		clock:    utils.RealClock{},
+	}
+
+	signer.key, _ = config.PrivateKey.(crypto.Signer)
+
+	return signer
+}
+
+// dkimSigner signs messages with a DKIM-Signature header using the relaxed header and body canonicalization algorithms
+// described in RFC6376.
+type dkimSigner struct {
+	domain   string
+	selector string
+	key      crypto.Signer
+	headers  []string
+	clock    utils.Clock
+}
+
+// Sign returns the value of the DKIM-Signature header for the message. The message must be the complete message
+// including the headers.
+func (s *dkimSigner) Sign(message []byte) (value string, err error) {
+	var (
+		algorithm string
+		opts      crypto.SignerOpts
+	)
+
+	switch s.key.(type) {
+	case nil:
+		return "", errors.New("the private key is not a valid signing key")
+	case ed25519.PrivateKey:
+		algorithm, opts = dkimAlgorithmEd25519SHA256, crypto.Hash(0)
+	default:
+		algorithm, opts = dkimAlgorithmRSASHA256, crypto.SHA256
+	}
+
+	header, body := smtpSplitMessage(smtpNormalizeLineEndings(message))
+
+	bh := sha256.Sum256(dkimCanonicalizeBodyRelaxed(body))
+
+	fields := smtpHeaderFields(header)
+
+	used := make([]bool, len(fields))
+	names := make([]string, 0, len(s.headers))
+
+	h := sha256.New()
+
+	for _, name := range s.headers {
+		names = append(names, strings.ToLower(name))
+
+		// Each occurrence of a header name signs the next instance of the field from the bottom of the header. If there
+		// are no more instances the name is still included which prevents the field from being added later.
+		for i := len(fields) - 1; i >= 0; i-- {
+			if used[i] || !strings.EqualFold(smtpHeaderFieldName(fields[i]), name) {
+				continue
+			}
+
+			used[i] = true
+
+			h.Write([]byte(dkimCanonicalizeHeaderRelaxed(fields[i])))
+			h.Write([]byte("\r\n"))
+
+			break
+		}
+	}
+
+	value = fmt.Sprintf("v=1; a=%s; c=relaxed/relaxed; d=%s; s=%s;\r\n t=%d; h=%s;\r\n bh=%s;\r\n b=",
+		algorithm, s.domain, s.selector, s.clock.Now().Unix(), strings.Join(names, ":"), base64.StdEncoding.EncodeToString(bh[:]))
+
+	h.Write([]byte(dkimCanonicalizeHeaderRelaxed(smtpHeaderDKIMSignature + ": " + value)))
+
+	var signature []byte
+
+	if signature, err = s.key.Sign(rand.Reader, h.Sum(nil), opts); err != nil {
+		return "", fmt.Errorf("failed to sign the message: %w", err)
+	}
+
+	return value + smtpFoldBase64(base64.StdEncoding.EncodeToString(signature), 72), nil
+}
+
+// dkimCanonicalizeHeaderRelaxed canonicalizes a header field using the relaxed algorithm described in RFC6376 Section
+// 3.4.2.
+func dkimCanonicalizeHeaderRelaxed(field string) string {
+	name, value, _ := strings.Cut(field, ":")
+
+	value = strings.NewReplacer("\r\n", "", "\n", "").Replace(value)
+
+	return strings.ToLower(strings.TrimRight(name, " \t")) + ":" + strings.Trim(dkimCompressWhitespace(value), " ")
+}
+
+// dkimCanonicalizeBodyRelaxed canonicalizes a body using the relaxed algorithm described in RFC6376 Section 3.4.4.
+func dkimCanonicalizeBodyRelaxed(body []byte) []byte {
+	lines := strings.Split(string(body), "\r\n")
+
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(dkimCompressWhitespace(line), " ")
+	}
+
+	for len(lines) != 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+func dkimCompressWhitespace(value string) string {
+	b := strings.Builder{}
+
+	space := false
+
+	for _, r := range value {
+		if r == ' ' || r == '\t' {
+			space = true
+
+			continue
+		}
+
+		if space {
+			b.WriteByte(' ')
+
+			space = false
+		}
+
+		b.WriteRune(r)
+	}
+
+	if space {
+		b.WriteByte(' ')
+	}
+
+	return b.String()
+}
+
+// smtpNormalizeLineEndings ensures every line in the message ends with CRLF as this is how the message is transmitted.
+func smtpNormalizeLineEndings(message []byte) []byte {
+	return bytes.ReplaceAll(bytes.ReplaceAll(message, []byte("\r\n"), []byte("\n")), []byte("\n"), []byte("\r\n"))
+}
+
+// smtpSplitMessage splits a message into the header and body. The header includes the CRLF of the last field.
+func smtpSplitMessage(message []byte) (header, body []byte) {
+	if i := bytes.Index(message, []byte("\r\n\r\n")); i >= 0 {
+		return message[:i+2], message[i+4:]
+	}
+
+	return message, nil
+}
+
+// smtpHeaderFields returns the header fields including any folded lines without the final CRLF.
+func smtpHeaderFields(header []byte) (fields []string) {
+	for _, line := range strings.Split(strings.TrimSuffix(string(header), "\r\n"), "\r\n") {
+		if len(fields) != 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			fields[len(fields)-1] += "\r\n" + line
+
+			continue
+		}
+
+		fields = append(fields, line)
+	}
+
+	return fields
+}
+
+func smtpHeaderFieldName(field string) string {
+	name, _, _ := strings.Cut(field, ":")
+
+	return strings.TrimSpace(name)
+}
+
+// smtpFoldBase64 folds a base64 value into lines of the given length.
+func smtpFoldBase64(value string, length int) string {
+	b := strings.Builder{}
+
+	for len(value) > length {
+		b.WriteString(value[:length])
+		b.WriteString("\r\n ")
+
+		value = value[length:]
+	}
+
+	b.WriteString(value)
+
+	return b.String()
+}
@@ -1,6 +1,7 @@
 package notification
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
@@ -62,7 +63,7 @@ func NewSMTPNotifier(config *schema.SMTPNotifierConfiguration, certPool *x509.Ce
 		domain = "localhost.localdomain"
 	}
 
-	return &SMTPNotifier{
+	notifier := &SMTPNotifier{
 		config: config,
 		domain: domain,
 		random: &random.Cryptographical{},
@@ -70,6 +71,16 @@ func NewSMTPNotifier(config *schema.SMTPNotifierConfiguration, certPool *x509.Ce
 		log:    logging.Logger(),
 		opts:   opts,
 	}
+
+	if config.DKIM != nil {
+		notifier.dkim = newDKIMSigner(config.DKIM)
+	}
+
+	if config.SMIME != nil {
+		notifier.smime = newSMIMESigner(config.SMIME)
+	}
+
+	return notifier
 }
 
 // SMTPNotifier a notifier to send emails to SMTP servers.
@@ -80,6 +91,9 @@ type SMTPNotifier struct {
 	tls    *tls.Config
 	log    *logrus.Logger
 	opts   []gomail.Option
+
+	dkim  *dkimSigner
+	smime *smimeSigner
 }
 
 // StartupCheck implements model.StartupCheck to perform startup check operations.
@@ -137,6 +151,10 @@ func (n *SMTPNotifier) Send(ctx context.Context, recipient mail.Address, subject
 		}
 	}
 
+	if err = n.sign(msg); err != nil {
+		return fmt.Errorf("notifier: smtp: failed to sign message: %w", err)
+	}
+
 	var client *gomail.Client
 
 	if client, err = gomail.NewClient(n.config.Host, n.opts...); err != nil {
@@ -162,6 +180,54 @@ func (n *SMTPNotifier) Send(ctx context.Context, recipient mail.Address, subject
 	return nil
 }
 
+// sign the message with S/MIME and DKIM if configured. The message is rendered to calculate the signatures which
+// requires all headers are set beforehand so the message is rendered identically when it's sent.
+func (n *SMTPNotifier) sign(msg *gomail.Msg) (err error) {
+	if n.smime == nil && n.dkim == nil {
+		return nil
+	}
+
+	msg.SetDate()
+
+	buf := &bytes.Buffer{}
+
+	if n.smime != nil {
+		if _, err = msg.WriteTo(buf); err != nil {
+			return fmt.Errorf("s/mime: failed to render message: %w", err)
+		}
+
+		entity := smimeEntity(buf.Bytes())
+
+		var signature []byte
+
+		if signature, err = n.smime.Sign(entity); err != nil {
+			return fmt.Errorf("s/mime: %w", err)
+		}
+
+		boundary := n.random.StringCustom(30, random.CharSetAlphaNumeric)
+
+		msg.SetBodyWriter(gomail.ContentType(smimeContentType(boundary)), smimeBodyWriter(boundary, entity, signature), gomail.WithPartEncoding(gomail.NoEncoding))
+
+		buf.Reset()
+	}
+
+	if n.dkim != nil {
+		if _, err = msg.WriteTo(buf); err != nil {
+			return fmt.Errorf("dkim: failed to render message: %w", err)
+		}
+
+		var value string
+
+		if value, err = n.dkim.Sign(buf.Bytes()); err != nil {
+			return fmt.Errorf("dkim: %w", err)
+		}
+
+		msg.SetGenHeaderPreformatted(smtpHeaderDKIMSignature, value)
+	}
+
+	return nil
+}
+
 func (n *SMTPNotifier) setMessageID(msg *gomail.Msg, domain string) {
 	rn := n.random.Intn(100000000)
 	rm := n.random.Intn(10000)
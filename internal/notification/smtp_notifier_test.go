@@ -0,0 +1,399 @@
+package notification
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"mime"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomail "github.com/wneessen/go-mail"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+)
+
+func TestDKIMCanonicalizeRelaxed(t *testing.T) {
+	// Example from RFC6376 Section 3.4.6.
+	assert.Equal(t, "a:X", dkimCanonicalizeHeaderRelaxed("A: X"))
+	assert.Equal(t, "b:Y Z", dkimCanonicalizeHeaderRelaxed("B : Y\t\r\n\tZ  "))
+	assert.Equal(t, " C\r\nD E\r\n", string(dkimCanonicalizeBodyRelaxed([]byte(" C \r\nD \t E\r\n\r\n\r\n"))))
+	assert.Nil(t, dkimCanonicalizeBodyRelaxed([]byte("\r\n\r\n")))
+}
+
+func TestSMTPNotifierShouldSignDKIM(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		key  schema.CryptographicPrivateKey
+	}{
+		{"ShouldSignRSA", rsaKey},
+		{"ShouldSignEd25519", ed25519Key},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifier := newSMTPTestNotifier(&schema.SMTPNotifierDKIMConfiguration{
+				Domain:     "example.com",
+				Selector:   "authelia",
+				PrivateKey: tc.key,
+				Headers:    schema.DefaultSMTPNotifierDKIMConfiguration.Headers,
+			}, nil)
+
+			msg := newSMTPTestMessage(t)
+
+			require.NoError(t, notifier.sign(msg))
+
+			rendered := &bytes.Buffer{}
+
+			_, err = msg.WriteTo(rendered)
+			require.NoError(t, err)
+
+			tags := verifyDKIMTestSignature(t, rendered.Bytes(), tc.key.Public())
+
+			assert.Equal(t, "example.com", tags["d"])
+			assert.Equal(t, "authelia", tags["s"])
+			assert.Equal(t, "relaxed/relaxed", tags["c"])
+			assert.Equal(t, "from:to:subject:date:message-id:mime-version:content-type", tags["h"])
+
+			tampered := bytes.Replace(rendered.Bytes(), []byte("Subject: Test"), []byte("Subject: Tampered"), 1)
+
+			assert.False(t, verifyDKIMSignature(tampered, tc.key.Public()))
+		})
+	}
+}
+
+func TestSMTPNotifierShouldSignSMIME(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		key       crypto.Signer
+		algorithm x509.SignatureAlgorithm
+	}{
+		{"ShouldSignRSA", rsaKey, x509.SHA256WithRSA},
+		{"ShouldSignECDSA", ecdsaKey, x509.ECDSAWithSHA256},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			certificate, chain := newSMIMETestCertificate(t, tc.key)
+
+			notifier := newSMTPTestNotifier(nil, &schema.SMTPNotifierSMIMEConfiguration{
+				PrivateKey:       tc.key.(schema.CryptographicPrivateKey),
+				CertificateChain: chain,
+			})
+
+			msg := newSMTPTestMessage(t)
+
+			unsigned := &bytes.Buffer{}
+
+			_, err = msg.WriteTo(unsigned)
+			require.NoError(t, err)
+
+			require.NoError(t, notifier.sign(msg))
+
+			rendered := &bytes.Buffer{}
+
+			_, err = msg.WriteTo(rendered)
+			require.NoError(t, err)
+
+			entity, signature := parseSMIMETestMessage(t, rendered.Bytes())
+
+			assert.Equal(t, smimeEntity(unsigned.Bytes()), entity)
+			assert.Contains(t, string(entity), "Content-Type: multipart/alternative;")
+
+			assert.NoError(t, verifySMIMESignature(entity, signature, certificate, tc.algorithm))
+			assert.EqualError(t, verifySMIMESignature(append(entity, 'x'), signature, certificate, tc.algorithm), "the message digest does not match the entity")
+		})
+	}
+}
+
+func TestSMTPNotifierShouldSignSMIMEAndDKIM(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	certificate, chain := newSMIMETestCertificate(t, key)
+
+	notifier := newSMTPTestNotifier(
+		&schema.SMTPNotifierDKIMConfiguration{Domain: "example.com", Selector: "authelia", PrivateKey: key, Headers: []string{"From", "Subject"}},
+		&schema.SMTPNotifierSMIMEConfiguration{PrivateKey: key, CertificateChain: chain},
+	)
+
+	msg := newSMTPTestMessage(t)
+
+	require.NoError(t, notifier.sign(msg))
+
+	rendered := &bytes.Buffer{}
+
+	_, err = msg.WriteTo(rendered)
+	require.NoError(t, err)
+
+	verifyDKIMTestSignature(t, rendered.Bytes(), key.Public())
+
+	entity, signature := parseSMIMETestMessage(t, rendered.Bytes())
+
+	assert.NoError(t, verifySMIMESignature(entity, signature, certificate, x509.SHA256WithRSA))
+}
+
+func TestSMTPNotifierSignShouldErrorInvalidKey(t *testing.T) {
+	notifier := newSMTPTestNotifier(&schema.SMTPNotifierDKIMConfiguration{Domain: "example.com", Selector: "authelia"}, nil)
+
+	assert.EqualError(t, notifier.sign(newSMTPTestMessage(t)), "dkim: the private key is not a valid signing key")
+}
+
+func newSMTPTestNotifier(dkim *schema.SMTPNotifierDKIMConfiguration, smime *schema.SMTPNotifierSMIMEConfiguration) *SMTPNotifier {
+	return NewSMTPNotifier(&schema.SMTPNotifierConfiguration{
+		Host:   "localhost",
+		Port:   25,
+		Sender: mail.Address{Name: "Authelia", Address: "authelia@example.com"},
+		TLS:    &schema.TLSConfig{},
+		DKIM:   dkim,
+		SMIME:  smime,
+	}, nil)
+}
+
+func newSMTPTestMessage(t *testing.T) *gomail.Msg {
+	msg := gomail.NewMsg(gomail.WithMIMEVersion(gomail.Mime10), gomail.WithBoundary("abc123"))
+
+	msg.SetMessageIDWithValue("1.2345.abc@example.com")
+
+	require.NoError(t, msg.From("Authelia <authelia@example.com>"))
+	require.NoError(t, msg.AddTo("John Smith <john@example.com>"))
+
+	msg.Subject("Test")
+	msg.AddAlternativeString(gomail.TypeTextHTML, "<p>Hi John Smith,</p>  \n<p>A   test.</p>\n\n")
+	msg.AddAlternativeString(gomail.TypeTextPlain, "Hi John Smith,\n\nA test with a line which is long enough that it has to be wrapped by the quoted printable encoding.\n")
+
+	return msg
+}
+
+func newSMIMETestCertificate(t *testing.T, key crypto.Signer) (certificate *x509.Certificate, chain schema.X509CertificateChain) {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "authelia@example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	certificate, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	c, err := schema.NewX509CertificateChain(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+	require.NoError(t, err)
+
+	return certificate, *c
+}
+
+func verifyDKIMTestSignature(t *testing.T, message []byte, key crypto.PublicKey) (tags map[string]string) {
+	tags, ok := verifyDKIMTestSignatureTags(message, key)
+
+	require.True(t, ok, "the DKIM signature is not valid")
+
+	return tags
+}
+
+func verifyDKIMSignature(message []byte, key crypto.PublicKey) bool {
+	_, ok := verifyDKIMTestSignatureTags(message, key)
+
+	return ok
+}
+
+func verifyDKIMTestSignatureTags(message []byte, key crypto.PublicKey) (tags map[string]string, ok bool) {
+	header, body := smtpSplitMessage(message)
+
+	var (
+		signature string
+		fields    []string
+	)
+
+	for _, field := range smtpHeaderFields(header) {
+		if strings.EqualFold(smtpHeaderFieldName(field), smtpHeaderDKIMSignature) {
+			signature = field
+
+			continue
+		}
+
+		fields = append(fields, field)
+	}
+
+	if signature == "" {
+		return nil, false
+	}
+
+	tags = map[string]string{}
+
+	_, value, _ := strings.Cut(signature, ":")
+
+	for _, tag := range strings.Split(value, ";") {
+		k, v, _ := strings.Cut(tag, "=")
+
+		tags[strings.TrimSpace(k)] = strings.Join(strings.Fields(v), "")
+	}
+
+	bh := sha256.Sum256(dkimCanonicalizeBodyRelaxed(body))
+
+	if base64.StdEncoding.EncodeToString(bh[:]) != tags["bh"] {
+		return tags, false
+	}
+
+	used := make([]bool, len(fields))
+
+	h := sha256.New()
+
+	for _, name := range strings.Split(tags["h"], ":") {
+		for i := len(fields) - 1; i >= 0; i-- {
+			if !used[i] && strings.EqualFold(smtpHeaderFieldName(fields[i]), name) {
+				used[i] = true
+
+				h.Write([]byte(dkimCanonicalizeHeaderRelaxed(fields[i]) + "\r\n"))
+
+				break
+			}
+		}
+	}
+
+	h.Write([]byte(dkimCanonicalizeHeaderRelaxed(signature[:strings.LastIndex(signature, "b=")+2])))
+
+	sig, err := base64.StdEncoding.DecodeString(tags["b"])
+	if err != nil {
+		return tags, false
+	}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return tags, tags["a"] == dkimAlgorithmRSASHA256 && rsa.VerifyPKCS1v15(k, crypto.SHA256, h.Sum(nil), sig) == nil
+	case ed25519.PublicKey:
+		return tags, tags["a"] == dkimAlgorithmEd25519SHA256 && ed25519.Verify(k, h.Sum(nil), sig)
+	default:
+		return tags, false
+	}
+}
+
+func parseSMIMETestMessage(t *testing.T, message []byte) (entity, signature []byte) {
+	header, body := smtpSplitMessage(message)
+
+	var contentType string
+
+	for _, field := range smtpHeaderFields(header) {
+		if strings.EqualFold(smtpHeaderFieldName(field), headerContentType) {
+			_, contentType, _ = strings.Cut(field, ":")
+		}
+	}
+
+	mediatype, params, err := mime.ParseMediaType(strings.ReplaceAll(contentType, "\r\n", ""))
+	require.NoError(t, err)
+
+	assert.Equal(t, "multipart/signed", mediatype)
+	assert.Equal(t, "application/pkcs7-signature", params["protocol"])
+	assert.Equal(t, "sha-256", params["micalg"])
+
+	delimiter := []byte("\r\n--" + params["boundary"])
+
+	parts := bytes.Split(body, delimiter)
+	require.Len(t, parts, 4)
+
+	entity = bytes.TrimPrefix(parts[1], []byte("\r\n"))
+
+	_, encoded := smtpSplitMessage(bytes.TrimPrefix(parts[2], []byte("\r\n")))
+
+	signature, err = base64.StdEncoding.DecodeString(string(bytes.ReplaceAll(encoded, []byte("\r\n"), nil)))
+	require.NoError(t, err)
+
+	return entity, signature
+}
+
+func verifySMIMESignature(entity, signature []byte, certificate *x509.Certificate, algorithm x509.SignatureAlgorithm) (err error) {
+	info := pkcs7ContentInfo{}
+
+	if _, err = asn1.Unmarshal(signature, &info); err != nil {
+		return err
+	}
+
+	if !info.ContentType.Equal(oidPKCS7SignedData) {
+		return errors.New("the content type is not signed data")
+	}
+
+	data := pkcs7SignedData{}
+
+	if _, err = asn1.Unmarshal(info.Content.Bytes, &data); err != nil {
+		return err
+	}
+
+	if len(data.SignerInfos) != 1 {
+		return fmt.Errorf("expected 1 signer but there are %d", len(data.SignerInfos))
+	}
+
+	signer := data.SignerInfos[0]
+
+	if !bytes.Equal(certificate.Raw, data.Certificates.Bytes) ||
+		!bytes.Equal(certificate.RawIssuer, signer.IssuerAndSerialNumber.Issuer.FullBytes) ||
+		certificate.SerialNumber.Cmp(signer.IssuerAndSerialNumber.SerialNumber) != 0 {
+		return errors.New("the signer does not match the certificate")
+	}
+
+	digest := sha256.Sum256(entity)
+
+	for rest := signer.AuthenticatedAttributes.Bytes; len(rest) != 0; {
+		attribute := pkcs7Attribute{}
+
+		if rest, err = asn1.Unmarshal(rest, &attribute); err != nil {
+			return err
+		}
+
+		if !attribute.Type.Equal(oidAttributeMessageDigest) {
+			continue
+		}
+
+		var value []byte
+
+		if _, err = asn1.Unmarshal(attribute.Values[0].FullBytes, &value); err != nil {
+			return err
+		}
+
+		if !bytes.Equal(digest[:], value) {
+			return errors.New("the message digest does not match the entity")
+		}
+
+		var set []byte
+
+		if set, err = asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: signer.AuthenticatedAttributes.Bytes}); err != nil {
+			return err
+		}
+
+		return certificate.CheckSignature(algorithm, set, signer.EncryptedDigest)
+	}
+
+	return errors.New("the message digest attribute is missing")
+}
@@ -0,0 +1,245 @@
+package notification
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+// newSMIMESigner creates a smimeSigner using the S/MIME configuration.
+func newSMIMESigner(config *schema.SMTPNotifierSMIMEConfiguration) *smimeSigner {
+	signer := &smimeSigner{
+		certificates: config.CertificateChain.Certificates(),
+		clock:        utils.RealClock{},
+	}
+
+	signer.key, _ = config.PrivateKey.(crypto.Signer)
+
+	return signer
+}
+
+// smimeSigner signs MIME entities with a detached PKCS #7 signature as described in RFC8551.
+type smimeSigner struct {
+	key          crypto.Signer
+	certificates []*x509.Certificate
+	clock        utils.Clock
+}
+
+// pkcs7ContentInfo is the ContentInfo type. The Content is the explicitly tagged [0] value which is encoded manually as
+// the field tags are not applied to an asn1.RawValue.
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"optional"`
+}
+
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	ContentInfo      pkcs7ContentInfo
+	Certificates     asn1.RawValue     `asn1:"optional,tag:0"`
+	SignerInfos      []pkcs7SignerInfo `asn1:"set"`
+}
+
+type pkcs7IssuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type pkcs7SignerInfo struct {
+	Version                   int
+	IssuerAndSerialNumber     pkcs7IssuerAndSerialNumber
+	DigestAlgorithm           pkix.AlgorithmIdentifier
+	AuthenticatedAttributes   asn1.RawValue `asn1:"optional,tag:0"`
+	DigestEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedDigest           []byte
+}
+
+type pkcs7Attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+// Sign returns the DER encoded detached signature of the MIME entity.
+func (s *smimeSigner) Sign(entity []byte) (signature []byte, err error) {
+	if s.key == nil {
+		return nil, errors.New("the private key is not a valid signing key")
+	}
+
+	if len(s.certificates) == 0 {
+		return nil, errors.New("the certificate chain is empty")
+	}
+
+	var algorithm pkix.AlgorithmIdentifier
+
+	switch s.key.(type) {
+	case *ecdsa.PrivateKey:
+		algorithm = pkix.AlgorithmIdentifier{Algorithm: oidSignatureECDSAWithSHA256}
+	default:
+		algorithm = pkix.AlgorithmIdentifier{Algorithm: oidSignatureRSA, Parameters: asn1.NullRawValue}
+	}
+
+	digest := sha256.Sum256(entity)
+
+	var attributes []byte
+
+	if attributes, err = s.attributes(digest[:]); err != nil {
+		return nil, fmt.Errorf("failed to encode the signed attributes: %w", err)
+	}
+
+	var set []byte
+
+	// The signature is calculated over the DER encoding of the attributes as a SET OF rather than the implicitly tagged
+	// value included in the SignerInfo.
+	if set, err = asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: attributes}); err != nil {
+		return nil, fmt.Errorf("failed to encode the signed attributes: %w", err)
+	}
+
+	hashed := sha256.Sum256(set)
+
+	var encrypted []byte
+
+	if encrypted, err = s.key.Sign(rand.Reader, hashed[:], crypto.SHA256); err != nil {
+		return nil, fmt.Errorf("failed to sign the attributes: %w", err)
+	}
+
+	certificates := bytes.Buffer{}
+
+	for _, certificate := range s.certificates {
+		certificates.Write(certificate.Raw)
+	}
+
+	var content []byte
+
+	if content, err = asn1.Marshal(pkcs7SignedData{
+		Version:          1,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{{Algorithm: oidDigestSHA256, Parameters: asn1.NullRawValue}},
+		ContentInfo:      pkcs7ContentInfo{ContentType: oidPKCS7Data},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: certificates.Bytes()},
+		SignerInfos: []pkcs7SignerInfo{
+			{
+				Version: 1,
+				IssuerAndSerialNumber: pkcs7IssuerAndSerialNumber{
+					Issuer:       asn1.RawValue{FullBytes: s.certificates[0].RawIssuer},
+					SerialNumber: s.certificates[0].SerialNumber,
+				},
+				DigestAlgorithm:           pkix.AlgorithmIdentifier{Algorithm: oidDigestSHA256, Parameters: asn1.NullRawValue},
+				AuthenticatedAttributes:   asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: attributes},
+				DigestEncryptionAlgorithm: algorithm,
+				EncryptedDigest:           encrypted,
+			},
+		},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to encode the signed data: %w", err)
+	}
+
+	if signature, err = asn1.Marshal(pkcs7ContentInfo{
+		ContentType: oidPKCS7SignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: content},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to encode the content info: %w", err)
+	}
+
+	return signature, nil
+}
+
+// attributes returns the DER encoded signed attributes sorted as required for a SET OF.
+func (s *smimeSigner) attributes(digest []byte) (attributes []byte, err error) {
+	values := []struct {
+		oid   asn1.ObjectIdentifier
+		value any
+	}{
+		{oidAttributeContentType, oidPKCS7Data},
+		{oidAttributeSigningTime, s.clock.Now().UTC()},
+		{oidAttributeMessageDigest, digest},
+	}
+
+	encoded := make([][]byte, len(values))
+
+	for i, v := range values {
+		var value, attribute []byte
+
+		if value, err = asn1.Marshal(v.value); err != nil {
+			return nil, err
+		}
+
+		if attribute, err = asn1.Marshal(pkcs7Attribute{Type: v.oid, Values: []asn1.RawValue{{FullBytes: value}}}); err != nil {
+			return nil, err
+		}
+
+		encoded[i] = attribute
+	}
+
+	sort.Slice(encoded, func(i, j int) bool {
+		return bytes.Compare(encoded[i], encoded[j]) < 0
+	})
+
+	return bytes.Join(encoded, nil), nil
+}
+
+// smimeEntity returns the MIME entity of a rendered message which is the content headers and the body.
+func smimeEntity(message []byte) []byte {
+	header, body := smtpSplitMessage(smtpNormalizeLineEndings(message))
+
+	entity := bytes.Buffer{}
+
+	for _, field := range smtpHeaderFields(header) {
+		if strings.HasPrefix(strings.ToLower(smtpHeaderFieldName(field)), "content-") {
+			entity.WriteString(field)
+			entity.WriteString("\r\n")
+		}
+	}
+
+	entity.WriteString("\r\n")
+	entity.Write(body)
+
+	return entity.Bytes()
+}
+
+// smimeContentType returns the Content-Type of a multipart/signed entity with the boundary.
+func smimeContentType(boundary string) string {
+	return fmt.Sprintf(`multipart/signed; protocol="application/pkcs7-signature"; micalg=sha-256; boundary="%s"`, boundary)
+}
+
+// smimeBodyWriter returns a function which writes the body of a multipart/signed entity with the signed entity as the
+// first part and the signature as the second part.
+func smimeBodyWriter(boundary string, entity, signature []byte) func(w io.Writer) (int64, error) {
+	return func(w io.Writer) (int64, error) {
+		buf := bytes.Buffer{}
+
+		buf.WriteString("This is a cryptographically signed message in MIME format.\r\n\r\n")
+		buf.WriteString("--" + boundary + "\r\n")
+		buf.Write(entity)
+		buf.WriteString("\r\n--" + boundary + "\r\n")
+		buf.WriteString("Content-Type: application/pkcs7-signature; name=\"smime.p7s\"\r\n")
+		buf.WriteString("Content-Transfer-Encoding: base64\r\n")
+		buf.WriteString("Content-Disposition: attachment; filename=\"smime.p7s\"\r\n\r\n")
+
+		encoded := base64.StdEncoding.EncodeToString(signature)
+
+		for len(encoded) > 76 {
+			buf.WriteString(encoded[:76] + "\r\n")
+
+			encoded = encoded[76:]
+		}
+
+		buf.WriteString(encoded + "\r\n")
+		buf.WriteString("--" + boundary + "--\r\n")
+
+		return buf.WriteTo(w)
+	}
+}
@@ -0,0 +1,210 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/templates"
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+// NewWebhookNotifier creates a WebhookNotifier using the webhook notifier configuration.
+func NewWebhookNotifier(config *schema.WebhookNotifierConfiguration, certPool *x509.CertPool) *WebhookNotifier {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+	}
+
+	if config.TLS != nil {
+		transport.TLSClientConfig = utils.NewTLSConfig(config.TLS, certPool)
+	}
+
+	return &WebhookNotifier{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout, Transport: transport},
+	}
+}
+
+// WebhookNotifier a notifier to send notifications to HTTP endpoints such as chat services.
+type WebhookNotifier struct {
+	config *schema.WebhookNotifierConfiguration
+	client *http.Client
+}
+
+// Name returns the configured name of the webhook.
+func (n *WebhookNotifier) Name() string {
+	return n.config.Name
+}
+
+// Matches returns true if the template should be sent to this webhook. The identity verification template is never
+// sent as it contains a link which grants access to the account of the user.
+func (n *WebhookNotifier) Matches(et *templates.EmailTemplate) bool {
+	if et == nil || et.Name == templates.TemplateNameEmailIdentityVerification {
+		return false
+	}
+
+	if len(n.config.Templates) == 0 {
+		return true
+	}
+
+	return utils.IsStringInSlice(et.Name, n.config.Templates)
+}
+
+// StartupCheck implements model.StartupCheck to perform startup check operations.
+func (n *WebhookNotifier) StartupCheck() (err error) {
+	var req *http.Request
+
+	switch n.config.Format {
+	case schema.WebhookNotifierFormatMatrix:
+		if req, err = http.NewRequest(http.MethodGet, n.matrixURL(webhookMatrixPathWhoAmI), nil); err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set(headerAuthorization, "Bearer "+n.config.AccessToken)
+	default:
+		if req, err = http.NewRequest(http.MethodHead, n.config.URL.String(), nil); err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+	}
+
+	var resp *http.Response
+
+	if resp, err = n.client.Do(req); err != nil {
+		return fmt.Errorf("failed to perform request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	// Incoming webhook endpoints generally don't respond successfully to anything other than a valid message, so a
+	// response of any kind is considered successful for formats other than matrix.
+	if n.config.Format == schema.WebhookNotifierFormatMatrix && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to validate the access token: the server responded with status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Send a notification via the WebhookNotifier.
+func (n *WebhookNotifier) Send(ctx context.Context, recipient mail.Address, subject string, et *templates.EmailTemplate, data any) (err error) {
+	buf := &bytes.Buffer{}
+
+	if err = et.Text.Execute(buf, data); err != nil {
+		return fmt.Errorf("notifier: webhook: failed to execute template: %w", err)
+	}
+
+	body := strings.TrimSpace(buf.String())
+
+	var (
+		method, target string
+		payload        any
+	)
+
+	switch n.config.Format {
+	case schema.WebhookNotifierFormatSlack:
+		method, target, payload = http.MethodPost, n.config.URL.String(), webhookChatPayload{Text: fmt.Sprintf("*%s*\n\n%s", subject, body)}
+	case schema.WebhookNotifierFormatMattermost:
+		method, target, payload = http.MethodPost, n.config.URL.String(), webhookChatPayload{Text: fmt.Sprintf("**%s**\n\n%s", subject, body)}
+	case schema.WebhookNotifierFormatMatrix:
+		method = http.MethodPut
+		target = n.matrixURL(fmt.Sprintf(webhookMatrixPathSendFmt, url.PathEscape(n.config.RoomID), uuid.NewString()))
+		payload = webhookMatrixPayload{MsgType: "m.notice", Body: fmt.Sprintf("%s\n\n%s", subject, body)}
+	default:
+		method, target = http.MethodPost, n.config.URL.String()
+		payload = webhookGenericPayload{
+			Time:      time.Now().UTC(),
+			Template:  et.Name,
+			Recipient: webhookGenericRecipient{Name: recipient.Name, Address: recipient.Address},
+			Subject:   subject,
+			Body:      body,
+			Data:      data,
+		}
+	}
+
+	var raw []byte
+
+	if raw, err = json.Marshal(payload); err != nil {
+		return fmt.Errorf("notifier: webhook: failed to marshal payload: %w", err)
+	}
+
+	var req *http.Request
+
+	if req, err = http.NewRequestWithContext(ctx, method, target, bytes.NewReader(raw)); err != nil {
+		return fmt.Errorf("notifier: webhook: failed to create request: %w", err)
+	}
+
+	req.Header.Set(headerContentType, "application/json")
+
+	switch n.config.Format {
+	case schema.WebhookNotifierFormatMatrix:
+		req.Header.Set(headerAuthorization, "Bearer "+n.config.AccessToken)
+	case schema.WebhookNotifierFormatGeneric:
+		req.Header.Set(headerWebhookSignature, WebhookSignature(n.config.Secret, raw))
+	}
+
+	var resp *http.Response
+
+	if resp, err = n.client.Do(req); err != nil {
+		return fmt.Errorf("notifier: webhook: failed to perform request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notifier: webhook: failed to send message: the server responded with status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (n *WebhookNotifier) matrixURL(path string) string {
+	return strings.TrimSuffix(n.config.URL.String(), "/") + path
+}
+
+// WebhookSignature returns the value of the signature header for a generic webhook payload which is the hex encoded
+// HMAC-SHA256 of the payload using the secret as the key.
+func WebhookSignature(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type webhookGenericPayload struct {
+	Time      time.Time               `json:"time"`
+	Template  string                  `json:"template"`
+	Recipient webhookGenericRecipient `json:"recipient"`
+	Subject   string                  `json:"subject"`
+	Body      string                  `json:"body"`
+	Data      any                     `json:"data,omitempty"`
+}
+
+type webhookGenericRecipient struct {
+	Name    string `json:"name,omitempty"`
+	Address string `json:"address"`
+}
+
+type webhookChatPayload struct {
+	Text string `json:"text"`
+}
+
+type webhookMatrixPayload struct {
+	MsgType string `json:"msgtype"`
+	Body    string `json:"body"`
+}
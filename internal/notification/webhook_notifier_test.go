@@ -0,0 +1,276 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/url"
+	"strings"
+	"testing"
+	tt "text/template"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/templates"
+)
+
+type webhookTestRequest struct {
+	method string
+	path   string
+	header http.Header
+	body   []byte
+}
+
+func newWebhookTestServer(t *testing.T, status int) (server *httptest.Server, requests chan webhookTestRequest) {
+	requests = make(chan webhookTestRequest, 10)
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		requests <- webhookTestRequest{method: r.Method, path: r.URL.Path, header: r.Header, body: body}
+
+		w.WriteHeader(status)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, requests
+}
+
+func newWebhookTestConfig(t *testing.T, server *httptest.Server, format string) *schema.WebhookNotifierConfiguration {
+	u, err := url.Parse(server.URL + "/hook")
+	require.NoError(t, err)
+
+	return &schema.WebhookNotifierConfiguration{
+		Name:        "test",
+		Format:      format,
+		URL:         u,
+		Secret:      "secret",
+		AccessToken: "token",
+		RoomID:      "!room:example.com",
+		Timeout:     time.Second,
+	}
+}
+
+func newWebhookTestTemplate(name string) *templates.EmailTemplate {
+	return &templates.EmailTemplate{
+		Name: name,
+		Text: tt.Must(tt.New(name).Parse("Hi {{ .DisplayName }},\n\n{{ .Title }}\n")),
+	}
+}
+
+func TestWebhookNotifierSend(t *testing.T) {
+	testCases := []struct {
+		name     string
+		format   string
+		method   string
+		path     string
+		expected func(t *testing.T, req webhookTestRequest)
+	}{
+		{
+			"ShouldSendGeneric",
+			schema.WebhookNotifierFormatGeneric,
+			http.MethodPost,
+			"/hook",
+			func(t *testing.T, req webhookTestRequest) {
+				assert.Equal(t, WebhookSignature("secret", req.body), req.header.Get("X-Authelia-Signature"))
+				assert.True(t, strings.HasPrefix(req.header.Get("X-Authelia-Signature"), "sha256="))
+				assert.Equal(t, "", req.header.Get("Authorization"))
+
+				payload := map[string]any{}
+
+				require.NoError(t, json.Unmarshal(req.body, &payload))
+
+				assert.Equal(t, "Event", payload["template"])
+				assert.Equal(t, "Password changed", payload["subject"])
+				assert.Equal(t, "Hi John Smith,\n\nYour password has been changed", payload["body"])
+				assert.Equal(t, map[string]any{"name": "John Smith", "address": "john@example.com"}, payload["recipient"])
+				assert.Equal(t, map[string]any{"DisplayName": "John Smith", "Title": "Your password has been changed"}, payload["data"])
+			},
+		},
+		{
+			"ShouldSendSlack",
+			schema.WebhookNotifierFormatSlack,
+			http.MethodPost,
+			"/hook",
+			func(t *testing.T, req webhookTestRequest) {
+				assert.Equal(t, "", req.header.Get("X-Authelia-Signature"))
+				assert.JSONEq(t, `{"text":"*Password changed*\n\nHi John Smith,\n\nYour password has been changed"}`, string(req.body))
+			},
+		},
+		{
+			"ShouldSendMattermost",
+			schema.WebhookNotifierFormatMattermost,
+			http.MethodPost,
+			"/hook",
+			func(t *testing.T, req webhookTestRequest) {
+				assert.JSONEq(t, `{"text":"**Password changed**\n\nHi John Smith,\n\nYour password has been changed"}`, string(req.body))
+			},
+		},
+		{
+			"ShouldSendMatrix",
+			schema.WebhookNotifierFormatMatrix,
+			http.MethodPut,
+			"/hook/_matrix/client/v3/rooms/!room:example.com/send/m.room.message/",
+			func(t *testing.T, req webhookTestRequest) {
+				assert.Equal(t, "Bearer token", req.header.Get("Authorization"))
+				assert.JSONEq(t, `{"msgtype":"m.notice","body":"Password changed\n\nHi John Smith,\n\nYour password has been changed"}`, string(req.body))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server, requests := newWebhookTestServer(t, http.StatusOK)
+
+			notifier := NewWebhookNotifier(newWebhookTestConfig(t, server, tc.format), nil)
+
+			data := map[string]any{"DisplayName": "John Smith", "Title": "Your password has been changed"}
+
+			require.NoError(t, notifier.Send(context.Background(), mail.Address{Name: "John Smith", Address: "john@example.com"}, "Password changed", newWebhookTestTemplate(templates.TemplateNameEmailEvent), data))
+
+			req := <-requests
+
+			assert.Equal(t, tc.method, req.method)
+			assert.True(t, strings.HasPrefix(req.path, tc.path), "path '%s' does not have the prefix '%s'", req.path, tc.path)
+			assert.Equal(t, "application/json", req.header.Get("Content-Type"))
+
+			tc.expected(t, req)
+		})
+	}
+}
+
+func TestWebhookNotifierSendShouldErrorOnStatus(t *testing.T) {
+	server, _ := newWebhookTestServer(t, http.StatusBadRequest)
+
+	notifier := NewWebhookNotifier(newWebhookTestConfig(t, server, schema.WebhookNotifierFormatSlack), nil)
+
+	err := notifier.Send(context.Background(), mail.Address{Address: "john@example.com"}, "Subject", newWebhookTestTemplate(templates.TemplateNameEmailEvent), nil)
+
+	assert.EqualError(t, err, "notifier: webhook: failed to send message: the server responded with status code 400")
+}
+
+func TestWebhookNotifierStartupCheck(t *testing.T) {
+	testCases := []struct {
+		name     string
+		format   string
+		status   int
+		method   string
+		path     string
+		expected string
+	}{
+		{"ShouldSucceedGenericAnyStatus", schema.WebhookNotifierFormatGeneric, http.StatusMethodNotAllowed, http.MethodHead, "/hook", ""},
+		{"ShouldSucceedMatrix", schema.WebhookNotifierFormatMatrix, http.StatusOK, http.MethodGet, "/hook/_matrix/client/v3/account/whoami", ""},
+		{"ShouldFailMatrixUnauthorized", schema.WebhookNotifierFormatMatrix, http.StatusUnauthorized, http.MethodGet, "/hook/_matrix/client/v3/account/whoami", "failed to validate the access token: the server responded with status code 401"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server, requests := newWebhookTestServer(t, tc.status)
+
+			notifier := NewWebhookNotifier(newWebhookTestConfig(t, server, tc.format), nil)
+
+			err := notifier.StartupCheck()
+
+			if tc.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expected)
+			}
+
+			req := <-requests
+
+			assert.Equal(t, tc.method, req.method)
+			assert.Equal(t, tc.path, req.path)
+		})
+	}
+}
+
+func TestWebhookNotifierMatches(t *testing.T) {
+	notifier := NewWebhookNotifier(&schema.WebhookNotifierConfiguration{}, nil)
+
+	assert.True(t, notifier.Matches(newWebhookTestTemplate(templates.TemplateNameEmailEvent)))
+	assert.False(t, notifier.Matches(newWebhookTestTemplate(templates.TemplateNameEmailIdentityVerification)))
+	assert.False(t, notifier.Matches(nil))
+
+	notifier = NewWebhookNotifier(&schema.WebhookNotifierConfiguration{Templates: []string{"Other"}}, nil)
+
+	assert.False(t, notifier.Matches(newWebhookTestTemplate(templates.TemplateNameEmailEvent)))
+	assert.True(t, notifier.Matches(newWebhookTestTemplate("Other")))
+}
+
+type testPrimaryNotifier struct {
+	startup error
+	send    error
+	sent    []string
+}
+
+func (n *testPrimaryNotifier) StartupCheck() error {
+	return n.startup
+}
+
+func (n *testPrimaryNotifier) Send(_ context.Context, _ mail.Address, subject string, _ *templates.EmailTemplate, _ any) error {
+	n.sent = append(n.sent, subject)
+
+	return n.send
+}
+
+func TestMultiNotifierSend(t *testing.T) {
+	server, requests := newWebhookTestServer(t, http.StatusOK)
+
+	primary := &testPrimaryNotifier{send: errors.New("smtp failure")}
+
+	notifier := NewMultiNotifier(primary, NewWebhookNotifier(newWebhookTestConfig(t, server, schema.WebhookNotifierFormatSlack), nil))
+
+	require.NoError(t, notifier.StartupCheck())
+
+	<-requests
+
+	assert.EqualError(t, notifier.Send(context.Background(), mail.Address{Address: "john@example.com"}, "Event", newWebhookTestTemplate(templates.TemplateNameEmailEvent), nil), "smtp failure")
+
+	req := <-requests
+
+	assert.Equal(t, http.MethodPost, req.method)
+
+	primary.send = nil
+
+	assert.NoError(t, notifier.Send(context.Background(), mail.Address{Address: "john@example.com"}, "Verify", newWebhookTestTemplate(templates.TemplateNameEmailIdentityVerification), nil))
+
+	assert.Equal(t, []string{"Event", "Verify"}, primary.sent)
+	assert.Len(t, requests, 0)
+}
+
+func TestMultiNotifierShouldLogWebhookErrors(t *testing.T) {
+	server, _ := newWebhookTestServer(t, http.StatusInternalServerError)
+
+	primary := &testPrimaryNotifier{}
+
+	notifier := NewMultiNotifier(primary, NewWebhookNotifier(newWebhookTestConfig(t, server, schema.WebhookNotifierFormatMatrix), nil))
+
+	logger, hook := test.NewNullLogger()
+
+	notifier.log = logger.WithField("notifier", "webhook")
+
+	assert.EqualError(t, notifier.StartupCheck(), "webhook 'test': failed to validate the access token: the server responded with status code 500")
+	assert.NoError(t, notifier.Send(context.Background(), mail.Address{Address: "john@example.com"}, "Event", newWebhookTestTemplate(templates.TemplateNameEmailEvent), nil))
+
+	require.NotNil(t, hook.LastEntry())
+	assert.Equal(t, "Error occurred sending the notification to the webhook", hook.LastEntry().Message)
+	assert.Equal(t, "test", hook.LastEntry().Data["webhook"])
+	assert.EqualError(t, hook.LastEntry().Data[logrus.ErrorKey].(error), "notifier: webhook: failed to send message: the server responded with status code 500")
+	assert.Equal(t, []string{"Event"}, primary.sent)
+
+	primary.startup = errors.New("smtp failure")
+
+	assert.EqualError(t, notifier.StartupCheck(), "smtp failure")
+}
@@ -36,6 +36,8 @@ func NewClient(config schema.OpenIDConnectClientConfiguration) (client *Client)
 
 		Policy: authorization.NewLevel(config.Policy),
 
+		MaxAuthenticationAge: config.MaxAuthenticationAge,
+
 		Consent: NewClientConsent(config.ConsentMode, config.ConsentPreConfiguredDuration),
 	}
 
@@ -12,6 +12,7 @@ import (
 	"gopkg.in/square/go-jose.v2"
 
 	"github.com/authelia/authelia/v4/internal/authorization"
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
 	"github.com/authelia/authelia/v4/internal/model"
 	"github.com/authelia/authelia/v4/internal/storage"
 	"github.com/authelia/authelia/v4/internal/utils"
@@ -122,6 +123,8 @@ type Client struct {
 
 	Policy authorization.Level
 
+	MaxAuthenticationAge schema.MaxAuthenticationAge
+
 	Consent ClientConsent
 }
 
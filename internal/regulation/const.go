@@ -17,4 +17,11 @@ const (
 
 	// AuthTypeDuo is the string representing an auth log for second-factor authentication via DUO.
 	AuthTypeDuo = "Duo"
+
+	// AuthTypePasswordChange is the string representing an auth log for a password change by a logged in user, which
+	// includes the check of their current password.
+	AuthTypePasswordChange = "PWChange"
 )
+
+// userAgentMaxLength is the maximum length of the user agent recorded in the authentication log.
+const userAgentMaxLength = 512
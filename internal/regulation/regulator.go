@@ -35,6 +35,7 @@ func (r *Regulator) Mark(ctx Context, successful, banned bool, username, request
 		RemoteIP:      model.NewNullIP(ctx.RemoteIP()),
 		RequestURI:    requestURI,
 		RequestMethod: requestMethod,
+		UserAgent:     NormalizeUserAgent(ctx.UserAgent()),
 	})
 }
 
@@ -81,3 +82,12 @@ func (r *Regulator) Regulate(ctx context.Context, username string) (time.Time, e
 
 	return time.Time{}, nil
 }
+
+// NormalizeUserAgent returns the user agent as it's recorded in the authentication log.
+func NormalizeUserAgent(userAgent []byte) string {
+	if len(userAgent) > userAgentMaxLength {
+		userAgent = userAgent[:userAgentMaxLength]
+	}
+
+	return string(userAgent)
+}
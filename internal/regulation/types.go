@@ -27,6 +27,7 @@ type Context interface {
 	MetricsRecorder
 
 	RemoteIP() (ip net.IP)
+	UserAgent() (userAgent []byte)
 }
 
 // MetricsRecorder represents the methods used to record regulation.
@@ -205,6 +205,7 @@ func handleRouter(config *schema.Configuration, providers middlewares.Providers)
 	delayFunc := middlewares.TimingAttackDelay(10, 250, 85, time.Second, true)
 
 	r.POST("/api/firstfactor", middlewareAPI(handlers.FirstFactorPOST(delayFunc)))
+	r.POST("/api/firstfactor/password-change", middlewareAPI(handlers.FirstFactorPasswordChangePOST))
 	r.POST("/api/logout", middlewareAPI(handlers.LogoutPOST))
 
 	// Only register endpoints if forgot password is not disabled.
@@ -216,6 +217,11 @@ func handleRouter(config *schema.Configuration, providers middlewares.Providers)
 		r.POST("/api/reset-password", middlewareAPI(handlers.ResetPasswordPOST))
 	}
 
+	// Only register the password change endpoint if it is not disabled.
+	if !config.AuthenticationBackend.PasswordChange.Disable {
+		r.POST("/api/change-password", middleware1FA(handlers.ChangePasswordPOST))
+	}
+
 	// Information about the user.
 	r.GET("/api/user/info", middleware1FA(handlers.UserInfoGET))
 	r.POST("/api/user/info", middleware1FA(handlers.UserInfoPOST))
@@ -205,12 +205,13 @@ func NewTemplatedFileOptions(config *schema.Configuration) (opts *TemplatedFileO
 		ResetPasswordCustomURL: config.AuthenticationBackend.PasswordReset.CustomURL.String(),
 		Theme:                  config.Theme,
 
-		EndpointsPasswordReset: !(config.AuthenticationBackend.PasswordReset.Disable || config.AuthenticationBackend.PasswordReset.CustomURL.String() != ""),
-		EndpointsWebauthn:      !config.Webauthn.Disable,
-		EndpointsTOTP:          !config.TOTP.Disable,
-		EndpointsDuo:           !config.DuoAPI.Disable,
-		EndpointsOpenIDConnect: !(config.IdentityProviders.OIDC == nil),
-		EndpointsAuthz:         config.Server.Endpoints.Authz,
+		EndpointsPasswordReset:  !(config.AuthenticationBackend.PasswordReset.Disable || config.AuthenticationBackend.PasswordReset.CustomURL.String() != ""),
+		EndpointsPasswordChange: !config.AuthenticationBackend.PasswordChange.Disable,
+		EndpointsWebauthn:       !config.Webauthn.Disable,
+		EndpointsTOTP:           !config.TOTP.Disable,
+		EndpointsDuo:            !config.DuoAPI.Disable,
+		EndpointsOpenIDConnect:  !(config.IdentityProviders.OIDC == nil),
+		EndpointsAuthz:          config.Server.Endpoints.Authz,
 	}
 
 	if config.PrivacyPolicy.Enabled {
@@ -237,11 +238,12 @@ type TemplatedFileOptions struct {
 	Session                string
 	Theme                  string
 
-	EndpointsPasswordReset bool
-	EndpointsWebauthn      bool
-	EndpointsTOTP          bool
-	EndpointsDuo           bool
-	EndpointsOpenIDConnect bool
+	EndpointsPasswordReset  bool
+	EndpointsPasswordChange bool
+	EndpointsWebauthn       bool
+	EndpointsTOTP           bool
+	EndpointsDuo            bool
+	EndpointsOpenIDConnect  bool
 
 	EndpointsAuthz map[string]schema.ServerAuthzEndpoint
 }
@@ -293,6 +295,7 @@ func (options *TemplatedFileOptions) OpenAPIData(base, baseURL, nonce string) Te
 
 		Session:        options.Session,
 		PasswordReset:  options.EndpointsPasswordReset,
+		PasswordChange: options.EndpointsPasswordChange,
 		Webauthn:       options.EndpointsWebauthn,
 		TOTP:           options.EndpointsTOTP,
 		Duo:            options.EndpointsDuo,
@@ -319,15 +322,16 @@ type TemplatedFileCommonData struct {
 
 // TemplatedFileOpenAPIData is a struct which is used for the OpenAPI spec file.
 type TemplatedFileOpenAPIData struct {
-	Base          string
-	BaseURL       string
-	CSPNonce      string
-	Session       string
-	PasswordReset bool
-	Webauthn      bool
-	TOTP          bool
-	Duo           bool
-	OpenIDConnect bool
+	Base           string
+	BaseURL        string
+	CSPNonce       string
+	Session        string
+	PasswordReset  bool
+	PasswordChange bool
+	Webauthn       bool
+	TOTP           bool
+	Duo            bool
+	OpenIDConnect  bool
 
 	EndpointsAuthz map[string]schema.ServerAuthzEndpoint
 }
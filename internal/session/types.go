@@ -33,6 +33,10 @@ type UserSession struct {
 	FirstFactorAuthnTimestamp  int64
 	SecondFactorAuthnTimestamp int64
 
+	// This boolean is set to true when the first factor exceeded a maximum authentication age while the second factor
+	// did not, allowing the two factor authentication level to be restored once the first factor is performed again.
+	FirstFactorReauthentication bool
+
 	AuthenticationMethodRefs oidc.AuthenticationMethodsReferences
 
 	// Webauthn holds the session registration data for this session.
@@ -42,9 +46,24 @@ type UserSession struct {
 	// while doing the query actually updating the password.
 	PasswordResetUsername *string
 
+	// PasswordChangeRequired is set after the first factor when the password of the user was correct but has expired
+	// or must be changed, and is checked while changing the password. The session is not elevated by the first factor
+	// in this situation.
+	PasswordChangeRequired *PasswordChangeRequired
+
+	// PasswordChangeTimestamp is the time the password of the user was last changed using this session, and prevents
+	// this session from being invalidated by the password change.
+	PasswordChangeTimestamp int64
+
 	RefreshTTL time.Time
 }
 
+// PasswordChangeRequired is the state of a password change which is required before the user is allowed to login.
+type PasswordChangeRequired struct {
+	Username string
+	Expires  time.Time
+}
+
 // Identity identity of the user who is being verified.
 type Identity struct {
 	Username    string
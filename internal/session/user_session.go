@@ -6,6 +6,7 @@ import (
 
 	"github.com/authelia/authelia/v4/internal/authentication"
 	"github.com/authelia/authelia/v4/internal/authorization"
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
 )
 
 // NewDefaultUserSession create a default user session.
@@ -26,7 +27,14 @@ func (s *UserSession) IsAnonymous() bool {
 func (s *UserSession) SetOneFactor(now time.Time, details *authentication.UserDetails, keepMeLoggedIn bool) {
 	s.FirstFactorAuthnTimestamp = now.Unix()
 	s.LastActivity = now.Unix()
-	s.AuthenticationLevel = authentication.OneFactor
+
+	if s.FirstFactorReauthentication && s.Username == details.Username && s.SecondFactorAuthnTimestamp != 0 {
+		s.AuthenticationLevel = authentication.TwoFactor
+	} else {
+		s.AuthenticationLevel = authentication.OneFactor
+	}
+
+	s.FirstFactorReauthentication = false
 
 	s.KeepMeLoggedIn = keepMeLoggedIn
 
@@ -76,3 +84,44 @@ func (s *UserSession) AuthenticatedTime(level authorization.Level) (authenticate
 		return time.Unix(0, 0), errors.New("invalid authorization level")
 	}
 }
+
+// StaleFactors returns true for each factor which is required for the authorization level and was performed longer ago
+// than the respective maximum authentication age. A maximum authentication age of zero is never considered stale.
+func (s *UserSession) StaleFactors(now time.Time, level authorization.Level, maxAge schema.MaxAuthenticationAge) (firstFactor, secondFactor bool) {
+	if s.IsAnonymous() {
+		return false, false
+	}
+
+	switch level {
+	case authorization.OneFactor, authorization.TwoFactor:
+		firstFactor = isAuthenticationStale(now, s.FirstFactorAuthnTimestamp, maxAge.FirstFactor)
+	default:
+		return false, false
+	}
+
+	if level == authorization.TwoFactor && s.AuthenticationLevel >= authentication.TwoFactor {
+		secondFactor = isAuthenticationStale(now, s.SecondFactorAuthnTimestamp, maxAge.SecondFactor)
+	}
+
+	return firstFactor, secondFactor
+}
+
+// SetStaleFactors lowers the authentication level of the session so that only the stale factors have to be performed
+// again.
+func (s *UserSession) SetStaleFactors(firstFactor, secondFactor bool) {
+	switch {
+	case firstFactor:
+		s.FirstFactorReauthentication = !secondFactor && s.AuthenticationLevel >= authentication.TwoFactor
+		s.AuthenticationLevel = authentication.NotAuthenticated
+	case secondFactor:
+		s.AuthenticationLevel = authentication.OneFactor
+	}
+}
+
+func isAuthenticationStale(now time.Time, timestamp int64, maxAge time.Duration) bool {
+	if maxAge <= 0 {
+		return false
+	}
+
+	return time.Unix(timestamp, 0).Add(maxAge).Before(now)
+}
@@ -0,0 +1,131 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/authelia/authelia/v4/internal/authentication"
+	"github.com/authelia/authelia/v4/internal/authorization"
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+)
+
+func TestUserSession_StaleFactors(t *testing.T) {
+	now := time.Unix(1000000, 0)
+
+	maxAge := schema.MaxAuthenticationAge{
+		FirstFactor:  time.Hour * 12,
+		SecondFactor: time.Hour,
+	}
+
+	testCases := []struct {
+		name                                      string
+		have                                      UserSession
+		level                                     authorization.Level
+		maxAge                                    schema.MaxAuthenticationAge
+		expectedFirstFactor, expectedSecondFactor bool
+	}{
+		{
+			"ShouldNotBeStaleWhenFresh",
+			UserSession{Username: "john", AuthenticationLevel: authentication.TwoFactor, FirstFactorAuthnTimestamp: now.Add(-time.Minute).Unix(), SecondFactorAuthnTimestamp: now.Add(-time.Minute).Unix()},
+			authorization.TwoFactor, maxAge, false, false,
+		},
+		{
+			"ShouldBeStaleSecondFactor",
+			UserSession{Username: "john", AuthenticationLevel: authentication.TwoFactor, FirstFactorAuthnTimestamp: now.Add(-time.Minute).Unix(), SecondFactorAuthnTimestamp: now.Add(-time.Hour * 2).Unix()},
+			authorization.TwoFactor, maxAge, false, true,
+		},
+		{
+			"ShouldNotBeStaleSecondFactorOneFactorPolicy",
+			UserSession{Username: "john", AuthenticationLevel: authentication.TwoFactor, FirstFactorAuthnTimestamp: now.Add(-time.Minute).Unix(), SecondFactorAuthnTimestamp: now.Add(-time.Hour * 2).Unix()},
+			authorization.OneFactor, maxAge, false, false,
+		},
+		{
+			"ShouldBeStaleFirstFactor",
+			UserSession{Username: "john", AuthenticationLevel: authentication.OneFactor, FirstFactorAuthnTimestamp: now.Add(-time.Hour * 13).Unix()},
+			authorization.OneFactor, maxAge, true, false,
+		},
+		{
+			"ShouldBeStaleBoth",
+			UserSession{Username: "john", AuthenticationLevel: authentication.TwoFactor, FirstFactorAuthnTimestamp: now.Add(-time.Hour * 13).Unix(), SecondFactorAuthnTimestamp: now.Add(-time.Hour * 2).Unix()},
+			authorization.TwoFactor, maxAge, true, true,
+		},
+		{
+			"ShouldNotBeStaleWithoutMaxAge",
+			UserSession{Username: "john", AuthenticationLevel: authentication.TwoFactor, FirstFactorAuthnTimestamp: now.Add(-time.Hour * 13).Unix(), SecondFactorAuthnTimestamp: now.Add(-time.Hour * 2).Unix()},
+			authorization.TwoFactor, schema.MaxAuthenticationAge{}, false, false,
+		},
+		{
+			"ShouldNotBeStaleAnonymous",
+			UserSession{},
+			authorization.TwoFactor, maxAge, false, false,
+		},
+		{
+			"ShouldNotBeStaleBypass",
+			UserSession{Username: "john", AuthenticationLevel: authentication.TwoFactor, FirstFactorAuthnTimestamp: now.Add(-time.Hour * 13).Unix(), SecondFactorAuthnTimestamp: now.Add(-time.Hour * 2).Unix()},
+			authorization.Bypass, maxAge, false, false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			firstFactor, secondFactor := tc.have.StaleFactors(now, tc.level, tc.maxAge)
+
+			assert.Equal(t, tc.expectedFirstFactor, firstFactor)
+			assert.Equal(t, tc.expectedSecondFactor, secondFactor)
+		})
+	}
+}
+
+func TestUserSession_SetStaleFactorsShouldRestoreTwoFactor(t *testing.T) {
+	now := time.Unix(1000000, 0)
+
+	details := &authentication.UserDetails{Username: "john"}
+
+	userSession := UserSession{}
+
+	userSession.SetOneFactor(now, details, false)
+	userSession.SetTwoFactorTOTP(now)
+
+	userSession.SetStaleFactors(false, true)
+
+	assert.Equal(t, authentication.OneFactor, userSession.AuthenticationLevel)
+	assert.False(t, userSession.FirstFactorReauthentication)
+
+	userSession.SetTwoFactorTOTP(now)
+
+	userSession.SetStaleFactors(true, false)
+
+	assert.Equal(t, authentication.NotAuthenticated, userSession.AuthenticationLevel)
+	assert.True(t, userSession.FirstFactorReauthentication)
+
+	userSession.SetOneFactor(now, details, false)
+
+	assert.Equal(t, authentication.TwoFactor, userSession.AuthenticationLevel)
+	assert.False(t, userSession.FirstFactorReauthentication)
+
+	userSession.SetStaleFactors(true, true)
+
+	assert.Equal(t, authentication.NotAuthenticated, userSession.AuthenticationLevel)
+	assert.False(t, userSession.FirstFactorReauthentication)
+
+	userSession.SetOneFactor(now, details, false)
+
+	assert.Equal(t, authentication.OneFactor, userSession.AuthenticationLevel)
+}
+
+func TestUserSession_SetOneFactorShouldNotRestoreTwoFactorDifferentUser(t *testing.T) {
+	now := time.Unix(1000000, 0)
+
+	userSession := UserSession{}
+
+	userSession.SetOneFactor(now, &authentication.UserDetails{Username: "john"}, false)
+	userSession.SetTwoFactorTOTP(now)
+	userSession.SetStaleFactors(true, false)
+
+	userSession.SetOneFactor(now, &authentication.UserDetails{Username: "harry"}, false)
+
+	assert.Equal(t, authentication.OneFactor, userSession.AuthenticationLevel)
+	assert.Equal(t, "harry", userSession.Username)
+}
@@ -0,0 +1,422 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// NewBackupWriter creates a BackupWriter which writes the backup to w encrypted with a key derived from the
+// passphrase. The manifest is written immediately and must describe the rows which are written afterwards.
+func NewBackupWriter(w io.Writer, passphrase string, manifest BackupManifest) (writer *BackupWriter, err error) {
+	if passphrase == "" {
+		return nil, errors.New("the passphrase must not be empty")
+	}
+
+	header := backupHeader{
+		version:     BackupFormatVersion,
+		iterations:  backupArgon2Iterations,
+		memory:      backupArgon2Memory,
+		parallelism: backupArgon2Parallelism,
+		salt:        make([]byte, backupSaltLength),
+		noncePrefix: make([]byte, backupNoncePrefixLength),
+	}
+
+	if _, err = io.ReadFull(rand.Reader, header.salt); err != nil {
+		return nil, fmt.Errorf("error generating the salt: %w", err)
+	}
+
+	if _, err = io.ReadFull(rand.Reader, header.noncePrefix); err != nil {
+		return nil, fmt.Errorf("error generating the nonce prefix: %w", err)
+	}
+
+	raw := header.bytes()
+
+	if _, err = w.Write(raw); err != nil {
+		return nil, fmt.Errorf("error writing the backup header: %w", err)
+	}
+
+	chunks := &backupChunkWriter{w: w, header: header, aad: raw}
+
+	if chunks.aead, err = header.aead(passphrase); err != nil {
+		return nil, err
+	}
+
+	writer = &BackupWriter{
+		chunks: chunks,
+		counts: map[string]int64{},
+	}
+
+	writer.gzip = gzip.NewWriter(chunks)
+	writer.encoder = json.NewEncoder(writer.gzip)
+
+	if err = writer.encoder.Encode(manifest); err != nil {
+		return nil, fmt.Errorf("error writing the backup manifest: %w", err)
+	}
+
+	return writer, nil
+}
+
+// BackupWriter writes a backup which contains the rows of each table of the storage. The backup is compressed and
+// encrypted in chunks so it doesn't have to be held in memory.
+type BackupWriter struct {
+	chunks  *backupChunkWriter
+	gzip    *gzip.Writer
+	encoder *json.Encoder
+	counts  map[string]int64
+}
+
+// WriteRow writes a row of the table to the backup.
+func (w *BackupWriter) WriteRow(table string, row []any) (err error) {
+	if err = w.encoder.Encode(backupRecord{Table: table, Row: row}); err != nil {
+		return fmt.Errorf("error writing a row of table '%s' to the backup: %w", table, err)
+	}
+
+	w.counts[table]++
+
+	return nil
+}
+
+// Close writes the number of rows written for each table and the final chunk of the backup. It does not close the
+// underlying writer.
+func (w *BackupWriter) Close() (err error) {
+	if err = w.encoder.Encode(backupRecord{Counts: w.counts}); err != nil {
+		return fmt.Errorf("error writing the backup row counts: %w", err)
+	}
+
+	if err = w.gzip.Close(); err != nil {
+		return fmt.Errorf("error compressing the backup: %w", err)
+	}
+
+	return w.chunks.Close()
+}
+
+// OpenBackup reads the header and manifest of the backup from r decrypting it with a key derived from the
+// passphrase.
+func OpenBackup(r io.Reader, passphrase string) (reader *BackupReader, err error) {
+	br := bufio.NewReader(r)
+
+	var (
+		header backupHeader
+		raw    []byte
+	)
+
+	if header, raw, err = readBackupHeader(br); err != nil {
+		return nil, err
+	}
+
+	chunks := &backupChunkReader{r: br, header: header, aad: raw}
+
+	if chunks.aead, err = header.aead(passphrase); err != nil {
+		return nil, err
+	}
+
+	reader = &BackupReader{}
+
+	if reader.gzip, err = gzip.NewReader(chunks); err != nil {
+		if errors.Is(err, ErrBackupDecrypt) || errors.Is(err, ErrBackupTruncated) || errors.Is(err, ErrBackupMalformed) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("%w: %v", ErrBackupMalformed, err)
+	}
+
+	reader.decoder = json.NewDecoder(reader.gzip)
+	reader.decoder.UseNumber()
+
+	if err = reader.decoder.Decode(&reader.manifest); err != nil {
+		return nil, fmt.Errorf("error reading the backup manifest: %w", err)
+	}
+
+	return reader, nil
+}
+
+// BackupReader reads the rows of a backup written by a BackupWriter.
+type BackupReader struct {
+	gzip     *gzip.Reader
+	decoder  *json.Decoder
+	manifest BackupManifest
+	counts   map[string]int64
+	done     bool
+}
+
+// Manifest returns the manifest of the backup.
+func (r *BackupReader) Manifest() BackupManifest {
+	return r.manifest
+}
+
+// Next returns the table and the values of the next row of the backup. It returns io.EOF once every row has been
+// read and the number of rows read for each table matches the number of rows written.
+func (r *BackupReader) Next() (table string, row []any, err error) {
+	if r.done {
+		return "", nil, io.EOF
+	}
+
+	if r.counts == nil {
+		r.counts = map[string]int64{}
+	}
+
+	var record backupRecord
+
+	if err = r.decoder.Decode(&record); err != nil {
+		if errors.Is(err, io.EOF) {
+			return "", nil, fmt.Errorf("error reading the backup: %w", ErrBackupTruncated)
+		}
+
+		return "", nil, fmt.Errorf("error reading the backup: %w", err)
+	}
+
+	if record.Counts != nil {
+		r.done = true
+
+		for name, count := range record.Counts {
+			if r.counts[name] != count {
+				return "", nil, fmt.Errorf("error reading the backup: table '%s' has %d rows but the backup should contain %d rows", name, r.counts[name], count)
+			}
+		}
+
+		return "", nil, io.EOF
+	}
+
+	r.counts[record.Table]++
+
+	return record.Table, record.Row, nil
+}
+
+// BackupManifest describes the contents of a backup.
+type BackupManifest struct {
+	SchemaVersion int                   `json:"schema_version"`
+	CreatedAt     time.Time             `json:"created_at"`
+	Tables        []BackupManifestTable `json:"tables"`
+}
+
+// BackupManifestTable describes the columns of a table in a backup.
+type BackupManifestTable struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+}
+
+type backupRecord struct {
+	Table  string           `json:"table,omitempty"`
+	Row    []any            `json:"row,omitempty"`
+	Counts map[string]int64 `json:"counts,omitempty"`
+}
+
+type backupHeader struct {
+	version     uint8
+	iterations  uint32
+	memory      uint32
+	parallelism uint8
+	salt        []byte
+	noncePrefix []byte
+}
+
+func (h backupHeader) bytes() (raw []byte) {
+	raw = append([]byte{}, backupHeaderMagic...)
+	raw = append(raw, h.version)
+	raw = binary.BigEndian.AppendUint32(raw, h.iterations)
+	raw = binary.BigEndian.AppendUint32(raw, h.memory)
+	raw = append(raw, h.parallelism)
+	raw = append(raw, h.salt...)
+
+	return append(raw, h.noncePrefix...)
+}
+
+func (h backupHeader) aead(passphrase string) (aead cipher.AEAD, err error) {
+	key := argon2.IDKey([]byte(passphrase), h.salt, h.iterations, h.memory, h.parallelism, backupKeyLength)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// nonce returns the nonce of the chunk with the given index. The final chunk has a different nonce so a backup which
+// is truncated at a chunk boundary can be detected.
+func (h backupHeader) nonce(index uint32, final bool) (nonce []byte) {
+	nonce = append([]byte{}, h.noncePrefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, index)
+
+	if final {
+		return append(nonce, 1)
+	}
+
+	return append(nonce, 0)
+}
+
+func readBackupHeader(r io.Reader) (header backupHeader, raw []byte, err error) {
+	raw = make([]byte, len(backupHeaderMagic)+1+4+4+1+backupSaltLength+backupNoncePrefixLength)
+
+	if _, err = io.ReadFull(r, raw); err != nil {
+		return header, nil, fmt.Errorf("%w: error reading the header: %v", ErrBackupMalformed, err)
+	}
+
+	if !bytes.Equal(raw[:len(backupHeaderMagic)], backupHeaderMagic) {
+		return header, nil, ErrBackupMalformed
+	}
+
+	i := len(backupHeaderMagic)
+
+	header.version = raw[i]
+
+	if header.version != BackupFormatVersion {
+		return header, nil, fmt.Errorf("the backup format version %d is not supported", header.version)
+	}
+
+	header.iterations = binary.BigEndian.Uint32(raw[i+1:])
+	header.memory = binary.BigEndian.Uint32(raw[i+5:])
+	header.parallelism = raw[i+9]
+	header.salt = raw[i+10 : i+10+backupSaltLength]
+	header.noncePrefix = raw[i+10+backupSaltLength:]
+
+	// The key derivation parameters are bounded so a malformed backup can't exhaust the available memory.
+	switch {
+	case header.iterations == 0 || header.iterations > backupArgon2Iterations*16,
+		header.parallelism == 0,
+		header.memory < 8*uint32(header.parallelism) || header.memory > backupArgon2Memory*16:
+		return header, nil, fmt.Errorf("%w: the key derivation parameters are invalid", ErrBackupMalformed)
+	}
+
+	return header, raw, nil
+}
+
+// backupChunkWriter encrypts the data written to it in chunks of backupChunkSize which are each prefixed with the
+// length of the encrypted chunk.
+type backupChunkWriter struct {
+	w      io.Writer
+	header backupHeader
+	aead   cipher.AEAD
+	aad    []byte
+	buf    []byte
+	index  uint32
+}
+
+func (w *backupChunkWriter) Write(p []byte) (n int, err error) {
+	if w.buf == nil {
+		w.buf = make([]byte, 0, backupChunkSize)
+	}
+
+	for len(p) != 0 {
+		if len(w.buf) == backupChunkSize {
+			if err = w.flush(false); err != nil {
+				return n, err
+			}
+		}
+
+		i := copy(w.buf[len(w.buf):backupChunkSize], p)
+
+		w.buf = w.buf[:len(w.buf)+i]
+		p = p[i:]
+		n += i
+	}
+
+	return n, nil
+}
+
+func (w *backupChunkWriter) Close() (err error) {
+	return w.flush(true)
+}
+
+func (w *backupChunkWriter) flush(final bool) (err error) {
+	sealed := w.aead.Seal(nil, w.header.nonce(w.index, final), w.buf, w.aad)
+
+	if err = binary.Write(w.w, binary.BigEndian, uint32(len(sealed))); err != nil {
+		return fmt.Errorf("error writing the backup: %w", err)
+	}
+
+	if _, err = w.w.Write(sealed); err != nil {
+		return fmt.Errorf("error writing the backup: %w", err)
+	}
+
+	w.index++
+	w.buf = w.buf[:0]
+
+	return nil
+}
+
+// backupChunkReader decrypts the chunks written by a backupChunkWriter.
+type backupChunkReader struct {
+	r      io.Reader
+	header backupHeader
+	aead   cipher.AEAD
+	aad    []byte
+	buf    []byte
+	index  uint32
+	final  bool
+}
+
+func (r *backupChunkReader) Read(p []byte) (n int, err error) {
+	for len(r.buf) == 0 {
+		if r.final {
+			return 0, io.EOF
+		}
+
+		if err = r.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n = copy(p, r.buf)
+	r.buf = r.buf[n:]
+
+	return n, nil
+}
+
+func (r *backupChunkReader) next() (err error) {
+	var length uint32
+
+	if err = binary.Read(r.r, binary.BigEndian, &length); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return ErrBackupTruncated
+		}
+
+		return err
+	}
+
+	if length > backupChunkSize+uint32(r.aead.Overhead()) {
+		return ErrBackupMalformed
+	}
+
+	sealed := make([]byte, length)
+
+	if _, err = io.ReadFull(r.r, sealed); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return ErrBackupTruncated
+		}
+
+		return err
+	}
+
+	if r.buf, err = r.aead.Open(nil, r.header.nonce(r.index, false), sealed, r.aad); err == nil {
+		r.index++
+
+		return nil
+	}
+
+	if r.buf, err = r.aead.Open(nil, r.header.nonce(r.index, true), sealed, r.aad); err != nil {
+		return ErrBackupDecrypt
+	}
+
+	r.final = true
+
+	var extra [1]byte
+
+	if n, _ := r.r.Read(extra[:]); n != 0 {
+		return fmt.Errorf("%w: the backup contains data after the final chunk", ErrBackupMalformed)
+	}
+
+	return nil
+}
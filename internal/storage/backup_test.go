@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupWriterReader(t *testing.T) {
+	manifest := BackupManifest{
+		SchemaVersion: 11,
+		CreatedAt:     time.Unix(1700000000, 0).UTC(),
+		Tables:        []BackupManifestTable{{Name: "example", Columns: []string{"id", "value"}}},
+	}
+
+	// The random values can't be compressed which ensures the backup contains more than one chunk.
+	values := make([]string, 100)
+
+	for i := range values {
+		value := make([]byte, 2048)
+
+		_, err := rand.Read(value)
+		require.NoError(t, err)
+
+		values[i] = base64.StdEncoding.EncodeToString(value)
+	}
+
+	buf := &bytes.Buffer{}
+
+	writer, err := NewBackupWriter(buf, "passphrase", manifest)
+	require.NoError(t, err)
+
+	for i, value := range values {
+		require.NoError(t, writer.WriteRow("example", []any{i + 1, value}))
+	}
+
+	require.NoError(t, writer.Close())
+
+	assert.Greater(t, buf.Len(), backupChunkSize)
+
+	data := buf.Bytes()
+
+	reader, err := OpenBackup(bytes.NewReader(data), "passphrase")
+	require.NoError(t, err)
+
+	assert.Equal(t, manifest, reader.Manifest())
+
+	for _, value := range values {
+		table, row, err := reader.Next()
+		require.NoError(t, err)
+
+		assert.Equal(t, "example", table)
+		assert.Equal(t, value, row[1])
+	}
+
+	_, _, err = reader.Next()
+	assert.ErrorIs(t, err, io.EOF)
+
+	_, err = OpenBackup(bytes.NewReader(data), "incorrect")
+	assert.ErrorIs(t, err, ErrBackupDecrypt)
+
+	_, err = OpenBackup(bytes.NewReader([]byte("not a backup at all, not even close")), "passphrase")
+	assert.ErrorIs(t, err, ErrBackupMalformed)
+
+	// The backup is truncated at the end of the first chunk which is only detected as the final chunk is missing.
+	boundary := len(backupHeaderMagic) + 10 + backupSaltLength + backupNoncePrefixLength + 4 + backupChunkSize + 16
+
+	reader, err = OpenBackup(bytes.NewReader(data[:boundary]), "passphrase")
+	require.NoError(t, err)
+
+	for err == nil {
+		_, _, err = reader.Next()
+	}
+
+	assert.ErrorIs(t, err, ErrBackupTruncated)
+
+	_, err = NewBackupWriter(buf, "", manifest)
+	assert.EqualError(t, err, "the passphrase must not be empty")
+}
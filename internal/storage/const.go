@@ -2,14 +2,18 @@ package storage
 
 import (
 	"regexp"
+	"time"
 )
 
 const (
 	tableAuthenticationLogs   = "authentication_logs"
 	tableDuoDevices           = "duo_devices"
 	tableIdentityVerification = "identity_verification"
+	tableNotificationOutbox   = "notification_outbox"
+	tablePasswordHistory      = "password_history"
 	tableTOTPConfigurations   = "totp_configurations"
 	tableUserOpaqueIdentifier = "user_opaque_identifier"
+	tableUserPasswordChanges  = "user_password_changes"
 	tableUserPreferences      = "user_preferences"
 	tableWebauthnDevices      = "webauthn_devices"
 
@@ -122,6 +126,55 @@ var (
 	reMigration = regexp.MustCompile(`^V(\d{4})\.([^.]+)\.(all|sqlite|postgres|mysql)\.(up|down)\.sql$`)
 )
 
+const (
+	encryptionKeyIDLength  = 8
+	encryptionKeyIDContext = "authelia storage encryption key id"
+	encryptionHeaderLength = 4 + encryptionKeyIDLength
+
+	encryptionDataKeyLength        = 32
+	encryptionWrappedKeyLengthSize = 2
+	encryptionVaultTransitIDPrefix = "vault_transit"
+
+	// encryptionKeyProviderTimeout is the maximum duration of a call to the key provider which is shared by several
+	// callers, as it's not bound to the context of any of them.
+	encryptionKeyProviderTimeout = time.Second * 30
+)
+
+const (
+	// BackupFormatVersion is the version of the backup format written by NewBackupWriter.
+	BackupFormatVersion = 1
+
+	backupSaltLength        = 16
+	backupNoncePrefixLength = 7
+	backupChunkSize         = 64 * 1024
+	backupKeyLength         = 32
+
+	backupArgon2Iterations  = 3
+	backupArgon2Memory      = 64 * 1024
+	backupArgon2Parallelism = 4
+)
+
+const (
+	headerContentType    = "Content-Type"
+	vaultHeaderToken     = "X-Vault-Token"
+	vaultHeaderNamespace = "X-Vault-Namespace"
+
+	contentTypeApplicationJSON = "application/json"
+)
+
+var (
+	// encryptionHeaderMagic is the prefix of the header of encrypted values which is followed by the key id.
+	encryptionHeaderMagic = []byte("AEK1")
+
+	// encryptionEnvelopeHeaderMagic is the prefix of the header of values encrypted with a data key from a key provider
+	// which is followed by the key id, the length of the wrapped data key, and the wrapped data key.
+	encryptionEnvelopeHeaderMagic = []byte("AEE1")
+
+	// backupHeaderMagic is the prefix of the header of a backup which is followed by the format version, the key
+	// derivation parameters, the salt, and the nonce prefix.
+	backupHeaderMagic = []byte("AUTHELIA-BACKUP")
+)
+
 const (
 	na      = "N/A"
 	invalid = "invalid"
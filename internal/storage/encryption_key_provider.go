@@ -0,0 +1,284 @@
+package storage
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+// EncryptionKeyProvider is implemented by the providers of the keys which wrap the data keys used to encrypt values
+// in the database. The keys themselves never leave the provider.
+type EncryptionKeyProvider interface {
+	// ID returns the identifier of the key which wraps new data keys.
+	ID() (id EncryptionKeyID)
+
+	// IDs returns the identifiers of every key which can unwrap data keys with the key which wraps new data keys first.
+	IDs() (ids []EncryptionKeyID)
+
+	// GenerateDataKey returns a new data key and the data key wrapped by the provider.
+	GenerateDataKey(ctx context.Context) (key, wrapped []byte, err error)
+
+	// DecryptDataKey returns the data key which was wrapped by the provider.
+	DecryptDataKey(ctx context.Context, wrapped []byte) (key []byte, err error)
+}
+
+// NewEncryptionKeyProvider returns the EncryptionKeyProvider for the configuration or nil if none is configured.
+func NewEncryptionKeyProvider(config *schema.StorageEncryptionKeyProviderConfiguration, caCertPool *x509.CertPool) (provider EncryptionKeyProvider, err error) {
+	switch {
+	case config.VaultTransit != nil:
+		return NewVaultTransitKeyProvider(config.VaultTransit, caCertPool), nil
+	case config.File != nil:
+		return NewFileKeyProvider(config.File)
+	default:
+		return nil, nil
+	}
+}
+
+func newEncryptionKeyring(config *schema.StorageConfiguration, caCertPool *x509.CertPool) (keys *EncryptionKeyring, err error) {
+	keys = NewEncryptionKeyring(config.EncryptionKey, config.Encryption.DecryptionKeys...)
+
+	var provider EncryptionKeyProvider
+
+	if provider, err = NewEncryptionKeyProvider(&config.Encryption.KeyProvider, caCertPool); err != nil || provider == nil {
+		return keys, err
+	}
+
+	return keys.WithKeyProvider(provider, config.Encryption.KeyProvider.DataKeyCache), nil
+}
+
+func newEncryptionEnvelope(provider EncryptionKeyProvider, config schema.StorageEncryptionDataKeyCacheConfiguration) *encryptionEnvelope {
+	return &encryptionEnvelope{
+		provider: provider,
+		lifetime: config.Lifetime,
+		size:     config.Size,
+		cache:    map[string]*encryptionDataKey{},
+	}
+}
+
+// encryptionEnvelope encrypts each value with a data key generated by the EncryptionKeyProvider and stores the data
+// key wrapped by the provider alongside the value. Data keys are reused for new values and cached after they're
+// unwrapped for the configured lifetime so the provider is not used for every value. The lock is never held while the
+// provider is used, instead concurrent requests for the same data key share a single call to the provider which is
+// detached from the context of each request so one request being canceled doesn't fail the others.
+type encryptionEnvelope struct {
+	provider EncryptionKeyProvider
+	lifetime time.Duration
+	size     int
+
+	generating singleflight.Group
+	unwrapping singleflight.Group
+
+	mu      sync.Mutex
+	current *encryptionDataKey
+	cache   map[string]*encryptionDataKey
+}
+
+type encryptionDataKey struct {
+	key     [encryptionDataKeyLength]byte
+	wrapped []byte
+	expires time.Time
+}
+
+func (e *encryptionEnvelope) header() (header []byte) {
+	id := e.provider.ID()
+
+	header = make([]byte, 0, encryptionHeaderLength)
+
+	header = append(header, encryptionEnvelopeHeaderMagic...)
+
+	return append(header, id[:]...)
+}
+
+func (e *encryptionEnvelope) encrypt(ctx context.Context, clearText []byte) (cipherText []byte, err error) {
+	var key *encryptionDataKey
+
+	if key, err = e.dataKey(ctx); err != nil {
+		return nil, err
+	}
+
+	if cipherText, err = utils.Encrypt(clearText, &key.key); err != nil {
+		return nil, err
+	}
+
+	header := e.header()
+
+	value := make([]byte, 0, len(header)+encryptionWrappedKeyLengthSize+len(key.wrapped)+len(cipherText))
+
+	value = append(value, header...)
+	value = binary.BigEndian.AppendUint16(value, uint16(len(key.wrapped)))
+	value = append(value, key.wrapped...)
+
+	return append(value, cipherText...), nil
+}
+
+// decrypt the value after the header.
+func (e *encryptionEnvelope) decrypt(ctx context.Context, value []byte) (clearText []byte, err error) {
+	if len(value) < encryptionWrappedKeyLengthSize {
+		return nil, ErrEncryptionEnvelopeMalformed
+	}
+
+	n := int(binary.BigEndian.Uint16(value)) + encryptionWrappedKeyLengthSize
+
+	if n == encryptionWrappedKeyLengthSize || len(value) < n {
+		return nil, ErrEncryptionEnvelopeMalformed
+	}
+
+	var key *encryptionDataKey
+
+	if key, err = e.unwrap(ctx, value[encryptionWrappedKeyLengthSize:n]); err != nil {
+		return nil, err
+	}
+
+	return utils.Decrypt(value[n:], &key.key)
+}
+
+// dataKey returns the data key used to encrypt new values generating a new one when it has expired.
+func (e *encryptionEnvelope) dataKey(ctx context.Context) (key *encryptionDataKey, err error) {
+	e.mu.Lock()
+
+	if key = e.current; key != nil && time.Now().Before(key.expires) {
+		e.mu.Unlock()
+
+		return key, nil
+	}
+
+	e.mu.Unlock()
+
+	return e.do(ctx, &e.generating, "", e.generate)
+}
+
+func (e *encryptionEnvelope) generate(ctx context.Context) (key *encryptionDataKey, err error) {
+	var raw, wrapped []byte
+
+	if raw, wrapped, err = e.provider.GenerateDataKey(ctx); err != nil {
+		return nil, fmt.Errorf("error generating data key: %w", err)
+	}
+
+	now := time.Now()
+
+	if key, err = newEncryptionDataKey(raw, wrapped, now.Add(e.lifetime)); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+
+	defer e.mu.Unlock()
+
+	e.current = key
+
+	e.store(key, now)
+
+	return key, nil
+}
+
+// unwrap returns the data key for a wrapped data key from the cache or the provider.
+func (e *encryptionEnvelope) unwrap(ctx context.Context, wrapped []byte) (key *encryptionDataKey, err error) {
+	e.mu.Lock()
+
+	if key = e.cache[string(wrapped)]; key != nil && time.Now().Before(key.expires) {
+		e.mu.Unlock()
+
+		return key, nil
+	}
+
+	e.mu.Unlock()
+
+	return e.do(ctx, &e.unwrapping, string(wrapped), func(ctx context.Context) (*encryptionDataKey, error) {
+		return e.decryptDataKey(ctx, wrapped)
+	})
+}
+
+// do calls fn once for all concurrent callers with the same key. The fn is called with a context which is limited by
+// the encryptionKeyProviderTimeout instead of the context of the caller which started the call, and each caller only
+// waits for the result until its own context is done.
+func (e *encryptionEnvelope) do(ctx context.Context, group *singleflight.Group, key string, fn func(ctx context.Context) (*encryptionDataKey, error)) (*encryptionDataKey, error) {
+	results := group.DoChan(key, func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), encryptionKeyProviderTimeout)
+
+		defer cancel()
+
+		return fn(ctx)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-results:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		return result.Val.(*encryptionDataKey), nil
+	}
+}
+
+func (e *encryptionEnvelope) decryptDataKey(ctx context.Context, wrapped []byte) (key *encryptionDataKey, err error) {
+	var raw []byte
+
+	if raw, err = e.provider.DecryptDataKey(ctx, wrapped); err != nil {
+		return nil, fmt.Errorf("error decrypting data key: %w", err)
+	}
+
+	now := time.Now()
+
+	if key, err = newEncryptionDataKey(raw, wrapped, now.Add(e.lifetime)); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+
+	defer e.mu.Unlock()
+
+	e.store(key, now)
+
+	return key, nil
+}
+
+func (e *encryptionEnvelope) store(key *encryptionDataKey, now time.Time) {
+	if len(e.cache) >= e.size {
+		for wrapped, cached := range e.cache {
+			if !now.Before(cached.expires) {
+				delete(e.cache, wrapped)
+			}
+		}
+	}
+
+	for wrapped := range e.cache {
+		if len(e.cache) < e.size {
+			break
+		}
+
+		delete(e.cache, wrapped)
+	}
+
+	if e.size > 0 {
+		e.cache[string(key.wrapped)] = key
+	}
+}
+
+func newEncryptionDataKey(raw, wrapped []byte, expires time.Time) (key *encryptionDataKey, err error) {
+	if len(raw) != encryptionDataKeyLength {
+		return nil, fmt.Errorf("error using data key: the data key must be %d bytes but it's %d bytes", encryptionDataKeyLength, len(raw))
+	}
+
+	if len(wrapped) == 0 || len(wrapped) > 0xFFFF {
+		return nil, fmt.Errorf("error using data key: the wrapped data key must be between 1 and %d bytes but it's %d bytes", 0xFFFF, len(wrapped))
+	}
+
+	key = &encryptionDataKey{
+		wrapped: wrapped,
+		expires: expires,
+	}
+
+	copy(key.key[:], raw)
+
+	return key, nil
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/random"
+)
+
+// NewFileKeyProvider creates a FileKeyProvider from the keys in the file at the configured path. Each non-empty line
+// which isn't a comment is a key where the first key wraps new data keys and every key unwraps data keys.
+func NewFileKeyProvider(config *schema.StorageEncryptionFileKeyProviderConfiguration) (provider *FileKeyProvider, err error) {
+	var data []byte
+
+	if data, err = os.ReadFile(config.Path); err != nil {
+		return nil, fmt.Errorf("error reading the key provider file: %w", err)
+	}
+
+	var secrets []string
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if len(line) < 20 {
+			return nil, fmt.Errorf("error reading the key provider file: the key on line %d must be 20 characters or longer", i+1)
+		}
+
+		secrets = append(secrets, line)
+	}
+
+	if len(secrets) == 0 {
+		return nil, fmt.Errorf("error reading the key provider file: the file at path '%s' doesn't contain any keys", config.Path)
+	}
+
+	return &FileKeyProvider{
+		keys:   NewEncryptionKeyring(secrets[0], secrets[1:]...),
+		random: &random.Cryptographical{},
+	}, nil
+}
+
+// FileKeyProvider is an EncryptionKeyProvider which wraps data keys with keys stored in a local file.
+type FileKeyProvider struct {
+	keys   *EncryptionKeyring
+	random random.Provider
+}
+
+// ID returns the identifier of the first key in the file.
+func (p *FileKeyProvider) ID() (id EncryptionKeyID) {
+	return p.keys.Current().ID
+}
+
+// IDs returns the identifiers of every key in the file.
+func (p *FileKeyProvider) IDs() (ids []EncryptionKeyID) {
+	return p.keys.IDs()
+}
+
+// GenerateDataKey returns a new random data key and the data key encrypted with the first key in the file.
+func (p *FileKeyProvider) GenerateDataKey(ctx context.Context) (key, wrapped []byte, err error) {
+	if key, err = p.random.BytesCustomErr(encryptionDataKeyLength, nil); err != nil {
+		return nil, nil, err
+	}
+
+	if wrapped, err = p.keys.Encrypt(ctx, key); err != nil {
+		return nil, nil, err
+	}
+
+	return key, wrapped, nil
+}
+
+// DecryptDataKey returns the data key decrypted with the key in the file which encrypted it.
+func (p *FileKeyProvider) DecryptDataKey(ctx context.Context, wrapped []byte) (key []byte, err error) {
+	key, _, _, err = p.keys.Decrypt(ctx, wrapped)
+
+	return key, err
+}
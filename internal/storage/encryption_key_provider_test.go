@@ -0,0 +1,476 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/model"
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+// newVaultTransitTestServer returns a stub of the HashiCorp Vault Transit endpoints used by the
+// VaultTransitKeyProvider which wraps data keys with a fixed key.
+func newVaultTransitTestServer(t *testing.T) (server *httptest.Server, generated, decrypted *int32) {
+	key := sha256.Sum256([]byte("vault transit test key"))
+
+	generated, decrypted = new(int32), new(int32)
+
+	write := func(rw http.ResponseWriter, status int, body any) {
+		rw.Header().Set(headerContentType, contentTypeApplicationJSON)
+		rw.WriteHeader(status)
+
+		_ = json.NewEncoder(rw).Encode(body)
+	}
+
+	server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get(vaultHeaderToken) != "hvs.test" || req.Header.Get(vaultHeaderNamespace) != "authelia" {
+			write(rw, http.StatusForbidden, map[string]any{"errors": []string{"permission denied"}})
+
+			return
+		}
+
+		var body vaultTransitRequest
+
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+
+		switch req.URL.Path {
+		case "/v1/transit/datakey/plaintext/authelia":
+			atomic.AddInt32(generated, 1)
+
+			assert.Equal(t, 256, body.Bits)
+
+			plaintext := []byte(fmt.Sprintf("%032d", atomic.LoadInt32(generated)))
+
+			ciphertext, err := utils.Encrypt(plaintext, &key)
+			require.NoError(t, err)
+
+			write(rw, http.StatusOK, map[string]any{"data": map[string]any{
+				"plaintext":  base64.StdEncoding.EncodeToString(plaintext),
+				"ciphertext": "vault:v1:" + base64.StdEncoding.EncodeToString(ciphertext),
+			}})
+		case "/v1/transit/decrypt/authelia":
+			atomic.AddInt32(decrypted, 1)
+
+			ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(body.Ciphertext, "vault:v1:"))
+			if err != nil {
+				write(rw, http.StatusBadRequest, map[string]any{"errors": []string{"invalid ciphertext"}})
+
+				return
+			}
+
+			plaintext, err := utils.Decrypt(ciphertext, &key)
+			if err != nil {
+				write(rw, http.StatusBadRequest, map[string]any{"errors": []string{"cipher: message authentication failed"}})
+
+				return
+			}
+
+			write(rw, http.StatusOK, map[string]any{"data": map[string]any{
+				"plaintext": base64.StdEncoding.EncodeToString(plaintext),
+			}})
+		default:
+			write(rw, http.StatusNotFound, map[string]any{"errors": []string{}})
+		}
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, generated, decrypted
+}
+
+func newVaultTransitTestConfig(t *testing.T, server *httptest.Server) *schema.StorageEncryptionVaultTransitConfiguration {
+	address, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	return &schema.StorageEncryptionVaultTransitConfiguration{
+		Address:   address,
+		Token:     "hvs.test",
+		Namespace: "authelia",
+		Mount:     "transit",
+		KeyName:   "authelia",
+		Timeout:   time.Second * 5,
+	}
+}
+
+func TestVaultTransitKeyProvider(t *testing.T) {
+	server, generated, decrypted := newVaultTransitTestServer(t)
+
+	config := newVaultTransitTestConfig(t, server)
+
+	provider := NewVaultTransitKeyProvider(config, nil)
+
+	key, wrapped, err := provider.GenerateDataKey(context.Background())
+	require.NoError(t, err)
+
+	assert.Len(t, key, encryptionDataKeyLength)
+	assert.True(t, strings.HasPrefix(string(wrapped), "vault:v1:"))
+
+	unwrapped, err := provider.DecryptDataKey(context.Background(), wrapped)
+	require.NoError(t, err)
+
+	assert.Equal(t, key, unwrapped)
+	assert.Equal(t, int32(1), atomic.LoadInt32(generated))
+	assert.Equal(t, int32(1), atomic.LoadInt32(decrypted))
+
+	_, err = provider.DecryptDataKey(context.Background(), []byte("vault:v1:invalid"))
+	assert.EqualError(t, err, "error performing vault transit decrypt request: status code 400: invalid ciphertext")
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cancel()
+
+	_, err = provider.DecryptDataKey(ctx, wrapped)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(2), atomic.LoadInt32(decrypted))
+
+	assert.Equal(t, []EncryptionKeyID{provider.ID()}, provider.IDs())
+
+	config.Mount = "other"
+
+	assert.NotEqual(t, provider.ID(), NewVaultTransitKeyProvider(config, nil).ID())
+
+	config.Mount = "transit"
+	config.Token = "hvs.invalid"
+
+	_, _, err = NewVaultTransitKeyProvider(config, nil).GenerateDataKey(context.Background())
+	assert.EqualError(t, err, "error performing vault transit datakey/plaintext request: status code 403: permission denied")
+}
+
+func TestFileKeyProvider(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "keyring")
+
+	require.NoError(t, os.WriteFile(path, []byte("# The first key wraps new data keys.\na_new_file_key_which_is_very_long\n\nan_old_file_key_which_is_very_long\n"), 0600))
+
+	provider, err := NewFileKeyProvider(&schema.StorageEncryptionFileKeyProviderConfiguration{Path: path})
+	require.NoError(t, err)
+
+	current, old := NewEncryptionKey("a_new_file_key_which_is_very_long"), NewEncryptionKey("an_old_file_key_which_is_very_long")
+
+	assert.Equal(t, current.ID, provider.ID())
+	assert.Equal(t, []EncryptionKeyID{current.ID, old.ID}, provider.IDs())
+
+	key, wrapped, err := provider.GenerateDataKey(context.Background())
+	require.NoError(t, err)
+
+	assert.Len(t, key, encryptionDataKeyLength)
+
+	unwrapped, err := provider.DecryptDataKey(context.Background(), wrapped)
+	require.NoError(t, err)
+
+	assert.Equal(t, key, unwrapped)
+
+	wrapped, err = old.Encrypt(key)
+	require.NoError(t, err)
+
+	unwrapped, err = provider.DecryptDataKey(context.Background(), wrapped)
+	require.NoError(t, err)
+
+	assert.Equal(t, key, unwrapped)
+
+	testCases := []struct {
+		name     string
+		have     string
+		expected string
+	}{
+		{"ShouldErrorOnShortKey", "a_new_file_key_which_is_very_long\nshort\n", "error reading the key provider file: the key on line 2 must be 20 characters or longer"},
+		{"ShouldErrorOnNoKeys", "# No keys.\n\n", fmt.Sprintf("error reading the key provider file: the file at path '%s' doesn't contain any keys", filepath.Join(dir, "ShouldErrorOnNoKeys"))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, tc.name)
+
+			require.NoError(t, os.WriteFile(path, []byte(tc.have), 0600))
+
+			_, err := NewFileKeyProvider(&schema.StorageEncryptionFileKeyProviderConfiguration{Path: path})
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+
+	_, err = NewFileKeyProvider(&schema.StorageEncryptionFileKeyProviderConfiguration{Path: filepath.Join(dir, "missing")})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestEncryptionKeyringWithKeyProvider(t *testing.T) {
+	server, generated, decrypted := newVaultTransitTestServer(t)
+
+	provider := NewVaultTransitKeyProvider(newVaultTransitTestConfig(t, server), nil)
+
+	previous := NewEncryptionKeyring("an_old_encryption_key_which_is_long")
+	keyring := previous.WithKeyProvider(provider, schema.DefaultStorageEncryptionDataKeyCacheConfiguration)
+
+	assert.Equal(t, provider, keyring.KeyProvider())
+	assert.Nil(t, previous.KeyProvider())
+	assert.Equal(t, provider.ID(), keyring.CurrentID())
+	assert.Equal(t, []EncryptionKeyID{provider.ID(), previous.Current().ID}, keyring.IDs())
+
+	cipherText, err := keyring.Encrypt(context.Background(), []byte("example"))
+	require.NoError(t, err)
+
+	assert.True(t, keyring.IsCurrent(cipherText))
+	assert.False(t, previous.IsCurrent(cipherText))
+
+	other, err := keyring.Encrypt(context.Background(), []byte("other"))
+	require.NoError(t, err)
+
+	clearText, id, legacy, err := keyring.Decrypt(context.Background(), cipherText)
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("example"), clearText)
+	assert.Equal(t, provider.ID(), id)
+	assert.False(t, legacy)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(generated))
+	assert.Equal(t, int32(0), atomic.LoadInt32(decrypted))
+
+	restarted := previous.WithKeyProvider(provider, schema.DefaultStorageEncryptionDataKeyCacheConfiguration)
+
+	for _, value := range [][]byte{cipherText, other, cipherText} {
+		_, _, _, err = restarted.Decrypt(context.Background(), value)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(decrypted))
+
+	_, _, _, err = previous.Decrypt(context.Background(), cipherText)
+	assert.ErrorIs(t, err, ErrEncryptionNoKey)
+
+	legacyCipherText, err := previous.Encrypt(context.Background(), []byte("example"))
+	require.NoError(t, err)
+
+	assert.False(t, keyring.IsCurrent(legacyCipherText))
+
+	clearText, id, _, err = keyring.Decrypt(context.Background(), legacyCipherText)
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("example"), clearText)
+	assert.Equal(t, previous.Current().ID, id)
+
+	malformed := append(keyring.header(), 0xFF, 0xFF, 0x00)
+
+	_, _, _, err = keyring.Decrypt(context.Background(), malformed)
+	assert.ErrorIs(t, err, ErrEncryptionEnvelopeMalformed)
+
+	expiring := previous.WithKeyProvider(provider, schema.StorageEncryptionDataKeyCacheConfiguration{Lifetime: time.Millisecond, Size: 1})
+
+	_, err = expiring.Encrypt(context.Background(), []byte("example"))
+	require.NoError(t, err)
+
+	time.Sleep(time.Millisecond * 5)
+
+	_, err = expiring.Encrypt(context.Background(), []byte("example"))
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(generated))
+	assert.Len(t, expiring.envelope.cache, 1)
+}
+
+type blockingKeyProvider struct {
+	EncryptionKeyProvider
+
+	release chan struct{}
+	calls   int32
+}
+
+func (p *blockingKeyProvider) DecryptDataKey(ctx context.Context, wrapped []byte) (key []byte, err error) {
+	atomic.AddInt32(&p.calls, 1)
+
+	<-p.release
+
+	return p.EncryptionKeyProvider.DecryptDataKey(ctx, wrapped)
+}
+
+func TestEncryptionKeyringWithKeyProviderShouldShareUnwrapping(t *testing.T) {
+	ctx := context.Background()
+
+	server, _, decrypted := newVaultTransitTestServer(t)
+
+	vault := NewVaultTransitKeyProvider(newVaultTransitTestConfig(t, server), nil)
+
+	previous := NewEncryptionKeyring("an_old_encryption_key_which_is_long")
+
+	cipherText, err := previous.WithKeyProvider(vault, schema.DefaultStorageEncryptionDataKeyCacheConfiguration).Encrypt(ctx, []byte("example"))
+	require.NoError(t, err)
+
+	provider := &blockingKeyProvider{EncryptionKeyProvider: vault, release: make(chan struct{})}
+
+	keyring := previous.WithKeyProvider(provider, schema.DefaultStorageEncryptionDataKeyCacheConfiguration)
+
+	_, err = keyring.Encrypt(ctx, []byte("other"))
+	require.NoError(t, err)
+
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			clearText, _, _, err := keyring.Decrypt(ctx, cipherText)
+
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("example"), clearText)
+		}()
+	}
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&provider.calls) == 1
+	}, time.Second, time.Millisecond)
+
+	// The envelope must not be locked while the provider is unwrapping a data key.
+	_, err = keyring.Encrypt(ctx, []byte("another"))
+	require.NoError(t, err)
+
+	close(provider.release)
+
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&provider.calls))
+	assert.Equal(t, int32(1), atomic.LoadInt32(decrypted))
+}
+
+func TestEncryptionKeyringWithKeyProviderShouldNotCancelSharedUnwrapping(t *testing.T) {
+	server, _, decrypted := newVaultTransitTestServer(t)
+
+	vault := NewVaultTransitKeyProvider(newVaultTransitTestConfig(t, server), nil)
+
+	previous := NewEncryptionKeyring("an_old_encryption_key_which_is_long")
+
+	cipherText, err := previous.WithKeyProvider(vault, schema.DefaultStorageEncryptionDataKeyCacheConfiguration).Encrypt(context.Background(), []byte("example"))
+	require.NoError(t, err)
+
+	provider := &blockingKeyProvider{EncryptionKeyProvider: vault, release: make(chan struct{})}
+
+	keyring := previous.WithKeyProvider(provider, schema.DefaultStorageEncryptionDataKeyCacheConfiguration)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	canceled := make(chan error)
+
+	go func() {
+		_, _, _, err := keyring.Decrypt(ctx, cipherText)
+
+		canceled <- err
+	}()
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&provider.calls) == 1
+	}, time.Second, time.Millisecond)
+
+	done := make(chan error)
+
+	go func() {
+		clearText, _, _, err := keyring.Decrypt(context.Background(), cipherText)
+
+		assert.Equal(t, []byte("example"), clearText)
+
+		done <- err
+	}()
+
+	cancel()
+
+	// The caller which started the unwrapping must stop waiting once its context is canceled.
+	assert.ErrorIs(t, <-canceled, context.Canceled)
+
+	close(provider.release)
+
+	assert.NoError(t, <-done)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&provider.calls))
+	assert.Equal(t, int32(1), atomic.LoadInt32(decrypted))
+}
+
+func TestSQLProviderSchemaEncryptionKeyProvider(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	path, keyring := filepath.Join(dir, "db.sqlite3"), filepath.Join(dir, "keyring")
+
+	provider := newTestSQLiteProvider(t, path, testEncryptionKeyOld)
+	require.NoError(t, provider.StartupCheck())
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, provider.SaveTOTPConfiguration(ctx, model.TOTPConfiguration{
+			CreatedAt: time.Now(),
+			Username:  fmt.Sprintf("user%d", i),
+			Issuer:    "Authelia",
+			Algorithm: "SHA1",
+			Digits:    6,
+			Period:    30,
+			Secret:    []byte(fmt.Sprintf("secret%d", i)),
+		}))
+	}
+
+	require.NoError(t, provider.Close())
+
+	config := &schema.Configuration{
+		Storage: schema.StorageConfiguration{
+			Local:         &schema.LocalStorageConfiguration{Path: path},
+			EncryptionKey: testEncryptionKeyOld,
+			Encryption: schema.StorageEncryptionConfiguration{
+				KeyProvider: schema.StorageEncryptionKeyProviderConfiguration{
+					File:         &schema.StorageEncryptionFileKeyProviderConfiguration{Path: keyring},
+					DataKeyCache: schema.DefaultStorageEncryptionDataKeyCacheConfiguration,
+				},
+			},
+		},
+	}
+
+	provider = NewSQLiteProvider(config, nil)
+	assert.ErrorIs(t, provider.StartupCheck(), os.ErrNotExist)
+	require.NoError(t, provider.Close())
+
+	require.NoError(t, os.WriteFile(keyring, []byte("a_file_key_which_is_very_long\n"), 0600))
+
+	provider = NewSQLiteProvider(config, nil)
+
+	t.Cleanup(func() {
+		_ = provider.Close()
+	})
+
+	require.NoError(t, provider.StartupCheck())
+
+	current := NewEncryptionKey("a_file_key_which_is_very_long").ID
+
+	state := &EncryptionRekeyState{}
+
+	for i := 0; i < 10 && !state.Complete(); i++ {
+		require.NoError(t, provider.SchemaEncryptionRekey(ctx, state, 100))
+	}
+
+	assert.Equal(t, 3, state.Rekeyed)
+
+	result, err := provider.SchemaEncryptionCheckKey(ctx, true)
+	require.NoError(t, err)
+
+	assert.True(t, result.Success())
+	assert.Equal(t, current, result.Current)
+	assert.Equal(t, map[EncryptionKeyID]int{current: 3}, result.Tables[tableTOTPConfigurations].Keys)
+
+	c, err := provider.LoadTOTPConfiguration(ctx, "user1")
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("secret1"), c.Secret)
+
+	err = provider.SchemaEncryptionChangeKey(ctx, testEncryptionKeyNew)
+	assert.ErrorIs(t, err, ErrEncryptionKeyProviderChangeKey)
+}
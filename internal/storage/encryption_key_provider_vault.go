@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+// NewVaultTransitKeyProvider creates a VaultTransitKeyProvider using the HashiCorp Vault Transit configuration.
+func NewVaultTransitKeyProvider(config *schema.StorageEncryptionVaultTransitConfiguration, caCertPool *x509.CertPool) *VaultTransitKeyProvider {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+	}
+
+	if config.TLS != nil {
+		transport.TLSClientConfig = utils.NewTLSConfig(config.TLS, caCertPool)
+	}
+
+	// The identifier only depends on the mount and key name so the address of Vault can be changed without the values
+	// being re-encrypted. Vault tracks the version of the key which wrapped each data key itself.
+	sum := sha256.Sum256([]byte(strings.Join([]string{encryptionVaultTransitIDPrefix, config.Mount, config.KeyName}, "\x00")))
+
+	provider := &VaultTransitKeyProvider{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout, Transport: transport},
+	}
+
+	copy(provider.id[:], sum[:])
+
+	return provider
+}
+
+// VaultTransitKeyProvider is an EncryptionKeyProvider which generates and unwraps data keys using the HashiCorp Vault
+// Transit secrets engine.
+type VaultTransitKeyProvider struct {
+	config *schema.StorageEncryptionVaultTransitConfiguration
+	client *http.Client
+	id     EncryptionKeyID
+}
+
+// ID returns the identifier of the Vault Transit key.
+func (p *VaultTransitKeyProvider) ID() (id EncryptionKeyID) {
+	return p.id
+}
+
+// IDs returns the identifier of the Vault Transit key.
+func (p *VaultTransitKeyProvider) IDs() (ids []EncryptionKeyID) {
+	return []EncryptionKeyID{p.id}
+}
+
+// GenerateDataKey returns a new data key generated by Vault and the data key wrapped by the Vault Transit key.
+func (p *VaultTransitKeyProvider) GenerateDataKey(ctx context.Context) (key, wrapped []byte, err error) {
+	var data vaultTransitResponseData
+
+	if err = p.do(ctx, "datakey/plaintext", vaultTransitRequest{Bits: encryptionDataKeyLength * 8}, &data); err != nil {
+		return nil, nil, err
+	}
+
+	if data.Ciphertext == "" {
+		return nil, nil, fmt.Errorf("error generating data key with vault transit: the response did not include the ciphertext")
+	}
+
+	if key, err = base64.StdEncoding.DecodeString(data.Plaintext); err != nil {
+		return nil, nil, fmt.Errorf("error generating data key with vault transit: error decoding the plaintext: %w", err)
+	}
+
+	return key, []byte(data.Ciphertext), nil
+}
+
+// DecryptDataKey returns the data key unwrapped by the Vault Transit key.
+func (p *VaultTransitKeyProvider) DecryptDataKey(ctx context.Context, wrapped []byte) (key []byte, err error) {
+	var data vaultTransitResponseData
+
+	if err = p.do(ctx, "decrypt", vaultTransitRequest{Ciphertext: string(wrapped)}, &data); err != nil {
+		return nil, err
+	}
+
+	if key, err = base64.StdEncoding.DecodeString(data.Plaintext); err != nil {
+		return nil, fmt.Errorf("error decrypting data key with vault transit: error decoding the plaintext: %w", err)
+	}
+
+	return key, nil
+}
+
+func (p *VaultTransitKeyProvider) do(ctx context.Context, operation string, body vaultTransitRequest, data *vaultTransitResponseData) (err error) {
+	var payload []byte
+
+	if payload, err = json.Marshal(body); err != nil {
+		return err
+	}
+
+	elements := append([]string{"v1", p.config.Mount}, strings.Split(operation, "/")...)
+
+	endpoint := p.config.Address.JoinPath(append(elements, p.config.KeyName)...)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("error creating vault transit %s request: %w", operation, err)
+	}
+
+	req.Header.Set(headerContentType, contentTypeApplicationJSON)
+	req.Header.Set(vaultHeaderToken, p.config.Token)
+
+	if p.config.Namespace != "" {
+		req.Header.Set(vaultHeaderNamespace, p.config.Namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error performing vault transit %s request: %w", operation, err)
+	}
+
+	defer resp.Body.Close()
+
+	var result vaultTransitResponse
+
+	if err = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil && resp.StatusCode == http.StatusOK {
+		return fmt.Errorf("error decoding vault transit %s response: %w", operation, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if len(result.Errors) != 0 {
+			return fmt.Errorf("error performing vault transit %s request: status code %d: %s", operation, resp.StatusCode, strings.Join(result.Errors, ", "))
+		}
+
+		return fmt.Errorf("error performing vault transit %s request: status code %d", operation, resp.StatusCode)
+	}
+
+	*data = result.Data
+
+	return nil
+}
+
+type vaultTransitRequest struct {
+	Bits       int    `json:"bits,omitempty"`
+	Ciphertext string `json:"ciphertext,omitempty"`
+}
+
+type vaultTransitResponse struct {
+	Data   vaultTransitResponseData `json:"data"`
+	Errors []string                 `json:"errors"`
+}
+
+type vaultTransitResponseData struct {
+	Plaintext  string `json:"plaintext"`
+	Ciphertext string `json:"ciphertext"`
+}
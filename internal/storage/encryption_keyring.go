@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+// NewEncryptionKey derives an EncryptionKey from the given secret.
+func NewEncryptionKey(secret string) (key EncryptionKey) {
+	key.key = sha256.Sum256([]byte(secret))
+
+	mac := hmac.New(sha256.New, key.key[:])
+	mac.Write([]byte(encryptionKeyIDContext))
+
+	copy(key.ID[:], mac.Sum(nil))
+
+	return key
+}
+
+// EncryptionKeyID is the identifier of an EncryptionKey which is stored alongside the values encrypted with it. It's
+// derived from the key itself so it does not need to be configured.
+type EncryptionKeyID [encryptionKeyIDLength]byte
+
+// String returns the hex representation of the EncryptionKeyID.
+func (id EncryptionKeyID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// EncryptionKey is a key used to encrypt values in the database.
+type EncryptionKey struct {
+	ID EncryptionKeyID
+
+	key [32]byte
+}
+
+// Encrypt the clear text with this key prefixing the cipher text with the header which identifies the key.
+func (k EncryptionKey) Encrypt(clearText []byte) (cipherText []byte, err error) {
+	if cipherText, err = utils.Encrypt(clearText, &k.key); err != nil {
+		return nil, err
+	}
+
+	return append(k.header(), cipherText...), nil
+}
+
+func (k EncryptionKey) header() (header []byte) {
+	header = make([]byte, 0, encryptionHeaderLength)
+
+	header = append(header, encryptionHeaderMagic...)
+
+	return append(header, k.ID[:]...)
+}
+
+// NewEncryptionKeyring creates a new EncryptionKeyring which encrypts values with the key derived from the current
+// secret and decrypts values with the keys derived from the current secret and the decryption secrets. The current
+// secret may be empty when the keyring is used with an EncryptionKeyProvider.
+func NewEncryptionKeyring(current string, decryption ...string) (keyring *EncryptionKeyring) {
+	keyring = &EncryptionKeyring{
+		current: NewEncryptionKey(current),
+		keys:    map[EncryptionKeyID]EncryptionKey{},
+	}
+
+	if current != "" {
+		keyring.add(keyring.current)
+	}
+
+	for _, secret := range decryption {
+		keyring.add(NewEncryptionKey(secret))
+	}
+
+	return keyring
+}
+
+// EncryptionKeyring is a set of EncryptionKey's where the current key is used to encrypt values and every key is used
+// to decrypt values. This allows the encryption key to be changed without re-encrypting every value at the same time.
+// When the keyring has an EncryptionKeyProvider values are instead encrypted with data keys from the provider and the
+// keys are only used to decrypt values.
+type EncryptionKeyring struct {
+	current  EncryptionKey
+	keys     map[EncryptionKeyID]EncryptionKey
+	ordered  []EncryptionKey
+	envelope *encryptionEnvelope
+}
+
+func (k *EncryptionKeyring) add(key EncryptionKey) {
+	if _, ok := k.keys[key.ID]; ok {
+		return
+	}
+
+	k.keys[key.ID] = key
+	k.ordered = append(k.ordered, key)
+}
+
+// WithKeyProvider returns a copy of this EncryptionKeyring which encrypts values with data keys from the
+// EncryptionKeyProvider and caches the data keys using the cache configuration.
+func (k *EncryptionKeyring) WithKeyProvider(provider EncryptionKeyProvider, config schema.StorageEncryptionDataKeyCacheConfiguration) (keyring *EncryptionKeyring) {
+	return &EncryptionKeyring{
+		current:  k.current,
+		keys:     k.keys,
+		ordered:  k.ordered,
+		envelope: newEncryptionEnvelope(provider, config),
+	}
+}
+
+// KeyProvider returns the EncryptionKeyProvider of this EncryptionKeyring or nil if it doesn't have one.
+func (k *EncryptionKeyring) KeyProvider() (provider EncryptionKeyProvider) {
+	if k.envelope == nil {
+		return nil
+	}
+
+	return k.envelope.provider
+}
+
+// WithCurrent returns a copy of this EncryptionKeyring which uses the given key as the current key. The copy doesn't
+// have the EncryptionKeyProvider of this EncryptionKeyring.
+func (k *EncryptionKeyring) WithCurrent(key EncryptionKey) (keyring *EncryptionKeyring) {
+	keyring = &EncryptionKeyring{
+		current: key,
+		keys:    map[EncryptionKeyID]EncryptionKey{},
+	}
+
+	keyring.add(key)
+
+	for _, key = range k.ordered {
+		keyring.add(key)
+	}
+
+	return keyring
+}
+
+// Current returns the EncryptionKey used to encrypt values when the keyring doesn't have an EncryptionKeyProvider.
+func (k *EncryptionKeyring) Current() (key EncryptionKey) {
+	return k.current
+}
+
+// CurrentID returns the EncryptionKeyID of the key used to encrypt values.
+func (k *EncryptionKeyring) CurrentID() (id EncryptionKeyID) {
+	if k.envelope != nil {
+		return k.envelope.provider.ID()
+	}
+
+	return k.current.ID
+}
+
+// IDs returns the EncryptionKeyID of each key in the keyring with the current key first.
+func (k *EncryptionKeyring) IDs() (ids []EncryptionKeyID) {
+	if k.envelope != nil {
+		ids = append(ids, k.envelope.provider.IDs()...)
+	}
+
+	for _, key := range k.ordered {
+		ids = append(ids, key.ID)
+	}
+
+	return ids
+}
+
+// Encrypt the clear text with the current key.
+func (k *EncryptionKeyring) Encrypt(ctx context.Context, clearText []byte) (cipherText []byte, err error) {
+	if k.envelope != nil {
+		return k.envelope.encrypt(ctx, clearText)
+	}
+
+	return k.current.Encrypt(clearText)
+}
+
+// Decrypt the cipher text with the key identified by its header. Values encrypted before keys were identified have no
+// header in which case each key is attempted and the legacy return value is true.
+func (k *EncryptionKeyring) Decrypt(ctx context.Context, cipherText []byte) (clearText []byte, id EncryptionKeyID, legacy bool, err error) {
+	var errEnvelope error
+
+	if len(cipherText) > encryptionHeaderLength {
+		switch {
+		case bytes.HasPrefix(cipherText, encryptionHeaderMagic):
+			copy(id[:], cipherText[len(encryptionHeaderMagic):encryptionHeaderLength])
+
+			if key, ok := k.keys[id]; ok {
+				if clearText, err = utils.Decrypt(cipherText[encryptionHeaderLength:], &key.key); err == nil {
+					return clearText, id, false, nil
+				}
+			}
+		case k.envelope != nil && bytes.HasPrefix(cipherText, encryptionEnvelopeHeaderMagic):
+			copy(id[:], cipherText[len(encryptionEnvelopeHeaderMagic):encryptionHeaderLength])
+
+			if clearText, errEnvelope = k.envelope.decrypt(ctx, cipherText[encryptionHeaderLength:]); errEnvelope == nil {
+				return clearText, id, false, nil
+			}
+		}
+	}
+
+	for _, key := range k.ordered {
+		if clearText, err = utils.Decrypt(cipherText, &key.key); err == nil {
+			return clearText, key.ID, true, nil
+		}
+	}
+
+	if errEnvelope != nil {
+		return nil, id, false, errEnvelope
+	}
+
+	return nil, id, false, ErrEncryptionNoKey
+}
+
+// IsCurrent returns true if the cipher text has the header of the current key.
+func (k *EncryptionKeyring) IsCurrent(cipherText []byte) bool {
+	return len(cipherText) > encryptionHeaderLength && bytes.HasPrefix(cipherText, k.header())
+}
+
+// header returns the header of values encrypted with the current key.
+func (k *EncryptionKeyring) header() (header []byte) {
+	if k.envelope != nil {
+		return k.envelope.header()
+	}
+
+	return k.current.header()
+}
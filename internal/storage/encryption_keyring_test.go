@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+func TestEncryptionKeyring(t *testing.T) {
+	previous := NewEncryptionKeyring("an_old_encryption_key_which_is_long")
+	keyring := NewEncryptionKeyring("a_new_encryption_key_which_is_long", "an_old_encryption_key_which_is_long", "a_new_encryption_key_which_is_long")
+
+	assert.Equal(t, []EncryptionKeyID{keyring.Current().ID, previous.Current().ID}, keyring.IDs())
+	assert.NotEqual(t, keyring.Current().ID, previous.Current().ID)
+	assert.Equal(t, NewEncryptionKey("an_old_encryption_key_which_is_long").ID, previous.Current().ID)
+	assert.Len(t, keyring.Current().ID.String(), encryptionKeyIDLength*2)
+
+	cipherText, err := keyring.Encrypt(context.Background(), []byte("example"))
+	require.NoError(t, err)
+
+	assert.True(t, keyring.IsCurrent(cipherText))
+	assert.False(t, previous.IsCurrent(cipherText))
+
+	clearText, id, legacy, err := keyring.Decrypt(context.Background(), cipherText)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("example"), clearText)
+	assert.Equal(t, keyring.Current().ID, id)
+	assert.False(t, legacy)
+
+	_, _, _, err = previous.Decrypt(context.Background(), cipherText)
+	assert.ErrorIs(t, err, ErrEncryptionNoKey)
+
+	cipherText, err = previous.Encrypt(context.Background(), []byte("example"))
+	require.NoError(t, err)
+
+	assert.False(t, keyring.IsCurrent(cipherText))
+
+	clearText, id, legacy, err = keyring.Decrypt(context.Background(), cipherText)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("example"), clearText)
+	assert.Equal(t, previous.Current().ID, id)
+	assert.False(t, legacy)
+
+	key := previous.Current()
+
+	cipherText, err = utils.Encrypt([]byte("example"), &key.key)
+	require.NoError(t, err)
+
+	assert.False(t, previous.IsCurrent(cipherText))
+
+	clearText, id, legacy, err = keyring.Decrypt(context.Background(), cipherText)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("example"), clearText)
+	assert.Equal(t, previous.Current().ID, id)
+	assert.True(t, legacy)
+}
+
+func TestEncryptionKeyringWithCurrent(t *testing.T) {
+	keyring := NewEncryptionKeyring("an_old_encryption_key_which_is_long")
+
+	key := NewEncryptionKey("a_new_encryption_key_which_is_long")
+
+	changed := keyring.WithCurrent(key)
+
+	assert.Equal(t, key.ID, changed.Current().ID)
+	assert.Equal(t, []EncryptionKeyID{key.ID, keyring.Current().ID}, changed.IDs())
+	assert.Equal(t, []EncryptionKeyID{keyring.Current().ID}, keyring.IDs())
+}
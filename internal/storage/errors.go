@@ -14,6 +14,9 @@ var (
 	// ErrNoWebauthnDevice error thrown when no Webauthn device handle has been found in DB.
 	ErrNoWebauthnDevice = errors.New("no Webauthn device found")
 
+	// ErrNoOutboundNotification error thrown when no failed outbound notification has been found in DB.
+	ErrNoOutboundNotification = errors.New("no failed outbound notification found")
+
 	// ErrNoDuoDevice error thrown when no Duo device and method has been found in DB.
 	ErrNoDuoDevice = errors.New("no Duo device and method saved")
 
@@ -36,6 +39,35 @@ var (
 	// ErrSchemaEncryptionInvalidKey is returned when the schema is checked if the encryption key is valid for
 	// the database but the key doesn't appear to be valid.
 	ErrSchemaEncryptionInvalidKey = errors.New("the configured encryption key does not appear to be valid for this database which may occur if the encryption key was changed in the configuration without using the cli to change it in the database")
+
+	// ErrEncryptionNoKey is returned when a value can't be decrypted by any of the keys in the encryption keyring.
+	ErrEncryptionNoKey = errors.New("none of the encryption keys could decrypt the value")
+
+	// ErrEncryptionEnvelopeMalformed is returned when a value encrypted with a data key from a key provider doesn't
+	// contain a valid wrapped data key.
+	ErrEncryptionEnvelopeMalformed = errors.New("the value encrypted with a data key from the key provider is malformed")
+
+	// ErrEncryptionKeyProviderChangeKey is returned when the encryption key is changed with the cli while a key provider
+	// is configured.
+	ErrEncryptionKeyProviderChangeKey = errors.New("the encryption key can't be changed while a key provider is configured as values are encrypted with data keys from the key provider")
+
+	// ErrSchemaCopyTargetNotEmpty is returned when the schema is copied to a storage provider which already contains
+	// data.
+	ErrSchemaCopyTargetNotEmpty = errors.New("the target storage must not contain any data")
+
+	// ErrSchemaCopyTargetUnsupported is returned when the schema is copied to a storage provider which isn't a SQL
+	// storage provider.
+	ErrSchemaCopyTargetUnsupported = errors.New("the target storage provider is not supported")
+
+	// ErrBackupMalformed is returned when a backup can't be read because it's not a backup or it's malformed.
+	ErrBackupMalformed = errors.New("the file is not a backup or it's malformed")
+
+	// ErrBackupDecrypt is returned when a backup can't be decrypted which occurs when the passphrase is incorrect or
+	// the backup was modified.
+	ErrBackupDecrypt = errors.New("the backup could not be decrypted which usually means the passphrase is incorrect or the backup has been modified")
+
+	// ErrBackupTruncated is returned when a backup ends before the final chunk.
+	ErrBackupTruncated = errors.New("the backup is truncated")
 )
 
 // Error formats for the storage provider.
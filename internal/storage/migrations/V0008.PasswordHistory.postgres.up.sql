@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS password_history (
+    id SERIAL CONSTRAINT password_history_pkey PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    username VARCHAR(100) NOT NULL,
+    digest TEXT NOT NULL
+);
+
+CREATE INDEX password_history_username_idx ON password_history (username);
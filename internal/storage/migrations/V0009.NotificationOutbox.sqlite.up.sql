@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS notification_outbox (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP NULL DEFAULT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL,
+    template VARCHAR(100) NOT NULL,
+    recipient TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    data BLOB NOT NULL,
+    last_error TEXT NULL DEFAULT NULL
+);
+
+CREATE INDEX notification_outbox_status_next_attempt_at_idx ON notification_outbox (status, next_attempt_at);
@@ -0,0 +1,2 @@
+ALTER TABLE authentication_logs
+    DROP COLUMN user_agent;
@@ -0,0 +1,2 @@
+ALTER TABLE notification_outbox
+    DROP COLUMN locale;
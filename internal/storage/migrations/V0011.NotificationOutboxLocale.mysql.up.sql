@@ -0,0 +1,2 @@
+ALTER TABLE notification_outbox
+    ADD COLUMN locale VARCHAR(35) NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS user_password_changes (
+    id SERIAL CONSTRAINT user_password_changes_pkey PRIMARY KEY,
+    username VARCHAR(100) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX user_password_changes_username_key ON user_password_changes (username);
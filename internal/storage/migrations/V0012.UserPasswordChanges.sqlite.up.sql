@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS user_password_changes (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(100) NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (username)
+);
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"io"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,6 +24,27 @@ type Provider interface {
 	LoadPreferred2FAMethod(ctx context.Context, username string) (method string, err error)
 	LoadUserInfo(ctx context.Context, username string) (info model.UserInfo, err error)
 
+	LoadAuthenticationHistory(ctx context.Context, username, authType string, ip model.NullIP, userAgent string) (history *model.AuthenticationHistory, err error)
+
+	SaveUserPasswordChange(ctx context.Context, username string, changedAt time.Time) (err error)
+	LoadUserPasswordChange(ctx context.Context, username string) (changedAt time.Time, err error)
+
+	SavePasswordHistory(ctx context.Context, history model.PasswordHistory) (err error)
+	LoadPasswordHistory(ctx context.Context, username string, limit int) (history []model.PasswordHistory, err error)
+	PrunePasswordHistory(ctx context.Context, username string, keep int) (err error)
+
+	SaveOutboundNotification(ctx context.Context, notification model.OutboundNotification) (err error)
+	LoadOutboundNotificationsDue(ctx context.Context, now time.Time, limit int) (notifications []model.OutboundNotification, err error)
+	LoadOutboundNotifications(ctx context.Context, status string, limit, page int) (notifications []model.OutboundNotification, err error)
+	ClaimOutboundNotification(ctx context.Context, id int, now, until time.Time) (claimed bool, err error)
+	UpdateOutboundNotificationStatus(ctx context.Context, notification model.OutboundNotification) (err error)
+	RetryOutboundNotification(ctx context.Context, id int, at time.Time) (err error)
+	RetryOutboundNotifications(ctx context.Context, at time.Time) (count int64, err error)
+	PurgeOutboundNotifications(ctx context.Context, status string, before time.Time) (count int64, err error)
+
+	Prune(ctx context.Context, policy PrunePolicy, limit int) (result PruneResult, err error)
+	LoadPruneCounts(ctx context.Context, policy PrunePolicy) (result PruneResult, err error)
+
 	SaveUserOpaqueIdentifier(ctx context.Context, subject model.UserOpaqueIdentifier) (err error)
 	LoadUserOpaqueIdentifier(ctx context.Context, opaqueUUID uuid.UUID) (subject *model.UserOpaqueIdentifier, err error)
 	LoadUserOpaqueIdentifiers(ctx context.Context) (opaqueIDs []model.UserOpaqueIdentifier, err error)
@@ -79,6 +101,11 @@ type Provider interface {
 
 	SchemaEncryptionChangeKey(ctx context.Context, key string) (err error)
 	SchemaEncryptionCheckKey(ctx context.Context, verbose bool) (result EncryptionValidationResult, err error)
+	SchemaEncryptionRekey(ctx context.Context, state *EncryptionRekeyState, limit int) (err error)
+
+	SchemaCopy(ctx context.Context, target Provider, limit int) (result SchemaCopyResult, err error)
+	SchemaBackup(ctx context.Context, w io.Writer, passphrase string, limit int) (result SchemaBackupResult, err error)
+	SchemaRestore(ctx context.Context, backup *BackupReader, limit int) (result SchemaBackupResult, err error)
 
 	Close() (err error)
 }
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+)
+
+// NewPrunePolicy creates a PrunePolicy from the storage retention configuration relative to the given time. The
+// OAuth 2.0 sessions are only pruned once the configured lifespan of the token has elapsed as well as the retention.
+func NewPrunePolicy(config *schema.Configuration, now time.Time) (policy PrunePolicy) {
+	retention := config.Storage.Retention
+
+	lifespans := schema.DefaultOpenIDConnectConfiguration
+
+	if config.IdentityProviders.OIDC != nil {
+		lifespans = *config.IdentityProviders.OIDC
+	}
+
+	policy = PrunePolicy{
+		AuthenticationLogs:             pruneBefore(now, retention.AuthenticationLogs, 0),
+		IdentityVerification:           pruneBefore(now, retention.IdentityVerification, 0),
+		OAuth2ConsentSessions:          pruneBefore(now, retention.OAuth2Sessions, 0),
+		OAuth2ConsentPreConfigurations: pruneBefore(now, retention.OAuth2ConsentPreConfiguration, 0),
+		OAuth2BlacklistedJTI:           pruneBefore(now, retention.OAuth2BlacklistedJTI, 0),
+		OAuth2Sessions: map[OAuth2SessionType]time.Time{
+			OAuth2SessionTypeAuthorizeCode: pruneBefore(now, retention.OAuth2Sessions, pruneLifespan(lifespans.AuthorizeCodeLifespan, schema.DefaultOpenIDConnectConfiguration.AuthorizeCodeLifespan)),
+			OAuth2SessionTypeAccessToken:   pruneBefore(now, retention.OAuth2Sessions, pruneLifespan(lifespans.AccessTokenLifespan, schema.DefaultOpenIDConnectConfiguration.AccessTokenLifespan)),
+			OAuth2SessionTypeRefreshToken:  pruneBefore(now, retention.OAuth2Sessions, pruneLifespan(lifespans.RefreshTokenLifespan, schema.DefaultOpenIDConnectConfiguration.RefreshTokenLifespan)),
+			OAuth2SessionTypePKCEChallenge: pruneBefore(now, retention.OAuth2Sessions, pruneLifespan(lifespans.AuthorizeCodeLifespan, schema.DefaultOpenIDConnectConfiguration.AuthorizeCodeLifespan)),
+			OAuth2SessionTypeOpenIDConnect: pruneBefore(now, retention.OAuth2Sessions, pruneLifespan(lifespans.AuthorizeCodeLifespan, schema.DefaultOpenIDConnectConfiguration.AuthorizeCodeLifespan)),
+		},
+	}
+
+	return policy
+}
+
+// PrunePolicy is the time before which the data of each type is pruned. A zero time disables pruning of the type.
+type PrunePolicy struct {
+	AuthenticationLogs   time.Time
+	IdentityVerification time.Time
+
+	// OAuth2Sessions is compared to the time each OAuth 2.0 session was requested.
+	OAuth2Sessions map[OAuth2SessionType]time.Time
+
+	// OAuth2ConsentSessions is compared to the time each consent session was requested. Consent sessions which are
+	// still referenced by an OAuth 2.0 session that is not pruned are retained.
+	OAuth2ConsentSessions time.Time
+
+	// OAuth2ConsentPreConfigurations is compared to the time each pre-configured consent expired or if it was revoked
+	// the time it was created. Pre-configured consents which are still referenced by a consent session that is not
+	// pruned are retained.
+	OAuth2ConsentPreConfigurations time.Time
+
+	OAuth2BlacklistedJTI time.Time
+}
+
+// PruneResult contains the number of rows pruned or which would be pruned from each table.
+type PruneResult struct {
+	Tables []PruneTableResult
+
+	// Complete is true when every table had fewer rows pruned than the limit so there's nothing left to prune.
+	Complete bool
+}
+
+// Total returns the number of rows pruned from every table.
+func (r PruneResult) Total() (total int64) {
+	for _, table := range r.Tables {
+		total += table.Count
+	}
+
+	return total
+}
+
+// Merge adds the counts of each table of the other PruneResult to this PruneResult which is complete if the other
+// PruneResult is complete.
+func (r *PruneResult) Merge(other PruneResult) {
+	for _, table := range other.Tables {
+		merged := false
+
+		for i := range r.Tables {
+			if r.Tables[i].Table == table.Table {
+				r.Tables[i].Count += table.Count
+				merged = true
+
+				break
+			}
+		}
+
+		if !merged {
+			r.Tables = append(r.Tables, table)
+		}
+	}
+
+	r.Complete = other.Complete
+}
+
+// PruneTableResult contains the number of rows pruned or which would be pruned from a table.
+type PruneTableResult struct {
+	Table string
+	Count int64
+}
+
+func pruneBefore(now time.Time, retention, lifespan time.Duration) time.Time {
+	if retention < 0 {
+		return time.Time{}
+	}
+
+	return now.Add(-retention - lifespan)
+}
+
+func pruneLifespan(lifespan, fallback time.Duration) time.Duration {
+	if lifespan <= 0 {
+		return fallback
+	}
+
+	return lifespan
+}
@@ -2,7 +2,7 @@ package storage
 
 import (
 	"context"
-	"crypto/sha256"
+	"crypto/x509"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -19,20 +19,41 @@ import (
 )
 
 // NewSQLProvider generates a generic SQLProvider to be used with other SQL provider NewUp's.
-func NewSQLProvider(config *schema.Configuration, name, driverName, dataSourceName string) (provider SQLProvider) {
+func NewSQLProvider(config *schema.Configuration, caCertPool *x509.CertPool, name, driverName, dataSourceName string) (provider SQLProvider) {
 	db, err := sqlx.Open(driverName, dataSourceName)
 
+	keys, errKeys := newEncryptionKeyring(&config.Storage, caCertPool)
+
 	provider = SQLProvider{
 		db:         db,
-		key:        sha256.Sum256([]byte(config.Storage.EncryptionKey)),
+		keys:       keys,
 		name:       name,
 		driverName: driverName,
 		config:     config,
 		errOpen:    err,
+		errKeys:    errKeys,
 		log:        logging.Logger(),
 
 		sqlInsertAuthenticationAttempt:            fmt.Sprintf(queryFmtInsertAuthenticationLogEntry, tableAuthenticationLogs),
 		sqlSelectAuthenticationAttemptsByUsername: fmt.Sprintf(queryFmtSelect1FAAuthenticationLogEntryByUsername, tableAuthenticationLogs),
+		sqlSelectAuthenticationHistory:            fmt.Sprintf(queryFmtSelectAuthenticationHistoryByUsernameAndType, tableAuthenticationLogs),
+
+		sqlUpsertUserPasswordChange: fmt.Sprintf(queryFmtUpsertUserPasswordChange, tableUserPasswordChanges),
+		sqlSelectUserPasswordChange: fmt.Sprintf(queryFmtSelectUserPasswordChange, tableUserPasswordChanges),
+
+		sqlInsertPasswordHistory:      fmt.Sprintf(queryFmtInsertPasswordHistory, tablePasswordHistory),
+		sqlSelectPasswordHistory:      fmt.Sprintf(queryFmtSelectPasswordHistory, tablePasswordHistory),
+		sqlDeletePasswordHistoryPrune: fmt.Sprintf(queryFmtDeletePasswordHistoryPrune, tablePasswordHistory, tablePasswordHistory),
+
+		sqlInsertOutboundNotification:          fmt.Sprintf(queryFmtInsertOutboundNotification, tableNotificationOutbox),
+		sqlSelectOutboundNotificationsDue:      fmt.Sprintf(queryFmtSelectOutboundNotificationsDue, tableNotificationOutbox),
+		sqlSelectOutboundNotifications:         fmt.Sprintf(queryFmtSelectOutboundNotifications, tableNotificationOutbox),
+		sqlSelectOutboundNotificationsByStatus: fmt.Sprintf(queryFmtSelectOutboundNotificationsByStatus, tableNotificationOutbox),
+		sqlUpdateOutboundNotificationClaim:     fmt.Sprintf(queryFmtUpdateOutboundNotificationClaim, tableNotificationOutbox),
+		sqlUpdateOutboundNotificationStatus:    fmt.Sprintf(queryFmtUpdateOutboundNotificationStatus, tableNotificationOutbox),
+		sqlUpdateOutboundNotificationRetry:     fmt.Sprintf(queryFmtUpdateOutboundNotificationRetry, tableNotificationOutbox),
+		sqlUpdateOutboundNotificationsRetry:    fmt.Sprintf(queryFmtUpdateOutboundNotificationsRetry, tableNotificationOutbox),
+		sqlDeleteOutboundNotifications:         fmt.Sprintf(queryFmtDeleteOutboundNotifications, tableNotificationOutbox),
 
 		sqlInsertIdentityVerification:  fmt.Sprintf(queryFmtInsertIdentityVerification, tableIdentityVerification),
 		sqlConsumeIdentityVerification: fmt.Sprintf(queryFmtConsumeIdentityVerification, tableIdentityVerification),
@@ -124,6 +145,9 @@ func NewSQLProvider(config *schema.Configuration, name, driverName, dataSourceNa
 		sqlUpsertEncryptionValue: fmt.Sprintf(queryFmtUpsertEncryptionValue, tableEncryption),
 		sqlSelectEncryptionValue: fmt.Sprintf(queryFmtSelectEncryptionValue, tableEncryption),
 
+		sqlSelectPruneCount: sqlPruneQueries(queryFmtSelectPruneCount),
+		sqlDeletePrune:      sqlPruneQueries(queryFmtDeletePrune),
+
 		sqlFmtRenameTable: queryFmtRenameTable,
 	}
 
@@ -133,18 +157,40 @@ func NewSQLProvider(config *schema.Configuration, name, driverName, dataSourceNa
 // SQLProvider is a storage provider persisting data in a SQL database.
 type SQLProvider struct {
 	db         *sqlx.DB
-	key        [32]byte
+	keys       *EncryptionKeyring
 	name       string
 	driverName string
 	schema     string
 	config     *schema.Configuration
 	errOpen    error
+	errKeys    error
 
 	log *logrus.Logger
 
 	// Table: authentication_logs.
 	sqlInsertAuthenticationAttempt            string
 	sqlSelectAuthenticationAttemptsByUsername string
+	sqlSelectAuthenticationHistory            string
+
+	// Table: user_password_changes.
+	sqlUpsertUserPasswordChange string
+	sqlSelectUserPasswordChange string
+
+	// Table: password_history.
+	sqlInsertPasswordHistory      string
+	sqlSelectPasswordHistory      string
+	sqlDeletePasswordHistoryPrune string
+
+	// Table: notification_outbox.
+	sqlInsertOutboundNotification          string
+	sqlSelectOutboundNotificationsDue      string
+	sqlSelectOutboundNotifications         string
+	sqlSelectOutboundNotificationsByStatus string
+	sqlUpdateOutboundNotificationClaim     string
+	sqlUpdateOutboundNotificationStatus    string
+	sqlUpdateOutboundNotificationRetry     string
+	sqlUpdateOutboundNotificationsRetry    string
+	sqlDeleteOutboundNotifications         string
 
 	// Table: identity_verification.
 	sqlInsertIdentityVerification  string
@@ -251,9 +297,17 @@ type SQLProvider struct {
 	sqlUpsertOAuth2BlacklistedJTI string
 	sqlSelectOAuth2BlacklistedJTI string
 
+	// Pruning keyed by table.
+	sqlSelectPruneCount map[string]string
+	sqlDeletePrune      map[string]string
+
 	// Utility.
 	sqlSelectExistingTables string
 	sqlFmtRenameTable       string
+
+	// sqlFmtUpdateSequence is used after rows are copied with their ids to ensure the next generated id doesn't
+	// collide with them. It's empty for dialects which do this automatically.
+	sqlFmtUpdateSequence string
 }
 
 // Close the underlying database connection.
@@ -267,6 +321,10 @@ func (p *SQLProvider) StartupCheck() (err error) {
 		return fmt.Errorf("error opening database: %w", p.errOpen)
 	}
 
+	if p.errKeys != nil {
+		return fmt.Errorf("error initializing the encryption key provider: %w", p.errKeys)
+	}
+
 	// TODO: Decide if this is needed, or if it should be configurable.
 	for i := 0; i < 19; i++ {
 		if err = p.db.Ping(); err == nil {
@@ -297,12 +355,17 @@ func (p *SQLProvider) StartupCheck() (err error) {
 	switch err = p.SchemaMigrate(ctx, true, SchemaLatest); err {
 	case ErrSchemaAlreadyUpToDate:
 		p.log.Infof("Storage schema is already up to date")
-		return nil
 	case nil:
-		return nil
+		break
 	default:
 		return fmt.Errorf("error during schema migrate: %w", err)
 	}
+
+	if err = p.schemaEncryptionRekeyCheckValue(ctx); err != nil {
+		return fmt.Errorf("error updating the encryption check value: %w", err)
+	}
+
+	return nil
 }
 
 // BeginTX begins a transaction.
@@ -510,7 +573,7 @@ func (p *SQLProvider) SaveOAuth2Session(ctx context.Context, sessionType OAuth2S
 		return fmt.Errorf("error inserting oauth2 session for subject '%s' and request id '%s': unknown oauth2 session type '%s'", session.Subject, session.RequestID, sessionType)
 	}
 
-	if session.Session, err = p.encrypt(session.Session); err != nil {
+	if session.Session, err = p.encrypt(ctx, session.Session); err != nil {
 		return fmt.Errorf("error encrypting the oauth2 %s session data for subject '%s' and request id '%s' and challenge id '%s': %w", sessionType, session.Subject, session.RequestID, session.ChallengeID.String(), err)
 	}
 
@@ -656,7 +719,7 @@ func (p *SQLProvider) LoadOAuth2Session(ctx context.Context, sessionType OAuth2S
 		return nil, fmt.Errorf("error selecting oauth2 %s session with signature '%s': %w", sessionType.String(), signature, err)
 	}
 
-	if session.Session, err = p.decrypt(session.Session); err != nil {
+	if session.Session, err = p.decrypt(ctx, session.Session); err != nil {
 		return nil, fmt.Errorf("error decrypting the oauth2 %s session data with signature '%s' for subject '%s' and request id '%s': %w", sessionType.String(), signature, session.Subject, session.RequestID, err)
 	}
 
@@ -761,7 +824,7 @@ func (p *SQLProvider) FindIdentityVerification(ctx context.Context, jti string)
 
 // SaveTOTPConfiguration save a TOTP configuration of a given user in the database.
 func (p *SQLProvider) SaveTOTPConfiguration(ctx context.Context, config model.TOTPConfiguration) (err error) {
-	if config.Secret, err = p.encrypt(config.Secret); err != nil {
+	if config.Secret, err = p.encrypt(ctx, config.Secret); err != nil {
 		return fmt.Errorf("error encrypting the TOTP configuration secret for user '%s': %w", config.Username, err)
 	}
 
@@ -805,7 +868,7 @@ func (p *SQLProvider) LoadTOTPConfiguration(ctx context.Context, username string
 		return nil, fmt.Errorf("error selecting TOTP configuration for user '%s': %w", username, err)
 	}
 
-	if config.Secret, err = p.decrypt(config.Secret); err != nil {
+	if config.Secret, err = p.decrypt(ctx, config.Secret); err != nil {
 		return nil, fmt.Errorf("error decrypting the TOTP secret for user '%s': %w", username, err)
 	}
 
@@ -825,7 +888,7 @@ func (p *SQLProvider) LoadTOTPConfigurations(ctx context.Context, limit, page in
 	}
 
 	for i, c := range configs {
-		if configs[i].Secret, err = p.decrypt(c.Secret); err != nil {
+		if configs[i].Secret, err = p.decrypt(ctx, c.Secret); err != nil {
 			return nil, fmt.Errorf("error decrypting TOTP configuration for user '%s': %w", c.Username, err)
 		}
 	}
@@ -835,7 +898,7 @@ func (p *SQLProvider) LoadTOTPConfigurations(ctx context.Context, limit, page in
 
 // SaveWebauthnDevice saves a registered Webauthn device.
 func (p *SQLProvider) SaveWebauthnDevice(ctx context.Context, device model.WebauthnDevice) (err error) {
-	if device.PublicKey, err = p.encrypt(device.PublicKey); err != nil {
+	if device.PublicKey, err = p.encrypt(ctx, device.PublicKey); err != nil {
 		return fmt.Errorf("error encrypting the Webauthn device public key for user '%s' kid '%x': %w", device.Username, device.KID, err)
 	}
 
@@ -901,7 +964,7 @@ func (p *SQLProvider) LoadWebauthnDevices(ctx context.Context, limit, page int)
 	}
 
 	for i, device := range devices {
-		if devices[i].PublicKey, err = p.decrypt(device.PublicKey); err != nil {
+		if devices[i].PublicKey, err = p.decrypt(ctx, device.PublicKey); err != nil {
 			return nil, fmt.Errorf("error decrypting Webauthn public key for user '%s': %w", device.Username, err)
 		}
 	}
@@ -920,7 +983,7 @@ func (p *SQLProvider) LoadWebauthnDevicesByUsername(ctx context.Context, usernam
 	}
 
 	for i, device := range devices {
-		if devices[i].PublicKey, err = p.decrypt(device.PublicKey); err != nil {
+		if devices[i].PublicKey, err = p.decrypt(ctx, device.PublicKey); err != nil {
 			return nil, fmt.Errorf("error decrypting Webauthn public key for user '%s': %w", username, err)
 		}
 	}
@@ -965,7 +1028,7 @@ func (p *SQLProvider) LoadPreferredDuoDevice(ctx context.Context, username strin
 func (p *SQLProvider) AppendAuthenticationLog(ctx context.Context, attempt model.AuthenticationAttempt) (err error) {
 	if _, err = p.db.ExecContext(ctx, p.sqlInsertAuthenticationAttempt,
 		attempt.Time, attempt.Successful, attempt.Banned, attempt.Username,
-		attempt.Type, attempt.RemoteIP, attempt.RequestURI, attempt.RequestMethod); err != nil {
+		attempt.Type, attempt.RemoteIP, attempt.RequestURI, attempt.RequestMethod, attempt.UserAgent); err != nil {
 		return fmt.Errorf("error inserting authentication attempt for user '%s': %w", attempt.Username, err)
 	}
 
@@ -986,3 +1049,215 @@ func (p *SQLProvider) LoadAuthenticationLogs(ctx context.Context, username strin
 
 	return attempts, nil
 }
+
+// SaveUserPasswordChange saves the time the password of a user was last changed.
+func (p *SQLProvider) SaveUserPasswordChange(ctx context.Context, username string, changedAt time.Time) (err error) {
+	if _, err = p.db.ExecContext(ctx, p.sqlUpsertUserPasswordChange, username, changedAt); err != nil {
+		return fmt.Errorf("error upserting the password change time for user '%s': %w", username, err)
+	}
+
+	return nil
+}
+
+// LoadUserPasswordChange loads the time the password of a user was last changed. The time is zero if the password
+// change of the user was never saved.
+func (p *SQLProvider) LoadUserPasswordChange(ctx context.Context, username string) (changedAt time.Time, err error) {
+	if err = p.db.GetContext(ctx, &changedAt, p.sqlSelectUserPasswordChange, username); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, nil
+		}
+
+		return time.Time{}, fmt.Errorf("error selecting the password change time for user '%s': %w", username, err)
+	}
+
+	return changedAt, nil
+}
+
+// SavePasswordHistory saves a previous password of a user to the password history.
+func (p *SQLProvider) SavePasswordHistory(ctx context.Context, history model.PasswordHistory) (err error) {
+	if _, err = p.db.ExecContext(ctx, p.sqlInsertPasswordHistory, history.CreatedAt, history.Username, history.Digest); err != nil {
+		return fmt.Errorf("error inserting password history for user '%s': %w", history.Username, err)
+	}
+
+	return nil
+}
+
+// LoadPasswordHistory loads the most recent previous passwords of a user from the password history.
+func (p *SQLProvider) LoadPasswordHistory(ctx context.Context, username string, limit int) (history []model.PasswordHistory, err error) {
+	history = make([]model.PasswordHistory, 0, limit)
+
+	if err = p.db.SelectContext(ctx, &history, p.sqlSelectPasswordHistory, username, limit); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error selecting password history for user '%s': %w", username, err)
+	}
+
+	return history, nil
+}
+
+// PrunePasswordHistory deletes all but the most recent previous passwords of a user from the password history.
+func (p *SQLProvider) PrunePasswordHistory(ctx context.Context, username string, keep int) (err error) {
+	if _, err = p.db.ExecContext(ctx, p.sqlDeletePasswordHistoryPrune, username, username, keep); err != nil {
+		return fmt.Errorf("error pruning password history for user '%s': %w", username, err)
+	}
+
+	return nil
+}
+
+// SaveOutboundNotification saves a notification to the notification outbox encrypting the data.
+func (p *SQLProvider) SaveOutboundNotification(ctx context.Context, notification model.OutboundNotification) (err error) {
+	if notification.Data, err = p.encrypt(ctx, notification.Data); err != nil {
+		return fmt.Errorf("error encrypting the outbound notification data for template '%s': %w", notification.Template, err)
+	}
+
+	if _, err = p.db.ExecContext(ctx, p.sqlInsertOutboundNotification,
+		notification.CreatedAt, notification.NextAttemptAt, notification.Attempts, notification.Status,
+		notification.Template, notification.Locale, notification.Recipient, notification.Subject, notification.Data); err != nil {
+		return fmt.Errorf("error inserting outbound notification for template '%s': %w", notification.Template, err)
+	}
+
+	return nil
+}
+
+// LoadOutboundNotificationsDue loads the pending notifications from the notification outbox which are due to be
+// attempted, oldest first.
+func (p *SQLProvider) LoadOutboundNotificationsDue(ctx context.Context, now time.Time, limit int) (notifications []model.OutboundNotification, err error) {
+	notifications = make([]model.OutboundNotification, 0, limit)
+
+	if err = p.db.SelectContext(ctx, &notifications, p.sqlSelectOutboundNotificationsDue, model.OutboundNotificationStatusPending, now, limit); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error selecting due outbound notifications: %w", err)
+	}
+
+	return p.decryptOutboundNotifications(ctx, notifications)
+}
+
+// LoadOutboundNotifications loads a page of notifications from the notification outbox, newest first. If the status
+// is empty notifications of all statuses are loaded.
+func (p *SQLProvider) LoadOutboundNotifications(ctx context.Context, status string, limit, page int) (notifications []model.OutboundNotification, err error) {
+	notifications = make([]model.OutboundNotification, 0, limit)
+
+	if status == "" {
+		err = p.db.SelectContext(ctx, &notifications, p.sqlSelectOutboundNotifications, limit, limit*page)
+	} else {
+		err = p.db.SelectContext(ctx, &notifications, p.sqlSelectOutboundNotificationsByStatus, status, limit, limit*page)
+	}
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error selecting outbound notifications: %w", err)
+	}
+
+	return p.decryptOutboundNotifications(ctx, notifications)
+}
+
+// ClaimOutboundNotification claims a due pending notification for delivery by moving the next attempt to until. The
+// claim only succeeds if no other worker has claimed the notification since it was loaded.
+func (p *SQLProvider) ClaimOutboundNotification(ctx context.Context, id int, now, until time.Time) (claimed bool, err error) {
+	var (
+		result   sql.Result
+		affected int64
+	)
+
+	if result, err = p.db.ExecContext(ctx, p.sqlUpdateOutboundNotificationClaim, until, id, model.OutboundNotificationStatusPending, now); err != nil {
+		return false, fmt.Errorf("error claiming outbound notification with id '%d': %w", id, err)
+	}
+
+	if affected, err = result.RowsAffected(); err != nil {
+		return false, fmt.Errorf("error claiming outbound notification with id '%d': %w", id, err)
+	}
+
+	return affected == 1, nil
+}
+
+// UpdateOutboundNotificationStatus updates the delivery status of a notification in the notification outbox.
+func (p *SQLProvider) UpdateOutboundNotificationStatus(ctx context.Context, notification model.OutboundNotification) (err error) {
+	if _, err = p.db.ExecContext(ctx, p.sqlUpdateOutboundNotificationStatus,
+		notification.Status, notification.Attempts, notification.NextAttemptAt, notification.SentAt, notification.LastError,
+		notification.ID); err != nil {
+		return fmt.Errorf("error updating outbound notification with id '%d': %w", notification.ID, err)
+	}
+
+	return nil
+}
+
+// RetryOutboundNotification resets a failed notification in the notification outbox so it's attempted again at the
+// given time.
+func (p *SQLProvider) RetryOutboundNotification(ctx context.Context, id int, at time.Time) (err error) {
+	var (
+		result   sql.Result
+		affected int64
+	)
+
+	if result, err = p.db.ExecContext(ctx, p.sqlUpdateOutboundNotificationRetry,
+		model.OutboundNotificationStatusPending, at, id, model.OutboundNotificationStatusFailed); err != nil {
+		return fmt.Errorf("error retrying outbound notification with id '%d': %w", id, err)
+	}
+
+	if affected, err = result.RowsAffected(); err != nil {
+		return fmt.Errorf("error retrying outbound notification with id '%d': %w", id, err)
+	}
+
+	if affected == 0 {
+		return ErrNoOutboundNotification
+	}
+
+	return nil
+}
+
+// RetryOutboundNotifications resets all failed notifications in the notification outbox so they're attempted again
+// at the given time.
+func (p *SQLProvider) RetryOutboundNotifications(ctx context.Context, at time.Time) (count int64, err error) {
+	var result sql.Result
+
+	if result, err = p.db.ExecContext(ctx, p.sqlUpdateOutboundNotificationsRetry,
+		model.OutboundNotificationStatusPending, at, model.OutboundNotificationStatusFailed); err != nil {
+		return 0, fmt.Errorf("error retrying failed outbound notifications: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// PurgeOutboundNotifications deletes the notifications with the given status created before the given time from the
+// notification outbox.
+func (p *SQLProvider) PurgeOutboundNotifications(ctx context.Context, status string, before time.Time) (count int64, err error) {
+	var result sql.Result
+
+	if result, err = p.db.ExecContext(ctx, p.sqlDeleteOutboundNotifications, status, before); err != nil {
+		return 0, fmt.Errorf("error purging %s outbound notifications: %w", status, err)
+	}
+
+	return result.RowsAffected()
+}
+
+func (p *SQLProvider) decryptOutboundNotifications(ctx context.Context, notifications []model.OutboundNotification) ([]model.OutboundNotification, error) {
+	var err error
+
+	for i := range notifications {
+		if notifications[i].Data, err = p.decrypt(ctx, notifications[i].Data); err != nil {
+			return nil, fmt.Errorf("error decrypting the outbound notification data with id '%d': %w", notifications[i].ID, err)
+		}
+	}
+
+	return notifications, nil
+}
+
+// LoadAuthenticationHistory retrieve the number of previous successful authentications of a specific type for a user
+// which match the remote IP and user agent.
+func (p *SQLProvider) LoadAuthenticationHistory(ctx context.Context, username, authType string, ip model.NullIP, userAgent string) (history *model.AuthenticationHistory, err error) {
+	history = &model.AuthenticationHistory{}
+
+	if err = p.db.GetContext(ctx, history, p.sqlSelectAuthenticationHistory, ip, userAgent, username, authType); err != nil {
+		return nil, fmt.Errorf("error selecting '%s' authentication history for user '%s': %w", authType, username, err)
+	}
+
+	return history, nil
+}
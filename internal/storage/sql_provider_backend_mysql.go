@@ -20,7 +20,7 @@ type MySQLProvider struct {
 // NewMySQLProvider a MySQL provider.
 func NewMySQLProvider(config *schema.Configuration, caCertPool *x509.CertPool) (provider *MySQLProvider) {
 	provider = &MySQLProvider{
-		SQLProvider: NewSQLProvider(config, providerMySQL, providerMySQL, dsnMySQL(config.Storage.MySQL, caCertPool)),
+		SQLProvider: NewSQLProvider(config, caCertPool, providerMySQL, providerMySQL, dsnMySQL(config.Storage.MySQL, caCertPool)),
 	}
 
 	// All providers have differing SELECT existing table statements.
@@ -28,6 +28,7 @@ func NewMySQLProvider(config *schema.Configuration, caCertPool *x509.CertPool) (
 
 	// Specific alterations to this provider.
 	provider.sqlFmtRenameTable = queryFmtMySQLRenameTable
+	provider.sqlDeletePrune = sqlPruneQueries(queryFmtDeletePruneMySQL)
 
 	return provider
 }
@@ -23,7 +23,7 @@ type PostgreSQLProvider struct {
 // NewPostgreSQLProvider a PostgreSQL provider.
 func NewPostgreSQLProvider(config *schema.Configuration, caCertPool *x509.CertPool) (provider *PostgreSQLProvider) {
 	provider = &PostgreSQLProvider{
-		SQLProvider: NewSQLProvider(config, providerPostgres, "pgx", dsnPostgreSQL(config.Storage.PostgreSQL, caCertPool)),
+		SQLProvider: NewSQLProvider(config, caCertPool, providerPostgres, "pgx", dsnPostgreSQL(config.Storage.PostgreSQL, caCertPool)),
 	}
 
 	// All providers have differing SELECT existing table statements.
@@ -35,8 +35,10 @@ func NewPostgreSQLProvider(config *schema.Configuration, caCertPool *x509.CertPo
 	provider.sqlUpsertDuoDevice = fmt.Sprintf(queryFmtUpsertDuoDevicePostgreSQL, tableDuoDevices)
 	provider.sqlUpsertTOTPConfig = fmt.Sprintf(queryFmtUpsertTOTPConfigurationPostgreSQL, tableTOTPConfigurations)
 	provider.sqlUpsertPreferred2FAMethod = fmt.Sprintf(queryFmtUpsertPreferred2FAMethodPostgreSQL, tableUserPreferences)
+	provider.sqlUpsertUserPasswordChange = fmt.Sprintf(queryFmtUpsertUserPasswordChangePostgreSQL, tableUserPasswordChanges)
 	provider.sqlUpsertEncryptionValue = fmt.Sprintf(queryFmtUpsertEncryptionValuePostgreSQL, tableEncryption)
 	provider.sqlUpsertOAuth2BlacklistedJTI = fmt.Sprintf(queryFmtUpsertOAuth2BlacklistedJTIPostgreSQL, tableOAuth2BlacklistedJTI)
+	provider.sqlFmtUpdateSequence = queryFmtPostgreSQLUpdateSequence
 	provider.sqlInsertOAuth2ConsentPreConfiguration = fmt.Sprintf(queryFmtInsertOAuth2ConsentPreConfigurationPostgreSQL, tableOAuth2ConsentPreConfiguration)
 
 	// PostgreSQL requires rebinding of any query that contains a '?' placeholder to use the '$#' notation placeholders.
@@ -70,8 +72,25 @@ func NewPostgreSQLProvider(config *schema.Configuration, caCertPool *x509.CertPo
 	provider.sqlSelectDuoDevice = provider.db.Rebind(provider.sqlSelectDuoDevice)
 	provider.sqlDeleteDuoDevice = provider.db.Rebind(provider.sqlDeleteDuoDevice)
 
+	provider.sqlSelectUserPasswordChange = provider.db.Rebind(provider.sqlSelectUserPasswordChange)
+
+	provider.sqlInsertPasswordHistory = provider.db.Rebind(provider.sqlInsertPasswordHistory)
+	provider.sqlSelectPasswordHistory = provider.db.Rebind(provider.sqlSelectPasswordHistory)
+	provider.sqlDeletePasswordHistoryPrune = provider.db.Rebind(provider.sqlDeletePasswordHistoryPrune)
+
+	provider.sqlInsertOutboundNotification = provider.db.Rebind(provider.sqlInsertOutboundNotification)
+	provider.sqlSelectOutboundNotificationsDue = provider.db.Rebind(provider.sqlSelectOutboundNotificationsDue)
+	provider.sqlSelectOutboundNotifications = provider.db.Rebind(provider.sqlSelectOutboundNotifications)
+	provider.sqlSelectOutboundNotificationsByStatus = provider.db.Rebind(provider.sqlSelectOutboundNotificationsByStatus)
+	provider.sqlUpdateOutboundNotificationClaim = provider.db.Rebind(provider.sqlUpdateOutboundNotificationClaim)
+	provider.sqlUpdateOutboundNotificationStatus = provider.db.Rebind(provider.sqlUpdateOutboundNotificationStatus)
+	provider.sqlUpdateOutboundNotificationRetry = provider.db.Rebind(provider.sqlUpdateOutboundNotificationRetry)
+	provider.sqlUpdateOutboundNotificationsRetry = provider.db.Rebind(provider.sqlUpdateOutboundNotificationsRetry)
+	provider.sqlDeleteOutboundNotifications = provider.db.Rebind(provider.sqlDeleteOutboundNotifications)
+
 	provider.sqlInsertAuthenticationAttempt = provider.db.Rebind(provider.sqlInsertAuthenticationAttempt)
 	provider.sqlSelectAuthenticationAttemptsByUsername = provider.db.Rebind(provider.sqlSelectAuthenticationAttemptsByUsername)
+	provider.sqlSelectAuthenticationHistory = provider.db.Rebind(provider.sqlSelectAuthenticationHistory)
 
 	provider.sqlInsertMigration = provider.db.Rebind(provider.sqlInsertMigration)
 	provider.sqlSelectMigrations = provider.db.Rebind(provider.sqlSelectMigrations)
@@ -124,6 +143,11 @@ func NewPostgreSQLProvider(config *schema.Configuration, caCertPool *x509.CertPo
 
 	provider.sqlSelectOAuth2BlacklistedJTI = provider.db.Rebind(provider.sqlSelectOAuth2BlacklistedJTI)
 
+	for table := range provider.sqlDeletePrune {
+		provider.sqlSelectPruneCount[table] = provider.db.Rebind(provider.sqlSelectPruneCount[table])
+		provider.sqlDeletePrune[table] = provider.db.Rebind(provider.sqlDeletePrune[table])
+	}
+
 	provider.schema = config.Storage.PostgreSQL.Schema
 
 	return provider
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"crypto/x509"
 	"database/sql"
 	"encoding/base64"
 
@@ -16,9 +17,9 @@ type SQLiteProvider struct {
 }
 
 // NewSQLiteProvider constructs a SQLite provider.
-func NewSQLiteProvider(config *schema.Configuration) (provider *SQLiteProvider) {
+func NewSQLiteProvider(config *schema.Configuration, caCertPool *x509.CertPool) (provider *SQLiteProvider) {
 	provider = &SQLiteProvider{
-		SQLProvider: NewSQLProvider(config, providerSQLite, "sqlite3e", config.Storage.Local.Path),
+		SQLProvider: NewSQLProvider(config, caCertPool, providerSQLite, "sqlite3e", config.Storage.Local.Path),
 	}
 
 	// All providers have differing SELECT existing table statements.
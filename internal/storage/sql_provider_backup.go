@@ -0,0 +1,305 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SchemaBackup writes every row of each table to w as a backup encrypted with a key derived from the passphrase. The
+// rows are read in batches of up to limit rows within a single read-only transaction so the backup is a consistent
+// snapshot. Encrypted values are decrypted before they're written so the backup can be restored to a storage provider
+// with a different encryption key.
+func (p *SQLProvider) SchemaBackup(ctx context.Context, w io.Writer, passphrase string, limit int) (result SchemaBackupResult, err error) {
+	if limit <= 0 {
+		return result, fmt.Errorf("error backing up the schema: the batch size must be more than 0 but it's %d", limit)
+	}
+
+	manifest := BackupManifest{
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if manifest.SchemaVersion, err = p.SchemaVersion(ctx); err != nil {
+		return result, fmt.Errorf("error backing up the schema: error reading the schema version: %w", err)
+	}
+
+	for _, table := range copyTables() {
+		manifest.Tables = append(manifest.Tables, BackupManifestTable{Name: table.name, Columns: table.names()})
+	}
+
+	var backup *BackupWriter
+
+	if backup, err = NewBackupWriter(w, passphrase, manifest); err != nil {
+		return result, fmt.Errorf("error backing up the schema: %w", err)
+	}
+
+	var tx *sqlx.Tx
+
+	if tx, err = p.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}); err != nil {
+		return result, fmt.Errorf("error backing up the schema: error beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) {
+			p.log.WithError(rerr).Error("Error rolling back the schema backup transaction")
+		}
+	}()
+
+	for _, table := range copyTables() {
+		var count int64
+
+		if count, err = p.schemaBackupTable(ctx, tx, backup, table, limit); err != nil {
+			return result, fmt.Errorf("error backing up the schema: %w", err)
+		}
+
+		result.Tables = append(result.Tables, SchemaBackupTableResult{Table: table.name, Count: count})
+	}
+
+	if err = backup.Close(); err != nil {
+		return result, fmt.Errorf("error backing up the schema: %w", err)
+	}
+
+	return result, nil
+}
+
+func (p *SQLProvider) schemaBackupTable(ctx context.Context, conn SQLXConnection, backup *BackupWriter, table copyTable, limit int) (count int64, err error) {
+	query := p.db.Rebind(table.querySelect())
+
+	var (
+		cursor int64
+		rows   [][]any
+	)
+
+	for {
+		if rows, err = p.schemaCopySelect(ctx, conn, table, query, cursor, limit); err != nil {
+			return count, err
+		}
+
+		for _, row := range rows {
+			values := make([]any, len(row))
+
+			for i, column := range table.columns {
+				if column.kind == copyColumnEncrypted {
+					if row[i], err = p.decrypt(ctx, row[i].([]byte)); err != nil {
+						return count, fmt.Errorf("error decrypting column '%s' of the row with id '%d' in table '%s': %w", column.name, copyRowID(row), table.name, err)
+					}
+				}
+
+				values[i] = column.kind.marshal(row[i])
+			}
+
+			if err = backup.WriteRow(table.name, values); err != nil {
+				return count, err
+			}
+		}
+
+		count += int64(len(rows))
+
+		if len(rows) < limit {
+			break
+		}
+
+		cursor = copyRowID(rows[len(rows)-1])
+	}
+
+	return count, nil
+}
+
+// SchemaRestore inserts every row of the backup in batches of up to limit rows. The schema version of this provider
+// must match the schema version of the backup and this provider must not contain any data. The encrypted values are
+// encrypted with the current encryption key. The rows are inserted within a single transaction which is only committed
+// once the whole backup has been read and verified so a backup that can't be fully restored leaves no data behind.
+func (p *SQLProvider) SchemaRestore(ctx context.Context, backup *BackupReader, limit int) (result SchemaBackupResult, err error) {
+	if limit <= 0 {
+		return result, fmt.Errorf("error restoring the schema: the batch size must be more than 0 but it's %d", limit)
+	}
+
+	manifest := backup.Manifest()
+
+	var version int
+
+	if version, err = p.SchemaVersion(ctx); err != nil {
+		return result, fmt.Errorf("error restoring the schema: error reading the schema version: %w", err)
+	}
+
+	if version != manifest.SchemaVersion {
+		return result, fmt.Errorf("error restoring the schema: the backup schema version %d doesn't match the schema version %d", manifest.SchemaVersion, version)
+	}
+
+	tables, err := schemaRestoreTables(manifest)
+	if err != nil {
+		return result, fmt.Errorf("error restoring the schema: %w", err)
+	}
+
+	var tx *sqlx.Tx
+
+	if tx, err = p.db.BeginTxx(ctx, nil); err != nil {
+		return result, fmt.Errorf("error restoring the schema: error beginning transaction: %w", err)
+	}
+
+	if result, err = p.schemaRestore(ctx, tx, backup, tables, limit); err != nil {
+		if rerr := tx.Rollback(); rerr != nil {
+			return SchemaBackupResult{}, fmt.Errorf("%w: rollback error: %v", err, rerr)
+		}
+
+		return SchemaBackupResult{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return SchemaBackupResult{}, fmt.Errorf("error restoring the schema: error committing transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+func (p *SQLProvider) schemaRestore(ctx context.Context, conn SQLXConnection, backup *BackupReader, tables map[string]copyTable, limit int) (result SchemaBackupResult, err error) {
+	if err = p.schemaCopyCheckEmpty(ctx, conn); err != nil {
+		return result, fmt.Errorf("error restoring the schema: %w", err)
+	}
+
+	restore := &schemaRestoreState{tables: tables, counts: map[string]int64{}}
+
+	for {
+		var (
+			name string
+			row  []any
+		)
+
+		if name, row, err = backup.Next(); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return result, fmt.Errorf("error restoring the schema: %w", err)
+		}
+
+		if err = p.schemaRestoreRow(ctx, conn, restore, name, row, limit); err != nil {
+			return result, fmt.Errorf("error restoring the schema: %w", err)
+		}
+	}
+
+	if err = p.schemaRestoreFlush(ctx, conn, restore); err != nil {
+		return result, fmt.Errorf("error restoring the schema: %w", err)
+	}
+
+	for _, table := range copyTables() {
+		var count int64
+
+		if restore.counts[table.name] != 0 {
+			if err = p.schemaCopyUpdateSequence(ctx, conn, table); err != nil {
+				return result, fmt.Errorf("error restoring the schema: %w", err)
+			}
+		}
+
+		if count, err = p.schemaCopyCount(ctx, conn, table.name); err != nil {
+			return result, fmt.Errorf("error verifying the restored schema: %w", err)
+		}
+
+		if count != restore.counts[table.name] {
+			return result, fmt.Errorf("error verifying the restored schema: table '%s' has %d rows but the backup contains %d rows", table.name, count, restore.counts[table.name])
+		}
+
+		result.Tables = append(result.Tables, SchemaBackupTableResult{Table: table.name, Count: count})
+	}
+
+	return result, nil
+}
+
+type schemaRestoreState struct {
+	tables map[string]copyTable
+	counts map[string]int64
+
+	table copyTable
+	rows  [][]any
+}
+
+func (p *SQLProvider) schemaRestoreRow(ctx context.Context, conn SQLXConnection, restore *schemaRestoreState, name string, row []any, limit int) (err error) {
+	if name != restore.table.name || len(restore.rows) >= limit {
+		if err = p.schemaRestoreFlush(ctx, conn, restore); err != nil {
+			return err
+		}
+	}
+
+	table, ok := restore.tables[name]
+	if !ok {
+		return fmt.Errorf("the backup contains a row for the unknown table '%s'", name)
+	}
+
+	if len(row) != len(table.columns) {
+		return fmt.Errorf("the backup contains a row for table '%s' with %d values but it should have %d values", name, len(row), len(table.columns))
+	}
+
+	values := make([]any, len(row))
+
+	for i, column := range table.columns {
+		if values[i], err = column.kind.unmarshal(row[i]); err != nil {
+			return fmt.Errorf("the backup contains an invalid value for column '%s' of table '%s': %w", column.name, name, err)
+		}
+
+		if column.kind == copyColumnEncrypted {
+			if values[i], err = p.encrypt(ctx, values[i].([]byte)); err != nil {
+				return fmt.Errorf("error encrypting column '%s' of the row with id '%d' in table '%s': %w", column.name, copyRowID(values), name, err)
+			}
+		}
+	}
+
+	restore.table = table
+	restore.rows = append(restore.rows, values)
+
+	return nil
+}
+
+func (p *SQLProvider) schemaRestoreFlush(ctx context.Context, conn SQLXConnection, restore *schemaRestoreState) (err error) {
+	if len(restore.rows) == 0 {
+		return nil
+	}
+
+	if err = schemaCopyExecRows(ctx, conn, restore.table, p.db.Rebind(restore.table.queryInsert()), restore.rows); err != nil {
+		return err
+	}
+
+	restore.counts[restore.table.name] += int64(len(restore.rows))
+	restore.rows = restore.rows[:0]
+
+	return nil
+}
+
+// schemaRestoreTables returns the tables in the backup ensuring each one has the same columns as the table which is
+// restored.
+func schemaRestoreTables(manifest BackupManifest) (tables map[string]copyTable, err error) {
+	tables = map[string]copyTable{}
+
+	for _, table := range copyTables() {
+		tables[table.name] = table
+	}
+
+	restore := map[string]copyTable{}
+
+	for _, mtable := range manifest.Tables {
+		table, ok := tables[mtable.Name]
+		if !ok {
+			return nil, fmt.Errorf("the backup contains the unknown table '%s'", mtable.Name)
+		}
+
+		names := table.names()
+
+		if len(names) != len(mtable.Columns) {
+			return nil, fmt.Errorf("the backup contains table '%s' with columns which don't match the schema", mtable.Name)
+		}
+
+		for i, name := range names {
+			if mtable.Columns[i] != name {
+				return nil, fmt.Errorf("the backup contains table '%s' with columns which don't match the schema", mtable.Name)
+			}
+		}
+
+		restore[mtable.Name] = table
+	}
+
+	return restore, nil
+}
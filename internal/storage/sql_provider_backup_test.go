@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/model"
+)
+
+func TestSQLProviderSchemaBackupRestore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	source := newTestSQLiteProvider(t, filepath.Join(t.TempDir(), "source.sqlite3"), testEncryptionKeyOld)
+	require.NoError(t, source.StartupCheck())
+
+	subject := uuid.New()
+
+	require.NoError(t, source.SaveUserOpaqueIdentifier(ctx, model.UserOpaqueIdentifier{Service: "openid", Username: "john", Identifier: subject}))
+	require.NoError(t, source.SavePreferred2FAMethod(ctx, "john", "webauthn"))
+	require.NoError(t, source.SavePreferredDuoDevice(ctx, model.DuoDevice{Username: "john", Device: "ABC123", Method: "push"}))
+	require.NoError(t, source.SaveTOTPConfiguration(ctx, model.TOTPConfiguration{CreatedAt: now, Username: "john", Issuer: "Authelia", Algorithm: "SHA1", Digits: 6, Period: 30, Secret: []byte("secret")}))
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, source.AppendAuthenticationLog(ctx, model.AuthenticationAttempt{Time: now.Add(time.Duration(i) * time.Minute), Successful: true, Username: "john", Type: "1FA"}))
+	}
+
+	preconfig, err := source.SaveOAuth2ConsentPreConfiguration(ctx, model.OAuth2ConsentPreConfig{ClientID: "client", Subject: subject, CreatedAt: now, ExpiresAt: sql.NullTime{Time: now.Add(time.Hour), Valid: true}})
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+
+	result, err := source.SchemaBackup(ctx, buf, "passphrase", 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(8), result.Total())
+
+	target := newTestSQLiteProvider(t, filepath.Join(t.TempDir(), "target.sqlite3"), testEncryptionKeyNew)
+	require.NoError(t, target.StartupCheck())
+
+	backup, err := OpenBackup(bytes.NewReader(buf.Bytes()), "passphrase")
+	require.NoError(t, err)
+
+	result, err = target.SchemaRestore(ctx, backup, 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(8), result.Total())
+
+	config, err := target.LoadTOTPConfiguration(ctx, "john")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), config.Secret)
+
+	method, err := target.LoadPreferred2FAMethod(ctx, "john")
+	require.NoError(t, err)
+	assert.Equal(t, "webauthn", method)
+
+	device, err := target.LoadPreferredDuoDevice(ctx, "john")
+	require.NoError(t, err)
+	assert.Equal(t, "ABC123", device.Device)
+
+	preconfigs, err := target.LoadOAuth2ConsentPreConfigurations(ctx, "client", subject)
+	require.NoError(t, err)
+
+	require.True(t, preconfigs.Next())
+
+	restored, err := preconfigs.Get()
+	require.NoError(t, err)
+	require.NoError(t, preconfigs.Close())
+
+	assert.Equal(t, preconfig, restored.ID)
+	assert.Equal(t, now.Add(time.Hour).Unix(), restored.ExpiresAt.Time.Unix())
+
+	check, err := target.SchemaEncryptionCheckKey(ctx, false)
+	require.NoError(t, err)
+	assert.True(t, check.Success())
+
+	backup, err = OpenBackup(bytes.NewReader(buf.Bytes()), "passphrase")
+	require.NoError(t, err)
+
+	_, err = target.SchemaRestore(ctx, backup, 2)
+	assert.ErrorIs(t, err, ErrSchemaCopyTargetNotEmpty)
+}
+
+func TestSQLProviderSchemaRestoreShouldErrorOnVersionMismatch(t *testing.T) {
+	ctx := context.Background()
+
+	source := newTestSQLiteProvider(t, filepath.Join(t.TempDir(), "source.sqlite3"), testEncryptionKeyOld)
+	require.NoError(t, source.StartupCheck())
+
+	buf := &bytes.Buffer{}
+
+	_, err := source.SchemaBackup(ctx, buf, "passphrase", 10)
+	require.NoError(t, err)
+
+	target := newTestSQLiteProvider(t, filepath.Join(t.TempDir(), "target.sqlite3"), testEncryptionKeyOld)
+	require.NoError(t, target.SchemaMigrate(ctx, true, 1))
+
+	backup, err := OpenBackup(buf, "passphrase")
+	require.NoError(t, err)
+
+	_, err = target.SchemaRestore(ctx, backup, 10)
+	assert.EqualError(t, err, fmt.Sprintf("error restoring the schema: the backup schema version %d doesn't match the schema version 1", backup.Manifest().SchemaVersion))
+}
+
+func TestSQLProviderSchemaRestoreShouldRollbackOnError(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	source := newTestSQLiteProvider(t, filepath.Join(t.TempDir(), "source.sqlite3"), testEncryptionKeyOld)
+	require.NoError(t, source.StartupCheck())
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, source.AppendAuthenticationLog(ctx, model.AuthenticationAttempt{Time: now.Add(time.Duration(i) * time.Minute), Successful: true, Username: "john", Type: "1FA"}))
+	}
+
+	buf := &bytes.Buffer{}
+
+	_, err := source.SchemaBackup(ctx, buf, "passphrase", 10)
+	require.NoError(t, err)
+
+	backup, err := OpenBackup(buf, "passphrase")
+	require.NoError(t, err)
+
+	invalid := &bytes.Buffer{}
+
+	writer, err := NewBackupWriter(invalid, "passphrase", backup.Manifest())
+	require.NoError(t, err)
+
+	var (
+		name      string
+		row, last []any
+	)
+
+	for {
+		if name, row, err = backup.Next(); err != nil {
+			require.ErrorIs(t, err, io.EOF)
+
+			break
+		}
+
+		if name == tableAuthenticationLogs {
+			last = row
+		}
+
+		require.NoError(t, writer.WriteRow(name, row))
+	}
+
+	require.NotNil(t, last)
+	require.NoError(t, writer.WriteRow(tableAuthenticationLogs, last[1:]))
+	require.NoError(t, writer.Close())
+
+	target := newTestSQLiteProvider(t, filepath.Join(t.TempDir(), "target.sqlite3"), testEncryptionKeyOld)
+	require.NoError(t, target.StartupCheck())
+
+	backup, err = OpenBackup(invalid, "passphrase")
+	require.NoError(t, err)
+
+	_, err = target.SchemaRestore(ctx, backup, 1)
+	assert.ErrorContains(t, err, fmt.Sprintf("error restoring the schema: the backup contains a row for table '%s' with", tableAuthenticationLogs))
+
+	assert.NoError(t, target.schemaCopyCheckEmpty(ctx, target.db))
+}
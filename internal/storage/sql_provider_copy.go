@@ -0,0 +1,514 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SchemaCopy copies every row of each table to the target provider in batches of up to limit rows. The target must be
+// migrated to the same schema version as this provider and must not contain any data. The id of each row is preserved
+// and encrypted values are re-encrypted with the current encryption key of the target if it differs. The number of
+// rows in each table of the target is compared to this provider once every table is copied.
+func (p *SQLProvider) SchemaCopy(ctx context.Context, target Provider, limit int) (result SchemaCopyResult, err error) {
+	dst, ok := sqlProviderFromProvider(target)
+	if !ok {
+		return result, fmt.Errorf("error copying the schema: %w", ErrSchemaCopyTargetUnsupported)
+	}
+
+	if limit <= 0 {
+		return result, fmt.Errorf("error copying the schema: the batch size must be more than 0 but it's %d", limit)
+	}
+
+	if err = p.schemaCopyCheck(ctx, dst); err != nil {
+		return result, fmt.Errorf("error copying the schema: %w", err)
+	}
+
+	for _, table := range copyTables() {
+		var count int64
+
+		if count, err = p.schemaCopyTable(ctx, dst, table, limit, &result); err != nil {
+			return result, fmt.Errorf("error copying the schema: %w", err)
+		}
+
+		result.Tables = append(result.Tables, SchemaCopyTableResult{Table: table.name, Count: count})
+	}
+
+	for _, table := range copyTables() {
+		var source, destination int64
+
+		if source, err = p.schemaCopyCount(ctx, p.db, table.name); err != nil {
+			return result, fmt.Errorf("error verifying the copied schema: %w", err)
+		}
+
+		if destination, err = dst.schemaCopyCount(ctx, dst.db, table.name); err != nil {
+			return result, fmt.Errorf("error verifying the copied schema: %w", err)
+		}
+
+		if source != destination {
+			return result, fmt.Errorf("error verifying the copied schema: table '%s' has %d rows in the source but %d rows in the target", table.name, source, destination)
+		}
+	}
+
+	return result, nil
+}
+
+func (p *SQLProvider) schemaCopyCheck(ctx context.Context, dst *SQLProvider) (err error) {
+	var version, versionTarget int
+
+	if version, err = p.SchemaVersion(ctx); err != nil {
+		return fmt.Errorf("error reading the source schema version: %w", err)
+	}
+
+	if versionTarget, err = dst.SchemaVersion(ctx); err != nil {
+		return fmt.Errorf("error reading the target schema version: %w", err)
+	}
+
+	if version != versionTarget {
+		return fmt.Errorf("the source schema version %d doesn't match the target schema version %d", version, versionTarget)
+	}
+
+	return dst.schemaCopyCheckEmpty(ctx, dst.db)
+}
+
+// schemaCopyCheckEmpty ensures none of the tables which are copied contain any rows.
+func (p *SQLProvider) schemaCopyCheckEmpty(ctx context.Context, conn SQLXConnection) (err error) {
+	for _, table := range copyTables() {
+		var count int64
+
+		if count, err = p.schemaCopyCount(ctx, conn, table.name); err != nil {
+			return err
+		}
+
+		if count != 0 {
+			return fmt.Errorf("%w but table '%s' has %d rows", ErrSchemaCopyTargetNotEmpty, table.name, count)
+		}
+	}
+
+	return nil
+}
+
+func (p *SQLProvider) schemaCopyCount(ctx context.Context, conn SQLXConnection, table string) (count int64, err error) {
+	if err = sqlx.GetContext(ctx, conn, &count, fmt.Sprintf(queryFmtSelectRowCount, table)); err != nil {
+		return 0, fmt.Errorf("error counting the rows in table '%s': %w", table, err)
+	}
+
+	return count, nil
+}
+
+func (p *SQLProvider) schemaCopyTable(ctx context.Context, dst *SQLProvider, table copyTable, limit int, result *SchemaCopyResult) (count int64, err error) {
+	querySelect, queryInsert := p.db.Rebind(table.querySelect()), dst.db.Rebind(table.queryInsert())
+
+	var (
+		cursor int64
+		rows   [][]any
+	)
+
+	for {
+		if rows, err = p.schemaCopySelect(ctx, p.db, table, querySelect, cursor, limit); err != nil {
+			return count, err
+		}
+
+		if len(rows) == 0 {
+			break
+		}
+
+		if err = p.schemaCopyInsert(ctx, dst, table, queryInsert, rows, result); err != nil {
+			return count, err
+		}
+
+		count += int64(len(rows))
+		cursor = copyRowID(rows[len(rows)-1])
+
+		if len(rows) < limit {
+			break
+		}
+	}
+
+	if count != 0 {
+		if err = dst.schemaCopyUpdateSequence(ctx, dst.db, table); err != nil {
+			return count, err
+		}
+	}
+
+	return count, nil
+}
+
+func (p *SQLProvider) schemaCopyUpdateSequence(ctx context.Context, conn SQLXConnection, table copyTable) (err error) {
+	if p.sqlFmtUpdateSequence == "" {
+		return nil
+	}
+
+	if _, err = conn.ExecContext(ctx, fmt.Sprintf(p.sqlFmtUpdateSequence, table.name, table.name)); err != nil {
+		return fmt.Errorf("error updating the id sequence of table '%s': %w", table.name, err)
+	}
+
+	return nil
+}
+
+func (p *SQLProvider) schemaCopySelect(ctx context.Context, conn SQLXConnection, table copyTable, query string, cursor int64, limit int) (values [][]any, err error) {
+	rows, err := conn.QueryContext(ctx, query, cursor, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error selecting the rows to copy from table '%s': %w", table.name, err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.log.Errorf(logFmtErrClosingConn, err)
+		}
+	}()
+
+	for rows.Next() {
+		dest := make([]any, len(table.columns))
+
+		for i, column := range table.columns {
+			dest[i] = column.kind.dest()
+		}
+
+		if err = rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("error scanning the rows to copy from table '%s': %w", table.name, err)
+		}
+
+		row := make([]any, len(dest))
+
+		for i, column := range table.columns {
+			row[i] = column.kind.value(dest[i])
+		}
+
+		values = append(values, row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error selecting the rows to copy from table '%s': %w", table.name, err)
+	}
+
+	return values, nil
+}
+
+func (p *SQLProvider) schemaCopyInsert(ctx context.Context, dst *SQLProvider, table copyTable, query string, rows [][]any, result *SchemaCopyResult) (err error) {
+	var reencrypted int
+
+	for _, row := range rows {
+		for i, column := range table.columns {
+			if column.kind != copyColumnEncrypted {
+				continue
+			}
+
+			var changed bool
+
+			if row[i], changed, err = p.schemaCopyEncrypted(ctx, dst, row[i].([]byte)); err != nil {
+				return fmt.Errorf("error re-encrypting column '%s' of the row with id '%d' in table '%s': %w", column.name, copyRowID(row), table.name, err)
+			}
+
+			if changed {
+				reencrypted++
+			}
+		}
+	}
+
+	if err = dst.schemaCopyInsertRows(ctx, table, query, rows); err != nil {
+		return err
+	}
+
+	result.Reencrypted += reencrypted
+
+	return nil
+}
+
+// schemaCopyInsertRows inserts the rows into the table in a single transaction.
+func (p *SQLProvider) schemaCopyInsertRows(ctx context.Context, table copyTable, query string, rows [][]any) (err error) {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction to copy rows to table '%s': %w", table.name, err)
+	}
+
+	if err = schemaCopyExecRows(ctx, tx, table, query, rows); err != nil {
+		if rerr := tx.Rollback(); rerr != nil {
+			return fmt.Errorf("%w: rollback error: %v", err, rerr)
+		}
+
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing the rows copied to table '%s': %w", table.name, err)
+	}
+
+	return nil
+}
+
+// schemaCopyExecRows inserts the rows into the table using the connection.
+func schemaCopyExecRows(ctx context.Context, conn SQLXConnection, table copyTable, query string, rows [][]any) (err error) {
+	for _, row := range rows {
+		if _, err = conn.ExecContext(ctx, query, row...); err != nil {
+			return fmt.Errorf("error copying the row with id '%d' to table '%s': %w", copyRowID(row), table.name, err)
+		}
+	}
+
+	return nil
+}
+
+// schemaCopyEncrypted returns the value encrypted with the current encryption key of the target provider.
+func (p *SQLProvider) schemaCopyEncrypted(ctx context.Context, dst *SQLProvider, value []byte) (cipherText []byte, changed bool, err error) {
+	if dst.errKeys == nil && dst.keys.IsCurrent(value) {
+		return value, false, nil
+	}
+
+	var clearText []byte
+
+	if clearText, err = p.decrypt(ctx, value); err != nil {
+		return nil, false, err
+	}
+
+	if cipherText, err = dst.encrypt(ctx, clearText); err != nil {
+		return nil, false, err
+	}
+
+	return cipherText, true, nil
+}
+
+// copyRowID returns the id of a row which is always the first column.
+func copyRowID(row []any) int64 {
+	return row[0].(sql.NullInt64).Int64
+}
+
+func sqlProviderFromProvider(provider Provider) (p *SQLProvider, ok bool) {
+	switch v := provider.(type) {
+	case *SQLProvider:
+		return v, true
+	case *SQLiteProvider:
+		return &v.SQLProvider, true
+	case *MySQLProvider:
+		return &v.SQLProvider, true
+	case *PostgreSQLProvider:
+		return &v.SQLProvider, true
+	default:
+		return nil, false
+	}
+}
+
+type copyColumnKind int
+
+const (
+	copyColumnInteger copyColumnKind = iota
+	copyColumnString
+	copyColumnBoolean
+	copyColumnTime
+	copyColumnEncrypted
+)
+
+// dest returns a pointer to a value which every dialect can scan the column into.
+func (k copyColumnKind) dest() any {
+	switch k {
+	case copyColumnInteger:
+		return &sql.NullInt64{}
+	case copyColumnString:
+		return &sql.NullString{}
+	case copyColumnBoolean:
+		return &sql.NullBool{}
+	case copyColumnTime:
+		return &sql.NullTime{}
+	default:
+		return &[]byte{}
+	}
+}
+
+// value dereferences a value returned from dest.
+func (k copyColumnKind) value(dest any) any {
+	switch k {
+	case copyColumnInteger:
+		return *dest.(*sql.NullInt64)
+	case copyColumnString:
+		return *dest.(*sql.NullString)
+	case copyColumnBoolean:
+		return *dest.(*sql.NullBool)
+	case copyColumnTime:
+		return *dest.(*sql.NullTime)
+	default:
+		return *dest.(*[]byte)
+	}
+}
+
+// marshal converts a value returned from value to a value which is encoded as JSON the same way for every dialect.
+func (k copyColumnKind) marshal(value any) any {
+	switch k {
+	case copyColumnInteger:
+		if v := value.(sql.NullInt64); v.Valid {
+			return v.Int64
+		}
+	case copyColumnString:
+		if v := value.(sql.NullString); v.Valid {
+			return v.String
+		}
+	case copyColumnBoolean:
+		if v := value.(sql.NullBool); v.Valid {
+			return v.Bool
+		}
+	case copyColumnTime:
+		if v := value.(sql.NullTime); v.Valid {
+			return v.Time.Format(time.RFC3339Nano)
+		}
+	default:
+		return value
+	}
+
+	return nil
+}
+
+// unmarshal converts a value decoded from JSON with the numbers decoded as a json.Number to the value returned from
+// value.
+func (k copyColumnKind) unmarshal(value any) (result any, err error) {
+	if value == nil {
+		return k.value(k.dest()), nil
+	}
+
+	switch v := value.(type) {
+	case json.Number:
+		if k == copyColumnInteger {
+			var i int64
+
+			if i, err = v.Int64(); err != nil {
+				return nil, err
+			}
+
+			return sql.NullInt64{Int64: i, Valid: true}, nil
+		}
+	case bool:
+		if k == copyColumnBoolean {
+			return sql.NullBool{Bool: v, Valid: true}, nil
+		}
+	case string:
+		switch k {
+		case copyColumnString:
+			return sql.NullString{String: v, Valid: true}, nil
+		case copyColumnTime:
+			var t time.Time
+
+			if t, err = time.Parse(time.RFC3339Nano, v); err != nil {
+				return nil, err
+			}
+
+			return sql.NullTime{Time: t, Valid: true}, nil
+		case copyColumnEncrypted:
+			return base64.StdEncoding.DecodeString(v)
+		}
+	}
+
+	return nil, fmt.Errorf("value of type %T is not valid for the column", value)
+}
+
+type copyColumn struct {
+	name string
+	kind copyColumnKind
+}
+
+type copyTable struct {
+	name    string
+	columns []copyColumn
+}
+
+func (t copyTable) names() (names []string) {
+	names = make([]string, len(t.columns))
+
+	for i, column := range t.columns {
+		names[i] = column.name
+	}
+
+	return names
+}
+
+func (t copyTable) querySelect() string {
+	return fmt.Sprintf(queryFmtSelectCopyRows, strings.Join(t.names(), ", "), t.name)
+}
+
+func (t copyTable) queryInsert() string {
+	return fmt.Sprintf(queryFmtInsertCopyRow, t.name, strings.Join(t.names(), ", "), strings.TrimSuffix(strings.Repeat("?, ", len(t.columns)), ", "))
+}
+
+// copyTables returns the tables copied by SchemaCopy in an order which satisfies the foreign keys. The first column of
+// every table must be the id. The migrations and encryption tables are excluded as the target has its own.
+func copyTables() (tables []copyTable) {
+	tables = []copyTable{
+		{tableUserOpaqueIdentifier, []copyColumn{
+			{"id", copyColumnInteger}, {"service", copyColumnString}, {"sector_id", copyColumnString},
+			{"username", copyColumnString}, {"identifier", copyColumnString},
+		}},
+		{tableAuthenticationLogs, []copyColumn{
+			{"id", copyColumnInteger}, {"time", copyColumnTime}, {"successful", copyColumnBoolean},
+			{"banned", copyColumnBoolean}, {"username", copyColumnString}, {"auth_type", copyColumnString},
+			{"remote_ip", copyColumnString}, {"request_uri", copyColumnString}, {"request_method", copyColumnString},
+			{"user_agent", copyColumnString},
+		}},
+		{tableIdentityVerification, []copyColumn{
+			{"id", copyColumnInteger}, {"jti", copyColumnString}, {"iat", copyColumnTime},
+			{"issued_ip", copyColumnString}, {"exp", copyColumnTime}, {"username", copyColumnString},
+			{"action", copyColumnString}, {"consumed", copyColumnTime}, {"consumed_ip", copyColumnString},
+		}},
+		{tableTOTPConfigurations, []copyColumn{
+			{"id", copyColumnInteger}, {"created_at", copyColumnTime}, {"last_used_at", copyColumnTime},
+			{"username", copyColumnString}, {"issuer", copyColumnString}, {"algorithm", copyColumnString},
+			{"digits", copyColumnInteger}, {"period", copyColumnInteger}, {"secret", copyColumnEncrypted},
+		}},
+		{tableWebauthnDevices, []copyColumn{
+			{"id", copyColumnInteger}, {"created_at", copyColumnTime}, {"last_used_at", copyColumnTime},
+			{"rpid", copyColumnString}, {"username", copyColumnString}, {"description", copyColumnString},
+			{"kid", copyColumnString}, {"public_key", copyColumnEncrypted}, {"attestation_type", copyColumnString},
+			{"transport", copyColumnString}, {"aaguid", copyColumnString}, {"sign_count", copyColumnInteger},
+			{"clone_warning", copyColumnBoolean},
+		}},
+		{tableDuoDevices, []copyColumn{
+			{"id", copyColumnInteger}, {"username", copyColumnString}, {"device", copyColumnString},
+			{"method", copyColumnString},
+		}},
+		{tableUserPreferences, []copyColumn{
+			{"id", copyColumnInteger}, {"username", copyColumnString}, {"second_factor_method", copyColumnString},
+		}},
+		{tableUserPasswordChanges, []copyColumn{
+			{"id", copyColumnInteger}, {"username", copyColumnString}, {"changed_at", copyColumnTime},
+		}},
+		{tablePasswordHistory, []copyColumn{
+			{"id", copyColumnInteger}, {"created_at", copyColumnTime}, {"username", copyColumnString},
+			{"digest", copyColumnString},
+		}},
+		{tableNotificationOutbox, []copyColumn{
+			{"id", copyColumnInteger}, {"created_at", copyColumnTime}, {"next_attempt_at", copyColumnTime},
+			{"sent_at", copyColumnTime}, {"attempts", copyColumnInteger}, {"status", copyColumnString},
+			{"template", copyColumnString}, {"recipient", copyColumnString}, {"subject", copyColumnString},
+			{"data", copyColumnEncrypted}, {"last_error", copyColumnString}, {"locale", copyColumnString},
+		}},
+		{tableOAuth2ConsentPreConfiguration, []copyColumn{
+			{"id", copyColumnInteger}, {"client_id", copyColumnString}, {"subject", copyColumnString},
+			{"created_at", copyColumnTime}, {"expires_at", copyColumnTime}, {"revoked", copyColumnBoolean},
+			{"scopes", copyColumnString}, {"audience", copyColumnString},
+		}},
+		{tableOAuth2ConsentSession, []copyColumn{
+			{"id", copyColumnInteger}, {"challenge_id", copyColumnString}, {"client_id", copyColumnString},
+			{"subject", copyColumnString}, {"authorized", copyColumnBoolean}, {"granted", copyColumnBoolean},
+			{"requested_at", copyColumnTime}, {"responded_at", copyColumnTime}, {"form_data", copyColumnString},
+			{"requested_scopes", copyColumnString}, {"granted_scopes", copyColumnString},
+			{"requested_audience", copyColumnString}, {"granted_audience", copyColumnString},
+			{"preconfiguration", copyColumnInteger},
+		}},
+	}
+
+	for _, typeOAuth2Session := range sqlPruneOAuth2SessionTypes() {
+		tables = append(tables, copyTable{typeOAuth2Session.Table(), []copyColumn{
+			{"id", copyColumnInteger}, {"challenge_id", copyColumnString}, {"request_id", copyColumnString},
+			{"client_id", copyColumnString}, {"signature", copyColumnString}, {"subject", copyColumnString},
+			{"requested_at", copyColumnTime}, {"requested_scopes", copyColumnString},
+			{"granted_scopes", copyColumnString}, {"requested_audience", copyColumnString},
+			{"granted_audience", copyColumnString}, {"active", copyColumnBoolean}, {"revoked", copyColumnBoolean},
+			{"form_data", copyColumnString}, {"session_data", copyColumnEncrypted},
+		}})
+	}
+
+	return append(tables, copyTable{tableOAuth2BlacklistedJTI, []copyColumn{
+		{"id", copyColumnInteger}, {"signature", copyColumnString}, {"expires_at", copyColumnTime},
+	}})
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/model"
+)
+
+func TestCopyTablesShouldMatchSchema(t *testing.T) {
+	ctx := context.Background()
+
+	provider := newTestSQLiteProvider(t, filepath.Join(t.TempDir(), "db.sqlite3"), testEncryptionKeyOld)
+	require.NoError(t, provider.StartupCheck())
+
+	tables, err := provider.SchemaTables(ctx)
+	require.NoError(t, err)
+
+	expected := map[string]bool{}
+
+	for _, table := range tables {
+		switch table {
+		case tableMigrations, tableEncryption, "sqlite_sequence":
+			continue
+		default:
+			expected[table] = true
+		}
+	}
+
+	for _, table := range copyTables() {
+		assert.True(t, expected[table.name], table.name)
+
+		delete(expected, table.name)
+
+		var columns []string
+
+		require.NoError(t, provider.db.SelectContext(ctx, &columns, fmt.Sprintf("SELECT name FROM pragma_table_info('%s');", table.name)))
+
+		names := table.names()
+
+		assert.Equal(t, "id", names[0], table.name)
+
+		sort.Strings(columns)
+		sort.Strings(names)
+
+		assert.Equal(t, columns, names, table.name)
+	}
+
+	assert.Empty(t, expected)
+}
+
+func TestSQLProviderSchemaCopy(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	source := newTestSQLiteProvider(t, filepath.Join(t.TempDir(), "source.sqlite3"), testEncryptionKeyOld)
+	require.NoError(t, source.StartupCheck())
+
+	target := newTestSQLiteProvider(t, filepath.Join(t.TempDir(), "target.sqlite3"), testEncryptionKeyNew)
+	require.NoError(t, target.StartupCheck())
+
+	subject := uuid.New()
+
+	require.NoError(t, source.SaveUserOpaqueIdentifier(ctx, model.UserOpaqueIdentifier{Service: "openid", Username: "john", Identifier: subject}))
+	require.NoError(t, source.SavePreferred2FAMethod(ctx, "john", "totp"))
+	require.NoError(t, source.SaveUserPasswordChange(ctx, "john", now.Add(-time.Hour)))
+	require.NoError(t, source.SaveUserPasswordChange(ctx, "john", now))
+	require.NoError(t, source.SaveTOTPConfiguration(ctx, model.TOTPConfiguration{CreatedAt: now, Username: "john", Issuer: "Authelia", Algorithm: "SHA1", Digits: 6, Period: 30, Secret: []byte("secret")}))
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, source.AppendAuthenticationLog(ctx, model.AuthenticationAttempt{Time: now.Add(time.Duration(i) * time.Minute), Successful: i%2 == 0, Username: "john", Type: "1FA"}))
+	}
+
+	preconfig, err := source.SaveOAuth2ConsentPreConfiguration(ctx, model.OAuth2ConsentPreConfig{ClientID: "client", Subject: subject, CreatedAt: now})
+	require.NoError(t, err)
+
+	consent := model.OAuth2ConsentSession{
+		ChallengeID:      uuid.New(),
+		ClientID:         "client",
+		Subject:          uuid.NullUUID{UUID: subject, Valid: true},
+		RequestedAt:      now,
+		PreConfiguration: sql.NullInt64{Int64: preconfig, Valid: true},
+	}
+
+	require.NoError(t, source.SaveOAuth2ConsentSession(ctx, consent))
+
+	require.NoError(t, source.SaveOAuth2Session(ctx, OAuth2SessionTypeAccessToken, model.OAuth2Session{
+		ChallengeID: consent.ChallengeID,
+		RequestID:   uuid.NewString(),
+		ClientID:    "client",
+		Signature:   "signature",
+		RequestedAt: now,
+		Subject:     subject.String(),
+		Active:      true,
+		Session:     []byte(`{"value":true}`),
+	}))
+
+	result, err := source.SchemaCopy(ctx, target, 2)
+	require.NoError(t, err)
+
+	counts := map[string]int64{}
+
+	for _, table := range result.Tables {
+		counts[table.Table] = table.Count
+	}
+
+	assert.Equal(t, int64(5), counts[tableAuthenticationLogs])
+	assert.Equal(t, int64(1), counts[tableTOTPConfigurations])
+	assert.Equal(t, int64(1), counts[tableOAuth2AccessTokenSession])
+	assert.Equal(t, int64(0), counts[tableWebauthnDevices])
+	assert.Equal(t, int64(1), counts[tableUserPasswordChanges])
+	assert.Equal(t, int64(12), result.Total())
+	assert.Equal(t, 2, result.Reencrypted)
+
+	config, err := target.LoadTOTPConfiguration(ctx, "john")
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("secret"), config.Secret)
+
+	var createdAt time.Time
+
+	require.NoError(t, target.db.GetContext(ctx, &createdAt, fmt.Sprintf("SELECT created_at FROM %s WHERE id = ?;", tableTOTPConfigurations), config.ID))
+	assert.Equal(t, now.Unix(), createdAt.Unix())
+
+	method, err := target.LoadPreferred2FAMethod(ctx, "john")
+	require.NoError(t, err)
+	assert.Equal(t, "totp", method)
+
+	changedAt, err := target.LoadUserPasswordChange(ctx, "john")
+	require.NoError(t, err)
+	assert.Equal(t, now.Unix(), changedAt.Unix())
+
+	changedAt, err = target.LoadUserPasswordChange(ctx, "harry")
+	require.NoError(t, err)
+	assert.True(t, changedAt.IsZero())
+
+	session, err := target.LoadOAuth2Session(ctx, OAuth2SessionTypeAccessToken, "signature")
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"value":true}`), session.Session)
+
+	copied, err := target.LoadOAuth2ConsentSessionByChallengeID(ctx, consent.ChallengeID)
+	require.NoError(t, err)
+	assert.Equal(t, sql.NullInt64{Int64: preconfig, Valid: true}, copied.PreConfiguration)
+
+	check, err := target.SchemaEncryptionCheckKey(ctx, false)
+	require.NoError(t, err)
+	assert.True(t, check.Success())
+
+	_, err = source.SchemaCopy(ctx, target, 2)
+	assert.ErrorIs(t, err, ErrSchemaCopyTargetNotEmpty)
+}
+
+func TestSQLProviderSchemaCopyShouldErrorOnVersionMismatch(t *testing.T) {
+	ctx := context.Background()
+
+	source := newTestSQLiteProvider(t, filepath.Join(t.TempDir(), "source.sqlite3"), testEncryptionKeyOld)
+	require.NoError(t, source.StartupCheck())
+
+	target := newTestSQLiteProvider(t, filepath.Join(t.TempDir(), "target.sqlite3"), testEncryptionKeyOld)
+	require.NoError(t, target.SchemaMigrate(ctx, true, 1))
+
+	latest, err := source.SchemaLatestVersion()
+	require.NoError(t, err)
+
+	_, err = source.SchemaCopy(ctx, target, 10)
+	assert.EqualError(t, err, fmt.Sprintf("error copying the schema: the source schema version %d doesn't match the target schema version 1", latest))
+}
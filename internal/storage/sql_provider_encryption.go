@@ -1,25 +1,25 @@
 package storage
 
 import (
-	"bytes"
 	"context"
-	"crypto/sha256"
 	"database/sql"
 	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
-
-	"github.com/authelia/authelia/v4/internal/utils"
 )
 
 // SchemaEncryptionChangeKey uses the currently configured key to decrypt values in the database and the key provided
 // by this command to encrypt the values again and update them using a transaction.
 func (p *SQLProvider) SchemaEncryptionChangeKey(ctx context.Context, key string) (err error) {
-	skey := sha256.Sum256([]byte(key))
+	if p.keys.KeyProvider() != nil {
+		return fmt.Errorf("error changing the storage encryption key: %w", ErrEncryptionKeyProviderChangeKey)
+	}
+
+	skey := NewEncryptionKey(key)
 
-	if bytes.Equal(skey[:], p.key[:]) {
+	if skey.ID == p.keys.Current().ID {
 		return fmt.Errorf("error changing the storage encryption key: the old key and the new key are the same")
 	}
 
@@ -35,6 +35,7 @@ func (p *SQLProvider) SchemaEncryptionChangeKey(ctx context.Context, key string)
 	encChangeFuncs := []EncryptionChangeKeyFunc{
 		schemaEncryptionChangeKeyTOTP,
 		schemaEncryptionChangeKeyWebauthn,
+		schemaEncryptionChangeKeyNotificationOutbox,
 	}
 
 	for i := 0; true; i++ {
@@ -57,7 +58,7 @@ func (p *SQLProvider) SchemaEncryptionChangeKey(ctx context.Context, key string)
 		}
 	}
 
-	if err = p.setNewEncryptionCheckValue(ctx, tx, &skey); err != nil {
+	if err = p.setNewEncryptionCheckValue(ctx, tx, p.keys.WithCurrent(skey)); err != nil {
 		if rerr := tx.Rollback(); rerr != nil {
 			return fmt.Errorf("rollback error %v: rollback due to error: %w", rerr, err)
 		}
@@ -70,6 +71,10 @@ func (p *SQLProvider) SchemaEncryptionChangeKey(ctx context.Context, key string)
 
 // SchemaEncryptionCheckKey checks the encryption key configured is valid for the database.
 func (p *SQLProvider) SchemaEncryptionCheckKey(ctx context.Context, verbose bool) (result EncryptionValidationResult, err error) {
+	if p.errKeys != nil {
+		return result, p.errKeys
+	}
+
 	version, err := p.SchemaVersion(ctx)
 	if err != nil {
 		return result, err
@@ -80,7 +85,9 @@ func (p *SQLProvider) SchemaEncryptionCheckKey(ctx context.Context, verbose bool
 	}
 
 	result = EncryptionValidationResult{
-		Tables: map[string]EncryptionValidationTableResult{},
+		Current: p.keys.CurrentID(),
+		Keys:    p.keys.IDs(),
+		Tables:  map[string]EncryptionValidationTableResult{},
 	}
 
 	if _, err = p.getEncryptionValue(ctx, encryptionNameCheck); err != nil {
@@ -91,6 +98,7 @@ func (p *SQLProvider) SchemaEncryptionCheckKey(ctx context.Context, verbose bool
 		encCheckFuncs := []EncryptionCheckKeyFunc{
 			schemaEncryptionCheckKeyTOTP,
 			schemaEncryptionCheckKeyWebauthn,
+			schemaEncryptionCheckKeyNotificationOutbox,
 		}
 
 		for i := 0; true; i++ {
@@ -113,7 +121,140 @@ func (p *SQLProvider) SchemaEncryptionCheckKey(ctx context.Context, verbose bool
 	return result, nil
 }
 
-func schemaEncryptionChangeKeyTOTP(ctx context.Context, provider *SQLProvider, tx *sqlx.Tx, key [32]byte) (err error) {
+// SchemaEncryptionRekey re-encrypts up to the limit of values in each table which are not encrypted with the current
+// encryption key. Each table is re-encrypted in its own transaction so the tables are only locked for the duration
+// of a single batch. The state is used to resume the process on the next call and indicates when it's complete.
+func (p *SQLProvider) SchemaEncryptionRekey(ctx context.Context, state *EncryptionRekeyState, limit int) (err error) {
+	if state.cursors == nil {
+		state.cursors = map[string]int{}
+		state.complete = map[string]bool{}
+	}
+
+	complete := true
+
+	for _, column := range encColumns() {
+		if state.complete[column.table] {
+			continue
+		}
+
+		if err = p.schemaEncryptionRekeyColumn(ctx, column, state, limit); err != nil {
+			return err
+		}
+
+		if !state.complete[column.table] {
+			complete = false
+		}
+	}
+
+	state.done = complete
+
+	return nil
+}
+
+func (p *SQLProvider) schemaEncryptionRekeyColumn(ctx context.Context, column encColumn, state *EncryptionRekeyState, limit int) (err error) {
+	var values []encValue
+
+	query := p.db.Rebind(fmt.Sprintf(queryFmtSelectEncryptedValuesNotCurrent, column.column, column.table, column.column, encryptionHeaderLength))
+
+	if err = p.db.SelectContext(ctx, &values, query, state.cursors[column.table], p.keys.header(), limit); err != nil {
+		return fmt.Errorf("error selecting values to re-encrypt from table '%s': %w", column.table, err)
+	}
+
+	if len(values) < limit {
+		state.complete[column.table] = true
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	state.cursors[column.table] = values[len(values)-1].ID
+
+	tx, err := p.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("error beginning transaction to re-encrypt values in table '%s': %w", column.table, err)
+	}
+
+	query = p.db.Rebind(fmt.Sprintf(queryFmtUpdateEncryptedValue, column.table, column.column, column.column))
+
+	var (
+		clearText, cipherText []byte
+		rekeyed               int
+	)
+
+	for _, value := range values {
+		if clearText, err = p.decrypt(ctx, value.Value); err != nil {
+			state.Failed++
+
+			p.log.WithError(err).Warnf("Error occurred decrypting the value with id '%d' in table '%s' so it can't be re-encrypted", value.ID, column.table)
+
+			continue
+		}
+
+		if cipherText, err = p.encrypt(ctx, clearText); err != nil {
+			return p.schemaEncryptionRekeyRollback(tx, fmt.Errorf("error encrypting value with id '%d' in table '%s': %w", value.ID, column.table, err))
+		}
+
+		if _, err = tx.ExecContext(ctx, query, cipherText, value.ID, value.Value); err != nil {
+			return p.schemaEncryptionRekeyRollback(tx, fmt.Errorf("error updating value with id '%d' in table '%s': %w", value.ID, column.table, err))
+		}
+
+		rekeyed++
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing the re-encrypted values in table '%s': %w", column.table, err)
+	}
+
+	state.Rekeyed += rekeyed
+
+	return nil
+}
+
+func (p *SQLProvider) schemaEncryptionRekeyRollback(tx *sqlx.Tx, err error) error {
+	if rerr := tx.Rollback(); rerr != nil {
+		return fmt.Errorf("rollback error %v: rollback due to error: %w", rerr, err)
+	}
+
+	return fmt.Errorf("rollback due to error: %w", err)
+}
+
+// schemaEncryptionRekeyCheckValue ensures the encryption check value is encrypted with the current encryption key.
+func (p *SQLProvider) schemaEncryptionRekeyCheckValue(ctx context.Context) (err error) {
+	var value []byte
+
+	if err = p.db.GetContext(ctx, &value, p.sqlSelectEncryptionValue, encryptionNameCheck); err != nil {
+		return err
+	}
+
+	if p.keys.IsCurrent(value) {
+		return nil
+	}
+
+	return p.setNewEncryptionCheckValue(ctx, p.db, p.keys)
+}
+
+func encColumns() (columns []encColumn) {
+	columns = []encColumn{
+		{tableTOTPConfigurations, "secret"},
+		{tableWebauthnDevices, "public_key"},
+		{tableNotificationOutbox, "data"},
+	}
+
+	for i := 0; true; i++ {
+		typeOAuth2Session := OAuth2SessionType(i)
+
+		if typeOAuth2Session.Table() == "" {
+			break
+		}
+
+		columns = append(columns, encColumn{typeOAuth2Session.Table(), "session_data"})
+	}
+
+	return columns
+}
+
+func schemaEncryptionChangeKeyTOTP(ctx context.Context, provider *SQLProvider, tx *sqlx.Tx, key EncryptionKey) (err error) {
 	var count int
 
 	if err = tx.GetContext(ctx, &count, fmt.Sprintf(queryFmtSelectRowCount, tableTOTPConfigurations)); err != nil {
@@ -137,11 +278,11 @@ func schemaEncryptionChangeKeyTOTP(ctx context.Context, provider *SQLProvider, t
 	query := provider.db.Rebind(fmt.Sprintf(queryFmtUpdateTOTPConfigurationSecret, tableTOTPConfigurations))
 
 	for _, c := range configs {
-		if c.Secret, err = provider.decrypt(c.Secret); err != nil {
+		if c.Secret, err = provider.decrypt(ctx, c.Secret); err != nil {
 			return fmt.Errorf("error decrypting TOTP configuration secret with id '%d': %w", c.ID, err)
 		}
 
-		if c.Secret, err = utils.Encrypt(c.Secret, &key); err != nil {
+		if c.Secret, err = key.Encrypt(c.Secret); err != nil {
 			return fmt.Errorf("error encrypting TOTP configuration secret with id '%d': %w", c.ID, err)
 		}
 
@@ -153,7 +294,7 @@ func schemaEncryptionChangeKeyTOTP(ctx context.Context, provider *SQLProvider, t
 	return nil
 }
 
-func schemaEncryptionChangeKeyWebauthn(ctx context.Context, provider *SQLProvider, tx *sqlx.Tx, key [32]byte) (err error) {
+func schemaEncryptionChangeKeyWebauthn(ctx context.Context, provider *SQLProvider, tx *sqlx.Tx, key EncryptionKey) (err error) {
 	var count int
 
 	if err = tx.GetContext(ctx, &count, fmt.Sprintf(queryFmtSelectRowCount, tableWebauthnDevices)); err != nil {
@@ -177,11 +318,11 @@ func schemaEncryptionChangeKeyWebauthn(ctx context.Context, provider *SQLProvide
 	query := provider.db.Rebind(fmt.Sprintf(queryFmtUpdateWebauthnDevicePublicKey, tableWebauthnDevices))
 
 	for _, d := range devices {
-		if d.PublicKey, err = provider.decrypt(d.PublicKey); err != nil {
+		if d.PublicKey, err = provider.decrypt(ctx, d.PublicKey); err != nil {
 			return fmt.Errorf("error decrypting Webauthn device public key with id '%d': %w", d.ID, err)
 		}
 
-		if d.PublicKey, err = utils.Encrypt(d.PublicKey, &key); err != nil {
+		if d.PublicKey, err = key.Encrypt(d.PublicKey); err != nil {
 			return fmt.Errorf("error encrypting Webauthn device public key with id '%d': %w", d.ID, err)
 		}
 
@@ -193,8 +334,48 @@ func schemaEncryptionChangeKeyWebauthn(ctx context.Context, provider *SQLProvide
 	return nil
 }
 
+func schemaEncryptionChangeKeyNotificationOutbox(ctx context.Context, provider *SQLProvider, tx *sqlx.Tx, key EncryptionKey) (err error) {
+	var count int
+
+	if err = tx.GetContext(ctx, &count, fmt.Sprintf(queryFmtSelectRowCount, tableNotificationOutbox)); err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	notifications := make([]encOutboundNotification, 0, count)
+
+	if err = tx.SelectContext(ctx, &notifications, fmt.Sprintf(queryFmtSelectOutboundNotificationsEncryptedData, tableNotificationOutbox)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		return fmt.Errorf("error selecting outbound notifications: %w", err)
+	}
+
+	query := provider.db.Rebind(fmt.Sprintf(queryFmtUpdateOutboundNotificationData, tableNotificationOutbox))
+
+	for _, n := range notifications {
+		if n.Data, err = provider.decrypt(ctx, n.Data); err != nil {
+			return fmt.Errorf("error decrypting outbound notification data with id '%d': %w", n.ID, err)
+		}
+
+		if n.Data, err = key.Encrypt(n.Data); err != nil {
+			return fmt.Errorf("error encrypting outbound notification data with id '%d': %w", n.ID, err)
+		}
+
+		if _, err = tx.ExecContext(ctx, query, n.Data, n.ID); err != nil {
+			return fmt.Errorf("error updating outbound notification data with id '%d': %w", n.ID, err)
+		}
+	}
+
+	return nil
+}
+
 func schemaEncryptionChangeKeyOpenIDConnect(typeOAuth2Session OAuth2SessionType) EncryptionChangeKeyFunc {
-	return func(ctx context.Context, provider *SQLProvider, tx *sqlx.Tx, key [32]byte) (err error) {
+	return func(ctx context.Context, provider *SQLProvider, tx *sqlx.Tx, key EncryptionKey) (err error) {
 		var count int
 
 		if err = tx.GetContext(ctx, &count, fmt.Sprintf(queryFmtSelectRowCount, typeOAuth2Session.Table())); err != nil {
@@ -214,11 +395,11 @@ func schemaEncryptionChangeKeyOpenIDConnect(typeOAuth2Session OAuth2SessionType)
 		query := provider.db.Rebind(fmt.Sprintf(queryFmtUpdateOAuth2ConsentSessionSessionData, typeOAuth2Session.Table()))
 
 		for _, s := range sessions {
-			if s.Session, err = provider.decrypt(s.Session); err != nil {
+			if s.Session, err = provider.decrypt(ctx, s.Session); err != nil {
 				return fmt.Errorf("error decrypting oauth2 %s session data with id '%d': %w", typeOAuth2Session.String(), s.ID, err)
 			}
 
-			if s.Session, err = utils.Encrypt(s.Session, &key); err != nil {
+			if s.Session, err = key.Encrypt(s.Session); err != nil {
 				return fmt.Errorf("error encrypting oauth2 %s session data with id '%d': %w", typeOAuth2Session.String(), s.ID, err)
 			}
 
@@ -252,9 +433,7 @@ func schemaEncryptionCheckKeyTOTP(ctx context.Context, provider *SQLProvider) (t
 			return tableTOTPConfigurations, EncryptionValidationTableResult{Error: fmt.Errorf("error scanning TOTP configuration to struct: %w", err)}
 		}
 
-		if _, err = provider.decrypt(config.Secret); err != nil {
-			result.Invalid++
-		}
+		result.check(ctx, provider.keys, config.Secret)
 	}
 
 	_ = rows.Close()
@@ -283,9 +462,7 @@ func schemaEncryptionCheckKeyWebauthn(ctx context.Context, provider *SQLProvider
 			return tableWebauthnDevices, EncryptionValidationTableResult{Error: fmt.Errorf("error scanning Webauthn device to struct: %w", err)}
 		}
 
-		if _, err = provider.decrypt(device.PublicKey); err != nil {
-			result.Invalid++
-		}
+		result.check(ctx, provider.keys, device.PublicKey)
 	}
 
 	_ = rows.Close()
@@ -293,6 +470,35 @@ func schemaEncryptionCheckKeyWebauthn(ctx context.Context, provider *SQLProvider
 	return tableWebauthnDevices, result
 }
 
+func schemaEncryptionCheckKeyNotificationOutbox(ctx context.Context, provider *SQLProvider) (table string, result EncryptionValidationTableResult) {
+	var (
+		rows *sqlx.Rows
+		err  error
+	)
+
+	if rows, err = provider.db.QueryxContext(ctx, fmt.Sprintf(queryFmtSelectOutboundNotificationsEncryptedData, tableNotificationOutbox)); err != nil {
+		return tableNotificationOutbox, EncryptionValidationTableResult{Error: fmt.Errorf("error selecting outbound notifications: %w", err)}
+	}
+
+	var notification encOutboundNotification
+
+	for rows.Next() {
+		result.Total++
+
+		if err = rows.StructScan(&notification); err != nil {
+			_ = rows.Close()
+
+			return tableNotificationOutbox, EncryptionValidationTableResult{Error: fmt.Errorf("error scanning outbound notification to struct: %w", err)}
+		}
+
+		result.check(ctx, provider.keys, notification.Data)
+	}
+
+	_ = rows.Close()
+
+	return tableNotificationOutbox, result
+}
+
 func schemaEncryptionCheckKeyOpenIDConnect(typeOAuth2Session OAuth2SessionType) EncryptionCheckKeyFunc {
 	return func(ctx context.Context, provider *SQLProvider) (table string, result EncryptionValidationTableResult) {
 		var (
@@ -315,9 +521,7 @@ func schemaEncryptionCheckKeyOpenIDConnect(typeOAuth2Session OAuth2SessionType)
 				return typeOAuth2Session.Table(), EncryptionValidationTableResult{Error: fmt.Errorf("error scanning oauth2 %s session to struct: %w", typeOAuth2Session.String(), err)}
 			}
 
-			if _, err = provider.decrypt(session.Session); err != nil {
-				result.Invalid++
-			}
+			result.check(ctx, provider.keys, session.Session)
 		}
 
 		_ = rows.Close()
@@ -326,12 +530,22 @@ func schemaEncryptionCheckKeyOpenIDConnect(typeOAuth2Session OAuth2SessionType)
 	}
 }
 
-func (p *SQLProvider) encrypt(clearText []byte) (cipherText []byte, err error) {
-	return utils.Encrypt(clearText, &p.key)
+func (p *SQLProvider) encrypt(ctx context.Context, clearText []byte) (cipherText []byte, err error) {
+	if p.errKeys != nil {
+		return nil, p.errKeys
+	}
+
+	return p.keys.Encrypt(ctx, clearText)
 }
 
-func (p *SQLProvider) decrypt(cipherText []byte) (clearText []byte, err error) {
-	return utils.Decrypt(cipherText, &p.key)
+func (p *SQLProvider) decrypt(ctx context.Context, cipherText []byte) (clearText []byte, err error) {
+	if p.errKeys != nil {
+		return nil, p.errKeys
+	}
+
+	clearText, _, _, err = p.keys.Decrypt(ctx, cipherText)
+
+	return clearText, err
 }
 
 func (p *SQLProvider) getEncryptionValue(ctx context.Context, name string) (value []byte, err error) {
@@ -342,16 +556,16 @@ func (p *SQLProvider) getEncryptionValue(ctx context.Context, name string) (valu
 		return nil, err
 	}
 
-	return p.decrypt(encryptedValue)
+	return p.decrypt(ctx, encryptedValue)
 }
 
-func (p *SQLProvider) setNewEncryptionCheckValue(ctx context.Context, conn SQLXConnection, key *[32]byte) (err error) {
+func (p *SQLProvider) setNewEncryptionCheckValue(ctx context.Context, conn SQLXConnection, keys *EncryptionKeyring) (err error) {
 	valueClearText, err := uuid.NewRandom()
 	if err != nil {
 		return err
 	}
 
-	value, err := utils.Encrypt([]byte(valueClearText.String()), key)
+	value, err := keys.Encrypt(ctx, []byte(valueClearText.String()))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/model"
+	"github.com/authelia/authelia/v4/internal/utils"
+)
+
+const (
+	testEncryptionKeyOld = "an_old_encryption_key_which_is_long"
+	testEncryptionKeyNew = "a_new_encryption_key_which_is_long"
+)
+
+func newTestSQLiteProvider(t *testing.T, path, key string, decryption ...string) *SQLiteProvider {
+	provider := NewSQLiteProvider(&schema.Configuration{
+		Storage: schema.StorageConfiguration{
+			Local:         &schema.LocalStorageConfiguration{Path: path},
+			EncryptionKey: key,
+			Encryption: schema.StorageEncryptionConfiguration{
+				DecryptionKeys: decryption,
+			},
+		},
+	}, nil)
+
+	t.Cleanup(func() {
+		_ = provider.Close()
+	})
+
+	return provider
+}
+
+func TestSQLProviderSchemaEncryptionRekey(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "db.sqlite3")
+
+	provider := newTestSQLiteProvider(t, path, testEncryptionKeyOld)
+	require.NoError(t, provider.StartupCheck())
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, provider.SaveTOTPConfiguration(ctx, model.TOTPConfiguration{
+			CreatedAt: time.Now(),
+			Username:  fmt.Sprintf("user%d", i),
+			Issuer:    "Authelia",
+			Algorithm: "SHA1",
+			Digits:    6,
+			Period:    30,
+			Secret:    []byte(fmt.Sprintf("secret%d", i)),
+		}))
+	}
+
+	key := provider.keys.Current()
+
+	legacy, err := utils.Encrypt([]byte("secret0"), &key.key)
+	require.NoError(t, err)
+
+	_, err = provider.db.ExecContext(ctx, "UPDATE totp_configurations SET secret = ? WHERE username = ?", legacy, "user0")
+	require.NoError(t, err)
+
+	require.NoError(t, provider.Close())
+
+	provider = newTestSQLiteProvider(t, path, testEncryptionKeyNew)
+	assert.ErrorIs(t, provider.StartupCheck(), ErrSchemaEncryptionInvalidKey)
+
+	provider = newTestSQLiteProvider(t, path, testEncryptionKeyNew, testEncryptionKeyOld)
+	require.NoError(t, provider.StartupCheck())
+
+	current, old := NewEncryptionKey(testEncryptionKeyNew).ID, NewEncryptionKey(testEncryptionKeyOld).ID
+
+	result, err := provider.SchemaEncryptionCheckKey(ctx, true)
+	require.NoError(t, err)
+
+	assert.True(t, result.Success())
+	assert.Equal(t, current, result.Current)
+	assert.Equal(t, []EncryptionKeyID{current, old}, result.Keys)
+
+	table := result.Tables[tableTOTPConfigurations]
+
+	assert.Equal(t, 5, table.Total)
+	assert.Equal(t, 1, table.Legacy)
+	assert.Equal(t, map[EncryptionKeyID]int{old: 4}, table.Keys)
+
+	rows, percent := table.Progress(current)
+	assert.Equal(t, 0, rows)
+	assert.Equal(t, float64(0), percent)
+
+	state := &EncryptionRekeyState{}
+
+	require.NoError(t, provider.SchemaEncryptionRekey(ctx, state, 2))
+	assert.False(t, state.Complete())
+	assert.Equal(t, 2, state.Rekeyed)
+
+	for i := 0; i < 3 && !state.Complete(); i++ {
+		require.NoError(t, provider.SchemaEncryptionRekey(ctx, state, 2))
+	}
+
+	assert.True(t, state.Complete())
+	assert.Equal(t, 5, state.Rekeyed)
+	assert.Equal(t, 0, state.Failed)
+
+	result, err = provider.SchemaEncryptionCheckKey(ctx, true)
+	require.NoError(t, err)
+
+	table = result.Tables[tableTOTPConfigurations]
+
+	assert.Equal(t, 0, table.Legacy)
+	assert.Equal(t, map[EncryptionKeyID]int{current: 5}, table.Keys)
+
+	rows, percent = table.Progress(current)
+	assert.Equal(t, 5, rows)
+	assert.Equal(t, float64(100), percent)
+
+	config, err := provider.LoadTOTPConfiguration(ctx, "user0")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret0"), config.Secret)
+
+	require.NoError(t, provider.Close())
+
+	provider = newTestSQLiteProvider(t, path, testEncryptionKeyNew)
+	require.NoError(t, provider.StartupCheck())
+
+	config, err = provider.LoadTOTPConfiguration(ctx, "user4")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret4"), config.Secret)
+}
+
+func TestSQLProviderSchemaEncryptionChangeKey(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "db.sqlite3")
+
+	provider := newTestSQLiteProvider(t, path, testEncryptionKeyOld)
+	require.NoError(t, provider.StartupCheck())
+
+	require.NoError(t, provider.SaveTOTPConfiguration(ctx, model.TOTPConfiguration{
+		CreatedAt: time.Now(),
+		Username:  "john",
+		Issuer:    "Authelia",
+		Algorithm: "SHA1",
+		Digits:    6,
+		Period:    30,
+		Secret:    []byte("secret"),
+	}))
+
+	assert.EqualError(t, provider.SchemaEncryptionChangeKey(ctx, testEncryptionKeyOld), "error changing the storage encryption key: the old key and the new key are the same")
+	require.NoError(t, provider.SchemaEncryptionChangeKey(ctx, testEncryptionKeyNew))
+	require.NoError(t, provider.Close())
+
+	provider = newTestSQLiteProvider(t, path, testEncryptionKeyNew)
+	require.NoError(t, provider.StartupCheck())
+
+	result, err := provider.SchemaEncryptionCheckKey(ctx, true)
+	require.NoError(t, err)
+
+	assert.True(t, result.Success())
+	assert.Equal(t, map[EncryptionKeyID]int{NewEncryptionKey(testEncryptionKeyNew).ID: 1}, result.Tables[tableTOTPConfigurations].Keys)
+}
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Prune deletes up to limit rows from each table which the PrunePolicy no longer retains. The result is complete when
+// there are no rows left to prune.
+func (p *SQLProvider) Prune(ctx context.Context, policy PrunePolicy, limit int) (result PruneResult, err error) {
+	result.Complete = true
+
+	for _, table := range sqlPruneTables() {
+		args, ok := policy.args(table)
+		if !ok {
+			continue
+		}
+
+		var (
+			res   sql.Result
+			count int64
+		)
+
+		if res, err = p.db.ExecContext(ctx, p.sqlDeletePrune[table], append(args, limit)...); err != nil {
+			return result, fmt.Errorf("error pruning table '%s': %w", table, err)
+		}
+
+		if count, err = res.RowsAffected(); err != nil {
+			return result, fmt.Errorf("error pruning table '%s': %w", table, err)
+		}
+
+		if count >= int64(limit) {
+			result.Complete = false
+		}
+
+		result.Tables = append(result.Tables, PruneTableResult{Table: table, Count: count})
+	}
+
+	return result, nil
+}
+
+// LoadPruneCounts returns the number of rows in each table which the PrunePolicy no longer retains.
+func (p *SQLProvider) LoadPruneCounts(ctx context.Context, policy PrunePolicy) (result PruneResult, err error) {
+	result.Complete = true
+
+	for _, table := range sqlPruneTables() {
+		args, ok := policy.args(table)
+		if !ok {
+			continue
+		}
+
+		var count int64
+
+		if err = p.db.GetContext(ctx, &count, p.sqlSelectPruneCount[table], args...); err != nil {
+			return result, fmt.Errorf("error counting the rows to prune from table '%s': %w", table, err)
+		}
+
+		result.Tables = append(result.Tables, PruneTableResult{Table: table, Count: count})
+	}
+
+	return result, nil
+}
+
+// args returns the arguments for the prune queries of the table or false if the table is not pruned.
+func (p PrunePolicy) args(table string) (args []any, ok bool) {
+	switch table {
+	case tableAuthenticationLogs:
+		return pruneArgs(p.AuthenticationLogs)
+	case tableIdentityVerification:
+		return pruneArgs(p.IdentityVerification)
+	case tableOAuth2ConsentSession:
+		return pruneArgs(p.OAuth2ConsentSessions, p.retained()...)
+	case tableOAuth2ConsentPreConfiguration:
+		return pruneArgs(p.OAuth2ConsentPreConfigurations, append([]any{p.OAuth2ConsentPreConfigurations, pruneRetainedAfter(p.OAuth2ConsentSessions)}, p.retained()...)...)
+	case tableOAuth2BlacklistedJTI:
+		return pruneArgs(p.OAuth2BlacklistedJTI)
+	default:
+		for typeOAuth2Session, before := range p.OAuth2Sessions {
+			if typeOAuth2Session.Table() == table {
+				return pruneArgs(before)
+			}
+		}
+
+		return nil, false
+	}
+}
+
+// retained returns the arguments for the conditions which determine if a consent session is still referenced by an
+// OAuth 2.0 session which is retained.
+func (p PrunePolicy) retained() (args []any) {
+	for _, typeOAuth2Session := range sqlPruneOAuth2SessionTypes() {
+		args = append(args, pruneRetainedAfter(p.OAuth2Sessions[typeOAuth2Session]))
+	}
+
+	return args
+}
+
+func pruneArgs(before time.Time, extra ...any) (args []any, ok bool) {
+	if before.IsZero() {
+		return nil, false
+	}
+
+	return append([]any{before}, extra...), true
+}
+
+// pruneRetainedAfter returns the time after which rows are retained. If pruning is disabled every row is retained.
+func pruneRetainedAfter(before time.Time) time.Time {
+	if before.IsZero() {
+		return time.Unix(0, 0).UTC()
+	}
+
+	return before
+}
+
+// sqlPruneTables returns the tables in the order they're pruned. The OAuth 2.0 sessions are pruned before the consent
+// sessions they reference which are pruned before the pre-configured consents they reference.
+func sqlPruneTables() (tables []string) {
+	tables = []string{tableAuthenticationLogs, tableIdentityVerification}
+
+	for _, typeOAuth2Session := range sqlPruneOAuth2SessionTypes() {
+		tables = append(tables, typeOAuth2Session.Table())
+	}
+
+	return append(tables, tableOAuth2ConsentSession, tableOAuth2ConsentPreConfiguration, tableOAuth2BlacklistedJTI)
+}
+
+func sqlPruneOAuth2SessionTypes() (types []OAuth2SessionType) {
+	for i := 0; true; i++ {
+		typeOAuth2Session := OAuth2SessionType(i)
+
+		if typeOAuth2Session.Table() == "" {
+			break
+		}
+
+		types = append(types, typeOAuth2Session)
+	}
+
+	return types
+}
+
+// sqlPruneQueries returns the prune query for each table using the given format which has the table name and the
+// WHERE clause as its arguments.
+func sqlPruneQueries(format string) (queries map[string]string) {
+	types := sqlPruneOAuth2SessionTypes()
+
+	retained := make([]string, len(types))
+
+	for i, typeOAuth2Session := range types {
+		retained[i] = fmt.Sprintf(queryFmtWherePruneOAuth2SessionRetained, typeOAuth2Session.Table(), tableOAuth2ConsentSession)
+	}
+
+	where := map[string]string{
+		tableAuthenticationLogs:            queryWherePruneAuthenticationLogs,
+		tableIdentityVerification:          queryWherePruneIdentityVerification,
+		tableOAuth2ConsentSession:          fmt.Sprintf(queryFmtWherePruneOAuth2ConsentSession, strings.Join(retained, " OR ")),
+		tableOAuth2ConsentPreConfiguration: fmt.Sprintf(queryFmtWherePruneOAuth2ConsentPreConfiguration, tableOAuth2ConsentSession, tableOAuth2ConsentPreConfiguration, strings.Join(retained, " OR ")),
+		tableOAuth2BlacklistedJTI:          queryWherePruneOAuth2BlacklistedJTI,
+	}
+
+	for _, typeOAuth2Session := range types {
+		where[typeOAuth2Session.Table()] = queryWherePruneOAuth2Session
+	}
+
+	queries = make(map[string]string, len(where))
+
+	for table, clause := range where {
+		queries[table] = fmt.Sprintf(format, table, clause)
+	}
+
+	return queries
+}
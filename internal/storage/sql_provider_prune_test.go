@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/v4/internal/configuration/schema"
+	"github.com/authelia/authelia/v4/internal/model"
+)
+
+func TestNewPrunePolicy(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	config := &schema.Configuration{
+		Storage: schema.StorageConfiguration{
+			Retention: schema.DefaultStorageRetentionConfiguration,
+		},
+		IdentityProviders: schema.IdentityProvidersConfiguration{
+			OIDC: &schema.OpenIDConnectConfiguration{
+				RefreshTokenLifespan: time.Hour * 24,
+			},
+		},
+	}
+
+	config.Storage.Retention.OAuth2BlacklistedJTI = -1
+
+	policy := NewPrunePolicy(config, now)
+
+	assert.Equal(t, now.Add(-time.Hour*24*365), policy.AuthenticationLogs)
+	assert.Equal(t, now.Add(-time.Hour*24*7), policy.IdentityVerification)
+	assert.Equal(t, now.Add(-time.Hour*24*7), policy.OAuth2ConsentSessions)
+	assert.Equal(t, now.Add(-time.Hour*24*7), policy.OAuth2ConsentPreConfigurations)
+	assert.True(t, policy.OAuth2BlacklistedJTI.IsZero())
+
+	assert.Equal(t, now.Add(-time.Hour*24*8), policy.OAuth2Sessions[OAuth2SessionTypeRefreshToken])
+	assert.Equal(t, now.Add(-time.Hour*24*7-time.Hour), policy.OAuth2Sessions[OAuth2SessionTypeAccessToken])
+	assert.Equal(t, now.Add(-time.Hour*24*7-time.Minute), policy.OAuth2Sessions[OAuth2SessionTypeAuthorizeCode])
+}
+
+func TestSQLProviderPrune(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	provider := newTestSQLiteProvider(t, filepath.Join(t.TempDir(), "db.sqlite3"), testEncryptionKeyOld)
+	require.NoError(t, provider.StartupCheck())
+
+	subject := uuid.New()
+
+	require.NoError(t, provider.SaveUserOpaqueIdentifier(ctx, model.UserOpaqueIdentifier{Service: "openid", Username: "john", Identifier: subject}))
+
+	for _, at := range []time.Time{now.Add(-time.Hour * 24 * 400), now} {
+		require.NoError(t, provider.AppendAuthenticationLog(ctx, model.AuthenticationAttempt{Time: at, Successful: true, Username: "john", Type: "1FA"}))
+		require.NoError(t, provider.SaveIdentityVerification(ctx, model.IdentityVerification{JTI: uuid.New(), IssuedAt: at, IssuedIP: model.NewIP(net.ParseIP("127.0.0.1")), ExpiresAt: at.Add(time.Minute * 5), Action: "ResetPassword", Username: "john"}))
+	}
+
+	require.NoError(t, provider.SaveOAuth2BlacklistedJTI(ctx, model.OAuth2BlacklistedJTI{Signature: "expired", ExpiresAt: now.Add(-time.Hour * 48)}))
+	require.NoError(t, provider.SaveOAuth2BlacklistedJTI(ctx, model.OAuth2BlacklistedJTI{Signature: "current", ExpiresAt: now.Add(time.Hour)}))
+
+	expired := sql.NullTime{Time: now.Add(-time.Hour * 24 * 10), Valid: true}
+
+	preconfigUnreferenced, err := provider.SaveOAuth2ConsentPreConfiguration(ctx, model.OAuth2ConsentPreConfig{ClientID: "client", Subject: subject, CreatedAt: now.Add(-time.Hour * 24 * 20), ExpiresAt: expired})
+	require.NoError(t, err)
+
+	preconfigReferenced, err := provider.SaveOAuth2ConsentPreConfiguration(ctx, model.OAuth2ConsentPreConfig{ClientID: "client", Subject: subject, CreatedAt: now.Add(-time.Hour * 24 * 20), ExpiresAt: expired})
+	require.NoError(t, err)
+
+	_, err = provider.SaveOAuth2ConsentPreConfiguration(ctx, model.OAuth2ConsentPreConfig{ClientID: "client", Subject: subject, CreatedAt: now.Add(-time.Hour * 24 * 20)})
+	require.NoError(t, err)
+
+	saveConsent := func(requestedAt time.Time, preconfig int64) uuid.UUID {
+		consent := model.OAuth2ConsentSession{
+			ChallengeID: uuid.New(),
+			ClientID:    "client",
+			Subject:     uuid.NullUUID{UUID: subject, Valid: true},
+			RequestedAt: requestedAt,
+		}
+
+		if preconfig != 0 {
+			consent.PreConfiguration = sql.NullInt64{Int64: preconfig, Valid: true}
+		}
+
+		require.NoError(t, provider.SaveOAuth2ConsentSession(ctx, consent))
+
+		return consent.ChallengeID
+	}
+
+	saveSession := func(sessionType OAuth2SessionType, challengeID uuid.UUID, requestedAt time.Time) {
+		require.NoError(t, provider.SaveOAuth2Session(ctx, sessionType, model.OAuth2Session{
+			ChallengeID: challengeID,
+			RequestID:   uuid.NewString(),
+			ClientID:    "client",
+			Signature:   uuid.NewString(),
+			RequestedAt: requestedAt,
+			Subject:     subject.String(),
+			Active:      true,
+			Session:     []byte("{}"),
+		}))
+	}
+
+	old := now.Add(-time.Hour * 24 * 30)
+
+	saveConsent(old, 0)
+	saveSession(OAuth2SessionTypeAccessToken, saveConsent(old, 0), old)
+	saveSession(OAuth2SessionTypeRefreshToken, saveConsent(old, 0), now)
+	saveConsent(now, preconfigReferenced)
+
+	config := &schema.Configuration{
+		Storage: schema.StorageConfiguration{
+			Retention: schema.DefaultStorageRetentionConfiguration,
+		},
+	}
+
+	policy := NewPrunePolicy(config, now)
+
+	expected := map[string]int64{
+		tableAuthenticationLogs:            1,
+		tableIdentityVerification:          1,
+		tableOAuth2AuthorizeCodeSession:    0,
+		tableOAuth2AccessTokenSession:      1,
+		tableOAuth2RefreshTokenSession:     0,
+		tableOAuth2PKCERequestSession:      0,
+		tableOAuth2OpenIDConnectSession:    0,
+		tableOAuth2ConsentSession:          2,
+		tableOAuth2ConsentPreConfiguration: 1,
+		tableOAuth2BlacklistedJTI:          1,
+	}
+
+	counts, err := provider.LoadPruneCounts(ctx, policy)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, pruneResultCounts(counts))
+	assert.Equal(t, int64(7), counts.Total())
+
+	pruned := map[string]int64{}
+
+	var result PruneResult
+
+	for i := 0; i < 10 && !result.Complete; i++ {
+		result, err = provider.Prune(ctx, policy, 1)
+		require.NoError(t, err)
+
+		for table, count := range pruneResultCounts(result) {
+			pruned[table] += count
+		}
+	}
+
+	assert.True(t, result.Complete)
+	assert.Equal(t, expected, pruned)
+
+	counts, err = provider.LoadPruneCounts(ctx, policy)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(0), counts.Total())
+
+	for table, remaining := range map[string]int{
+		tableAuthenticationLogs:            1,
+		tableIdentityVerification:          1,
+		tableOAuth2AccessTokenSession:      0,
+		tableOAuth2RefreshTokenSession:     1,
+		tableOAuth2ConsentSession:          2,
+		tableOAuth2ConsentPreConfiguration: 2,
+		tableOAuth2BlacklistedJTI:          1,
+	} {
+		var count int
+
+		require.NoError(t, provider.db.GetContext(ctx, &count, fmt.Sprintf(queryFmtSelectRowCount, table)))
+		assert.Equal(t, remaining, count, table)
+	}
+
+	var id int64
+
+	require.NoError(t, provider.db.GetContext(ctx, &id, fmt.Sprintf("SELECT id FROM %s WHERE expires_at IS NOT NULL;", tableOAuth2ConsentPreConfiguration)))
+	assert.Equal(t, preconfigReferenced, id)
+	assert.NotEqual(t, preconfigUnreferenced, id)
+}
+
+func TestSQLProviderPruneDisabled(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	provider := newTestSQLiteProvider(t, filepath.Join(t.TempDir(), "db.sqlite3"), testEncryptionKeyOld)
+	require.NoError(t, provider.StartupCheck())
+
+	require.NoError(t, provider.AppendAuthenticationLog(ctx, model.AuthenticationAttempt{Time: now.Add(-time.Hour * 24 * 400), Successful: true, Username: "john", Type: "1FA"}))
+
+	config := &schema.Configuration{
+		Storage: schema.StorageConfiguration{
+			Retention: schema.DefaultStorageRetentionConfiguration,
+		},
+	}
+
+	config.Storage.Retention.AuthenticationLogs = -1
+
+	result, err := provider.Prune(ctx, NewPrunePolicy(config, now), 100)
+	require.NoError(t, err)
+
+	assert.True(t, result.Complete)
+	assert.NotContains(t, pruneResultCounts(result), tableAuthenticationLogs)
+	assert.Equal(t, int64(0), result.Total())
+}
+
+func pruneResultCounts(result PruneResult) (counts map[string]int64) {
+	counts = map[string]int64{}
+
+	for _, table := range result.Tables {
+		counts[table.Table] = table.Count
+	}
+
+	return counts
+}
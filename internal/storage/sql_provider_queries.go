@@ -199,10 +199,113 @@ const (
 		ORDER BY id;`
 )
 
+const (
+	queryFmtUpsertUserPasswordChange = `
+		REPLACE INTO %s (username, changed_at)
+		VALUES (?, ?);`
+
+	queryFmtUpsertUserPasswordChangePostgreSQL = `
+		INSERT INTO %s (username, changed_at)
+		VALUES ($1, $2)
+			ON CONFLICT (username)
+			DO UPDATE SET changed_at = $2;`
+
+	queryFmtSelectUserPasswordChange = `
+		SELECT changed_at
+		FROM %s
+		WHERE username = ?;`
+)
+
+const (
+	queryFmtInsertPasswordHistory = `
+		INSERT INTO %s (created_at, username, digest)
+		VALUES (?, ?, ?);`
+
+	queryFmtSelectPasswordHistory = `
+		SELECT id, created_at, username, digest
+		FROM %s
+		WHERE username = ?
+		ORDER BY id DESC
+		LIMIT ?;`
+
+	queryFmtDeletePasswordHistoryPrune = `
+		DELETE FROM %s
+		WHERE username = ? AND id NOT IN (
+			SELECT id FROM (
+				SELECT id
+				FROM %s
+				WHERE username = ?
+				ORDER BY id DESC
+				LIMIT ?
+			) AS recent
+		);`
+)
+
+const (
+	queryFmtInsertOutboundNotification = `
+		INSERT INTO %s (created_at, next_attempt_at, attempts, status, template, locale, recipient, subject, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);`
+
+	queryFmtSelectOutboundNotificationsDue = `
+		SELECT id, created_at, next_attempt_at, sent_at, attempts, status, template, locale, recipient, subject, data, last_error
+		FROM %s
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY id ASC
+		LIMIT ?;`
+
+	queryFmtSelectOutboundNotifications = `
+		SELECT id, created_at, next_attempt_at, sent_at, attempts, status, template, locale, recipient, subject, data, last_error
+		FROM %s
+		ORDER BY id DESC
+		LIMIT ?
+		OFFSET ?;`
+
+	queryFmtSelectOutboundNotificationsByStatus = `
+		SELECT id, created_at, next_attempt_at, sent_at, attempts, status, template, locale, recipient, subject, data, last_error
+		FROM %s
+		WHERE status = ?
+		ORDER BY id DESC
+		LIMIT ?
+		OFFSET ?;`
+
+	queryFmtSelectOutboundNotificationsEncryptedData = `
+		SELECT id, data
+		FROM %s;`
+
+	queryFmtUpdateOutboundNotificationData = `
+		UPDATE %s
+		SET data = ?
+		WHERE id = ?;`
+
+	queryFmtUpdateOutboundNotificationClaim = `
+		UPDATE %s
+		SET next_attempt_at = ?
+		WHERE id = ? AND status = ? AND next_attempt_at <= ?;`
+
+	queryFmtUpdateOutboundNotificationStatus = `
+		UPDATE %s
+		SET status = ?, attempts = ?, next_attempt_at = ?, sent_at = ?, last_error = ?
+		WHERE id = ?;`
+
+	queryFmtUpdateOutboundNotificationRetry = `
+		UPDATE %s
+		SET status = ?, attempts = 0, next_attempt_at = ?, last_error = NULL
+		WHERE id = ? AND status = ?;`
+
+	queryFmtUpdateOutboundNotificationsRetry = `
+		UPDATE %s
+		SET status = ?, attempts = 0, next_attempt_at = ?, last_error = NULL
+		WHERE status = ?;`
+
+	queryFmtDeleteOutboundNotifications = `
+		DELETE FROM %s
+		WHERE status = ? AND created_at < ?;`
+)
+
 const (
 	queryFmtInsertAuthenticationLogEntry = `
-		INSERT INTO %s (time, successful, banned, username, auth_type, remote_ip, request_uri, request_method)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?);`
+		INSERT INTO %s (time, successful, banned, username, auth_type, remote_ip, request_uri, request_method, user_agent)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);`
 
 	queryFmtSelect1FAAuthenticationLogEntryByUsername = `
 		SELECT time, successful, username
@@ -211,6 +314,15 @@ const (
 		ORDER BY time DESC
 		LIMIT ?
 		OFFSET ?;`
+
+	queryFmtSelectAuthenticationHistoryByUsernameAndType = `
+		SELECT
+			COUNT(id) AS successful,
+			COALESCE(SUM(CASE WHEN remote_ip = ? THEN 1 ELSE 0 END), 0) AS remote_ip,
+			COALESCE(SUM(CASE WHEN user_agent = ? THEN 1 ELSE 0 END), 0) AS user_agent,
+			COALESCE(SUM(CASE WHEN user_agent <> '' THEN 1 ELSE 0 END), 0) AS user_agent_recorded
+		FROM %s
+		WHERE username = ? AND auth_type = ? AND successful = TRUE;`
 )
 
 const (
@@ -223,6 +335,18 @@ const (
 		REPLACE INTO %s (name, value)
 		VALUES (?, ?);`
 
+	queryFmtSelectEncryptedValuesNotCurrent = `
+		SELECT id, %s AS value
+		FROM %s
+		WHERE id > ? AND SUBSTR(%s, 1, %d) <> ?
+		ORDER BY id ASC
+		LIMIT ?;`
+
+	queryFmtUpdateEncryptedValue = `
+		UPDATE %s
+		SET %s = ?
+		WHERE id = ? AND %s = ?;`
+
 	queryFmtUpsertEncryptionValuePostgreSQL = `
 		INSERT INTO %s (name, value)
 		VALUES ($1, $2)
@@ -349,3 +473,49 @@ const (
 		SELECT id, service, sector_id, username, identifier
 		FROM %s;`
 )
+
+const (
+	queryFmtSelectPruneCount = `
+		SELECT COUNT(id)
+		FROM %s
+		WHERE %s;`
+
+	queryFmtDeletePrune = `
+		DELETE FROM %[1]s
+		WHERE id IN (
+			SELECT id
+			FROM %[1]s
+			WHERE %[2]s
+			ORDER BY id
+			LIMIT ?
+		);`
+
+	// MySQL doesn't support LIMIT in IN subqueries but supports it in single table DELETE statements.
+	queryFmtDeletePruneMySQL = `
+		DELETE FROM %s
+		WHERE %s
+		ORDER BY id
+		LIMIT ?;`
+
+	queryWherePruneAuthenticationLogs = `time < ?`
+
+	queryWherePruneIdentityVerification = `exp < ?`
+
+	queryWherePruneOAuth2Session = `requested_at < ?`
+
+	queryWherePruneOAuth2BlacklistedJTI = `expires_at < ?`
+
+	queryFmtWherePruneOAuth2ConsentSession = `requested_at < ? AND NOT (%s)`
+
+	queryFmtWherePruneOAuth2ConsentPreConfiguration = `((expires_at IS NOT NULL AND expires_at < ?) OR (revoked = TRUE AND created_at < ?)) AND NOT EXISTS (
+		SELECT 1
+		FROM %[1]s
+		WHERE %[1]s.preconfiguration = %[2]s.id AND (%[1]s.requested_at >= ? OR %[3]s)
+	)`
+
+	queryFmtWherePruneOAuth2SessionRetained = `EXISTS (
+		SELECT 1
+		FROM %[1]s
+		WHERE %[1]s.challenge_id = %[2]s.challenge_id AND %[1]s.requested_at >= ?
+	)`
+)
@@ -14,4 +14,18 @@ const (
 	queryFmtSelectRowCount = `
 		SELECT COUNT(id)
 		FROM %s;`
+
+	queryFmtSelectCopyRows = `
+		SELECT %s
+		FROM %s
+		WHERE id > ?
+		ORDER BY id
+		LIMIT ?;`
+
+	queryFmtInsertCopyRow = `
+		INSERT INTO %s (%s)
+		VALUES (%s);`
+
+	queryFmtPostgreSQLUpdateSequence = `
+		SELECT SETVAL(PG_GET_SERIAL_SEQUENCE('%s', 'id'), (SELECT COALESCE(MAX(id), 0) + 1 FROM %s), false);`
 )
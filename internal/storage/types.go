@@ -22,7 +22,7 @@ type SQLXConnection interface {
 }
 
 // EncryptionChangeKeyFunc handles encryption key changes for a specific table or tables.
-type EncryptionChangeKeyFunc func(ctx context.Context, provider *SQLProvider, tx *sqlx.Tx, key [32]byte) (err error)
+type EncryptionChangeKeyFunc func(ctx context.Context, provider *SQLProvider, tx *sqlx.Tx, key EncryptionKey) (err error)
 
 // EncryptionCheckKeyFunc handles encryption key checking for a specific table or tables.
 type EncryptionCheckKeyFunc func(ctx context.Context, provider *SQLProvider) (table string, result EncryptionValidationTableResult)
@@ -37,6 +37,21 @@ type encWebauthnDevice struct {
 	PublicKey []byte `db:"public_key"`
 }
 
+type encOutboundNotification struct {
+	ID   int    `db:"id"`
+	Data []byte `db:"data"`
+}
+
+type encValue struct {
+	ID    int    `db:"id"`
+	Value []byte `db:"value"`
+}
+
+type encColumn struct {
+	table  string
+	column string
+}
+
 type encTOTPConfiguration struct {
 	ID     int    `db:"id" json:"-"`
 	Secret []byte `db:"secret" json:"-"`
@@ -45,6 +60,8 @@ type encTOTPConfiguration struct {
 // EncryptionValidationResult contains information about the success of a schema encryption validation.
 type EncryptionValidationResult struct {
 	InvalidCheckValue bool
+	Current           EncryptionKeyID
+	Keys              []EncryptionKeyID
 	Tables            map[string]EncryptionValidationTableResult
 }
 
@@ -79,6 +96,41 @@ type EncryptionValidationTableResult struct {
 	Error   error
 	Total   int
 	Invalid int
+
+	// Legacy is the number of rows which are encrypted without a key identifier.
+	Legacy int
+
+	// Keys is the number of rows encrypted with each key excluding the Legacy rows.
+	Keys map[EncryptionKeyID]int
+}
+
+// Progress returns the number of rows which are encrypted with the given key and the percentage of the total rows
+// this represents.
+func (r EncryptionValidationTableResult) Progress(id EncryptionKeyID) (rows int, percent float64) {
+	if r.Total == 0 {
+		return 0, 100
+	}
+
+	rows = r.Keys[id]
+
+	return rows, float64(rows) / float64(r.Total) * 100
+}
+
+func (r *EncryptionValidationTableResult) check(ctx context.Context, keys *EncryptionKeyring, cipherText []byte) {
+	_, id, legacy, err := keys.Decrypt(ctx, cipherText)
+
+	switch {
+	case err != nil:
+		r.Invalid++
+	case legacy:
+		r.Legacy++
+	default:
+		if r.Keys == nil {
+			r.Keys = map[EncryptionKeyID]int{}
+		}
+
+		r.Keys[id]++
+	}
 }
 
 // ResultDescriptor returns a string representing the result.
@@ -93,3 +145,66 @@ func (r EncryptionValidationTableResult) ResultDescriptor() string {
 
 	return "SUCCESS"
 }
+
+// EncryptionRekeyState tracks the progress of re-encrypting the values which are not encrypted with the current
+// encryption key between calls to SchemaEncryptionRekey.
+type EncryptionRekeyState struct {
+	// Rekeyed is the number of values which have been re-encrypted with the current encryption key.
+	Rekeyed int
+
+	// Failed is the number of values which could not be decrypted with any of the encryption keys.
+	Failed int
+
+	cursors  map[string]int
+	complete map[string]bool
+	done     bool
+}
+
+// Complete returns true if every table has been processed.
+func (s *EncryptionRekeyState) Complete() bool {
+	return s.done
+}
+
+// SchemaCopyResult contains the number of rows copied from each table by SchemaCopy.
+type SchemaCopyResult struct {
+	Tables []SchemaCopyTableResult
+
+	// Reencrypted is the number of values which were re-encrypted with the current encryption key of the target.
+	Reencrypted int
+}
+
+// Total returns the number of rows copied from every table.
+func (r SchemaCopyResult) Total() (total int64) {
+	for _, table := range r.Tables {
+		total += table.Count
+	}
+
+	return total
+}
+
+// SchemaCopyTableResult contains the number of rows copied from a table.
+type SchemaCopyTableResult struct {
+	Table string
+	Count int64
+}
+
+// SchemaBackupResult contains the number of rows backed up or restored from each table by SchemaBackup and
+// SchemaRestore.
+type SchemaBackupResult struct {
+	Tables []SchemaBackupTableResult
+}
+
+// Total returns the number of rows backed up or restored from every table.
+func (r SchemaBackupResult) Total() (total int64) {
+	for _, table := range r.Tables {
+		total += table.Count
+	}
+
+	return total
+}
+
+// SchemaBackupTableResult contains the number of rows backed up or restored from a table.
+type SchemaBackupTableResult struct {
+	Table string
+	Count int64
+}
@@ -865,7 +865,7 @@ func (s *CLISuite) TestStorage02ShouldShowSchemaInfo() {
 }
 
 func (s *CLISuite) TestStorage03ShouldExportTOTP() {
-	storageProvider := storage.NewSQLiteProvider(&storageLocalTmpConfig)
+	storageProvider := storage.NewSQLiteProvider(&storageLocalTmpConfig, nil)
 
 	ctx := context.Background()
 
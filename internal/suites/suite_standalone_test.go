@@ -150,7 +150,7 @@ func (s *StandaloneWebDriverSuite) TestShouldCheckUserIsAskedToRegisterDevice()
 	password := "password"
 
 	// Clean up any TOTP secret already in DB.
-	provider := storage.NewSQLiteProvider(&storageLocalTmpConfig)
+	provider := storage.NewSQLiteProvider(&storageLocalTmpConfig, nil)
 
 	require.NoError(s.T(), provider.DeleteTOTPConfiguration(ctx, username))
 
@@ -3,6 +3,7 @@ package templates
 const (
 	extText = ".txt"
 	extHTML = ".html"
+	extYAML = ".yml"
 )
 
 // Template File Names.
@@ -10,6 +11,15 @@ const (
 	TemplateNameEmailIdentityVerification = "IdentityVerification"
 	TemplateNameEmailEvent                = "Event"
 
+	TemplateNameEmailEventPasswordChanged     = "EventPasswordChanged"
+	TemplateNameEmailEventWebauthnDeviceAdded = "EventWebauthnDeviceAdded"
+	TemplateNameEmailEventTOTPAdded           = "EventTOTPAdded"
+	TemplateNameEmailEventTOTPRemoved         = "EventTOTPRemoved"
+	TemplateNameEmailEventNewLogin            = "EventNewLogin"
+	TemplateNameEmailEventUserBanned          = "EventUserBanned"
+
+	TemplateNameEmailMessages = "Messages"
+
 	TemplateNameOIDCAuthorizeFormPost = "AuthorizeResponseFormPost.html"
 )
 
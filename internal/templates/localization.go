@@ -0,0 +1,224 @@
+package templates
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+// localizedTemplateFiles is an index of the localized template files which exist for a given category keyed by the
+// template name, canonical locale, and extension.
+type localizedTemplateFiles struct {
+	embedded  map[string]string
+	overrides map[string]string
+	locales   []language.Tag
+}
+
+func localizedTemplateKey(name string, locale language.Tag, ext string) string {
+	return name + "." + locale.String() + ext
+}
+
+// discoverLocalizedTemplates finds all the localized variants of the named templates in both the embedded templates
+// and the override path. The localized variants are named using the convention '<Name>.<Locale><Extension>', for
+// example 'IdentityVerification.de.html'. The translated messages such as the subjects are named using the same
+// convention, for example 'Messages.de.yml'.
+func discoverLocalizedTemplates(names []string, category, overridePath string) (files *localizedTemplateFiles, err error) {
+	files = &localizedTemplateFiles{
+		embedded:  map[string]string{},
+		overrides: map[string]string{},
+	}
+
+	known := map[string]struct{}{}
+
+	for _, name := range names {
+		known[name] = struct{}{}
+	}
+
+	seen := map[language.Tag]struct{}{}
+
+	var entries []fs.DirEntry
+
+	dir := path.Join("src", category)
+
+	if entries, err = embedFS.ReadDir(dir); err != nil {
+		return nil, fmt.Errorf("failed to list embedded templates '%s': %w", dir, err)
+	}
+
+	if err = files.index(entries, known, seen, dir, true); err != nil {
+		return nil, err
+	}
+
+	if overridePath == "" {
+		return files, nil
+	}
+
+	if entries, err = os.ReadDir(overridePath); err != nil {
+		if os.IsNotExist(err) {
+			return files, nil
+		}
+
+		return nil, fmt.Errorf("failed to list template overrides at path '%s': %w", overridePath, err)
+	}
+
+	if err = files.index(entries, known, seen, overridePath, false); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+func (f *localizedTemplateFiles) index(entries []fs.DirEntry, known map[string]struct{}, seen map[language.Tag]struct{}, dir string, embed bool) (err error) {
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		ext := filepath.Ext(entry.Name())
+
+		name, locale, found := strings.Cut(strings.TrimSuffix(entry.Name(), ext), ".")
+
+		if !found {
+			continue
+		}
+
+		switch ext {
+		case extText, extHTML:
+			if _, ok := known[name]; !ok {
+				continue
+			}
+		case extYAML:
+			if name != TemplateNameEmailMessages {
+				continue
+			}
+		default:
+			continue
+		}
+
+		var tag language.Tag
+
+		if tag, err = language.Parse(locale); err != nil {
+			if embed {
+				return fmt.Errorf("failed to parse the locale of embedded template '%s': %w", path.Join(dir, entry.Name()), err)
+			}
+
+			return fmt.Errorf("failed to parse the locale of template override at path '%s': %w", filepath.Join(dir, entry.Name()), err)
+		}
+
+		if embed {
+			f.embedded[localizedTemplateKey(name, tag, ext)] = path.Join(dir, entry.Name())
+		} else {
+			f.overrides[localizedTemplateKey(name, tag, ext)] = filepath.Join(dir, entry.Name())
+		}
+
+		if _, ok := seen[tag]; !ok {
+			seen[tag] = struct{}{}
+
+			f.locales = append(f.locales, tag)
+		}
+	}
+
+	return nil
+}
+
+// read returns the most specific template for the given locale. A localized override takes precedence, followed by
+// the default override, followed by a localized embedded template, followed by the default embedded template. Each
+// locale is checked along with its parents i.e. 'de-AT' is checked before 'de'.
+func (f *localizedTemplateFiles) read(name string, locale language.Tag, ext, category, overridePath string) (tPath string, embed bool, data []byte, err error) {
+	for tag := locale; tag != language.Und; tag = tag.Parent() {
+		if tPath, ok := f.overrides[localizedTemplateKey(name, tag, ext)]; ok {
+			if data, err = os.ReadFile(tPath); err != nil {
+				return tPath, false, nil, fmt.Errorf("failed to read template override at path '%s': %w", tPath, err)
+			}
+
+			return tPath, false, data, nil
+		}
+	}
+
+	if overridePath == "" || !fileExists(filepath.Join(overridePath, name+ext)) {
+		for tag := locale; tag != language.Und; tag = tag.Parent() {
+			if tPath, ok := f.embedded[localizedTemplateKey(name, tag, ext)]; ok {
+				if data, err = embedFS.ReadFile(tPath); err != nil {
+					return tPath, true, nil, fmt.Errorf("failed to read embedded template '%s': %w", tPath, err)
+				}
+
+				return tPath, true, data, nil
+			}
+		}
+	}
+
+	return readTemplate(name, ext, category, overridePath)
+}
+
+// messages returns the translated messages for the given locale. The messages of each parent locale are loaded first
+// so the more specific locales take precedence, and the overrides take precedence over the embedded messages.
+func (f *localizedTemplateFiles) messages(locale language.Tag) (messages map[string]string, err error) {
+	var tags []language.Tag
+
+	for tag := locale; tag != language.Und; tag = tag.Parent() {
+		tags = append([]language.Tag{tag}, tags...)
+	}
+
+	messages = map[string]string{}
+
+	var data []byte
+
+	for _, tag := range tags {
+		key := localizedTemplateKey(TemplateNameEmailMessages, tag, extYAML)
+
+		if tPath, ok := f.embedded[key]; ok {
+			if data, err = embedFS.ReadFile(tPath); err != nil {
+				return nil, fmt.Errorf("failed to read embedded messages '%s': %w", tPath, err)
+			}
+
+			if err = yaml.Unmarshal(data, &messages); err != nil {
+				return nil, fmt.Errorf("failed to parse embedded messages '%s': %w", tPath, err)
+			}
+		}
+
+		if tPath, ok := f.overrides[key]; ok {
+			if data, err = os.ReadFile(tPath); err != nil {
+				return nil, fmt.Errorf("failed to read messages override at path '%s': %w", tPath, err)
+			}
+
+			if err = yaml.Unmarshal(data, &messages); err != nil {
+				return nil, fmt.Errorf("failed to parse messages override at path '%s': %w", tPath, err)
+			}
+		}
+	}
+
+	return messages, nil
+}
+
+func loadLocalizedEmailTemplate(name string, locale language.Tag, messages map[string]string, files *localizedTemplateFiles, overridePath string) (t *EmailTemplate, err error) {
+	var (
+		embed bool
+		tpath string
+		data  []byte
+	)
+
+	t = &EmailTemplate{Name: name, Locale: locale.String(), messages: messages}
+
+	if tpath, embed, data, err = files.read(name, locale, extText, TemplateCategoryNotifications, overridePath); err != nil {
+		return nil, err
+	}
+
+	if t.Text, err = parseTextTemplate(name, tpath, embed, data); err != nil {
+		return nil, err
+	}
+
+	if tpath, embed, data, err = files.read(name, locale, extHTML, TemplateCategoryNotifications, overridePath); err != nil {
+		return nil, err
+	}
+
+	if t.HTML, err = parseHTMLTemplate(name, tpath, embed, data); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
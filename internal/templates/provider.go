@@ -6,6 +6,8 @@ import (
 	th "html/template"
 	"path"
 	tt "text/template"
+
+	"golang.org/x/text/language"
 )
 
 // New creates a new templates' provider.
@@ -94,6 +96,51 @@ func (p *Provider) GetIdentityVerificationEmailTemplate() (t *EmailTemplate) {
 	return p.templates.notification.identityVerification
 }
 
+// GetEmailTemplate returns the EmailTemplate with the given name or nil if there is no such template.
+func (p *Provider) GetEmailTemplate(name string) (t *EmailTemplate) {
+	switch name {
+	case TemplateNameEmailIdentityVerification:
+		return p.templates.notification.identityVerification
+	case TemplateNameEmailEvent:
+		return p.templates.notification.event
+	case TemplateNameEmailEventPasswordChanged:
+		return p.templates.notification.events.passwordChanged
+	case TemplateNameEmailEventWebauthnDeviceAdded:
+		return p.templates.notification.events.webauthnDeviceAdded
+	case TemplateNameEmailEventTOTPAdded:
+		return p.templates.notification.events.totpAdded
+	case TemplateNameEmailEventTOTPRemoved:
+		return p.templates.notification.events.totpRemoved
+	case TemplateNameEmailEventNewLogin:
+		return p.templates.notification.events.newLogin
+	case TemplateNameEmailEventUserBanned:
+		return p.templates.notification.events.userBanned
+	default:
+		return nil
+	}
+}
+
+// GetLocalizedEmailTemplate returns the EmailTemplate with the given name in the locale which best matches the
+// preferred languages. The default template is returned if no localized template matches and nil is returned if there
+// is no such template.
+func (p *Provider) GetLocalizedEmailTemplate(name string, preferred ...language.Tag) (t *EmailTemplate) {
+	if t = p.GetEmailTemplate(name); t == nil || len(preferred) == 0 || p.templates.notification.matcher == nil {
+		return t
+	}
+
+	_, index, confidence := p.templates.notification.matcher.Match(preferred...)
+
+	if index == 0 || confidence == language.No {
+		return t
+	}
+
+	if localized, ok := p.templates.notification.localized[p.templates.notification.locales[index]][name]; ok {
+		return localized
+	}
+
+	return t
+}
+
 // GetOpenIDConnectAuthorizeResponseFormPostTemplate returns a Template used to generate the OpenID Connect 1.0 Form Post Authorize Response.
 func (p *Provider) GetOpenIDConnectAuthorizeResponseFormPostTemplate() (t *th.Template) {
 	return p.templates.oidc.formpost
@@ -110,6 +157,34 @@ func (p *Provider) load() (err error) {
 		errs = append(errs, err)
 	}
 
+	events := []struct {
+		name     string
+		template **EmailTemplate
+	}{
+		{TemplateNameEmailEventPasswordChanged, &p.templates.notification.events.passwordChanged},
+		{TemplateNameEmailEventWebauthnDeviceAdded, &p.templates.notification.events.webauthnDeviceAdded},
+		{TemplateNameEmailEventTOTPAdded, &p.templates.notification.events.totpAdded},
+		{TemplateNameEmailEventTOTPRemoved, &p.templates.notification.events.totpRemoved},
+		{TemplateNameEmailEventNewLogin, &p.templates.notification.events.newLogin},
+		{TemplateNameEmailEventUserBanned, &p.templates.notification.events.userBanned},
+	}
+
+	for _, event := range events {
+		if *event.template, err = loadEmailTemplate(event.name, p.config.EmailTemplatesPath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	names := []string{TemplateNameEmailIdentityVerification, TemplateNameEmailEvent}
+
+	for _, event := range events {
+		names = append(names, event.name)
+	}
+
+	if err = p.loadLocalized(names); err != nil {
+		errs = append(errs, err)
+	}
+
 	var data []byte
 
 	if data, err = embedFS.ReadFile(path.Join("src", TemplateCategoryOpenIDConnect, TemplateNameOIDCAuthorizeFormPost)); err != nil {
@@ -136,3 +211,43 @@ func (p *Provider) load() (err error) {
 
 	return nil
 }
+
+func (p *Provider) loadLocalized(names []string) (err error) {
+	var files *localizedTemplateFiles
+
+	if files, err = discoverLocalizedTemplates(names, TemplateCategoryNotifications, p.config.EmailTemplatesPath); err != nil {
+		return err
+	}
+
+	if len(files.locales) == 0 {
+		return nil
+	}
+
+	p.templates.notification.localized = map[language.Tag]map[string]*EmailTemplate{}
+	p.templates.notification.locales = append([]language.Tag{language.Und}, files.locales...)
+
+	var (
+		t        *EmailTemplate
+		messages map[string]string
+	)
+
+	for _, locale := range files.locales {
+		p.templates.notification.localized[locale] = map[string]*EmailTemplate{}
+
+		if messages, err = files.messages(locale); err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			if t, err = loadLocalizedEmailTemplate(name, locale, messages, files, p.config.EmailTemplatesPath); err != nil {
+				return err
+			}
+
+			p.templates.notification.localized[locale][name] = t
+		}
+	}
+
+	p.templates.notification.matcher = language.NewMatcher(p.templates.notification.locales)
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package templates
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestProviderGetEmailTemplate(t *testing.T) {
+	provider, err := New(Config{})
+	require.NoError(t, err)
+
+	testCases := []string{
+		TemplateNameEmailIdentityVerification,
+		TemplateNameEmailEvent,
+		TemplateNameEmailEventPasswordChanged,
+		TemplateNameEmailEventWebauthnDeviceAdded,
+		TemplateNameEmailEventTOTPAdded,
+		TemplateNameEmailEventTOTPRemoved,
+		TemplateNameEmailEventNewLogin,
+		TemplateNameEmailEventUserBanned,
+	}
+
+	for _, name := range testCases {
+		t.Run(name, func(t *testing.T) {
+			template := provider.GetEmailTemplate(name)
+			require.NotNil(t, template)
+
+			assert.Equal(t, name, template.Name)
+
+			if name == TemplateNameEmailIdentityVerification {
+				return
+			}
+
+			data := EmailEventValues{
+				Title:       "Example",
+				DisplayName: "John Smith",
+				RemoteIP:    "127.0.0.1",
+				Details:     map[string]any{"Action": "Example"},
+			}
+
+			buf := &bytes.Buffer{}
+
+			require.NoError(t, template.Text.Execute(buf, data))
+			assert.Contains(t, buf.String(), "Action: Example")
+
+			buf.Reset()
+
+			require.NoError(t, template.HTML.Execute(buf, data))
+			assert.Contains(t, buf.String(), "Hi John Smith")
+		})
+	}
+
+	assert.Nil(t, provider.GetEmailTemplate("Unknown"))
+}
+
+func TestProviderGetLocalizedEmailTemplate(t *testing.T) {
+	provider, err := New(Config{})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		preferred []language.Tag
+		locale    string
+		expected  string
+	}{
+		{"ShouldReturnDefaultWithoutPreference", nil, "", "Hi John Smith"},
+		{"ShouldReturnDefaultForEnglish", []language.Tag{language.English}, "", "Hi John Smith"},
+		{"ShouldReturnDefaultForUnknownLocale", []language.Tag{language.Japanese}, "", "Hi John Smith"},
+		{"ShouldReturnGerman", []language.Tag{language.German}, "de", "Hallo John Smith"},
+		{"ShouldReturnGermanForRegion", []language.Tag{language.MustParse("de-AT")}, "de", "Hallo John Smith"},
+		{"ShouldReturnGermanForSecondPreference", []language.Tag{language.Japanese, language.German}, "de", "Hallo John Smith"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			template := provider.GetLocalizedEmailTemplate(TemplateNameEmailEvent, tc.preferred...)
+			require.NotNil(t, template)
+
+			assert.Equal(t, TemplateNameEmailEvent, template.Name)
+			assert.Equal(t, tc.locale, template.Locale)
+
+			buf := &bytes.Buffer{}
+
+			require.NoError(t, template.HTML.Execute(buf, EmailEventValues{DisplayName: "John Smith", Details: map[string]any{}}))
+			assert.Contains(t, buf.String(), tc.expected)
+		})
+	}
+
+	assert.Nil(t, provider.GetLocalizedEmailTemplate("Unknown", language.German))
+}
+
+func TestProviderGetLocalizedEmailTemplateOverrides(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "IdentityVerification.fr-CA.txt"), []byte("Bonjour {{ .DisplayName }}"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Event.txt"), []byte("Custom {{ .DisplayName }}"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Unknown.es.txt"), []byte("Hola"), 0600))
+
+	provider, err := New(Config{EmailTemplatesPath: dir})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		template  string
+		preferred language.Tag
+		expected  string
+	}{
+		{"ShouldReturnLocalizedOverride", TemplateNameEmailIdentityVerification, language.CanadianFrench, "Bonjour John Smith"},
+		{"ShouldReturnLocalizedOverrideForParent", TemplateNameEmailIdentityVerification, language.French, "Bonjour John Smith"},
+		{"ShouldReturnDefaultOverrideWhenNotLocalized", TemplateNameEmailEvent, language.CanadianFrench, "Custom John Smith"},
+		{"ShouldReturnEmbeddedDefaultWhenNotOverridden", TemplateNameEmailIdentityVerification, language.Japanese, "This email has been sent to you"},
+		{"ShouldReturnEmbeddedLocalized", TemplateNameEmailIdentityVerification, language.German, "Diese E-Mail wurde an Sie gesendet"},
+		{"ShouldPreferDefaultOverrideOverEmbeddedLocalized", TemplateNameEmailEvent, language.German, "Custom John Smith"},
+		{"ShouldIgnoreUnknownTemplates", TemplateNameEmailEvent, language.Spanish, "Custom John Smith"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			template := provider.GetLocalizedEmailTemplate(tc.template, tc.preferred)
+			require.NotNil(t, template)
+
+			buf := &bytes.Buffer{}
+
+			require.NoError(t, template.Text.Execute(buf, EmailIdentityVerificationValues{DisplayName: "John Smith"}))
+			assert.Contains(t, buf.String(), tc.expected)
+		})
+	}
+}
+
+func TestProviderShouldErrorOnInvalidOverrideLocale(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Event.zz.txt"), []byte("Example"), 0600))
+
+	_, err := New(Config{EmailTemplatesPath: dir})
+	assert.ErrorContains(t, err, "failed to parse the locale of template override at path '"+filepath.Join(dir, "Event.zz.txt")+"'")
+}
+
+func TestProviderEmailTemplateTranslate(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Messages.de.yml"), []byte("Reset your password: Passwort neu setzen\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Messages.de-AT.yml"), []byte("New Sign In: Neue Anmeldung in Österreich\n"), 0600))
+
+	provider, err := New(Config{EmailTemplatesPath: dir})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		preferred []language.Tag
+		message   string
+		expected  string
+	}{
+		{"ShouldNotTranslateDefault", nil, "Reset your password", "Reset your password"},
+		{"ShouldTranslateEmbedded", []language.Tag{language.German}, "Register your key", "Registrieren Sie Ihren Sicherheitsschlüssel"},
+		{"ShouldPreferOverride", []language.Tag{language.German}, "Reset your password", "Passwort neu setzen"},
+		{"ShouldPreferMoreSpecificLocale", []language.Tag{language.MustParse("de-AT")}, "New Sign In", "Neue Anmeldung in Österreich"},
+		{"ShouldFallbackToParentLocale", []language.Tag{language.MustParse("de-AT")}, "Account Locked", "Konto gesperrt"},
+		{"ShouldReturnUnknownMessage", []language.Tag{language.German}, "Unknown", "Unknown"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			template := provider.GetLocalizedEmailTemplate(TemplateNameEmailIdentityVerification, tc.preferred...)
+			require.NotNil(t, template)
+
+			assert.Equal(t, tc.expected, template.Translate(tc.message))
+		})
+	}
+
+	assert.Equal(t, "Reset", (*EmailTemplate)(nil).Translate("Reset"))
+}
+
+func TestProviderShouldErrorOnInvalidOverrideMessages(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Messages.de.yml"), []byte("- invalid"), 0600))
+
+	_, err := New(Config{EmailTemplatesPath: dir})
+	assert.ErrorContains(t, err, "failed to parse messages override at path '"+filepath.Join(dir, "Messages.de.yml")+"'")
+}
@@ -4,6 +4,8 @@ import (
 	th "html/template"
 	"io"
 	tt "text/template"
+
+	"golang.org/x/text/language"
 )
 
 // Templates is the struct which holds all the *template.Template values.
@@ -33,6 +35,21 @@ type OpenAPIAssetTemplates struct {
 type NotificationTemplates struct {
 	identityVerification *EmailTemplate
 	event                *EmailTemplate
+	events               NotificationEventTemplates
+
+	localized map[language.Tag]map[string]*EmailTemplate
+	locales   []language.Tag
+	matcher   language.Matcher
+}
+
+// NotificationEventTemplates are the templates for the security event notifications.
+type NotificationEventTemplates struct {
+	passwordChanged     *EmailTemplate
+	webauthnDeviceAdded *EmailTemplate
+	totpAdded           *EmailTemplate
+	totpRemoved         *EmailTemplate
+	newLogin            *EmailTemplate
+	userBanned          *EmailTemplate
 }
 
 // Template covers shared implementations between the text and html template.Template.
@@ -50,8 +67,26 @@ type Config struct {
 
 // EmailTemplate is the template type which contains both the html and txt versions of a template.
 type EmailTemplate struct {
-	HTML *th.Template
-	Text *tt.Template
+	Name   string
+	Locale string
+	HTML   *th.Template
+	Text   *tt.Template
+
+	messages map[string]string
+}
+
+// Translate returns the message translated to the locale of the template such as the subject of the email, or the
+// message itself if there is no translation.
+func (t *EmailTemplate) Translate(message string) string {
+	if t == nil {
+		return message
+	}
+
+	if translated, ok := t.messages[message]; ok {
+		return translated
+	}
+
+	return message
 }
 
 // EmailEventValues are the values used for event templates.
@@ -99,7 +99,7 @@ func loadEmailTemplate(name, overridePath string) (t *EmailTemplate, err error)
 		data  []byte
 	)
 
-	t = &EmailTemplate{}
+	t = &EmailTemplate{Name: name}
 
 	if tpath, embed, data, err = readTemplate(name, extText, TemplateCategoryNotifications, overridePath); err != nil {
 		return nil, err
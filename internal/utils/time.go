@@ -148,3 +148,8 @@ func matchParseTimeStringWithLayouts(input string, layouts []string) (index int,
 func UnixNanoTimeToMicrosoftNTEpoch(nano int64) (t uint64) {
 	return uint64(nano/100) + timeUnixEpochAsMicrosoftNTEpoch
 }
+
+// MicrosoftNTEpochToTime converts a win32 epoch format timestamp to a time.Time.
+func MicrosoftNTEpochToTime(epoch uint64) (t time.Time) {
+	return time.Unix(0, int64(epoch-timeUnixEpochAsMicrosoftNTEpoch)*100).UTC()
+}
@@ -173,6 +173,11 @@ func TestShouldConvertKnownUnixNanoTimeToKnownWin32Epoch(t *testing.T) {
 	assert.Equal(t, timeUnixEpochAsMicrosoftNTEpoch, UnixNanoTimeToMicrosoftNTEpoch(0))
 }
 
+func TestShouldConvertKnownWin32EpochToKnownTime(t *testing.T) {
+	assert.Equal(t, time.Unix(1626234411, 0).UTC(), MicrosoftNTEpochToTime(132707080110000000))
+	assert.Equal(t, time.Unix(0, 0).UTC(), MicrosoftNTEpochToTime(timeUnixEpochAsMicrosoftNTEpoch))
+}
+
 func TestParseTimeString(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -1,6 +1,10 @@
-import { FirstFactorPath } from "@services/Api";
+import { FirstFactorPasswordChangePath, FirstFactorPath } from "@services/Api";
 import { PostWithOptionalResponse } from "@services/Client";
-import { SignInResponse } from "@services/SignIn";
+
+export interface FirstFactorResponse {
+    redirect?: string;
+    password_change_required?: boolean;
+}
 
 interface PostFirstFactorBody {
     username: string;
@@ -37,6 +41,10 @@ export async function postFirstFactor(
         data.workflow = workflow;
     }
 
-    const res = await PostWithOptionalResponse<SignInResponse>(FirstFactorPath, data);
-    return res ? res : ({} as SignInResponse);
+    const res = await PostWithOptionalResponse<FirstFactorResponse>(FirstFactorPath, data);
+    return res ? res : ({} as FirstFactorResponse);
+}
+
+export async function postFirstFactorPasswordChange(newPassword: string) {
+    return PostWithOptionalResponse(FirstFactorPasswordChangePath, { password: newPassword });
 }
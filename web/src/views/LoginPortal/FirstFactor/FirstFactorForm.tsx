@@ -15,6 +15,7 @@ import { useQueryParam } from "@hooks/QueryParam";
 import { useWorkflow } from "@hooks/Workflow";
 import LoginLayout from "@layouts/LoginLayout";
 import { postFirstFactor } from "@services/FirstFactor";
+import PasswordChangeForm from "@views/LoginPortal/FirstFactor/PasswordChangeForm";
 
 export interface Props {
     disabled: boolean;
@@ -42,6 +43,7 @@ const FirstFactorForm = function (props: Props) {
     const [usernameError, setUsernameError] = useState(false);
     const [password, setPassword] = useState("");
     const [passwordError, setPasswordError] = useState(false);
+    const [passwordChangeRequired, setPasswordChangeRequired] = useState(false);
     const { createErrorNotification } = useNotifications();
     // TODO (PR: #806, Issue: #511) potentially refactor
     const usernameRef = useRef() as MutableRefObject<HTMLInputElement>;
@@ -79,9 +81,26 @@ const FirstFactorForm = function (props: Props) {
             return;
         }
 
+        await signIn(password);
+    };
+
+    const signIn = async (currentPassword: string) => {
         props.onAuthenticationStart();
         try {
-            const res = await postFirstFactor(username, password, rememberMe, redirectionURL, requestMethod, workflow);
+            const res = await postFirstFactor(
+                username,
+                currentPassword,
+                rememberMe,
+                redirectionURL,
+                requestMethod,
+                workflow,
+            );
+            if (res.password_change_required) {
+                props.onAuthenticationFailure();
+                setPassword("");
+                setPasswordChangeRequired(true);
+                return;
+            }
             await loginChannel.postMessage(true);
             props.onAuthenticationSuccess(res ? res.redirect : undefined);
         } catch (err) {
@@ -89,10 +108,19 @@ const FirstFactorForm = function (props: Props) {
             createErrorNotification(translate("Incorrect username or password"));
             props.onAuthenticationFailure();
             setPassword("");
-            passwordRef.current.focus();
+            setPasswordChangeRequired(false);
+            passwordRef.current?.focus();
         }
     };
 
+    const handlePasswordChanged = async (newPassword: string) => {
+        await signIn(newPassword);
+    };
+
+    const handlePasswordChangeCancel = () => {
+        setPasswordChangeRequired(false);
+    };
+
     const handleResetPasswordClick = () => {
         if (props.resetPassword) {
             if (props.resetPasswordCustomURL !== "") {
@@ -103,6 +131,16 @@ const FirstFactorForm = function (props: Props) {
         }
     };
 
+    if (passwordChangeRequired) {
+        return (
+            <PasswordChangeForm
+                disabled={disabled}
+                onPasswordChanged={handlePasswordChanged}
+                onCancel={handlePasswordChangeCancel}
+            />
+        );
+    }
+
     return (
         <LoginLayout id="first-factor-stage" title={translate("Sign in")} showBrand>
             <Grid container spacing={2}>
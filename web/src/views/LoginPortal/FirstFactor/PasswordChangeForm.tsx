@@ -0,0 +1,184 @@
+import React, { useEffect, useState } from "react";
+
+import { Visibility, VisibilityOff } from "@mui/icons-material";
+import { Button, Grid, IconButton, InputAdornment, Typography } from "@mui/material";
+import makeStyles from "@mui/styles/makeStyles";
+import classnames from "classnames";
+import { useTranslation } from "react-i18next";
+
+import FixedTextField from "@components/FixedTextField";
+import PasswordMeter from "@components/PasswordMeter";
+import { useNotifications } from "@hooks/NotificationsContext";
+import LoginLayout from "@layouts/LoginLayout";
+import { PasswordPolicyConfiguration, PasswordPolicyMode } from "@models/PasswordPolicy";
+import { postFirstFactorPasswordChange } from "@services/FirstFactor";
+import { getPasswordPolicyConfiguration } from "@services/PasswordPolicyConfiguration";
+
+export interface Props {
+    disabled: boolean;
+
+    onPasswordChanged: (newPassword: string) => void;
+    onCancel: () => void;
+}
+
+const PasswordChangeForm = function (props: Props) {
+    const styles = useStyles();
+    const [formDisabled, setFormDisabled] = useState(false);
+    const [password1, setPassword1] = useState("");
+    const [password2, setPassword2] = useState("");
+    const [errorPassword1, setErrorPassword1] = useState(false);
+    const [errorPassword2, setErrorPassword2] = useState(false);
+    const { createErrorNotification } = useNotifications();
+    const { t: translate } = useTranslation();
+    const [showPassword, setShowPassword] = useState(false);
+
+    const [pPolicy, setPPolicy] = useState<PasswordPolicyConfiguration>({
+        max_length: 0,
+        min_length: 8,
+        min_score: 0,
+        require_lowercase: false,
+        require_number: false,
+        require_special: false,
+        require_uppercase: false,
+        mode: PasswordPolicyMode.Disabled,
+    });
+
+    useEffect(() => {
+        getPasswordPolicyConfiguration()
+            .then((policy) => setPPolicy(policy))
+            .catch((err) => console.error(err));
+    }, []);
+
+    const disabled = props.disabled || formDisabled;
+
+    const doChangePassword = async () => {
+        if (password1 === "" || password2 === "") {
+            if (password1 === "") {
+                setErrorPassword1(true);
+            }
+            if (password2 === "") {
+                setErrorPassword2(true);
+            }
+            return;
+        }
+        if (password1 !== password2) {
+            setErrorPassword1(true);
+            setErrorPassword2(true);
+            createErrorNotification(translate("Passwords do not match"));
+            return;
+        }
+
+        setFormDisabled(true);
+
+        try {
+            await postFirstFactorPasswordChange(password1);
+            props.onPasswordChanged(password1);
+        } catch (err) {
+            console.error(err);
+            if ((err as Error).message.includes("0000052D.") || (err as Error).message.includes("policy")) {
+                createErrorNotification(
+                    translate("Your supplied password does not meet the password policy requirements"),
+                );
+            } else {
+                createErrorNotification(translate("There was an issue changing the password"));
+            }
+            setFormDisabled(false);
+        }
+    };
+
+    return (
+        <LoginLayout id="password-change-stage" title={translate("Change password")} showBrand>
+            <Grid container spacing={2}>
+                <Grid item xs={12}>
+                    <Typography>
+                        {translate("Your password has expired or must be changed before you can sign in")}
+                    </Typography>
+                </Grid>
+                <Grid item xs={12}>
+                    <FixedTextField
+                        id="password1-textfield"
+                        label={translate("New password")}
+                        variant="outlined"
+                        type={showPassword ? "text" : "password"}
+                        value={password1}
+                        disabled={disabled}
+                        onChange={(e) => setPassword1(e.target.value)}
+                        onFocus={() => setErrorPassword1(false)}
+                        error={errorPassword1}
+                        className={classnames(styles.fullWidth)}
+                        autoComplete="new-password"
+                        InputProps={{
+                            endAdornment: (
+                                <InputAdornment position="end">
+                                    <IconButton
+                                        aria-label="toggle password visibility"
+                                        onClick={() => setShowPassword(!showPassword)}
+                                        edge="end"
+                                        size="large"
+                                    >
+                                        {showPassword ? <VisibilityOff></VisibilityOff> : <Visibility></Visibility>}
+                                    </IconButton>
+                                </InputAdornment>
+                            ),
+                        }}
+                    />
+                    {pPolicy.mode === PasswordPolicyMode.Disabled ? null : (
+                        <PasswordMeter value={password1} policy={pPolicy} />
+                    )}
+                </Grid>
+                <Grid item xs={12}>
+                    <FixedTextField
+                        id="password2-textfield"
+                        label={translate("Repeat new password")}
+                        variant="outlined"
+                        type={showPassword ? "text" : "password"}
+                        disabled={disabled}
+                        value={password2}
+                        onChange={(e) => setPassword2(e.target.value)}
+                        onFocus={() => setErrorPassword2(false)}
+                        error={errorPassword2}
+                        onKeyPress={(ev) => {
+                            if (ev.key === "Enter") {
+                                doChangePassword();
+                                ev.preventDefault();
+                            }
+                        }}
+                        className={classnames(styles.fullWidth)}
+                        autoComplete="new-password"
+                    />
+                </Grid>
+                <Grid item xs={6}>
+                    <Button
+                        id="change-password-button"
+                        variant="contained"
+                        color="primary"
+                        disabled={disabled}
+                        onClick={() => doChangePassword()}
+                        className={styles.fullWidth}
+                    >
+                        {translate("Change password")}
+                    </Button>
+                </Grid>
+                <Grid item xs={6}>
+                    <Button
+                        id="cancel-button"
+                        variant="contained"
+                        color="primary"
+                        onClick={props.onCancel}
+                        className={styles.fullWidth}
+                    >
+                        {translate("Cancel")}
+                    </Button>
+                </Grid>
+            </Grid>
+        </LoginLayout>
+    );
+};
+
+export default PasswordChangeForm;
+
+const useStyles = makeStyles(() => ({
+    fullWidth: {
+        width: "100%",
+    },
+}));